@@ -17,6 +17,9 @@ limitations under the License.
 package v1
 
 import (
+	"fmt"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -43,6 +46,30 @@ const (
 	BackupPhaseWalArchivingFailing = "walArchivingFailing"
 )
 
+// BackupVerificationPhase is the result of the verification of a backup
+type BackupVerificationPhase string
+
+const (
+	// BackupVerificationPhaseVerified means that the backup can be used
+	// to recover a cluster
+	BackupVerificationPhaseVerified = BackupVerificationPhase("verified")
+
+	// BackupVerificationPhaseFailed means that the backup verification failed
+	BackupVerificationPhaseFailed = BackupVerificationPhase("failed")
+)
+
+const (
+	// BackupMaxRetries is the number of times the operator retries
+	// to start a backup before marking it as failed
+	BackupMaxRetries = 8
+
+	// backupRetryBaseDelay is the delay before the first retry of a backup
+	backupRetryBaseDelay = 10 * time.Second
+
+	// backupRetryMaxDelay is the maximum delay between two retries of a backup
+	backupRetryMaxDelay = 5 * time.Minute
+)
+
 // BackupSpec defines the desired state of Backup
 type BackupSpec struct {
 	// The cluster to backup
@@ -75,6 +102,13 @@ type BackupStatus struct {
 	// Encryption method required to S3 API
 	Encryption string `json:"encryption,omitempty"`
 
+	// The compression algorithm used for the backup files
+	Compression string `json:"compression,omitempty"`
+
+	// The maximum amount of data uploaded per second while taking the
+	// backup, if limited
+	MaxBandwidth string `json:"maxBandwidth,omitempty"`
+
 	// The ID of the Barman backup
 	BackupID string `json:"backupId,omitempty"`
 
@@ -110,6 +144,30 @@ type BackupStatus struct {
 
 	// Information to identify the instance where the backup has been taken from
 	InstanceID *InstanceID `json:"instanceID,omitempty"`
+
+	// The number of times the operator retried to start the backup
+	// +optional
+	RetryCount int `json:"retryCount,omitempty"`
+
+	// When the operator will retry to start the backup
+	// +optional
+	NextRetryAt *metav1.Time `json:"nextRetryAt,omitempty"`
+
+	// The result of the verification of the backup, if enabled
+	Verification *BackupVerificationStatus `json:"verification,omitempty"`
+}
+
+// BackupVerificationStatus contains the result of the verification
+// of a completed backup
+type BackupVerificationStatus struct {
+	// The result of the verification
+	Phase BackupVerificationPhase `json:"phase"`
+
+	// When the backup has been verified
+	VerifiedAt *metav1.Time `json:"verifiedAt,omitempty"`
+
+	// The reason why the verification failed
+	Error string `json:"error,omitempty"`
 }
 
 // InstanceID contains the information to identify an instance
@@ -167,12 +225,77 @@ func (backupStatus *BackupStatus) SetAsFailed(
 	}
 }
 
+// SetAsRetrying records a failed attempt to start the backup, scheduling
+// a new one with an exponential backoff. When the retries are exhausted
+// the backup is marked as failed, and false is returned
+func (backupStatus *BackupStatus) SetAsRetrying(reason string, now time.Time) (time.Duration, bool) {
+	backupStatus.NextRetryAt = nil
+
+	if backupStatus.RetryCount >= BackupMaxRetries {
+		backupStatus.SetAsFailed(fmt.Errorf("giving up after %d retries: %s", backupStatus.RetryCount, reason))
+		return 0, false
+	}
+
+	delay := getBackupRetryDelay(backupStatus.RetryCount)
+	nextRetryAt := metav1.NewTime(now.Add(delay))
+	backupStatus.Phase = BackupPhasePending
+	backupStatus.Error = reason
+	backupStatus.RetryCount++
+	backupStatus.NextRetryAt = &nextRetryAt
+
+	return delay, true
+}
+
+// GetRetryWaitTime returns how long we need to wait before the
+// next scheduled retry of the backup, zero if none is pending
+func (backupStatus *BackupStatus) GetRetryWaitTime(now time.Time) time.Duration {
+	if backupStatus.NextRetryAt == nil || !backupStatus.NextRetryAt.After(now) {
+		return 0
+	}
+
+	return backupStatus.NextRetryAt.Sub(now)
+}
+
+// getBackupRetryDelay returns the delay before retrying a backup
+// that has already been retried retryCount times
+func getBackupRetryDelay(retryCount int) time.Duration {
+	delay := backupRetryBaseDelay
+	for i := 0; i < retryCount && delay < backupRetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > backupRetryMaxDelay {
+		return backupRetryMaxDelay
+	}
+	return delay
+}
+
 // SetAsCompleted marks a certain backup as completed
 func (backupStatus *BackupStatus) SetAsCompleted() {
 	backupStatus.Phase = BackupPhaseCompleted
 	backupStatus.Error = ""
 }
 
+// SetVerification records the result of the verification of a backup
+func (backupStatus *BackupStatus) SetVerification(err error, now time.Time) {
+	verifiedAt := metav1.NewTime(now)
+	backupStatus.Verification = &BackupVerificationStatus{
+		Phase:      BackupVerificationPhaseVerified,
+		VerifiedAt: &verifiedAt,
+	}
+
+	if err != nil {
+		backupStatus.Verification.Phase = BackupVerificationPhaseFailed
+		backupStatus.Verification.Error = err.Error()
+	}
+}
+
+// IsVerified checks if the backup has been successfully verified
+func (backupStatus *BackupStatus) IsVerified() bool {
+	return backupStatus.Verification != nil &&
+		backupStatus.Verification.Phase == BackupVerificationPhaseVerified
+}
+
 // IsDone check if a backup is completed or still in progress
 func (backupStatus *BackupStatus) IsDone() bool {
 	return backupStatus.Phase == BackupPhaseCompleted || backupStatus.Phase == BackupPhaseFailed
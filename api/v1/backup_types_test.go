@@ -0,0 +1,86 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup retries", func() {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	It("uses an exponential backoff with a cap", func() {
+		Expect(getBackupRetryDelay(0)).To(Equal(10 * time.Second))
+		Expect(getBackupRetryDelay(1)).To(Equal(20 * time.Second))
+		Expect(getBackupRetryDelay(3)).To(Equal(80 * time.Second))
+		Expect(getBackupRetryDelay(5)).To(Equal(5 * time.Minute))
+		Expect(getBackupRetryDelay(100)).To(Equal(5 * time.Minute))
+	})
+
+	It("schedules the next retry of a pending backup", func() {
+		status := BackupStatus{}
+		delay, retrying := status.SetAsRetrying("pod not ready", now)
+		Expect(retrying).To(BeTrue())
+		Expect(delay).To(Equal(10 * time.Second))
+		Expect(status.Phase).To(BeEquivalentTo(BackupPhasePending))
+		Expect(status.RetryCount).To(Equal(1))
+		Expect(status.Error).To(Equal("pod not ready"))
+		Expect(status.NextRetryAt.Time).To(Equal(now.Add(10 * time.Second)))
+
+		Expect(status.GetRetryWaitTime(now)).To(Equal(10 * time.Second))
+		Expect(status.GetRetryWaitTime(now.Add(10 * time.Second))).To(BeZero())
+	})
+
+	It("marks the backup as failed when the retries are exhausted", func() {
+		status := BackupStatus{RetryCount: BackupMaxRetries}
+		_, retrying := status.SetAsRetrying("pod not ready", now)
+		Expect(retrying).To(BeFalse())
+		Expect(status.Phase).To(BeEquivalentTo(BackupPhaseFailed))
+		Expect(status.Error).To(ContainSubstring("pod not ready"))
+		Expect(status.NextRetryAt).To(BeNil())
+		Expect(status.IsDone()).To(BeTrue())
+	})
+
+	It("doesn't wait when no retry is scheduled", func() {
+		status := BackupStatus{}
+		Expect(status.GetRetryWaitTime(now)).To(BeZero())
+	})
+})
+
+var _ = Describe("backup verification", func() {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	It("marks the backup as verified", func() {
+		status := BackupStatus{}
+		status.SetVerification(nil, now)
+		Expect(status.IsVerified()).To(BeTrue())
+		Expect(status.Verification.VerifiedAt.Time).To(Equal(now))
+		Expect(status.Verification.Error).To(BeEmpty())
+	})
+
+	It("records the reason of a failed verification", func() {
+		status := BackupStatus{}
+		status.SetVerification(fmt.Errorf("backup not found"), now)
+		Expect(status.IsVerified()).To(BeFalse())
+		Expect(status.Verification.Phase).To(Equal(BackupVerificationPhaseFailed))
+		Expect(status.Verification.Error).To(Equal("backup not found"))
+	})
+})
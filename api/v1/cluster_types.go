@@ -20,11 +20,14 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/strings/slices"
 
 	"github.com/cloudnative-pg/cloudnative-pg/internal/configuration"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
@@ -57,6 +60,11 @@ const (
 	// the generated CA for the client certificates
 	ClientCaSecretSuffix = "-ca"
 
+	// ClientCertificateSecretSuffix is the suffix appended to the cluster
+	// name and the role name to get the name of the secret containing the
+	// client certificate generated for that role
+	ClientCertificateSecretSuffix = "-client-cert" // #nosec
+
 	// ServerSecretSuffix is the suffix appended to the secret containing
 	// the generated server secret for PostgreSQL
 	ServerSecretSuffix = "-server"
@@ -86,6 +94,10 @@ const (
 	// streaming replication purposes
 	StreamingReplicationUser = "streaming_replica"
 
+	// ReplicationSlotsUser is the name of the low-privileged user the
+	// instance manager may use to manage the local replication slots
+	ReplicationSlotsUser = "cnpg_replication_slots"
+
 	// defaultPostgresUID is the default UID which is used by PostgreSQL
 	defaultPostgresUID = 26
 
@@ -98,6 +110,23 @@ const (
 	// PodAntiAffinityTypePreferred is the label for preferred anti-affinity type
 	PodAntiAffinityTypePreferred = "preferred"
 
+	// MaxSharedBuffersPercentage is the maximum percentage of the memory of
+	// the PostgreSQL container that can be dedicated to shared_buffers
+	MaxSharedBuffersPercentage = 80
+
+	// MaxEffectiveCacheSizePercentage is the maximum percentage of the memory
+	// of the PostgreSQL container that can be used for effective_cache_size
+	MaxEffectiveCacheSizePercentage = 100
+
+	// MinIdleInTransactionSessionTimeout is the minimum number of seconds
+	// accepted for the idle_in_transaction_session_timeout parameter,
+	// unless the timeout is disabled
+	MinIdleInTransactionSessionTimeout = 10
+
+	// DefaultPrePromotionHookTimeout is the default maximum time to wait
+	// for the statements of the pre-promotion hook to complete
+	DefaultPrePromotionHookTimeout = 30 * time.Second
+
 	// DefaultPgBouncerPoolerSecretSuffix is the suffix for the default pgbouncer Pooler secret
 	DefaultPgBouncerPoolerSecretSuffix = "-pooler"
 
@@ -216,10 +245,74 @@ type ClusterSpec struct {
 	// +kubebuilder:default:=40000000
 	MaxSwitchoverDelay int32 `json:"switchoverDelay,omitempty"`
 
+	// The shutdown mode used when stopping PostgreSQL during planned
+	// operations, like restarts, fencing and switchovers. When the
+	// shutdown doesn't complete in time, the next faster mode is used.
+	// By default, a `smart` shutdown is requested for restarts and fencing,
+	// and a `fast` one for switchovers
+	// +kubebuilder:validation:Enum:=smart;fast;immediate
+	// +optional
+	ShutdownMode ShutdownMode `json:"shutdownMode,omitempty"`
+
+	// The time in seconds that is allowed for PostgreSQL to shut down
+	// with the requested shutdown mode during planned operations. By default,
+	// `stopDelay` is used for restarts and fencing, and `switchoverDelay`
+	// for switchovers
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ShutdownTimeout int32 `json:"shutdownTimeout,omitempty"`
+
+	// The time in seconds the kubelet waits for the instance to terminate
+	// before killing its containers when the Pod is deleted. It must not be
+	// shorter than the time PostgreSQL is allowed to shut down. By default,
+	// it's the longest between `stopDelay` and `shutdownTimeout`, plus a
+	// margin for the fallback to a faster shutdown mode
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// The amount of time (in seconds) to wait before triggering a failover
+	// after the primary PostgreSQL instance in the cluster was detected
+	// to be unhealthy. If the primary recovers within this time, no
+	// failover is triggered
+	// +kubebuilder:default:=0
+	// +optional
+	FailoverDelay int32 `json:"failoverDelay,omitempty"`
+
+	// The configuration of the readiness probe of the PostgreSQL instances
+	// +optional
+	Probes *ProbesConfiguration `json:"probes,omitempty"`
+
+	// Hints about the instance to be preferred as primary. When the cluster
+	// is healthy and the current primary doesn't match the preference, the
+	// operator switches over to a matching instance that is caught up
+	// +optional
+	PreferredPrimary *PreferredPrimaryConfiguration `json:"preferredPrimary,omitempty"`
+
+	// Configuration of the read-write service, pointing to the primary
+	// +optional
+	ReadWriteService *ServiceConfiguration `json:"readWriteService,omitempty"`
+
+	// Configuration of the read-only service, pointing to the replicas
+	// +optional
+	ReadOnlyService *ReadOnlyServiceConfiguration `json:"readOnlyService,omitempty"`
+
+	// Configuration of the read service, pointing to every ready instance
+	// +optional
+	ReadService *ServiceConfiguration `json:"readService,omitempty"`
+
 	// Affinity/Anti-affinity rules for Pods
 	// +optional
 	Affinity AffinityConfiguration `json:"affinity,omitempty"`
 
+	// TopologySpreadConstraints specifies how to spread the instances across
+	// the topology domains, i.e. zones. When a constraint has no label
+	// selector, it applies to the instances of this cluster.
+	// More info:
+	// https://kubernetes.io/docs/concepts/scheduling-eviction/topology-spread-constraints/
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
 	// Resources requirements of every generated Pod. Please refer to
 	// https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
 	// for more information.
@@ -240,6 +333,18 @@ type ClusterSpec struct {
 	// +kubebuilder:validation:Enum:=switchover;restart
 	PrimaryUpdateMethod PrimaryUpdateMethod `json:"primaryUpdateMethod,omitempty"`
 
+	// The time window during which the operator is allowed to perform
+	// disruptive operations, such as rolling updates and switchovers.
+	// Outside of this window these operations are deferred, while
+	// failovers due to a primary failure are always performed
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// The VACUUM and ANALYZE operations to be periodically executed
+	// on the primary instance, following a cron schedule
+	// +optional
+	ScheduledMaintenance []ScheduledMaintenance `json:"scheduledMaintenance,omitempty"`
+
 	// The configuration to be used for backups
 	Backup *BackupConfiguration `json:"backup,omitempty"`
 
@@ -271,12 +376,24 @@ const (
 	// PhaseCreatingReplica everytime we add a new replica
 	PhaseCreatingReplica = "Creating a new replica"
 
+	// PhaseRebuildingReplica when a replica is being destroyed to be
+	// rebuilt from the primary
+	PhaseRebuildingReplica = "Rebuilding a replica"
+
 	// PhaseUpgrade upgrade in process
 	PhaseUpgrade = "Upgrading cluster"
 
 	// PhaseWaitingForUser set the status to wait for an action from the user
 	PhaseWaitingForUser = "Waiting for user action"
 
+	// PhaseWaitingForMaintenanceWindow for a cluster having disruptive operations
+	// deferred until the next maintenance window
+	PhaseWaitingForMaintenanceWindow = "Waiting for the maintenance window"
+
+	// PhaseWaitingForRolloutSlot for a cluster whose rollout is queued, as
+	// the operator is already rolling out the maximum number of clusters
+	PhaseWaitingForRolloutSlot = "Waiting for a rollout slot"
+
 	// PhaseInplacePrimaryRestart for a cluster restarting the primary instance in-place
 	PhaseInplacePrimaryRestart = "Primary instance is being restarted in-place"
 
@@ -289,6 +406,27 @@ const (
 	// PhaseUnrecoverable for an unrecoverable cluster
 	PhaseUnrecoverable = "Cluster is in an unrecoverable state, needs manual intervention"
 
+	// PhaseJobFailed for a cluster whose bootstrap or replica creation
+	// job failed, for example because the post-init SQL scripts failed
+	PhaseJobFailed = "A job failed, needs manual intervention"
+
+	// PhaseMissingSharedPreloadLibraries for a cluster whose instances can't be
+	// restarted because some of the requested shared preload libraries are missing
+	PhaseMissingSharedPreloadLibraries = "Shared preload libraries are missing, needs manual intervention"
+
+	// PhaseHibernating for a cluster whose instances are being shut down
+	// because the hibernation has been requested
+	PhaseHibernating = "Cluster is being hibernated"
+
+	// PhaseHibernated for a cluster whose instances have been shut down,
+	// keeping the PVCs to allow resuming it later
+	PhaseHibernated = "Cluster in hibernation"
+
+	// PhaseReplicaClusterPromotion is the phase of a replica cluster whose
+	// replica mode has been disabled, waiting for the designated primary
+	// to be promoted
+	PhaseReplicaClusterPromotion = "Promoting the replica cluster"
+
 	// PhaseOnlineUpgrading for when the instance manager is being upgraded in place
 	PhaseOnlineUpgrading = "Online upgrade in progress"
 
@@ -341,6 +479,30 @@ type ClusterStatus struct {
 	// The timeline of the Postgres cluster
 	TimelineID int `json:"timelineID,omitempty"`
 
+	// The size in megabytes of the WAL segments of the cluster, as reported
+	// by the primary instance. It is chosen at bootstrap and can't be changed
+	WALSegmentSize int `json:"walSegmentSize,omitempty"`
+
+	// Whether the data checksums are enabled in the cluster, as reported
+	// by the primary instance. It is chosen at bootstrap and can't be changed
+	// +optional
+	DataChecksums *bool `json:"dataChecksums,omitempty"`
+
+	// The encoding of the databases, as reported by the primary instance.
+	// It is chosen at bootstrap and can't be changed
+	// +optional
+	Encoding string `json:"encoding,omitempty"`
+
+	// The collation (`LC_COLLATE`) of the databases, as reported by the
+	// primary instance. It is chosen at bootstrap and can't be changed
+	// +optional
+	LocaleCollate string `json:"localeCollate,omitempty"`
+
+	// The character classification (`LC_CTYPE`) of the databases, as reported
+	// by the primary instance. It is chosen at bootstrap and can't be changed
+	// +optional
+	LocaleCType string `json:"localeCType,omitempty"`
+
 	// Instances topology.
 	Topology Topology `json:"topology,omitempty"`
 
@@ -406,6 +568,41 @@ type ClusterStatus struct {
 	// The first recoverability point, stored as a date in RFC3339 format
 	FirstRecoverabilityPoint string `json:"firstRecoverabilityPoint,omitempty"`
 
+	// The status of the WAL archiving into the additional object stores,
+	// indexed by destination name
+	// +optional
+	WALArchiveDestinations map[string]WALArchiveDestinationStatus `json:"walArchiveDestinations,omitempty"`
+
+	// The name of the last WAL file successfully archived into the
+	// object store, as reported by pg_stat_archiver in the primary
+	// +optional
+	LastArchivedWAL string `json:"lastArchivedWAL,omitempty"`
+
+	// The LSN where the last WAL file successfully archived into the
+	// object store ends. Every change before this LSN is archived
+	// +optional
+	LastArchivedWALLSN string `json:"lastArchivedWALLSN,omitempty"`
+
+	// The time when the last WAL file was successfully archived into the
+	// object store, in RFC3339 format
+	// +optional
+	LastArchivedWALTime string `json:"lastArchivedWALTime,omitempty"`
+
+	// The roles imported from the source cluster while bootstrapping
+	// the cluster with the monolith import
+	// +optional
+	ImportedRoles []string `json:"importedRoles,omitempty"`
+
+	// The outcome of the last execution of each scheduled maintenance
+	// operation
+	// +optional
+	ScheduledMaintenance []ScheduledMaintenanceStatus `json:"scheduledMaintenance,omitempty"`
+
+	// The outcome of the last scheduled enforcement of the backup
+	// retention policy
+	// +optional
+	RetentionPolicy *RetentionPolicyStatus `json:"retentionPolicy,omitempty"`
+
 	// The commit hash number of which this operator running
 	CommitHash string `json:"cloudNativePGCommitHash,omitempty"`
 
@@ -415,6 +612,12 @@ type ClusterStatus struct {
 	// The timestamp when the last request for a new primary has occurred
 	TargetPrimaryTimestamp string `json:"targetPrimaryTimestamp,omitempty"`
 
+	// The timestamp when the primary was detected to be unhealthy.
+	// This field is reported only when spec.failoverDelay is populated
+	// and a failover is pending
+	// +optional
+	CurrentPrimaryFailingSinceTimestamp string `json:"currentPrimaryFailingSinceTimestamp,omitempty"`
+
 	// The integration needed by poolers referencing the cluster
 	PoolerIntegrations *PoolerIntegrations `json:"poolerIntegrations,omitempty"`
 
@@ -427,6 +630,18 @@ type ClusterStatus struct {
 	// AzurePVCUpdateEnabled shows if the PVC online upgrade is enabled for this cluster
 	AzurePVCUpdateEnabled bool `json:"azurePVCUpdateEnabled,omitempty"`
 
+	// The list of disruptive operations which have been deferred
+	// until the next maintenance window
+	// +optional
+	PendingMaintenanceOperations []string `json:"pendingMaintenanceOperations,omitempty"`
+
+	// The instance which was the primary when the cluster was hibernated.
+	// It is reported while the cluster is being resumed, and the instance
+	// manager uses it to verify that the former primary was shut down
+	// cleanly before starting it again
+	// +optional
+	HibernationResumePrimary string `json:"hibernationResumePrimary,omitempty"`
+
 	// Conditions for cluster object
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
@@ -440,6 +655,12 @@ type InstanceReportedState struct {
 	IsPrimary bool `json:"isPrimary"`
 	// indicates on which TimelineId the instance is
 	TimeLineID int `json:"timeLineID,omitempty"`
+	// the configuration parameters whose new value will be
+	// applied only after a restart of the instance
+	PendingRestartSettings []string `json:"pendingRestartSettings,omitempty"`
+	// the number of WAL files waiting to be archived, i.e. the backlog
+	// of the WAL archiving process of the instance
+	ReadyWALFiles int `json:"readyWalFiles,omitempty"`
 }
 
 // ClusterConditionType defines types of cluster conditions
@@ -454,6 +675,18 @@ const (
 	ConditionBackup ClusterConditionType = "LastBackupSucceeded"
 	// ConditionClusterReady represents whether a cluster is Ready
 	ConditionClusterReady ClusterConditionType = "Ready"
+	// ConditionCredentialsRotated represents whether the credentials inherited
+	// from the source cluster have been regenerated during the recovery
+	ConditionCredentialsRotated ClusterConditionType = "CredentialsRotated"
+	// ConditionWaitingForUser represents whether the operator can't make
+	// progress on the cluster without a manual intervention
+	ConditionWaitingForUser ClusterConditionType = "WaitingForUser"
+	// ConditionReplicationSlotsHealthy represents whether the HA replication
+	// slots of every replica are synchronized with the ones in the primary
+	ConditionReplicationSlotsHealthy ClusterConditionType = "ReplicationSlotsHealthy"
+	// ConditionPrePromotionHook represents whether the pre-promotion hook
+	// succeeded the last time an instance has been promoted
+	ConditionPrePromotionHook ClusterConditionType = "PrePromotionHookSucceeded"
 )
 
 // ConditionStatus defines conditions of resources
@@ -494,6 +727,59 @@ const (
 	// the WAL archiving is not working correctly
 	ConditionReasonContinuousArchivingFailing ConditionReason = "ContinuousArchivingFailing"
 
+	// ConditionReasonCredentialsRegenerated means that the condition changed because the
+	// credentials inherited from the source cluster have been regenerated during the recovery
+	ConditionReasonCredentialsRegenerated ConditionReason = "CredentialsRegenerated"
+
+	// ConditionReasonSupervisedSwitchoverRequired means that the user needs to
+	// issue a switchover or a restart of the primary to complete a rolling update
+	ConditionReasonSupervisedSwitchoverRequired ConditionReason = "SupervisedSwitchoverRequired"
+
+	// ConditionReasonJobFailed means that a job creating an instance failed
+	// and won't be retried
+	ConditionReasonJobFailed ConditionReason = "JobFailed"
+
+	// ConditionReasonClusterUnrecoverable means that no instance of the cluster
+	// is active and the operator can't recover it
+	ConditionReasonClusterUnrecoverable ConditionReason = "ClusterUnrecoverable"
+
+	// ConditionReasonMissingSharedPreloadLibraries means that some of the requested
+	// shared preload libraries can't be found in the image used by the instances
+	ConditionReasonMissingSharedPreloadLibraries ConditionReason = "MissingSharedPreloadLibraries"
+
+	// ConditionReasonNoUserActionRequired means that the operator can make
+	// progress on the cluster without a manual intervention
+	ConditionReasonNoUserActionRequired ConditionReason = "NoUserActionRequired"
+
+	// ConditionReasonReplicationSlotsConverged means that the HA replication slots
+	// of every replica are aligned with the ones in the primary
+	ConditionReasonReplicationSlotsConverged ConditionReason = "ReplicationSlotsConverged"
+
+	// ConditionReasonReplicationSlotsNotConverged means that the HA replication slots
+	// of some replicas are not aligned with the ones in the primary
+	ConditionReasonReplicationSlotsNotConverged ConditionReason = "ReplicationSlotsNotConverged"
+
+	// ConditionReasonReplicationSlotsSynchronizationFailing means that the
+	// synchronization of the HA replication slots is failing in some replicas
+	ConditionReasonReplicationSlotsSynchronizationFailing ConditionReason = "ReplicationSlotsSynchronizationFailing"
+
+	// ConditionReasonReplicationSlotsSynchronizationPaused means that the
+	// synchronization of the HA replication slots is paused
+	ConditionReasonReplicationSlotsSynchronizationPaused ConditionReason = "ReplicationSlotsSynchronizationPaused"
+
+	// ConditionReasonReplicationSlotsStatusUnknown means that the convergence
+	// of the HA replication slots can't be determined for some replicas,
+	// because they are unreachable or haven't synchronized them yet
+	ConditionReasonReplicationSlotsStatusUnknown ConditionReason = "ReplicationSlotsStatusUnknown"
+
+	// ConditionReasonPrePromotionHookCompleted means that the statements of the
+	// pre-promotion hook have been run successfully on the promoted instance
+	ConditionReasonPrePromotionHookCompleted ConditionReason = "PrePromotionHookCompleted"
+
+	// ConditionReasonPrePromotionHookFailed means that the statements of the
+	// pre-promotion hook failed on the instance being promoted
+	ConditionReasonPrePromotionHookFailed ConditionReason = "PrePromotionHookFailed"
+
 	// ClusterReady means that the condition changed because the cluster is ready and working properly
 	ClusterReady ConditionReason = "ClusterIsReady"
 
@@ -501,6 +787,114 @@ const (
 	ClusterIsNotReady ConditionReason = "ClusterIsNotReady"
 )
 
+// waitingForUserReasons maps the phases requiring a manual intervention
+// to the reason reported in the WaitingForUser condition
+var waitingForUserReasons = map[string]ConditionReason{
+	PhaseWaitingForUser: ConditionReasonSupervisedSwitchoverRequired,
+	PhaseJobFailed:      ConditionReasonJobFailed,
+	PhaseUnrecoverable:  ConditionReasonClusterUnrecoverable,
+
+	PhaseMissingSharedPreloadLibraries: ConditionReasonMissingSharedPreloadLibraries,
+}
+
+// GetWaitingForUserCondition returns the WaitingForUser condition
+// corresponding to the passed phase of the cluster
+func GetWaitingForUserCondition(phase, phaseReason string) metav1.Condition {
+	reason, waitingForUser := waitingForUserReasons[phase]
+	if !waitingForUser {
+		return metav1.Condition{
+			Type:    string(ConditionWaitingForUser),
+			Status:  metav1.ConditionFalse,
+			Reason:  string(ConditionReasonNoUserActionRequired),
+			Message: "No manual intervention is required",
+		}
+	}
+
+	return metav1.Condition{
+		Type:    string(ConditionWaitingForUser),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(reason),
+		Message: phaseReason,
+	}
+}
+
+// GetReplicationSlotsCondition returns the ReplicationSlotsHealthy condition,
+// aggregating the convergence of the HA replication slots reported by the
+// replicas. The current primary, named by the caller, is excluded even when
+// it is in recovery, as happens to the designated primary of a replica
+// cluster. Replicas whose convergence is unknown take precedence over
+// synchronization errors, which take precedence over a paused synchronization
+// and diverging slots
+func GetReplicationSlotsCondition(
+	statuses postgres.PostgresqlStatusList,
+	currentPrimary string,
+) metav1.Condition {
+	var unknownInstances, failingInstances, pausedInstances, divergingInstances []string
+	for _, item := range statuses.Items {
+		if item.IsPrimary || item.Pod.Name == currentPrimary {
+			continue
+		}
+
+		convergence := item.SlotsConvergence
+		switch {
+		case item.Error != nil || convergence == nil:
+			unknownInstances = append(unknownInstances, item.Pod.Name)
+		case convergence.Error != "":
+			failingInstances = append(failingInstances, item.Pod.Name)
+		case convergence.Paused:
+			pausedInstances = append(pausedInstances, item.Pod.Name)
+		case !convergence.Converged:
+			divergingInstances = append(divergingInstances, item.Pod.Name)
+		}
+	}
+
+	switch {
+	case len(unknownInstances) > 0:
+		return metav1.Condition{
+			Type:   string(ConditionReplicationSlotsHealthy),
+			Status: metav1.ConditionFalse,
+			Reason: string(ConditionReasonReplicationSlotsStatusUnknown),
+			Message: fmt.Sprintf("The replication slots status is unknown on: %s",
+				strings.Join(unknownInstances, ", ")),
+		}
+
+	case len(failingInstances) > 0:
+		return metav1.Condition{
+			Type:   string(ConditionReplicationSlotsHealthy),
+			Status: metav1.ConditionFalse,
+			Reason: string(ConditionReasonReplicationSlotsSynchronizationFailing),
+			Message: fmt.Sprintf("The replication slots synchronization is failing on: %s",
+				strings.Join(failingInstances, ", ")),
+		}
+
+	case len(pausedInstances) > 0:
+		return metav1.Condition{
+			Type:   string(ConditionReplicationSlotsHealthy),
+			Status: metav1.ConditionFalse,
+			Reason: string(ConditionReasonReplicationSlotsSynchronizationPaused),
+			Message: fmt.Sprintf("The replication slots synchronization is paused on: %s",
+				strings.Join(pausedInstances, ", ")),
+		}
+
+	case len(divergingInstances) > 0:
+		return metav1.Condition{
+			Type:   string(ConditionReplicationSlotsHealthy),
+			Status: metav1.ConditionFalse,
+			Reason: string(ConditionReasonReplicationSlotsNotConverged),
+			Message: fmt.Sprintf("The replication slots are not aligned with the primary on: %s",
+				strings.Join(divergingInstances, ", ")),
+		}
+
+	default:
+		return metav1.Condition{
+			Type:    string(ConditionReplicationSlotsHealthy),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(ConditionReasonReplicationSlotsConverged),
+			Message: "The replication slots of every replica are aligned with the primary",
+		}
+	}
+}
+
 // EmbeddedObjectMetadata contains metadata to be inherited by all resources related to a Cluster
 type EmbeddedObjectMetadata struct {
 	Labels      map[string]string `json:"labels,omitempty"`
@@ -515,6 +909,11 @@ type PoolerIntegrations struct {
 // PgBouncerIntegrationStatus encapsulates the needed integration for the pgbouncer poolers referencing the cluster
 type PgBouncerIntegrationStatus struct {
 	Secrets []string `json:"secrets,omitempty"`
+
+	// True when the primary has the user and the function used by
+	// the auth_query in every database
+	// +optional
+	AuthQueryReady bool `json:"authQueryReady,omitempty"`
 }
 
 // ReplicaClusterConfiguration encapsulates the configuration of a replica
@@ -535,9 +934,29 @@ type ReplicaClusterConfiguration struct {
 // DefaultReplicationSlotsUpdateInterval is the default in seconds for the replication slots update interval
 const DefaultReplicationSlotsUpdateInterval = 30
 
+// MinimumReplicationSlotsUpdateInterval is the shortest interval in seconds
+// between two synchronizations of the replication slots, as every
+// synchronization queries the primary
+const MinimumReplicationSlotsUpdateInterval = 10
+
 // DefaultReplicationSlotsHASlotPrefix is the default prefix for names of replication slots used for HA.
 const DefaultReplicationSlotsHASlotPrefix = "_cnpg_"
 
+// DefaultReplicationSlotsDatabase is the default database used to manage the replication slots
+const DefaultReplicationSlotsDatabase = "postgres"
+
+// DefaultReplicationSlotsOrphanReaperInterval is the default in seconds for the interval
+// between two runs of the orphan replication slots reaper
+const DefaultReplicationSlotsOrphanReaperInterval = 300
+
+// DefaultReplicationSlotsSynchronizationConcurrency is the default number
+// of operations on the local replication slots applied at the same time
+const DefaultReplicationSlotsSynchronizationConcurrency = 1
+
+// DefaultReplicationSlotsOrphanGracePeriod is the default in seconds for how long a
+// replication slot must be orphan before being dropped by the reaper
+const DefaultReplicationSlotsOrphanGracePeriod = 300
+
 // ReplicationSlotsConfiguration encapsulates the configuration
 // of replication slots
 type ReplicationSlotsConfiguration struct {
@@ -545,16 +964,140 @@ type ReplicationSlotsConfiguration struct {
 	HighAvailability *ReplicationSlotsHAConfiguration `json:"highAvailability,omitempty"`
 
 	// Standby will update the status of the local replication slots
-	// every `updateInterval` seconds (default 30).
+	// every `updateInterval` seconds (default 30, minimum 10).
 	//+kubebuilder:default:=30
 	//+kubebuilder:validation:Minimum=1
 	UpdateInterval int `json:"updateInterval,omitempty"`
+
+	// The database the instance manager connects to, both in the local instance
+	// and in the primary, to manage the replication slots (default `postgres`).
+	// The streaming replication user must be allowed to connect to it.
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// The primary drops the HA replication slots not belonging to
+	// any instance of the cluster every `orphanReaperInterval` seconds
+	// (default 300), independently of `updateInterval`.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	OrphanReaperInterval int `json:"orphanReaperInterval,omitempty"`
+
+	// How many seconds a HA replication slot must have been without
+	// a matching instance before being dropped by the reaper (default 300).
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	OrphanGracePeriod int `json:"orphanGracePeriod,omitempty"`
+
+	// How many operations on the local replication slots a standby applies
+	// at the same time while synchronizing them with the primary (default 1,
+	// at most 32).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32
+	// +optional
+	SynchronizationConcurrency int `json:"synchronizationConcurrency,omitempty"`
+
+	// What a standby does with the local replication slots which don't
+	// exist anymore in the primary: `delete` (default) drops them, while
+	// `disable` keeps them for a manual cleanup, advancing them to the
+	// current replay position so that they don't retain WAL files
+	// +kubebuilder:validation:Enum=delete;disable
+	// +optional
+	ExtraSlotsPolicy ExtraSlotsPolicy `json:"extraSlotsPolicy,omitempty"`
+
+	// When enabled, the instance manager manages the local replication slots
+	// connecting as the `cnpg_replication_slots` role, which has only the
+	// `REPLICATION` attribute, instead of the superuser (default false)
+	// +optional
+	UseDedicatedRole bool `json:"useDedicatedRole,omitempty"`
+
+	// When paused, the standbys keep comparing their replication slots with
+	// the ones in the primary, but don't create, advance, disable or drop
+	// them until the synchronization is resumed (default false)
+	// +optional
+	Paused bool `json:"paused,omitempty"`
 }
 
-// GetUpdateInterval returns the update interval, defaulting to DefaultReplicationSlotsUpdateInterval if empty
+// ExtraSlotsPolicy describes what happens to the local replication slots
+// of a standby which don't exist anymore in the primary
+type ExtraSlotsPolicy string
+
+const (
+	// ExtraSlotsPolicyDelete means that the extra replication slots
+	// are dropped
+	ExtraSlotsPolicyDelete ExtraSlotsPolicy = "delete"
+
+	// ExtraSlotsPolicyDisable means that the extra replication slots are
+	// kept, following the replay position so that they don't retain WAL
+	ExtraSlotsPolicyDisable ExtraSlotsPolicy = "disable"
+)
+
+// GetOrphanReaperInterval returns the interval between two runs of the orphan
+// replication slots reaper, defaulting to DefaultReplicationSlotsOrphanReaperInterval
+func (r *ReplicationSlotsConfiguration) GetOrphanReaperInterval() time.Duration {
+	if r == nil || r.OrphanReaperInterval <= 0 {
+		return DefaultReplicationSlotsOrphanReaperInterval * time.Second
+	}
+	return time.Duration(r.OrphanReaperInterval) * time.Second
+}
+
+// GetOrphanGracePeriod returns how long a replication slot must be orphan before
+// being dropped, defaulting to DefaultReplicationSlotsOrphanGracePeriod
+func (r *ReplicationSlotsConfiguration) GetOrphanGracePeriod() time.Duration {
+	if r == nil || r.OrphanGracePeriod <= 0 {
+		return DefaultReplicationSlotsOrphanGracePeriod * time.Second
+	}
+	return time.Duration(r.OrphanGracePeriod) * time.Second
+}
+
+// GetSynchronizationConcurrency returns how many operations on the local
+// replication slots are applied at the same time, defaulting to
+// DefaultReplicationSlotsSynchronizationConcurrency
+func (r *ReplicationSlotsConfiguration) GetSynchronizationConcurrency() int {
+	if r == nil || r.SynchronizationConcurrency <= 0 {
+		return DefaultReplicationSlotsSynchronizationConcurrency
+	}
+	return r.SynchronizationConcurrency
+}
+
+// GetExtraSlotsPolicy returns what happens to the local replication slots
+// which don't exist anymore in the primary, defaulting to `delete`
+func (r *ReplicationSlotsConfiguration) GetExtraSlotsPolicy() ExtraSlotsPolicy {
+	if r == nil || r.ExtraSlotsPolicy == "" {
+		return ExtraSlotsPolicyDelete
+	}
+	return r.ExtraSlotsPolicy
+}
+
+// IsDedicatedRoleEnabled checks if the local replication slots are managed
+// using the ReplicationSlotsUser role instead of the superuser
+func (r *ReplicationSlotsConfiguration) IsDedicatedRoleEnabled() bool {
+	return r != nil && r.UseDedicatedRole
+}
+
+// IsPaused checks if the synchronization of the replication slots
+// has been temporarily paused
+func (r *ReplicationSlotsConfiguration) IsPaused() bool {
+	return r != nil && r.Paused
+}
+
+// GetDatabase returns the database used to manage the replication slots,
+// defaulting to DefaultReplicationSlotsDatabase if empty
+func (r *ReplicationSlotsConfiguration) GetDatabase() string {
+	if r == nil || r.Database == "" {
+		return DefaultReplicationSlotsDatabase
+	}
+	return r.Database
+}
+
+// GetUpdateInterval returns the update interval, defaulting to DefaultReplicationSlotsUpdateInterval if empty.
+// Intervals shorter than MinimumReplicationSlotsUpdateInterval are raised to it
 func (r *ReplicationSlotsConfiguration) GetUpdateInterval() time.Duration {
 	if r == nil || r.UpdateInterval <= 0 {
-		return DefaultReplicationSlotsUpdateInterval
+		return DefaultReplicationSlotsUpdateInterval * time.Second
+	}
+	if r.UpdateInterval < MinimumReplicationSlotsUpdateInterval {
+		return MinimumReplicationSlotsUpdateInterval * time.Second
 	}
 	return time.Duration(r.UpdateInterval) * time.Second
 }
@@ -646,6 +1189,100 @@ type NodeMaintenanceWindow struct {
 	ReusePVC *bool `json:"reusePVC"`
 }
 
+// MaintenanceWindow defines the time window during which the operator
+// is allowed to perform disruptive operations on the cluster
+type MaintenanceWindow struct {
+	// The days of the week when the window starts, as lowercase English
+	// names (i.e. `monday`). When empty, the window starts every day
+	// +optional
+	Days []string `json:"days,omitempty"`
+
+	// The time of the day when the window starts, in the `HH:MM` format
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	StartTime string `json:"startTime"`
+
+	// The time of the day when the window ends, in the `HH:MM` format.
+	// When it precedes the start time, the window ends on the following day
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	EndTime string `json:"endTime"`
+
+	// The IANA time zone the start and end times refer to,
+	// i.e. `Europe/Rome`. Defaults to `UTC`
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// MaintenanceOperation is the operation executed by a scheduled maintenance
+type MaintenanceOperation string
+
+const (
+	// MaintenanceOperationVacuum runs VACUUM
+	MaintenanceOperationVacuum MaintenanceOperation = "vacuum"
+
+	// MaintenanceOperationAnalyze runs ANALYZE
+	MaintenanceOperationAnalyze MaintenanceOperation = "analyze"
+
+	// MaintenanceOperationVacuumAnalyze runs VACUUM (ANALYZE)
+	MaintenanceOperationVacuumAnalyze MaintenanceOperation = "vacuumAnalyze"
+)
+
+// ScheduledMaintenance defines a VACUUM or ANALYZE operation periodically
+// executed by the instance manager of the primary
+type ScheduledMaintenance struct {
+	// The name of the scheduled maintenance, unique in the cluster
+	Name string `json:"name"`
+
+	// The schedule in Cron format, see https://pkg.go.dev/github.com/robfig/cron#hdr-CRON_Expression_Format.
+	Schedule string `json:"schedule"`
+
+	// The operation to be executed, `vacuum`, `analyze` or `vacuumAnalyze`
+	// +kubebuilder:validation:Enum:=vacuum;analyze;vacuumAnalyze
+	Operation MaintenanceOperation `json:"operation"`
+
+	// The database where the operation is executed
+	Database string `json:"database"`
+
+	// The tables to be processed, optionally schema-qualified
+	// (i.e. `public.orders`). When empty, the whole database is processed
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+}
+
+// ScheduledMaintenanceStatus is the outcome of the last execution of
+// a scheduled maintenance operation
+type ScheduledMaintenanceStatus struct {
+	// The name of the scheduled maintenance
+	Name string `json:"name"`
+
+	// The time when the operation was last started, in RFC3339 format
+	// +optional
+	LastRunTime string `json:"lastRunTime,omitempty"`
+
+	// The duration of the last execution of the operation
+	// +optional
+	LastRunDuration string `json:"lastRunDuration,omitempty"`
+
+	// The error reported by the last execution, if any
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ShutdownMode is the way PostgreSQL is asked to shut down
+type ShutdownMode string
+
+const (
+	// ShutdownModeSmart waits for all the clients to disconnect
+	ShutdownModeSmart ShutdownMode = "smart"
+
+	// ShutdownModeFast terminates the client connections, rolling back
+	// the open transactions, and proceeds with a clean shutdown
+	ShutdownModeFast ShutdownMode = "fast"
+
+	// ShutdownModeImmediate aborts all the server processes, without a
+	// clean shutdown, requiring a crash recovery at the next start
+	ShutdownModeImmediate ShutdownMode = "immediate"
+)
+
 // PrimaryUpdateStrategy contains the strategy to follow when upgrading
 // the primary server of the cluster as part of rolling updates
 type PrimaryUpdateStrategy string
@@ -681,6 +1318,16 @@ const (
 	// is gracefully shutdown during a switchover.
 	// It is greater than one year in seconds, big enough to simulate an infinite timeout
 	DefaultMaxSwitchoverDelay = 40000000
+
+	// DefaultTerminationGracePeriodMargin is the time in seconds added to the
+	// shutdown timeout of PostgreSQL to compute the default termination grace
+	// period of the instance Pods, leaving room for a faster shutdown mode
+	// when the requested one doesn't complete in time
+	DefaultTerminationGracePeriodMargin = 10
+
+	// DefaultSlowQueryEventsSamplingInterval is the default minimum time in
+	// seconds between two slow query events emitted by the same instance
+	DefaultSlowQueryEventsSamplingInterval = 300
 )
 
 // PostgresConfiguration defines the PostgreSQL configuration
@@ -710,8 +1357,334 @@ type PostgresConfiguration struct {
 	// Options to specify LDAP configuration
 	// +optional
 	LDAP *LDAPConfig `json:"ldap,omitempty"`
+
+	// The format of the log files written by the PostgreSQL logging
+	// collector, that are parsed by the instance manager and emitted to the
+	// standard output in JSON format. Can be `csvlog` (default) or `jsonlog`,
+	// which requires PostgreSQL 15 or higher
+	// +kubebuilder:validation:Enum=csvlog;jsonlog
+	// +optional
+	LogFormat LogFormat `json:"logFormat,omitempty"`
+
+	// The TCP keepalive settings applied by PostgreSQL to the client
+	// connections, including the replication ones
+	// +optional
+	TCPKeepalives *TCPKeepalivesConfiguration `json:"tcpKeepalives,omitempty"`
+
+	// The minimum amount of WAL files retained in the `pg_wal` directory,
+	// regardless of the replication slots, expressed as a quantity
+	// (e.g. `1Gi`). It is translated into `wal_keep_size`, or into
+	// `wal_keep_segments` before PostgreSQL 13, overriding the parameter
+	// set in the configuration
+	// +optional
+	WALKeepSize string `json:"walKeepSize,omitempty"`
+
+	// The amount of memory used by PostgreSQL for `shared_buffers`,
+	// expressed as a percentage of the memory limit of the PostgreSQL
+	// container, or of its memory request when no limit is set. It
+	// overrides the parameter set in the configuration
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=80
+	// +optional
+	SharedBuffersPercentage *int32 `json:"sharedBuffersPercentage,omitempty"`
+
+	// The value of `effective_cache_size`, expressed as a percentage of
+	// the memory limit of the PostgreSQL container, or of its memory request
+	// when no limit is set. It overrides the parameter set in the configuration
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	EffectiveCacheSizePercentage *int32 `json:"effectiveCacheSizePercentage,omitempty"`
+
+	// The number of seconds after which PostgreSQL terminates the sessions
+	// which are idle within an open transaction. It is translated into
+	// `idle_in_transaction_session_timeout`, overriding the parameter set
+	// in the configuration. Zero disables the timeout, otherwise the value
+	// must be at least 10 seconds
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	IdleInTransactionSessionTimeout *int32 `json:"idleInTransactionSessionTimeout,omitempty"`
+
+	// The autovacuum settings, overriding the corresponding
+	// parameters set in the configuration
+	// +optional
+	Autovacuum *AutovacuumConfiguration `json:"autovacuum,omitempty"`
+
+	// The method used to compress the full page images written to the WAL.
+	// It is translated into `wal_compression`, overriding the parameter set
+	// in the configuration. Can be `off`, `on`, or, with PostgreSQL 15 or
+	// higher, one of the algorithms `pglz`, `lz4` and `zstd`, which must be
+	// available in the container image
+	// +kubebuilder:validation:Enum=off;on;pglz;lz4;zstd
+	// +optional
+	WALCompression WALCompressionMethod `json:"walCompression,omitempty"`
+
+	// The SQL statements to be run on an instance just before promoting
+	// it to primary, during a failover or a switchover
+	// +optional
+	PrePromotionHook *PrePromotionHookConfiguration `json:"prePromotionHook,omitempty"`
+
+	// The connections opened by the instance manager right after the
+	// promotion of an instance to primary, to warm up its connection pool
+	// +optional
+	ConnectionWarmUp *ConnectionWarmUpConfiguration `json:"connectionWarmUp,omitempty"`
+}
+
+// ConnectionWarmUpConfiguration defines how the connection pool of the
+// instance manager is warmed up after the promotion to primary
+type ConnectionWarmUpConfiguration struct {
+	// The number of connections to be opened
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	Connections int32 `json:"connections"`
+
+	// The database the connections are opened to. Default: `postgres`
+	// +optional
+	Database string `json:"database,omitempty"`
+}
+
+// GetDatabase gets the database the connections are opened to
+func (warmUp *ConnectionWarmUpConfiguration) GetDatabase() string {
+	if warmUp.Database == "" {
+		return "postgres"
+	}
+
+	return warmUp.Database
+}
+
+// PrePromotionHookConfiguration contains the SQL statements run by the
+// instance manager on the instance being promoted, just before the promotion
+type PrePromotionHookConfiguration struct {
+	// The SQL statements to be run, in the `postgres` database, as the
+	// superuser. As the instance is still a standby, only read-only
+	// statements can succeed
+	// +kubebuilder:validation:MinItems=1
+	SQL []string `json:"sql"`
+
+	// The maximum number of seconds to wait for the statements
+	// to complete. Default: 30
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Timeout int32 `json:"timeout,omitempty"`
+
+	// Whether the promotion must be aborted when the statements fail.
+	// The promotion is retried, together with the statements, at the next
+	// reconciliation loop. By default, the failure is reported and the
+	// promotion proceeds
+	// +optional
+	AbortOnFailure bool `json:"abortOnFailure,omitempty"`
+}
+
+// GetTimeout gets the maximum time to wait for the statements of the
+// pre-promotion hook to complete
+func (hook *PrePromotionHookConfiguration) GetTimeout() time.Duration {
+	if hook.Timeout <= 0 {
+		return DefaultPrePromotionHookTimeout
+	}
+
+	return time.Duration(hook.Timeout) * time.Second
+}
+
+// TCPKeepalivesConfiguration contains the TCP keepalive settings of
+// PostgreSQL. A value of zero selects the operating system default
+type TCPKeepalivesConfiguration struct {
+	// Number of seconds of inactivity after which TCP should send a
+	// keepalive message to the client (`tcp_keepalives_idle`)
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Idle *int32 `json:"idle,omitempty"`
+
+	// Number of seconds after which a TCP keepalive message that is not
+	// acknowledged by the client should be retransmitted (`tcp_keepalives_interval`)
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Interval *int32 `json:"interval,omitempty"`
+
+	// Number of TCP keepalive messages that can be lost before the
+	// connection is considered dead (`tcp_keepalives_count`)
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Count *int32 `json:"count,omitempty"`
+}
+
+// AutovacuumConfiguration contains the most common autovacuum settings
+type AutovacuumConfiguration struct {
+	// The maximum number of autovacuum worker processes running at
+	// the same time (`autovacuum_max_workers`). Changing it requires
+	// a restart of the instances
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=262143
+	// +optional
+	MaxWorkers *int32 `json:"maxWorkers,omitempty"`
+
+	// The minimum delay, in seconds, between autovacuum runs on any
+	// given database (`autovacuum_naptime`)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=2147483
+	// +optional
+	Naptime *int32 `json:"naptime,omitempty"`
+
+	// The cost limit used in automatic vacuum operations
+	// (`autovacuum_vacuum_cost_limit`). -1 means that the value of
+	// `vacuum_cost_limit` is used
+	// +kubebuilder:validation:Minimum=-1
+	// +kubebuilder:validation:Maximum=10000
+	// +optional
+	VacuumCostLimit *int32 `json:"vacuumCostLimit,omitempty"`
+
+	// The fraction of the table size, between 0 and 100, to add to the
+	// threshold when deciding whether to trigger a vacuum
+	// (`autovacuum_vacuum_scale_factor`), i.e. `0.05`
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	VacuumScaleFactor string `json:"vacuumScaleFactor,omitempty"`
+
+	// The fraction of the table size, between 0 and 100, to add to the
+	// threshold when deciding whether to trigger an analyze
+	// (`autovacuum_analyze_scale_factor`), i.e. `0.02`
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	AnalyzeScaleFactor string `json:"analyzeScaleFactor,omitempty"`
+}
+
+// PreferredPrimaryConfiguration contains the hints used by the operator
+// to choose the instance that should act as primary when the cluster is
+// healthy. Only one of the options can be specified
+type PreferredPrimaryConfiguration struct {
+	// The serial number of the instance to be preferred as primary,
+	// i.e. 2 for the instance named `<cluster-name>-2`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	InstanceSerial int `json:"instanceSerial,omitempty"`
+
+	// The zone where the primary should preferably run, matched against
+	// the `topology.kubernetes.io/zone` label of the nodes
+	// +optional
+	Zone string `json:"zone,omitempty"`
+}
+
+// DefaultProbesDatabase is the default database the readiness probe connects to
+const DefaultProbesDatabase = "postgres"
+
+// ProbesConfiguration contains the configuration of the readiness probe
+// of the PostgreSQL instances, which connects to a database as the
+// superuser and optionally runs a query
+type ProbesConfiguration struct {
+	// The database the readiness probe connects to, defaults to `postgres`
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// The query run by the readiness probe, which must complete without
+	// errors for the instance to be ready. When not set, the readiness
+	// probe just checks the connection to the database
+	// +optional
+	Query string `json:"query,omitempty"`
+}
+
+// GetDatabase gets the database the readiness probe connects to
+func (p *ProbesConfiguration) GetDatabase() string {
+	if p == nil || p.Database == "" {
+		return DefaultProbesDatabase
+	}
+	return p.Database
+}
+
+// GetQuery gets the query run by the readiness probe, if any
+func (p *ProbesConfiguration) GetQuery() string {
+	if p == nil {
+		return ""
+	}
+	return p.Query
+}
+
+// ServiceConfiguration contains the configuration of a service
+// generated by the operator for the cluster
+type ServiceConfiguration struct {
+	// The type of the service, which can be `ClusterIP` (default),
+	// `NodePort` or `LoadBalancer`
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// When true, the service is created without a cluster IP address
+	// (headless service). Only valid with the `ClusterIP` type, and cannot
+	// be changed once the cluster has been created
+	// +optional
+	Headless bool `json:"headless,omitempty"`
+
+	// Annotations to be added to the service, i.e. the ones required
+	// by the load balancer of the cloud provider
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels to be added to the service
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// GetType gets the type of the service, defaulting to `ClusterIP`
+func (s *ServiceConfiguration) GetType() corev1.ServiceType {
+	if s == nil || s.Type == "" {
+		return corev1.ServiceTypeClusterIP
+	}
+	return s.Type
+}
+
+// IsHeadless checks whether the service should be created without
+// a cluster IP address
+func (s *ServiceConfiguration) IsHeadless() bool {
+	return s != nil && s.Headless
 }
 
+// ReadOnlyServiceConfiguration contains the configuration of the
+// read-only (`-ro`) service of the cluster
+type ReadOnlyServiceConfiguration struct {
+	// The configuration of the service
+	ServiceConfiguration `json:",inline"`
+
+	// The maximum amount of WAL, i.e. `16Mi`, that a replica may not have
+	// flushed yet to be included in the read-only service. When not set,
+	// every replica is included regardless of its lag
+	// +optional
+	MaxLag string `json:"maxLag,omitempty"`
+}
+
+// LogFormat is the format used by the PostgreSQL logging collector
+type LogFormat string
+
+const (
+	// LogFormatCSV is the CSV log format, available with every
+	// PostgreSQL version
+	LogFormatCSV LogFormat = "csvlog"
+
+	// LogFormatJSON is the JSON log format, available since PostgreSQL 15
+	LogFormatJSON LogFormat = "jsonlog"
+)
+
+// WALCompressionMethod is the method used to compress the full page
+// images written to the WAL
+type WALCompressionMethod string
+
+const (
+	// WALCompressionOff disables the compression of the WAL
+	WALCompressionOff WALCompressionMethod = "off"
+
+	// WALCompressionOn compresses the WAL with the default method
+	WALCompressionOn WALCompressionMethod = "on"
+
+	// WALCompressionPGLZ compresses the WAL with pglz, available
+	// since PostgreSQL 15
+	WALCompressionPGLZ WALCompressionMethod = "pglz"
+
+	// WALCompressionLZ4 compresses the WAL with lz4, available
+	// since PostgreSQL 15
+	WALCompressionLZ4 WALCompressionMethod = "lz4"
+
+	// WALCompressionZSTD compresses the WAL with zstd, available
+	// since PostgreSQL 15
+	WALCompressionZSTD WALCompressionMethod = "zstd"
+)
+
 // BootstrapConfiguration contains information about how to create the PostgreSQL
 // cluster. Only a single bootstrap method can be defined among the supported
 // ones. `initdb` will be used as the bootstrap method if left
@@ -822,6 +1795,13 @@ type CertificatesConfiguration struct {
 
 	// The list of the server alternative DNS names to be added to the generated server TLS certificates, when required.
 	ServerAltDNSNames []string `json:"serverAltDNSNames,omitempty"`
+
+	// The list of PostgreSQL roles for which the operator will issue a client
+	// certificate signed by the client CA, enabling the `cert` authentication
+	// method for them. The certificate of each role is stored in a secret
+	// of type kubernetes.io/tls named `<cluster>-<role>-client-cert`.
+	// Requires ClientCASecret to provide also `ca.key`.
+	ClientCertificateUsers []string `json:"clientCertificateUsers,omitempty"`
 }
 
 // CertificatesStatus contains configuration certificates and related expiration dates.
@@ -859,20 +1839,31 @@ type BootstrapInitDB struct {
 	Options []string `json:"options,omitempty"`
 
 	// Whether the `-k` option should be passed to initdb,
-	// enabling checksums on data pages (default: `false`)
+	// enabling checksums on data pages (default: `false`).
+	// It can't be changed after the bootstrap
 	DataChecksums *bool `json:"dataChecksums,omitempty"`
 
-	// The value to be passed as option `--encoding` for initdb (default:`UTF8`)
+	// The value to be passed as option `--encoding` for initdb (default:`UTF8`).
+	// It can't be changed after the bootstrap
 	Encoding string `json:"encoding,omitempty"`
 
-	// The value to be passed as option `--lc-collate` for initdb (default:`C`)
+	// The value to be passed as option `--locale` for initdb, setting the
+	// default for all the locale categories, unless overridden by
+	// `localeCollate` and `localeCType`. It can't be changed after the bootstrap
+	// +optional
+	Locale string `json:"locale,omitempty"`
+
+	// The value to be passed as option `--lc-collate` for initdb (default:`C`).
+	// It can't be changed after the bootstrap
 	LocaleCollate string `json:"localeCollate,omitempty"`
 
-	// The value to be passed as option `--lc-ctype` for initdb (default:`C`)
+	// The value to be passed as option `--lc-ctype` for initdb (default:`C`).
+	// It can't be changed after the bootstrap
 	LocaleCType string `json:"localeCType,omitempty"`
 
 	// The value in megabytes (1 to 1024) to be passed to the `--wal-segsize`
-	// option for initdb (default: empty, resulting in PostgreSQL default: 16MB)
+	// option for initdb (default: empty, resulting in PostgreSQL default: 16MB).
+	// It must be a power of 2 and can't be changed after the bootstrap
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=1024
 	WalSegmentSize int `json:"walSegmentSize,omitempty"`
@@ -930,12 +1921,31 @@ type Import struct {
 	// The roles to import
 	Roles []string `json:"roles,omitempty"`
 
+	// How to handle the passwords of the imported roles: `keep` (default)
+	// copies them from the source, `reset` imports the roles without a
+	// password. Only available in monolith type.
+	// +kubebuilder:validation:Enum=keep;reset
+	// +optional
+	RolePasswords ImportRolePasswordsPolicy `json:"rolePasswords,omitempty"`
+
 	// List of SQL queries to be executed as a superuser in the application
 	// database right after is imported - to be used with extreme care
 	// (by default empty). Only available in microservice type.
 	PostImportApplicationSQL []string `json:"postImportApplicationSQL,omitempty"`
 }
 
+// ImportRolePasswordsPolicy describes how the passwords of the imported
+// roles are handled
+type ImportRolePasswordsPolicy string
+
+const (
+	// ImportRolePasswordsKeep copies the passwords of the roles from the source
+	ImportRolePasswordsKeep ImportRolePasswordsPolicy = "keep"
+
+	// ImportRolePasswordsReset imports the roles without a password
+	ImportRolePasswordsReset ImportRolePasswordsPolicy = "reset"
+)
+
 // ImportSource describes the source for the logical snapshot
 type ImportSource struct {
 	// The name of the externalCluster used for import
@@ -990,6 +2000,14 @@ type BootstrapRecovery struct {
 	// created from scratch
 	// +optional
 	Secret *LocalObjectReference `json:"secret,omitempty"`
+
+	// When enabled, the passwords of the superuser and of the owner of the
+	// application database inherited from the source cluster are replaced
+	// by the ones stored in the secrets of the new cluster at the end of the
+	// recovery, before the cluster is opened to the applications.
+	// Not allowed for replica clusters. Default: `false`.
+	// +optional
+	RegenerateCredentials bool `json:"regenerateCredentials,omitempty"`
 }
 
 // BackupSource contains the backup we need to restore from, plus some
@@ -1079,8 +2097,29 @@ type StorageConfiguration struct {
 	// Template to be used to generate the Persistent Volume Claim
 	// +optional
 	PersistentVolumeClaimTemplate *corev1.PersistentVolumeClaimSpec `json:"pvcTemplate,omitempty"`
+
+	// What happens to the generated PVCs when the cluster is deleted:
+	// `delete` (default) removes them together with the cluster, while
+	// `retain` keeps them, detaching them from the cluster
+	// +kubebuilder:validation:Enum=delete;retain
+	// +optional
+	PVCReclaimPolicy PVCReclaimPolicy `json:"pvcReclaimPolicy,omitempty"`
 }
 
+// PVCReclaimPolicy describes what happens to the PVCs of a cluster
+// when the cluster is deleted
+type PVCReclaimPolicy string
+
+const (
+	// PVCReclaimPolicyDelete means that the PVCs are deleted together
+	// with the cluster
+	PVCReclaimPolicyDelete PVCReclaimPolicy = "delete"
+
+	// PVCReclaimPolicyRetain means that the PVCs are kept after the
+	// cluster is deleted
+	PVCReclaimPolicyRetain PVCReclaimPolicy = "retain"
+)
+
 // SyncReplicaElectionConstraints contains the constraints for sync replicas election.
 //
 // For anti-affinity parameters two instances are considered in the same location
@@ -1137,6 +2176,24 @@ type AffinityConfiguration struct {
 	// AdditionalPodAffinity allows to specify pod affinity terms to be passed to all the cluster's pods.
 	// +optional
 	AdditionalPodAffinity *corev1.PodAffinity `json:"additionalPodAffinity,omitempty"`
+
+	// InstanceNodeSelectors allows to restrict specific instances to a set of
+	// nodes, i.e. to pin them to a zone. The labels are added to the ones
+	// in NodeSelector, taking precedence over them.
+	// +optional
+	InstanceNodeSelectors []InstanceNodeSelector `json:"instanceNodeSelectors,omitempty"`
+}
+
+// InstanceNodeSelector contains the node selector to be used
+// for a specific instance of the cluster
+type InstanceNodeSelector struct {
+	// The serial number of the instance, i.e. `2` for `cluster-example-2`
+	// +kubebuilder:validation:Minimum=1
+	InstanceSerial int `json:"instanceSerial"`
+
+	// Map of key-value pairs used to define the nodes
+	// on which the instance can run
+	NodeSelector map[string]string `json:"nodeSelector"`
 }
 
 // RollingUpdateStatus contains the information about an instance which is
@@ -1164,6 +2221,10 @@ const (
 
 	// CompressionTypeSnappy means snappy compression is performed
 	CompressionTypeSnappy = CompressionType("snappy")
+
+	// CompressionTypeZstd means zstd compression is performed. It is
+	// only available for the base backups
+	CompressionTypeZstd = CompressionType("zstd")
 )
 
 // EncryptionType encapsulated the available types of encryption
@@ -1241,6 +2302,100 @@ type BarmanObjectStoreConfiguration struct {
 	// HistoryTags is a list of key value pairs that will be passed to the
 	// Barman --history-tags option.
 	HistoryTags map[string]string `json:"historyTags,omitempty"`
+
+	// Additional environment variables passed to the barman-cloud
+	// commands, i.e. to set a proxy or provider specific options.
+	// Only `value`, `secretKeyRef` and `configMapKeyRef` are supported,
+	// and the variables managed by the operator can't be overridden
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Secrets and config maps whose keys are passed as additional
+	// environment variables to the barman-cloud commands. Keys that
+	// aren't valid variable names, or that are managed by the operator,
+	// are skipped
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// The HTTP proxy used by the barman-cloud commands to reach the
+	// object store. It overrides the proxy environment variables
+	// +optional
+	Proxy *ProxyConfiguration `json:"proxy,omitempty"`
+}
+
+// ProxyConfiguration defines the HTTP proxy used to reach an object store
+type ProxyConfiguration struct {
+	// The URL of the proxy, i.e. `http://proxy.example.com:3128`.
+	// It is used for both HTTP and HTTPS requests
+	URL string `json:"url"`
+
+	// The hosts, domains and networks which must be reached
+	// without going through the proxy
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// barmanManagedEnvVars are the environment variables set by the operator
+// for the barman-cloud commands
+var barmanManagedEnvVars = []string{
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"AWS_DEFAULT_REGION",
+	"AWS_CA_BUNDLE",
+	"REQUESTS_CA_BUNDLE",
+	"AZURE_STORAGE_ACCOUNT",
+	"AZURE_STORAGE_KEY",
+	"AZURE_STORAGE_SAS_TOKEN",
+	"AZURE_STORAGE_CONNECTION_STRING",
+	"GOOGLE_APPLICATION_CREDENTIALS",
+}
+
+// IsBarmanManagedEnvVar checks if the passed environment variable is set by
+// the operator for the barman-cloud commands, and can't be customized
+func IsBarmanManagedEnvVar(name string) bool {
+	return slices.Contains(barmanManagedEnvVars, name)
+}
+
+// barmanCustomizableEnvVarPrefixes are the prefixes of the environment
+// variables of the object store providers, which can be customized
+var barmanCustomizableEnvVarPrefixes = []string{
+	"AWS_",
+	"AZURE_",
+	"GOOGLE_",
+}
+
+// barmanCustomizableEnvVars are the environment variables, not belonging
+// to an object store provider, which can be customized
+var barmanCustomizableEnvVars = []string{
+	"HTTP_PROXY",
+	"HTTPS_PROXY",
+	"NO_PROXY",
+	"http_proxy",
+	"https_proxy",
+	"no_proxy",
+}
+
+// IsBarmanCustomizableEnvVar checks if the passed environment variable can
+// be set by the user for the barman-cloud commands. Only the variables of
+// the object store providers and the proxy ones are allowed, as the others,
+// like PATH or LD_PRELOAD, would change how the commands are run
+func IsBarmanCustomizableEnvVar(name string) bool {
+	if IsBarmanManagedEnvVar(name) {
+		return false
+	}
+
+	if slices.Contains(barmanCustomizableEnvVars, name) {
+		return true
+	}
+
+	for _, prefix := range barmanCustomizableEnvVarPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // BackupConfiguration defines how the backup of the cluster are taken.
@@ -1251,13 +2406,93 @@ type BackupConfiguration struct {
 	// The configuration for the barman-cloud tool suite
 	BarmanObjectStore *BarmanObjectStoreConfiguration `json:"barmanObjectStore,omitempty"`
 
-	// RetentionPolicy is the retention policy to be used for backups
-	// and WALs (i.e. '60d'). The retention policy is expressed in the form
-	// of `XXu` where `XX` is a positive integer and `u` is in `[dwm]` -
-	// days, weeks, months.
-	// +kubebuilder:validation:Pattern=^[1-9][0-9]*[dwm]$
+	// RetentionPolicy is the retention policy to be used for backups
+	// and WALs (i.e. '60d'). The retention policy is expressed in the form
+	// of `XXu` where `XX` is a positive integer and `u` is in `[dwm]` -
+	// days, weeks, months.
+	// +kubebuilder:validation:Pattern=^[1-9][0-9]*[dwm]$
+	// +optional
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
+
+	// The schedule in Cron format, see https://pkg.go.dev/github.com/robfig/cron#hdr-CRON_Expression_Format,
+	// on which the instance manager of the primary enforces the retention
+	// policy against the object store. When empty, the retention policy
+	// is only applied after every completed backup
+	// +optional
+	RetentionPolicySchedule string `json:"retentionPolicySchedule,omitempty"`
+
+	// The list of additional object stores where the WAL files are
+	// archived together with the one defined in `barmanObjectStore`,
+	// i.e. for disaster recovery purposes
+	// +optional
+	AdditionalWALArchives []WALArchiveDestination `json:"additionalWalArchives,omitempty"`
+
+	// When enabled, every completed backup is verified against the
+	// object store, reading its metadata again and downloading the WAL
+	// files needed to make it consistent. Disabled by default, as it
+	// requires additional round trips to the object store
+	// +optional
+	Verify bool `json:"verify,omitempty"`
+}
+
+// RetentionPolicyStatus is the outcome of the last scheduled
+// enforcement of the backup retention policy
+type RetentionPolicyStatus struct {
+	// The time when the retention policy was last enforced, in RFC3339 format
+	// +optional
+	LastRunTime string `json:"lastRunTime,omitempty"`
+
+	// The IDs of the backups deleted from the object store by the
+	// last enforcement of the retention policy
+	// +optional
+	DeletedBackups []string `json:"deletedBackups,omitempty"`
+
+	// The error reported by the last enforcement, if any
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// WALArchiveDestination is an additional object store where the WAL
+// files are archived
+type WALArchiveDestination struct {
+	// The name of the destination, used to report its status
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Name string `json:"name"`
+
+	// The configuration of the object store. Only the WAL related options
+	// are taken into account
+	BarmanObjectStore BarmanObjectStoreConfiguration `json:"barmanObjectStore"`
+
+	// When true, a failure while archiving a WAL file into this destination
+	// is reported but doesn't prevent PostgreSQL from considering the WAL
+	// file archived. Otherwise, the WAL file needs to be archived into every
+	// destination to succeed. `false` by default.
+	// +optional
+	BestEffort bool `json:"bestEffort,omitempty"`
+}
+
+// WALArchiveDestinationStatus is the status of the WAL archiving into
+// an additional object store
+type WALArchiveDestinationStatus struct {
+	// The name of the last WAL file archived into the destination
+	// +optional
+	LastArchivedWAL string `json:"lastArchivedWAL,omitempty"`
+
+	// The time when the last WAL file was archived into the destination
+	// +optional
+	LastArchivedTime string `json:"lastArchivedTime,omitempty"`
+
+	// The name of the last WAL file that couldn't be archived into the destination
+	// +optional
+	LastFailedWAL string `json:"lastFailedWAL,omitempty"`
+
+	// The time of the last failure while archiving into the destination
+	// +optional
+	LastFailedTime string `json:"lastFailedTime,omitempty"`
+
+	// The error raised by the last failure while archiving into the destination
 	// +optional
-	RetentionPolicy string `json:"retentionPolicy,omitempty"`
+	LastFailedError string `json:"lastFailedError,omitempty"`
 }
 
 // WalBackupConfiguration is the configuration of the backup of the
@@ -1283,6 +2518,48 @@ type WalBackupConfiguration struct {
 	// value - with 1 being the minimum accepted value.
 	// +kubebuilder:validation:Minimum=1
 	MaxParallel int `json:"maxParallel,omitempty"`
+
+	// Number of WAL files to be restored in parallel when a PostgreSQL
+	// instance is fetching WAL files from the object store during recovery,
+	// overriding maxParallel. The WAL files that are not immediately
+	// requested by PostgreSQL are prefetched into a spool directory.
+	// It accepts a value between 1 and 64.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=64
+	// +optional
+	RestoreMaxParallel int `json:"restoreMaxParallel,omitempty"`
+
+	// Number of consecutive WAL archiving failures after which the
+	// `ContinuousArchiving` condition of the cluster is set to false and
+	// a warning event is raised, to detect a broken archive before the
+	// WAL files fill the volume. When not set, no threshold is applied
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConsecutiveFailures int `json:"maxConsecutiveFailures,omitempty"`
+}
+
+// GetRestoreMaxParallel returns the number of WAL files to be restored in
+// parallel, which is restoreMaxParallel if set and maxParallel otherwise
+func (w *WalBackupConfiguration) GetRestoreMaxParallel() int {
+	if w == nil {
+		return 1
+	}
+	if w.RestoreMaxParallel > 0 {
+		return w.RestoreMaxParallel
+	}
+	if w.MaxParallel > 1 {
+		return w.MaxParallel
+	}
+	return 1
+}
+
+// GetMaxConsecutiveFailures returns the number of consecutive WAL archiving
+// failures which are reported as critical, or zero if there's no threshold
+func (w *WalBackupConfiguration) GetMaxConsecutiveFailures() int {
+	if w == nil {
+		return 0
+	}
+	return w.MaxConsecutiveFailures
 }
 
 // DataBackupConfiguration is the configuration of the backup of
@@ -1290,8 +2567,8 @@ type WalBackupConfiguration struct {
 type DataBackupConfiguration struct {
 	// Compress a backup file (a tar file per tablespace) while streaming it
 	// to the object store. Available options are empty string (no
-	// compression, default), `gzip`, `bzip2` or `snappy`.
-	// +kubebuilder:validation:Enum=gzip;bzip2;snappy
+	// compression, default), `gzip`, `bzip2`, `snappy` or `zstd`.
+	// +kubebuilder:validation:Enum=gzip;bzip2;snappy;zstd
 	Compression CompressionType `json:"compression,omitempty"`
 
 	// Whenever to force the encryption of files (if the bucket is
@@ -1312,6 +2589,33 @@ type DataBackupConfiguration struct {
 	// to 2
 	// +kubebuilder:validation:Minimum=1
 	Jobs *int32 `json:"jobs,omitempty"`
+
+	// The maximum amount of data to be uploaded per second while taking
+	// a base backup, expressed as a quantity of bytes (i.e. `50Mi`).
+	// The bandwidth is not limited by default. Requires Barman >= 3.0
+	// +optional
+	MaxBandwidth string `json:"maxBandwidth,omitempty"`
+}
+
+// GetMaxBandwidth returns the maximum number of bytes to be uploaded per
+// second while taking a base backup, or zero when the bandwidth is not limited
+func (configuration *DataBackupConfiguration) GetMaxBandwidth() (int64, error) {
+	if configuration == nil || configuration.MaxBandwidth == "" {
+		return 0, nil
+	}
+
+	quantity, err := resource.ParseQuantity(configuration.MaxBandwidth)
+	if err != nil {
+		return 0, err
+	}
+
+	maxBandwidth, ok := quantity.AsInt64()
+	if !ok || maxBandwidth <= 0 {
+		return 0, fmt.Errorf("the maximum bandwidth must be a positive number of bytes: %v",
+			configuration.MaxBandwidth)
+	}
+
+	return maxBandwidth, nil
 }
 
 // S3Credentials is the type for the credentials to be used to upload
@@ -1397,6 +2701,44 @@ type MonitoringConfiguration struct {
 	// Enable or disable the `PodMonitor`
 	// +kubebuilder:default:=false
 	EnablePodMonitor bool `json:"enablePodMonitor,omitempty"`
+
+	// Configure TLS communication for the metrics endpoint.
+	// Changing this setting requires the instances to be recreated
+	// +optional
+	TLSConfig *ClusterMonitoringTLSConfiguration `json:"tls,omitempty"`
+
+	// Configure the events reporting the statements logged by PostgreSQL
+	// for exceeding `log_min_duration_statement`
+	// +optional
+	SlowQueryEvents *SlowQueryEventsConfiguration `json:"slowQueryEvents,omitempty"`
+}
+
+// SlowQueryEventsConfiguration is the configuration of the events reporting
+// the slow queries logged by each instance
+type SlowQueryEventsConfiguration struct {
+	// Emit an event on the cluster reporting the number of slow queries
+	// logged by an instance and the slowest of them, at most once per
+	// sampling interval
+	// +kubebuilder:default:=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// The minimum time in seconds between two slow query events
+	// emitted by the same instance (default 300)
+	// +kubebuilder:default:=300
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	SamplingInterval int32 `json:"samplingInterval,omitempty"`
+}
+
+// ClusterMonitoringTLSConfiguration is the type containing the TLS configuration
+// for the cluster's monitoring
+type ClusterMonitoringTLSConfiguration struct {
+	// Enable TLS for the monitoring endpoint, using the server
+	// certificate of the cluster, which is signed by the server CA.
+	// +kubebuilder:default:=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
 }
 
 // AreDefaultQueriesDisabled checks whether default monitoring queries should be disabled
@@ -1404,6 +2746,20 @@ func (m *MonitoringConfiguration) AreDefaultQueriesDisabled() bool {
 	return m != nil && m.DisableDefaultQueries != nil && *m.DisableDefaultQueries
 }
 
+// AreSlowQueryEventsEnabled checks whether the slow queries should be reported as events
+func (m *MonitoringConfiguration) AreSlowQueryEventsEnabled() bool {
+	return m != nil && m.SlowQueryEvents != nil && m.SlowQueryEvents.Enabled
+}
+
+// GetSlowQueryEventsSamplingInterval gets the minimum time between two
+// slow query events emitted by the same instance
+func (m *MonitoringConfiguration) GetSlowQueryEventsSamplingInterval() time.Duration {
+	if m == nil || m.SlowQueryEvents == nil || m.SlowQueryEvents.SamplingInterval <= 0 {
+		return DefaultSlowQueryEventsSamplingInterval * time.Second
+	}
+	return time.Duration(m.SlowQueryEvents.SamplingInterval) * time.Second
+}
+
 // ExternalCluster represents the connection parameters to an
 // external cluster which is used in the other sections of the configuration
 type ExternalCluster struct {
@@ -1707,6 +3063,22 @@ func (cluster *Cluster) GetReplicationSecretName() string {
 	return fmt.Sprintf("%v%v", cluster.Name, ReplicationSecretSuffix)
 }
 
+// GetClientCertificateSecretName get the name of the secret containing the
+// client certificate generated for the passed PostgreSQL role. Underscores,
+// which are not allowed in Kubernetes object names, are replaced by dashes
+func (cluster *Cluster) GetClientCertificateSecretName(user string) string {
+	return fmt.Sprintf("%v-%v%v", cluster.Name, strings.ReplaceAll(user, "_", "-"), ClientCertificateSecretSuffix)
+}
+
+// GetClientCertificateUsers get the list of PostgreSQL roles for which
+// a client certificate has to be issued
+func (cluster *Cluster) GetClientCertificateUsers() []string {
+	if cluster.Spec.Certificates == nil {
+		return nil
+	}
+	return cluster.Spec.Certificates.ClientCertificateUsers
+}
+
 // GetServiceAnyName return the name of the service that is used as DNS
 // domain for all the nodes, even if they are not ready
 func (cluster *Cluster) GetServiceAnyName() string {
@@ -1747,6 +3119,116 @@ func (cluster *Cluster) GetMaxStopDelay() int32 {
 	return 30
 }
 
+// GetShutdownTimeout gets the longest amount of time PostgreSQL is
+// allowed to shut down, between stopDelay and shutdownTimeout
+func (cluster *Cluster) GetShutdownTimeout() int32 {
+	if cluster.Spec.ShutdownTimeout > cluster.GetMaxStopDelay() {
+		return cluster.Spec.ShutdownTimeout
+	}
+	return cluster.GetMaxStopDelay()
+}
+
+// GetTerminationGracePeriodSeconds gets the termination grace period of the
+// instance Pods, defaulting to the shutdown timeout plus a safety margin
+func (cluster *Cluster) GetTerminationGracePeriodSeconds() int64 {
+	if cluster.Spec.TerminationGracePeriodSeconds != nil {
+		return *cluster.Spec.TerminationGracePeriodSeconds
+	}
+	return int64(cluster.GetShutdownTimeout()) + DefaultTerminationGracePeriodMargin
+}
+
+// GetWALArchiveMaxConsecutiveFailures returns the number of consecutive WAL
+// archiving failures which are reported as critical, or zero if there's no
+// threshold or WAL archiving is not configured
+func (cluster *Cluster) GetWALArchiveMaxConsecutiveFailures() int {
+	if cluster.Spec.Backup == nil || cluster.Spec.Backup.BarmanObjectStore == nil {
+		return 0
+	}
+	return cluster.Spec.Backup.BarmanObjectStore.Wal.GetMaxConsecutiveFailures()
+}
+
+// SetLastArchivedWAL updates the last archived WAL file with the one
+// reported by pg_stat_archiver in the primary instance. History, backup
+// and partial files are ignored, as they don't mark any progress in the
+// archived WAL stream, and the status never goes back to an older WAL file
+func (status *ClusterStatus) SetLastArchivedWAL(walName string, archivedTime string, segmentSize int64) {
+	if !postgres.IsWALFile(walName) || walName <= status.LastArchivedWAL {
+		return
+	}
+
+	var walSegmentSize *int64
+	if segmentSize != 0 {
+		walSegmentSize = &segmentSize
+	}
+
+	status.LastArchivedWAL = walName
+	status.LastArchivedWALLSN = string(postgres.MustSegmentFromName(walName).EndLSN(walSegmentSize))
+	status.LastArchivedWALTime = archivedTime
+	if timestamp, err := time.Parse(time.RFC3339Nano, archivedTime); err == nil {
+		status.LastArchivedWALTime = timestamp.UTC().Format(time.RFC3339)
+	}
+}
+
+// SetWALArchiveDestinationsStatus updates the status of the additional WAL
+// archives with the one reported by the primary instance. Destinations not
+// reported by the primary, which may have been just promoted, keep their
+// status, while the ones not defined anymore are dropped
+func (cluster *Cluster) SetWALArchiveDestinationsStatus(
+	reported map[string]postgres.WALArchiveDestinationStatus,
+) {
+	var result map[string]WALArchiveDestinationStatus
+	if cluster.Spec.Backup != nil && len(cluster.Spec.Backup.AdditionalWALArchives) > 0 {
+		result = make(map[string]WALArchiveDestinationStatus, len(cluster.Spec.Backup.AdditionalWALArchives))
+		for _, destination := range cluster.Spec.Backup.AdditionalWALArchives {
+			if status, ok := reported[destination.Name]; ok {
+				result[destination.Name] = WALArchiveDestinationStatus(status)
+			} else if status, ok := cluster.Status.WALArchiveDestinations[destination.Name]; ok {
+				result[destination.Name] = status
+			}
+		}
+	}
+
+	cluster.Status.WALArchiveDestinations = result
+}
+
+// GetReadWriteServiceConfiguration gets the configuration of the
+// read-write service, if any
+func (cluster *Cluster) GetReadWriteServiceConfiguration() *ServiceConfiguration {
+	return cluster.Spec.ReadWriteService
+}
+
+// GetReadOnlyServiceConfiguration gets the configuration of the
+// read-only service, if any
+func (cluster *Cluster) GetReadOnlyServiceConfiguration() *ServiceConfiguration {
+	if cluster.Spec.ReadOnlyService == nil {
+		return nil
+	}
+	return &cluster.Spec.ReadOnlyService.ServiceConfiguration
+}
+
+// GetReadServiceConfiguration gets the configuration of the
+// read service, if any
+func (cluster *Cluster) GetReadServiceConfiguration() *ServiceConfiguration {
+	return cluster.Spec.ReadService
+}
+
+// GetReadOnlyServiceMaxLag gets the maximum amount of WAL, in bytes, that
+// a replica may not have flushed yet to be included in the read-only service,
+// and whether the read-only service should exclude the lagging replicas
+func (cluster *Cluster) GetReadOnlyServiceMaxLag() (int64, bool) {
+	if cluster.Spec.ReadOnlyService == nil || cluster.Spec.ReadOnlyService.MaxLag == "" {
+		return 0, false
+	}
+
+	maxLag, err := resource.ParseQuantity(cluster.Spec.ReadOnlyService.MaxLag)
+	if err != nil {
+		// This error will be raised by the validating webhook
+		return 0, false
+	}
+
+	return maxLag.Value(), true
+}
+
 // GetMaxSwitchoverDelay get the amount of time PostgreSQL has to stop before switchover
 func (cluster *Cluster) GetMaxSwitchoverDelay() int32 {
 	if cluster.Spec.MaxSwitchoverDelay > 0 {
@@ -1777,11 +3259,334 @@ func (cluster *Cluster) GetPrimaryUpdateMethod() PrimaryUpdateMethod {
 	return strategy
 }
 
+// GetLogFormat get the format used by the PostgreSQL logging collector,
+// defaulting to csvlog
+func (cluster *Cluster) GetLogFormat() LogFormat {
+	format := cluster.Spec.PostgresConfiguration.LogFormat
+	if format == "" {
+		return LogFormatCSV
+	}
+
+	return format
+}
+
+// GetTCPKeepalivesParameters gets the PostgreSQL parameters corresponding
+// to the TCP keepalive settings of the cluster
+func (cluster *Cluster) GetTCPKeepalivesParameters() map[string]string {
+	keepalives := cluster.Spec.PostgresConfiguration.TCPKeepalives
+	if keepalives == nil {
+		return nil
+	}
+
+	parameters := make(map[string]string)
+	if keepalives.Idle != nil {
+		parameters["tcp_keepalives_idle"] = strconv.Itoa(int(*keepalives.Idle))
+	}
+	if keepalives.Interval != nil {
+		parameters["tcp_keepalives_interval"] = strconv.Itoa(int(*keepalives.Interval))
+	}
+	if keepalives.Count != nil {
+		parameters["tcp_keepalives_count"] = strconv.Itoa(int(*keepalives.Count))
+	}
+
+	return parameters
+}
+
+// GetAutovacuumParameters gets the PostgreSQL parameters
+// corresponding to the autovacuum settings requested in the cluster
+func (cluster *Cluster) GetAutovacuumParameters() map[string]string {
+	autovacuum := cluster.Spec.PostgresConfiguration.Autovacuum
+	if autovacuum == nil {
+		return nil
+	}
+
+	parameters := make(map[string]string)
+	if autovacuum.MaxWorkers != nil {
+		parameters["autovacuum_max_workers"] = strconv.Itoa(int(*autovacuum.MaxWorkers))
+	}
+	if autovacuum.Naptime != nil {
+		parameters["autovacuum_naptime"] = fmt.Sprintf("%ds", *autovacuum.Naptime)
+	}
+	if autovacuum.VacuumCostLimit != nil {
+		parameters["autovacuum_vacuum_cost_limit"] = strconv.Itoa(int(*autovacuum.VacuumCostLimit))
+	}
+	if autovacuum.VacuumScaleFactor != "" {
+		parameters["autovacuum_vacuum_scale_factor"] = autovacuum.VacuumScaleFactor
+	}
+	if autovacuum.AnalyzeScaleFactor != "" {
+		parameters["autovacuum_analyze_scale_factor"] = autovacuum.AnalyzeScaleFactor
+	}
+
+	return parameters
+}
+
+// GetWALKeepSizeParameters gets the PostgreSQL parameters retaining the
+// amount of WAL files requested in the cluster, given the PostgreSQL version
+func (cluster *Cluster) GetWALKeepSizeParameters(majorVersion int) map[string]string {
+	if cluster.Spec.PostgresConfiguration.WALKeepSize == "" {
+		return nil
+	}
+
+	walKeepSize, err := resource.ParseQuantity(cluster.Spec.PostgresConfiguration.WALKeepSize)
+	if err != nil {
+		// This error will be raised by the validating webhook
+		return nil
+	}
+
+	// PostgreSQL expects the size in megabytes, rounded up
+	const megabyte = 1024 * 1024
+	walKeepSizeMB := (walKeepSize.Value() + megabyte - 1) / megabyte
+
+	if majorVersion >= 130000 {
+		return map[string]string{
+			"wal_keep_size": fmt.Sprintf("%dMB", walKeepSizeMB),
+		}
+	}
+
+	walSegmentSizeMB := int64(16)
+	if cluster.Spec.Bootstrap != nil && cluster.Spec.Bootstrap.InitDB != nil &&
+		cluster.Spec.Bootstrap.InitDB.WalSegmentSize != 0 {
+		walSegmentSizeMB = int64(cluster.Spec.Bootstrap.InitDB.WalSegmentSize)
+	}
+
+	return map[string]string{
+		"wal_keep_segments": strconv.FormatInt((walKeepSizeMB+walSegmentSizeMB-1)/walSegmentSizeMB, 10),
+	}
+}
+
+// GetIdleInTransactionSessionTimeout gets the value of the
+// idle_in_transaction_session_timeout parameter requested in the cluster,
+// or an empty string when it is not set
+func (cluster *Cluster) GetIdleInTransactionSessionTimeout() string {
+	timeout := cluster.Spec.PostgresConfiguration.IdleInTransactionSessionTimeout
+	if timeout == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%ds", *timeout)
+}
+
+// GetWALCompression gets the value of the wal_compression parameter
+// requested in the cluster, or an empty string when it is not set
+func (cluster *Cluster) GetWALCompression() string {
+	return string(cluster.Spec.PostgresConfiguration.WALCompression)
+}
+
+// GetMemoryParameters gets the PostgreSQL parameters which are expressed
+// as a percentage of the memory available to the PostgreSQL container
+func (cluster *Cluster) GetMemoryParameters() map[string]string {
+	config := cluster.Spec.PostgresConfiguration
+	if config.SharedBuffersPercentage == nil && config.EffectiveCacheSizePercentage == nil {
+		return nil
+	}
+
+	memory := cluster.GetMemoryForPercentageParameters()
+	if memory == nil {
+		// This error will be raised by the validating webhook
+		return nil
+	}
+
+	parameters := make(map[string]string)
+	if config.SharedBuffersPercentage != nil {
+		parameters["shared_buffers"] = getMemoryPercentage(
+			memory, *config.SharedBuffersPercentage, MaxSharedBuffersPercentage)
+	}
+	if config.EffectiveCacheSizePercentage != nil {
+		parameters["effective_cache_size"] = getMemoryPercentage(
+			memory, *config.EffectiveCacheSizePercentage, MaxEffectiveCacheSizePercentage)
+	}
+
+	return parameters
+}
+
+// GetMemoryForPercentageParameters gets the amount of memory the parameters
+// expressed as a percentage are resolved against: the memory limit of the
+// PostgreSQL container, or its memory request when no limit is set.
+// Returns nil when neither of them is set
+func (cluster *Cluster) GetMemoryForPercentageParameters() *resource.Quantity {
+	if memory, ok := cluster.Spec.Resources.Limits[corev1.ResourceMemory]; ok && !memory.IsZero() {
+		return &memory
+	}
+	if memory, ok := cluster.Spec.Resources.Requests[corev1.ResourceMemory]; ok && !memory.IsZero() {
+		return &memory
+	}
+
+	return nil
+}
+
+// getMemoryPercentage computes the given percentage of the memory, clamping
+// the percentage to the allowed bounds and expressing the result in megabytes,
+// rounded down. The result is never smaller than one megabyte
+func getMemoryPercentage(memory *resource.Quantity, percentage int32, maxPercentage int32) string {
+	const megabyte = 1024 * 1024
+
+	if percentage < 1 {
+		percentage = 1
+	}
+	if percentage > maxPercentage {
+		percentage = maxPercentage
+	}
+
+	valueMB := memory.Value() * int64(percentage) / 100 / megabyte
+	if valueMB < 1 {
+		valueMB = 1
+	}
+
+	return fmt.Sprintf("%dMB", valueMB)
+}
+
+// GetInstanceNodeSelector returns the node selector to be used for
+// the instance with the passed serial number
+func (cluster *Cluster) GetInstanceNodeSelector(nodeSerial int) map[string]string {
+	for _, instanceSelector := range cluster.Spec.Affinity.InstanceNodeSelectors {
+		if instanceSelector.InstanceSerial != nodeSerial {
+			continue
+		}
+
+		result := make(map[string]string, len(cluster.Spec.Affinity.NodeSelector)+len(instanceSelector.NodeSelector))
+		for key, value := range cluster.Spec.Affinity.NodeSelector {
+			result[key] = value
+		}
+		for key, value := range instanceSelector.NodeSelector {
+			result[key] = value
+		}
+		return result
+	}
+
+	return cluster.Spec.Affinity.NodeSelector
+}
+
 // IsNodeMaintenanceWindowInProgress check if the upgrade mode is active or not
 func (cluster *Cluster) IsNodeMaintenanceWindowInProgress() bool {
 	return cluster.Spec.NodeMaintenanceWindow != nil && cluster.Spec.NodeMaintenanceWindow.InProgress
 }
 
+// maintenanceWindowDays maps the day names accepted in a
+// maintenance window to the corresponding weekday
+var maintenanceWindowDays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// GetLocation returns the time zone the maintenance window refers to
+func (window *MaintenanceWindow) GetLocation() (*time.Location, error) {
+	if window.TimeZone == "" {
+		return time.UTC, nil
+	}
+
+	return time.LoadLocation(window.TimeZone)
+}
+
+// startsOn checks if the maintenance window starts on the passed weekday
+func (window *MaintenanceWindow) startsOn(weekday time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+
+	for _, day := range window.Days {
+		if maintenanceWindowDays[day] == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getBoundaries returns the start and the end of the maintenance
+// window beginning on the day of the passed time
+func (window *MaintenanceWindow) getBoundaries(day time.Time) (time.Time, time.Time, error) {
+	startTime, err := time.Parse("15:04", window.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid maintenance window start time: %w", err)
+	}
+
+	endTime, err := time.Parse("15:04", window.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid maintenance window end time: %w", err)
+	}
+
+	year, month, dayOfMonth := day.Date()
+	start := time.Date(year, month, dayOfMonth, startTime.Hour(), startTime.Minute(), 0, 0, day.Location())
+	end := time.Date(year, month, dayOfMonth, endTime.Hour(), endTime.Minute(), 0, 0, day.Location())
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return start, end, nil
+}
+
+// IsActive checks if the passed time is inside the maintenance window
+func (window *MaintenanceWindow) IsActive(now time.Time) (bool, error) {
+	location, err := window.GetLocation()
+	if err != nil {
+		return false, err
+	}
+	now = now.In(location)
+
+	// A window starting on the previous day may still be open
+	for _, offset := range []int{-1, 0} {
+		day := now.AddDate(0, 0, offset)
+		if !window.startsOn(day.Weekday()) {
+			continue
+		}
+
+		start, end, err := window.getBoundaries(day)
+		if err != nil {
+			return false, err
+		}
+
+		if !now.Before(start) && now.Before(end) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GetNextStart returns the time when the next maintenance window
+// will start, after the passed time
+func (window *MaintenanceWindow) GetNextStart(now time.Time) (time.Time, error) {
+	location, err := window.GetLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	now = now.In(location)
+
+	for offset := 0; offset <= 7; offset++ {
+		day := now.AddDate(0, 0, offset)
+		if !window.startsOn(day.Weekday()) {
+			continue
+		}
+
+		start, _, err := window.getBoundaries(day)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if start.After(now) {
+			return start, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot find the next start of the maintenance window")
+}
+
+// IsInMaintenanceWindow checks if the operator is allowed to perform
+// disruptive operations on the cluster at the passed time. This is
+// always true when no maintenance window has been defined
+func (cluster *Cluster) IsInMaintenanceWindow(now time.Time) (bool, error) {
+	if cluster.Spec.MaintenanceWindow == nil {
+		return true, nil
+	}
+
+	return cluster.Spec.MaintenanceWindow.IsActive(now)
+}
+
 // GetPgCtlTimeoutForPromotion returns the timeout that should be waited for an instance to be promoted
 // to primary. As default, DefaultPgCtlTimeoutForPromotion is big enough to simulate an infinite timeout
 func (cluster *Cluster) GetPgCtlTimeoutForPromotion() int32 {
@@ -1918,6 +3723,20 @@ func (cluster *Cluster) ShouldPgBaseBackupCreateApplicationDatabase() bool {
 	return pgBaseBackupParameters.Owner != "" && pgBaseBackupParameters.Database != ""
 }
 
+// ShouldRecoveryRegenerateCredentials returns true if the credentials inherited
+// from the source cluster need to be regenerated at the end of the recovery job
+func (cluster *Cluster) ShouldRecoveryRegenerateCredentials() bool {
+	if cluster.IsReplica() {
+		return false
+	}
+
+	if cluster.Spec.Bootstrap == nil || cluster.Spec.Bootstrap.Recovery == nil {
+		return false
+	}
+
+	return cluster.Spec.Bootstrap.Recovery.RegenerateCredentials
+}
+
 // ShouldRecoveryCreateApplicationDatabase returns true if the application database needs to be created during the
 // recovery job
 func (cluster *Cluster) ShouldRecoveryCreateApplicationDatabase() bool {
@@ -1948,6 +3767,34 @@ func (cluster *Cluster) GetWalArchiveVolumeSuffix() string {
 	return "-wal"
 }
 
+// GetPVCReclaimPolicy gets the policy to be applied to the generated
+// PVCs when the cluster is deleted, defaulting to `delete`
+func (s *StorageConfiguration) GetPVCReclaimPolicy() PVCReclaimPolicy {
+	if s == nil || s.PVCReclaimPolicy == "" {
+		return PVCReclaimPolicyDelete
+	}
+	return s.PVCReclaimPolicy
+}
+
+// GetPVCReclaimPolicy gets the reclaim policy to be applied to the
+// PVCs having the passed role
+func (cluster *Cluster) GetPVCReclaimPolicy(role utils.PVCRole) PVCReclaimPolicy {
+	if role == utils.PVCRolePgWal {
+		return cluster.Spec.WalStorage.GetPVCReclaimPolicy()
+	}
+	return cluster.Spec.StorageConfiguration.GetPVCReclaimPolicy()
+}
+
+// ShouldRetainPVCs returns true when at least one kind of PVC
+// should be kept after the cluster is deleted
+func (cluster *Cluster) ShouldRetainPVCs() bool {
+	if cluster.GetPVCReclaimPolicy(utils.PVCRolePgData) == PVCReclaimPolicyRetain {
+		return true
+	}
+	return cluster.ShouldCreateWalArchiveVolume() &&
+		cluster.GetPVCReclaimPolicy(utils.PVCRolePgWal) == PVCReclaimPolicyRetain
+}
+
 // GetPostgresUID returns the UID that is being used for the "postgres"
 // user
 func (cluster Cluster) GetPostgresUID() int64 {
@@ -1988,15 +3835,21 @@ var slotNameNegativeRegex = regexp.MustCompile("[^a-z0-9_]+")
 // GetSlotNameFromInstanceName returns the slot name, given the instance name.
 // It returns an empty string if High Availability Replication Slots are disabled
 func (cluster Cluster) GetSlotNameFromInstanceName(instanceName string) string {
-	if cluster.Spec.ReplicationSlots == nil ||
-		cluster.Spec.ReplicationSlots.HighAvailability == nil ||
-		!cluster.Spec.ReplicationSlots.HighAvailability.Enabled {
+	if !cluster.IsReplicationSlotsHAEnabled() {
 		return ""
 	}
 
 	return cluster.Spec.ReplicationSlots.HighAvailability.GetSlotNameFromInstanceName(instanceName)
 }
 
+// IsReplicationSlotsHAEnabled checks if the High Availability Replication
+// Slots are enabled
+func (cluster Cluster) IsReplicationSlotsHAEnabled() bool {
+	return cluster.Spec.ReplicationSlots != nil &&
+		cluster.Spec.ReplicationSlots.HighAvailability != nil &&
+		cluster.Spec.ReplicationSlots.HighAvailability.Enabled
+}
+
 // GetBarmanEndpointCAForReplicaCluster checks if this is a replica cluster which needs barman endpoint CA
 func (cluster Cluster) GetBarmanEndpointCAForReplicaCluster() *SecretKeySelector {
 	if !cluster.IsReplica() {
@@ -2077,6 +3930,15 @@ func (cluster *Cluster) UsesConfigMap(config string) (ok bool) {
 	return false
 }
 
+// IsMetricsTLSEnabled checks if the metrics endpoint should use TLS
+func (cluster *Cluster) IsMetricsTLSEnabled() bool {
+	if cluster.Spec.Monitoring != nil && cluster.Spec.Monitoring.TLSConfig != nil {
+		return cluster.Spec.Monitoring.TLSConfig.Enabled
+	}
+
+	return false
+}
+
 // IsPodMonitorEnabled checks if the PodMonitor object needs to be created
 func (cluster *Cluster) IsPodMonitorEnabled() bool {
 	if cluster.Spec.Monitoring != nil {
@@ -17,8 +17,14 @@ limitations under the License.
 package v1
 
 import (
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -58,6 +64,232 @@ var _ = Describe("PostgreSQL cluster type", func() {
 	})
 })
 
+var _ = Describe("TCP keepalives parameters", func() {
+	It("are empty when not configured", func() {
+		cluster := Cluster{}
+		Expect(cluster.GetTCPKeepalivesParameters()).To(BeEmpty())
+	})
+
+	It("contain only the configured settings", func() {
+		idle := int32(60)
+		count := int32(0)
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					TCPKeepalives: &TCPKeepalivesConfiguration{
+						Idle:  &idle,
+						Count: &count,
+					},
+				},
+			},
+		}
+		Expect(cluster.GetTCPKeepalivesParameters()).To(Equal(map[string]string{
+			"tcp_keepalives_idle":  "60",
+			"tcp_keepalives_count": "0",
+		}))
+	})
+})
+
+var _ = Describe("WAL retention parameters", func() {
+	It("are empty when not configured", func() {
+		cluster := Cluster{}
+		Expect(cluster.GetWALKeepSizeParameters(150000)).To(BeEmpty())
+	})
+
+	It("use wal_keep_size since PostgreSQL 13", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					WALKeepSize: "1500Ki",
+				},
+			},
+		}
+		Expect(cluster.GetWALKeepSizeParameters(130000)).To(Equal(map[string]string{
+			"wal_keep_size": "2MB",
+		}))
+	})
+
+	It("use wal_keep_segments before PostgreSQL 13", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					WALKeepSize: "1Gi",
+				},
+			},
+		}
+		Expect(cluster.GetWALKeepSizeParameters(120000)).To(Equal(map[string]string{
+			"wal_keep_segments": "64",
+		}))
+
+		cluster.Spec.Bootstrap = &BootstrapConfiguration{
+			InitDB: &BootstrapInitDB{WalSegmentSize: 64},
+		}
+		Expect(cluster.GetWALKeepSizeParameters(120000)).To(Equal(map[string]string{
+			"wal_keep_segments": "16",
+		}))
+	})
+})
+
+var _ = Describe("idle in transaction session timeout", func() {
+	It("is empty when not configured", func() {
+		cluster := Cluster{}
+		Expect(cluster.GetIdleInTransactionSessionTimeout()).To(BeEmpty())
+	})
+
+	It("is expressed in seconds", func() {
+		timeout := int32(300)
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					IdleInTransactionSessionTimeout: &timeout,
+				},
+			},
+		}
+		Expect(cluster.GetIdleInTransactionSessionTimeout()).To(Equal("300s"))
+	})
+})
+
+var _ = Describe("autovacuum parameters", func() {
+	It("are empty when not configured", func() {
+		cluster := Cluster{}
+		Expect(cluster.GetAutovacuumParameters()).To(BeEmpty())
+	})
+
+	It("contain only the configured settings", func() {
+		maxWorkers := int32(5)
+		naptime := int32(30)
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					Autovacuum: &AutovacuumConfiguration{
+						MaxWorkers:        &maxWorkers,
+						Naptime:           &naptime,
+						VacuumScaleFactor: "0.05",
+					},
+				},
+			},
+		}
+		Expect(cluster.GetAutovacuumParameters()).To(Equal(map[string]string{
+			"autovacuum_max_workers":         "5",
+			"autovacuum_naptime":             "30s",
+			"autovacuum_vacuum_scale_factor": "0.05",
+		}))
+	})
+})
+
+var _ = Describe("pre-promotion hook", func() {
+	It("has a default timeout", func() {
+		hook := PrePromotionHookConfiguration{}
+		Expect(hook.GetTimeout()).To(Equal(DefaultPrePromotionHookTimeout))
+	})
+
+	It("uses the requested timeout", func() {
+		hook := PrePromotionHookConfiguration{Timeout: 5}
+		Expect(hook.GetTimeout()).To(Equal(5 * time.Second))
+	})
+})
+
+var _ = Describe("connection warm-up", func() {
+	It("uses the postgres database by default", func() {
+		warmUp := ConnectionWarmUpConfiguration{Connections: 2}
+		Expect(warmUp.GetDatabase()).To(Equal("postgres"))
+	})
+
+	It("uses the requested database", func() {
+		warmUp := ConnectionWarmUpConfiguration{Connections: 2, Database: "app"}
+		Expect(warmUp.GetDatabase()).To(Equal("app"))
+	})
+})
+
+var _ = Describe("Memory parameters expressed as a percentage", func() {
+	sharedBuffers := int32(25)
+	effectiveCacheSize := int32(75)
+
+	It("are empty when not configured", func() {
+		cluster := Cluster{}
+		Expect(cluster.GetMemoryParameters()).To(BeEmpty())
+	})
+
+	It("are empty when the memory of the container is unknown", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					SharedBuffersPercentage: &sharedBuffers,
+				},
+			},
+		}
+		Expect(cluster.GetMemoryParameters()).To(BeEmpty())
+	})
+
+	It("are resolved against the memory limit", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Resources: corev1.ResourceRequirements{
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+				PostgresConfiguration: PostgresConfiguration{
+					SharedBuffersPercentage:      &sharedBuffers,
+					EffectiveCacheSizePercentage: &effectiveCacheSize,
+				},
+			},
+		}
+		Expect(cluster.GetMemoryParameters()).To(Equal(map[string]string{
+			"shared_buffers":       "1024MB",
+			"effective_cache_size": "3072MB",
+		}))
+	})
+
+	It("are resolved against the memory request when there's no limit", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+				PostgresConfiguration: PostgresConfiguration{
+					SharedBuffersPercentage: &sharedBuffers,
+				},
+			},
+		}
+		Expect(cluster.GetMemoryParameters()).To(Equal(map[string]string{
+			"shared_buffers": "512MB",
+		}))
+	})
+
+	It("clamps the percentages to the allowed bounds", func() {
+		tooMuch := int32(95)
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1000Mi")},
+				},
+				PostgresConfiguration: PostgresConfiguration{
+					SharedBuffersPercentage: &tooMuch,
+				},
+			},
+		}
+		Expect(cluster.GetMemoryParameters()).To(Equal(map[string]string{
+			"shared_buffers": "800MB",
+		}))
+	})
+
+	It("never goes below one megabyte", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Mi")},
+				},
+				PostgresConfiguration: PostgresConfiguration{
+					SharedBuffersPercentage: &sharedBuffers,
+				},
+			},
+		}
+		Expect(cluster.GetMemoryParameters()).To(Equal(map[string]string{
+			"shared_buffers": "1MB",
+		}))
+	})
+})
+
 var _ = Describe("PostgreSQL services name", func() {
 	postgresql := Cluster{
 		ObjectMeta: v1.ObjectMeta{
@@ -392,6 +624,10 @@ var _ = Describe("look up for secrets", func() {
 	It("retrieves replication secret name", func() {
 		Expect(cluster.GetReplicationSecretName()).To(Equal("clustername-replication"))
 	})
+	It("retrieves the client certificate secret name of a role", func() {
+		Expect(cluster.GetClientCertificateSecretName("app")).To(Equal("clustername-app-client-cert"))
+		Expect(cluster.GetClientCertificateSecretName("app_user")).To(Equal("clustername-app-user-client-cert"))
+	})
 	It("retrieves all names needed to build a server CA certificate are 9", func() {
 		Expect(len(cluster.GetClusterAltDNSNames())).To(Equal(9))
 	})
@@ -843,3 +1079,457 @@ var _ = Describe("Replication slots names for instances", func() {
 			"_232_test_cluster_example_1"))
 	})
 })
+
+var _ = Describe("Maintenance window", func() {
+	// 2022-10-14 is a Friday
+	at := func(value string) time.Time {
+		result, err := time.Parse(time.RFC3339, value)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	It("allows disruptive operations when no window is defined", func() {
+		cluster := Cluster{}
+		Expect(cluster.IsInMaintenanceWindow(at("2022-10-14T12:00:00Z"))).To(BeTrue())
+	})
+
+	It("detects when the time is inside a daily window", func() {
+		window := MaintenanceWindow{StartTime: "02:00", EndTime: "04:00"}
+		Expect(window.IsActive(at("2022-10-14T01:59:00Z"))).To(BeFalse())
+		Expect(window.IsActive(at("2022-10-14T02:00:00Z"))).To(BeTrue())
+		Expect(window.IsActive(at("2022-10-14T03:59:00Z"))).To(BeTrue())
+		Expect(window.IsActive(at("2022-10-14T04:00:00Z"))).To(BeFalse())
+	})
+
+	It("only opens the window on the requested days", func() {
+		window := MaintenanceWindow{Days: []string{"saturday", "sunday"}, StartTime: "02:00", EndTime: "04:00"}
+		Expect(window.IsActive(at("2022-10-14T03:00:00Z"))).To(BeFalse())
+		Expect(window.IsActive(at("2022-10-15T03:00:00Z"))).To(BeTrue())
+		Expect(window.IsActive(at("2022-10-16T03:00:00Z"))).To(BeTrue())
+		Expect(window.IsActive(at("2022-10-17T03:00:00Z"))).To(BeFalse())
+	})
+
+	It("supports windows ending on the following day", func() {
+		window := MaintenanceWindow{Days: []string{"friday"}, StartTime: "23:00", EndTime: "01:00"}
+		Expect(window.IsActive(at("2022-10-14T23:30:00Z"))).To(BeTrue())
+		Expect(window.IsActive(at("2022-10-15T00:30:00Z"))).To(BeTrue())
+		Expect(window.IsActive(at("2022-10-15T23:30:00Z"))).To(BeFalse())
+		Expect(window.IsActive(at("2022-10-14T00:30:00Z"))).To(BeFalse())
+	})
+
+	It("evaluates the window in the requested time zone", func() {
+		window := MaintenanceWindow{StartTime: "02:00", EndTime: "04:00", TimeZone: "Europe/Rome"}
+		Expect(window.IsActive(at("2022-10-14T00:30:00Z"))).To(BeTrue())
+		Expect(window.IsActive(at("2022-10-14T02:30:00Z"))).To(BeFalse())
+	})
+
+	It("computes the start of the next window", func() {
+		window := MaintenanceWindow{Days: []string{"sunday"}, StartTime: "02:00", EndTime: "04:00"}
+		Expect(window.GetNextStart(at("2022-10-14T12:00:00Z"))).To(
+			BeTemporally("==", at("2022-10-16T02:00:00Z")))
+		Expect(window.GetNextStart(at("2022-10-16T03:00:00Z"))).To(
+			BeTemporally("==", at("2022-10-23T02:00:00Z")))
+	})
+
+	It("reports invalid time zones", func() {
+		window := MaintenanceWindow{StartTime: "02:00", EndTime: "04:00", TimeZone: "Mars/Olympus"}
+		_, err := window.IsActive(at("2022-10-14T12:00:00Z"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WaitingForUser condition", func() {
+	It("is true when the phase requires a manual intervention", func() {
+		condition := GetWaitingForUserCondition(PhaseWaitingForUser, "User must issue a supervised switchover")
+		Expect(condition.Type).To(Equal(string(ConditionWaitingForUser)))
+		Expect(condition.Status).To(Equal(v1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(string(ConditionReasonSupervisedSwitchoverRequired)))
+		Expect(condition.Message).To(Equal("User must issue a supervised switchover"))
+
+		Expect(GetWaitingForUserCondition(PhaseJobFailed, "").Reason).
+			To(Equal(string(ConditionReasonJobFailed)))
+		Expect(GetWaitingForUserCondition(PhaseUnrecoverable, "").Reason).
+			To(Equal(string(ConditionReasonClusterUnrecoverable)))
+		Expect(GetWaitingForUserCondition(PhaseMissingSharedPreloadLibraries, "").Reason).
+			To(Equal(string(ConditionReasonMissingSharedPreloadLibraries)))
+	})
+
+	It("is false when the operator can make progress by itself", func() {
+		for _, phase := range []string{PhaseHealthy, PhaseUpgrade, PhaseWaitingForMaintenanceWindow} {
+			condition := GetWaitingForUserCondition(phase, "")
+			Expect(condition.Status).To(Equal(v1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(string(ConditionReasonNoUserActionRequired)))
+		}
+	})
+})
+
+var _ = Describe("replication slots database", func() {
+	It("defaults to the postgres database", func() {
+		var config *ReplicationSlotsConfiguration
+		Expect(config.GetDatabase()).To(Equal("postgres"))
+		Expect((&ReplicationSlotsConfiguration{}).GetDatabase()).To(Equal("postgres"))
+	})
+
+	It("uses the configured database", func() {
+		config := &ReplicationSlotsConfiguration{Database: "maintenance"}
+		Expect(config.GetDatabase()).To(Equal("maintenance"))
+	})
+})
+
+var _ = Describe("replication slots dedicated role", func() {
+	It("is disabled by default", func() {
+		var config *ReplicationSlotsConfiguration
+		Expect(config.IsDedicatedRoleEnabled()).To(BeFalse())
+		Expect((&ReplicationSlotsConfiguration{}).IsDedicatedRoleEnabled()).To(BeFalse())
+	})
+
+	It("can be enabled", func() {
+		config := &ReplicationSlotsConfiguration{UseDedicatedRole: true}
+		Expect(config.IsDedicatedRoleEnabled()).To(BeTrue())
+	})
+})
+
+var _ = Describe("replication slots synchronization", func() {
+	It("applies one operation at a time and drops the extra slots by default", func() {
+		var config *ReplicationSlotsConfiguration
+		Expect(config.GetSynchronizationConcurrency()).To(Equal(1))
+		Expect(config.GetExtraSlotsPolicy()).To(Equal(ExtraSlotsPolicyDelete))
+		Expect((&ReplicationSlotsConfiguration{}).GetExtraSlotsPolicy()).To(Equal(ExtraSlotsPolicyDelete))
+	})
+
+	It("uses the configured values", func() {
+		config := &ReplicationSlotsConfiguration{
+			SynchronizationConcurrency: 8,
+			ExtraSlotsPolicy:           ExtraSlotsPolicyDisable,
+		}
+		Expect(config.GetSynchronizationConcurrency()).To(Equal(8))
+		Expect(config.GetExtraSlotsPolicy()).To(Equal(ExtraSlotsPolicyDisable))
+	})
+
+	It("synchronizes the slots every 30 seconds by default", func() {
+		var config *ReplicationSlotsConfiguration
+		Expect(config.GetUpdateInterval()).To(Equal(30 * time.Second))
+		Expect((&ReplicationSlotsConfiguration{}).GetUpdateInterval()).To(Equal(30 * time.Second))
+		Expect((&ReplicationSlotsConfiguration{UpdateInterval: 60}).GetUpdateInterval()).To(Equal(time.Minute))
+	})
+
+	It("raises the update interval to the minimum", func() {
+		config := &ReplicationSlotsConfiguration{UpdateInterval: 1}
+		Expect(config.GetUpdateInterval()).To(Equal(10 * time.Second))
+	})
+})
+
+var _ = Describe("parallel WAL restore", func() {
+	It("restores one WAL file at a time by default", func() {
+		var config *WalBackupConfiguration
+		Expect(config.GetRestoreMaxParallel()).To(Equal(1))
+		Expect((&WalBackupConfiguration{}).GetRestoreMaxParallel()).To(Equal(1))
+	})
+
+	It("uses maxParallel when restoreMaxParallel is not set", func() {
+		config := &WalBackupConfiguration{MaxParallel: 8}
+		Expect(config.GetRestoreMaxParallel()).To(Equal(8))
+	})
+
+	It("prefers restoreMaxParallel over maxParallel", func() {
+		config := &WalBackupConfiguration{MaxParallel: 8, RestoreMaxParallel: 16}
+		Expect(config.GetRestoreMaxParallel()).To(Equal(16))
+	})
+})
+
+var _ = Describe("maximum number of consecutive WAL archiving failures", func() {
+	It("has no threshold when WAL archiving is not configured", func() {
+		cluster := &Cluster{}
+		Expect(cluster.GetWALArchiveMaxConsecutiveFailures()).To(BeZero())
+
+		cluster.Spec.Backup = &BackupConfiguration{BarmanObjectStore: &BarmanObjectStoreConfiguration{}}
+		Expect(cluster.GetWALArchiveMaxConsecutiveFailures()).To(BeZero())
+	})
+
+	It("uses the configured threshold", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore: &BarmanObjectStoreConfiguration{
+						Wal: &WalBackupConfiguration{MaxConsecutiveFailures: 5},
+					},
+				},
+			},
+		}
+		Expect(cluster.GetWALArchiveMaxConsecutiveFailures()).To(Equal(5))
+	})
+})
+
+var _ = Describe("readiness probe configuration", func() {
+	It("connects to the postgres database without running queries by default", func() {
+		var config *ProbesConfiguration
+		Expect(config.GetDatabase()).To(Equal("postgres"))
+		Expect(config.GetQuery()).To(BeEmpty())
+	})
+
+	It("uses the configured database and query", func() {
+		config := &ProbesConfiguration{Database: "app", Query: "SELECT 1"}
+		Expect(config.GetDatabase()).To(Equal("app"))
+		Expect(config.GetQuery()).To(Equal("SELECT 1"))
+	})
+})
+
+var _ = Describe("PVC reclaim policy", func() {
+	It("deletes the PVCs by default", func() {
+		cluster := Cluster{}
+		Expect(cluster.GetPVCReclaimPolicy(utils.PVCRolePgData)).To(Equal(PVCReclaimPolicyDelete))
+		Expect(cluster.GetPVCReclaimPolicy(utils.PVCRolePgWal)).To(Equal(PVCReclaimPolicyDelete))
+		Expect(cluster.ShouldRetainPVCs()).To(BeFalse())
+	})
+
+	It("applies the WAL storage policy to the WAL PVCs only", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				WalStorage: &StorageConfiguration{PVCReclaimPolicy: PVCReclaimPolicyRetain},
+			},
+		}
+		Expect(cluster.GetPVCReclaimPolicy(utils.PVCRolePgData)).To(Equal(PVCReclaimPolicyDelete))
+		Expect(cluster.GetPVCReclaimPolicy(utils.PVCRolePgWal)).To(Equal(PVCReclaimPolicyRetain))
+		Expect(cluster.ShouldRetainPVCs()).To(BeTrue())
+	})
+
+	It("retains the PVCs when requested for the data storage", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				StorageConfiguration: StorageConfiguration{PVCReclaimPolicy: PVCReclaimPolicyRetain},
+			},
+		}
+		Expect(cluster.ShouldRetainPVCs()).To(BeTrue())
+	})
+})
+
+var _ = Describe("termination grace period", func() {
+	It("defaults to the stop delay plus a margin", func() {
+		cluster := Cluster{}
+		Expect(cluster.GetShutdownTimeout()).To(BeEquivalentTo(30))
+		Expect(cluster.GetTerminationGracePeriodSeconds()).To(BeEquivalentTo(30 + DefaultTerminationGracePeriodMargin))
+	})
+
+	It("follows the shutdown timeout when it's longer than the stop delay", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				MaxStopDelay:    60,
+				ShutdownTimeout: 300,
+			},
+		}
+		Expect(cluster.GetShutdownTimeout()).To(BeEquivalentTo(300))
+		Expect(cluster.GetTerminationGracePeriodSeconds()).To(BeEquivalentTo(300 + DefaultTerminationGracePeriodMargin))
+	})
+
+	It("uses the requested termination grace period", func() {
+		gracePeriod := int64(600)
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				TerminationGracePeriodSeconds: &gracePeriod,
+			},
+		}
+		Expect(cluster.GetTerminationGracePeriodSeconds()).To(BeEquivalentTo(600))
+	})
+})
+
+var _ = Describe("slow query events", func() {
+	It("are disabled by default", func() {
+		var monitoring *MonitoringConfiguration
+		Expect(monitoring.AreSlowQueryEventsEnabled()).To(BeFalse())
+		Expect(monitoring.GetSlowQueryEventsSamplingInterval()).To(Equal(300 * time.Second))
+	})
+
+	It("use the requested sampling interval", func() {
+		monitoring := &MonitoringConfiguration{
+			SlowQueryEvents: &SlowQueryEventsConfiguration{
+				Enabled:          true,
+				SamplingInterval: 60,
+			},
+		}
+		Expect(monitoring.AreSlowQueryEventsEnabled()).To(BeTrue())
+		Expect(monitoring.GetSlowQueryEventsSamplingInterval()).To(Equal(time.Minute))
+	})
+})
+
+var _ = Describe("base backup maximum bandwidth", func() {
+	It("doesn't limit the bandwidth by default", func() {
+		var configuration *DataBackupConfiguration
+		Expect(configuration.GetMaxBandwidth()).To(BeZero())
+		Expect((&DataBackupConfiguration{}).GetMaxBandwidth()).To(BeZero())
+	})
+
+	It("converts the quantity in bytes", func() {
+		configuration := &DataBackupConfiguration{MaxBandwidth: "50Mi"}
+		Expect(configuration.GetMaxBandwidth()).To(BeEquivalentTo(50 * 1024 * 1024))
+	})
+
+	It("rejects non positive or fractional values", func() {
+		for _, value := range []string{"0", "-1Mi", "100m", "fast"} {
+			_, err := (&DataBackupConfiguration{MaxBandwidth: value}).GetMaxBandwidth()
+			Expect(err).To(HaveOccurred(), value)
+		}
+	})
+})
+
+var _ = Describe("ReplicationSlotsHealthy condition", func() {
+	replica := func(name string, convergence *postgres.SlotsConvergence) postgres.PostgresqlStatus {
+		return postgres.PostgresqlStatus{
+			Pod:              corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name}},
+			SlotsConvergence: convergence,
+		}
+	}
+
+	It("is true when every replica converged", func() {
+		condition := GetReplicationSlotsCondition(postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				{IsPrimary: true, Pod: corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "cluster-1"}}},
+				replica("cluster-2", &postgres.SlotsConvergence{Converged: true}),
+				replica("cluster-3", &postgres.SlotsConvergence{Converged: true}),
+			},
+		}, "cluster-1")
+		Expect(condition.Type).To(Equal(string(ConditionReplicationSlotsHealthy)))
+		Expect(condition.Status).To(Equal(v1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(string(ConditionReasonReplicationSlotsConverged)))
+	})
+
+	It("excludes the current primary even when it is in recovery", func() {
+		condition := GetReplicationSlotsCondition(postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				replica("cluster-1", nil),
+				replica("cluster-2", &postgres.SlotsConvergence{Converged: true}),
+			},
+		}, "cluster-1")
+		Expect(condition.Status).To(Equal(v1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(string(ConditionReasonReplicationSlotsConverged)))
+	})
+
+	It("is false when the slots of some replicas are diverging", func() {
+		condition := GetReplicationSlotsCondition(postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				replica("cluster-2", &postgres.SlotsConvergence{Converged: true}),
+				replica("cluster-3", &postgres.SlotsConvergence{MissingSlots: []string{"_cnpg_cluster_2"}}),
+			},
+		}, "cluster-1")
+		Expect(condition.Status).To(Equal(v1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(ConditionReasonReplicationSlotsNotConverged)))
+		Expect(condition.Message).To(HaveSuffix(": cluster-3"))
+	})
+
+	It("reports the synchronization errors before the diverging slots", func() {
+		condition := GetReplicationSlotsCondition(postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				replica("cluster-2", &postgres.SlotsConvergence{MissingSlots: []string{"_cnpg_cluster_3"}}),
+				replica("cluster-3", &postgres.SlotsConvergence{Error: "connection refused"}),
+				replica("cluster-4", &postgres.SlotsConvergence{Error: "connection refused"}),
+			},
+		}, "cluster-1")
+		Expect(condition.Status).To(Equal(v1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(ConditionReasonReplicationSlotsSynchronizationFailing)))
+		Expect(condition.Message).To(HaveSuffix(": cluster-3, cluster-4"))
+	})
+
+	It("reports the paused synchronization before the diverging slots", func() {
+		condition := GetReplicationSlotsCondition(postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				replica("cluster-2", &postgres.SlotsConvergence{MissingSlots: []string{"_cnpg_cluster_3"}}),
+				replica("cluster-3", &postgres.SlotsConvergence{Converged: true, Paused: true}),
+			},
+		}, "cluster-1")
+		Expect(condition.Status).To(Equal(v1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(ConditionReasonReplicationSlotsSynchronizationPaused)))
+		Expect(condition.Message).To(HaveSuffix(": cluster-3"))
+	})
+
+	It("reports the replicas whose convergence is unknown before everything else", func() {
+		unreachable := replica("cluster-4", &postgres.SlotsConvergence{Converged: true})
+		unreachable.Error = errors.New("connection refused")
+		condition := GetReplicationSlotsCondition(postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				replica("cluster-2", &postgres.SlotsConvergence{Error: "connection refused"}),
+				replica("cluster-3", nil),
+				unreachable,
+			},
+		}, "cluster-1")
+		Expect(condition.Status).To(Equal(v1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(ConditionReasonReplicationSlotsStatusUnknown)))
+		Expect(condition.Message).To(HaveSuffix(": cluster-3, cluster-4"))
+	})
+})
+
+var _ = Describe("additional WAL archives status", func() {
+	newCluster := func(names ...string) *Cluster {
+		cluster := &Cluster{Spec: ClusterSpec{Backup: &BackupConfiguration{}}}
+		for _, name := range names {
+			cluster.Spec.Backup.AdditionalWALArchives = append(
+				cluster.Spec.Backup.AdditionalWALArchives, WALArchiveDestination{Name: name})
+		}
+		return cluster
+	}
+
+	It("is taken from the status reported by the primary", func() {
+		cluster := newCluster("dr")
+		cluster.SetWALArchiveDestinationsStatus(map[string]postgres.WALArchiveDestinationStatus{
+			"dr": {LastArchivedWAL: "000000010000000000000002", LastArchivedTime: "2022-10-01T12:00:00.000000Z"},
+		})
+		Expect(cluster.Status.WALArchiveDestinations).To(Equal(map[string]WALArchiveDestinationStatus{
+			"dr": {LastArchivedWAL: "000000010000000000000002", LastArchivedTime: "2022-10-01T12:00:00.000000Z"},
+		}))
+	})
+
+	It("keeps the destinations the primary hasn't reported yet", func() {
+		cluster := newCluster("dr", "offsite")
+		cluster.Status.WALArchiveDestinations = map[string]WALArchiveDestinationStatus{
+			"offsite": {LastFailedWAL: "000000010000000000000001"},
+		}
+		cluster.SetWALArchiveDestinationsStatus(map[string]postgres.WALArchiveDestinationStatus{
+			"dr": {LastArchivedWAL: "000000010000000000000002"},
+		})
+		Expect(cluster.Status.WALArchiveDestinations).To(HaveLen(2))
+		Expect(cluster.Status.WALArchiveDestinations["offsite"].LastFailedWAL).To(Equal("000000010000000000000001"))
+	})
+
+	It("drops the destinations that are not defined anymore", func() {
+		cluster := newCluster()
+		cluster.Status.WALArchiveDestinations = map[string]WALArchiveDestinationStatus{
+			"dr": {LastArchivedWAL: "000000010000000000000002"},
+		}
+		cluster.SetWALArchiveDestinationsStatus(map[string]postgres.WALArchiveDestinationStatus{
+			"dr": {LastArchivedWAL: "000000010000000000000003"},
+		})
+		Expect(cluster.Status.WALArchiveDestinations).To(BeNil())
+	})
+})
+
+var _ = Describe("last archived WAL status", func() {
+	It("records the WAL file reported by pg_stat_archiver", func() {
+		status := ClusterStatus{}
+		status.SetLastArchivedWAL("000000010000000000000004", "2022-10-01T14:00:00.123456+02:00", 0)
+		Expect(status.LastArchivedWAL).To(Equal("000000010000000000000004"))
+		Expect(status.LastArchivedWALLSN).To(Equal("0/5000000"))
+		Expect(status.LastArchivedWALTime).To(Equal("2022-10-01T12:00:00Z"))
+	})
+
+	It("uses the WAL segment size of the cluster", func() {
+		status := ClusterStatus{}
+		status.SetLastArchivedWAL("000000010000000100000002", "2022-10-01T12:00:00Z", 64*1024*1024)
+		Expect(status.LastArchivedWALLSN).To(Equal("1/C000000"))
+	})
+
+	It("ignores history, backup and partial files", func() {
+		status := ClusterStatus{LastArchivedWAL: "000000010000000000000003"}
+		status.SetLastArchivedWAL("00000002.history", "2022-10-01T12:00:00Z", 0)
+		status.SetLastArchivedWAL("000000010000000000000004.00000028.backup", "2022-10-01T12:00:00Z", 0)
+		status.SetLastArchivedWAL("000000010000000000000005.partial", "2022-10-01T12:00:00Z", 0)
+		Expect(status.LastArchivedWAL).To(Equal("000000010000000000000003"))
+	})
+
+	It("doesn't go back to an older WAL file", func() {
+		status := ClusterStatus{
+			LastArchivedWAL:    "000000010000000000000009",
+			LastArchivedWALLSN: "0/A000000",
+		}
+		status.SetLastArchivedWAL("000000010000000000000008", "2022-10-01T12:00:00Z", 0)
+		Expect(status.LastArchivedWAL).To(Equal("000000010000000000000009"))
+		Expect(status.LastArchivedWALLSN).To(Equal("0/A000000"))
+	})
+})
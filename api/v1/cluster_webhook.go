@@ -19,13 +19,18 @@ package v1
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/robfig/cron"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -60,6 +65,15 @@ const (
 // clusterLog is for logging in this package.
 var clusterLog = log.WithName("cluster-resource").WithValues("version", "v1")
 
+// encodingNameRegex matches the names of the encodings, and of their aliases,
+// accepted by PostgreSQL, i.e. `UTF8`, `LATIN1` or `ISO_8859_5`
+var encodingNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// localeNameRegex matches the names of the locales, in the
+// `language_territory.codeset@modifier` format, i.e. `C`, `en_US.UTF-8`
+// or `de_DE@euro`
+var localeNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*(\.[A-Za-z0-9_-]+)?(@[A-Za-z0-9_-]+)?$`)
+
 // SetupWebhookWithManager setup the webhook inside the controller manager
 func (r *Cluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
@@ -120,6 +134,8 @@ func (r *Cluster) setDefaults(preserveUserSettings bool) {
 			UserSettings:                  r.Spec.PostgresConfiguration.Parameters,
 			IsReplicaCluster:              r.IsReplica(),
 			PreserveFixedSettingsFromUser: preserveUserSettings,
+			LogDestination:                string(r.GetLogFormat()),
+			ParametersSkippingValidation:  utils.GetParametersSkippingValidation(&r.ObjectMeta),
 		}
 		sanitizedParameters := postgres.CreatePostgresqlConfiguration(info).GetConfigurationParameters()
 		r.Spec.PostgresConfiguration.Parameters = sanitizedParameters
@@ -253,7 +269,8 @@ var _ webhook.Validator = &Cluster{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *Cluster) ValidateCreate() error {
 	clusterLog.Info("validate create", "name", r.Name, "namespace", r.Namespace)
-	allErrs := r.Validate()
+	allErrs := append(r.Validate(), r.validateMinimumPostgresVersion()...)
+	allErrs = append(allErrs, r.validateReplicationSlotsUpdateInterval(nil)...)
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -277,21 +294,44 @@ func (r *Cluster) Validate() (allErrs field.ErrorList) {
 		r.validateImageName,
 		r.validateImagePullPolicy,
 		r.validateRecoveryTarget,
+		r.validateRecoveryRegenerateCredentials,
 		r.validatePrimaryUpdateStrategy,
 		r.validateMinSyncReplicas,
 		r.validateMaxSyncReplicas,
 		r.validateWalStorageSize,
+		r.validatePVCReclaimPolicy,
 		r.validateName,
 		r.validateBootstrapPgBaseBackupSource,
 		r.validateBootstrapRecoverySource,
 		r.validateExternalClusters,
 		r.validateTolerations,
 		r.validateAntiAffinity,
+		r.validateTopologySpreadConstraints,
+		r.validateInstanceNodeSelectors,
 		r.validateReplicaMode,
 		r.validateBackupConfiguration,
+		r.validateAdditionalWALArchives,
+		r.validateBarmanEnv,
 		r.validateConfiguration,
+		r.validateSharedPreloadLibraries,
 		r.validateLDAP,
 		r.validateReplicationSlots,
+		r.validateReplicationSlotsDatabase,
+		r.validateLogFormat,
+		r.validateInheritedMetadata,
+		r.validateHibernationAnnotation,
+		r.validatePreferredPrimary,
+		r.validateWALKeepSize,
+		r.validateMemoryPercentageParameters,
+		r.validateIdleInTransactionSessionTimeout,
+		r.validateAutovacuum,
+		r.validatePrePromotionHook,
+		r.validateMaintenanceWindow,
+		r.validateScheduledMaintenance,
+		r.validateShutdownSettings,
+		r.validateReadOnlyService,
+		r.validateServices,
+		r.validateProbes,
 	}
 
 	for _, validate := range validations {
@@ -338,9 +378,39 @@ func (r *Cluster) ValidateChanges(old *Cluster) (allErrs field.ErrorList) {
 	allErrs = append(allErrs, r.validateReplicaModeChange(old)...)
 	allErrs = append(allErrs, r.validateUnixPermissionIdentifierChange(old)...)
 	allErrs = append(allErrs, r.validateReplicationSlotsChange(old)...)
+	allErrs = append(allErrs, r.validateReplicationSlotsUpdateInterval(old)...)
+	allErrs = append(allErrs, r.validateWalSegmentSizeChange(old)...)
+	allErrs = append(allErrs, r.validateDataChecksumsChange(old)...)
+	allErrs = append(allErrs, r.validateLocaleChange(old)...)
+	allErrs = append(allErrs, r.validateServicesChange(old)...)
 	return allErrs
 }
 
+// getInitDBWalSegmentSize returns the WAL segment size requested for initdb,
+// or zero if not specified
+func (r *Cluster) getInitDBWalSegmentSize() int {
+	if r.Spec.Bootstrap == nil || r.Spec.Bootstrap.InitDB == nil {
+		return 0
+	}
+	return r.Spec.Bootstrap.InitDB.WalSegmentSize
+}
+
+// validateWalSegmentSizeChange validates that the WAL segment size is not
+// changed, as it is chosen at initdb time and can't be modified later
+func (r *Cluster) validateWalSegmentSizeChange(old *Cluster) field.ErrorList {
+	if r.getInitDBWalSegmentSize() == old.getInitDBWalSegmentSize() {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", "bootstrap", "initdb", "walSegmentSize"),
+			r.getInitDBWalSegmentSize(),
+			fmt.Sprintf("The WAL segment size can't be changed after the bootstrap, the current value is %v",
+				old.getInitDBWalSegmentSize())),
+	}
+}
+
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *Cluster) ValidateDelete() error {
 	clusterLog.Info("validate delete", "name", r.Name)
@@ -376,6 +446,106 @@ func (r *Cluster) validateLDAP() field.ErrorList {
 	return result
 }
 
+// isInitDBDataChecksumsEnabled checks if the data checksums are
+// requested for initdb
+func (r *Cluster) isInitDBDataChecksumsEnabled() bool {
+	if r.Spec.Bootstrap == nil || r.Spec.Bootstrap.InitDB == nil ||
+		r.Spec.Bootstrap.InitDB.DataChecksums == nil {
+		return false
+	}
+	return *r.Spec.Bootstrap.InitDB.DataChecksums
+}
+
+// validateDataChecksumsChange validates that the data checksums setting is
+// not changed, as it is chosen at initdb time and can't be modified later
+func (r *Cluster) validateDataChecksumsChange(old *Cluster) field.ErrorList {
+	if r.isInitDBDataChecksumsEnabled() == old.isInitDBDataChecksumsEnabled() {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", "bootstrap", "initdb", "dataChecksums"),
+			r.isInitDBDataChecksumsEnabled(),
+			fmt.Sprintf("The data checksums setting can't be changed after the bootstrap, the current value is %v",
+				old.isInitDBDataChecksumsEnabled())),
+	}
+}
+
+// getInitDBLocale returns the encoding and the locale settings requested
+// for initdb, keyed by their field name
+func (r *Cluster) getInitDBLocale() map[string]string {
+	if r.Spec.Bootstrap == nil || r.Spec.Bootstrap.InitDB == nil {
+		return map[string]string{}
+	}
+	initDB := r.Spec.Bootstrap.InitDB
+	return map[string]string{
+		"encoding":      initDB.Encoding,
+		"locale":        initDB.Locale,
+		"localeCollate": initDB.LocaleCollate,
+		"localeCType":   initDB.LocaleCType,
+	}
+}
+
+// validateLocaleChange validates that the encoding and the locale are not
+// changed, as they are chosen at initdb time and can't be modified later
+func (r *Cluster) validateLocaleChange(old *Cluster) field.ErrorList {
+	var result field.ErrorList
+
+	newLocale := r.getInitDBLocale()
+	oldLocale := old.getInitDBLocale()
+	for _, name := range []string{"encoding", "locale", "localeCollate", "localeCType"} {
+		if newLocale[name] == oldLocale[name] {
+			continue
+		}
+		result = append(
+			result,
+			field.Invalid(
+				field.NewPath("spec", "bootstrap", "initdb", name),
+				newLocale[name],
+				fmt.Sprintf("The %s can't be changed after the bootstrap, the current value is %q",
+					name, oldLocale[name])))
+	}
+
+	return result
+}
+
+// validateInitDBLocale validates the format of the encoding and
+// of the locale names passed to initdb
+func validateInitDBLocale(initDB *BootstrapInitDB) field.ErrorList {
+	var result field.ErrorList
+
+	if initDB.Encoding != "" && !encodingNameRegex.MatchString(initDB.Encoding) {
+		result = append(
+			result,
+			field.Invalid(
+				field.NewPath("spec", "bootstrap", "initdb", "encoding"),
+				initDB.Encoding,
+				"invalid encoding name"))
+	}
+
+	locales := []struct {
+		name  string
+		value string
+	}{
+		{"locale", initDB.Locale},
+		{"localeCollate", initDB.LocaleCollate},
+		{"localeCType", initDB.LocaleCType},
+	}
+	for _, locale := range locales {
+		if locale.value != "" && !localeNameRegex.MatchString(locale.value) {
+			result = append(
+				result,
+				field.Invalid(
+					field.NewPath("spec", "bootstrap", "initdb", locale.name),
+					locale.value,
+					"invalid locale name, expected a format like `C`, `en_US.UTF-8` or `de_DE@euro`"))
+		}
+	}
+
+	return result
+}
+
 // validateInitDB validate the bootstrapping options when initdb
 // method is used
 func (r *Cluster) validateInitDB() field.ErrorList {
@@ -396,6 +566,8 @@ func (r *Cluster) validateInitDB() field.ErrorList {
 	result = r.validateApplicationDatabase(initDBOptions.Database, initDBOptions.Owner,
 		"initdb")
 
+	result = append(result, validateInitDBLocale(initDBOptions)...)
+
 	if initDBOptions.WalSegmentSize != 0 && !utils.IsPowerOfTwo(initDBOptions.WalSegmentSize) {
 		result = append(
 			result,
@@ -485,6 +657,16 @@ func (s Import) validateMicroservice() field.ErrorList {
 		)
 	}
 
+	if s.RolePasswords != "" {
+		result = append(
+			result,
+			field.Invalid(
+				field.NewPath("spec", "bootstrap", "initdb", "import", "rolePasswords"),
+				s.RolePasswords,
+				"You cannot specify how to import the role passwords for the `microservice` import type"),
+		)
+	}
+
 	if len(s.Databases) == 1 && strings.Contains(s.Databases[0], "*") {
 		result = append(
 			result,
@@ -648,9 +830,145 @@ func (r *Cluster) validateCerts() field.ErrorList {
 				"Client CA secret can't be empty when client replication secret is provided"))
 	}
 
+	result = append(result, r.validateClientCertificateUsers()...)
+
+	return result
+}
+
+// validateInheritedMetadata ensures that the inherited metadata don't
+// contain any label or annotation managed by the operator
+func (r *Cluster) validateInheritedMetadata() field.ErrorList {
+	if r.Spec.InheritedMetadata == nil {
+		return nil
+	}
+
+	var result field.ErrorList
+	path := field.NewPath("spec", "inheritedMetadata")
+
+	for key := range r.Spec.InheritedMetadata.Labels {
+		if utils.IsOperatorManagedLabel(key) {
+			result = append(result, field.Invalid(
+				path.Child("labels").Key(key),
+				key,
+				"This label is managed by the operator and can't be inherited"))
+		}
+	}
+
+	for key := range r.Spec.InheritedMetadata.Annotations {
+		if utils.IsOperatorManagedAnnotation(key) {
+			result = append(result, field.Invalid(
+				path.Child("annotations").Key(key),
+				key,
+				"This annotation is managed by the operator and can't be inherited"))
+		}
+	}
+
+	return result
+}
+
+// validateHibernationAnnotation ensures that the value of the
+// hibernation annotation, when present, is a supported one
+func (r *Cluster) validateHibernationAnnotation() field.ErrorList {
+	value, ok := r.Annotations[utils.HibernationAnnotationName]
+	if !ok {
+		return nil
+	}
+
+	switch utils.HibernationAnnotationValue(value) {
+	case utils.HibernationAnnotationValueOn, utils.HibernationAnnotationValueOff:
+		return nil
+	default:
+		return field.ErrorList{
+			field.NotSupported(
+				field.NewPath("metadata", "annotations").Key(utils.HibernationAnnotationName),
+				value,
+				[]string{
+					string(utils.HibernationAnnotationValueOn),
+					string(utils.HibernationAnnotationValueOff),
+				}),
+		}
+	}
+}
+
+// validateClientCertificateUsers validate the list of roles for which
+// a client certificate has to be issued
+func (r *Cluster) validateClientCertificateUsers() field.ErrorList {
+	var result field.ErrorList
+	secretNames := stringset.New()
+
+	for idx, user := range r.GetClientCertificateUsers() {
+		path := field.NewPath("spec", "certificates", "clientCertificateUsers").Index(idx)
+
+		if user == StreamingReplicationUser || user == PGBouncerPoolerUserName || user == ReplicationSlotsUser {
+			result = append(
+				result,
+				field.Invalid(
+					path,
+					user,
+					"The client certificate of this role is already managed by the operator"))
+			continue
+		}
+
+		if user == "postgres" {
+			result = append(
+				result,
+				field.Invalid(
+					path,
+					user,
+					"The superuser can't be required to use client certificate authentication"))
+			continue
+		}
+
+		if isPgHbaUserKeyword(user) {
+			result = append(
+				result,
+				field.Invalid(
+					path,
+					user,
+					"The role name can't be a pg_hba.conf keyword, a group (+) or a file (@) reference"))
+			continue
+		}
+
+		secretName := r.GetClientCertificateSecretName(user)
+		if errs := validationutil.IsDNS1123Subdomain(secretName); user == "" || len(errs) > 0 {
+			result = append(
+				result,
+				field.Invalid(
+					path,
+					user,
+					fmt.Sprintf("The role name must produce a valid secret name (%s): %s",
+						secretName, strings.Join(errs, ", "))))
+			continue
+		}
+
+		if secretNames.Has(secretName) {
+			result = append(
+				result,
+				field.Invalid(
+					path,
+					user,
+					fmt.Sprintf("The role name produces the same secret name (%s) of a previous one",
+						secretName)))
+			continue
+		}
+		secretNames.Put(secretName)
+	}
+
 	return result
 }
 
+// isPgHbaUserKeyword checks if the passed role name would be interpreted
+// as something different from a single role in the pg_hba.conf rule
+// requiring client certificate authentication
+func isPgHbaUserKeyword(user string) bool {
+	switch user {
+	case "all", "sameuser", "samerole", "samegroup":
+		return true
+	}
+
+	return strings.HasPrefix(user, "+") || strings.HasPrefix(user, "@")
+}
+
 // ValidateSuperuserSecret validate super user secret value
 func (r *Cluster) validateSuperuserSecret() field.ErrorList {
 	var result field.ErrorList
@@ -793,6 +1111,47 @@ func (r *Cluster) validateImageName() field.ErrorList {
 	return result
 }
 
+// validateMinimumPostgresVersion rejects the new clusters running a
+// PostgreSQL major version older than the minimum allowed by the operator
+// configuration. It's only enforced on creation, as the major version of an
+// existing cluster can't be changed
+func (r *Cluster) validateMinimumPostgresVersion() field.ErrorList {
+	minimumVersion := configuration.Current.MinimumPostgresMajorVersion
+	if minimumVersion == 0 {
+		return nil
+	}
+
+	imageName := r.GetImageName()
+	majorVersion, err := postgres.GetPostgresMajorVersionFromTag(utils.GetImageTag(imageName))
+	if err != nil && r.Spec.ImageName != "" {
+		// The image name is already validated by validateImageName
+		return nil
+	}
+	if err != nil {
+		// The default image of the operator may be referenced only by its
+		// digest, and we can't let it bypass the minimum version
+		return field.ErrorList{
+			field.Invalid(
+				field.NewPath("spec", "imageName"),
+				imageName,
+				fmt.Sprintf("cannot detect the PostgreSQL version of the default image, while the operator "+
+					"requires PostgreSQL %d or newer: please set an image with a version tag", minimumVersion)),
+		}
+	}
+
+	if majorVersion >= minimumVersion {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", "imageName"),
+			imageName,
+			fmt.Sprintf("PostgreSQL %d is not allowed by the operator, which requires PostgreSQL %d or newer: "+
+				"please use a more recent image", majorVersion, minimumVersion)),
+	}
+}
+
 // validateImagePullPolicy validates the image pull policy,
 // ensuring it is one of "Always", "Never" or "IfNotPresent" when defined
 func (r *Cluster) validateImagePullPolicy() field.ErrorList {
@@ -822,15 +1181,30 @@ func (r *Cluster) validateConfiguration() field.ErrorList {
 		// validateImageName function
 		return result
 	}
+	// The mandatory settings are included so that fixed parameters set
+	// to the value enforced by the operator, like `wal_level = logical`,
+	// are accepted
 	info := postgres.ConfigurationInfo{
-		Settings:         postgres.CnpgConfigurationSettings,
-		MajorVersion:     psqlVersion,
-		UserSettings:     r.Spec.PostgresConfiguration.Parameters,
-		IsReplicaCluster: r.IsReplica(),
+		Settings:           postgres.CnpgConfigurationSettings,
+		MajorVersion:       psqlVersion,
+		UserSettings:       r.Spec.PostgresConfiguration.Parameters,
+		IsReplicaCluster:   r.IsReplica(),
+		IncludingMandatory: true,
+		LogDestination:     string(r.GetLogFormat()),
 	}
 	sanitizedParameters := postgres.CreatePostgresqlConfiguration(info).GetConfigurationParameters()
 
+	skippedParameters := utils.GetParametersSkippingValidation(&r.ObjectMeta)
+	if len(skippedParameters) > 0 {
+		clusterLog.Info("WARNING: the validation of some PostgreSQL parameters has been disabled by the user",
+			"name", r.Name, "namespace", r.Namespace, "parameters", skippedParameters)
+	}
+
 	for key, value := range r.Spec.PostgresConfiguration.Parameters {
+		if slices.Contains(skippedParameters, key) {
+			continue
+		}
+
 		_, isFixed := postgres.FixedConfigurationParameters[key]
 		sanitizedValue, presentInSanitizedConfiguration := sanitizedParameters[key]
 		if isFixed && (!presentInSanitizedConfiguration || value != sanitizedValue) {
@@ -843,6 +1217,21 @@ func (r *Cluster) validateConfiguration() field.ErrorList {
 		}
 	}
 
+	if value, ok := r.Spec.PostgresConfiguration.Parameters["wal_compression"]; ok &&
+		!slices.Contains(skippedParameters, "wal_compression") {
+		if err := validateWalCompression(
+			field.NewPath("spec", "postgresql", "parameters", "wal_compression"), value, psqlVersion); err != nil {
+			result = append(result, err)
+		}
+	}
+
+	if method := r.GetWALCompression(); method != "" {
+		if err := validateWalCompression(
+			field.NewPath("spec", "postgresql", "walCompression"), method, psqlVersion); err != nil {
+			result = append(result, err)
+		}
+	}
+
 	if err := validateSyncReplicaElectionConstraint(
 		r.Spec.PostgresConfiguration.SyncReplicaElectionConstraint,
 	); err != nil {
@@ -852,6 +1241,30 @@ func (r *Cluster) validateConfiguration() field.ErrorList {
 	return result
 }
 
+// validateWalCompression checks that the requested WAL compression method
+// is supported by the PostgreSQL version in use. Before PostgreSQL 15
+// wal_compression is a boolean, while from PostgreSQL 15 it also accepts
+// the name of the compression algorithm
+func validateWalCompression(path *field.Path, value string, psqlVersion int) *field.Error {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on", "off", "true", "false", "yes", "no", "1", "0":
+		return nil
+	case "pglz", "lz4", "zstd":
+		if psqlVersion >= 150000 {
+			return nil
+		}
+		return field.Invalid(
+			path,
+			value,
+			"Choosing the WAL compression method requires PostgreSQL 15 or above")
+	}
+
+	return field.Invalid(
+		path,
+		value,
+		"Invalid value for wal_compression, the supported values are on, off, pglz, lz4 and zstd")
+}
+
 // validateConfigurationChange determines whether a PostgreSQL configuration
 // change can be applied
 func (r *Cluster) validateConfigurationChange(old *Cluster) field.ErrorList {
@@ -898,6 +1311,12 @@ func validateSyncReplicaElectionConstraint(constraints SyncReplicaElectionConstr
 func (r *Cluster) validateImageChange(old string) field.ErrorList {
 	var result field.ErrorList
 
+	// The check can be skipped in expert recovery scenarios,
+	// when the data directory has been already migrated
+	if !utils.IsMajorVersionCheckEnabled(&r.ObjectMeta) {
+		return nil
+	}
+
 	newVersion := r.Spec.ImageName
 	if newVersion == "" {
 		// We'll use the default one
@@ -922,13 +1341,32 @@ func (r *Cluster) validateImageChange(old string) field.ErrorList {
 			field.Invalid(
 				field.NewPath("spec", "imageName"),
 				r.Spec.ImageName,
-				fmt.Sprintf("can't upgrade between %v and %v",
-					old, newVersion)))
+				getImageChangeErrorMessage(old, newVersion)))
 	}
 
 	return result
 }
 
+// getImageChangeErrorMessage explains why the image of a cluster
+// can't be changed from oldImage to newImage
+func getImageChangeErrorMessage(oldImage, newImage string) string {
+	oldVersion, oldErr := postgres.GetPostgresVersionFromTag(utils.GetImageTag(oldImage))
+	newVersion, newErr := postgres.GetPostgresVersionFromTag(utils.GetImageTag(newImage))
+	if oldErr != nil || newErr != nil {
+		return fmt.Sprintf("can't upgrade between %v and %v", oldImage, newImage)
+	}
+
+	oldMajor := postgres.GetPostgresMajorVersion(oldVersion)
+	newMajor := postgres.GetPostgresMajorVersion(newVersion)
+	if newMajor < oldMajor {
+		return fmt.Sprintf("can't downgrade between %v and %v: the data directory "+
+			"can't be read by an older PostgreSQL major version", oldImage, newImage)
+	}
+
+	return fmt.Sprintf("can't upgrade between %v and %v: major upgrades are not "+
+		"supported in place, import the data in a new cluster instead", oldImage, newImage)
+}
+
 // Validate the recovery target to ensure that the mutual exclusivity
 // of options is respected and plus validating the format of targetTime
 // if specified
@@ -989,6 +1427,27 @@ func (r *Cluster) validateRecoveryTarget() field.ErrorList {
 	return result
 }
 
+// validateRecoveryRegenerateCredentials ensures that the credentials are
+// regenerated only when the recovered cluster is promoted at the end of
+// the recovery, as passwords can't be changed on a read-only instance
+func (r *Cluster) validateRecoveryRegenerateCredentials() field.ErrorList {
+	if r.Spec.Bootstrap == nil || r.Spec.Bootstrap.Recovery == nil {
+		return nil
+	}
+
+	if !r.Spec.Bootstrap.Recovery.RegenerateCredentials || !r.IsReplica() {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", "bootstrap", "recovery", "regenerateCredentials"),
+			r.Spec.Bootstrap.Recovery.RegenerateCredentials,
+			"Credentials can't be regenerated when recovering a replica cluster, "+
+				"as it is not promoted at the end of the recovery"),
+	}
+}
+
 func validateTargetExclusiveness(recoveryTarget *RecoveryTarget) field.ErrorList {
 	targets := 0
 	if recoveryTarget.TargetImmediate != nil {
@@ -1063,7 +1522,8 @@ func (r *Cluster) validateMaxSyncReplicas() field.ErrorList {
 		result = append(result, field.Invalid(
 			field.NewPath("spec", "maxSyncReplicas"),
 			r.Spec.MaxSyncReplicas,
-			"maxSyncReplicas must be lower than the number of instances"))
+			fmt.Sprintf("maxSyncReplicas must be lower than the number of instances "+
+				"(at most %v with %v instances)", r.Spec.Instances-1, r.Spec.Instances)))
 	}
 
 	return result
@@ -1100,6 +1560,37 @@ func (r *Cluster) validateWalStorageSize() field.ErrorList {
 	return result
 }
 
+// validatePVCReclaimPolicy checks the reclaim policy of the data
+// and WAL storage
+func (r *Cluster) validatePVCReclaimPolicy() field.ErrorList {
+	var result field.ErrorList
+
+	result = append(result, validateStorageConfigurationPVCReclaimPolicy("storage", r.Spec.StorageConfiguration)...)
+	if r.ShouldCreateWalArchiveVolume() {
+		result = append(result, validateStorageConfigurationPVCReclaimPolicy("walStorage", *r.Spec.WalStorage)...)
+	}
+
+	return result
+}
+
+func validateStorageConfigurationPVCReclaimPolicy(
+	structPath string,
+	storageConfiguration StorageConfiguration,
+) field.ErrorList {
+	switch storageConfiguration.PVCReclaimPolicy {
+	case "", PVCReclaimPolicyDelete, PVCReclaimPolicyRetain:
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", structPath, "pvcReclaimPolicy"),
+			storageConfiguration.PVCReclaimPolicy,
+			fmt.Sprintf("pvcReclaimPolicy must be one of %q or %q",
+				PVCReclaimPolicyDelete, PVCReclaimPolicyRetain)),
+	}
+}
+
 func validateStorageConfigurationSize(structPath string, storageConfiguration StorageConfiguration) field.ErrorList {
 	var result field.ErrorList
 
@@ -1144,11 +1635,20 @@ func (r *Cluster) validateWalStorageChange(old *Cluster) field.ErrorList {
 	}
 
 	if old.Spec.WalStorage != nil && r.Spec.WalStorage == nil {
+		// The user explicitly asked to skip this check, accepting that the
+		// WAL files stored in the WAL volumes won't be available to the
+		// instances recreated without them
+		if !utils.IsWalStorageCheckEnabled(&r.ObjectMeta) {
+			return nil
+		}
+
 		return append(result,
 			field.Invalid(
 				field.NewPath("spec", "walStorage"),
 				r.Spec.WalStorage,
-				"walStorage cannot be disabled once the cluster is created"),
+				"walStorage cannot be disabled once the cluster is created, as the WAL files "+
+					"stored in the WAL volumes would be lost. Set the "+
+					"cnpg.io/skipWalStorageCheck annotation to 'enabled' to force this change"),
 		)
 	}
 
@@ -1443,6 +1943,108 @@ func (r *Cluster) validateAntiAffinity() field.ErrorList {
 	return allErrors
 }
 
+// validateTopologySpreadConstraints validates the topology spread constraints,
+// following the same rules used by Kubernetes for the Pod specification
+func (r *Cluster) validateTopologySpreadConstraints() field.ErrorList {
+	path := field.NewPath("spec", "topologySpreadConstraints")
+	allErrors := field.ErrorList{}
+
+	type constraintPair struct {
+		topologyKey       string
+		whenUnsatisfiable v1.UnsatisfiableConstraintAction
+	}
+	existingConstraintPairs := make(map[constraintPair]bool)
+
+	for idx, constraint := range r.Spec.TopologySpreadConstraints {
+		idxPath := path.Index(idx)
+
+		if constraint.MaxSkew <= 0 {
+			allErrors = append(allErrors,
+				field.Invalid(idxPath.Child("maxSkew"), constraint.MaxSkew, "must be greater than zero"))
+		}
+
+		if constraint.TopologyKey == "" {
+			allErrors = append(allErrors,
+				field.Required(idxPath.Child("topologyKey"), "can not be empty"))
+		} else {
+			allErrors = append(allErrors,
+				validation.ValidateLabelName(constraint.TopologyKey, idxPath.Child("topologyKey"))...)
+		}
+
+		switch constraint.WhenUnsatisfiable {
+		case v1.DoNotSchedule, v1.ScheduleAnyway:
+		default:
+			allErrors = append(allErrors, field.NotSupported(
+				idxPath.Child("whenUnsatisfiable"),
+				constraint.WhenUnsatisfiable,
+				[]string{string(v1.DoNotSchedule), string(v1.ScheduleAnyway)}))
+		}
+
+		if constraint.MinDomains != nil {
+			if *constraint.MinDomains <= 0 {
+				allErrors = append(allErrors,
+					field.Invalid(idxPath.Child("minDomains"), *constraint.MinDomains, "must be greater than zero"))
+			}
+			if constraint.WhenUnsatisfiable != v1.DoNotSchedule {
+				allErrors = append(allErrors,
+					field.Invalid(idxPath.Child("minDomains"), *constraint.MinDomains,
+						fmt.Sprintf("can only use minDomains if whenUnsatisfiable=%s", v1.DoNotSchedule)))
+			}
+		}
+
+		if constraint.LabelSelector != nil {
+			allErrors = append(allErrors,
+				validation.ValidateLabelSelector(constraint.LabelSelector, idxPath.Child("labelSelector"))...)
+		}
+
+		pair := constraintPair{
+			topologyKey:       constraint.TopologyKey,
+			whenUnsatisfiable: constraint.WhenUnsatisfiable,
+		}
+		if existingConstraintPairs[pair] {
+			allErrors = append(allErrors,
+				field.Duplicate(idxPath, fmt.Sprintf("{%v, %v}", pair.topologyKey, pair.whenUnsatisfiable)))
+		}
+		existingConstraintPairs[pair] = true
+	}
+
+	return allErrors
+}
+
+// validateInstanceNodeSelectors validates the node selectors
+// requested for specific instances
+func (r *Cluster) validateInstanceNodeSelectors() field.ErrorList {
+	path := field.NewPath("spec", "affinity", "instanceNodeSelectors")
+	allErrors := field.ErrorList{}
+
+	existingSerials := make(map[int]bool)
+	for idx, instanceSelector := range r.Spec.Affinity.InstanceNodeSelectors {
+		idxPath := path.Index(idx)
+
+		if instanceSelector.InstanceSerial <= 0 {
+			allErrors = append(allErrors, field.Invalid(
+				idxPath.Child("instanceSerial"),
+				instanceSelector.InstanceSerial,
+				"the instance serial must be a positive number"))
+		} else if existingSerials[instanceSelector.InstanceSerial] {
+			allErrors = append(allErrors, field.Duplicate(
+				idxPath.Child("instanceSerial"),
+				instanceSelector.InstanceSerial))
+		}
+		existingSerials[instanceSelector.InstanceSerial] = true
+
+		if len(instanceSelector.NodeSelector) == 0 {
+			allErrors = append(allErrors, field.Required(
+				idxPath.Child("nodeSelector"),
+				"at least a label is required"))
+		}
+		allErrors = append(allErrors,
+			validation.ValidateLabels(instanceSelector.NodeSelector, idxPath.Child("nodeSelector"))...)
+	}
+
+	return allErrors
+}
+
 // validateBackupConfiguration validates the backup configuration
 func (r *Cluster) validateBackupConfiguration() field.ErrorList {
 	allErrors := field.ErrorList{}
@@ -1484,6 +2086,14 @@ func (r *Cluster) validateBackupConfiguration() field.ErrorList {
 		))
 	}
 
+	if _, err := r.Spec.Backup.BarmanObjectStore.Data.GetMaxBandwidth(); err != nil {
+		allErrors = append(allErrors, field.Invalid(
+			field.NewPath("spec", "backup", "barmanObjectStore", "data", "maxBandwidth"),
+			r.Spec.Backup.BarmanObjectStore.Data.MaxBandwidth,
+			err.Error(),
+		))
+	}
+
 	if r.Spec.Backup.RetentionPolicy != "" {
 		_, err := utils.ParsePolicy(r.Spec.Backup.RetentionPolicy)
 		if err != nil {
@@ -1495,9 +2105,204 @@ func (r *Cluster) validateBackupConfiguration() field.ErrorList {
 		}
 	}
 
-	return allErrors
-}
-
+	if r.Spec.Backup.RetentionPolicySchedule != "" {
+		schedulePath := field.NewPath("spec", "backup", "retentionPolicySchedule")
+		if _, err := cron.Parse(r.Spec.Backup.RetentionPolicySchedule); err != nil {
+			allErrors = append(allErrors, field.Invalid(
+				schedulePath,
+				r.Spec.Backup.RetentionPolicySchedule,
+				fmt.Sprintf("not a valid cron schedule: %v", err),
+			))
+		}
+		if r.Spec.Backup.RetentionPolicy == "" {
+			allErrors = append(allErrors, field.Invalid(
+				schedulePath,
+				r.Spec.Backup.RetentionPolicySchedule,
+				"the retention policy schedule requires a retention policy",
+			))
+		}
+	}
+
+	return allErrors
+}
+
+// validateBarmanEnv validates the custom environment variables and the proxy of
+// every object store used by the cluster
+func (r *Cluster) validateBarmanEnv() field.ErrorList {
+	var result field.ErrorList
+
+	if r.Spec.Backup != nil {
+		if r.Spec.Backup.BarmanObjectStore != nil {
+			path := field.NewPath("spec", "backup", "barmanObjectStore")
+			result = append(result, r.Spec.Backup.BarmanObjectStore.validateEnv(path)...)
+			result = append(result, r.Spec.Backup.BarmanObjectStore.validateProxy(path)...)
+		}
+		for idx := range r.Spec.Backup.AdditionalWALArchives {
+			path := field.NewPath("spec", "backup", "additionalWalArchives").Index(idx).Child("barmanObjectStore")
+			result = append(result, r.Spec.Backup.AdditionalWALArchives[idx].BarmanObjectStore.validateEnv(path)...)
+			result = append(result, r.Spec.Backup.AdditionalWALArchives[idx].BarmanObjectStore.validateProxy(path)...)
+		}
+	}
+
+	for idx, externalCluster := range r.Spec.ExternalClusters {
+		if externalCluster.BarmanObjectStore != nil {
+			path := field.NewPath("spec", "externalClusters").Index(idx).Child("barmanObjectStore")
+			result = append(result, externalCluster.BarmanObjectStore.validateEnv(path)...)
+			result = append(result, externalCluster.BarmanObjectStore.validateProxy(path)...)
+		}
+	}
+
+	return result
+}
+
+// validateEnv checks that the custom environment variables have a valid
+// name, don't override the ones managed by the operator, and only use the
+// supported sources
+func (configuration *BarmanObjectStoreConfiguration) validateEnv(path *field.Path) field.ErrorList {
+	var result field.ErrorList
+
+	for idx, variable := range configuration.Env {
+		variablePath := path.Child("env").Index(idx)
+
+		nameErrors := validationutil.IsEnvVarName(variable.Name)
+		for _, msg := range nameErrors {
+			result = append(result, field.Invalid(variablePath.Child("name"), variable.Name, msg))
+		}
+
+		switch {
+		case len(nameErrors) > 0:
+			// the name has already been reported as invalid
+		case IsBarmanManagedEnvVar(variable.Name):
+			result = append(result, field.Invalid(
+				variablePath.Child("name"),
+				variable.Name,
+				"this environment variable is managed by the operator"))
+		case !IsBarmanCustomizableEnvVar(variable.Name):
+			result = append(result, field.Invalid(
+				variablePath.Child("name"),
+				variable.Name,
+				"only the variables of the object store providers (AWS_*, AZURE_*, GOOGLE_*) "+
+					"and the proxy ones can be set"))
+		}
+
+		if source := variable.ValueFrom; source != nil {
+			if source.FieldRef != nil || source.ResourceFieldRef != nil ||
+				(source.SecretKeyRef == nil) == (source.ConfigMapKeyRef == nil) {
+				result = append(result, field.Invalid(
+					variablePath.Child("valueFrom"),
+					variable.ValueFrom,
+					"one and only one of secretKeyRef and configMapKeyRef is required"))
+			}
+			if variable.Value != "" {
+				result = append(result, field.Invalid(
+					variablePath.Child("value"),
+					variable.Value,
+					"value can't be used together with valueFrom"))
+			}
+		}
+	}
+
+	for idx, source := range configuration.EnvFrom {
+		sourcePath := path.Child("envFrom").Index(idx)
+
+		if (source.SecretRef == nil) == (source.ConfigMapRef == nil) {
+			result = append(result, field.Invalid(
+				sourcePath,
+				source,
+				"one and only one of secretRef and configMapRef is required"))
+		}
+
+		if source.Prefix != "" {
+			for _, msg := range validationutil.IsEnvVarName(source.Prefix) {
+				result = append(result, field.Invalid(sourcePath.Child("prefix"), source.Prefix, msg))
+			}
+		}
+	}
+
+	return result
+}
+
+// validateProxy checks that the proxy is an HTTP or HTTPS URL
+func (configuration *BarmanObjectStoreConfiguration) validateProxy(path *field.Path) field.ErrorList {
+	if configuration.Proxy == nil {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(configuration.Proxy.URL)
+	if err != nil || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") || proxyURL.Host == "" {
+		return field.ErrorList{field.Invalid(
+			path.Child("proxy", "url"),
+			configuration.Proxy.URL,
+			"the proxy must be an URL with the http or https scheme and a host")}
+	}
+
+	return nil
+}
+
+// validateAdditionalWALArchives validates the additional object stores
+// where the WAL files are archived
+func (r *Cluster) validateAdditionalWALArchives() field.ErrorList {
+	if r.Spec.Backup == nil || len(r.Spec.Backup.AdditionalWALArchives) == 0 {
+		return nil
+	}
+
+	var allErrors field.ErrorList
+	basePath := field.NewPath("spec", "backup", "additionalWalArchives")
+
+	if r.Spec.Backup.BarmanObjectStore == nil {
+		allErrors = append(allErrors, field.Invalid(
+			basePath,
+			r.Spec.Backup.AdditionalWALArchives,
+			"additional WAL archives require barmanObjectStore to be defined",
+		))
+	}
+
+	names := make(map[string]bool, len(r.Spec.Backup.AdditionalWALArchives))
+	for idx, destination := range r.Spec.Backup.AdditionalWALArchives {
+		path := basePath.Index(idx)
+
+		if names[destination.Name] {
+			allErrors = append(allErrors, field.Duplicate(path.Child("name"), destination.Name))
+		}
+		names[destination.Name] = true
+
+		configuration := destination.BarmanObjectStore
+		credentialsCount := 0
+		if configuration.BarmanCredentials.Azure != nil {
+			credentialsCount++
+			allErrors = append(allErrors, configuration.BarmanCredentials.Azure.validateAzureCredentials(
+				path.Child("barmanObjectStore", "azureCredentials"))...)
+		}
+		if configuration.BarmanCredentials.AWS != nil {
+			credentialsCount++
+			allErrors = append(allErrors, configuration.BarmanCredentials.AWS.validateAwsCredentials(
+				path.Child("barmanObjectStore", "s3Credentials"))...)
+		}
+		if configuration.BarmanCredentials.Google != nil {
+			credentialsCount++
+			allErrors = append(allErrors, configuration.BarmanCredentials.Google.validateGCSCredentials(
+				path.Child("barmanObjectStore", "googleCredentials"))...)
+		}
+		if credentialsCount != 1 {
+			allErrors = append(allErrors, field.Invalid(
+				path.Child("barmanObjectStore"),
+				destination.Name,
+				"one and only one of azureCredentials, s3Credentials and googleCredentials are required",
+			))
+		}
+
+		if configuration.EndpointCA != nil {
+			allErrors = append(allErrors, field.Invalid(
+				path.Child("barmanObjectStore", "endpointCA"),
+				configuration.EndpointCA.Name,
+				"endpointCA is not supported for additional WAL archives",
+			))
+		}
+	}
+
+	return allErrors
+}
+
 func (r *Cluster) validateReplicationSlots() field.ErrorList {
 	replicationSlots := r.Spec.ReplicationSlots
 	if replicationSlots == nil ||
@@ -1525,6 +2330,259 @@ func (r *Cluster) validateReplicationSlots() field.ErrorList {
 	}
 }
 
+// validateReplicationSlotsUpdateInterval checks that the replication slots
+// are not synchronized so often to load the primary with the queries of
+// the standbys. Existing clusters are checked only when the interval
+// changes, as shorter intervals are anyway raised to the minimum
+func (r *Cluster) validateReplicationSlotsUpdateInterval(old *Cluster) field.ErrorList {
+	updateInterval := 0
+	if r.Spec.ReplicationSlots != nil {
+		updateInterval = r.Spec.ReplicationSlots.UpdateInterval
+	}
+	if updateInterval <= 0 || updateInterval >= MinimumReplicationSlotsUpdateInterval {
+		return nil
+	}
+
+	if old != nil && old.Spec.ReplicationSlots != nil &&
+		old.Spec.ReplicationSlots.UpdateInterval == updateInterval {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", "replicationSlots", "updateInterval"),
+			updateInterval,
+			fmt.Sprintf("the replication slots can't be synchronized more often than every %d seconds",
+				MinimumReplicationSlotsUpdateInterval)),
+	}
+}
+
+// validateReplicationSlotsDatabase checks that the database used to
+// manage the replication slots accepts connections
+func (r *Cluster) validateReplicationSlotsDatabase() field.ErrorList {
+	if r.Spec.ReplicationSlots == nil || r.Spec.ReplicationSlots.Database != "template0" {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", "replicationSlots", "database"),
+			r.Spec.ReplicationSlots.Database,
+			"the template0 database doesn't accept connections and can't be used to manage replication slots"),
+	}
+}
+
+// validatePreferredPrimary checks that exactly one preference has been
+// expressed, and that the preferred instance is part of the cluster
+func (r *Cluster) validatePreferredPrimary() field.ErrorList {
+	preference := r.Spec.PreferredPrimary
+	if preference == nil {
+		return nil
+	}
+
+	path := field.NewPath("spec", "preferredPrimary")
+	switch {
+	case preference.InstanceSerial == 0 && preference.Zone == "":
+		return field.ErrorList{
+			field.Invalid(path, preference, "either instanceSerial or zone must be specified"),
+		}
+	case preference.InstanceSerial != 0 && preference.Zone != "":
+		return field.ErrorList{
+			field.Invalid(path, preference, "instanceSerial and zone are mutually exclusive"),
+		}
+	case preference.InstanceSerial < 0:
+		return field.ErrorList{
+			field.Invalid(path.Child("instanceSerial"), preference.InstanceSerial,
+				"the instance serial must be a positive number"),
+		}
+	}
+
+	return nil
+}
+
+// validateLogFormat checks that the requested log format is supported
+// by the PostgreSQL version in use
+func (r *Cluster) validateLogFormat() field.ErrorList {
+	if r.Spec.PostgresConfiguration.LogFormat != LogFormatJSON {
+		return nil
+	}
+
+	psqlVersion, err := r.GetPostgresqlVersion()
+	if err != nil {
+		// The validation error will be already raised by the
+		// validateImageName function
+		return nil
+	}
+
+	if psqlVersion >= 150000 {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", "postgresql", "logFormat"),
+			r.Spec.PostgresConfiguration.LogFormat,
+			"Cannot use the jsonlog log format. It requires PostgreSQL 15 or above"),
+	}
+}
+
+// validateWALKeepSize checks that the requested WAL retention is a valid
+// quantity fitting in the volume holding the WAL files
+func (r *Cluster) validateWALKeepSize() field.ErrorList {
+	if r.Spec.PostgresConfiguration.WALKeepSize == "" {
+		return nil
+	}
+
+	fieldPath := field.NewPath("spec", "postgresql", "walKeepSize")
+	walKeepSize, err := resource.ParseQuantity(r.Spec.PostgresConfiguration.WALKeepSize)
+	if err != nil || walKeepSize.Sign() <= 0 {
+		return field.ErrorList{
+			field.Invalid(
+				fieldPath,
+				r.Spec.PostgresConfiguration.WALKeepSize,
+				"walKeepSize must be a positive quantity"),
+		}
+	}
+
+	walVolumeSize := r.Spec.StorageConfiguration.Size
+	if r.ShouldCreateWalArchiveVolume() {
+		walVolumeSize = r.Spec.WalStorage.Size
+	}
+
+	// When the size of the volume is not known, e.g. when a PVC template
+	// is used, the retained WAL files can't be checked against it
+	volumeSize, err := resource.ParseQuantity(walVolumeSize)
+	if err != nil {
+		return nil
+	}
+
+	if walKeepSize.Cmp(volumeSize) >= 0 {
+		return field.ErrorList{
+			field.Invalid(
+				fieldPath,
+				r.Spec.PostgresConfiguration.WALKeepSize,
+				fmt.Sprintf("walKeepSize must be smaller than the size of the volume holding the WAL files (%s)",
+					walVolumeSize)),
+		}
+	}
+
+	return nil
+}
+
+// validateMemoryPercentageParameters checks that the memory parameters
+// expressed as a percentage are within bounds and can be resolved against
+// the memory of the PostgreSQL container
+func (r *Cluster) validateMemoryPercentageParameters() field.ErrorList {
+	var result field.ErrorList
+
+	config := r.Spec.PostgresConfiguration
+	basePath := field.NewPath("spec", "postgresql")
+
+	if config.SharedBuffersPercentage != nil &&
+		(*config.SharedBuffersPercentage < 1 || *config.SharedBuffersPercentage > MaxSharedBuffersPercentage) {
+		result = append(result, field.Invalid(
+			basePath.Child("sharedBuffersPercentage"),
+			*config.SharedBuffersPercentage,
+			fmt.Sprintf("sharedBuffersPercentage must be between 1 and %d", MaxSharedBuffersPercentage)))
+	}
+
+	if config.EffectiveCacheSizePercentage != nil &&
+		(*config.EffectiveCacheSizePercentage < 1 ||
+			*config.EffectiveCacheSizePercentage > MaxEffectiveCacheSizePercentage) {
+		result = append(result, field.Invalid(
+			basePath.Child("effectiveCacheSizePercentage"),
+			*config.EffectiveCacheSizePercentage,
+			fmt.Sprintf("effectiveCacheSizePercentage must be between 1 and %d", MaxEffectiveCacheSizePercentage)))
+	}
+
+	if (config.SharedBuffersPercentage != nil || config.EffectiveCacheSizePercentage != nil) &&
+		r.GetMemoryForPercentageParameters() == nil {
+		result = append(result, field.Required(
+			field.NewPath("spec", "resources", "limits", "memory"),
+			"a memory limit or request is required to express memory parameters as a percentage"))
+	}
+
+	return result
+}
+
+// validateIdleInTransactionSessionTimeout checks that the timeout of the
+// sessions idle in transaction is either disabled or not too short
+func (r *Cluster) validateIdleInTransactionSessionTimeout() field.ErrorList {
+	timeout := r.Spec.PostgresConfiguration.IdleInTransactionSessionTimeout
+	if timeout == nil || *timeout == 0 {
+		return nil
+	}
+
+	if *timeout < MinIdleInTransactionSessionTimeout {
+		return field.ErrorList{
+			field.Invalid(
+				field.NewPath("spec", "postgresql", "idleInTransactionSessionTimeout"),
+				*timeout,
+				fmt.Sprintf("idleInTransactionSessionTimeout must be 0, to disable it, or at least %d seconds",
+					MinIdleInTransactionSessionTimeout)),
+		}
+	}
+
+	return nil
+}
+
+// validateAutovacuum checks that the autovacuum scale factors are
+// numbers between 0 and 100, as required by PostgreSQL
+func (r *Cluster) validateAutovacuum() field.ErrorList {
+	autovacuum := r.Spec.PostgresConfiguration.Autovacuum
+	if autovacuum == nil {
+		return nil
+	}
+
+	var result field.ErrorList
+	scaleFactors := []struct {
+		name  string
+		value string
+	}{
+		{name: "vacuumScaleFactor", value: autovacuum.VacuumScaleFactor},
+		{name: "analyzeScaleFactor", value: autovacuum.AnalyzeScaleFactor},
+	}
+	for _, scaleFactor := range scaleFactors {
+		if scaleFactor.value == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(scaleFactor.value, 64)
+		if err != nil || value < 0 || value > 100 {
+			result = append(result, field.Invalid(
+				field.NewPath("spec", "postgresql", "autovacuum", scaleFactor.name),
+				scaleFactor.value,
+				fmt.Sprintf("%s must be a number between 0 and 100", scaleFactor.name)))
+		}
+	}
+
+	return result
+}
+
+// validatePrePromotionHook checks that the pre-promotion hook contains
+// at least a statement, and that none of them is empty
+func (r *Cluster) validatePrePromotionHook() field.ErrorList {
+	hook := r.Spec.PostgresConfiguration.PrePromotionHook
+	if hook == nil {
+		return nil
+	}
+
+	var result field.ErrorList
+	basePath := field.NewPath("spec", "postgresql", "prePromotionHook", "sql")
+
+	if len(hook.SQL) == 0 {
+		result = append(result, field.Required(basePath, "at least a statement is required"))
+	}
+
+	for idx, statement := range hook.SQL {
+		if strings.TrimSpace(statement) == "" {
+			result = append(result, field.Invalid(basePath.Index(idx), statement, "the statement is empty"))
+		}
+	}
+
+	return result
+}
+
 func (r *Cluster) validateReplicationSlotsChange(old *Cluster) field.ErrorList {
 	newReplicationSlots := r.Spec.ReplicationSlots
 	oldReplicationSlots := old.Spec.ReplicationSlots
@@ -1670,3 +2728,289 @@ func (gcs *GoogleCredentials) validateGCSCredentials(path *field.Path) field.Err
 
 	return allErrors
 }
+
+// validateMaintenanceWindow checks that the days, the times and the
+// time zone of the maintenance window are valid
+func (r *Cluster) validateMaintenanceWindow() field.ErrorList {
+	window := r.Spec.MaintenanceWindow
+	if window == nil {
+		return nil
+	}
+
+	var result field.ErrorList
+	path := field.NewPath("spec", "maintenanceWindow")
+
+	for idx, day := range window.Days {
+		if _, ok := maintenanceWindowDays[day]; !ok {
+			result = append(result, field.Invalid(
+				path.Child("days").Index(idx),
+				day,
+				"must be the lowercase name of a day of the week"))
+		}
+	}
+
+	startTime, startErr := time.Parse("15:04", window.StartTime)
+	if startErr != nil {
+		result = append(result, field.Invalid(
+			path.Child("startTime"),
+			window.StartTime,
+			"must be a time in the HH:MM format"))
+	}
+
+	endTime, endErr := time.Parse("15:04", window.EndTime)
+	if endErr != nil {
+		result = append(result, field.Invalid(
+			path.Child("endTime"),
+			window.EndTime,
+			"must be a time in the HH:MM format"))
+	}
+
+	if startErr == nil && endErr == nil && startTime.Equal(endTime) {
+		result = append(result, field.Invalid(
+			path.Child("endTime"),
+			window.EndTime,
+			"the maintenance window can't start and end at the same time"))
+	}
+
+	if _, err := window.GetLocation(); err != nil {
+		result = append(result, field.Invalid(
+			path.Child("timeZone"),
+			window.TimeZone,
+			fmt.Sprintf("invalid time zone: %v", err)))
+	}
+
+	return result
+}
+
+// validateScheduledMaintenance checks that the scheduled maintenance
+// operations have a unique name, a valid schedule and a target database
+func (r *Cluster) validateScheduledMaintenance() field.ErrorList {
+	var result field.ErrorList
+	path := field.NewPath("spec", "scheduledMaintenance")
+
+	names := stringset.New()
+	for idx, maintenance := range r.Spec.ScheduledMaintenance {
+		itemPath := path.Index(idx)
+
+		switch {
+		case maintenance.Name == "":
+			result = append(result, field.Invalid(
+				itemPath.Child("name"),
+				maintenance.Name,
+				"the name of a scheduled maintenance can't be empty"))
+		case names.Has(maintenance.Name):
+			result = append(result, field.Duplicate(
+				itemPath.Child("name"),
+				maintenance.Name))
+		default:
+			names.Put(maintenance.Name)
+		}
+
+		if _, err := cron.Parse(maintenance.Schedule); err != nil {
+			result = append(result, field.Invalid(
+				itemPath.Child("schedule"),
+				maintenance.Schedule,
+				fmt.Sprintf("invalid schedule: %v", err)))
+		}
+
+		switch maintenance.Operation {
+		case MaintenanceOperationVacuum, MaintenanceOperationAnalyze, MaintenanceOperationVacuumAnalyze:
+		default:
+			result = append(result, field.NotSupported(
+				itemPath.Child("operation"),
+				maintenance.Operation,
+				[]string{
+					string(MaintenanceOperationVacuum),
+					string(MaintenanceOperationAnalyze),
+					string(MaintenanceOperationVacuumAnalyze),
+				}))
+		}
+
+		if maintenance.Database == "" {
+			result = append(result, field.Invalid(
+				itemPath.Child("database"),
+				maintenance.Database,
+				"the database of a scheduled maintenance can't be empty"))
+		}
+
+		for tableIdx, table := range maintenance.Tables {
+			if table == "" {
+				result = append(result, field.Invalid(
+					itemPath.Child("tables").Index(tableIdx),
+					table,
+					"table names can't be empty"))
+			}
+		}
+	}
+
+	return result
+}
+
+// validateSharedPreloadLibraries checks that the requested shared preload
+// libraries can be safely written in the PostgreSQL configuration. Their
+// existence can only be checked by the instances, as it depends on the image
+func (r *Cluster) validateSharedPreloadLibraries() field.ErrorList {
+	var result field.ErrorList
+	path := field.NewPath("spec", "postgresql", "shared_preload_libraries")
+
+	existingLibraries := stringset.New()
+	for idx, library := range r.Spec.PostgresConfiguration.AdditionalLibraries {
+		switch {
+		case library == "":
+			result = append(result, field.Invalid(path.Index(idx), library,
+				"the library name can't be empty"))
+		case strings.ContainsAny(library, ",'\"\\ \t\n\r"):
+			result = append(result, field.Invalid(path.Index(idx), library,
+				"the library name can't contain commas, quotes, backslashes or whitespace characters"))
+		case existingLibraries.Has(library):
+			result = append(result, field.Duplicate(path.Index(idx), library))
+		}
+		existingLibraries.Put(library)
+	}
+
+	return result
+}
+
+// validateShutdownSettings checks the shutdown mode and timeout
+// used during planned operations
+func (r *Cluster) validateShutdownSettings() field.ErrorList {
+	var result field.ErrorList
+
+	switch r.Spec.ShutdownMode {
+	case "", ShutdownModeSmart, ShutdownModeFast, ShutdownModeImmediate:
+	default:
+		result = append(result, field.NotSupported(
+			field.NewPath("spec", "shutdownMode"),
+			r.Spec.ShutdownMode,
+			[]string{string(ShutdownModeSmart), string(ShutdownModeFast), string(ShutdownModeImmediate)}))
+	}
+
+	if r.Spec.ShutdownTimeout < 0 {
+		result = append(result, field.Invalid(
+			field.NewPath("spec", "shutdownTimeout"),
+			r.Spec.ShutdownTimeout,
+			"shutdownTimeout must not be negative"))
+	}
+
+	if gracePeriod := r.Spec.TerminationGracePeriodSeconds; gracePeriod != nil &&
+		*gracePeriod < int64(r.GetShutdownTimeout()) {
+		result = append(result, field.Invalid(
+			field.NewPath("spec", "terminationGracePeriodSeconds"),
+			*gracePeriod,
+			fmt.Sprintf("terminationGracePeriodSeconds must not be shorter than the shutdown timeout (%v seconds)",
+				r.GetShutdownTimeout())))
+	}
+
+	return result
+}
+
+// validateReadOnlyService checks the maximum lag of the replicas
+// included in the read-only service
+func (r *Cluster) validateReadOnlyService() field.ErrorList {
+	if r.Spec.ReadOnlyService == nil || r.Spec.ReadOnlyService.MaxLag == "" {
+		return nil
+	}
+
+	maxLag, err := resource.ParseQuantity(r.Spec.ReadOnlyService.MaxLag)
+	if err != nil || maxLag.Sign() < 0 {
+		return field.ErrorList{
+			field.Invalid(
+				field.NewPath("spec", "readOnlyService", "maxLag"),
+				r.Spec.ReadOnlyService.MaxLag,
+				"maxLag must be a non-negative quantity"),
+		}
+	}
+
+	return nil
+}
+
+// serviceFieldNames are the names of the fields holding the configuration
+// of the services generated by the operator
+var serviceFieldNames = []string{"readWriteService", "readOnlyService", "readService"}
+
+// getServiceConfigurations gets the configuration of the services generated
+// by the operator, indexed by the name of the corresponding field
+func (r *Cluster) getServiceConfigurations() map[string]*ServiceConfiguration {
+	return map[string]*ServiceConfiguration{
+		"readWriteService": r.GetReadWriteServiceConfiguration(),
+		"readOnlyService":  r.GetReadOnlyServiceConfiguration(),
+		"readService":      r.GetReadServiceConfiguration(),
+	}
+}
+
+// validateServices checks the type, annotations and labels requested
+// for the services of the cluster
+func (r *Cluster) validateServices() field.ErrorList {
+	var result field.ErrorList
+
+	services := r.getServiceConfigurations()
+	for _, name := range serviceFieldNames {
+		service := services[name]
+		if service == nil {
+			continue
+		}
+		servicePath := field.NewPath("spec", name)
+
+		switch service.GetType() {
+		case v1.ServiceTypeClusterIP, v1.ServiceTypeNodePort, v1.ServiceTypeLoadBalancer:
+		default:
+			result = append(result, field.NotSupported(
+				servicePath.Child("type"),
+				service.Type,
+				[]string{
+					string(v1.ServiceTypeClusterIP),
+					string(v1.ServiceTypeNodePort),
+					string(v1.ServiceTypeLoadBalancer),
+				}))
+		}
+
+		if service.Headless && service.GetType() != v1.ServiceTypeClusterIP {
+			result = append(result, field.Invalid(
+				servicePath.Child("headless"),
+				service.Headless,
+				fmt.Sprintf("a headless service must be of type %s", v1.ServiceTypeClusterIP)))
+		}
+
+		result = append(result,
+			apivalidation.ValidateAnnotations(service.Annotations, servicePath.Child("annotations"))...)
+		result = append(result,
+			validation.ValidateLabels(service.Labels, servicePath.Child("labels"))...)
+	}
+
+	return result
+}
+
+// validateServicesChange checks that the services are not turned into
+// headless ones, or vice versa, as the cluster IP of a service is immutable
+func (r *Cluster) validateServicesChange(old *Cluster) field.ErrorList {
+	var result field.ErrorList
+
+	services := r.getServiceConfigurations()
+	oldServices := old.getServiceConfigurations()
+	for _, name := range serviceFieldNames {
+		service := services[name]
+		if service.IsHeadless() != oldServices[name].IsHeadless() {
+			result = append(result, field.Invalid(
+				field.NewPath("spec", name, "headless"),
+				service.IsHeadless(),
+				"cannot change whether a service is headless after the cluster has been created"))
+		}
+	}
+
+	return result
+}
+
+// validateProbes checks that the readiness probe can connect to the
+// requested database
+func (r *Cluster) validateProbes() field.ErrorList {
+	if r.Spec.Probes == nil || r.Spec.Probes.Database != "template0" {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", "probes", "database"),
+			r.Spec.Probes.Database,
+			"the template0 database doesn't accept connections and can't be used by the readiness probe"),
+	}
+}
@@ -20,10 +20,13 @@ import (
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 
 	"github.com/cloudnative-pg/cloudnative-pg/internal/configuration"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/versions"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -225,6 +228,70 @@ var _ = Describe("certificates options validation", func() {
 		result := cluster.validateCerts()
 		Expect(len(result)).To(Equal(1))
 	})
+
+	It("doesn't complain about valid client certificate users", func() {
+		cluster := Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-example"},
+			Spec: ClusterSpec{
+				Certificates: &CertificatesConfiguration{
+					ClientCertificateUsers: []string{"app", "reporting_user"},
+				},
+			},
+		}
+		Expect(cluster.validateCerts()).To(BeEmpty())
+	})
+
+	It("complains about client certificate users managed by the operator", func() {
+		cluster := Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-example"},
+			Spec: ClusterSpec{
+				Certificates: &CertificatesConfiguration{
+					ClientCertificateUsers: []string{
+						StreamingReplicationUser,
+						PGBouncerPoolerUserName,
+						ReplicationSlotsUser,
+					},
+				},
+			},
+		}
+		Expect(cluster.validateCerts()).To(HaveLen(3))
+	})
+
+	It("complains about client certificate users matching more than a single role", func() {
+		cluster := Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-example"},
+			Spec: ClusterSpec{
+				Certificates: &CertificatesConfiguration{
+					ClientCertificateUsers: []string{"all", "sameuser", "samerole", "+app", "@users"},
+				},
+			},
+		}
+		Expect(cluster.validateCerts()).To(HaveLen(5))
+	})
+
+	It("complains about requiring a client certificate for the superuser", func() {
+		cluster := Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-example"},
+			Spec: ClusterSpec{
+				Certificates: &CertificatesConfiguration{
+					ClientCertificateUsers: []string{"postgres"},
+				},
+			},
+		}
+		Expect(cluster.validateCerts()).To(HaveLen(1))
+	})
+
+	It("complains about client certificate users producing invalid or duplicate secret names", func() {
+		cluster := Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-example"},
+			Spec: ClusterSpec{
+				Certificates: &CertificatesConfiguration{
+					ClientCertificateUsers: []string{"", "App", "app_user", "app-user"},
+				},
+			},
+		}
+		Expect(cluster.validateCerts()).To(HaveLen(3))
+	})
 })
 
 var _ = Describe("initdb options validation", func() {
@@ -614,6 +681,67 @@ var _ = Describe("Defaulting webhook", func() {
 	})
 })
 
+var _ = Describe("Minimum PostgreSQL version validation", func() {
+	AfterEach(func() {
+		configuration.Current.MinimumPostgresMajorVersion = 0
+		configuration.Current.PostgresImageName = versions.DefaultImageName
+	})
+
+	It("doesn't complain when no minimum version is configured", func() {
+		cluster := Cluster{Spec: ClusterSpec{ImageName: "postgres:10.4"}}
+		Expect(cluster.validateMinimumPostgresVersion()).To(BeEmpty())
+	})
+
+	It("accepts the versions equal or newer than the minimum one", func() {
+		configuration.Current.MinimumPostgresMajorVersion = 12
+		cluster := Cluster{Spec: ClusterSpec{ImageName: "postgres:12.13"}}
+		Expect(cluster.validateMinimumPostgresVersion()).To(BeEmpty())
+
+		cluster.Spec.ImageName = "postgres:15.1-3"
+		Expect(cluster.validateMinimumPostgresVersion()).To(BeEmpty())
+	})
+
+	It("rejects the versions older than the minimum one", func() {
+		configuration.Current.MinimumPostgresMajorVersion = 12
+		cluster := Cluster{Spec: ClusterSpec{ImageName: "postgres:11.18"}}
+		result := cluster.validateMinimumPostgresVersion()
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Field).To(Equal("spec.imageName"))
+		Expect(result[0].Detail).To(ContainSubstring("requires PostgreSQL 12 or newer"))
+	})
+
+	It("checks the default image when no image name is specified", func() {
+		configuration.Current.MinimumPostgresMajorVersion = 99
+		var cluster Cluster
+		Expect(cluster.validateMinimumPostgresVersion()).To(HaveLen(1))
+	})
+
+	It("rejects the default images without a version tag", func() {
+		configuration.Current.MinimumPostgresMajorVersion = 12
+		configuration.Current.PostgresImageName = "postgres@sha256:" + strings.Repeat("a", 64)
+		var cluster Cluster
+		result := cluster.validateMinimumPostgresVersion()
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Detail).To(ContainSubstring("cannot detect the PostgreSQL version"))
+	})
+
+	It("leaves the image names without a version tag to the image name validation", func() {
+		configuration.Current.MinimumPostgresMajorVersion = 12
+		cluster := Cluster{Spec: ClusterSpec{ImageName: "postgres@sha256:" + strings.Repeat("a", 64)}}
+		Expect(cluster.validateMinimumPostgresVersion()).To(BeEmpty())
+		Expect(cluster.validateImageName()).To(HaveLen(1))
+	})
+
+	It("is only enforced when a cluster is created", func() {
+		configuration.Current.MinimumPostgresMajorVersion = 12
+		cluster := &Cluster{Spec: ClusterSpec{ImageName: "postgres:11.18"}}
+		err := cluster.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("requires PostgreSQL 12 or newer"))
+		Expect(cluster.ValidateChanges(cluster.DeepCopy())).To(BeEmpty())
+	})
+})
+
 var _ = Describe("Image name validation", func() {
 	It("doesn't complain if the user simply accept the default", func() {
 		var cluster Cluster
@@ -790,6 +918,74 @@ var _ = Describe("validate image name change", func() {
 		}
 		Expect(len(clusterNew.validateImageChange("postgres:12.1"))).To(Equal(0))
 	})
+
+	It("explains whether the major version change is a downgrade or an upgrade", func() {
+		clusterNew := Cluster{
+			Spec: ClusterSpec{
+				ImageName: "postgres:11.0",
+			},
+		}
+		result := clusterNew.validateImageChange("postgres:12.0")
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Detail).To(ContainSubstring("can't downgrade"))
+
+		clusterNew.Spec.ImageName = "postgres:14.1"
+		result = clusterNew.validateImageChange("postgres:12.0")
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Detail).To(ContainSubstring("import the data in a new cluster"))
+	})
+
+	It("doesn't complain when the major version check is skipped", func() {
+		clusterNew := Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"cnpg.io/skipMajorVersionCheck": "enabled",
+				},
+			},
+			Spec: ClusterSpec{
+				ImageName: "postgres:11.0",
+			},
+		}
+		Expect(clusterNew.validateImageChange("postgres:12.0")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("credentials regeneration on recovery", func() {
+	It("is allowed when the recovered cluster will be promoted", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Bootstrap: &BootstrapConfiguration{
+					Recovery: &BootstrapRecovery{
+						RegenerateCredentials: true,
+						RecoveryTarget: &RecoveryTarget{
+							TargetTime: "2021-09-01 10:22:47.000000+06",
+						},
+					},
+				},
+			},
+		}
+		Expect(cluster.validateRecoveryRegenerateCredentials()).To(BeEmpty())
+		Expect(cluster.ShouldRecoveryRegenerateCredentials()).To(BeTrue())
+	})
+
+	It("is not allowed for replica clusters", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Bootstrap: &BootstrapConfiguration{
+					Recovery: &BootstrapRecovery{
+						Source:                "test",
+						RegenerateCredentials: true,
+					},
+				},
+				ReplicaCluster: &ReplicaClusterConfiguration{
+					Enabled: true,
+					Source:  "test",
+				},
+			},
+		}
+		Expect(cluster.validateRecoveryRegenerateCredentials()).To(HaveLen(1))
+		Expect(cluster.ShouldRecoveryRegenerateCredentials()).To(BeFalse())
+	})
 })
 
 var _ = Describe("recovery target", func() {
@@ -1167,6 +1363,44 @@ var _ = Describe("Number of synchronous replicas", func() {
 		}
 		Expect(cluster.validateMaxSyncReplicas()).To(BeEmpty())
 	})
+
+	It("complains once if minSyncReplicas can never be satisfied by the standbys", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Instances:       3,
+				MinSyncReplicas: 3,
+				MaxSyncReplicas: 3,
+			},
+		}
+		// minSyncReplicas can't exceed maxSyncReplicas, which is the
+		// one being lower than the number of instances
+		Expect(cluster.validateMinSyncReplicas()).To(BeEmpty())
+		Expect(cluster.validateMaxSyncReplicas()).To(HaveLen(1))
+	})
+
+	It("allows every standby to be synchronous", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Instances:       3,
+				MinSyncReplicas: 2,
+				MaxSyncReplicas: 2,
+			},
+		}
+		Expect(cluster.validateMinSyncReplicas()).To(BeEmpty())
+		Expect(cluster.validateMaxSyncReplicas()).To(BeEmpty())
+	})
+
+	It("complains about synchronous replication in a single instance cluster", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Instances:       1,
+				MinSyncReplicas: 1,
+				MaxSyncReplicas: 1,
+			},
+		}
+		Expect(cluster.validateMinSyncReplicas()).To(BeEmpty())
+		Expect(cluster.validateMaxSyncReplicas()).To(HaveLen(1))
+	})
 })
 
 var _ = Describe("storage configuration validation", func() {
@@ -1564,6 +1798,103 @@ var _ = Describe("toleration validation", func() {
 	})
 })
 
+var _ = Describe("validate topology spread constraints", func() {
+	It("doesn't complain if no constraint is defined", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validateTopologySpreadConstraints()).To(BeEmpty())
+	})
+
+	It("doesn't complain with valid constraints", func() {
+		minDomains := int32(3)
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				TopologySpreadConstraints: []v1.TopologySpreadConstraint{
+					{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: v1.DoNotSchedule,
+						MinDomains:        &minDomains,
+					},
+					{
+						MaxSkew:           1,
+						TopologyKey:       "kubernetes.io/hostname",
+						WhenUnsatisfiable: v1.ScheduleAnyway,
+					},
+				},
+			},
+		}
+		Expect(cluster.validateTopologySpreadConstraints()).To(BeEmpty())
+	})
+
+	It("complains about invalid constraints", func() {
+		minDomains := int32(3)
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				TopologySpreadConstraints: []v1.TopologySpreadConstraint{
+					{
+						MaxSkew:           0,
+						WhenUnsatisfiable: "Sometimes",
+					},
+					{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: v1.ScheduleAnyway,
+						MinDomains:        &minDomains,
+					},
+				},
+			},
+		}
+		Expect(cluster.validateTopologySpreadConstraints()).To(HaveLen(4))
+	})
+
+	It("complains about duplicated constraints", func() {
+		constraint := v1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: v1.DoNotSchedule,
+		}
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				TopologySpreadConstraints: []v1.TopologySpreadConstraint{constraint, constraint},
+			},
+		}
+		Expect(cluster.validateTopologySpreadConstraints()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("validate instance node selectors", func() {
+	It("doesn't complain with valid node selectors", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Affinity: AffinityConfiguration{
+					InstanceNodeSelectors: []InstanceNodeSelector{
+						{InstanceSerial: 1, NodeSelector: map[string]string{"topology.kubernetes.io/zone": "a"}},
+						{InstanceSerial: 2, NodeSelector: map[string]string{"topology.kubernetes.io/zone": "b"}},
+					},
+				},
+			},
+		}
+		Expect(cluster.validateInstanceNodeSelectors()).To(BeEmpty())
+	})
+
+	It("complains about invalid serials, duplicates and labels", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Affinity: AffinityConfiguration{
+					InstanceNodeSelectors: []InstanceNodeSelector{
+						{InstanceSerial: 0, NodeSelector: map[string]string{"zone": "a"}},
+						{InstanceSerial: 1, NodeSelector: map[string]string{"zone": "a"}},
+						{InstanceSerial: 1, NodeSelector: map[string]string{"zone": "b"}},
+						{InstanceSerial: 2},
+						{InstanceSerial: 3, NodeSelector: map[string]string{"zone": "not valid!"}},
+					},
+				},
+			},
+		}
+		Expect(cluster.validateInstanceNodeSelectors()).To(HaveLen(4))
+	})
+})
+
 var _ = Describe("validate anti-affinity", func() {
 	t := true
 	f := false
@@ -1972,56 +2303,333 @@ var _ = Describe("Backup validation", func() {
 		err := cluster.validateBackupConfiguration()
 		Expect(len(err)).To(Equal(2))
 	})
-})
 
-var _ = Describe("Default monitoring queries", func() {
-	It("correctly set the default monitoring queries configmap and secret when none is already specified", func() {
-		cluster := &Cluster{}
-		cluster.defaultMonitoringQueries(&configuration.Data{
-			MonitoringQueriesSecret:    "test-secret",
-			MonitoringQueriesConfigmap: "test-configmap",
-		})
-		Expect(cluster.Spec.Monitoring).NotTo(BeNil())
-		Expect(cluster.Spec.Monitoring.CustomQueriesConfigMap).NotTo(BeEmpty())
-		Expect(cluster.Spec.Monitoring.CustomQueriesConfigMap).
-			To(ContainElement(ConfigMapKeySelector{
-				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringConfigMapName},
-				Key:                  DefaultMonitoringKey,
-			}))
-		Expect(cluster.Spec.Monitoring.CustomQueriesSecret).NotTo(BeEmpty())
-		Expect(cluster.Spec.Monitoring.CustomQueriesSecret).
-			To(ContainElement(SecretKeySelector{
-				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringSecretName},
-				Key:                  DefaultMonitoringKey,
-			}))
+	It("accepts a valid retention policy schedule", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore: &BarmanObjectStoreConfiguration{
+						BarmanCredentials: BarmanCredentials{
+							AWS: &S3Credentials{InheritFromIAMRole: true},
+						},
+					},
+					RetentionPolicy:         "7d",
+					RetentionPolicySchedule: "0 0 3 * * *",
+				},
+			},
+		}
+		Expect(cluster.validateBackupConfiguration()).To(BeEmpty())
 	})
-	testCluster := &Cluster{Spec: ClusterSpec{Monitoring: &MonitoringConfiguration{
-		CustomQueriesConfigMap: []ConfigMapKeySelector{
-			{
-				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringConfigMapName},
-				Key:                  "test2",
+
+	It("complains if the retention policy schedule is not valid", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore: &BarmanObjectStoreConfiguration{
+						BarmanCredentials: BarmanCredentials{
+							AWS: &S3Credentials{InheritFromIAMRole: true},
+						},
+					},
+					RetentionPolicy:         "7d",
+					RetentionPolicySchedule: "every night",
+				},
 			},
-		},
-		CustomQueriesSecret: []SecretKeySelector{
-			{
-				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringConfigMapName},
-				Key:                  "test3",
+		}
+		Expect(cluster.validateBackupConfiguration()).To(HaveLen(1))
+	})
+
+	It("complains if the retention policy schedule has no retention policy", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore: &BarmanObjectStoreConfiguration{
+						BarmanCredentials: BarmanCredentials{
+							AWS: &S3Credentials{InheritFromIAMRole: true},
+						},
+					},
+					RetentionPolicySchedule: "0 0 3 * * *",
+				},
 			},
-		},
-	}}}
-	It("correctly set the default monitoring queries configmap when other metrics are already specified", func() {
-		modifiedCluster := testCluster.DeepCopy()
-		modifiedCluster.defaultMonitoringQueries(&configuration.Data{
-			MonitoringQueriesConfigmap: "test-configmap",
-		})
+		}
+		Expect(cluster.validateBackupConfiguration()).To(HaveLen(1))
+	})
 
-		Expect(modifiedCluster.Spec.Monitoring).NotTo(BeNil())
-		Expect(modifiedCluster.Spec.Monitoring.CustomQueriesConfigMap).NotTo(BeEmpty())
-		Expect(modifiedCluster.Spec.Monitoring.CustomQueriesSecret).NotTo(BeEmpty())
-		Expect(modifiedCluster.Spec.Monitoring.CustomQueriesConfigMap).
-			To(ContainElement(ConfigMapKeySelector{
-				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringConfigMapName},
-				Key:                  "test2",
+	It("accepts a valid maximum bandwidth", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore: &BarmanObjectStoreConfiguration{
+						BarmanCredentials: BarmanCredentials{
+							AWS: &S3Credentials{InheritFromIAMRole: true},
+						},
+						Data: &DataBackupConfiguration{MaxBandwidth: "50Mi"},
+					},
+				},
+			},
+		}
+		Expect(cluster.validateBackupConfiguration()).To(BeEmpty())
+	})
+
+	It("complains if the maximum bandwidth is not valid", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore: &BarmanObjectStoreConfiguration{
+						BarmanCredentials: BarmanCredentials{
+							AWS: &S3Credentials{InheritFromIAMRole: true},
+						},
+						Data: &DataBackupConfiguration{MaxBandwidth: "fast"},
+					},
+				},
+			},
+		}
+		Expect(cluster.validateBackupConfiguration()).To(HaveLen(1))
+
+		cluster.Spec.Backup.BarmanObjectStore.Data.MaxBandwidth = "-10Mi"
+		Expect(cluster.validateBackupConfiguration()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("barman-cloud environment validation", func() {
+	clusterWithEnv := func(env []v1.EnvVar, envFrom []v1.EnvFromSource) *Cluster {
+		return &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore: &BarmanObjectStoreConfiguration{
+						Env:     env,
+						EnvFrom: envFrom,
+					},
+				},
+			},
+		}
+	}
+
+	It("accepts values and references to secrets and config maps", func() {
+		cluster := clusterWithEnv(
+			[]v1.EnvVar{
+				{Name: "HTTPS_PROXY", Value: "http://proxy:3128"},
+				{Name: "AWS_REGION", ValueFrom: &v1.EnvVarSource{
+					ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "options"},
+						Key:                  "region",
+					},
+				}},
+			},
+			[]v1.EnvFromSource{
+				{Prefix: "AWS_", SecretRef: &v1.SecretEnvSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: "proxy"},
+				}},
+			},
+		)
+		Expect(cluster.validateBarmanEnv()).To(BeEmpty())
+	})
+
+	It("rejects invalid names and the variables managed by the operator", func() {
+		cluster := clusterWithEnv([]v1.EnvVar{
+			{Name: "1INVALID", Value: "value"},
+			{Name: "AWS_ACCESS_KEY_ID", Value: "value"},
+		}, nil)
+		Expect(cluster.validateBarmanEnv()).To(HaveLen(2))
+	})
+
+	It("rejects the variables not belonging to the object store providers", func() {
+		cluster := clusterWithEnv([]v1.EnvVar{
+			{Name: "PATH", Value: "/tmp"},
+			{Name: "LD_PRELOAD", Value: "/tmp/library.so"},
+			{Name: "PGHOST", Value: "/tmp"},
+			{Name: "AZURE_STORAGE_ENDPOINT", Value: "https://storage.example.com"},
+		}, nil)
+		result := cluster.validateBarmanEnv()
+		Expect(result).To(HaveLen(3))
+		Expect(result[0].Detail).To(ContainSubstring("object store providers"))
+	})
+
+	It("rejects unsupported sources", func() {
+		cluster := clusterWithEnv(
+			[]v1.EnvVar{
+				{Name: "AWS_POD_NAME", ValueFrom: &v1.EnvVarSource{
+					FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"},
+				}},
+			},
+			[]v1.EnvFromSource{{Prefix: "CUSTOM_"}},
+		)
+		Expect(cluster.validateBarmanEnv()).To(HaveLen(2))
+	})
+
+	It("validates the external clusters", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ExternalClusters: []ExternalCluster{
+					{
+						Name: "origin",
+						BarmanObjectStore: &BarmanObjectStoreConfiguration{
+							Env: []v1.EnvVar{{Name: "AZURE_STORAGE_KEY", Value: "value"}},
+						},
+					},
+				},
+			},
+		}
+		Expect(cluster.validateBarmanEnv()).To(HaveLen(1))
+	})
+
+	It("validates the URL of the proxy", func() {
+		clusterWithProxy := func(proxyURL string) *Cluster {
+			cluster := clusterWithEnv(nil, nil)
+			cluster.Spec.Backup.BarmanObjectStore.Proxy = &ProxyConfiguration{URL: proxyURL}
+			return cluster
+		}
+
+		Expect(clusterWithProxy("http://proxy.example.com:3128").validateBarmanEnv()).To(BeEmpty())
+		Expect(clusterWithProxy("https://proxy.example.com").validateBarmanEnv()).To(BeEmpty())
+		Expect(clusterWithProxy("socks5://proxy.example.com:1080").validateBarmanEnv()).To(HaveLen(1))
+		Expect(clusterWithProxy("http://").validateBarmanEnv()).To(HaveLen(1))
+		Expect(clusterWithProxy("proxy.example.com:3128").validateBarmanEnv()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Additional WAL archives validation", func() {
+	s3Destination := func(name string) WALArchiveDestination {
+		return WALArchiveDestination{
+			Name: name,
+			BarmanObjectStore: BarmanObjectStoreConfiguration{
+				DestinationPath: "s3://dr-bucket/",
+				BarmanCredentials: BarmanCredentials{
+					AWS: &S3Credentials{InheritFromIAMRole: true},
+				},
+			},
+		}
+	}
+
+	It("doesn't complain if there are no additional destinations", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{},
+			},
+		}
+		Expect(cluster.validateAdditionalWALArchives()).To(BeEmpty())
+	})
+
+	It("accepts a well formed list of destinations", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore: &BarmanObjectStoreConfiguration{},
+					AdditionalWALArchives: []WALArchiveDestination{
+						s3Destination("dr"),
+						s3Destination("dr-2"),
+					},
+				},
+			},
+		}
+		Expect(cluster.validateAdditionalWALArchives()).To(BeEmpty())
+	})
+
+	It("complains if the main object store is not defined", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					AdditionalWALArchives: []WALArchiveDestination{s3Destination("dr")},
+				},
+			},
+		}
+		Expect(cluster.validateAdditionalWALArchives()).To(HaveLen(1))
+	})
+
+	It("complains about duplicated names", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore: &BarmanObjectStoreConfiguration{},
+					AdditionalWALArchives: []WALArchiveDestination{
+						s3Destination("dr"),
+						s3Destination("dr"),
+					},
+				},
+			},
+		}
+		Expect(cluster.validateAdditionalWALArchives()).To(HaveLen(1))
+	})
+
+	It("complains if a destination has no credentials", func() {
+		destination := s3Destination("dr")
+		destination.BarmanObjectStore.BarmanCredentials.AWS = nil
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore:     &BarmanObjectStoreConfiguration{},
+					AdditionalWALArchives: []WALArchiveDestination{destination},
+				},
+			},
+		}
+		Expect(cluster.validateAdditionalWALArchives()).To(HaveLen(1))
+	})
+
+	It("complains if a destination has an endpoint CA", func() {
+		destination := s3Destination("dr")
+		destination.BarmanObjectStore.EndpointCA = &SecretKeySelector{
+			LocalObjectReference: LocalObjectReference{Name: "ca"},
+			Key:                  "ca.crt",
+		}
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Backup: &BackupConfiguration{
+					BarmanObjectStore:     &BarmanObjectStoreConfiguration{},
+					AdditionalWALArchives: []WALArchiveDestination{destination},
+				},
+			},
+		}
+		Expect(cluster.validateAdditionalWALArchives()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Default monitoring queries", func() {
+	It("correctly set the default monitoring queries configmap and secret when none is already specified", func() {
+		cluster := &Cluster{}
+		cluster.defaultMonitoringQueries(&configuration.Data{
+			MonitoringQueriesSecret:    "test-secret",
+			MonitoringQueriesConfigmap: "test-configmap",
+		})
+		Expect(cluster.Spec.Monitoring).NotTo(BeNil())
+		Expect(cluster.Spec.Monitoring.CustomQueriesConfigMap).NotTo(BeEmpty())
+		Expect(cluster.Spec.Monitoring.CustomQueriesConfigMap).
+			To(ContainElement(ConfigMapKeySelector{
+				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringConfigMapName},
+				Key:                  DefaultMonitoringKey,
+			}))
+		Expect(cluster.Spec.Monitoring.CustomQueriesSecret).NotTo(BeEmpty())
+		Expect(cluster.Spec.Monitoring.CustomQueriesSecret).
+			To(ContainElement(SecretKeySelector{
+				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringSecretName},
+				Key:                  DefaultMonitoringKey,
+			}))
+	})
+	testCluster := &Cluster{Spec: ClusterSpec{Monitoring: &MonitoringConfiguration{
+		CustomQueriesConfigMap: []ConfigMapKeySelector{
+			{
+				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringConfigMapName},
+				Key:                  "test2",
+			},
+		},
+		CustomQueriesSecret: []SecretKeySelector{
+			{
+				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringConfigMapName},
+				Key:                  "test3",
+			},
+		},
+	}}}
+	It("correctly set the default monitoring queries configmap when other metrics are already specified", func() {
+		modifiedCluster := testCluster.DeepCopy()
+		modifiedCluster.defaultMonitoringQueries(&configuration.Data{
+			MonitoringQueriesConfigmap: "test-configmap",
+		})
+
+		Expect(modifiedCluster.Spec.Monitoring).NotTo(BeNil())
+		Expect(modifiedCluster.Spec.Monitoring.CustomQueriesConfigMap).NotTo(BeEmpty())
+		Expect(modifiedCluster.Spec.Monitoring.CustomQueriesSecret).NotTo(BeEmpty())
+		Expect(modifiedCluster.Spec.Monitoring.CustomQueriesConfigMap).
+			To(ContainElement(ConfigMapKeySelector{
+				LocalObjectReference: LocalObjectReference{Name: DefaultMonitoringConfigMapName},
+				Key:                  "test2",
 			}))
 
 		Expect(modifiedCluster.Spec.Monitoring.CustomQueriesSecret).
@@ -2132,6 +2740,27 @@ var _ = Describe("validation of imports", func() {
 		Expect(result).To(HaveLen(1))
 	})
 
+	It("rejects microservice import with a role passwords policy", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Bootstrap: &BootstrapConfiguration{
+					InitDB: &BootstrapInitDB{
+						Database: "app",
+						Owner:    "app",
+						Import: &Import{
+							Type:          MicroserviceSnapshotType,
+							Databases:     []string{"foo"},
+							RolePasswords: ImportRolePasswordsReset,
+						},
+					},
+				},
+			},
+		}
+
+		result := cluster.validateImport()
+		Expect(result).To(HaveLen(1))
+	})
+
 	It("accepts microservice import when well specified", func() {
 		cluster := &Cluster{
 			Spec: ClusterSpec{
@@ -2387,3 +3016,1053 @@ var _ = Describe("validation of replication slots configuration", func() {
 		Expect(newCluster.validateReplicationSlotsChange(oldCluster)).To(BeEmpty())
 	})
 })
+
+var _ = Describe("validation of the log format", func() {
+	It("accepts the default log format on every PostgreSQL version", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:11.18",
+			},
+		}
+		Expect(cluster.validateLogFormat()).To(BeEmpty())
+		Expect(cluster.GetLogFormat()).To(Equal(LogFormatCSV))
+	})
+
+	It("prevents using jsonlog before PostgreSQL 15", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:14.6",
+				PostgresConfiguration: PostgresConfiguration{
+					LogFormat: LogFormatJSON,
+				},
+			},
+		}
+		Expect(cluster.validateLogFormat()).To(HaveLen(1))
+	})
+
+	It("allows using jsonlog with PostgreSQL 15", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:15.1",
+				PostgresConfiguration: PostgresConfiguration{
+					LogFormat: LogFormatJSON,
+				},
+			},
+		}
+		Expect(cluster.validateLogFormat()).To(BeEmpty())
+	})
+
+	It("accepts a defaulted cluster using jsonlog", func() {
+		cluster := &Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "cluster-example",
+			},
+			Spec: ClusterSpec{
+				Instances: 3,
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:15.1",
+				PostgresConfiguration: PostgresConfiguration{
+					LogFormat: LogFormatJSON,
+				},
+			},
+		}
+		cluster.Default()
+		Expect(cluster.Spec.PostgresConfiguration.Parameters).To(HaveKeyWithValue("log_destination", "jsonlog"))
+		Expect(cluster.Validate()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("defaulting of the PostgreSQL configuration fields", func() {
+	newCluster := func(configuration PostgresConfiguration) *Cluster {
+		return &Cluster{
+			Spec: ClusterSpec{
+				ImageName:             "ghcr.io/cloudnative-pg/postgresql:15.1",
+				PostgresConfiguration: configuration,
+			},
+		}
+	}
+
+	It("doesn't copy the TCP keepalives settings into the parameters", func() {
+		idle := int32(60)
+		cluster := newCluster(PostgresConfiguration{
+			TCPKeepalives: &TCPKeepalivesConfiguration{Idle: &idle},
+		})
+		cluster.Default()
+		Expect(cluster.Spec.PostgresConfiguration.Parameters).ToNot(HaveKey("tcp_keepalives_idle"))
+	})
+
+	It("doesn't copy the WAL retention into the parameters", func() {
+		cluster := newCluster(PostgresConfiguration{WALKeepSize: "2Gi"})
+		cluster.Default()
+		Expect(cluster.Spec.PostgresConfiguration.Parameters).To(HaveKeyWithValue("wal_keep_size", "512MB"))
+	})
+
+	It("doesn't copy the memory parameters into the parameters", func() {
+		sharedBuffers := int32(25)
+		cluster := newCluster(PostgresConfiguration{SharedBuffersPercentage: &sharedBuffers})
+		cluster.Spec.Resources.Limits = v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")}
+		cluster.Default()
+		Expect(cluster.Spec.PostgresConfiguration.Parameters).ToNot(HaveKey("shared_buffers"))
+	})
+
+	It("doesn't copy the timeout of the sessions idle in transaction into the parameters", func() {
+		timeout := int32(300)
+		cluster := newCluster(PostgresConfiguration{IdleInTransactionSessionTimeout: &timeout})
+		cluster.Default()
+		Expect(cluster.Spec.PostgresConfiguration.Parameters).ToNot(HaveKey("idle_in_transaction_session_timeout"))
+	})
+
+	It("doesn't copy the autovacuum settings into the parameters", func() {
+		maxWorkers := int32(5)
+		cluster := newCluster(PostgresConfiguration{
+			Autovacuum: &AutovacuumConfiguration{MaxWorkers: &maxWorkers},
+		})
+		cluster.Default()
+		Expect(cluster.Spec.PostgresConfiguration.Parameters).ToNot(HaveKey("autovacuum_max_workers"))
+	})
+})
+
+var _ = Describe("validation of the preferred primary", func() {
+	It("accepts a cluster without preferences", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validatePreferredPrimary()).To(BeEmpty())
+	})
+
+	It("accepts an instance serial", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PreferredPrimary: &PreferredPrimaryConfiguration{InstanceSerial: 2},
+			},
+		}
+		Expect(cluster.validatePreferredPrimary()).To(BeEmpty())
+	})
+
+	It("accepts a zone", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PreferredPrimary: &PreferredPrimaryConfiguration{Zone: "eu-west-1a"},
+			},
+		}
+		Expect(cluster.validatePreferredPrimary()).To(BeEmpty())
+	})
+
+	It("rejects an empty preference", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PreferredPrimary: &PreferredPrimaryConfiguration{},
+			},
+		}
+		Expect(cluster.validatePreferredPrimary()).To(HaveLen(1))
+	})
+
+	It("rejects both an instance serial and a zone", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PreferredPrimary: &PreferredPrimaryConfiguration{InstanceSerial: 1, Zone: "eu-west-1a"},
+			},
+		}
+		Expect(cluster.validatePreferredPrimary()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("validation of wal_compression", func() {
+	walCompressionPath := field.NewPath("spec", "postgresql", "walCompression")
+
+	It("accepts boolean values on every version", func() {
+		Expect(validateWalCompression(walCompressionPath, "on", 130000)).To(BeNil())
+		Expect(validateWalCompression(walCompressionPath, "off", 150000)).To(BeNil())
+	})
+
+	It("accepts the compression methods from PostgreSQL 15", func() {
+		Expect(validateWalCompression(walCompressionPath, "zstd", 150000)).To(BeNil())
+		Expect(validateWalCompression(walCompressionPath, "lz4", 150000)).To(BeNil())
+		Expect(validateWalCompression(walCompressionPath, "pglz", 150000)).To(BeNil())
+	})
+
+	It("rejects the compression methods before PostgreSQL 15", func() {
+		Expect(validateWalCompression(walCompressionPath, "zstd", 140000)).ToNot(BeNil())
+	})
+
+	It("rejects unknown values", func() {
+		Expect(validateWalCompression(walCompressionPath, "gzip", 150000)).ToNot(BeNil())
+	})
+
+	It("is called while validating the configuration", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:14.6",
+				PostgresConfiguration: PostgresConfiguration{
+					Parameters: map[string]string{
+						"wal_compression": "lz4",
+					},
+				},
+			},
+		}
+		Expect(cluster.validateConfiguration()).To(HaveLen(1))
+	})
+
+	It("is called for the walCompression option", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:14.6",
+				PostgresConfiguration: PostgresConfiguration{
+					WALCompression: WALCompressionZSTD,
+				},
+			},
+		}
+		Expect(cluster.validateConfiguration()).To(HaveLen(1))
+
+		cluster.Spec.ImageName = "ghcr.io/cloudnative-pg/postgresql:15.1"
+		Expect(cluster.validateConfiguration()).To(BeEmpty())
+	})
+
+	It("is not called when the validation of wal_compression is skipped", func() {
+		cluster := &Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"cnpg.io/skipParametersValidation": "wal_compression",
+				},
+			},
+			Spec: ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:14.6",
+				PostgresConfiguration: PostgresConfiguration{
+					Parameters: map[string]string{
+						"wal_compression": "lz4",
+					},
+				},
+			},
+		}
+		Expect(cluster.validateConfiguration()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("skipping the validation of the PostgreSQL parameters", func() {
+	It("rejects fixed parameters by default", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:14.6",
+				PostgresConfiguration: PostgresConfiguration{
+					Parameters: map[string]string{
+						"recovery_target_name": "test",
+					},
+				},
+			},
+		}
+		Expect(cluster.validateConfiguration()).To(HaveLen(1))
+	})
+
+	It("accepts fixed parameters set to the value enforced by the operator", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:14.6",
+				PostgresConfiguration: PostgresConfiguration{
+					Parameters: map[string]string{
+						"wal_level": "logical",
+					},
+				},
+			},
+		}
+		Expect(cluster.validateConfiguration()).To(BeEmpty())
+
+		cluster.Spec.PostgresConfiguration.Parameters["wal_level"] = "replica"
+		Expect(cluster.validateConfiguration()).To(HaveLen(1))
+	})
+
+	It("accepts fixed parameters listed in the annotation", func() {
+		cluster := &Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"cnpg.io/skipParametersValidation": "recovery_target_name, wal_compression",
+				},
+			},
+			Spec: ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:14.6",
+				PostgresConfiguration: PostgresConfiguration{
+					Parameters: map[string]string{
+						"recovery_target_name": "test",
+					},
+				},
+			},
+		}
+		Expect(cluster.validateConfiguration()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("validation of the WAL retention", func() {
+	It("accepts a cluster without WAL retention", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validateWALKeepSize()).To(BeEmpty())
+	})
+
+	It("accepts a WAL retention fitting in the volume", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				StorageConfiguration: StorageConfiguration{Size: "10Gi"},
+				PostgresConfiguration: PostgresConfiguration{
+					WALKeepSize: "2Gi",
+				},
+			},
+		}
+		Expect(cluster.validateWALKeepSize()).To(BeEmpty())
+	})
+
+	It("rejects invalid quantities", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					WALKeepSize: "lots",
+				},
+			},
+		}
+		Expect(cluster.validateWALKeepSize()).To(HaveLen(1))
+
+		cluster.Spec.PostgresConfiguration.WALKeepSize = "0"
+		Expect(cluster.validateWALKeepSize()).To(HaveLen(1))
+	})
+
+	It("rejects a WAL retention not fitting in the WAL volume", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				StorageConfiguration: StorageConfiguration{Size: "10Gi"},
+				WalStorage:           &StorageConfiguration{Size: "1Gi"},
+				PostgresConfiguration: PostgresConfiguration{
+					WALKeepSize: "2Gi",
+				},
+			},
+		}
+		Expect(cluster.validateWALKeepSize()).To(HaveLen(1))
+	})
+
+	It("doesn't check the WAL retention when the volume size is unknown", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					WALKeepSize: "2Gi",
+				},
+			},
+		}
+		Expect(cluster.validateWALKeepSize()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("validation of the memory parameters expressed as a percentage", func() {
+	memoryLimit := v1.ResourceRequirements{
+		Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")},
+	}
+
+	It("accepts a cluster without memory percentages", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validateMemoryPercentageParameters()).To(BeEmpty())
+	})
+
+	It("accepts percentages within bounds when the memory is known", func() {
+		sharedBuffers := int32(25)
+		effectiveCacheSize := int32(75)
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Resources: memoryLimit,
+				PostgresConfiguration: PostgresConfiguration{
+					SharedBuffersPercentage:      &sharedBuffers,
+					EffectiveCacheSizePercentage: &effectiveCacheSize,
+				},
+			},
+		}
+		Expect(cluster.validateMemoryPercentageParameters()).To(BeEmpty())
+	})
+
+	It("rejects percentages out of bounds", func() {
+		sharedBuffers := int32(90)
+		effectiveCacheSize := int32(0)
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				Resources: memoryLimit,
+				PostgresConfiguration: PostgresConfiguration{
+					SharedBuffersPercentage:      &sharedBuffers,
+					EffectiveCacheSizePercentage: &effectiveCacheSize,
+				},
+			},
+		}
+		Expect(cluster.validateMemoryPercentageParameters()).To(HaveLen(2))
+	})
+
+	It("requires the memory of the container to be set", func() {
+		sharedBuffers := int32(25)
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					SharedBuffersPercentage: &sharedBuffers,
+				},
+			},
+		}
+		Expect(cluster.validateMemoryPercentageParameters()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("validation of the idle in transaction session timeout", func() {
+	withTimeout := func(timeout int32) *Cluster {
+		return &Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					IdleInTransactionSessionTimeout: &timeout,
+				},
+			},
+		}
+	}
+
+	It("accepts a cluster without the timeout", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validateIdleInTransactionSessionTimeout()).To(BeEmpty())
+	})
+
+	It("accepts a disabled timeout", func() {
+		Expect(withTimeout(0).validateIdleInTransactionSessionTimeout()).To(BeEmpty())
+	})
+
+	It("accepts a long enough timeout", func() {
+		Expect(withTimeout(MinIdleInTransactionSessionTimeout).validateIdleInTransactionSessionTimeout()).To(BeEmpty())
+		Expect(withTimeout(600).validateIdleInTransactionSessionTimeout()).To(BeEmpty())
+	})
+
+	It("rejects a too short timeout", func() {
+		Expect(withTimeout(5).validateIdleInTransactionSessionTimeout()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("validation of the pre-promotion hook", func() {
+	It("accepts a cluster without the hook", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validatePrePromotionHook()).To(BeEmpty())
+	})
+
+	It("accepts a hook with statements", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					PrePromotionHook: &PrePromotionHookConfiguration{
+						SQL: []string{"SELECT pg_last_wal_replay_lsn()"},
+					},
+				},
+			},
+		}
+		Expect(cluster.validatePrePromotionHook()).To(BeEmpty())
+	})
+
+	It("rejects a hook without statements", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					PrePromotionHook: &PrePromotionHookConfiguration{},
+				},
+			},
+		}
+		Expect(cluster.validatePrePromotionHook()).To(HaveLen(1))
+	})
+
+	It("rejects empty statements", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					PrePromotionHook: &PrePromotionHookConfiguration{
+						SQL: []string{"SELECT 1", "  "},
+					},
+				},
+			},
+		}
+		Expect(cluster.validatePrePromotionHook()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("WAL segment size change validation", func() {
+	withWalSegmentSize := func(size int) *Cluster {
+		return &Cluster{
+			Spec: ClusterSpec{
+				Bootstrap: &BootstrapConfiguration{
+					InitDB: &BootstrapInitDB{WalSegmentSize: size},
+				},
+			},
+		}
+	}
+
+	It("allows keeping the same WAL segment size", func() {
+		Expect(withWalSegmentSize(64).validateWalSegmentSizeChange(withWalSegmentSize(64))).To(BeEmpty())
+		Expect(withWalSegmentSize(0).validateWalSegmentSizeChange(&Cluster{})).To(BeEmpty())
+	})
+
+	It("rejects changing the WAL segment size", func() {
+		Expect(withWalSegmentSize(64).validateWalSegmentSizeChange(withWalSegmentSize(32))).To(HaveLen(1))
+		Expect(withWalSegmentSize(64).validateWalSegmentSizeChange(withWalSegmentSize(0))).To(HaveLen(1))
+		Expect((&Cluster{}).validateWalSegmentSizeChange(withWalSegmentSize(64))).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("data checksums change validation", func() {
+	withDataChecksums := func(dataChecksums *bool) *Cluster {
+		return &Cluster{
+			Spec: ClusterSpec{
+				Bootstrap: &BootstrapConfiguration{
+					InitDB: &BootstrapInitDB{DataChecksums: dataChecksums},
+				},
+			},
+		}
+	}
+
+	It("allows keeping the same data checksums setting", func() {
+		Expect(withDataChecksums(pointer.Bool(true)).validateDataChecksumsChange(
+			withDataChecksums(pointer.Bool(true)))).To(BeEmpty())
+		Expect(withDataChecksums(pointer.Bool(false)).validateDataChecksumsChange(&Cluster{})).To(BeEmpty())
+	})
+
+	It("rejects changing the data checksums setting", func() {
+		Expect(withDataChecksums(pointer.Bool(true)).validateDataChecksumsChange(
+			withDataChecksums(pointer.Bool(false)))).To(HaveLen(1))
+		Expect(withDataChecksums(nil).validateDataChecksumsChange(
+			withDataChecksums(pointer.Bool(true)))).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("initdb locale validation", func() {
+	withInitDB := func(initDB *BootstrapInitDB) *Cluster {
+		return &Cluster{
+			Spec: ClusterSpec{
+				Bootstrap: &BootstrapConfiguration{InitDB: initDB},
+			},
+		}
+	}
+
+	It("accepts well formed encoding and locale names", func() {
+		cluster := withInitDB(&BootstrapInitDB{
+			Encoding:      "UTF8",
+			Locale:        "de_DE@euro",
+			LocaleCollate: "en_US.UTF-8",
+			LocaleCType:   "C",
+		})
+		Expect(cluster.validateInitDB()).To(BeEmpty())
+	})
+
+	It("rejects malformed encoding and locale names", func() {
+		cluster := withInitDB(&BootstrapInitDB{
+			Encoding:      "UTF 8",
+			Locale:        "en_US.UTF-8 --no-sync",
+			LocaleCollate: ".UTF-8",
+			LocaleCType:   "en_US@",
+		})
+		Expect(cluster.validateInitDB()).To(HaveLen(4))
+	})
+
+	It("allows keeping the same encoding and locale", func() {
+		initDB := &BootstrapInitDB{Encoding: "LATIN1", LocaleCollate: "en_US", LocaleCType: "en_US"}
+		Expect(withInitDB(initDB).validateLocaleChange(withInitDB(initDB.DeepCopy()))).To(BeEmpty())
+	})
+
+	It("rejects changing the encoding and the locale", func() {
+		oldCluster := withInitDB(&BootstrapInitDB{Encoding: "LATIN1", LocaleCollate: "en_US"})
+		cluster := withInitDB(&BootstrapInitDB{Encoding: "UTF8", Locale: "it_IT"})
+		result := cluster.validateLocaleChange(oldCluster)
+		Expect(result).To(HaveLen(3))
+		Expect(result[0].Field).To(Equal("spec.bootstrap.initdb.encoding"))
+		Expect(result[1].Field).To(Equal("spec.bootstrap.initdb.locale"))
+		Expect(result[2].Field).To(Equal("spec.bootstrap.initdb.localeCollate"))
+	})
+})
+
+var _ = Describe("inherited metadata validation", func() {
+	It("accepts labels and annotations not managed by the operator", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				InheritedMetadata: &EmbeddedObjectMetadata{
+					Labels:      map[string]string{"team": "dba"},
+					Annotations: map[string]string{"cost-center": "42"},
+				},
+			},
+		}
+		Expect(cluster.validateInheritedMetadata()).To(BeEmpty())
+	})
+
+	It("rejects labels and annotations managed by the operator", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				InheritedMetadata: &EmbeddedObjectMetadata{
+					Labels:      map[string]string{"role": "primary", "cnpg.io/cluster": "another"},
+					Annotations: map[string]string{"cnpg.io/operatorVersion": "1.0.0"},
+				},
+			},
+		}
+		Expect(cluster.validateInheritedMetadata()).To(HaveLen(3))
+	})
+})
+
+var _ = Describe("maintenance window validation", func() {
+	It("accepts a valid maintenance window", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				MaintenanceWindow: &MaintenanceWindow{
+					Days:      []string{"saturday", "sunday"},
+					StartTime: "23:00",
+					EndTime:   "03:00",
+					TimeZone:  "Europe/Rome",
+				},
+			},
+		}
+		Expect(cluster.validateMaintenanceWindow()).To(BeEmpty())
+	})
+
+	It("rejects unknown days, malformed times and time zones", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				MaintenanceWindow: &MaintenanceWindow{
+					Days:      []string{"Sunday", "someday"},
+					StartTime: "25:00",
+					EndTime:   "3am",
+					TimeZone:  "Mars/Olympus",
+				},
+			},
+		}
+		Expect(cluster.validateMaintenanceWindow()).To(HaveLen(5))
+	})
+
+	It("rejects windows starting and ending at the same time", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				MaintenanceWindow: &MaintenanceWindow{
+					StartTime: "02:00",
+					EndTime:   "02:00",
+				},
+			},
+		}
+		Expect(cluster.validateMaintenanceWindow()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("scheduled maintenance validation", func() {
+	It("accepts valid scheduled maintenance operations", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				ScheduledMaintenance: []ScheduledMaintenance{
+					{
+						Name:      "nightly",
+						Schedule:  "0 0 2 * * *",
+						Operation: MaintenanceOperationVacuumAnalyze,
+						Database:  "app",
+					},
+					{
+						Name:      "orders",
+						Schedule:  "0 */15 * * * *",
+						Operation: MaintenanceOperationAnalyze,
+						Database:  "app",
+						Tables:    []string{"public.orders"},
+					},
+				},
+			},
+		}
+		Expect(cluster.validateScheduledMaintenance()).To(BeEmpty())
+	})
+
+	It("rejects duplicated names, invalid schedules and missing databases", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				ScheduledMaintenance: []ScheduledMaintenance{
+					{
+						Name:      "nightly",
+						Schedule:  "0 0 2 * * *",
+						Operation: MaintenanceOperationVacuum,
+						Database:  "app",
+					},
+					{
+						Name:      "nightly",
+						Schedule:  "every night",
+						Operation: "reindex",
+						Tables:    []string{""},
+					},
+				},
+			},
+		}
+		Expect(cluster.validateScheduledMaintenance()).To(HaveLen(5))
+	})
+})
+
+var _ = Describe("shared preload libraries validation", func() {
+	It("accepts valid library names", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					AdditionalLibraries: []string{"timescaledb", "$libdir/pg_cron"},
+				},
+			},
+		}
+		Expect(cluster.validateSharedPreloadLibraries()).To(BeEmpty())
+	})
+
+	It("rejects empty, duplicated and malformed library names", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					AdditionalLibraries: []string{
+						"",
+						"timescaledb",
+						"timescaledb",
+						"pg_cron,pgaudit",
+						"pg_cron' evil='true",
+					},
+				},
+			},
+		}
+		Expect(cluster.validateSharedPreloadLibraries()).To(HaveLen(4))
+	})
+})
+
+var _ = Describe("hibernation annotation validation", func() {
+	It("accepts a cluster without the annotation", func() {
+		cluster := Cluster{}
+		Expect(cluster.validateHibernationAnnotation()).To(BeEmpty())
+	})
+
+	It("accepts the on and off values", func() {
+		for _, value := range []string{"on", "off"} {
+			cluster := Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						utils.HibernationAnnotationName: value,
+					},
+				},
+			}
+			Expect(cluster.validateHibernationAnnotation()).To(BeEmpty())
+		}
+	})
+
+	It("rejects any other value", func() {
+		cluster := Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					utils.HibernationAnnotationName: "true",
+				},
+			},
+		}
+		Expect(cluster.validateHibernationAnnotation()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("WAL storage change validation", func() {
+	walStorageCluster := func(walStorage *StorageConfiguration) *Cluster {
+		return &Cluster{
+			Spec: ClusterSpec{
+				StorageConfiguration: StorageConfiguration{Size: "1Gi"},
+				WalStorage:           walStorage,
+			},
+		}
+	}
+
+	It("allows growing the WAL storage", func() {
+		oldCluster := walStorageCluster(&StorageConfiguration{Size: "1Gi"})
+		newCluster := walStorageCluster(&StorageConfiguration{Size: "2Gi"})
+		Expect(newCluster.validateWalStorageChange(oldCluster)).To(BeEmpty())
+	})
+
+	It("rejects shrinking the WAL storage", func() {
+		oldCluster := walStorageCluster(&StorageConfiguration{Size: "2Gi"})
+		newCluster := walStorageCluster(&StorageConfiguration{Size: "1Gi"})
+		Expect(newCluster.validateWalStorageChange(oldCluster)).To(HaveLen(1))
+	})
+
+	It("rejects adding the WAL storage to an existing cluster", func() {
+		oldCluster := walStorageCluster(nil)
+		newCluster := walStorageCluster(&StorageConfiguration{Size: "1Gi"})
+		Expect(newCluster.validateWalStorageChange(oldCluster)).To(HaveLen(1))
+	})
+
+	It("rejects removing the WAL storage", func() {
+		oldCluster := walStorageCluster(&StorageConfiguration{Size: "1Gi"})
+		newCluster := walStorageCluster(nil)
+		Expect(newCluster.validateWalStorageChange(oldCluster)).To(HaveLen(1))
+	})
+
+	It("allows removing the WAL storage when the check is skipped", func() {
+		oldCluster := walStorageCluster(&StorageConfiguration{Size: "1Gi"})
+		newCluster := walStorageCluster(nil)
+		newCluster.Annotations = map[string]string{
+			"cnpg.io/skipWalStorageCheck": "enabled",
+		}
+		Expect(newCluster.validateWalStorageChange(oldCluster)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("PVC reclaim policy validation", func() {
+	It("accepts an empty policy", func() {
+		cluster := Cluster{}
+		Expect(cluster.validatePVCReclaimPolicy()).To(BeEmpty())
+	})
+
+	It("accepts the delete and retain policies", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				StorageConfiguration: StorageConfiguration{PVCReclaimPolicy: PVCReclaimPolicyRetain},
+				WalStorage:           &StorageConfiguration{PVCReclaimPolicy: PVCReclaimPolicyDelete},
+			},
+		}
+		Expect(cluster.validatePVCReclaimPolicy()).To(BeEmpty())
+	})
+
+	It("rejects unknown policies", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				StorageConfiguration: StorageConfiguration{PVCReclaimPolicy: "recycle"},
+				WalStorage:           &StorageConfiguration{PVCReclaimPolicy: "recycle"},
+			},
+		}
+		Expect(cluster.validatePVCReclaimPolicy()).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("replication slots database validation", func() {
+	It("accepts the default and custom databases", func() {
+		cluster := Cluster{}
+		Expect(cluster.validateReplicationSlotsDatabase()).To(BeEmpty())
+
+		cluster.Spec.ReplicationSlots = &ReplicationSlotsConfiguration{Database: "maintenance"}
+		Expect(cluster.validateReplicationSlotsDatabase()).To(BeEmpty())
+	})
+
+	It("rejects the template0 database", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				ReplicationSlots: &ReplicationSlotsConfiguration{Database: "template0"},
+			},
+		}
+		Expect(cluster.validateReplicationSlotsDatabase()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("replication slots update interval validation", func() {
+	withInterval := func(updateInterval int) *Cluster {
+		return &Cluster{
+			Spec: ClusterSpec{
+				ReplicationSlots: &ReplicationSlotsConfiguration{UpdateInterval: updateInterval},
+			},
+		}
+	}
+
+	It("accepts the default interval and the ones not shorter than the minimum", func() {
+		Expect((&Cluster{}).validateReplicationSlotsUpdateInterval(nil)).To(BeEmpty())
+		Expect(withInterval(0).validateReplicationSlotsUpdateInterval(nil)).To(BeEmpty())
+		Expect(withInterval(10).validateReplicationSlotsUpdateInterval(nil)).To(BeEmpty())
+		Expect(withInterval(300).validateReplicationSlotsUpdateInterval(nil)).To(BeEmpty())
+	})
+
+	It("rejects new clusters with an interval shorter than the minimum", func() {
+		Expect(withInterval(1).validateReplicationSlotsUpdateInterval(nil)).To(HaveLen(1))
+	})
+
+	It("rejects changing the interval to a value shorter than the minimum", func() {
+		Expect(withInterval(5).validateReplicationSlotsUpdateInterval(withInterval(30))).To(HaveLen(1))
+		Expect(withInterval(5).validateReplicationSlotsUpdateInterval(&Cluster{})).To(HaveLen(1))
+	})
+
+	It("accepts existing clusters whose interval doesn't change", func() {
+		Expect(withInterval(5).validateReplicationSlotsUpdateInterval(withInterval(5))).To(BeEmpty())
+	})
+})
+
+var _ = Describe("validation of the shutdown settings", func() {
+	It("accepts a cluster using the default shutdown settings", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validateShutdownSettings()).To(BeEmpty())
+	})
+
+	It("accepts the supported shutdown modes", func() {
+		for _, mode := range []ShutdownMode{ShutdownModeSmart, ShutdownModeFast, ShutdownModeImmediate} {
+			cluster := &Cluster{
+				Spec: ClusterSpec{
+					ShutdownMode:    mode,
+					ShutdownTimeout: 60,
+				},
+			}
+			Expect(cluster.validateShutdownSettings()).To(BeEmpty())
+		}
+	})
+
+	It("rejects unknown shutdown modes", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ShutdownMode: "graceful",
+			},
+		}
+		Expect(cluster.validateShutdownSettings()).To(HaveLen(1))
+	})
+
+	It("rejects negative shutdown timeouts", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ShutdownTimeout: -1,
+			},
+		}
+		Expect(cluster.validateShutdownSettings()).To(HaveLen(1))
+	})
+
+	It("accepts a termination grace period not shorter than the shutdown timeout", func() {
+		gracePeriod := int64(120)
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ShutdownTimeout:               120,
+				TerminationGracePeriodSeconds: &gracePeriod,
+			},
+		}
+		Expect(cluster.validateShutdownSettings()).To(BeEmpty())
+	})
+
+	It("rejects a termination grace period shorter than the shutdown timeout", func() {
+		gracePeriod := int64(20)
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				TerminationGracePeriodSeconds: &gracePeriod,
+			},
+		}
+		Expect(cluster.validateShutdownSettings()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("validation of the read-only service", func() {
+	It("accepts a cluster without read-only service configuration", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validateReadOnlyService()).To(BeEmpty())
+	})
+
+	It("accepts a valid maximum lag", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ReadOnlyService: &ReadOnlyServiceConfiguration{MaxLag: "16Mi"},
+			},
+		}
+		Expect(cluster.validateReadOnlyService()).To(BeEmpty())
+	})
+
+	It("rejects an invalid maximum lag", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ReadOnlyService: &ReadOnlyServiceConfiguration{MaxLag: "a lot"},
+			},
+		}
+		Expect(cluster.validateReadOnlyService()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("validation of the services configuration", func() {
+	It("accepts a cluster without services configuration", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validateServices()).To(BeEmpty())
+	})
+
+	It("accepts a different configuration for each service", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ReadWriteService: &ServiceConfiguration{
+					Type: v1.ServiceTypeLoadBalancer,
+					Annotations: map[string]string{
+						"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+					},
+					Labels: map[string]string{"exposed": "true"},
+				},
+				ReadOnlyService: &ReadOnlyServiceConfiguration{
+					ServiceConfiguration: ServiceConfiguration{Type: v1.ServiceTypeNodePort},
+				},
+				ReadService: &ServiceConfiguration{Headless: true},
+			},
+		}
+		Expect(cluster.validateServices()).To(BeEmpty())
+	})
+
+	It("rejects an unsupported service type", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ReadService: &ServiceConfiguration{Type: v1.ServiceTypeExternalName},
+			},
+		}
+		Expect(cluster.validateServices()).To(HaveLen(1))
+	})
+
+	It("rejects a headless service which is not of type ClusterIP", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ReadWriteService: &ServiceConfiguration{
+					Type:     v1.ServiceTypeLoadBalancer,
+					Headless: true,
+				},
+			},
+		}
+		Expect(cluster.validateServices()).To(HaveLen(1))
+	})
+
+	It("rejects invalid labels and annotations", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ReadWriteService: &ServiceConfiguration{
+					Annotations: map[string]string{"not a valid key": "value"},
+					Labels:      map[string]string{"key": "not a valid value"},
+				},
+			},
+		}
+		Expect(cluster.validateServices()).To(HaveLen(2))
+	})
+
+	It("rejects turning a service into a headless one", func() {
+		oldCluster := &Cluster{}
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				ReadService: &ServiceConfiguration{Headless: true},
+			},
+		}
+		Expect(cluster.validateServicesChange(oldCluster)).To(HaveLen(1))
+		Expect(cluster.validateServicesChange(cluster)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("validation of the autovacuum settings", func() {
+	It("accepts a cluster without autovacuum settings", func() {
+		cluster := &Cluster{}
+		Expect(cluster.validateAutovacuum()).To(BeEmpty())
+	})
+
+	It("accepts scale factors between 0 and 100", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					Autovacuum: &AutovacuumConfiguration{
+						VacuumScaleFactor:  "0.05",
+						AnalyzeScaleFactor: "100",
+					},
+				},
+			},
+		}
+		Expect(cluster.validateAutovacuum()).To(BeEmpty())
+	})
+
+	It("rejects scale factors out of range", func() {
+		cluster := &Cluster{
+			Spec: ClusterSpec{
+				PostgresConfiguration: PostgresConfiguration{
+					Autovacuum: &AutovacuumConfiguration{
+						VacuumScaleFactor:  "100.5",
+						AnalyzeScaleFactor: "zero",
+					},
+				},
+			},
+		}
+		Expect(cluster.validateAutovacuum()).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("probes validation", func() {
+	It("accepts the default and custom databases", func() {
+		cluster := Cluster{}
+		Expect(cluster.validateProbes()).To(BeEmpty())
+
+		cluster.Spec.Probes = &ProbesConfiguration{Database: "app", Query: "SELECT 1"}
+		Expect(cluster.validateProbes()).To(BeEmpty())
+	})
+
+	It("rejects the template0 database", func() {
+		cluster := Cluster{
+			Spec: ClusterSpec{
+				Probes: &ProbesConfiguration{Database: "template0"},
+			},
+		}
+		Expect(cluster.validateProbes()).To(HaveLen(1))
+	})
+})
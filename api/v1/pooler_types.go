@@ -17,8 +17,12 @@ limitations under the License.
 package v1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
 // PoolerType is the type of the connection pool, meaning the service
@@ -35,6 +39,10 @@ const (
 
 	// DefaultPgBouncerPoolerAuthQuery is the default auth_query for PgBouncer
 	DefaultPgBouncerPoolerAuthQuery = "SELECT usename, passwd FROM user_search($1)"
+
+	// DefaultSwitchoverPauseTimeout is the default number of seconds
+	// PgBouncer stays paused for a switchover of the cluster
+	DefaultSwitchoverPauseTimeout = 30
 )
 
 // PgBouncerPoolMode is the mode of PgBouncer
@@ -49,6 +57,22 @@ const (
 	PgBouncerPoolModeTransaction = PgBouncerPoolMode("transaction")
 )
 
+// PgBouncerServerTLSMode is the TLS mode used by PgBouncer
+// to connect to PostgreSQL
+// +kubebuilder:validation:Enum=verify-ca;verify-full
+type PgBouncerServerTLSMode string
+
+const (
+	// PgBouncerServerTLSModeVerifyCA verifies that the certificate of
+	// PostgreSQL is signed by the server CA of the cluster
+	PgBouncerServerTLSModeVerifyCA = PgBouncerServerTLSMode("verify-ca")
+
+	// PgBouncerServerTLSModeVerifyFull verifies that the certificate of
+	// PostgreSQL is signed by the server CA of the cluster, and that it
+	// matches the name of the service PgBouncer connects to
+	PgBouncerServerTLSModeVerifyFull = PgBouncerServerTLSMode("verify-full")
+)
+
 // PoolerSpec defines the desired state of Pooler
 type PoolerSpec struct {
 	// This is the cluster reference on which the Pooler will work.
@@ -131,6 +155,14 @@ type PgBouncerSpec struct {
 	// no automatic CNPG Cluster integration will be triggered.
 	AuthQuery string `json:"authQuery,omitempty"`
 
+	// The TLS mode used by PgBouncer to connect to PostgreSQL, using the
+	// server CA of the cluster: `verify-ca` (default) checks that the
+	// certificate of PostgreSQL is signed by the CA, while `verify-full` also
+	// checks that it matches the name of the service PgBouncer connects to
+	// +kubebuilder:default:=verify-ca
+	// +optional
+	ServerTLSMode PgBouncerServerTLSMode `json:"serverTLSMode,omitempty"`
+
 	// Additional parameters to be passed to PgBouncer - please check
 	// the CNPG documentation for a list of options you can configure
 	Parameters map[string]string `json:"parameters,omitempty"`
@@ -141,6 +173,47 @@ type PgBouncerSpec struct {
 	// the operator calls PgBouncer's `PAUSE` and `RESUME` commands.
 	// +kubebuilder:default:=false
 	Paused *bool `json:"paused,omitempty"`
+
+	// The pause of PgBouncer during the planned switchovers of the cluster
+	// +optional
+	SwitchoverPause *SwitchoverPauseConfiguration `json:"switchoverPause,omitempty"`
+}
+
+// SwitchoverPauseConfiguration defines whether PgBouncer is paused
+// during the planned switchovers of the cluster
+type SwitchoverPauseConfiguration struct {
+	// When `true`, the operator pauses PgBouncer before a planned switchover,
+	// letting the running transactions complete and queueing the new client
+	// connections, and resumes it when the new primary is promoted
+	// (default: `false`)
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// The maximum number of seconds PgBouncer stays paused, including the
+	// time needed to complete the running transactions. The switchover
+	// starts when every PgBouncer instance is paused or when this timeout
+	// expires, and PgBouncer is resumed anyway after it (default: `30`)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Timeout int32 `json:"timeout,omitempty"`
+}
+
+// GetTimeout returns the maximum time PgBouncer stays paused
+// during a switchover
+func (in *SwitchoverPauseConfiguration) GetTimeout() time.Duration {
+	if in == nil || in.Timeout == 0 {
+		return DefaultSwitchoverPauseTimeout * time.Second
+	}
+	return time.Duration(in.Timeout) * time.Second
+}
+
+// GetServerTLSMode gets the TLS mode used by PgBouncer to connect
+// to PostgreSQL, defaulting to `verify-ca`
+func (in PgBouncerSpec) GetServerTLSMode() PgBouncerServerTLSMode {
+	if in.ServerTLSMode == "" {
+		return PgBouncerServerTLSModeVerifyCA
+	}
+	return in.ServerTLSMode
 }
 
 // IsPaused returns whether all database should be paused or not
@@ -154,6 +227,30 @@ type PoolerStatus struct {
 	Secrets *PoolerSecrets `json:"secrets,omitempty"`
 	// The number of pods trying to be scheduled
 	Instances int32 `json:"instances,omitempty"`
+	// The status of the auth_query used by PgBouncer to fetch
+	// the credentials of the users from PostgreSQL
+	AuthQuery *PoolerAuthQueryStatus `json:"authQuery,omitempty"`
+	// The PgBouncer instances paused for a switchover of the cluster,
+	// with the time when the pause they acknowledged has been requested
+	// +optional
+	SwitchoverPausedInstances map[string]string `json:"switchoverPausedInstances,omitempty"`
+}
+
+// PoolerAuthQueryStatus reports whether the auth_query used by PgBouncer
+// is ready to authenticate the users
+type PoolerAuthQueryStatus struct {
+	// The auth_query used by PgBouncer
+	Query string `json:"query,omitempty"`
+
+	// True when the user and the function used by the auth_query
+	// are created by the operator
+	Managed bool `json:"managed,omitempty"`
+
+	// True when everything needed by the auth_query is in place
+	Ready bool `json:"ready"`
+
+	// The reason why the auth_query is not ready yet
+	Message string `json:"message,omitempty"`
 }
 
 // PoolerSecrets contains the versions of all the secrets used
@@ -226,6 +323,14 @@ func (in *Pooler) GetAuthQuerySecretName() string {
 	return in.Spec.Cluster.Name + DefaultPgBouncerPoolerSecretSuffix
 }
 
+// IsAuthQueryManaged returns true when the user and the function used by the
+// auth_query are created by the operator, that is when neither the auth
+// query nor the auth query secret have been specified
+func (in *Pooler) IsAuthQueryManaged() bool {
+	return in.Spec.PgBouncer.AuthQuery == "" &&
+		(in.Spec.PgBouncer.AuthQuerySecret == nil || in.Spec.PgBouncer.AuthQuerySecret.Name == "")
+}
+
 // GetAuthQuery returns the specified AuthQuery name for PgBouncer
 // if provided or the default name otherwise.
 func (in *Pooler) GetAuthQuery() string {
@@ -235,3 +340,49 @@ func (in *Pooler) GetAuthQuery() string {
 
 	return DefaultPgBouncerPoolerAuthQuery
 }
+
+// IsSwitchoverPauseEnabled checks whether PgBouncer should be paused
+// during the planned switchovers of the cluster
+func (in *Pooler) IsSwitchoverPauseEnabled() bool {
+	return in.Spec.PgBouncer != nil && in.Spec.PgBouncer.SwitchoverPause != nil &&
+		in.Spec.PgBouncer.SwitchoverPause.Enabled
+}
+
+// GetSwitchoverPauseRequest returns the time when the pause of PgBouncer
+// has been requested for a switchover of the cluster, and the time when
+// it expires. The last value is false when no pause has been requested
+func (in *Pooler) GetSwitchoverPauseRequest() (requestedAt time.Time, deadline time.Time, ok bool) {
+	value, found := in.Annotations[utils.SwitchoverPauseAnnotationName]
+	if !found {
+		return time.Time{}, time.Time{}, false
+	}
+
+	requestedAt, err := time.Parse(metav1.RFC3339Micro, value)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	var configuration *SwitchoverPauseConfiguration
+	if in.Spec.PgBouncer != nil {
+		configuration = in.Spec.PgBouncer.SwitchoverPause
+	}
+	return requestedAt, requestedAt.Add(configuration.GetTimeout()), true
+}
+
+// IsSwitchoverPauseAcknowledged checks whether every PgBouncer instance
+// has been paused for the switchover pause currently requested. A pooler
+// without any instance reported in its status never acknowledges the pause
+func (in *Pooler) IsSwitchoverPauseAcknowledged() bool {
+	request, found := in.Annotations[utils.SwitchoverPauseAnnotationName]
+	if !found || in.Status.Instances == 0 {
+		return false
+	}
+
+	acknowledged := 0
+	for _, value := range in.Status.SwitchoverPausedInstances {
+		if value == request {
+			acknowledged++
+		}
+	}
+	return acknowledged >= int(in.Status.Instances)
+}
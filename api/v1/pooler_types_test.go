@@ -17,6 +17,12 @@ limitations under the License.
 package v1
 
 import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -34,4 +40,80 @@ var _ = Describe("Pooler type tests", func() {
 		}
 		Expect(pgbouncer.IsPaused()).To(BeTrue())
 	})
+	It("knows when the auth query is managed by the operator", func() {
+		pooler := Pooler{Spec: PoolerSpec{PgBouncer: &PgBouncerSpec{}}}
+		Expect(pooler.IsAuthQueryManaged()).To(BeTrue())
+
+		pooler.Spec.PgBouncer.AuthQuery = "SELECT usename, passwd FROM my_search($1)"
+		pooler.Spec.PgBouncer.AuthQuerySecret = &LocalObjectReference{Name: "my-secret"}
+		Expect(pooler.IsAuthQueryManaged()).To(BeFalse())
+	})
+	Context("switchover pause", func() {
+		requestedAt := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+		request := requestedAt.Format(metav1.RFC3339Micro)
+
+		It("is disabled by default", func() {
+			pooler := Pooler{Spec: PoolerSpec{PgBouncer: &PgBouncerSpec{}}}
+			Expect(pooler.IsSwitchoverPauseEnabled()).To(BeFalse())
+
+			pooler.Spec.PgBouncer.SwitchoverPause = &SwitchoverPauseConfiguration{Enabled: true}
+			Expect(pooler.IsSwitchoverPauseEnabled()).To(BeTrue())
+		})
+
+		It("uses the default timeout when not specified", func() {
+			var configuration *SwitchoverPauseConfiguration
+			Expect(configuration.GetTimeout()).To(Equal(30 * time.Second))
+			Expect((&SwitchoverPauseConfiguration{}).GetTimeout()).To(Equal(30 * time.Second))
+			Expect((&SwitchoverPauseConfiguration{Timeout: 5}).GetTimeout()).To(Equal(5 * time.Second))
+		})
+
+		It("reads the requested pause and its deadline", func() {
+			pooler := Pooler{Spec: PoolerSpec{PgBouncer: &PgBouncerSpec{
+				SwitchoverPause: &SwitchoverPauseConfiguration{Enabled: true, Timeout: 10},
+			}}}
+			_, _, ok := pooler.GetSwitchoverPauseRequest()
+			Expect(ok).To(BeFalse())
+
+			pooler.Annotations = map[string]string{utils.SwitchoverPauseAnnotationName: "not-a-time"}
+			_, _, ok = pooler.GetSwitchoverPauseRequest()
+			Expect(ok).To(BeFalse())
+
+			pooler.Annotations[utils.SwitchoverPauseAnnotationName] = request
+			start, deadline, ok := pooler.GetSwitchoverPauseRequest()
+			Expect(ok).To(BeTrue())
+			Expect(start).To(BeTemporally("==", requestedAt))
+			Expect(deadline).To(BeTemporally("==", requestedAt.Add(10*time.Second)))
+		})
+
+		It("is acknowledged when every instance has been paused for the current request", func() {
+			pooler := Pooler{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{utils.SwitchoverPauseAnnotationName: request},
+				},
+				Status: PoolerStatus{
+					Instances: 2,
+					SwitchoverPausedInstances: map[string]string{
+						"pooler-1": request,
+						"pooler-2": requestedAt.Add(-time.Hour).Format(metav1.RFC3339Micro),
+					},
+				},
+			}
+			Expect(pooler.IsSwitchoverPauseAcknowledged()).To(BeFalse())
+
+			pooler.Status.SwitchoverPausedInstances["pooler-2"] = request
+			Expect(pooler.IsSwitchoverPauseAcknowledged()).To(BeTrue())
+
+			delete(pooler.Annotations, utils.SwitchoverPauseAnnotationName)
+			Expect(pooler.IsSwitchoverPauseAcknowledged()).To(BeFalse())
+		})
+
+		It("is never acknowledged without any instance", func() {
+			pooler := Pooler{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{utils.SwitchoverPauseAnnotationName: request},
+				},
+			}
+			Expect(pooler.IsSwitchoverPauseAcknowledged()).To(BeFalse())
+		})
+	})
 })
@@ -60,6 +60,13 @@ func (in *AffinityConfiguration) DeepCopyInto(out *AffinityConfiguration) {
 		*out = new(corev1.PodAffinity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.InstanceNodeSelectors != nil {
+		in, out := &in.InstanceNodeSelectors, &out.InstanceNodeSelectors
+		*out = make([]InstanceNodeSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AffinityConfiguration.
@@ -72,6 +79,36 @@ func (in *AffinityConfiguration) DeepCopy() *AffinityConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutovacuumConfiguration) DeepCopyInto(out *AutovacuumConfiguration) {
+	*out = *in
+	if in.MaxWorkers != nil {
+		in, out := &in.MaxWorkers, &out.MaxWorkers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Naptime != nil {
+		in, out := &in.Naptime, &out.Naptime
+		*out = new(int32)
+		**out = **in
+	}
+	if in.VacuumCostLimit != nil {
+		in, out := &in.VacuumCostLimit, &out.VacuumCostLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutovacuumConfiguration.
+func (in *AutovacuumConfiguration) DeepCopy() *AutovacuumConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(AutovacuumConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureCredentials) DeepCopyInto(out *AzureCredentials) {
 	*out = *in
@@ -142,6 +179,13 @@ func (in *BackupConfiguration) DeepCopyInto(out *BackupConfiguration) {
 		*out = new(BarmanObjectStoreConfiguration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdditionalWALArchives != nil {
+		in, out := &in.AdditionalWALArchives, &out.AdditionalWALArchives
+		*out = make([]WALArchiveDestination, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupConfiguration.
@@ -245,6 +289,15 @@ func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 		*out = new(InstanceID)
 		**out = **in
 	}
+	if in.NextRetryAt != nil {
+		in, out := &in.NextRetryAt, &out.NextRetryAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(BackupVerificationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStatus.
@@ -257,6 +310,25 @@ func (in *BackupStatus) DeepCopy() *BackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerificationStatus) DeepCopyInto(out *BackupVerificationStatus) {
+	*out = *in
+	if in.VerifiedAt != nil {
+		in, out := &in.VerifiedAt, &out.VerifiedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerificationStatus.
+func (in *BackupVerificationStatus) DeepCopy() *BackupVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BarmanCredentials) DeepCopyInto(out *BarmanCredentials) {
 	*out = *in
@@ -320,6 +392,25 @@ func (in *BarmanObjectStoreConfiguration) DeepCopyInto(out *BarmanObjectStoreCon
 			(*out)[key] = val
 		}
 	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]corev1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BarmanObjectStoreConfiguration.
@@ -475,6 +566,11 @@ func (in *CertificatesConfiguration) DeepCopyInto(out *CertificatesConfiguration
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ClientCertificateUsers != nil {
+		in, out := &in.ClientCertificateUsers, &out.ClientCertificateUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificatesConfiguration.
@@ -569,6 +665,21 @@ func (in *ClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterMonitoringTLSConfiguration) DeepCopyInto(out *ClusterMonitoringTLSConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMonitoringTLSConfiguration.
+func (in *ClusterMonitoringTLSConfiguration) DeepCopy() *ClusterMonitoringTLSConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterMonitoringTLSConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	*out = *in
@@ -619,8 +730,57 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		*out = new(StorageConfiguration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ProbesConfiguration)
+		**out = **in
+	}
+	if in.PreferredPrimary != nil {
+		in, out := &in.PreferredPrimary, &out.PreferredPrimary
+		*out = new(PreferredPrimaryConfiguration)
+		**out = **in
+	}
+	if in.ReadWriteService != nil {
+		in, out := &in.ReadWriteService, &out.ReadWriteService
+		*out = new(ServiceConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadOnlyService != nil {
+		in, out := &in.ReadOnlyService, &out.ReadOnlyService
+		*out = new(ReadOnlyServiceConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadService != nil {
+		in, out := &in.ReadService, &out.ReadService
+		*out = new(ServiceConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Affinity.DeepCopyInto(&out.Affinity)
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScheduledMaintenance != nil {
+		in, out := &in.ScheduledMaintenance, &out.ScheduledMaintenance
+		*out = make([]ScheduledMaintenance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Backup != nil {
 		in, out := &in.Backup, &out.Backup
 		*out = new(BackupConfiguration)
@@ -677,9 +837,14 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 		in, out := &in.InstancesReportedState, &out.InstancesReportedState
 		*out = make(map[PodName]InstanceReportedState, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.DataChecksums != nil {
+		in, out := &in.DataChecksums, &out.DataChecksums
+		*out = new(bool)
+		**out = **in
+	}
 	in.Topology.DeepCopyInto(&out.Topology)
 	if in.DanglingPVC != nil {
 		in, out := &in.DanglingPVC, &out.DanglingPVC
@@ -709,11 +874,38 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 	in.SecretsResourceVersion.DeepCopyInto(&out.SecretsResourceVersion)
 	in.ConfigMapResourceVersion.DeepCopyInto(&out.ConfigMapResourceVersion)
 	in.Certificates.DeepCopyInto(&out.Certificates)
+	if in.WALArchiveDestinations != nil {
+		in, out := &in.WALArchiveDestinations, &out.WALArchiveDestinations
+		*out = make(map[string]WALArchiveDestinationStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImportedRoles != nil {
+		in, out := &in.ImportedRoles, &out.ImportedRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScheduledMaintenance != nil {
+		in, out := &in.ScheduledMaintenance, &out.ScheduledMaintenance
+		*out = make([]ScheduledMaintenanceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(RetentionPolicyStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PoolerIntegrations != nil {
 		in, out := &in.PoolerIntegrations, &out.PoolerIntegrations
 		*out = new(PoolerIntegrations)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PendingMaintenanceOperations != nil {
+		in, out := &in.PendingMaintenanceOperations, &out.PendingMaintenanceOperations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -776,6 +968,21 @@ func (in *ConfigMapResourceVersion) DeepCopy() *ConfigMapResourceVersion {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionWarmUpConfiguration) DeepCopyInto(out *ConnectionWarmUpConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionWarmUpConfiguration.
+func (in *ConnectionWarmUpConfiguration) DeepCopy() *ConnectionWarmUpConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionWarmUpConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataBackupConfiguration) DeepCopyInto(out *DataBackupConfiguration) {
 	*out = *in
@@ -953,9 +1160,36 @@ func (in *InstanceID) DeepCopy() *InstanceID {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceNodeSelector) DeepCopyInto(out *InstanceNodeSelector) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceNodeSelector.
+func (in *InstanceNodeSelector) DeepCopy() *InstanceNodeSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceNodeSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceReportedState) DeepCopyInto(out *InstanceReportedState) {
 	*out = *in
+	if in.PendingRestartSettings != nil {
+		in, out := &in.PendingRestartSettings, &out.PendingRestartSettings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceReportedState.
@@ -1043,6 +1277,26 @@ func (in *LocalObjectReference) DeepCopy() *LocalObjectReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitoringConfiguration) DeepCopyInto(out *MonitoringConfiguration) {
 	*out = *in
@@ -1061,6 +1315,16 @@ func (in *MonitoringConfiguration) DeepCopyInto(out *MonitoringConfiguration) {
 		*out = make([]SecretKeySelector, len(*in))
 		copy(*out, *in)
 	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(ClusterMonitoringTLSConfiguration)
+		**out = **in
+	}
+	if in.SlowQueryEvents != nil {
+		in, out := &in.SlowQueryEvents, &out.SlowQueryEvents
+		*out = new(SlowQueryEventsConfiguration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConfiguration.
@@ -1149,6 +1413,11 @@ func (in *PgBouncerSpec) DeepCopyInto(out *PgBouncerSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SwitchoverPause != nil {
+		in, out := &in.SwitchoverPause, &out.SwitchoverPause
+		*out = new(SwitchoverPauseConfiguration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PgBouncerSpec.
@@ -1255,6 +1524,21 @@ func (in *Pooler) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolerAuthQueryStatus) DeepCopyInto(out *PoolerAuthQueryStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolerAuthQueryStatus.
+func (in *PoolerAuthQueryStatus) DeepCopy() *PoolerAuthQueryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolerAuthQueryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PoolerIntegrations) DeepCopyInto(out *PoolerIntegrations) {
 	*out = *in
@@ -1360,6 +1644,18 @@ func (in *PoolerStatus) DeepCopyInto(out *PoolerStatus) {
 		*out = new(PoolerSecrets)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AuthQuery != nil {
+		in, out := &in.AuthQuery, &out.AuthQuery
+		*out = new(PoolerAuthQueryStatus)
+		**out = **in
+	}
+	if in.SwitchoverPausedInstances != nil {
+		in, out := &in.SwitchoverPausedInstances, &out.SwitchoverPausedInstances
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolerStatus.
@@ -1423,6 +1719,41 @@ func (in *PostgresConfiguration) DeepCopyInto(out *PostgresConfiguration) {
 		*out = new(LDAPConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TCPKeepalives != nil {
+		in, out := &in.TCPKeepalives, &out.TCPKeepalives
+		*out = new(TCPKeepalivesConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedBuffersPercentage != nil {
+		in, out := &in.SharedBuffersPercentage, &out.SharedBuffersPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EffectiveCacheSizePercentage != nil {
+		in, out := &in.EffectiveCacheSizePercentage, &out.EffectiveCacheSizePercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IdleInTransactionSessionTimeout != nil {
+		in, out := &in.IdleInTransactionSessionTimeout, &out.IdleInTransactionSessionTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Autovacuum != nil {
+		in, out := &in.Autovacuum, &out.Autovacuum
+		*out = new(AutovacuumConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrePromotionHook != nil {
+		in, out := &in.PrePromotionHook, &out.PrePromotionHook
+		*out = new(PrePromotionHookConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionWarmUp != nil {
+		in, out := &in.ConnectionWarmUp, &out.ConnectionWarmUp
+		*out = new(ConnectionWarmUpConfiguration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresConfiguration.
@@ -1435,6 +1766,92 @@ func (in *PostgresConfiguration) DeepCopy() *PostgresConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrePromotionHookConfiguration) DeepCopyInto(out *PrePromotionHookConfiguration) {
+	*out = *in
+	if in.SQL != nil {
+		in, out := &in.SQL, &out.SQL
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrePromotionHookConfiguration.
+func (in *PrePromotionHookConfiguration) DeepCopy() *PrePromotionHookConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(PrePromotionHookConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreferredPrimaryConfiguration) DeepCopyInto(out *PreferredPrimaryConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreferredPrimaryConfiguration.
+func (in *PreferredPrimaryConfiguration) DeepCopy() *PreferredPrimaryConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(PreferredPrimaryConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbesConfiguration) DeepCopyInto(out *ProbesConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbesConfiguration.
+func (in *ProbesConfiguration) DeepCopy() *ProbesConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbesConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyConfiguration) DeepCopyInto(out *ProxyConfiguration) {
+	*out = *in
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyConfiguration.
+func (in *ProxyConfiguration) DeepCopy() *ProxyConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadOnlyServiceConfiguration) DeepCopyInto(out *ReadOnlyServiceConfiguration) {
+	*out = *in
+	in.ServiceConfiguration.DeepCopyInto(&out.ServiceConfiguration)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadOnlyServiceConfiguration.
+func (in *ReadOnlyServiceConfiguration) DeepCopy() *ReadOnlyServiceConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadOnlyServiceConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RecoveryTarget) DeepCopyInto(out *RecoveryTarget) {
 	*out = *in
@@ -1510,6 +1927,26 @@ func (in *ReplicationSlotsHAConfiguration) DeepCopy() *ReplicationSlotsHAConfigu
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionPolicyStatus) DeepCopyInto(out *RetentionPolicyStatus) {
+	*out = *in
+	if in.DeletedBackups != nil {
+		in, out := &in.DeletedBackups, &out.DeletedBackups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionPolicyStatus.
+func (in *RetentionPolicyStatus) DeepCopy() *RetentionPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RollingUpdateStatus) DeepCopyInto(out *RollingUpdateStatus) {
 	*out = *in
@@ -1673,6 +2110,41 @@ func (in *ScheduledBackupStatus) DeepCopy() *ScheduledBackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledMaintenance) DeepCopyInto(out *ScheduledMaintenance) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledMaintenance.
+func (in *ScheduledMaintenance) DeepCopy() *ScheduledMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledMaintenanceStatus) DeepCopyInto(out *ScheduledMaintenanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledMaintenanceStatus.
+func (in *ScheduledMaintenanceStatus) DeepCopy() *ScheduledMaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledMaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretKeySelector) DeepCopyInto(out *SecretKeySelector) {
 	*out = *in
@@ -1726,6 +2198,50 @@ func (in *SecretsResourceVersion) DeepCopy() *SecretsResourceVersion {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceConfiguration) DeepCopyInto(out *ServiceConfiguration) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceConfiguration.
+func (in *ServiceConfiguration) DeepCopy() *ServiceConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlowQueryEventsConfiguration) DeepCopyInto(out *SlowQueryEventsConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlowQueryEventsConfiguration.
+func (in *SlowQueryEventsConfiguration) DeepCopy() *SlowQueryEventsConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(SlowQueryEventsConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageConfiguration) DeepCopyInto(out *StorageConfiguration) {
 	*out = *in
@@ -1756,6 +2272,21 @@ func (in *StorageConfiguration) DeepCopy() *StorageConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchoverPauseConfiguration) DeepCopyInto(out *SwitchoverPauseConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchoverPauseConfiguration.
+func (in *SwitchoverPauseConfiguration) DeepCopy() *SwitchoverPauseConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchoverPauseConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncReplicaElectionConstraints) DeepCopyInto(out *SyncReplicaElectionConstraints) {
 	*out = *in
@@ -1776,6 +2307,36 @@ func (in *SyncReplicaElectionConstraints) DeepCopy() *SyncReplicaElectionConstra
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPKeepalivesConfiguration) DeepCopyInto(out *TCPKeepalivesConfiguration) {
+	*out = *in
+	if in.Idle != nil {
+		in, out := &in.Idle, &out.Idle
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPKeepalivesConfiguration.
+func (in *TCPKeepalivesConfiguration) DeepCopy() *TCPKeepalivesConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPKeepalivesConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Topology) DeepCopyInto(out *Topology) {
 	*out = *in
@@ -1808,6 +2369,37 @@ func (in *Topology) DeepCopy() *Topology {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WALArchiveDestination) DeepCopyInto(out *WALArchiveDestination) {
+	*out = *in
+	in.BarmanObjectStore.DeepCopyInto(&out.BarmanObjectStore)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WALArchiveDestination.
+func (in *WALArchiveDestination) DeepCopy() *WALArchiveDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(WALArchiveDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WALArchiveDestinationStatus) DeepCopyInto(out *WALArchiveDestinationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WALArchiveDestinationStatus.
+func (in *WALArchiveDestinationStatus) DeepCopy() *WALArchiveDestinationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WALArchiveDestinationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WalBackupConfiguration) DeepCopyInto(out *WalBackupConfiguration) {
 	*out = *in
@@ -89,6 +89,12 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, nil
 	}
 
+	// Wait for the scheduled retry, even if we have been woken up by a cluster change
+	if waitTime := backup.Status.GetRetryWaitTime(time.Now()); waitTime > 0 {
+		contextLogger.Debug("Waiting before retrying the backup", "nextRetryAt", backup.Status.NextRetryAt)
+		return ctrl.Result{RequeueAfter: waitTime}, nil
+	}
+
 	clusterName := backup.Spec.Cluster.Name
 	var cluster apiv1.Cluster
 	if err := r.Get(ctx, client.ObjectKey{
@@ -96,9 +102,7 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		Name:      clusterName,
 	}, &cluster); err != nil {
 		if apierrs.IsNotFound(err) {
-			r.Recorder.Eventf(&backup, "Warning", "FindingCluster",
-				"Unknown cluster %v, will retry in 30 seconds", clusterName)
-			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+			return r.retryBackup(ctx, &backup, "FindingCluster", fmt.Sprintf("Unknown cluster %v", clusterName))
 		}
 
 		backup.Status.SetAsFailed(fmt.Errorf("while getting cluster %s: %w", clusterName, err))
@@ -117,12 +121,8 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	}, &pod)
 	if err != nil {
 		if apierrs.IsNotFound(err) {
-			r.Recorder.Eventf(&backup, "Warning", "FindingPod",
-				"Couldn't find target pod %s, will retry in 30 seconds", cluster.Status.TargetPrimary)
-			contextLogger.Info("Couldn't find target pod, will retry in 30 seconds", "target",
-				cluster.Status.TargetPrimary)
-			backup.Status.Phase = apiv1.BackupPhasePending
-			return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, &backup)
+			return r.retryBackup(ctx, &backup, "FindingPod",
+				fmt.Sprintf("Couldn't find target pod %s", cluster.Status.TargetPrimary))
 		}
 		backup.Status.SetAsFailed(fmt.Errorf("while getting pod: %w", err))
 		r.Recorder.Eventf(&backup, "Warning", "FindingPod", "Error getting target pod: %s",
@@ -132,11 +132,8 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	contextLogger.Debug("Found pod for backup", "pod", pod.Name)
 
 	if !utils.IsPodReady(pod) {
-		contextLogger.Info("Not ready backup target, will retry in 30 seconds", "target", pod.Name)
-		backup.Status.Phase = apiv1.BackupPhasePending
-		r.Recorder.Eventf(&backup, "Warning", "BackupPending", "Backup target pod not ready: %s",
-			cluster.Status.TargetPrimary)
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, &backup)
+		return r.retryBackup(ctx, &backup, "BackupPending",
+			fmt.Sprintf("Backup target pod not ready: %s", cluster.Status.TargetPrimary))
 	}
 
 	if backup.Status.Phase != "" && backup.Status.InstanceID != nil {
@@ -185,6 +182,34 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	return ctrl.Result{}, err
 }
 
+// retryBackup schedules a new attempt to start the backup, waiting with an
+// exponential backoff, and marks the backup as failed when no retries are left
+func (r *BackupReconciler) retryBackup(
+	ctx context.Context,
+	backup *apiv1.Backup,
+	reason string,
+	message string,
+) (ctrl.Result, error) {
+	contextLogger := log.FromContext(ctx)
+
+	delay, retrying := backup.Status.SetAsRetrying(message, time.Now())
+	if !retrying {
+		contextLogger.Info("Cannot start the backup and no retries are left, marking it as failed",
+			"reason", message, "retryCount", backup.Status.RetryCount)
+		r.Recorder.Eventf(backup, "Warning", "Failed",
+			"Backup failed after %d retries: %s", backup.Status.RetryCount, message)
+		return ctrl.Result{}, r.Status().Update(ctx, backup)
+	}
+
+	contextLogger.Info("Cannot start the backup, will retry",
+		"reason", message,
+		"retryCount", backup.Status.RetryCount,
+		"nextRetryAt", backup.Status.NextRetryAt)
+	r.Recorder.Eventf(backup, "Warning", reason, "%s, will retry in %v (retry %d of %d)",
+		message, delay, backup.Status.RetryCount, apiv1.BackupMaxRetries)
+	return ctrl.Result{RequeueAfter: delay}, r.Status().Update(ctx, backup)
+}
+
 // StartBackup request a backup in a Pod and marks the backup started
 // or failed if needed
 func StartBackup(
@@ -197,6 +222,8 @@ func StartBackup(
 	// This backup has been started
 	status := backup.GetStatus()
 	status.Phase = apiv1.BackupPhaseStarted
+	status.Error = ""
+	status.NextRetryAt = nil
 	status.InstanceID = &apiv1.InstanceID{PodName: pod.Name, ContainerID: pod.Status.ContainerStatuses[0].ContainerID}
 	if err := postgres.UpdateBackupStatusAndRetry(ctx, client, backup); err != nil {
 		status.SetAsFailed(fmt.Errorf("can't update backup: %w", err))
@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"reflect"
 	goruntime "runtime"
+	"strings"
 	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -71,7 +72,9 @@ type ClusterReconciler struct {
 	Scheme          *runtime.Scheme
 	Recorder        record.EventRecorder
 
-	timeoutHTTPClient *http.Client
+	timeoutHTTPClient        *http.Client
+	rolloutLimiter           *rolloutLimiter
+	preferredPrimaryReporter *preferredPrimaryReporter
 }
 
 // NewClusterReconciler creates a new ClusterReconciler initializing it
@@ -91,7 +94,9 @@ func NewClusterReconciler(mgr manager.Manager, discoveryClient *discovery.Discov
 	}
 
 	return &ClusterReconciler{
-		timeoutHTTPClient: timeoutClient,
+		timeoutHTTPClient:        timeoutClient,
+		rolloutLimiter:           newRolloutLimiter(configuration.Current.MaxConcurrentRollouts),
+		preferredPrimaryReporter: newPreferredPrimaryReporter(),
 
 		DiscoveryClient: discoveryClient,
 		Client:          mgr.GetClient(),
@@ -146,6 +151,8 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	if cluster == nil {
+		r.rolloutLimiter.release(req.NamespacedName)
+		r.preferredPrimaryReporter.reset(req.NamespacedName)
 		if err := r.deleteDanglingMonitoringQueries(ctx, req.Namespace); err != nil {
 			contextLogger.Error(
 				err,
@@ -170,11 +177,22 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *apiv1.Cluster) (ctrl.Result, error) {
 	contextLogger := log.FromContext(ctx)
 
+	if !cluster.DeletionTimestamp.IsZero() {
+		r.rolloutLimiter.release(client.ObjectKeyFromObject(cluster))
+		r.preferredPrimaryReporter.reset(client.ObjectKeyFromObject(cluster))
+		return ctrl.Result{}, r.reconcileClusterDeletion(ctx, cluster)
+	}
+
 	if utils.IsReconciliationDisabled(&cluster.ObjectMeta) {
 		contextLogger.Warning("Disable reconciliation loop annotation set, skipping the reconciliation.")
+		r.rolloutLimiter.release(client.ObjectKeyFromObject(cluster))
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.reconcileRetainPVCsFinalizer(ctx, cluster); err != nil {
+		return ctrl.Result{}, fmt.Errorf("cannot reconcile the PVC reclaim policy: %w", err)
+	}
+
 	// IMPORTANT: the following call will delete conditions using
 	// invalid condition reasons.
 	//
@@ -223,6 +241,14 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *apiv1.Cluste
 		return ctrl.Result{}, fmt.Errorf("cannot update the resource status: %w", err)
 	}
 
+	// Report the progress of the switchover or the failover in progress, if any
+	if err := r.registerPrimaryChangeProgress(ctx, cluster); err != nil {
+		if apierrs.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("cannot update the switchover or failover progress: %w", err)
+	}
+
 	if cluster.Status.CurrentPrimary != "" &&
 		cluster.Status.CurrentPrimary != cluster.Status.TargetPrimary {
 		contextLogger.Info("There is a switchover or a failover "+
@@ -233,6 +259,22 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *apiv1.Cluste
 		return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
 	}
 
+	// Shut down the instances if the hibernation has been requested,
+	// or restore the former primary if the cluster is being resumed
+	hibernationResult, err := r.reconcileHibernation(ctx, cluster, resources)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if hibernationResult != nil {
+		r.rolloutLimiter.release(client.ObjectKeyFromObject(cluster))
+		return *hibernationResult, nil
+	}
+
+	// Resume the poolers which have been paused for a switchover
+	if err := r.resumePoolersAfterSwitchover(ctx, cluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Get the replication status
 	instancesStatus := r.getStatusFromInstances(ctx, resources.instances)
 
@@ -269,6 +311,15 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *apiv1.Cluste
 
 		return ctrl.Result{}, fmt.Errorf("cannot update the resource status: %w", err)
 	}
+
+	if isReplicaClusterPromotionPending(cluster, instancesStatus) {
+		contextLogger.Info("Waiting for the designated primary to be promoted",
+			"designatedPrimary", cluster.Status.CurrentPrimary)
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, r.RegisterPhase(ctx, cluster,
+			apiv1.PhaseReplicaClusterPromotion,
+			fmt.Sprintf("Waiting for the designated primary %v to be promoted", cluster.Status.CurrentPrimary))
+	}
+
 	result, err := r.handleSwitchover(ctx, cluster, resources, instancesStatus)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -277,6 +328,17 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *apiv1.Cluste
 		return *result, nil
 	}
 
+	// Destroy the replica whose rebuild has been requested, if any. This
+	// happens after the switchover and failover handling, so that a pending
+	// rebuild never delays the election of a new primary
+	rebuildResult, err := r.reconcileInstanceRebuild(ctx, cluster, resources)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if rebuildResult != nil {
+		return *rebuildResult, nil
+	}
+
 	// Updates all the objects managed by the controller
 	return r.reconcileResources(ctx, cluster, resources, instancesStatus)
 }
@@ -316,6 +378,14 @@ func (r *ClusterReconciler) handleSwitchover(
 			contextLogger.Info("Waiting for all WAL receivers to be down to elect a new primary")
 			return &ctrl.Result{RequeueAfter: 1 * time.Second}, nil
 		}
+		if err == ErrWaitingOnFailOverDelay {
+			contextLogger.Info("Waiting for the failover delay to expire")
+			return &ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+		}
+		if errors.Is(err, ErrWaitingForPoolersPause) {
+			contextLogger.Info("Waiting for the poolers to be paused before switching over")
+			return &ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+		}
 		contextLogger.Info("Cannot update target primary: operation cannot be fulfilled. "+
 			"An immediate retry will be scheduled",
 			"cluster", cluster.Name)
@@ -358,6 +428,10 @@ func (r *ClusterReconciler) getCluster(
 	if !namespace.DeletionTimestamp.IsZero() {
 		// This happens when you delete a namespace containing a Cluster resource. If that's the case,
 		// let's just wait for the Kubernetes to remove all object in the namespace.
+		// The PVCs are going away with the namespace, so there's nothing to retain.
+		if err := r.removeRetainPVCsFinalizer(ctx, cluster); err != nil {
+			return nil, err
+		}
 		return nil, nil
 	}
 
@@ -394,6 +468,11 @@ func (r *ClusterReconciler) reconcileResources(
 		return ctrl.Result{}, fmt.Errorf("cannot update role labels on pods: %w", err)
 	}
 
+	// Update the labels for the -ro service to exclude the lagging replicas
+	if err := r.updateCaughtUpLabelsOnPods(ctx, cluster, resources.instances, instancesStatus); err != nil {
+		return ctrl.Result{}, fmt.Errorf("cannot update caught up labels on pods: %w", err)
+	}
+
 	// updated any labels that are coming from the operator
 	if err := r.updateOperatorLabelsOnInstances(ctx, resources.instances); err != nil {
 		return ctrl.Result{}, fmt.Errorf("cannot update instance labels on pods: %w", err)
@@ -424,6 +503,16 @@ func (r *ClusterReconciler) reconcileResources(
 		return ctrl.Result{}, fmt.Errorf("cannot update annotations on pvcs: %w", err)
 	}
 
+	// A failed job won't be retried, and the cluster can't progress without the
+	// instance it was creating
+	if failedJobs := utils.FilterFailedJobs(resources.jobs.Items); len(failedJobs) > 0 {
+		job := failedJobs[0]
+		contextLogger.Warning("A job has failed, the cluster needs manual intervention",
+			"job", job.Name, "jobRole", job.Labels[utils.JobRoleLabelName])
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, r.RegisterPhase(ctx, cluster, apiv1.PhaseJobFailed,
+			fmt.Sprintf("The %s job %s failed, check its logs", job.Labels[utils.JobRoleLabelName], job.Name))
+	}
+
 	// Act on Pods and PVCs only if there is nothing that is currently being created or deleted
 	if runningJobs := resources.countRunningJobs(); runningJobs > 0 {
 		contextLogger.Debug("A job is currently running. Waiting", "count", runningJobs)
@@ -714,9 +803,46 @@ func (r *ClusterReconciler) handleRollingUpdate(
 ) (ctrl.Result, error) {
 	contextLogger := log.FromContext(ctx)
 
+	// PostgreSQL won't start after a restart if a shared preload library
+	// is missing, so we need the user to fix the configuration
+	if missingLibraries := getMissingSharedPreloadLibraries(cluster, &instancesStatus); len(missingLibraries) > 0 {
+		contextLogger.Warning("Some shared preload libraries can't be found, the rollout is suspended",
+			"missingLibraries", missingLibraries)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, r.RegisterPhase(ctx, cluster,
+			apiv1.PhaseMissingSharedPreloadLibraries,
+			fmt.Sprintf("The shared preload libraries %s can't be found in the image %s",
+				strings.Join(missingLibraries, ", "), cluster.GetImageName()))
+	}
+
+	// Disruptive operations are deferred until the maintenance window, if any
+	waitForWindow, err := r.deferRolloutToMaintenanceWindow(ctx, cluster, &instancesStatus)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if waitForWindow > 0 {
+		r.rolloutLimiter.release(client.ObjectKeyFromObject(cluster))
+		return ctrl.Result{RequeueAfter: waitForWindow}, ErrNextLoop
+	}
+
+	// Rollouts are queued when the operator is already rolling out
+	// the maximum number of clusters
+	waitForSlot, err := r.waitForRolloutSlot(ctx, cluster, &instancesStatus)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if waitForSlot {
+		return ctrl.Result{RequeueAfter: rolloutSlotCheckInterval}, ErrNextLoop
+	}
+
 	// If we need to roll out a restart of any instance, this is the right moment
 	// Do I have to roll out a new image?
 	done, err := r.rolloutDueToCondition(ctx, cluster, &instancesStatus, IsPodNeedingRollout)
+	if errors.Is(err, ErrWaitingForPoolersPause) {
+		// The poolers are paused in a short time, and releasing the rollout
+		// slot here could leave the cluster half rolled out
+		contextLogger.Info("Waiting for the poolers to be paused before switching over")
+		return ctrl.Result{RequeueAfter: 1 * time.Second}, ErrNextLoop
+	}
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -93,6 +93,11 @@ func (r *ClusterReconciler) createPostgresClusterObjects(ctx context.Context, cl
 		return err
 	}
 
+	err = r.reconcileInheritedMetadata(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
 	// TODO: only required to cleanup custom monitoring queries configmaps from older versions (v1.10 and v1.11)
 	// 		 that could have been copied with the source configmap name instead of the new default one.
 	// 		 Should be removed in future releases.
@@ -286,6 +291,9 @@ func (r *ClusterReconciler) createPostgresServices(ctx context.Context, cluster
 		if !apierrs.IsAlreadyExists(err) {
 			return err
 		}
+		if err := r.updateService(ctx, readService); err != nil {
+			return err
+		}
 	}
 
 	readOnlyService := specs.CreateClusterReadOnlyService(*cluster)
@@ -295,6 +303,9 @@ func (r *ClusterReconciler) createPostgresServices(ctx context.Context, cluster
 		if !apierrs.IsAlreadyExists(err) {
 			return err
 		}
+		if err := r.updateService(ctx, readOnlyService); err != nil {
+			return err
+		}
 	}
 
 	readWriteService := specs.CreateClusterReadWriteService(*cluster)
@@ -304,11 +315,54 @@ func (r *ClusterReconciler) createPostgresServices(ctx context.Context, cluster
 		if !apierrs.IsAlreadyExists(err) {
 			return err
 		}
+		if err := r.updateService(ctx, readWriteService); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// updateService aligns the selector, the type, the annotations and the labels
+// of an existing service with the expected ones, which may change following
+// the cluster configuration. Annotations and labels which are not expected
+// are kept, as they may have been added by other controllers, i.e. the
+// ones of the cloud provider load balancers
+func (r *ClusterReconciler) updateService(ctx context.Context, expected *corev1.Service) error {
+	var service corev1.Service
+	if err := r.Get(ctx, client.ObjectKeyFromObject(expected), &service); err != nil {
+		return fmt.Errorf("while getting service %s: %w", expected.Name, err)
+	}
+
+	if reflect.DeepEqual(service.Spec.Selector, expected.Spec.Selector) &&
+		service.Spec.Type == expected.Spec.Type &&
+		utils.IsMapSubset(service.Annotations, expected.Annotations) &&
+		utils.IsMapSubset(service.Labels, expected.Labels) {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Updating service",
+		"service", service.Name,
+		"selector", expected.Spec.Selector,
+		"type", expected.Spec.Type)
+	patch := client.MergeFrom(service.DeepCopy())
+	service.Spec.Selector = expected.Spec.Selector
+	service.Spec.Type = expected.Spec.Type
+	if service.Annotations == nil {
+		service.Annotations = make(map[string]string, len(expected.Annotations))
+	}
+	for key, value := range expected.Annotations {
+		service.Annotations[key] = value
+	}
+	if service.Labels == nil {
+		service.Labels = make(map[string]string, len(expected.Labels))
+	}
+	for key, value := range expected.Labels {
+		service.Labels[key] = value
+	}
+	return r.Patch(ctx, &service, patch)
+}
+
 // createOrPatchOwnedPodDisruptionBudget ensures that we have a PDB requiring to remove one node at a time
 func (r *ClusterReconciler) createOrPatchOwnedPodDisruptionBudget(
 	ctx context.Context,
@@ -18,14 +18,19 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/internal/configuration"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
 // deleteDanglingMonitoringQueries deletes the default monitoring configMap and/or secret if no cluster in the namespace
@@ -87,3 +92,88 @@ func (r *ClusterReconciler) deleteDanglingMonitoringQueries(ctx context.Context,
 
 	return nil
 }
+
+// reconcileRetainPVCsFinalizer adds the finalizer detaching the PVCs from
+// the cluster at deletion time when some of them need to be retained, and
+// removes it otherwise
+func (r *ClusterReconciler) reconcileRetainPVCsFinalizer(ctx context.Context, cluster *apiv1.Cluster) error {
+	if !cluster.ShouldRetainPVCs() {
+		return r.removeRetainPVCsFinalizer(ctx, cluster)
+	}
+
+	if controllerutil.ContainsFinalizer(cluster, utils.RetainPVCsFinalizerName) {
+		return nil
+	}
+
+	origCluster := cluster.DeepCopy()
+	controllerutil.AddFinalizer(cluster, utils.RetainPVCsFinalizerName)
+	return r.Patch(ctx, cluster, client.MergeFrom(origCluster))
+}
+
+// removeRetainPVCsFinalizer removes the finalizer detaching the PVCs from
+// the cluster, if present
+func (r *ClusterReconciler) removeRetainPVCsFinalizer(ctx context.Context, cluster *apiv1.Cluster) error {
+	if !controllerutil.ContainsFinalizer(cluster, utils.RetainPVCsFinalizerName) {
+		return nil
+	}
+
+	origCluster := cluster.DeepCopy()
+	controllerutil.RemoveFinalizer(cluster, utils.RetainPVCsFinalizerName)
+	return r.Patch(ctx, cluster, client.MergeFrom(origCluster))
+}
+
+// reconcileClusterDeletion detaches the PVCs to be retained from a cluster
+// being deleted, so that they are not removed by the Kubernetes garbage
+// collector, and then lets the deletion proceed
+func (r *ClusterReconciler) reconcileClusterDeletion(ctx context.Context, cluster *apiv1.Cluster) error {
+	contextLogger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(cluster, utils.RetainPVCsFinalizerName) {
+		return nil
+	}
+
+	pvcs, err := r.getManagedPVCs(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	for _, pvc := range getPVCsToRetain(cluster, pvcs.Items) {
+		origPVC := pvc.DeepCopy()
+		pvc.OwnerReferences = removeOwnerReference(pvc.OwnerReferences, cluster.UID)
+		if err := r.Patch(ctx, &pvc, client.MergeFrom(origPVC)); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("while detaching PVC %v from the cluster: %w", pvc.Name, err)
+		}
+		contextLogger.Info("Retaining PVC after cluster deletion", "pvc", pvc.Name)
+	}
+
+	return r.removeRetainPVCsFinalizer(ctx, cluster)
+}
+
+// getPVCsToRetain returns the PVCs whose reclaim policy is retain
+func getPVCsToRetain(
+	cluster *apiv1.Cluster,
+	pvcs []corev1.PersistentVolumeClaim,
+) []corev1.PersistentVolumeClaim {
+	var result []corev1.PersistentVolumeClaim
+	for _, pvc := range pvcs {
+		role := utils.PVCRole(pvc.Labels[utils.PvcRoleLabelName])
+		if cluster.GetPVCReclaimPolicy(role) == apiv1.PVCReclaimPolicyRetain {
+			result = append(result, pvc)
+		}
+	}
+
+	return result
+}
+
+// removeOwnerReference returns the passed owner references without
+// the one pointing to the object having the passed UID
+func removeOwnerReference(references []metav1.OwnerReference, uid types.UID) []metav1.OwnerReference {
+	result := make([]metav1.OwnerReference, 0, len(references))
+	for _, reference := range references {
+		if reference.UID != uid {
+			result = append(result, reference)
+		}
+	}
+
+	return result
+}
@@ -27,6 +27,7 @@ import (
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/internal/configuration"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -135,3 +136,45 @@ var _ = Describe("ensures that deleteDanglingMonitoringQueries works correctly",
 		})
 	})
 })
+
+var _ = Describe("PVC reclaim policy", func() {
+	pvc := func(name string, role utils.PVCRole) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{utils.PvcRoleLabelName: string(role)},
+			},
+		}
+	}
+	pvcs := []corev1.PersistentVolumeClaim{
+		pvc("cluster-example-1", utils.PVCRolePgData),
+		pvc("cluster-example-1-wal", utils.PVCRolePgWal),
+	}
+
+	It("doesn't retain any PVC by default", func() {
+		cluster := &apiv1.Cluster{}
+		Expect(getPVCsToRetain(cluster, pvcs)).To(BeEmpty())
+	})
+
+	It("retains the PVCs having the retain policy", func() {
+		cluster := &apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				StorageConfiguration: apiv1.StorageConfiguration{PVCReclaimPolicy: apiv1.PVCReclaimPolicyRetain},
+				WalStorage:           &apiv1.StorageConfiguration{PVCReclaimPolicy: apiv1.PVCReclaimPolicyDelete},
+			},
+		}
+		retained := getPVCsToRetain(cluster, pvcs)
+		Expect(retained).To(HaveLen(1))
+		Expect(retained[0].Name).To(Equal("cluster-example-1"))
+	})
+
+	It("removes only the owner reference of the cluster", func() {
+		references := []metav1.OwnerReference{
+			{Name: "cluster-example", UID: "cluster-uid"},
+			{Name: "other", UID: "other-uid"},
+		}
+		result := removeOwnerReference(references, "cluster-uid")
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Name).To(Equal("other"))
+	})
+})
@@ -0,0 +1,262 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+)
+
+// reconcileHibernation shuts down the instances of a cluster whose
+// hibernation has been requested, keeping the PVCs, and ensures
+// that the former primary is restored when the cluster is resumed.
+// A non-nil result means that the reconciliation loop must stop here
+func (r *ClusterReconciler) reconcileHibernation(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	resources *managedResources,
+) (*ctrl.Result, error) {
+	if utils.IsHibernationEnabled(&cluster.ObjectMeta) {
+		return r.hibernateCluster(ctx, cluster, resources)
+	}
+
+	return r.resumeCluster(ctx, cluster, resources)
+}
+
+// hibernateCluster records the current primary on the PVCs and then
+// deletes the instances, starting from the primary
+func (r *ClusterReconciler) hibernateCluster(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	resources *managedResources,
+) (*ctrl.Result, error) {
+	contextLogger := log.FromContext(ctx)
+
+	if runningJobs := resources.countRunningJobs(); runningJobs > 0 {
+		contextLogger.Info("Waiting for the running jobs to complete before hibernating the cluster",
+			"count", runningJobs)
+		return &ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+	}
+
+	if len(resources.instances.Items) == 0 {
+		return &ctrl.Result{}, r.RegisterPhase(ctx, cluster, apiv1.PhaseHibernated,
+			"All the instances have been shut down")
+	}
+
+	if err := r.annotatePVCsWithHibernationPrimary(ctx, cluster.Status.CurrentPrimary, resources.pvcs); err != nil {
+		return nil, err
+	}
+
+	// The primary is shut down first: during a clean shutdown PostgreSQL
+	// sends all the WAL records to the connected replicas, so that they
+	// won't be ahead of the primary when the cluster is resumed
+	podsToDelete := getPodsToHibernate(cluster.Status.CurrentPrimary, resources.instances.Items)
+	for idx := range podsToDelete {
+		pod := &podsToDelete[idx]
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		contextLogger.Info("Shutting down instance for hibernation", "pod", pod.Name)
+		if err := r.Delete(ctx, pod); err != nil && !apierrs.IsNotFound(err) {
+			return nil, fmt.Errorf("while deleting pod %s for hibernation: %w", pod.Name, err)
+		}
+	}
+
+	return &ctrl.Result{RequeueAfter: 1 * time.Second}, r.RegisterPhase(ctx, cluster, apiv1.PhaseHibernating,
+		fmt.Sprintf("Waiting for %d instances to be shut down", len(resources.instances.Items)))
+}
+
+// getPodsToHibernate returns the Pods that can be deleted now to
+// hibernate the cluster: the primary, if it still exists, or all the
+// other instances otherwise
+func getPodsToHibernate(primaryName string, pods []corev1.Pod) []corev1.Pod {
+	for _, pod := range pods {
+		if pod.Name == primaryName {
+			return []corev1.Pod{pod}
+		}
+	}
+
+	return pods
+}
+
+// annotatePVCsWithHibernationPrimary stores the name of the primary
+// instance in every PVC not already carrying this information
+func (r *ClusterReconciler) annotatePVCsWithHibernationPrimary(
+	ctx context.Context,
+	primaryName string,
+	pvcs corev1.PersistentVolumeClaimList,
+) error {
+	if primaryName == "" {
+		return nil
+	}
+
+	for idx := range pvcs.Items {
+		pvc := &pvcs.Items[idx]
+		if _, ok := pvc.Annotations[utils.HibernationPrimaryAnnotationName]; ok {
+			continue
+		}
+
+		patch := client.MergeFrom(pvc.DeepCopy())
+		if pvc.Annotations == nil {
+			pvc.Annotations = make(map[string]string)
+		}
+		pvc.Annotations[utils.HibernationPrimaryAnnotationName] = primaryName
+		if err := r.Patch(ctx, pvc, patch); err != nil {
+			return fmt.Errorf("while annotating PVC %s for hibernation: %w", pvc.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// resumeCluster ensures that a cluster resumed from hibernation is
+// restarted using the former primary, removing the hibernation
+// annotations from the PVCs when the primary is ready again.
+// The former primary is reported in the cluster status while the
+// cluster is being resumed
+func (r *ClusterReconciler) resumeCluster(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	resources *managedResources,
+) (*ctrl.Result, error) {
+	contextLogger := log.FromContext(ctx)
+
+	primaryName := getHibernationPrimary(resources.pvcs.Items)
+	if primaryName == "" {
+		return nil, r.resetHibernationResumePrimary(ctx, cluster)
+	}
+
+	if missingPVCs := getMissingInstancePVCs(cluster, primaryName, resources); len(missingPVCs) > 0 {
+		contextLogger.Warning("Cannot resume the cluster from hibernation, the former primary PVCs are missing",
+			"primary", primaryName, "missingPVCs", missingPVCs)
+		return &ctrl.Result{RequeueAfter: 10 * time.Second}, r.RegisterPhase(ctx, cluster, apiv1.PhaseUnrecoverable,
+			fmt.Sprintf("Cannot resume from hibernation, the PVCs %v of the former primary %s are missing",
+				missingPVCs, primaryName))
+	}
+
+	// The instance manager of the former primary will refuse to start
+	// PostgreSQL if it wasn't shut down cleanly before the hibernation
+	if cluster.Status.TargetPrimary != primaryName || cluster.Status.CurrentPrimary != primaryName ||
+		cluster.Status.HibernationResumePrimary != primaryName {
+		contextLogger.Info("Restoring the former primary after the hibernation",
+			"primary", primaryName,
+			"currentPrimary", cluster.Status.CurrentPrimary,
+			"targetPrimary", cluster.Status.TargetPrimary)
+		cluster.Status.HibernationResumePrimary = primaryName
+		cluster.Status.CurrentPrimary = primaryName
+		cluster.Status.CurrentPrimaryTimestamp = utils.GetCurrentTimestamp()
+		if err := r.setPrimaryInstance(ctx, cluster, primaryName); err != nil {
+			return nil, err
+		}
+		return &ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+	}
+
+	for _, pod := range resources.instances.Items {
+		if pod.Name == primaryName && utils.IsPodReady(pod) {
+			contextLogger.Info("The former primary is ready, the cluster has been resumed from hibernation",
+				"primary", primaryName)
+			if err := r.removeHibernationPrimaryAnnotation(ctx, resources.pvcs); err != nil {
+				return nil, err
+			}
+			return nil, r.resetHibernationResumePrimary(ctx, cluster)
+		}
+	}
+
+	return nil, nil
+}
+
+// getHibernationPrimary returns the name of the instance that was the
+// primary when the cluster has been hibernated, or an empty string if
+// the PVCs don't belong to an hibernated cluster
+func getHibernationPrimary(pvcs []corev1.PersistentVolumeClaim) string {
+	for _, pvc := range pvcs {
+		if primaryName := pvc.Annotations[utils.HibernationPrimaryAnnotationName]; primaryName != "" {
+			return primaryName
+		}
+	}
+
+	return ""
+}
+
+// getMissingInstancePVCs returns the names of the PVCs which are
+// expected for the passed instance but don't exist
+func getMissingInstancePVCs(
+	cluster *apiv1.Cluster,
+	instanceName string,
+	resources *managedResources,
+) []string {
+	expectedPVCs := []string{instanceName}
+	if cluster.ShouldCreateWalArchiveVolume() {
+		expectedPVCs = append(expectedPVCs, instanceName+cluster.GetWalArchiveVolumeSuffix())
+	}
+
+	var missingPVCs []string
+	for _, pvcName := range expectedPVCs {
+		if resources.getPVC(pvcName) == nil {
+			missingPVCs = append(missingPVCs, pvcName)
+		}
+	}
+
+	return missingPVCs
+}
+
+// removeHibernationPrimaryAnnotation removes the hibernation
+// annotation from the PVCs of a resumed cluster
+func (r *ClusterReconciler) removeHibernationPrimaryAnnotation(
+	ctx context.Context,
+	pvcs corev1.PersistentVolumeClaimList,
+) error {
+	for idx := range pvcs.Items {
+		pvc := &pvcs.Items[idx]
+		if _, ok := pvc.Annotations[utils.HibernationPrimaryAnnotationName]; !ok {
+			continue
+		}
+
+		patch := client.MergeFrom(pvc.DeepCopy())
+		delete(pvc.Annotations, utils.HibernationPrimaryAnnotationName)
+		if err := r.Patch(ctx, pvc, patch); err != nil {
+			return fmt.Errorf("while removing the hibernation annotation from PVC %s: %w", pvc.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// resetHibernationResumePrimary clears the former primary from the
+// status of a cluster which is not being resumed from hibernation
+func (r *ClusterReconciler) resetHibernationResumePrimary(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+) error {
+	if cluster.Status.HibernationResumePrimary == "" {
+		return nil
+	}
+
+	cluster.Status.HibernationResumePrimary = ""
+	return r.Status().Update(ctx, cluster)
+}
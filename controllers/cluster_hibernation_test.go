@@ -0,0 +1,161 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cluster hibernation", func() {
+	newPod := func(name string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	newPVC := func(name string, annotations map[string]string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+
+	It("shuts down the primary before the other instances", func() {
+		pods := []corev1.Pod{newPod("cluster-1"), newPod("cluster-2"), newPod("cluster-3")}
+		Expect(getPodsToHibernate("cluster-2", pods)).To(Equal([]corev1.Pod{newPod("cluster-2")}))
+		Expect(getPodsToHibernate("cluster-4", pods)).To(Equal(pods))
+	})
+
+	It("detects the former primary from the PVC annotations", func() {
+		Expect(getHibernationPrimary([]corev1.PersistentVolumeClaim{
+			newPVC("cluster-1", nil),
+		})).To(BeEmpty())
+
+		Expect(getHibernationPrimary([]corev1.PersistentVolumeClaim{
+			newPVC("cluster-1", nil),
+			newPVC("cluster-2", map[string]string{utils.HibernationPrimaryAnnotationName: "cluster-2"}),
+		})).To(Equal("cluster-2"))
+	})
+
+	It("detects the missing PVCs of the former primary", func() {
+		cluster := &apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec: apiv1.ClusterSpec{
+				WalStorage: &apiv1.StorageConfiguration{Size: "1Gi"},
+			},
+		}
+		resources := &managedResources{
+			pvcs: corev1.PersistentVolumeClaimList{
+				Items: []corev1.PersistentVolumeClaim{newPVC("cluster-1", nil)},
+			},
+		}
+		Expect(getMissingInstancePVCs(cluster, "cluster-1", resources)).To(
+			Equal([]string{"cluster-1" + cluster.GetWalArchiveVolumeSuffix()}))
+
+		cluster.Spec.WalStorage = nil
+		Expect(getMissingInstancePVCs(cluster, "cluster-1", resources)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("cluster resume from hibernation", func() {
+	const namespace = "default"
+
+	var (
+		cluster    *apiv1.Cluster
+		pvc        *corev1.PersistentVolumeClaim
+		fakeClient client.Client
+		reconciler *ClusterReconciler
+	)
+
+	BeforeEach(func() {
+		cluster = &apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: namespace},
+			Spec:       apiv1.ClusterSpec{Instances: 1},
+			Status: apiv1.ClusterStatus{
+				CurrentPrimary: "cluster-1",
+				TargetPrimary:  "cluster-1",
+			},
+		}
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cluster-1",
+				Namespace:   namespace,
+				Annotations: map[string]string{utils.HibernationPrimaryAnnotationName: "cluster-1"},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(cluster, pvc).
+			Build()
+		reconciler = &ClusterReconciler{
+			Client:   fakeClient,
+			Recorder: record.NewFakeRecorder(10),
+		}
+	})
+
+	resume := func(pods ...corev1.Pod) error {
+		var pvcs corev1.PersistentVolumeClaimList
+		Expect(fakeClient.List(context.Background(), &pvcs)).To(Succeed())
+		_, err := reconciler.resumeCluster(context.Background(), cluster, &managedResources{
+			instances: corev1.PodList{Items: pods},
+			pvcs:      pvcs,
+		})
+		return err
+	}
+
+	It("reports the former primary until it is ready", func() {
+		Expect(resume()).To(Succeed())
+		Expect(cluster.Status.HibernationResumePrimary).To(Equal("cluster-1"))
+
+		By("waiting for the former primary to be ready")
+		primary := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-1", Namespace: namespace}}
+		Expect(resume(primary)).To(Succeed())
+		Expect(cluster.Status.HibernationResumePrimary).To(Equal("cluster-1"))
+
+		By("resuming the cluster once the former primary is ready")
+		primary.Status.Conditions = []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}}
+		Expect(resume(primary)).To(Succeed())
+		Expect(cluster.Status.HibernationResumePrimary).To(BeEmpty())
+
+		var existingPVC corev1.PersistentVolumeClaim
+		Expect(fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pvc), &existingPVC)).To(Succeed())
+		Expect(existingPVC.Annotations).ToNot(HaveKey(utils.HibernationPrimaryAnnotationName))
+	})
+
+	It("stops reporting the former primary when the PVCs are not annotated anymore", func() {
+		Expect(resume()).To(Succeed())
+		Expect(cluster.Status.HibernationResumePrimary).To(Equal("cluster-1"))
+
+		Expect(reconciler.removeHibernationPrimaryAnnotation(context.Background(),
+			corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{*pvc}})).To(Succeed())
+		Expect(resume()).To(Succeed())
+		Expect(cluster.Status.HibernationResumePrimary).To(BeEmpty())
+	})
+})
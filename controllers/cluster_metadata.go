@@ -0,0 +1,140 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/configuration"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+)
+
+// reconcileInheritedMetadata applies the labels and annotations inherited from the
+// cluster to the services, the PodDisruptionBudgets, the ServiceAccount and the
+// secrets generated by the operator. Pods and PVCs are updated together with
+// the other instance resources
+func (r *ClusterReconciler) reconcileInheritedMetadata(ctx context.Context, cluster *apiv1.Cluster) error {
+	objects := []client.Object{
+		&corev1.Service{},
+		&corev1.Service{},
+		&corev1.Service{},
+		&corev1.Service{},
+		&policyv1.PodDisruptionBudget{},
+		&policyv1.PodDisruptionBudget{},
+		&corev1.ServiceAccount{},
+	}
+	names := []string{
+		cluster.GetServiceAnyName(),
+		cluster.GetServiceReadName(),
+		cluster.GetServiceReadOnlyName(),
+		cluster.GetServiceReadWriteName(),
+		cluster.Name,
+		cluster.Name + apiv1.PrimaryPodDisruptionBudgetSuffix,
+		cluster.Name,
+	}
+
+	for _, secretName := range getClusterGeneratedSecretNames(cluster) {
+		objects = append(objects, &corev1.Secret{})
+		names = append(names, secretName)
+	}
+
+	for idx, object := range objects {
+		err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: names[idx]}, object)
+		if apierrs.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("while getting %s to update its metadata: %w", names[idx], err)
+		}
+
+		// Objects provided by the user are left untouched
+		if owner, owned := IsOwnedByCluster(object); !owned || owner != cluster.Name {
+			continue
+		}
+
+		if err := r.updateInheritedMetadata(ctx, cluster, object); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getClusterGeneratedSecretNames gets the names of the secrets
+// that can be generated by the operator for a cluster
+func getClusterGeneratedSecretNames(cluster *apiv1.Cluster) []string {
+	result := []string{
+		cluster.GetSuperuserSecretName(),
+		cluster.GetApplicationSecretName(),
+		cluster.GetServerCASecretName(),
+		cluster.GetServerTLSSecretName(),
+		cluster.GetReplicationSecretName(),
+	}
+
+	if cluster.GetClientCASecretName() != cluster.GetServerCASecretName() {
+		result = append(result, cluster.GetClientCASecretName())
+	}
+
+	for _, user := range cluster.GetClientCertificateUsers() {
+		result = append(result, cluster.GetClientCertificateSecretName(user))
+	}
+
+	return result
+}
+
+// updateInheritedMetadata patches the passed object, if needed, to
+// include the labels and annotations inherited from the cluster
+func (r *ClusterReconciler) updateInheritedMetadata(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	object client.Object,
+) error {
+	fixedInheritedLabels := cluster.GetFixedInheritedLabels()
+	fixedInheritedAnnotations := cluster.GetFixedInheritedAnnotations()
+
+	if utils.IsLabelSubset(object.GetLabels(), cluster.Labels, fixedInheritedLabels, configuration.Current) &&
+		utils.IsAnnotationSubset(object.GetAnnotations(), cluster.Annotations, fixedInheritedAnnotations,
+			configuration.Current) {
+		return nil
+	}
+
+	origObject := object.DeepCopyObject().(client.Object)
+
+	objectMeta := metav1.ObjectMeta{Labels: object.GetLabels(), Annotations: object.GetAnnotations()}
+	utils.InheritLabels(&objectMeta, cluster.Labels, fixedInheritedLabels, configuration.Current)
+	utils.InheritAnnotations(&objectMeta, cluster.Annotations, fixedInheritedAnnotations, configuration.Current)
+	object.SetLabels(objectMeta.Labels)
+	object.SetAnnotations(objectMeta.Annotations)
+
+	log.FromContext(ctx).Info("Updating the inherited metadata",
+		"kind", fmt.Sprintf("%T", object),
+		"name", object.GetName())
+	if err := r.Patch(ctx, object, client.MergeFrom(origObject)); err != nil {
+		return fmt.Errorf("while updating the metadata of %s: %w", object.GetName(), err)
+	}
+
+	return nil
+}
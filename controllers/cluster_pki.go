@@ -28,6 +28,7 @@ import (
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/certs"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/stringset"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
@@ -89,6 +90,61 @@ func (r *ClusterReconciler) setupPostgresPKI(ctx context.Context, cluster *apiv1
 		return fmt.Errorf("generating server certificate: %w", err)
 	}
 
+	// Generating the client certificates requested by the user
+	for _, user := range cluster.GetClientCertificateUsers() {
+		userSecretName := client.ObjectKey{
+			Namespace: cluster.GetNamespace(),
+			Name:      cluster.GetClientCertificateSecretName(user),
+		}
+		err = r.ensureLeafCertificate(
+			ctx,
+			cluster,
+			userSecretName,
+			user,
+			clientCaSecret,
+			certs.CertTypeClient,
+			nil,
+			map[string]string{
+				utils.ClusterLabelName:               cluster.Name,
+				utils.ClientCertificateUserLabelName: user,
+			})
+		if err != nil {
+			return fmt.Errorf("generating client certificate for role %s: %w", user, err)
+		}
+	}
+
+	return r.deleteStaleClientCertificates(ctx, cluster)
+}
+
+// deleteStaleClientCertificates removes the client certificates that have been
+// generated for roles which are not listed in the cluster specification anymore
+func (r *ClusterReconciler) deleteStaleClientCertificates(ctx context.Context, cluster *apiv1.Cluster) error {
+	var secrets v1.SecretList
+	if err := r.List(
+		ctx,
+		&secrets,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{utils.ClusterLabelName: cluster.Name},
+		client.HasLabels{utils.ClientCertificateUserLabelName},
+	); err != nil {
+		return fmt.Errorf("while listing client certificates: %w", err)
+	}
+
+	users := stringset.From(cluster.GetClientCertificateUsers())
+	for idx := range secrets.Items {
+		secret := &secrets.Items[idx]
+		if users.Has(secret.Labels[utils.ClientCertificateUserLabelName]) {
+			continue
+		}
+
+		log.FromContext(ctx).Info("Deleting stale client certificate",
+			"secret", secret.Name,
+			"user", secret.Labels[utils.ClientCertificateUserLabelName])
+		if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("while deleting client certificate %s: %w", secret.Name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -114,7 +170,7 @@ func (r *ClusterReconciler) ensureClientCASecret(ctx context.Context, cluster *a
 	}
 
 	// Validate also ca.key if needed
-	if cluster.Spec.Certificates.ReplicationTLSSecret == "" {
+	if cluster.Spec.Certificates.ReplicationTLSSecret == "" || len(cluster.GetClientCertificateUsers()) > 0 {
 		_, err = certs.ParseCASecret(&secret)
 		if err != nil {
 			r.Recorder.Event(cluster, "Warning", "InvalidCASecret",
@@ -210,7 +266,7 @@ func (r *ClusterReconciler) ensureCASecret(ctx context.Context, cluster *apiv1.C
 	}
 
 	derivedCaSecret := caPair.GenerateCASecret(cluster.Namespace, secretName)
-	utils.SetAsOwnedBy(&derivedCaSecret.ObjectMeta, cluster.ObjectMeta, cluster.TypeMeta)
+	SetClusterOwnerAnnotationsAndLabels(&derivedCaSecret.ObjectMeta, cluster)
 	err = r.Create(ctx, derivedCaSecret)
 
 	return derivedCaSecret, err
@@ -308,9 +364,9 @@ func (r *ClusterReconciler) ensureLeafCertificate(
 		return err
 	}
 
-	utils.SetAsOwnedBy(&serverSecret.ObjectMeta, cluster.ObjectMeta, cluster.TypeMeta)
+	SetClusterOwnerAnnotationsAndLabels(&serverSecret.ObjectMeta, cluster)
 	for k, v := range additionalLabels {
-		if serverSecret.Annotations == nil {
+		if serverSecret.Labels == nil {
 			serverSecret.Labels = make(map[string]string)
 		}
 		serverSecret.Labels[k] = v
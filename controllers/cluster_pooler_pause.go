@@ -0,0 +1,171 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+)
+
+// ErrWaitingForPoolersPause is raised when a planned switchover is waiting
+// for the poolers of the cluster to be paused
+var ErrWaitingForPoolersPause = errors.New("waiting for the poolers to be paused before switching over")
+
+// abandonedSwitchoverPauseGracePeriod is how long, after its expiration,
+// a switchover pause which hasn't been followed by a switchover is kept
+// before being considered abandoned
+const abandonedSwitchoverPauseGracePeriod = time.Minute
+
+// getSwitchoverPausePoolers returns the poolers of the cluster
+// which are paused during the planned switchovers
+func (r *ClusterReconciler) getSwitchoverPausePoolers(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+) ([]apiv1.Pooler, error) {
+	var poolers apiv1.PoolerList
+	if err := r.List(ctx, &poolers,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingFields{poolerClusterKey: cluster.Name},
+	); err != nil {
+		return nil, fmt.Errorf("while getting poolers for cluster %s: %w", cluster.Name, err)
+	}
+
+	result := make([]apiv1.Pooler, 0, len(poolers.Items))
+	for _, pooler := range poolers.Items {
+		if pooler.IsSwitchoverPauseEnabled() {
+			result = append(result, pooler)
+		}
+	}
+	return result, nil
+}
+
+// pausePoolersBeforeSwitchover requests the pause of the poolers of the
+// cluster which are paused during the planned switchovers, returning
+// ErrWaitingForPoolersPause until every PgBouncer instance is paused or
+// the pause expires
+func (r *ClusterReconciler) pausePoolersBeforeSwitchover(ctx context.Context, cluster *apiv1.Cluster) error {
+	contextLogger := log.FromContext(ctx)
+
+	poolers, err := r.getSwitchoverPausePoolers(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	waiting := false
+	now := time.Now()
+	for i := range poolers {
+		pooler := &poolers[i]
+		_, deadline, requested := pooler.GetSwitchoverPauseRequest()
+		if !requested {
+			contextLogger.Info("Pausing the pooler before switching over", "pooler", pooler.Name)
+			if err := setSwitchoverPauseRequest(ctx, r.Client, pooler, now); err != nil {
+				return err
+			}
+			waiting = true
+			continue
+		}
+
+		if now.After(deadline) {
+			contextLogger.Warning("The pooler pause expired before every PgBouncer instance was paused, "+
+				"switching over anyway", "pooler", pooler.Name)
+			continue
+		}
+
+		if !pooler.IsSwitchoverPauseAcknowledged() {
+			waiting = true
+		}
+	}
+
+	if waiting {
+		return ErrWaitingForPoolersPause
+	}
+	return nil
+}
+
+// resumePoolersAfterSwitchover removes the switchover pause request from the
+// poolers of the cluster once the new primary has been promoted, or when
+// the switchover hasn't happened before the pause expired
+func (r *ClusterReconciler) resumePoolersAfterSwitchover(ctx context.Context, cluster *apiv1.Cluster) error {
+	if cluster.Status.TargetPrimary != cluster.Status.CurrentPrimary {
+		return nil
+	}
+
+	var poolers apiv1.PoolerList
+	if err := r.List(ctx, &poolers,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingFields{poolerClusterKey: cluster.Name},
+	); err != nil {
+		return fmt.Errorf("while getting poolers for cluster %s: %w", cluster.Name, err)
+	}
+
+	now := time.Now()
+	for i := range poolers.Items {
+		pooler := &poolers.Items[i]
+		requestedAt, deadline, requested := pooler.GetSwitchoverPauseRequest()
+		if !requested || !isSwitchoverPauseCompleted(cluster, requestedAt, deadline, now) {
+			continue
+		}
+
+		log.FromContext(ctx).Info("Resuming the pooler after the switchover", "pooler", pooler.Name)
+		original := pooler.DeepCopy()
+		delete(pooler.Annotations, utils.SwitchoverPauseAnnotationName)
+		if err := r.Patch(ctx, pooler, client.MergeFrom(original)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isSwitchoverPauseCompleted checks whether the primary has been changed
+// after the pause of a pooler has been requested, or whether the pause
+// has been abandoned without switching over
+func isSwitchoverPauseCompleted(
+	cluster *apiv1.Cluster,
+	requestedAt time.Time,
+	deadline time.Time,
+	now time.Time,
+) bool {
+	if now.After(deadline.Add(abandonedSwitchoverPauseGracePeriod)) {
+		return true
+	}
+
+	switchoverTime, err := time.Parse(metav1.RFC3339Micro, cluster.Status.TargetPrimaryTimestamp)
+	if err != nil {
+		return false
+	}
+	return !switchoverTime.Before(requestedAt)
+}
+
+// setSwitchoverPauseRequest requests the pause of a pooler for a switchover
+func setSwitchoverPauseRequest(ctx context.Context, c client.Client, pooler *apiv1.Pooler, now time.Time) error {
+	original := pooler.DeepCopy()
+	if pooler.Annotations == nil {
+		pooler.Annotations = make(map[string]string)
+	}
+	pooler.Annotations[utils.SwitchoverPauseAnnotationName] = now.Format(metav1.RFC3339Micro)
+	return c.Patch(ctx, pooler, client.MergeFrom(original))
+}
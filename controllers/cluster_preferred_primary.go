@@ -0,0 +1,71 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// preferredPrimaryReporter remembers, for every cluster, the last reported
+// reason why the current primary doesn't match the preferred one, so that
+// the mismatch is logged and reported as an event only when it changes
+// instead of at every reconciliation
+type preferredPrimaryReporter struct {
+	mu sync.Mutex
+
+	reported map[types.NamespacedName]string
+}
+
+// newPreferredPrimaryReporter creates an empty preferredPrimaryReporter
+func newPreferredPrimaryReporter() *preferredPrimaryReporter {
+	return &preferredPrimaryReporter{
+		reported: make(map[types.NamespacedName]string),
+	}
+}
+
+// shouldReport records the current mismatch state of the cluster, returning
+// true when it differs from the last reported one
+func (r *preferredPrimaryReporter) shouldReport(cluster types.NamespacedName, state string) bool {
+	if r == nil {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if previous, ok := r.reported[cluster]; ok && previous == state {
+		return false
+	}
+	r.reported[cluster] = state
+	return true
+}
+
+// reset forgets the mismatch state of the cluster, which happens when the
+// primary matches the preference, when there is no preference at all, or
+// when the cluster is deleted
+func (r *preferredPrimaryReporter) reset(cluster types.NamespacedName) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.reported, cluster)
+}
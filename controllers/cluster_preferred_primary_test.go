@@ -0,0 +1,82 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("preferred primary reporter", func() {
+	cluster := types.NamespacedName{Namespace: "default", Name: "cluster-example"}
+	other := types.NamespacedName{Namespace: "other", Name: "cluster-example"}
+
+	It("reports a mismatch only when its state changes", func() {
+		reporter := newPreferredPrimaryReporter()
+		Expect(reporter.shouldReport(cluster, "no-candidate")).To(BeTrue())
+		Expect(reporter.shouldReport(cluster, "no-candidate")).To(BeFalse())
+		Expect(reporter.shouldReport(cluster, "supervised/cluster-example-2")).To(BeTrue())
+		Expect(reporter.shouldReport(cluster, "supervised/cluster-example-2")).To(BeFalse())
+	})
+
+	It("tracks every cluster independently", func() {
+		reporter := newPreferredPrimaryReporter()
+		Expect(reporter.shouldReport(cluster, "no-candidate")).To(BeTrue())
+		Expect(reporter.shouldReport(other, "no-candidate")).To(BeTrue())
+	})
+
+	It("reports the mismatch again after it has been reset", func() {
+		reporter := newPreferredPrimaryReporter()
+		Expect(reporter.shouldReport(cluster, "no-candidate")).To(BeTrue())
+		reporter.reset(cluster)
+		Expect(reporter.shouldReport(cluster, "no-candidate")).To(BeTrue())
+	})
+
+	It("forgets the clusters which have been deleted", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &ClusterReconciler{
+			Client:                   fake.NewClientBuilder().WithScheme(scheme).Build(),
+			preferredPrimaryReporter: newPreferredPrimaryReporter(),
+		}
+		Expect(reconciler.preferredPrimaryReporter.shouldReport(cluster, "no-candidate")).To(BeTrue())
+
+		// The controller-runtime manager passes a logger to the reconciler
+		ctx := ctrl.LoggerInto(context.Background(), ctrl.Log)
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cluster})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reconciler.preferredPrimaryReporter.reported).ToNot(HaveKey(cluster))
+	})
+
+	It("always reports when it is not configured", func() {
+		var reporter *preferredPrimaryReporter
+		Expect(reporter.shouldReport(cluster, "no-candidate")).To(BeTrue())
+		Expect(reporter.shouldReport(cluster, "no-candidate")).To(BeTrue())
+		reporter.reset(cluster)
+	})
+})
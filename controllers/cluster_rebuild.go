@@ -0,0 +1,120 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/specs"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+)
+
+// reconcileInstanceRebuild destroys the data of the replica whose rebuild
+// has been requested via annotation, deleting its Pod and PVCs. The missing
+// instance is then recreated by the scale up logic, which clones the
+// current primary. A non-nil result means that the reconciliation loop
+// must stop here
+func (r *ClusterReconciler) reconcileInstanceRebuild(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	resources *managedResources,
+) (*ctrl.Result, error) {
+	contextLogger := log.FromContext(ctx)
+
+	pod := getInstanceToRebuild(resources.instances.Items)
+	if pod == nil {
+		return nil, nil
+	}
+
+	if pod.Name == cluster.Status.CurrentPrimary || pod.Name == cluster.Status.TargetPrimary {
+		contextLogger.Warning("Refusing to rebuild the primary instance", "pod", pod.Name)
+		r.Recorder.Eventf(cluster, "Warning", "RebuildRefused",
+			"Refusing to rebuild instance %v: it is the primary", pod.Name)
+		return nil, r.removeRebuildAnnotation(ctx, pod)
+	}
+
+	// Wait for the cluster to be stable before destroying an instance. The
+	// reconciliation goes on, as the cluster needs to be healed first, and
+	// the rebuild will be attempted again in the next loops
+	if resources.countRunningJobs() > 0 || !resources.allInstancesAreActive() ||
+		cluster.Status.Instances != cluster.Spec.Instances {
+		contextLogger.Info("Waiting for the cluster to be stable before rebuilding the instance",
+			"pod", pod.Name)
+		return nil, nil
+	}
+
+	r.Recorder.Eventf(cluster, "Normal", "RebuildingInstance",
+		"Rebuilding instance %v from the primary %v", pod.Name, cluster.Status.CurrentPrimary)
+	if err := r.RegisterPhase(ctx, cluster, apiv1.PhaseRebuildingReplica,
+		fmt.Sprintf("Rebuilding instance %s", pod.Name)); err != nil {
+		return nil, err
+	}
+
+	// The PVCs are deleted before the Pod: the PVC protection keeps them
+	// until the Pod is gone, and if one of these deletions fails the Pod
+	// still carries the rebuild request, so we'll try again. Deleting the
+	// Pod first would instead let the old PVCs be reattached to the new
+	// instance, silently skipping the rebuild
+	for _, pvc := range specs.FilterInstancePVCs(resources.pvcs.Items, pod.Spec) {
+		pvc := pvc
+		contextLogger.Info("Deleting the PVC of the instance to be rebuilt",
+			"pod", pod.Name, "pvc", pvc.Name)
+		if err := r.Delete(ctx, &pvc); err != nil && !apierrs.IsNotFound(err) {
+			return nil, fmt.Errorf("while deleting PVC %s to rebuild pod %s: %w", pvc.Name, pod.Name, err)
+		}
+	}
+
+	contextLogger.Info("Deleting the instance to be rebuilt", "pod", pod.Name)
+	if err := r.Delete(ctx, pod); err != nil && !apierrs.IsNotFound(err) {
+		return nil, fmt.Errorf("while deleting pod %s to rebuild it: %w", pod.Name, err)
+	}
+
+	return &ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+}
+
+// getInstanceToRebuild returns the first Pod, not already being deleted,
+// whose rebuild has been requested, or nil if there is none
+func getInstanceToRebuild(pods []corev1.Pod) *corev1.Pod {
+	for idx := range pods {
+		pod := &pods[idx]
+		if pod.DeletionTimestamp.IsZero() && utils.IsInstanceRebuildRequested(&pod.ObjectMeta) {
+			return pod
+		}
+	}
+
+	return nil
+}
+
+// removeRebuildAnnotation removes the rebuild request from the passed Pod
+func (r *ClusterReconciler) removeRebuildAnnotation(ctx context.Context, pod *corev1.Pod) error {
+	patch := client.MergeFrom(pod.DeepCopy())
+	delete(pod.Annotations, utils.RebuildInstanceAnnotationName)
+	if err := r.Patch(ctx, pod, patch); err != nil {
+		return fmt.Errorf("while removing the rebuild annotation from pod %s: %w", pod.Name, err)
+	}
+
+	return nil
+}
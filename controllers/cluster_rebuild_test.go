@@ -0,0 +1,186 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("instance rebuild", func() {
+	newPod := func(name string, annotations map[string]string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+
+	It("returns nil when no rebuild has been requested", func() {
+		pods := []corev1.Pod{
+			newPod("cluster-1", nil),
+			newPod("cluster-2", map[string]string{utils.RebuildInstanceAnnotationName: "disabled"}),
+		}
+		Expect(getInstanceToRebuild(pods)).To(BeNil())
+	})
+
+	It("returns the pod whose rebuild has been requested", func() {
+		pods := []corev1.Pod{
+			newPod("cluster-1", nil),
+			newPod("cluster-2", map[string]string{utils.RebuildInstanceAnnotationName: "enabled"}),
+		}
+		Expect(getInstanceToRebuild(pods).Name).To(Equal("cluster-2"))
+	})
+
+	It("ignores the pods being deleted", func() {
+		now := metav1.Now()
+		pod := newPod("cluster-2", map[string]string{utils.RebuildInstanceAnnotationName: "enabled"})
+		pod.DeletionTimestamp = &now
+		Expect(getInstanceToRebuild([]corev1.Pod{pod})).To(BeNil())
+	})
+})
+
+// failingDeleteClient is a client failing the deletion of the
+// objects whose name is in failingNames
+type failingDeleteClient struct {
+	client.Client
+	failingNames map[string]bool
+}
+
+func (c *failingDeleteClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if c.failingNames[obj.GetName()] {
+		return errors.New("deletion failed")
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+var _ = Describe("instance rebuild reconciliation", func() {
+	const namespace = "default"
+
+	var (
+		cluster    *apiv1.Cluster
+		pod        *corev1.Pod
+		pvcs       []corev1.PersistentVolumeClaim
+		fakeClient *failingDeleteClient
+		reconciler *ClusterReconciler
+	)
+
+	BeforeEach(func() {
+		cluster = &apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: namespace},
+			Spec:       apiv1.ClusterSpec{Instances: 2},
+			Status: apiv1.ClusterStatus{
+				Instances:      2,
+				CurrentPrimary: "cluster-1",
+				TargetPrimary:  "cluster-1",
+			},
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cluster-2",
+				Namespace:   namespace,
+				Annotations: map[string]string{utils.RebuildInstanceAnnotationName: "enabled"},
+			},
+		}
+		pvcs = nil
+		for _, name := range []string{"cluster-2", "cluster-2-wal"} {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+				Name: name,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+				},
+			})
+			pvcs = append(pvcs, corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			})
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = &failingDeleteClient{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(cluster, pod, &pvcs[0], &pvcs[1]).
+				Build(),
+			failingNames: map[string]bool{"cluster-2-wal": true},
+		}
+		reconciler = &ClusterReconciler{
+			Client:   fakeClient,
+			Recorder: record.NewFakeRecorder(10),
+		}
+	})
+
+	rebuild := func() (*ctrl.Result, error) {
+		return reconciler.reconcileInstanceRebuild(context.Background(), cluster, &managedResources{
+			instances: corev1.PodList{Items: []corev1.Pod{*pod}},
+			pvcs:      corev1.PersistentVolumeClaimList{Items: pvcs},
+		})
+	}
+
+	It("doesn't stop the reconciliation when the primary fails while a rebuild is pending", func() {
+		primary := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-1", Namespace: namespace},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+		result, err := reconciler.reconcileInstanceRebuild(context.Background(), cluster, &managedResources{
+			instances: corev1.PodList{Items: []corev1.Pod{primary, *pod}},
+			pvcs:      corev1.PersistentVolumeClaimList{Items: pvcs},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(BeNil())
+
+		var existingPod corev1.Pod
+		Expect(fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &existingPod)).To(Succeed())
+		Expect(utils.IsInstanceRebuildRequested(&existingPod.ObjectMeta)).To(BeTrue())
+	})
+
+	It("keeps the pod while its PVCs can't be deleted", func() {
+		_, err := rebuild()
+		Expect(err).To(HaveOccurred())
+
+		var existingPod corev1.Pod
+		Expect(fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &existingPod)).To(Succeed())
+		Expect(utils.IsInstanceRebuildRequested(&existingPod.ObjectMeta)).To(BeTrue())
+
+		By("retrying once the PVCs can be deleted")
+		fakeClient.failingNames = nil
+		result, err := rebuild()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).ToNot(BeNil())
+
+		err = fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &existingPod)
+		Expect(apierrs.IsNotFound(err)).To(BeTrue())
+		for idx := range pvcs {
+			var pvc corev1.PersistentVolumeClaim
+			err = fakeClient.Get(context.Background(), client.ObjectKeyFromObject(&pvcs[idx]), &pvc)
+			Expect(apierrs.IsNotFound(err)).To(BeTrue())
+		}
+	})
+})
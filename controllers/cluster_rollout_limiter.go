@@ -0,0 +1,177 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	rolloutsInProgress = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "cnpg",
+		Subsystem: "operator",
+		Name:      "rollouts_in_progress",
+		Help:      "Number of clusters whose instances are being rolled out",
+	})
+
+	rolloutsWaiting = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "cnpg",
+		Subsystem: "operator",
+		Name:      "rollouts_waiting",
+		Help:      "Number of clusters waiting for the limit of concurrent rollouts to start their rollout",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(rolloutsInProgress, rolloutsWaiting)
+}
+
+// rolloutStaleTimeout is the time after which a cluster that didn't ask
+// again for its slot, or for its position in the queue, is forgotten. This
+// prevents a cluster that stopped being reconciled, i.e. because its
+// reconciliation has been disabled, from blocking the other rollouts
+const rolloutStaleTimeout = 10 * time.Minute
+
+// rolloutLimiter limits the number of clusters rolling out their instances
+// at the same time, so that the rollouts triggered together, like the ones
+// following an upgrade of the operator, are queued instead of overwhelming
+// the API server and the nodes
+type rolloutLimiter struct {
+	mu sync.Mutex
+
+	// limit is the maximum number of concurrent rollouts, zero means unlimited
+	limit int
+
+	inProgress map[types.NamespacedName]struct{}
+
+	// waiting is the queue of the clusters waiting for a slot, which are
+	// served in the same order in which they asked for it
+	waiting []types.NamespacedName
+
+	// lastSeen is the last time every cluster holding a slot, or waiting
+	// for one, asked for it
+	lastSeen map[types.NamespacedName]time.Time
+
+	// now returns the current time
+	now func() time.Time
+}
+
+// newRolloutLimiter creates a limiter allowing the given number of
+// concurrent rollouts, zero meaning unlimited
+func newRolloutLimiter(limit int) *rolloutLimiter {
+	return &rolloutLimiter{
+		limit:      limit,
+		inProgress: make(map[types.NamespacedName]struct{}),
+		lastSeen:   make(map[types.NamespacedName]time.Time),
+		now:        time.Now,
+	}
+}
+
+// tryAcquire checks whether the cluster can roll out its instances, reserving
+// a slot for it until the rollout is released. A cluster that already holds
+// a slot can always proceed, while the others are queued, and get the free
+// slots in the order in which they asked for them
+func (l *rolloutLimiter) tryAcquire(cluster types.NamespacedName) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer l.updateMetrics()
+
+	now := l.now()
+	l.expireStaleEntries(now)
+	l.lastSeen[cluster] = now
+
+	if _, found := l.inProgress[cluster]; found {
+		return true
+	}
+
+	position := l.waitingPosition(cluster)
+	if position == -1 {
+		l.waiting = append(l.waiting, cluster)
+		position = len(l.waiting) - 1
+	}
+
+	if l.limit > 0 && position >= l.limit-len(l.inProgress) {
+		return false
+	}
+
+	l.waiting = append(l.waiting[:position], l.waiting[position+1:]...)
+	l.inProgress[cluster] = struct{}{}
+	return true
+}
+
+// release frees the slot held by the cluster, if any, and removes it from
+// the queue, as its rollout is completed or it is waiting for something
+// else, like the approval of the user or the maintenance window
+func (l *rolloutLimiter) release(cluster types.NamespacedName) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer l.updateMetrics()
+
+	l.forget(cluster)
+}
+
+// forget removes the cluster from the slots and from the queue. It must
+// be called holding the lock
+func (l *rolloutLimiter) forget(cluster types.NamespacedName) {
+	delete(l.inProgress, cluster)
+	delete(l.lastSeen, cluster)
+	if position := l.waitingPosition(cluster); position != -1 {
+		l.waiting = append(l.waiting[:position], l.waiting[position+1:]...)
+	}
+}
+
+// expireStaleEntries forgets the clusters that didn't ask for their slot, or
+// for their position in the queue, for longer than rolloutStaleTimeout.
+// It must be called holding the lock
+func (l *rolloutLimiter) expireStaleEntries(now time.Time) {
+	for cluster, lastSeen := range l.lastSeen {
+		if now.Sub(lastSeen) > rolloutStaleTimeout {
+			l.forget(cluster)
+		}
+	}
+}
+
+// waitingPosition returns the position of the cluster in the queue, or -1
+// if the cluster is not waiting for a slot. It must be called holding the lock
+func (l *rolloutLimiter) waitingPosition(cluster types.NamespacedName) int {
+	for idx := range l.waiting {
+		if l.waiting[idx] == cluster {
+			return idx
+		}
+	}
+	return -1
+}
+
+// updateMetrics exports the number of rollouts in progress and waiting.
+// It must be called holding the lock
+func (l *rolloutLimiter) updateMetrics() {
+	rolloutsInProgress.Set(float64(len(l.inProgress)))
+	rolloutsWaiting.Set(float64(len(l.waiting)))
+}
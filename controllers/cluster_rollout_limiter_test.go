@@ -0,0 +1,142 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("rollout limiter", func() {
+	first := types.NamespacedName{Namespace: "default", Name: "first"}
+	second := types.NamespacedName{Namespace: "default", Name: "second"}
+	third := types.NamespacedName{Namespace: "other", Name: "first"}
+
+	It("queues the rollouts exceeding the limit", func() {
+		limiter := newRolloutLimiter(2)
+		Expect(limiter.tryAcquire(first)).To(BeTrue())
+		Expect(limiter.tryAcquire(second)).To(BeTrue())
+		Expect(limiter.tryAcquire(third)).To(BeFalse())
+		Expect(limiter.waiting).To(ConsistOf(third))
+
+		By("letting the clusters holding a slot proceed", func() {
+			Expect(limiter.tryAcquire(first)).To(BeTrue())
+		})
+
+		By("starting a queued rollout when a slot is released", func() {
+			limiter.release(first)
+			Expect(limiter.tryAcquire(third)).To(BeTrue())
+			Expect(limiter.inProgress).To(HaveLen(2))
+			Expect(limiter.waiting).To(BeEmpty())
+		})
+	})
+
+	It("serves the queued rollouts in order", func() {
+		limiter := newRolloutLimiter(1)
+		Expect(limiter.tryAcquire(first)).To(BeTrue())
+		Expect(limiter.tryAcquire(second)).To(BeFalse())
+		Expect(limiter.tryAcquire(third)).To(BeFalse())
+		Expect(limiter.waiting).To(Equal([]types.NamespacedName{second, third}))
+
+		limiter.release(first)
+		Expect(limiter.tryAcquire(third)).To(BeFalse())
+		Expect(limiter.tryAcquire(second)).To(BeTrue())
+		Expect(limiter.waiting).To(Equal([]types.NamespacedName{third}))
+	})
+
+	It("gives the slot to the next cluster when the current one waits for something else", func() {
+		limiter := newRolloutLimiter(1)
+		Expect(limiter.tryAcquire(first)).To(BeTrue())
+		Expect(limiter.tryAcquire(second)).To(BeFalse())
+
+		// The first cluster is waiting for the approval of the user
+		limiter.release(first)
+		Expect(limiter.tryAcquire(second)).To(BeTrue())
+		Expect(limiter.tryAcquire(first)).To(BeFalse())
+		Expect(limiter.waiting).To(Equal([]types.NamespacedName{first}))
+
+		// A queued cluster not needing a rollout anymore leaves the queue
+		limiter.release(first)
+		Expect(limiter.waiting).To(BeEmpty())
+	})
+
+	It("doesn't limit the rollouts when the limit is zero", func() {
+		limiter := newRolloutLimiter(0)
+		Expect(limiter.tryAcquire(first)).To(BeTrue())
+		Expect(limiter.tryAcquire(second)).To(BeTrue())
+		Expect(limiter.tryAcquire(third)).To(BeTrue())
+		Expect(limiter.inProgress).To(HaveLen(3))
+	})
+
+	It("forgets the clusters which stopped asking for their slot", func() {
+		now := time.Now()
+		limiter := newRolloutLimiter(1)
+		limiter.now = func() time.Time { return now }
+		Expect(limiter.tryAcquire(first)).To(BeTrue())
+		Expect(limiter.tryAcquire(second)).To(BeFalse())
+		Expect(limiter.tryAcquire(third)).To(BeFalse())
+
+		// The first cluster and the head of the queue are not reconciled anymore
+		now = now.Add(rolloutStaleTimeout / 2)
+		Expect(limiter.tryAcquire(third)).To(BeFalse())
+		now = now.Add(rolloutStaleTimeout/2 + time.Second)
+		Expect(limiter.tryAcquire(third)).To(BeTrue())
+		Expect(limiter.inProgress).To(HaveLen(1))
+		Expect(limiter.waiting).To(BeEmpty())
+	})
+
+	It("releases the slot of a cluster whose reconciliation has been disabled", func() {
+		cluster := &apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        first.Name,
+				Namespace:   first.Namespace,
+				Annotations: map[string]string{utils.ReconciliationLoopAnnotationName: utils.ReconciliationDisabledValue},
+			},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &ClusterReconciler{
+			Client:         fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build(),
+			rolloutLimiter: newRolloutLimiter(1),
+		}
+		Expect(reconciler.rolloutLimiter.tryAcquire(first)).To(BeTrue())
+
+		_, err := reconciler.reconcile(ctrl.LoggerInto(context.Background(), ctrl.Log), cluster)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reconciler.rolloutLimiter.tryAcquire(second)).To(BeTrue())
+	})
+
+	It("is disabled when not configured", func() {
+		var limiter *rolloutLimiter
+		Expect(limiter.tryAcquire(first)).To(BeTrue())
+		limiter.release(first)
+	})
+})
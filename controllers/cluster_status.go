@@ -334,6 +334,7 @@ func (r *ClusterReconciler) updateResourceStatus(
 	cluster.Status.Certificates.ClientCASecret = cluster.GetClientCASecretName()
 	cluster.Status.Certificates.ReplicationTLSSecret = cluster.GetReplicationSecretName()
 	cluster.Status.Certificates.ServerAltDNSNames = cluster.GetClusterAltDNSNames()
+	cluster.Status.Certificates.ClientCertificateUsers = cluster.GetClientCertificateUsers()
 
 	// Set the version of the operator inside the status. This will allow us
 	// to discover the exact version of the operator which worked the last time
@@ -417,6 +418,19 @@ func (r *ClusterReconciler) updateOnlineUpdateEnabled(
 	return r.Status().Update(ctx, cluster)
 }
 
+// updatePendingMaintenanceOperations updates the list of the operations
+// deferred until the maintenance window in the cluster status
+func (r *ClusterReconciler) updatePendingMaintenanceOperations(
+	ctx context.Context, cluster *apiv1.Cluster, pendingOperations []string,
+) error {
+	if reflect.DeepEqual(cluster.Status.PendingMaintenanceOperations, pendingOperations) {
+		return nil
+	}
+
+	cluster.Status.PendingMaintenanceOperations = pendingOperations
+	return r.Status().Update(ctx, cluster)
+}
+
 // SetClusterOwnerAnnotationsAndLabels sets the cluster as owner of the passed object and then
 // sets all the needed annotations and labels
 func SetClusterOwnerAnnotationsAndLabels(obj *metav1.ObjectMeta, cluster *apiv1.Cluster) {
@@ -453,6 +467,12 @@ func (r *ClusterReconciler) getPoolerIntegrationsNeeded(ctx context.Context,
 			cluster.Name, err)
 	}
 
+	// the readiness of the auth_query is reported by the primary, and
+	// updated together with the status of the instances
+	if cluster.Status.PoolerIntegrations != nil && len(pgbouncerPoolerIntegrations.Secrets) > 0 {
+		pgbouncerPoolerIntegrations.AuthQueryReady = cluster.Status.PoolerIntegrations.PgBouncerIntegration.AuthQueryReady
+	}
+
 	return &apiv1.PoolerIntegrations{
 		PgBouncerIntegration: pgbouncerPoolerIntegrations,
 	}, nil
@@ -487,11 +507,7 @@ func (r *ClusterReconciler) getPgbouncerIntegrationStatus(
 		// We skip secrets which were directly setup by the user with
 		// the authQuery and authQuerySecret parameters inside the
 		// pooler
-		if pooler.Spec.PgBouncer.AuthQuery != "" {
-			continue
-		}
-
-		if pooler.Spec.PgBouncer.AuthQuerySecret != nil && pooler.Spec.PgBouncer.AuthQuerySecret.Name != "" {
+		if !pooler.IsAuthQueryManaged() {
 			continue
 		}
 
@@ -555,6 +571,14 @@ func (r *ClusterReconciler) refreshCertsExpirations(ctx context.Context, cluster
 		return err
 	}
 
+	for _, user := range certificates.ClientCertificateUsers {
+		err = r.setCertExpiration(ctx, cluster, cluster.GetClientCertificateSecretName(user), namespace,
+			certs.TLSCertKey)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -764,6 +788,7 @@ func (r *ClusterReconciler) RegisterPhase(ctx context.Context,
 	}
 
 	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	meta.SetStatusCondition(&cluster.Status.Conditions, apiv1.GetWaitingForUserCondition(phase, reason))
 
 	if !reflect.DeepEqual(existingClusterStatus, cluster.Status) {
 		if err := r.Status().Update(ctx, cluster); err != nil {
@@ -780,14 +805,18 @@ func (r *ClusterReconciler) updateClusterStatusThatRequiresInstancesState(
 	cluster *apiv1.Cluster,
 	statuses postgres.PostgresqlStatusList,
 ) error {
-	existingClusterStatus := cluster.Status
+	// the conditions are updated in place, so we need a deep copy
+	// to detect their changes
+	existingClusterStatus := *cluster.Status.DeepCopy()
 	cluster.Status.InstancesReportedState = make(map[apiv1.PodName]apiv1.InstanceReportedState, len(statuses.Items))
 
 	// we extract the instances reported state
 	for _, item := range statuses.Items {
 		cluster.Status.InstancesReportedState[apiv1.PodName(item.Pod.Name)] = apiv1.InstanceReportedState{
-			IsPrimary:  item.IsPrimary,
-			TimeLineID: item.TimeLineID,
+			IsPrimary:              item.IsPrimary,
+			TimeLineID:             item.TimeLineID,
+			PendingRestartSettings: item.PendingRestartSettings,
+			ReadyWALFiles:          item.ReadyWALFiles,
 		}
 	}
 
@@ -798,6 +827,49 @@ func (r *ClusterReconciler) updateClusterStatusThatRequiresInstancesState(
 		if item.IsPrimary && item.TimeLineID != 0 {
 			cluster.Status.TimelineID = item.TimeLineID
 		}
+
+		// the WAL segment size can't change after the bootstrap,
+		// so we keep the last value reported by the primary
+		if item.IsPrimary && item.WALSegmentSize != 0 {
+			cluster.Status.WALSegmentSize = int(item.WALSegmentSize / (1024 * 1024))
+		}
+
+		// the status of the WAL archiving is reported by the primary,
+		// reading pg_stat_archiver and the result of the wal-archive
+		// command for the additional WAL archives
+		if item.IsPrimary && item.Error == nil {
+			cluster.SetWALArchiveDestinationsStatus(item.WALArchiveDestinations)
+			cluster.Status.SetLastArchivedWAL(item.LastArchivedWAL, item.LastArchivedWALTime, item.WALSegmentSize)
+		}
+
+		// the same applies to the data checksums, which are not reported
+		// by the instance managers of the previous versions
+		if item.IsPrimary && item.Error == nil {
+			if item.DataChecksums != nil {
+				dataChecksums := *item.DataChecksums
+				cluster.Status.DataChecksums = &dataChecksums
+			}
+			cluster.Status.Encoding = item.Encoding
+			cluster.Status.LocaleCollate = item.LocaleCollate
+			cluster.Status.LocaleCType = item.LocaleCType
+		}
+
+		// the user and the function used by the auth_query of PgBouncer
+		// are created by the primary, which reports when they are in place
+		if item.IsPrimary && item.Error == nil && cluster.Status.PoolerIntegrations != nil {
+			cluster.Status.PoolerIntegrations.PgBouncerIntegration.AuthQueryReady = item.PgBouncerIntegrationReady
+		}
+	}
+
+	// we report the health of the HA replication slots only when they
+	// are managed by the operator
+	if cluster.IsReplicationSlotsHAEnabled() {
+		meta.SetStatusCondition(
+			&cluster.Status.Conditions,
+			apiv1.GetReplicationSlotsCondition(statuses, cluster.Status.CurrentPrimary),
+		)
+	} else {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, string(apiv1.ConditionReplicationSlotsHealthy))
 	}
 
 	if !reflect.DeepEqual(existingClusterStatus, cluster.Status) {
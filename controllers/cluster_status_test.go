@@ -21,12 +21,18 @@ import (
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	v1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/certs"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -162,3 +168,81 @@ var _ = Describe("cluster_status unit tests", func() {
 		})
 	})
 })
+
+var _ = Describe("data checksums status", func() {
+	var (
+		cluster    *v1.Cluster
+		reconciler *ClusterReconciler
+	)
+
+	primaryStatus := func(dataChecksums *bool) postgres.PostgresqlStatusList {
+		return postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				{
+					Pod:           corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-1"}},
+					IsPrimary:     true,
+					DataChecksums: dataChecksums,
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		cluster = &v1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-example", Namespace: "default"},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &ClusterReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build(),
+		}
+	})
+
+	It("reports the data checksums of the primary", func() {
+		Expect(reconciler.updateClusterStatusThatRequiresInstancesState(
+			context.Background(), cluster, primaryStatus(pointer.Bool(false)))).To(Succeed())
+		Expect(cluster.Status.DataChecksums).To(Equal(pointer.Bool(false)))
+	})
+
+	It("keeps the last known value when the instance manager doesn't report it", func() {
+		cluster.Status.DataChecksums = pointer.Bool(true)
+		Expect(reconciler.updateClusterStatusThatRequiresInstancesState(
+			context.Background(), cluster, primaryStatus(nil))).To(Succeed())
+		Expect(cluster.Status.DataChecksums).To(Equal(pointer.Bool(true)))
+	})
+})
+
+var _ = Describe("pgbouncer auth query status", func() {
+	It("reports whether the primary created the auth query function", func() {
+		cluster := &v1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-example", Namespace: "default"},
+			Status: v1.ClusterStatus{
+				PoolerIntegrations: &v1.PoolerIntegrations{
+					PgBouncerIntegration: v1.PgBouncerIntegrationStatus{
+						Secrets: []string{"cluster-example-pooler"},
+					},
+				},
+			},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &ClusterReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build(),
+		}
+
+		statuses := postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				{
+					Pod:                       corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-1"}},
+					IsPrimary:                 true,
+					PgBouncerIntegrationReady: true,
+				},
+			},
+		}
+		Expect(reconciler.updateClusterStatusThatRequiresInstancesState(
+			context.Background(), cluster, statuses)).To(Succeed())
+		Expect(cluster.Status.PoolerIntegrations.PgBouncerIntegration.AuthQueryReady).To(BeTrue())
+	})
+})
@@ -23,9 +23,12 @@ import (
 	"io"
 	"net/http"
 	neturl "net/url"
+	"reflect"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/strings/slices"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
@@ -38,6 +41,136 @@ import (
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
+// deferRolloutToMaintenanceWindow checks whether the rollouts required by
+// the instances need to wait for the maintenance window of the cluster,
+// reporting them in the status. It returns how long to wait for the window
+// to open, or zero if the rollouts can be performed now
+func (r *ClusterReconciler) deferRolloutToMaintenanceWindow(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	podList *postgres.PostgresqlStatusList,
+) (time.Duration, error) {
+	now := time.Now()
+	inMaintenanceWindow, err := cluster.IsInMaintenanceWindow(now)
+	if err != nil {
+		return 0, err
+	}
+
+	var pendingOperations []string
+	if !inMaintenanceWindow {
+		pendingOperations = getPendingRolloutOperations(cluster, podList)
+	}
+
+	if len(pendingOperations) == 0 {
+		return 0, r.updatePendingMaintenanceOperations(ctx, cluster, nil)
+	}
+
+	nextStart, err := cluster.Spec.MaintenanceWindow.GetNextStart(now)
+	if err != nil {
+		return 0, err
+	}
+
+	if !reflect.DeepEqual(cluster.Status.PendingMaintenanceOperations, pendingOperations) {
+		log.FromContext(ctx).Info("Deferring disruptive operations until the maintenance window",
+			"pendingOperations", pendingOperations,
+			"nextStart", nextStart)
+		r.Recorder.Eventf(cluster, "Normal", "MaintenanceDeferred",
+			"Deferring %d disruptive operations until %s",
+			len(pendingOperations), nextStart.Format(time.RFC3339))
+	}
+
+	if err := r.updatePendingMaintenanceOperations(ctx, cluster, pendingOperations); err != nil {
+		return 0, err
+	}
+
+	if err := r.RegisterPhase(ctx, cluster, apiv1.PhaseWaitingForMaintenanceWindow,
+		fmt.Sprintf("Disruptive operations deferred until %s", nextStart.Format(time.RFC3339)),
+	); err != nil {
+		return 0, err
+	}
+
+	return nextStart.Sub(now), nil
+}
+
+// rolloutSlotCheckInterval is how often a cluster whose rollout is
+// queued checks whether a rollout slot became available
+const rolloutSlotCheckInterval = 30 * time.Second
+
+// waitForRolloutSlot checks whether the rollouts required by the instances
+// need to be queued, as the operator is already rolling out the maximum
+// number of clusters. The slot held by the cluster is released as soon as
+// its instances don't require any rollout
+func (r *ClusterReconciler) waitForRolloutSlot(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	podList *postgres.PostgresqlStatusList,
+) (bool, error) {
+	key := client.ObjectKeyFromObject(cluster)
+	if len(getPendingRolloutOperations(cluster, podList)) == 0 {
+		r.rolloutLimiter.release(key)
+		return false, nil
+	}
+
+	if r.rolloutLimiter.tryAcquire(key) {
+		return false, nil
+	}
+
+	if cluster.Status.Phase != apiv1.PhaseWaitingForRolloutSlot {
+		log.FromContext(ctx).Info("Queueing the rollout, as the maximum number of concurrent rollouts is reached",
+			"maxConcurrentRollouts", configuration.Current.MaxConcurrentRollouts)
+		r.Recorder.Eventf(cluster, "Normal", "RolloutQueued",
+			"Queueing the rollout, as %d clusters are already being rolled out",
+			configuration.Current.MaxConcurrentRollouts)
+	}
+
+	return true, r.RegisterPhase(ctx, cluster, apiv1.PhaseWaitingForRolloutSlot,
+		"The maximum number of concurrent rollouts is reached")
+}
+
+// getMissingSharedPreloadLibraries returns the shared preload libraries which
+// can't be found by the instances. Instances that are going to be upgraded
+// to a new image are not considered, as the libraries may be found there
+func getMissingSharedPreloadLibraries(cluster *apiv1.Cluster, podList *postgres.PostgresqlStatusList) []string {
+	var result []string
+	for _, postgresqlStatus := range podList.Items {
+		if len(postgresqlStatus.MissingSharedPreloadLibraries) == 0 {
+			continue
+		}
+
+		_, newImage, err := isPodNeedingUpgradedImage(cluster, postgresqlStatus.Pod)
+		if err != nil || newImage != "" {
+			continue
+		}
+
+		for _, library := range postgresqlStatus.MissingSharedPreloadLibraries {
+			if !slices.Contains(result, library) {
+				result = append(result, library)
+			}
+		}
+	}
+
+	return result
+}
+
+// getPendingRolloutOperations returns a description of the
+// rollouts required by the instances of the cluster
+func getPendingRolloutOperations(cluster *apiv1.Cluster, podList *postgres.PostgresqlStatusList) []string {
+	var result []string
+	for _, postgresqlStatus := range podList.Items {
+		shouldRestart, _, reason := IsPodNeedingRollout(postgresqlStatus, cluster)
+		if !shouldRestart {
+			continue
+		}
+
+		if reason == "" {
+			reason = "the instance manager needs to be upgraded"
+		}
+		result = append(result, fmt.Sprintf("%s: %s", postgresqlStatus.Pod.Name, reason))
+	}
+
+	return result
+}
+
 func (r *ClusterReconciler) rolloutDueToCondition(
 	ctx context.Context,
 	cluster *apiv1.Cluster,
@@ -103,6 +236,70 @@ func (r *ClusterReconciler) rolloutDueToCondition(
 	return r.updatePrimaryPod(ctx, cluster, podList, primaryPostgresqlStatus.Pod, inPlacePossible, reason)
 }
 
+// consumePrimaryUpdateApproval checks whether the user approved the update
+// of the primary instance through the approval annotation, which is removed
+// as it is used for a single update. An approval not bound to the pending
+// update is discarded. An in-place restart of the primary in progress has
+// already been approved
+func (r *ClusterReconciler) consumePrimaryUpdateApproval(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	primaryPodName string,
+	reason string,
+) (bool, error) {
+	if cluster.Status.Phase == apiv1.PhaseInplacePrimaryRestart {
+		return true, nil
+	}
+
+	requested, approvalErr := getPrimaryUpdateApproval(cluster, primaryPodName)
+	if !requested {
+		return false, nil
+	}
+
+	oldCluster := cluster.DeepCopy()
+	delete(cluster.Annotations, utils.ApprovePrimaryUpdateAnnotationName)
+	if err := r.Patch(ctx, cluster, client.MergeFrom(oldCluster)); err != nil {
+		return false, err
+	}
+
+	if approvalErr != nil {
+		log.FromContext(ctx).Info("Discarding the approval of the primary update",
+			"reason", approvalErr.Error())
+		r.Recorder.Eventf(cluster, "Warning", "PrimaryUpdateApprovalDiscarded",
+			"Discarding the approval of the primary update: %v", approvalErr)
+		return false, nil
+	}
+
+	log.FromContext(ctx).Info("The user approved the update of the primary instance", "reason", reason)
+	r.Recorder.Eventf(cluster, "Normal", "PrimaryUpdateApproved",
+		"The user approved the update of the primary instance (%v)", reason)
+	return true, nil
+}
+
+// getPrimaryUpdateApproval returns true if the cluster has been annotated
+// to approve the update of the primary instance, and an error if the
+// approval doesn't refer to the update of the passed primary the cluster
+// is waiting for. An approval set before the cluster started waiting for
+// the user can't have been given for this update, so it's not valid
+func getPrimaryUpdateApproval(cluster *apiv1.Cluster, primaryPodName string) (bool, error) {
+	value, ok := cluster.Annotations[utils.ApprovePrimaryUpdateAnnotationName]
+	if !ok {
+		return false, nil
+	}
+
+	if cluster.Status.Phase != apiv1.PhaseWaitingForUser {
+		return true, fmt.Errorf("it was set before the update of the primary %v was pending",
+			primaryPodName)
+	}
+
+	if value != primaryPodName {
+		return true, fmt.Errorf("it refers to %q while the primary waiting for the update is %v",
+			value, primaryPodName)
+	}
+
+	return true, nil
+}
+
 func (r *ClusterReconciler) updatePrimaryPod(
 	ctx context.Context,
 	cluster *apiv1.Cluster,
@@ -116,14 +313,30 @@ func (r *ClusterReconciler) updatePrimaryPod(
 	// we need to check whether a manual switchover is required
 	contextLogger = contextLogger.WithValues("primaryPod", primaryPod.Name)
 	if cluster.GetPrimaryUpdateStrategy() == apiv1.PrimaryUpdateStrategySupervised {
-		contextLogger.Info("Waiting for the user to request a switchover to complete the rolling update",
-			"reason", reason)
-		err := r.RegisterPhase(ctx, cluster, apiv1.PhaseWaitingForUser, "User must issue a supervised switchover")
+		approved, err := r.consumePrimaryUpdateApproval(ctx, cluster, primaryPod.Name, reason)
 		if err != nil {
 			return false, err
 		}
+		if !approved {
+			contextLogger.Info("Waiting for the user to request a switchover to complete the rolling update",
+				"reason", reason)
+			// The user may take a long time to approve the update, and
+			// the other clusters shouldn't wait for it
+			r.rolloutLimiter.release(client.ObjectKeyFromObject(cluster))
+			if cluster.Status.Phase != apiv1.PhaseWaitingForUser {
+				r.Recorder.Eventf(cluster, "Normal", "WaitingForPrimaryUpdateApproval",
+					"The primary instance %v needs to be updated (%v): waiting for the user to approve it "+
+						"with the %v=%v annotation",
+					primaryPod.Name, reason, utils.ApprovePrimaryUpdateAnnotationName, primaryPod.Name)
+			}
+			err := r.RegisterPhase(ctx, cluster, apiv1.PhaseWaitingForUser,
+				"User must issue a supervised switchover or approve the primary update")
+			if err != nil {
+				return false, err
+			}
 
-		return true, nil
+			return true, nil
+		}
 	}
 
 	if cluster.GetPrimaryUpdateMethod() == apiv1.PrimaryUpdateMethodRestart {
@@ -163,6 +376,10 @@ func (r *ClusterReconciler) updatePrimaryPod(
 			targetPrimary = podList.Items[0].Pod.Name
 		}
 
+		if err := r.pausePoolersBeforeSwitchover(ctx, cluster); err != nil {
+			return false, err
+		}
+
 		contextLogger.Info("The primary needs to be restarted, we'll trigger a switchover to do that",
 			"reason", reason,
 			"currentPrimary", primaryPod.Name,
@@ -190,14 +407,13 @@ func (r *ClusterReconciler) updateRestartAnnotation(
 	primaryPod v1.Pod,
 ) error {
 	contextLogger := log.FromContext(ctx)
-	if clusterRestart, ok := cluster.Annotations[specs.ClusterRestartAnnotationName]; ok &&
-		(primaryPod.Annotations == nil || primaryPod.Annotations[specs.ClusterRestartAnnotationName] != clusterRestart) {
+	if isClusterRestartRequested(cluster, primaryPod) {
 		contextLogger.Info("Setting restart annotation on primary pod as needed", "label", specs.ClusterReloadAnnotationName)
 		original := primaryPod.DeepCopy()
 		if primaryPod.Annotations == nil {
 			primaryPod.Annotations = make(map[string]string)
 		}
-		primaryPod.Annotations[specs.ClusterRestartAnnotationName] = clusterRestart
+		primaryPod.Annotations[specs.ClusterRestartAnnotationName] = cluster.Annotations[specs.ClusterRestartAnnotationName]
 		if err := r.Client.Patch(ctx, &primaryPod, client.MergeFrom(original)); err != nil {
 			return err
 		}
@@ -283,13 +499,36 @@ func IsPodNeedingRollout(status postgres.PostgresqlStatus, cluster *apiv1.Cluste
 				cluster.Spec.Resources,
 				container.Resources)
 		}
+
+		// The instance manager configures the metrics endpoint only
+		// when it starts, so the Pod needs to be recreated
+		if isMetricsTLSEnabledInContainer(container) != cluster.IsMetricsTLSEnabled() {
+			return true, false, "the TLS configuration of the metrics endpoint changed"
+		}
+	}
+
+	// check if the user explicitly requested a rolling restart of the cluster
+	if isClusterRestartRequested(cluster, status.Pod) {
+		return true, true, "a restart of the cluster has been requested"
 	}
 
 	// check if pod needs to be restarted because of some config requiring it
-	return isPodNeedingRestart(cluster, status),
+	return status.PendingRestart,
 		true, "configuration needs a restart to apply some configuration changes"
 }
 
+// isMetricsTLSEnabledInContainer checks whether the instance manager
+// running in the passed container is serving the metrics over TLS
+func isMetricsTLSEnabledInContainer(container v1.Container) bool {
+	for _, envVar := range container.Env {
+		if envVar.Name == specs.MetricsTLSEnvVar {
+			return envVar.Value == "true"
+		}
+	}
+
+	return false
+}
+
 // isPodNeedingUpgradedImage checks whether an image in a pod has to be changed
 func isPodNeedingUpgradedImage(
 	cluster *apiv1.Cluster,
@@ -336,23 +575,16 @@ func isPodNeedingUpgradedInitContainerImage(
 	return "", "", nil
 }
 
-// isPodNeedingRestart returns true if we need to restart the
-// Pod to apply a configuration change or there is a request of restart for the cluster
-func isPodNeedingRestart(
-	cluster *apiv1.Cluster,
-	instanceStatus postgres.PostgresqlStatus,
-) bool {
-	// If the cluster has been restarted and we are working with a Pod
-	// which have not been restarted yet, or restarted in a different
-	// time, let's restart it.
-	if clusterRestart, ok := cluster.Annotations[specs.ClusterRestartAnnotationName]; ok {
-		podRestart := instanceStatus.Pod.Annotations[specs.ClusterRestartAnnotationName]
-		if clusterRestart != podRestart {
-			return true
-		}
+// isClusterRestartRequested returns true if the cluster has been restarted
+// and we are working with a Pod which has not been restarted yet, or has been
+// restarted in a different time
+func isClusterRestartRequested(cluster *apiv1.Cluster, pod v1.Pod) bool {
+	clusterRestart, ok := cluster.Annotations[specs.ClusterRestartAnnotationName]
+	if !ok {
+		return false
 	}
 
-	return instanceStatus.PendingRestart
+	return clusterRestart != pod.Annotations[specs.ClusterRestartAnnotationName]
 }
 
 // upgradePod updates an instance to a newer image version
@@ -451,6 +683,17 @@ func (r *ClusterReconciler) upgradeInstanceManager(
 	return nil
 }
 
+// instanceManagerUpgradeClient is the HTTP client used to upload the new
+// instance manager binary. The pods are directly reachable by the operator,
+// so the proxy configured in its environment, if any, is never used
+var instanceManagerUpgradeClient = &http.Client{
+	Transport: func() http.RoundTripper {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = nil
+		return transport
+	}(),
+}
+
 // upgradeInstanceManagerOnPod upgrades an instance manager of a Pod via an HTTP PUT request.
 func upgradeInstanceManagerOnPod(ctx context.Context, pod v1.Pod) error {
 	binaryFileStream, err := executablehash.Stream()
@@ -468,7 +711,7 @@ func upgradeInstanceManagerOnPod(ctx context.Context, pod v1.Pod) error {
 	}
 	req.Body = binaryFileStream
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := instanceManagerUpgradeClient.Do(req)
 	if err != nil {
 		if errors.Is(err.(*neturl.Error).Err, io.EOF) {
 			// This is perfectly fine as the instance manager will
@@ -17,9 +17,18 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/specs"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -33,7 +42,7 @@ var _ = Describe("Pod upgrade", func() {
 	}
 	It("will not require a restart for just created Pods", func() {
 		pod := specs.PodWithExistingStorage(cluster, 1)
-		Expect(isPodNeedingRestart(&cluster, postgres.PostgresqlStatus{Pod: *pod})).
+		Expect(isClusterRestartRequested(&cluster, *pod)).
 			To(BeFalse())
 	})
 
@@ -51,23 +60,21 @@ var _ = Describe("Pod upgrade", func() {
 		clusterRestart := cluster
 		clusterRestart.Annotations = make(map[string]string)
 		clusterRestart.Annotations[specs.ClusterRestartAnnotationName] = "now"
-		Expect(isPodNeedingRestart(&clusterRestart, postgres.PostgresqlStatus{Pod: *pod})).
+		Expect(isClusterRestartRequested(&clusterRestart, *pod)).
 			To(BeTrue())
-		Expect(isPodNeedingRestart(&cluster, postgres.PostgresqlStatus{Pod: *pod})).
+		Expect(isClusterRestartRequested(&cluster, *pod)).
 			To(BeFalse())
 	})
 
 	It("checks when a restart is being needed by PostgreSQL", func() {
 		pod := specs.PodWithExistingStorage(cluster, 1)
-		Expect(isPodNeedingRestart(&cluster, postgres.PostgresqlStatus{Pod: *pod})).
-			To(BeFalse())
+		status := postgres.PostgresqlStatus{Pod: *pod, IsReady: true, ExecutableHash: "test_hash"}
+		needRollout, _, _ := IsPodNeedingRollout(status, &cluster)
+		Expect(needRollout).To(BeFalse())
 
-		Expect(isPodNeedingRestart(&cluster,
-			postgres.PostgresqlStatus{
-				Pod:            *pod,
-				PendingRestart: true,
-			})).
-			To(BeTrue())
+		status.PendingRestart = true
+		needRollout, _, _ = IsPodNeedingRollout(status, &cluster)
+		Expect(needRollout).To(BeTrue())
 	})
 
 	It("checks when a rollout is being needed for any reason", func() {
@@ -90,4 +97,163 @@ var _ = Describe("Pod upgrade", func() {
 		Expect(inplacePossible).To(BeTrue())
 		Expect(reason).To(BeEquivalentTo("configuration needs a restart to apply some configuration changes"))
 	})
+
+	It("reports when a rollout is caused by a restart request", func() {
+		pod := specs.PodWithExistingStorage(cluster, 1)
+		clusterRestart := cluster
+		clusterRestart.Annotations = map[string]string{
+			specs.ClusterRestartAnnotationName: "now",
+		}
+		status := postgres.PostgresqlStatus{Pod: *pod, IsReady: true, ExecutableHash: "test_hash"}
+		needRollout, inplacePossible, reason := IsPodNeedingRollout(status, &clusterRestart)
+		Expect(needRollout).To(BeTrue())
+		Expect(inplacePossible).To(BeTrue())
+		Expect(reason).To(BeEquivalentTo("a restart of the cluster has been requested"))
+
+		pod.Annotations[specs.ClusterRestartAnnotationName] = "now"
+		status.Pod = *pod
+		needRollout, _, _ = IsPodNeedingRollout(status, &clusterRestart)
+		Expect(needRollout).To(BeFalse())
+	})
+
+	It("requires a rollout when the TLS configuration of the metrics changes", func() {
+		pod := specs.PodWithExistingStorage(cluster, 1)
+		clusterTLS := cluster
+		clusterTLS.Spec.Monitoring = &apiv1.MonitoringConfiguration{
+			TLSConfig: &apiv1.ClusterMonitoringTLSConfiguration{Enabled: true},
+		}
+		status := postgres.PostgresqlStatus{Pod: *pod, IsReady: true, ExecutableHash: "test_hash"}
+		needRollout, inplacePossible, reason := IsPodNeedingRollout(status, &clusterTLS)
+		Expect(needRollout).To(BeTrue())
+		Expect(inplacePossible).To(BeFalse())
+		Expect(reason).To(BeEquivalentTo("the TLS configuration of the metrics endpoint changed"))
+
+		status.Pod = *specs.PodWithExistingStorage(clusterTLS, 1)
+		needRollout, _, _ = IsPodNeedingRollout(status, &clusterTLS)
+		Expect(needRollout).To(BeFalse())
+	})
+
+	It("describes the rollouts pending on the instances", func() {
+		firstPod := specs.PodWithExistingStorage(cluster, 1)
+		secondPod := specs.PodWithExistingStorage(cluster, 2)
+		podList := postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				{Pod: *firstPod, IsReady: true, ExecutableHash: "test_hash", PendingRestart: true},
+				{Pod: *secondPod, IsReady: true, ExecutableHash: "test_hash"},
+			},
+		}
+		Expect(getPendingRolloutOperations(&cluster, &podList)).To(ConsistOf(
+			firstPod.Name + ": configuration needs a restart to apply some configuration changes"))
+
+		podList.Items[0].PendingRestart = false
+		Expect(getPendingRolloutOperations(&cluster, &podList)).To(BeEmpty())
+	})
+	It("reports the missing shared preload libraries of the instances not being upgraded", func() {
+		firstPod := specs.PodWithExistingStorage(cluster, 1)
+		secondPod := specs.PodWithExistingStorage(cluster, 2)
+		secondPod.Spec.Containers[0].Image = "postgres:12.0"
+		podList := postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				{Pod: *firstPod, MissingSharedPreloadLibraries: []string{"timescaledb"}},
+				{Pod: *secondPod, MissingSharedPreloadLibraries: []string{"pg_cron"}},
+			},
+		}
+		Expect(getMissingSharedPreloadLibraries(&cluster, &podList)).To(Equal([]string{"timescaledb"}))
+	})
+})
+
+var _ = Describe("supervised primary update approval", func() {
+	newCluster := func(phase string, annotations map[string]string) *apiv1.Cluster {
+		return &apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cluster-example",
+				Namespace:   "default",
+				Annotations: annotations,
+			},
+			Status: apiv1.ClusterStatus{Phase: phase},
+		}
+	}
+
+	It("is not approved without the annotation", func() {
+		requested, err := getPrimaryUpdateApproval(newCluster(apiv1.PhaseWaitingForUser, nil), "cluster-example-1")
+		Expect(requested).To(BeFalse())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("is approved by the annotation naming the primary waiting for the update", func() {
+		cluster := newCluster(apiv1.PhaseWaitingForUser,
+			map[string]string{utils.ApprovePrimaryUpdateAnnotationName: "cluster-example-1"})
+		requested, err := getPrimaryUpdateApproval(cluster, "cluster-example-1")
+		Expect(requested).To(BeTrue())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("is not approved by an annotation naming another pod", func() {
+		cluster := newCluster(apiv1.PhaseWaitingForUser,
+			map[string]string{utils.ApprovePrimaryUpdateAnnotationName: "true"})
+		requested, err := getPrimaryUpdateApproval(cluster, "cluster-example-1")
+		Expect(requested).To(BeTrue())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("is not approved by an annotation set before the update was pending", func() {
+		cluster := newCluster(apiv1.PhaseHealthy,
+			map[string]string{utils.ApprovePrimaryUpdateAnnotationName: "cluster-example-1"})
+		requested, err := getPrimaryUpdateApproval(cluster, "cluster-example-1")
+		Expect(requested).To(BeTrue())
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("when consuming the approval", func() {
+		var (
+			fakeClient client.Client
+			recorder   *record.FakeRecorder
+			reconciler *ClusterReconciler
+		)
+
+		setup := func(cluster *apiv1.Cluster) {
+			scheme := runtime.NewScheme()
+			Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &ClusterReconciler{Client: fakeClient, Recorder: recorder}
+		}
+
+		expectAnnotationRemoved := func(cluster *apiv1.Cluster) {
+			var stored apiv1.Cluster
+			Expect(fakeClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), &stored)).To(Succeed())
+			Expect(stored.Annotations).ToNot(HaveKey(utils.ApprovePrimaryUpdateAnnotationName))
+		}
+
+		It("approves the pending update once", func() {
+			cluster := newCluster(apiv1.PhaseWaitingForUser,
+				map[string]string{utils.ApprovePrimaryUpdateAnnotationName: "cluster-example-1"})
+			setup(cluster)
+
+			approved, err := reconciler.consumePrimaryUpdateApproval(
+				context.Background(), cluster, "cluster-example-1", "the image changed")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(approved).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring("PrimaryUpdateApproved")))
+			expectAnnotationRemoved(cluster)
+
+			approved, err = reconciler.consumePrimaryUpdateApproval(
+				context.Background(), cluster, "cluster-example-1", "the image changed")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(approved).To(BeFalse())
+		})
+
+		It("discards a leftover approval", func() {
+			cluster := newCluster(apiv1.PhaseHealthy,
+				map[string]string{utils.ApprovePrimaryUpdateAnnotationName: "cluster-example-1"})
+			setup(cluster)
+
+			approved, err := reconciler.consumePrimaryUpdateApproval(
+				context.Background(), cluster, "cluster-example-1", "the image changed")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(approved).To(BeFalse())
+			Expect(recorder.Events).To(Receive(ContainSubstring("PrimaryUpdateApprovalDiscarded")))
+			expectAnnotationRemoved(cluster)
+		})
+	})
 })
@@ -18,8 +18,11 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 
+	"k8s.io/utils/strings/slices"
+
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 )
 
@@ -65,6 +68,10 @@ func (r *PoolerReconciler) updatePoolerStatus(
 		updatedStatus.Instances = resources.Deployment.Status.Replicas
 	}
 
+	if pooler.Spec.PgBouncer != nil {
+		updatedStatus.AuthQuery = getAuthQueryStatus(pooler, resources)
+	}
+
 	// then update the status if anything changed
 	if !reflect.DeepEqual(pooler.Status, updatedStatus) {
 		pooler.Status = *updatedStatus
@@ -73,3 +80,36 @@ func (r *PoolerReconciler) updatePoolerStatus(
 
 	return nil
 }
+
+// getAuthQueryStatus checks whether everything needed by the auth_query
+// of PgBouncer is in place
+func getAuthQueryStatus(pooler *apiv1.Pooler, resources *poolerManagedResources) *apiv1.PoolerAuthQueryStatus {
+	status := &apiv1.PoolerAuthQueryStatus{
+		Query:   pooler.GetAuthQuery(),
+		Managed: pooler.IsAuthQueryManaged(),
+	}
+
+	secretName := pooler.GetAuthQuerySecretName()
+	switch {
+	case resources.AuthUserSecret == nil:
+		status.Message = fmt.Sprintf("waiting for the auth query secret %s", secretName)
+	case status.Managed && !isPgBouncerIntegrationInPlace(resources.Cluster, secretName):
+		status.Message = "waiting for the cluster to create the auth query user and function"
+	case status.Managed && !resources.Cluster.Status.PoolerIntegrations.PgBouncerIntegration.AuthQueryReady:
+		status.Message = "waiting for the primary to create the auth query function in every database"
+	default:
+		status.Ready = true
+	}
+
+	return status
+}
+
+// isPgBouncerIntegrationInPlace checks whether the cluster is taking care
+// of the user and the function used by the auth_query with the passed secret
+func isPgBouncerIntegrationInPlace(cluster *apiv1.Cluster, secretName string) bool {
+	if cluster == nil || cluster.Status.PoolerIntegrations == nil {
+		return false
+	}
+
+	return slices.Contains(cluster.Status.PoolerIntegrations.PgBouncerIntegration.Secrets, secretName)
+}
@@ -138,4 +138,39 @@ var _ = Describe("pooler_status unit tests", func() {
 			Expect(poolerAfter.ResourceVersion).To(Equal(poolerBefore.ResourceVersion))
 		})
 	})
+	It("should report when the auth query is ready", func() {
+		cluster := &v1.Cluster{}
+		pooler := &v1.Pooler{
+			Spec: v1.PoolerSpec{
+				Cluster:   v1.LocalObjectReference{Name: "cluster-example"},
+				PgBouncer: &v1.PgBouncerSpec{},
+			},
+		}
+		authUserSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: pooler.GetAuthQuerySecretName()},
+		}
+
+		status := getAuthQueryStatus(pooler, &poolerManagedResources{Cluster: cluster})
+		Expect(status.Ready).To(BeFalse())
+		Expect(status.Managed).To(BeTrue())
+		Expect(status.Message).To(ContainSubstring(pooler.GetAuthQuerySecretName()))
+
+		status = getAuthQueryStatus(pooler, &poolerManagedResources{Cluster: cluster, AuthUserSecret: authUserSecret})
+		Expect(status.Ready).To(BeFalse())
+		Expect(status.Message).To(ContainSubstring("user and function"))
+
+		cluster.Status.PoolerIntegrations = &v1.PoolerIntegrations{
+			PgBouncerIntegration: v1.PgBouncerIntegrationStatus{
+				Secrets: []string{pooler.GetAuthQuerySecretName()},
+			},
+		}
+		status = getAuthQueryStatus(pooler, &poolerManagedResources{Cluster: cluster, AuthUserSecret: authUserSecret})
+		Expect(status.Ready).To(BeFalse())
+		Expect(status.Message).To(ContainSubstring("function"))
+
+		cluster.Status.PoolerIntegrations.PgBouncerIntegration.AuthQueryReady = true
+		status = getAuthQueryStatus(pooler, &poolerManagedResources{Cluster: cluster, AuthUserSecret: authUserSecret})
+		Expect(status.Ready).To(BeTrue())
+		Expect(status.Query).To(Equal(v1.DefaultPgBouncerPoolerAuthQuery))
+	})
 })
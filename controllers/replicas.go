@@ -20,8 +20,11 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
@@ -36,6 +39,16 @@ import (
 // because there is a WAL receiver running in our Pod list
 var ErrWalReceiversRunning = fmt.Errorf("wal receivers are still running")
 
+// switchoverMaxLag is the maximum amount of WAL, in bytes, that a replica may
+// not have flushed yet to be promoted by a switchover requested by the user.
+// The replicas receive the remaining WAL while the former primary shuts down
+const switchoverMaxLag = 16 * 1024 * 1024
+
+// ErrWaitingOnFailOverDelay is raised when the primary server is unhealthy
+// but the failover is being delayed as requested by the user
+var ErrWaitingOnFailOverDelay = fmt.Errorf(
+	"current primary isn't healthy, waiting for the delay before triggering a failover")
+
 // updateTargetPrimaryFromPods sets the name of the target primary from the Pods status if needed
 // this function will returns the name of the new primary selected for promotion
 func (r *ClusterReconciler) updateTargetPrimaryFromPods(
@@ -73,7 +86,148 @@ func (r *ClusterReconciler) updateTargetPrimaryFromPods(
 		return r.updateTargetPrimaryFromPodsReplicaCluster(ctx, cluster, status, resources)
 	}
 
-	return r.updateTargetPrimaryFromPodsPrimaryCluster(ctx, cluster, status, resources)
+	selectedPrimary, err := r.updateTargetPrimaryFromPodsPrimaryCluster(ctx, cluster, status, resources)
+	if err != nil || selectedPrimary != "" {
+		return selectedPrimary, err
+	}
+
+	// Third step: if the user requested a switchover, check if we can
+	// perform it
+	selectedPrimary, err = r.switchoverToRequestedInstance(ctx, cluster, status)
+	if err != nil || selectedPrimary != "" {
+		return selectedPrimary, err
+	}
+
+	// Fourth step: if the user expressed a preference about where the primary
+	// should run, check if we need to switch over to a different instance
+	return r.setPrimaryOnPreferredInstance(ctx, cluster, status)
+}
+
+// registerPrimaryChangeProgress reports, in the phase reason, the step reached
+// by the switchover or the failover in progress: the shutdown of the failing
+// primary, the promotion of the target primary, and then the other instances
+// following the new primary
+func (r *ClusterReconciler) registerPrimaryChangeProgress(ctx context.Context, cluster *apiv1.Cluster) error {
+	phase := cluster.Status.Phase
+	if phase != apiv1.PhaseSwitchover && phase != apiv1.PhaseFailOver {
+		return nil
+	}
+
+	currentPrimary := cluster.Status.CurrentPrimary
+	targetPrimary := cluster.Status.TargetPrimary
+
+	var reason string
+	switch {
+	case currentPrimary == targetPrimary:
+		reason = fmt.Sprintf("Waiting for the other instances to follow the new primary %v", targetPrimary)
+	case targetPrimary == apiv1.PendingFailoverMarker:
+		reason = fmt.Sprintf("Waiting for the failing primary %v to shut down and the WAL receivers to stop",
+			currentPrimary)
+	case phase == apiv1.PhaseSwitchover:
+		reason = fmt.Sprintf("Waiting for the former primary %v to shut down and %v to be promoted",
+			currentPrimary, targetPrimary)
+	default:
+		reason = fmt.Sprintf("Waiting for %v to be promoted, replacing the failed primary %v",
+			targetPrimary, currentPrimary)
+	}
+
+	return r.RegisterPhase(ctx, cluster, phase, reason)
+}
+
+// switchoverToRequestedInstance performs the switchover requested by the user
+// through the switchover annotation, once the cluster is healthy. The request
+// is removed as soon as it is processed, whether the switchover is triggered
+// or rejected because the requested instance can't be promoted
+func (r *ClusterReconciler) switchoverToRequestedInstance(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	status postgres.PostgresqlStatusList,
+) (string, error) {
+	contextLogger := log.FromContext(ctx)
+
+	targetInstance, ok := cluster.Annotations[utils.SwitchoverToAnnotationName]
+	if !ok {
+		return "", nil
+	}
+
+	if cluster.Status.Phase != apiv1.PhaseHealthy ||
+		cluster.Status.TargetPrimary != cluster.Status.CurrentPrimary {
+		contextLogger.Info("Waiting for the cluster to be healthy before switching over",
+			"targetPrimary", targetInstance)
+		return "", nil
+	}
+
+	reason := getSwitchoverRejectionReason(cluster, status, targetInstance)
+	if reason == "" {
+		// The request is kept until the poolers are paused
+		if err := r.pausePoolersBeforeSwitchover(ctx, cluster); err != nil {
+			return "", err
+		}
+	}
+
+	oldCluster := cluster.DeepCopy()
+	delete(cluster.Annotations, utils.SwitchoverToAnnotationName)
+	if err := r.Patch(ctx, cluster, client.MergeFrom(oldCluster)); err != nil {
+		return "", err
+	}
+
+	if reason != "" {
+		contextLogger.Info("Rejecting the requested switchover",
+			"targetPrimary", targetInstance, "reason", reason)
+		r.Recorder.Eventf(cluster, "Warning", "SwitchoverRejected",
+			"Switchover to %v rejected: %v", targetInstance, reason)
+		return "", nil
+	}
+
+	contextLogger.Info("Switching over as requested by the user",
+		"currentPrimary", cluster.Status.CurrentPrimary, "targetPrimary", targetInstance)
+	status.LogStatus(ctx)
+	r.Recorder.Eventf(cluster, "Normal", "SwitchingOver",
+		"Switching over from %v to %v as requested by the user",
+		cluster.Status.CurrentPrimary, targetInstance)
+	if err := r.RegisterPhase(ctx, cluster, apiv1.PhaseSwitchover,
+		fmt.Sprintf("Switching over to %v, as requested by the user", targetInstance)); err != nil {
+		return "", err
+	}
+	return targetInstance, r.setPrimaryInstance(ctx, cluster, targetInstance)
+}
+
+// getSwitchoverRejectionReason checks whether the requested instance can be
+// promoted with a switchover, returning the reason why it can't or an empty
+// string otherwise
+func getSwitchoverRejectionReason(
+	cluster *apiv1.Cluster,
+	status postgres.PostgresqlStatusList,
+	targetInstance string,
+) string {
+	if cluster.IsReplica() {
+		return "switchover requests are not supported in replica clusters"
+	}
+
+	if targetInstance == cluster.Status.CurrentPrimary {
+		return "the instance is already the primary"
+	}
+
+	primary := status.Items[0]
+	if !primary.IsPrimary || primary.Pod.Name != cluster.Status.CurrentPrimary {
+		return "the current primary is not reporting its status"
+	}
+
+	for _, candidate := range status.Items[1:] {
+		if candidate.Pod.Name != targetInstance {
+			continue
+		}
+
+		if !candidate.IsReady || candidate.Error != nil {
+			return "the instance is not ready"
+		}
+		if !isReplicaLagWithin(primary, candidate, switchoverMaxLag) {
+			return "the instance is not caught up with the primary"
+		}
+		return ""
+	}
+
+	return "the instance doesn't exist"
 }
 
 // updateTargetPrimaryFromPodsPrimaryCluster sets the name of the target primary from the Pods status if needed
@@ -96,6 +250,17 @@ func (r *ClusterReconciler) updateTargetPrimaryFromPodsPrimaryCluster(
 	// If the first pod in the sorted list is already the targetPrimary,
 	// we have nothing to do here.
 	if cluster.Status.TargetPrimary == status.Items[0].Pod.Name {
+		if cluster.Status.CurrentPrimaryFailingSinceTimestamp != "" {
+			// The failover was pending only if the failing primary is still the target one
+			if cluster.Status.TargetPrimary == cluster.Status.CurrentPrimary {
+				contextLogger.Info("Current primary is healthy again, the pending failover has been cancelled",
+					"primary", cluster.Status.TargetPrimary)
+			}
+			cluster.Status.CurrentPrimaryFailingSinceTimestamp = ""
+			if err := r.Status().Update(ctx, cluster); err != nil {
+				return "", err
+			}
+		}
 		return "", nil
 	}
 
@@ -105,6 +270,10 @@ func (r *ClusterReconciler) updateTargetPrimaryFromPodsPrimaryCluster(
 	// (if is still alive) to shut down by setting the apiv1.PendingFailoverMarker as
 	// target primary.
 	if cluster.Status.TargetPrimary == cluster.Status.CurrentPrimary {
+		if err := r.delayFailover(ctx, cluster); err != nil {
+			return "", err
+		}
+
 		contextLogger.Info("Current primary isn't healthy, initiating a failover")
 		status.LogStatus(ctx)
 		contextLogger.Debug("Cluster status before initiating the failover", "instances", resources.instances)
@@ -114,6 +283,8 @@ func (r *ClusterReconciler) updateTargetPrimaryFromPodsPrimaryCluster(
 			fmt.Sprintf("Initiating a failover from %v", cluster.Status.CurrentPrimary)); err != nil {
 			return "", err
 		}
+		// The failover is not pending anymore
+		cluster.Status.CurrentPrimaryFailingSinceTimestamp = ""
 		err := r.setPrimaryInstance(ctx, cluster, apiv1.PendingFailoverMarker)
 		if err != nil {
 			return "", err
@@ -157,6 +328,246 @@ func (r *ClusterReconciler) updateTargetPrimaryFromPodsPrimaryCluster(
 	return status.Items[0].Pod.Name, r.setPrimaryInstance(ctx, cluster, status.Items[0].Pod.Name)
 }
 
+// delayFailover returns ErrWaitingOnFailOverDelay until the primary has been
+// unhealthy for longer than the failover delay requested by the user, keeping
+// track in the status of the time when it was first detected to be unhealthy
+func (r *ClusterReconciler) delayFailover(ctx context.Context, cluster *apiv1.Cluster) error {
+	if cluster.Spec.FailoverDelay <= 0 {
+		return nil
+	}
+
+	if cluster.Status.CurrentPrimaryFailingSinceTimestamp == "" {
+		log.FromContext(ctx).Info("Current primary isn't healthy, waiting before initiating a failover",
+			"primary", cluster.Status.CurrentPrimary, "failoverDelay", cluster.Spec.FailoverDelay)
+		r.Recorder.Eventf(cluster, "Normal", "FailoverDelayed",
+			"Current primary isn't healthy, waiting %v seconds before initiating a failover from %v",
+			cluster.Spec.FailoverDelay, cluster.Status.CurrentPrimary)
+		cluster.Status.CurrentPrimaryFailingSinceTimestamp = utils.GetCurrentTimestamp()
+		if err := r.Status().Update(ctx, cluster); err != nil {
+			return err
+		}
+		return ErrWaitingOnFailOverDelay
+	}
+
+	elapsed, err := isFailoverDelayElapsed(cluster, utils.GetCurrentTimestamp())
+	if err != nil {
+		return err
+	}
+	if !elapsed {
+		return ErrWaitingOnFailOverDelay
+	}
+
+	return nil
+}
+
+// isFailoverDelayElapsed checks if the primary has been unhealthy for
+// longer than the configured failover delay
+func isFailoverDelayElapsed(cluster *apiv1.Cluster, currentTimestamp string) (bool, error) {
+	failingSince, err := utils.DifferenceBetweenTimestamps(
+		currentTimestamp,
+		cluster.Status.CurrentPrimaryFailingSinceTimestamp,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return failingSince >= time.Duration(cluster.Spec.FailoverDelay)*time.Second, nil
+}
+
+// isReplicaClusterPromotionPending checks if the replica mode of the cluster
+// has been disabled while the designated primary is still running as a
+// standby server, waiting to be promoted by the instance manager
+func isReplicaClusterPromotionPending(
+	cluster *apiv1.Cluster,
+	instancesStatus postgres.PostgresqlStatusList,
+) bool {
+	if cluster.Spec.ReplicaCluster == nil || cluster.IsReplica() {
+		return false
+	}
+
+	// during a switchover or a failover the current primary is not
+	// the instance that we are promoting
+	if cluster.Status.CurrentPrimary == "" ||
+		cluster.Status.CurrentPrimary != cluster.Status.TargetPrimary {
+		return false
+	}
+
+	return instancesStatus.IsPodInRecovery(cluster.Status.CurrentPrimary)
+}
+
+// setPrimaryOnPreferredInstance switches over to the instance preferred by the
+// user, if the cluster is healthy and the current primary doesn't match the
+// preference. Only replicas that are streaming from the primary, and that
+// have replayed all the WAL it generated, are considered as candidates.
+// The mismatch is reported only when its reason changes
+func (r *ClusterReconciler) setPrimaryOnPreferredInstance(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	status postgres.PostgresqlStatusList,
+) (string, error) {
+	contextLogger := log.FromContext(ctx)
+	clusterName := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+
+	if cluster.Spec.PreferredPrimary == nil {
+		r.preferredPrimaryReporter.reset(clusterName)
+		return "", nil
+	}
+
+	if cluster.Status.Phase != apiv1.PhaseHealthy ||
+		cluster.Status.TargetPrimary != cluster.Status.CurrentPrimary ||
+		cluster.Status.ReadyInstances != cluster.Spec.Instances {
+		return "", nil
+	}
+
+	primary := status.Items[0]
+	if !primary.IsPrimary || primary.Pod.Name != cluster.Status.CurrentPrimary {
+		return "", nil
+	}
+
+	isPreferred, err := r.isPreferredPrimary(ctx, cluster, primary)
+	if err != nil {
+		return "", err
+	}
+	if isPreferred {
+		r.preferredPrimaryReporter.reset(clusterName)
+		return "", nil
+	}
+
+	for _, candidate := range status.Items[1:] {
+		isPreferred, err := r.isPreferredPrimary(ctx, cluster, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !isPreferred || !candidate.IsReady || !isReplicaCaughtUp(primary, candidate) {
+			continue
+		}
+
+		if cluster.GetPrimaryUpdateStrategy() == apiv1.PrimaryUpdateStrategySupervised {
+			if r.preferredPrimaryReporter.shouldReport(clusterName, "supervised/"+candidate.Pod.Name) {
+				contextLogger.Info("Current primary is not the preferred one, waiting for the user to "+
+					"issue a switchover", "currentPrimary", primary.Pod.Name, "preferredPrimary", candidate.Pod.Name)
+				r.Recorder.Eventf(cluster, "Normal", "PreferredPrimaryMismatch",
+					"Current primary %v is not the preferred one, a switchover to %v is required",
+					primary.Pod.Name, candidate.Pod.Name)
+			}
+			return "", nil
+		}
+
+		inMaintenanceWindow, err := cluster.IsInMaintenanceWindow(time.Now())
+		if err != nil {
+			return "", err
+		}
+		if !inMaintenanceWindow {
+			if r.preferredPrimaryReporter.shouldReport(clusterName, "maintenance/"+candidate.Pod.Name) {
+				contextLogger.Info("Current primary is not the preferred one, the switchover is deferred "+
+					"until the maintenance window", "currentPrimary", primary.Pod.Name,
+					"preferredPrimary", candidate.Pod.Name)
+			}
+			return "", nil
+		}
+
+		if err := r.pausePoolersBeforeSwitchover(ctx, cluster); err != nil {
+			return "", err
+		}
+
+		r.preferredPrimaryReporter.reset(clusterName)
+		contextLogger.Info("Current primary is not the preferred one, triggering a switchover",
+			"currentPrimary", primary.Pod.Name, "targetPrimary", candidate.Pod.Name)
+		status.LogStatus(ctx)
+		r.Recorder.Eventf(cluster, "Normal", "SwitchingOver",
+			"Switching over from %v to the preferred primary %v",
+			primary.Pod.Name, candidate.Pod.Name)
+		if err := r.RegisterPhase(ctx, cluster, apiv1.PhaseSwitchover,
+			fmt.Sprintf("Switching over to %v, the preferred primary", candidate.Pod.Name)); err != nil {
+			return "", err
+		}
+		return candidate.Pod.Name, r.setPrimaryInstance(ctx, cluster, candidate.Pod.Name)
+	}
+
+	if r.preferredPrimaryReporter.shouldReport(clusterName, "no-candidate") {
+		contextLogger.Info("Current primary is not the preferred one, but there are no caught up candidates",
+			"currentPrimary", primary.Pod.Name, "preferredPrimary", cluster.Spec.PreferredPrimary)
+		r.Recorder.Eventf(cluster, "Normal", "PreferredPrimaryMismatch",
+			"Current primary %v is not the preferred one, waiting for a matching replica to be caught up",
+			primary.Pod.Name)
+	}
+	return "", nil
+}
+
+// isPreferredPrimary checks whether an instance matches the preference
+// expressed by the user about the primary
+func (r *ClusterReconciler) isPreferredPrimary(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	instance postgres.PostgresqlStatus,
+) (bool, error) {
+	preference := cluster.Spec.PreferredPrimary
+
+	if preference.InstanceSerial != 0 {
+		serial, err := specs.GetNodeSerial(instance.Pod.ObjectMeta)
+		if err != nil {
+			return false, nil
+		}
+		return serial == preference.InstanceSerial, nil
+	}
+
+	if instance.Node == "" {
+		return false, nil
+	}
+
+	var node corev1.Node
+	if err := r.Get(ctx, client.ObjectKey{Name: instance.Node}, &node); err != nil {
+		return false, err
+	}
+	return node.Labels[corev1.LabelTopologyZone] == preference.Zone, nil
+}
+
+// isReplicaLagWithin checks whether a replica is streaming from the primary and
+// has flushed the WAL the primary has generated, except at most maxLag bytes
+func isReplicaLagWithin(primary, replica postgres.PostgresqlStatus, maxLag int64) bool {
+	for _, replication := range primary.ReplicationInfo {
+		if replication.ApplicationName != replica.Pod.Name {
+			continue
+		}
+
+		flushLsn, err := replication.FlushLsn.Parse()
+		if err != nil {
+			return false
+		}
+		currentLsn, err := primary.CurrentLsn.Parse()
+		if err != nil {
+			return false
+		}
+
+		return replication.State == "streaming" && flushLsn+maxLag >= currentLsn
+	}
+
+	return false
+}
+
+// isReplicaCaughtUp checks whether a replica is streaming from the primary
+// and has replayed all the WAL the primary has generated
+func isReplicaCaughtUp(primary, replica postgres.PostgresqlStatus) bool {
+	for _, replication := range primary.ReplicationInfo {
+		if replication.ApplicationName != replica.Pod.Name {
+			continue
+		}
+
+		replayLsn, err := replication.ReplayLsn.Parse()
+		if err != nil {
+			return false
+		}
+		currentLsn, err := primary.CurrentLsn.Parse()
+		if err != nil {
+			return false
+		}
+
+		return replication.State == "streaming" && replayLsn >= currentLsn
+	}
+
+	return false
+}
+
 // isNodeUnschedulable checks whether a node is set to unschedulable
 func (r *ClusterReconciler) isNodeUnschedulable(ctx context.Context, nodeName string) (bool, error) {
 	var node corev1.Node
@@ -216,6 +627,10 @@ func (r *ClusterReconciler) setPrimaryOnSchedulableNode(
 			continue
 		}
 
+		if err := r.pausePoolersBeforeSwitchover(ctx, cluster); err != nil {
+			return "", err
+		}
+
 		// Set the current candidate as targetPrimary
 		contextLogger.Info("Current primary is running on unschedulable node, triggering a switchover",
 			"currentPrimary", primaryPod.Pod.Name, "currentPrimaryNode", primaryPod.Node,
@@ -552,6 +967,76 @@ func (r *ClusterReconciler) updateRoleLabelsOnPods(
 	return nil
 }
 
+// updateCaughtUpLabelsOnPods marks the replicas whose lag is within the limit
+// set for the read-only service, which excludes the other ones
+func (r *ClusterReconciler) updateCaughtUpLabelsOnPods(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	pods corev1.PodList,
+	instancesStatus postgres.PostgresqlStatusList,
+) error {
+	contextLogger := log.FromContext(ctx)
+
+	caughtUpLabels := getCaughtUpLabels(cluster, instancesStatus)
+	for idx := range pods.Items {
+		pod := &pods.Items[idx]
+
+		value, ok := caughtUpLabels[pod.Name]
+		if !ok || !utils.IsPodActive(*pod) || pod.Labels[specs.ClusterCaughtUpLabelName] == value {
+			continue
+		}
+
+		contextLogger.Info("Setting caught up label", "pod", pod.Name, "value", value)
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		pod.Labels[specs.ClusterCaughtUpLabelName] = value
+		if err := r.Patch(ctx, pod, patch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getCaughtUpLabels computes, for every replica, the value of the label
+// telling if its lag is within the limit set for the read-only service.
+// Nothing is computed when no limit is set or the primary is not reporting
+// its status, as the lag of the replicas can't be known
+func getCaughtUpLabels(
+	cluster *apiv1.Cluster,
+	instancesStatus postgres.PostgresqlStatusList,
+) map[string]string {
+	maxLag, enabled := cluster.GetReadOnlyServiceMaxLag()
+	if !enabled {
+		return nil
+	}
+
+	var primary *postgres.PostgresqlStatus
+	for idx := range instancesStatus.Items {
+		item := &instancesStatus.Items[idx]
+		if item.Pod.Name == cluster.Status.CurrentPrimary && item.Error == nil && item.IsPrimary {
+			primary = item
+		}
+	}
+	if primary == nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(instancesStatus.Items))
+	for _, item := range instancesStatus.Items {
+		if item.Pod.Name == primary.Pod.Name {
+			continue
+		}
+
+		caughtUp := item.Error == nil && isReplicaLagWithin(*primary, item, maxLag)
+		result[item.Pod.Name] = strconv.FormatBool(caughtUp)
+	}
+
+	return result
+}
+
 // updateOperatorLabelsOnInstances ensures that the instances have the correct labels
 func (r *ClusterReconciler) updateOperatorLabelsOnInstances(
 	ctx context.Context,
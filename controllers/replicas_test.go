@@ -17,9 +17,16 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/specs"
 
@@ -150,3 +157,385 @@ var _ = Describe("Check pods not on primary node", func() {
 		Expect(GetPodsNotOnPrimaryNode(statusList2, &statusList2.Items[0]).Items).ToNot(BeEmpty())
 	})
 })
+
+var _ = Describe("failover delay", func() {
+	cluster := &apiv1.Cluster{
+		Spec: apiv1.ClusterSpec{
+			FailoverDelay: 30,
+		},
+		Status: apiv1.ClusterStatus{
+			CurrentPrimaryFailingSinceTimestamp: "2022-11-07T10:00:00.000000Z",
+		},
+	}
+
+	It("waits while the primary has been failing for less than the delay", func() {
+		elapsed, err := isFailoverDelayElapsed(cluster, "2022-11-07T10:00:29.000000Z")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(elapsed).To(BeFalse())
+	})
+
+	It("allows the failover when the delay is elapsed", func() {
+		elapsed, err := isFailoverDelayElapsed(cluster, "2022-11-07T10:00:30.000000Z")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(elapsed).To(BeTrue())
+	})
+
+	It("fails when the failing timestamp is not valid", func() {
+		invalidCluster := cluster.DeepCopy()
+		invalidCluster.Status.CurrentPrimaryFailingSinceTimestamp = "yesterday"
+		_, err := isFailoverDelayElapsed(invalidCluster, "2022-11-07T10:00:30.000000Z")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("while updating the target primary", func() {
+		var (
+			failingCluster *apiv1.Cluster
+			recorder       *record.FakeRecorder
+			reconciler     *ClusterReconciler
+		)
+
+		newStatus := func(primaryName string, others ...string) postgres.PostgresqlStatusList {
+			status := postgres.PostgresqlStatusList{
+				Items: []postgres.PostgresqlStatus{
+					{Pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: primaryName}}},
+				},
+			}
+			for _, name := range others {
+				status.Items = append(status.Items, postgres.PostgresqlStatus{
+					Pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}},
+				})
+			}
+			return status
+		}
+
+		BeforeEach(func() {
+			failingCluster = &apiv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+				Spec:       apiv1.ClusterSpec{FailoverDelay: 30},
+				Status: apiv1.ClusterStatus{
+					CurrentPrimary:                      "cluster-1",
+					TargetPrimary:                       "cluster-1",
+					CurrentPrimaryFailingSinceTimestamp: "2022-11-07T10:00:00.000000Z",
+				},
+			}
+
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &ClusterReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(failingCluster).Build(),
+				Recorder: recorder,
+			}
+		})
+
+		It("cancels the pending failover when the primary is healthy again", func() {
+			newPrimary, err := reconciler.updateTargetPrimaryFromPodsPrimaryCluster(
+				context.Background(), failingCluster, newStatus("cluster-1", "cluster-2"), &managedResources{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(newPrimary).To(BeEmpty())
+			Expect(failingCluster.Status.CurrentPrimaryFailingSinceTimestamp).To(BeEmpty())
+		})
+
+		It("stops tracking the failing primary when the failover is initiated", func() {
+			newPrimary, err := reconciler.updateTargetPrimaryFromPodsPrimaryCluster(
+				context.Background(), failingCluster, newStatus("cluster-2", "cluster-1"), &managedResources{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(newPrimary).To(Equal("cluster-2"))
+			Expect(failingCluster.Status.TargetPrimary).To(Equal("cluster-2"))
+			Expect(failingCluster.Status.CurrentPrimaryFailingSinceTimestamp).To(BeEmpty())
+			Expect(recorder.Events).To(Receive(ContainSubstring("initiating a failover")))
+		})
+	})
+})
+
+var _ = Describe("preferred primary candidates", func() {
+	replica := postgres.PostgresqlStatus{
+		Pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-2"}},
+	}
+
+	primaryWithReplication := func(state string, flushLsn postgres.LSN) postgres.PostgresqlStatus {
+		return postgres.PostgresqlStatus{
+			IsPrimary:  true,
+			CurrentLsn: "0/3000060",
+			ReplicationInfo: postgres.PgStatReplicationList{
+				{
+					ApplicationName: "cluster-example-2",
+					State:           state,
+					FlushLsn:        flushLsn,
+				},
+			},
+		}
+	}
+
+	It("considers a streaming replica that flushed all the WAL caught up", func() {
+		Expect(isReplicaLagWithin(primaryWithReplication("streaming", "0/3000060"), replica, 0)).To(BeTrue())
+	})
+
+	It("doesn't consider a lagging replica caught up", func() {
+		Expect(isReplicaLagWithin(primaryWithReplication("streaming", "0/3000000"), replica, 0)).To(BeFalse())
+	})
+
+	It("doesn't consider a replica that is not streaming caught up", func() {
+		Expect(isReplicaLagWithin(primaryWithReplication("catchup", "0/3000060"), replica, 0)).To(BeFalse())
+	})
+
+	It("doesn't consider a replica unknown to the primary caught up", func() {
+		Expect(isReplicaLagWithin(postgres.PostgresqlStatus{CurrentLsn: "0/3000060"}, replica, 0)).To(BeFalse())
+	})
+
+	It("doesn't consider a replica caught up when the LSN is not valid", func() {
+		Expect(isReplicaLagWithin(primaryWithReplication("streaming", ""), replica, 0)).To(BeFalse())
+	})
+
+	It("tolerates the requested lag", func() {
+		Expect(isReplicaLagWithin(primaryWithReplication("streaming", "0/3000000"), replica, 0x60)).To(BeTrue())
+		Expect(isReplicaLagWithin(primaryWithReplication("streaming", "0/3000000"), replica, 0x5f)).To(BeFalse())
+	})
+
+	It("requires a replica to have replayed all the WAL to be fully caught up", func() {
+		primary := primaryWithReplication("streaming", "0/3000060")
+		primary.ReplicationInfo[0].ReplayLsn = "0/3000000"
+		Expect(isReplicaCaughtUp(primary, replica)).To(BeFalse())
+
+		primary.ReplicationInfo[0].ReplayLsn = "0/3000060"
+		Expect(isReplicaCaughtUp(primary, replica)).To(BeTrue())
+
+		primary.ReplicationInfo[0].State = "catchup"
+		Expect(isReplicaCaughtUp(primary, replica)).To(BeFalse())
+	})
+})
+
+var _ = Describe("caught up labels for the read-only service", func() {
+	instancesStatus := postgres.PostgresqlStatusList{
+		Items: []postgres.PostgresqlStatus{
+			{
+				Pod:        corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-1"}},
+				IsPrimary:  true,
+				CurrentLsn: "0/3000000",
+				ReplicationInfo: postgres.PgStatReplicationList{
+					{ApplicationName: "cluster-example-2", State: "streaming", FlushLsn: "0/3000000"},
+					{ApplicationName: "cluster-example-3", State: "streaming", FlushLsn: "0/1000000"},
+				},
+			},
+			{Pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-2"}}},
+			{Pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-3"}}},
+		},
+	}
+
+	clusterWithMaxLag := func(maxLag string) *apiv1.Cluster {
+		return &apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				ReadOnlyService: &apiv1.ReadOnlyServiceConfiguration{MaxLag: maxLag},
+			},
+			Status: apiv1.ClusterStatus{CurrentPrimary: "cluster-example-1"},
+		}
+	}
+
+	It("doesn't label the replicas when no maximum lag is set", func() {
+		cluster := &apiv1.Cluster{Status: apiv1.ClusterStatus{CurrentPrimary: "cluster-example-1"}}
+		Expect(getCaughtUpLabels(cluster, instancesStatus)).To(BeEmpty())
+	})
+
+	It("marks the replicas whose lag exceeds the limit", func() {
+		Expect(getCaughtUpLabels(clusterWithMaxLag("16Mi"), instancesStatus)).To(Equal(map[string]string{
+			"cluster-example-2": "true",
+			"cluster-example-3": "false",
+		}))
+	})
+
+	It("doesn't label the replicas when the primary is not reporting its status", func() {
+		cluster := clusterWithMaxLag("16Mi")
+		cluster.Status.CurrentPrimary = "cluster-example-4"
+		Expect(getCaughtUpLabels(cluster, instancesStatus)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("switchover requested by the user", func() {
+	cluster := &apiv1.Cluster{
+		Status: apiv1.ClusterStatus{
+			CurrentPrimary: "cluster-example-1",
+			TargetPrimary:  "cluster-example-1",
+		},
+	}
+
+	makeStatus := func(flushLsn postgres.LSN, replicaReady bool) postgres.PostgresqlStatusList {
+		return postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				{
+					Pod:        corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-1"}},
+					IsPrimary:  true,
+					IsReady:    true,
+					CurrentLsn: "0/5000000",
+					ReplicationInfo: postgres.PgStatReplicationList{
+						{
+							ApplicationName: "cluster-example-2",
+							State:           "streaming",
+							FlushLsn:        flushLsn,
+						},
+					},
+				},
+				{
+					Pod:     corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-2"}},
+					IsReady: replicaReady,
+				},
+			},
+		}
+	}
+
+	It("accepts a ready replica which is caught up", func() {
+		status := makeStatus("0/4FFFF00", true)
+		Expect(getSwitchoverRejectionReason(cluster, status, "cluster-example-2")).To(BeEmpty())
+	})
+
+	It("rejects a replica which is lagging too much", func() {
+		status := makeStatus("0/1000000", true)
+		Expect(getSwitchoverRejectionReason(cluster, status, "cluster-example-2")).
+			To(ContainSubstring("not caught up"))
+	})
+
+	It("rejects a replica which is not ready", func() {
+		status := makeStatus("0/5000000", false)
+		Expect(getSwitchoverRejectionReason(cluster, status, "cluster-example-2")).
+			To(ContainSubstring("not ready"))
+	})
+
+	It("rejects unknown instances", func() {
+		status := makeStatus("0/5000000", true)
+		Expect(getSwitchoverRejectionReason(cluster, status, "cluster-example-9")).
+			To(ContainSubstring("doesn't exist"))
+	})
+
+	It("rejects the current primary", func() {
+		status := makeStatus("0/5000000", true)
+		Expect(getSwitchoverRejectionReason(cluster, status, "cluster-example-1")).
+			To(ContainSubstring("already the primary"))
+	})
+
+	It("rejects switchovers in replica clusters", func() {
+		replicaCluster := cluster.DeepCopy()
+		replicaCluster.Spec.ReplicaCluster = &apiv1.ReplicaClusterConfiguration{Enabled: true, Source: "source"}
+		status := makeStatus("0/5000000", true)
+		Expect(getSwitchoverRejectionReason(replicaCluster, status, "cluster-example-2")).
+			To(ContainSubstring("replica clusters"))
+	})
+})
+
+var _ = Describe("switchover and failover progress", func() {
+	newCluster := func(phase, currentPrimary, targetPrimary string) *apiv1.Cluster {
+		return &apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Status: apiv1.ClusterStatus{
+				Phase:          phase,
+				CurrentPrimary: currentPrimary,
+				TargetPrimary:  targetPrimary,
+			},
+		}
+	}
+
+	registerProgress := func(cluster *apiv1.Cluster) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &ClusterReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build(),
+		}
+		Expect(reconciler.registerPrimaryChangeProgress(context.Background(), cluster)).To(Succeed())
+	}
+
+	It("reports the promotion of the target primary during a switchover", func() {
+		cluster := newCluster(apiv1.PhaseSwitchover, "cluster-1", "cluster-2")
+		registerProgress(cluster)
+		Expect(cluster.Status.Phase).To(Equal(apiv1.PhaseSwitchover))
+		Expect(cluster.Status.PhaseReason).To(ContainSubstring("former primary cluster-1 to shut down"))
+	})
+
+	It("reports the promotion of the target primary during a failover", func() {
+		cluster := newCluster(apiv1.PhaseFailOver, "cluster-1", "cluster-2")
+		registerProgress(cluster)
+		Expect(cluster.Status.Phase).To(Equal(apiv1.PhaseFailOver))
+		Expect(cluster.Status.PhaseReason).To(Equal(
+			"Waiting for cluster-2 to be promoted, replacing the failed primary cluster-1"))
+	})
+
+	It("reports the shutdown of the failing primary", func() {
+		cluster := newCluster(apiv1.PhaseFailOver, "cluster-1", apiv1.PendingFailoverMarker)
+		registerProgress(cluster)
+		Expect(cluster.Status.PhaseReason).To(ContainSubstring("failing primary cluster-1 to shut down"))
+	})
+
+	It("reports the instances following the new primary", func() {
+		cluster := newCluster(apiv1.PhaseFailOver, "cluster-2", "cluster-2")
+		registerProgress(cluster)
+		Expect(cluster.Status.Phase).To(Equal(apiv1.PhaseFailOver))
+		Expect(cluster.Status.PhaseReason).To(ContainSubstring("follow the new primary cluster-2"))
+	})
+
+	It("doesn't change the other phases", func() {
+		cluster := newCluster(apiv1.PhaseHealthy, "cluster-1", "cluster-1")
+		registerProgress(cluster)
+		Expect(cluster.Status.Phase).To(Equal(apiv1.PhaseHealthy))
+		Expect(cluster.Status.PhaseReason).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Replica cluster promotion", func() {
+	instancesStatus := postgres.PostgresqlStatusList{
+		Items: []postgres.PostgresqlStatus{
+			{
+				Pod:       corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-1"}},
+				IsPrimary: false,
+			},
+			{
+				Pod:       corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-2"}},
+				IsPrimary: false,
+			},
+		},
+	}
+
+	makeCluster := func(replicaCluster *apiv1.ReplicaClusterConfiguration) *apiv1.Cluster {
+		return &apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				ReplicaCluster: replicaCluster,
+			},
+			Status: apiv1.ClusterStatus{
+				CurrentPrimary: "cluster-example-1",
+				TargetPrimary:  "cluster-example-1",
+			},
+		}
+	}
+
+	It("is pending when the replica mode is disabled and the designated primary is a standby", func() {
+		cluster := makeCluster(&apiv1.ReplicaClusterConfiguration{Enabled: false, Source: "source"})
+		Expect(isReplicaClusterPromotionPending(cluster, instancesStatus)).To(BeTrue())
+	})
+
+	It("is not pending while the replica mode is enabled", func() {
+		cluster := makeCluster(&apiv1.ReplicaClusterConfiguration{Enabled: true, Source: "source"})
+		Expect(isReplicaClusterPromotionPending(cluster, instancesStatus)).To(BeFalse())
+	})
+
+	It("is not pending for clusters that have never been replica clusters", func() {
+		cluster := makeCluster(nil)
+		Expect(isReplicaClusterPromotionPending(cluster, instancesStatus)).To(BeFalse())
+	})
+
+	It("is not pending during a switchover", func() {
+		cluster := makeCluster(&apiv1.ReplicaClusterConfiguration{Enabled: false, Source: "source"})
+		cluster.Status.TargetPrimary = "cluster-example-2"
+		Expect(isReplicaClusterPromotionPending(cluster, instancesStatus)).To(BeFalse())
+	})
+
+	It("is not pending once the designated primary has been promoted", func() {
+		cluster := makeCluster(&apiv1.ReplicaClusterConfiguration{Enabled: false, Source: "source"})
+		promotedStatus := postgres.PostgresqlStatusList{
+			Items: []postgres.PostgresqlStatus{
+				{
+					Pod:       corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cluster-example-1"}},
+					IsPrimary: true,
+				},
+			},
+		}
+		Expect(isReplicaClusterPromotionPending(cluster, promotedStatus)).To(BeFalse())
+	})
+})
@@ -3,6 +3,7 @@ module github.com/cloudnative-pg/cloudnative-pg
 go 1.18
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.0
 	github.com/Masterminds/semver/v3 v3.1.1
 	github.com/avast/retry-go/v4 v4.3.0
 	github.com/blang/semver v3.5.1+incompatible
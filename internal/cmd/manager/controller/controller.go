@@ -24,6 +24,9 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"time"
+	// The time zone database is embedded to evaluate the maintenance
+	// windows, as the operator image may not ship one
+	_ "time/tzdata"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -34,10 +37,13 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/controllers"
 	"github.com/cloudnative-pg/cloudnative-pg/internal/configuration"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/webhook/credentials"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/certs"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/webserver"
@@ -79,12 +85,19 @@ const (
 	// The name of the directory containing the TLS certificates
 	defaultWebhookCertDir = "/run/secrets/cnpg.io/webhook"
 
+	// The name of the directory where the TLS certificates issued by
+	// cert-manager are written, which needs to be writable
+	certManagerWebhookCertDir = "/controller/webhook"
+
 	// LeaderElectionID The operator Leader Election ID
 	LeaderElectionID = "db9c8771.cnpg.io"
 
 	// CaSecretName is the name of the secret which is hosting the Operator CA
 	CaSecretName = "cnpg-ca-secret" // #nosec
 
+	// watchedNamespacesRefreshInterval is how often the operator configuration
+	// is read to detect changes in the set of the watched namespaces
+	watchedNamespacesRefreshInterval = 30 * time.Second
 )
 
 func init() {
@@ -149,7 +162,7 @@ func RunController(
 
 	if configuration.Current.WatchNamespace != "" {
 		namespaces := configuration.Current.WatchedNamespaces()
-		managerOptions.NewCache = multicache.DelegatingMultiNamespacedCacheBuilder(
+		managerOptions.NewCache = multicache.DynamicNamespacedCacheBuilder(
 			namespaces,
 			configuration.Current.OperatorNamespace)
 		setupLog.Info("Listening for changes", "watchNamespaces", namespaces)
@@ -191,6 +204,20 @@ func RunController(
 
 	setupLog.Info("Operator configuration loaded", "configuration", configuration.Current)
 
+	// The webhook server is not started yet, so we can still harden its
+	// TLS configuration using the loaded operator configuration
+	webhookTLSOptions := certs.WebhookTLSOptions{
+		EnableSessionTickets: configuration.Current.EnableWebhookTLSSessionTickets,
+	}
+	mgr.GetWebhookServer().TLSOpts = append(mgr.GetWebhookServer().TLSOpts, webhookTLSOptions.ApplyTo)
+
+	if configuration.Current.WebhookCertDir == "" && configuration.Current.WebhookCertManager {
+		// The certificates issued by cert-manager are written by the
+		// operator itself, and can't be placed in the read-only mount
+		// of the webhook secret
+		mgr.GetWebhookServer().CertDir = certManagerWebhookCertDir
+	}
+
 	discoveryClient, err := utils.GetDiscoveryClient()
 	if err != nil {
 		return err
@@ -212,7 +239,7 @@ func RunController(
 		"systemUID", utils.GetKubeSystemUID(),
 		"haveSCC", utils.HaveSecurityContextConstraints())
 
-	if err := ensurePKI(ctx, mgr.GetWebhookServer().CertDir); err != nil {
+	if err := ensurePKI(ctx, mgr); err != nil {
 		return err
 	}
 
@@ -268,6 +295,22 @@ func RunController(
 		return err
 	}
 
+	mgr.GetWebhookServer().Register(credentials.WebhookPath, &webhook.Admission{
+		Handler: credentials.NewValidator(mgr.GetAPIReader()),
+	})
+
+	// Keep the watched namespaces in sync with the operator configuration
+	if updater, ok := mgr.GetCache().(multicache.NamespacesUpdater); ok {
+		if err = mgr.Add(&watchedNamespacesUpdater{
+			updater:       updater,
+			configMapName: configMapName,
+			secretName:    secretName,
+		}); err != nil {
+			setupLog.Error(err, "unable to add the watched namespaces updater")
+			return err
+		}
+	}
+
 	// Setup the handler used by the readiness and liveliness probe.
 	//
 	// Unfortunately the readiness of the probe is not sufficient for the operator to be
@@ -294,6 +337,26 @@ func RunController(
 
 // loadConfiguration reads the configuration from the provided configmap and secret
 func loadConfiguration(ctx context.Context, configMapName string, secretName string) error {
+	configData, err := readConfigurationData(ctx, configMapName, secretName)
+	if err != nil {
+		return err
+	}
+
+	// Finally, read the config if it was provided
+	if len(configData) > 0 {
+		configuration.Current.ReadConfigMap(configData)
+	}
+
+	return nil
+}
+
+// readConfigurationData reads the content of the provided configmap and secret,
+// with the values in the secret overwriting the ones in the configmap
+func readConfigurationData(
+	ctx context.Context,
+	configMapName string,
+	secretName string,
+) (map[string]string, error) {
 	configData := make(map[string]string)
 
 	// First read the configmap if provided and store it in configData
@@ -303,7 +366,7 @@ func loadConfiguration(ctx context.Context, configMapName string, secretName str
 			setupLog.Error(err, "unable to read ConfigMap",
 				"namespace", configuration.Current.OperatorNamespace,
 				"name", configMapName)
-			return err
+			return nil, err
 		}
 		for k, v := range configMapData {
 			configData[k] = v
@@ -317,19 +380,70 @@ func loadConfiguration(ctx context.Context, configMapName string, secretName str
 			setupLog.Error(err, "unable to read Secret",
 				"namespace", configuration.Current.OperatorNamespace,
 				"name", secretName)
-			return err
+			return nil, err
 		}
 		for k, v := range secretData {
 			configData[k] = v
 		}
 	}
 
-	// Finally, read the config if it was provided
-	if len(configData) > 0 {
-		configuration.Current.ReadConfigMap(configData)
+	return configData, nil
+}
+
+// watchedNamespacesUpdater is a runnable keeping the namespaces watched by
+// the operator in sync with the WATCH_NAMESPACE option of its configuration,
+// so that namespaces can be added and removed without restarting the operator
+type watchedNamespacesUpdater struct {
+	updater       multicache.NamespacesUpdater
+	configMapName string
+	secretName    string
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, as
+// every replica of the operator needs to keep its own cache updated
+func (u *watchedNamespacesUpdater) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements the Runnable interface
+func (u *watchedNamespacesUpdater) Start(ctx context.Context) error {
+	ticker := time.NewTicker(watchedNamespacesRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		u.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
 	}
+}
 
-	return nil
+// refresh reads the operator configuration and applies the watched namespaces
+func (u *watchedNamespacesUpdater) refresh(ctx context.Context) {
+	configData, err := readConfigurationData(ctx, u.configMapName, u.secretName)
+	if err != nil {
+		setupLog.Warning("Cannot read the operator configuration to refresh the watched namespaces",
+			"err", err.Error())
+		return
+	}
+
+	config := configuration.NewConfiguration()
+	config.ReadConfigMap(configData)
+	namespaces := config.WatchedNamespaces()
+	if len(namespaces) == 0 {
+		setupLog.Warning("Watching all the namespaces requires restarting the operator, " +
+			"keeping the current set of watched namespaces")
+		return
+	}
+
+	if err := u.updater.SetNamespaces(ctx, namespaces); err != nil {
+		setupLog.Warning("Cannot update the watched namespaces",
+			"namespaces", namespaces,
+			"err", err.Error())
+	}
 }
 
 // readinessProbeHandler is used to implement the readiness probe handler
@@ -355,8 +469,9 @@ func createKubernetesClient(config *rest.Config) error {
 }
 
 // ensurePKI ensures that we have the required PKI infrastructure to make
-// the operator and the clusters working
-func ensurePKI(ctx context.Context, mgrCertDir string) error {
+// the operator and the clusters working, and registers its periodic
+// maintenance to be run by the leader
+func ensurePKI(ctx context.Context, mgr manager.Manager) error {
 	if configuration.Current.WebhookCertDir != "" {
 		// OLM is generating certificates for us, so we can avoid injecting/creating certificates.
 		return nil
@@ -366,7 +481,7 @@ func ensurePKI(ctx context.Context, mgrCertDir string) error {
 	// the webhooks configuration
 	pkiConfig := certs.PublicKeyInfrastructure{
 		CaSecretName:                       CaSecretName,
-		CertDir:                            mgrCertDir,
+		CertDir:                            mgr.GetWebhookServer().CertDir,
 		SecretName:                         WebhookSecretName,
 		ServiceName:                        WebhookServiceName,
 		OperatorNamespace:                  configuration.Current.OperatorNamespace,
@@ -378,14 +493,69 @@ func ensurePKI(ctx context.Context, mgrCertDir string) error {
 			"scheduledbackups.postgresql.cnpg.io",
 		},
 		OperatorDeploymentLabelSelector: "app.kubernetes.io/name=cloudnative-pg",
+		CertManager:                     configuration.Current.WebhookCertManager,
 	}
 	err := pkiConfig.Setup(ctx, clientSet, apiClientSet)
 	if err != nil {
 		setupLog.Error(err, "unable to setup PKI infrastructure")
+		return err
+	}
+
+	if pkiConfig.CertManager {
+		// cert-manager renews the webhook certificate, we only need
+		// to follow it
+		err = mgr.Add(&certManagerCertificateWatcher{pki: pkiConfig})
+		if err != nil {
+			setupLog.Error(err, "unable to watch the webhook certificate issued by cert-manager")
+		}
+		return err
+	}
+
+	err = mgr.Add(&certificatesMaintenance{pki: pkiConfig})
+	if err != nil {
+		setupLog.Error(err, "unable to schedule the PKI maintenance")
 	}
 	return err
 }
 
+// certificatesMaintenance periodically renews the certificates of the operator
+// and injects them into the webhook configurations and the CRDs
+type certificatesMaintenance struct {
+	pki certs.PublicKeyInfrastructure
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, as
+// only the leader must update the certificates, to avoid conflicting updates
+// between the replicas of the operator
+func (m *certificatesMaintenance) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements the Runnable interface. It is called by the manager
+// as soon as this replica of the operator is elected as leader
+func (m *certificatesMaintenance) Start(ctx context.Context) error {
+	setupLog.Info("Starting the periodic TLS certificates maintenance")
+	return m.pki.SchedulePeriodicMaintenance(ctx, clientSet, apiClientSet)
+}
+
+// certManagerCertificateWatcher keeps the webhook certificate issued by
+// cert-manager in sync with the webhook server of this operator instance
+type certManagerCertificateWatcher struct {
+	pki certs.PublicKeyInfrastructure
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, as
+// the webhook server of every replica of the operator needs the certificate
+func (w *certManagerCertificateWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements the Runnable interface
+func (w *certManagerCertificateWatcher) Start(ctx context.Context) error {
+	setupLog.Info("Watching the webhook certificate issued by cert-manager")
+	return w.pki.WatchCertManagerCertificate(ctx, clientSet, apiClientSet)
+}
+
 // readConfigMap reads the configMap and returns its content as map
 func readConfigMap(ctx context.Context, namespace, name string) (map[string]string, error) {
 	if name == "" {
@@ -396,7 +566,7 @@ func readConfigMap(ctx context.Context, namespace, name string) (map[string]stri
 		return nil, nil
 	}
 
-	setupLog.Info("Loading configuration from ConfigMap",
+	setupLog.Debug("Loading configuration from ConfigMap",
 		"namespace", namespace,
 		"name", name)
 
@@ -421,7 +591,7 @@ func readSecret(ctx context.Context, namespace, name string) (map[string]string,
 		return nil, nil
 	}
 
-	setupLog.Info("Loading configuration from Secret",
+	setupLog.Debug("Loading configuration from Secret",
 		"namespace", namespace,
 		"name", name)
 
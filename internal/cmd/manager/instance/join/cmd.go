@@ -90,13 +90,15 @@ func joinSubCommand(ctx context.Context, instance *postgres.Instance, info postg
 		return err
 	}
 
-	metricServer, err := metricserver.New(instance)
+	metricServer, err := metricserver.New(instance, false)
 	if err != nil {
 		return err
 	}
 	// Let's download the crypto material from the cluster
 	// secrets.
-	reconciler := controller.NewInstanceReconciler(instance, client, metricServer)
+	// Role transitions are never reported while joining, so we
+	// don't need an event recorder here
+	reconciler := controller.NewInstanceReconciler(instance, client, metricServer, nil)
 	if err != nil {
 		log.Error(err, "Error creating reconciler to download certificates")
 		return err
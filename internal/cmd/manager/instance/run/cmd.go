@@ -36,6 +36,8 @@ import (
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/internal/cmd/manager/instance/run/lifecycle"
 	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/maintenance"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/retention"
 	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/slots/runner"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/concurrency"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
@@ -44,6 +46,7 @@ import (
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/webserver"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/webserver/metricserver"
 	pg "github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/specs"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/versions"
 )
 
@@ -60,6 +63,7 @@ func NewCmd() *cobra.Command {
 	var podName string
 	var clusterName string
 	var namespace string
+	var metricsTLS bool
 
 	cmd := &cobra.Command{
 		Use: "run [flags]",
@@ -73,7 +77,7 @@ func NewCmd() *cobra.Command {
 			instance.ClusterName = clusterName
 
 			return retry.OnError(retry.DefaultRetry, isRunSubCommandRetryable, func() error {
-				return runSubCommand(ctx, instance)
+				return runSubCommand(ctx, instance, metricsTLS)
 			})
 		},
 	}
@@ -85,11 +89,13 @@ func NewCmd() *cobra.Command {
 		"current cluster in k8s, used to coordinate switchover and failover")
 	cmd.Flags().StringVar(&namespace, "namespace", os.Getenv("NAMESPACE"), "The namespace of "+
 		"the cluster and of the Pod in k8s")
+	cmd.Flags().BoolVar(&metricsTLS, "metrics-tls", os.Getenv(specs.MetricsTLSEnvVar) == "true",
+		"Serve the metrics over TLS using the server certificate")
 
 	return cmd
 }
 
-func runSubCommand(ctx context.Context, instance *postgres.Instance) error {
+func runSubCommand(ctx context.Context, instance *postgres.Instance, metricsTLS bool) error {
 	var err error
 	setupLog := log.WithName("setup")
 
@@ -120,7 +126,7 @@ func runSubCommand(ctx context.Context, instance *postgres.Instance) error {
 		return err
 	}
 
-	metricsServer, err := metricserver.New(instance)
+	metricsServer, err := metricserver.New(instance, metricsTLS)
 	if err != nil {
 		return err
 	}
@@ -128,7 +134,12 @@ func runSubCommand(ctx context.Context, instance *postgres.Instance) error {
 	postgresStartConditions := concurrency.MultipleExecuted{}
 	exitedConditions := concurrency.MultipleExecuted{}
 
-	reconciler := controller.NewInstanceReconciler(instance, mgr.GetClient(), metricsServer)
+	reconciler := controller.NewInstanceReconciler(
+		instance,
+		mgr.GetClient(),
+		metricsServer,
+		mgr.GetEventRecorderFor("instance-manager"),
+	)
 	err = ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1.Cluster{}).
 		Complete(reconciler)
@@ -179,12 +190,31 @@ func runSubCommand(ctx context.Context, instance *postgres.Instance) error {
 		return err
 	}
 
-	slotReplicator := runner.NewReplicator(instance)
+	slotReplicator := runner.NewReplicator(instance, metricsServer.GetExporter())
 	if err = mgr.Add(slotReplicator); err != nil {
 		setupLog.Error(err, "unable to create slot replicator")
 		return err
 	}
 
+	if err = mgr.Add(runner.NewOrphanReaper(instance, mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to create orphan replication slots reaper")
+		return err
+	}
+
+	if err = mgr.Add(maintenance.NewScheduler(instance, mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to create scheduled maintenance runner")
+		return err
+	}
+
+	if err = mgr.Add(retention.NewEnforcer(
+		instance,
+		mgr.GetClient(),
+		mgr.GetEventRecorderFor("instance-manager"),
+	)); err != nil {
+		setupLog.Error(err, "unable to create retention policy enforcer")
+		return err
+	}
+
 	// onlineUpgradeCtx is a child context of the postgres context.
 	// onlineUpgradeCtx will be the context passed to all the manager handled Runnables via Start(ctx),
 	// its deletion will imply all Runnables to stop, but will be handled
@@ -176,15 +176,16 @@ func (i *PostgresLifecycle) handleInstanceCommandRequests(
 	case postgres.FenceOn:
 		log.Info("Fencing request received, will proceed shutting down the instance")
 		i.instance.SetFencing(true)
-		err := tryShuttingDownSmartFast(i.instance.MaxStopDelay, i.instance)
+		err := tryShuttingDownForPlannedOperation(postgres.ShutdownModeSmart, i.instance.MaxStopDelay, i.instance)
 		if err != nil {
 			err = fmt.Errorf("while shutting down the instance to fence it: %w", err)
 		}
 		return false, err
 	case postgres.RestartSmartFast:
-		return true, tryShuttingDownSmartFast(i.instance.MaxStopDelay, i.instance)
+		return true, tryShuttingDownForPlannedOperation(postgres.ShutdownModeSmart, i.instance.MaxStopDelay, i.instance)
 	case postgres.ShutDownFastImmediate:
-		if err := tryShuttingDownFastImmediate(i.instance.MaxSwitchoverDelay, i.instance); err != nil {
+		err := tryShuttingDownForPlannedOperation(postgres.ShutdownModeFast, i.instance.MaxSwitchoverDelay, i.instance)
+		if err != nil {
 			log.Error(err, "error shutting down instance, proceeding")
 		}
 		return false, nil
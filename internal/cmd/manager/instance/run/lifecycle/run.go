@@ -247,13 +247,7 @@ func verifyPgDataCoherence(ctx context.Context, instance *postgres.Instance) err
 
 	contextLogger.Debug("Checking PGDATA coherence")
 
-	if err := fileutils.EnsurePgDataPerms(instance.PgData); err != nil {
-		return err
-	}
-
-	if err := postgres.WritePostgresUserMaps(instance.PgData); err != nil {
-		return err
-	}
-
-	return nil
+	// The pg_ident.conf file depends on the cluster configuration, and is
+	// written by the instance reconciler before PostgreSQL is started
+	return fileutils.EnsurePgDataPerms(instance.PgData)
 }
@@ -18,6 +18,7 @@ package lifecycle
 
 import (
 	"errors"
+	"fmt"
 	"os/exec"
 
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
@@ -72,3 +73,41 @@ func tryShuttingDownSmartFast(timeout int32, instance *postgres.Instance) error
 	}
 	return err
 }
+
+// tryShuttingDownWithMode shuts down the instance with the given mode,
+// falling back to the next faster mode in case of failure or when the
+// given timeout expires
+func tryShuttingDownWithMode(mode postgres.ShutdownMode, timeout int32, instance *postgres.Instance) error {
+	switch mode {
+	case postgres.ShutdownModeSmart:
+		return tryShuttingDownSmartFast(timeout, instance)
+	case postgres.ShutdownModeFast:
+		return tryShuttingDownFastImmediate(timeout, instance)
+	case postgres.ShutdownModeImmediate:
+		log.Info("Requesting immediate shutdown of the PostgreSQL instance")
+		return instance.Shutdown(postgres.ShutdownOptions{
+			Mode: postgres.ShutdownModeImmediate,
+			Wait: true,
+		})
+	default:
+		return fmt.Errorf("unknown shutdown mode: %s", mode)
+	}
+}
+
+// tryShuttingDownForPlannedOperation shuts down the instance during a planned
+// operation, using the shutdown mode and timeout requested by the user,
+// or the given defaults when they are not set
+func tryShuttingDownForPlannedOperation(
+	defaultMode postgres.ShutdownMode,
+	defaultTimeout int32,
+	instance *postgres.Instance,
+) error {
+	mode, timeout := defaultMode, defaultTimeout
+	if instance.ShutdownMode != "" {
+		mode = instance.ShutdownMode
+	}
+	if instance.ShutdownTimeout > 0 {
+		timeout = instance.ShutdownTimeout
+	}
+	return tryShuttingDownWithMode(mode, timeout, instance)
+}
@@ -31,18 +31,47 @@ import (
 
 // NewCmd create the "instance status" subcommand
 func NewCmd() *cobra.Command {
+	var settings, replicationSlots bool
+
 	cmd := &cobra.Command{
 		Use: "status",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return statusSubCommand()
+			if settings && replicationSlots {
+				return fmt.Errorf("--settings and --replication-slots are mutually exclusive")
+			}
+
+			path := url.PathPgStatus
+			switch {
+			case settings:
+				path = url.PathPgSettings
+			case replicationSlots:
+				path = url.PathPgReplicationSlots
+			}
+
+			return statusSubCommand(path)
 		},
 	}
 
+	cmd.Flags().BoolVar(&settings, "settings", false,
+		"Show the configuration parameters loaded by PostgreSQL instead of the instance status")
+	cmd.Flags().BoolVar(&replicationSlots, "replication-slots", false,
+		"Show the replication slots of the PostgreSQL instance instead of the instance status")
+
 	return cmd
 }
 
-func statusSubCommand() error {
-	statusURL := url.Local(url.PathPgStatus, url.StatusPort)
+// getStatusURL returns the URL of the instance manager endpoint serving the
+// passed path. The PostgreSQL settings are served only by the local
+// webserver, while everything else is served by the status one
+func getStatusURL(path string) string {
+	if path == url.PathPgSettings {
+		return url.Local(path, url.LocalPort)
+	}
+	return url.Local(path, url.StatusPort)
+}
+
+func statusSubCommand(path string) error {
+	statusURL := getStatusURL(path)
 	resp, err := http.Get(statusURL) // nolint:gosec
 	if err != nil {
 		log.Error(err, "Error while requesting instance status")
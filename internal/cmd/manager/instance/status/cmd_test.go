@@ -0,0 +1,36 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("instance status URL", func() {
+	It("requests the PostgreSQL settings to the local webserver", func() {
+		Expect(getStatusURL(url.PathPgSettings)).To(Equal("http://localhost:8010/pg/settings"))
+	})
+
+	It("requests the status and the replication slots to the status webserver", func() {
+		Expect(getStatusURL(url.PathPgStatus)).To(Equal(url.Local(url.PathPgStatus, url.StatusPort)))
+		Expect(getStatusURL(url.PathPgReplicationSlots)).
+			To(Equal(url.Local(url.PathPgReplicationSlots, url.StatusPort)))
+	})
+})
@@ -171,9 +171,13 @@ func run(ctx context.Context, podName, pgData string, args []string, client clie
 		}
 	}
 
-	options, err := barmanCloudWalArchiveOptions(cluster, cluster.Name)
+	options, err := barmanCloudWalArchiveOptions(cluster.Spec.Backup.BarmanObjectStore, cluster.Name)
+	if err == nil {
+		err = addAdditionalWALArchives(ctx, cluster, walArchiver)
+	}
 	if err != nil {
 		log.Error(err, "while getting barman-cloud-wal-archive options")
+		recordWALArchiveResult(ctx, err)
 		condition := metav1.Condition{
 			Type:    string(apiv1.ConditionContinuousArchiving),
 			Status:  metav1.ConditionFalse,
@@ -198,6 +202,13 @@ func run(ctx context.Context, podName, pgData string, args []string, client clie
 			"totalTime", time.Since(startTime))
 	}
 
+	if len(cluster.Spec.Backup.AdditionalWALArchives) > 0 {
+		err := archiver.RecordWALArchiveDestinationsResult(cluster.Spec.Backup.AdditionalWALArchives, walStatus)
+		if err != nil {
+			log.Error(err, "Error while recording the status of the additional WAL archives")
+		}
+	}
+
 	// Update the condition if needed.
 	condition := metav1.Condition{
 		Type:    string(apiv1.ConditionContinuousArchiving),
@@ -205,8 +216,19 @@ func run(ctx context.Context, podName, pgData string, args []string, client clie
 		Reason:  string(apiv1.ConditionReasonContinuousArchivingSuccess),
 		Message: "Continuous archiving is working",
 	}
+	failures := recordWALArchiveResult(ctx, walStatus[0].Err)
+	if maxFailures := cluster.GetWALArchiveMaxConsecutiveFailures(); maxFailures > 0 && failures >= maxFailures {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = string(apiv1.ConditionReasonContinuousArchivingFailing)
+		condition.Message = fmt.Sprintf("WAL archiving failed %d consecutive times: %v",
+			failures, walStatus[0].Err)
+	}
 	if errCond := conditions.Update(ctx, client, cluster, &condition); errCond != nil {
-		log.Error(errCond, "Error while updating wal archiving condition (wal archiving succeeded)")
+		if walStatus[0].Err != nil {
+			log.Error(errCond, "Error while updating wal archiving condition (wal archiving failed)")
+		} else {
+			log.Error(errCond, "Error while updating wal archiving condition (wal archiving succeeded)")
+		}
 	}
 	// We return only the first error to PostgreSQL, because the first error
 	// is the one raised by the file that PostgreSQL has requested to archive.
@@ -216,6 +238,16 @@ func run(ctx context.Context, podName, pgData string, args []string, client clie
 	return walStatus[0].Err
 }
 
+// recordWALArchiveResult updates the number of consecutive WAL archiving
+// failures after an attempt, returning the updated value
+func recordWALArchiveResult(ctx context.Context, archiveErr error) int {
+	failures, err := archiver.RecordWALArchiveResult(archiveErr)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Error while recording the number of consecutive WAL archiving failures")
+	}
+	return failures
+}
+
 // gatherWALFilesToArchive reads from the archived status the list of WAL files
 // that can be archived in parallel way.
 // `requestedWALFile` is the name of the file whose archiving was requested by
@@ -289,15 +321,54 @@ func gatherWALFilesToArchive(ctx context.Context, requestedWALFile string, paral
 	return walList
 }
 
-func barmanCloudWalArchiveOptions(
+// addAdditionalWALArchives adds to the archiver the additional object stores
+// where the WAL files should be archived. A missing environment is an error
+// only for the destinations that are not best-effort, which are skipped otherwise
+func addAdditionalWALArchives(
+	ctx context.Context,
 	cluster *apiv1.Cluster,
+	walArchiver *archiver.WALArchiver,
+) error {
+	contextLog := log.FromContext(ctx)
+
+	for i := range cluster.Spec.Backup.AdditionalWALArchives {
+		destination := &cluster.Spec.Backup.AdditionalWALArchives[i]
+
+		env, err := cacheClient.GetEnv(cache.WALArchiveDestinationKey(destination.Name))
+		if err != nil {
+			if destination.BestEffort {
+				contextLog.Warning("Skipping best-effort WAL archive destination, cannot get envs",
+					"destination", destination.Name,
+					"error", err)
+				continue
+			}
+			return fmt.Errorf("failed to get envs for WAL archive destination %s: %w", destination.Name, err)
+		}
+
+		options, err := barmanCloudWalArchiveOptions(&destination.BarmanObjectStore, cluster.Name)
+		if err != nil {
+			return fmt.Errorf("while getting options for WAL archive destination %s: %w", destination.Name, err)
+		}
+
+		walArchiver.AddDestination(archiver.Destination{
+			Name:       destination.Name,
+			Options:    options,
+			Env:        env,
+			BestEffort: destination.BestEffort,
+		})
+	}
+
+	return nil
+}
+
+func barmanCloudWalArchiveOptions(
+	configuration *apiv1.BarmanObjectStoreConfiguration,
 	clusterName string,
 ) ([]string, error) {
 	capabilities, err := barmanCapabilities.CurrentCapabilities()
 	if err != nil {
 		return nil, err
 	}
-	configuration := cluster.Spec.Backup.BarmanObjectStore
 
 	var options []string
 	if configuration.Wal != nil {
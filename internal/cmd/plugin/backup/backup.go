@@ -0,0 +1,120 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup implements a command to request an on-demand backup
+// for a PostgreSQL cluster
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/cmd/plugin"
+)
+
+// pollInterval is the time between two checks of the backup status
+const pollInterval = 2 * time.Second
+
+// Backup command implementation
+func Backup(ctx context.Context, clusterName, backupName string, wait bool, timeout time.Duration) error {
+	var cluster apiv1.Cluster
+	err := plugin.Client.Get(ctx, client.ObjectKey{Namespace: plugin.Namespace, Name: clusterName}, &cluster)
+	if err != nil {
+		return fmt.Errorf("cluster %s not found in namespace %s", clusterName, plugin.Namespace)
+	}
+
+	if cluster.Spec.Backup == nil || cluster.Spec.Backup.BarmanObjectStore == nil {
+		return fmt.Errorf("cluster %s has no backup configuration", clusterName)
+	}
+
+	backup := apiv1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: plugin.Namespace,
+			Name:      backupName,
+		},
+		Spec: apiv1.BackupSpec{
+			Cluster: apiv1.LocalObjectReference{
+				Name: clusterName,
+			},
+		},
+	}
+	if err := plugin.Client.Create(ctx, &backup); err != nil {
+		return fmt.Errorf("while creating backup %s: %w", backupName, err)
+	}
+	fmt.Printf("Backup %s requested for cluster %s\n", backupName, clusterName)
+
+	if !wait {
+		return nil
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return waitForBackup(ctx, plugin.Client, client.ObjectKeyFromObject(&backup), pollInterval, os.Stdout)
+}
+
+// waitForBackup follows the progress of a backup, printing every change of
+// its phase, until it is completed or failed. An error is returned if the
+// backup fails or the context is done before the backup is completed
+func waitForBackup(
+	ctx context.Context,
+	cli client.Client,
+	key client.ObjectKey,
+	interval time.Duration,
+	out io.Writer,
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastPhase apiv1.BackupPhase
+	for {
+		var backup apiv1.Backup
+		if err := cli.Get(ctx, key, &backup); err != nil {
+			return fmt.Errorf("while getting backup %s: %w", key.Name, err)
+		}
+
+		if backup.Status.Phase != lastPhase {
+			lastPhase = backup.Status.Phase
+			_, _ = fmt.Fprintf(out, "%s backup %s: %s\n",
+				time.Now().Format(time.RFC3339), key.Name, lastPhase)
+		}
+
+		switch backup.Status.Phase {
+		case apiv1.BackupPhaseCompleted:
+			_, _ = fmt.Fprintf(out, "Backup %s completed (backup ID: %s, WAL: %s - %s)\n",
+				key.Name, backup.Status.BackupID, backup.Status.BeginWal, backup.Status.EndWal)
+			return nil
+		case apiv1.BackupPhaseFailed:
+			return fmt.Errorf("backup %s failed: %s", key.Name, backup.Status.Error)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("while waiting for backup %s to complete: %w", key.Name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
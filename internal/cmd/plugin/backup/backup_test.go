@@ -0,0 +1,81 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("waiting for a backup", func() {
+	key := client.ObjectKey{Namespace: "default", Name: "cluster-example-backup"}
+
+	newClient := func(phase apiv1.BackupPhase, errorMessage string) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(&apiv1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Status: apiv1.BackupStatus{
+				Phase:    phase,
+				BackupID: "20230101T000000",
+				Error:    errorMessage,
+			},
+		}).Build()
+	}
+
+	It("succeeds when the backup is completed", func() {
+		var out bytes.Buffer
+		err := waitForBackup(context.TODO(), newClient(apiv1.BackupPhaseCompleted, ""), key, time.Millisecond, &out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("20230101T000000"))
+	})
+
+	It("fails when the backup is failed", func() {
+		var out bytes.Buffer
+		err := waitForBackup(context.TODO(), newClient(apiv1.BackupPhaseFailed, "no space left"), key,
+			time.Millisecond, &out)
+		Expect(err).To(MatchError(ContainSubstring("no space left")))
+	})
+
+	It("fails when the backup doesn't complete in time", func() {
+		var out bytes.Buffer
+		ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+		defer cancel()
+		err := waitForBackup(ctx, newClient(apiv1.BackupPhaseRunning, ""), key, time.Millisecond, &out)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(out.String()).To(ContainSubstring(apiv1.BackupPhaseRunning))
+	})
+
+	It("fails when the backup doesn't exist", func() {
+		var out bytes.Buffer
+		scheme := runtime.NewScheme()
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+		cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+		Expect(waitForBackup(context.TODO(), cli, key, time.Millisecond, &out)).ToNot(Succeed())
+	})
+})
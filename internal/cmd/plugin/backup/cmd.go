@@ -0,0 +1,72 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCmd creates the new "backup" subcommand
+func NewCmd() *cobra.Command {
+	var backupName string
+	var wait bool
+	var timeout time.Duration
+
+	backupCmd := &cobra.Command{
+		Use:   "backup [cluster]",
+		Short: "Request an on-demand backup for a PostgreSQL Cluster",
+		Long: `Create a Backup resource for the given cluster, which is started immediately.
+With --wait, the command follows the progress of the backup until it
+is completed or failed, failing in the latter case.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			clusterName := args[0]
+
+			if backupName == "" {
+				backupName = fmt.Sprintf("%s-%s", clusterName, time.Now().Format("20060102150405"))
+			}
+
+			return Backup(ctx, clusterName, backupName, wait, timeout)
+		},
+	}
+
+	backupCmd.Flags().StringVar(
+		&backupName,
+		"backup-name",
+		"",
+		"The name of the Backup resource that will be created, "+
+			"defaults to \"[cluster]-[current timestamp]\"",
+	)
+	backupCmd.Flags().BoolVar(
+		&wait,
+		"wait",
+		false,
+		"Wait for the backup to complete, reporting its progress",
+	)
+	backupCmd.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		0,
+		"The maximum time to wait for the backup to complete, no limit if not set",
+	)
+
+	return backupCmd
+}
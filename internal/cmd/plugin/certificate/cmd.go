@@ -18,6 +18,7 @@ package certificate
 
 import (
 	"context"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -64,5 +65,33 @@ connect to the PostgreSQL cluster.`,
 	certificateCmd.Flags().Bool(
 		"dry-run", false, "If specified, the secret is not created")
 
+	certificateCmd.AddCommand(newShowCmd())
+
 	return certificateCmd
 }
+
+// newShowCmd creates the "certificate show" subcommand
+func newShowCmd() *cobra.Command {
+	showCmd := &cobra.Command{
+		Use:   "show [cluster]",
+		Short: `Show the details and the expiration of the certificates used by a cluster`,
+		Long: `This command reads the CAs, the server certificate and the replication certificate
+used by the cluster, printing their subject, issuer, DNS names and expiration date,
+and flagging the ones expiring within the requested threshold.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			threshold, _ := cmd.Flags().GetDuration("expiring-within")
+			output, _ := cmd.Flags().GetString("output")
+
+			return Show(cmd.Context(), args[0], threshold, plugin.OutputFormat(output))
+		},
+	}
+
+	showCmd.Flags().Duration(
+		"expiring-within", 7*24*time.Hour,
+		"Flag the certificates expiring within this duration")
+	showCmd.Flags().StringP(
+		"output", "o", "text", "Output format. One of text|json|yaml")
+
+	return showCmd
+}
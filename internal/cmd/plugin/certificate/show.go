@@ -0,0 +1,221 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cheynewallace/tabby"
+	"github.com/logrusorgru/aurora/v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/cmd/plugin"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/certs"
+)
+
+// Details contains the details of a certificate used by a cluster
+type Details struct {
+	// The usage of the certificate inside the cluster
+	Usage string `json:"usage"`
+
+	// The name of the secret containing the certificate
+	SecretName string `json:"secretName"`
+
+	// The subject of the certificate
+	Subject string `json:"subject,omitempty"`
+
+	// The issuer of the certificate
+	Issuer string `json:"issuer,omitempty"`
+
+	// The DNS names included in the subject alternative names
+	DNSNames []string `json:"dnsNames,omitempty"`
+
+	// The expiration time of the certificate
+	NotAfter *time.Time `json:"notAfter,omitempty"`
+
+	// True when the certificate expires within the requested threshold,
+	// or is not valid yet
+	Expiring bool `json:"expiring"`
+
+	// The reason why the certificate couldn't be read
+	Error string `json:"error,omitempty"`
+}
+
+// certificateSource is a certificate to be inspected, with the secret
+// containing it
+type certificateSource struct {
+	usage      string
+	secretName string
+	isCA       bool
+}
+
+// Show prints the details of the certificates used by a cluster, flagging
+// the ones expiring within the passed threshold
+func Show(
+	ctx context.Context,
+	clusterName string,
+	threshold time.Duration,
+	format plugin.OutputFormat,
+) error {
+	var cluster apiv1.Cluster
+	err := plugin.Client.Get(ctx,
+		client.ObjectKey{Namespace: plugin.Namespace, Name: clusterName},
+		&cluster)
+	if err != nil {
+		return err
+	}
+
+	sources := []certificateSource{
+		{usage: "Server CA", secretName: cluster.GetServerCASecretName(), isCA: true},
+		{usage: "Server", secretName: cluster.GetServerTLSSecretName()},
+		{usage: "Client CA", secretName: cluster.GetClientCASecretName(), isCA: true},
+		{usage: "Replication", secretName: cluster.GetReplicationSecretName()},
+	}
+
+	details := make([]Details, 0, len(sources))
+	for _, source := range sources {
+		var secret corev1.Secret
+		err := plugin.Client.Get(ctx,
+			client.ObjectKey{Namespace: plugin.Namespace, Name: source.secretName},
+			&secret)
+		switch {
+		case apierrs.IsNotFound(err):
+			details = append(details, Details{
+				Usage:      source.usage,
+				SecretName: source.secretName,
+				Error:      "secret not found",
+			})
+		case err != nil:
+			return err
+		default:
+			details = append(details, describeCertificate(source, &secret, threshold))
+		}
+	}
+
+	if format != plugin.OutputFormatText {
+		return plugin.Print(details, format, os.Stdout)
+	}
+
+	printDetails(details)
+	return nil
+}
+
+// describeCertificate extracts the details of the certificate contained
+// in the passed secret
+func describeCertificate(source certificateSource, secret *corev1.Secret, threshold time.Duration) Details {
+	result := Details{
+		Usage:      source.usage,
+		SecretName: source.secretName,
+	}
+
+	pair, err := parseSecret(secret, source.isCA)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	certificate, err := pair.ParseCertificate()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	expiring, notAfter, err := pair.IsExpiringWithin(threshold)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Subject = certificate.Subject.String()
+	result.Issuer = certificate.Issuer.String()
+	result.DNSNames = certificate.DNSNames
+	result.NotAfter = notAfter
+	result.Expiring = expiring
+	return result
+}
+
+// parseSecret parses a secret containing a certificate. The private key of a
+// CA is not required, as user-provided CAs may be stored without it
+func parseSecret(secret *corev1.Secret, isCA bool) (*certs.KeyPair, error) {
+	if !isCA {
+		return certs.ParseServerSecret(secret)
+	}
+
+	if _, hasPrivateKey := secret.Data[certs.CAPrivateKeyKey]; hasPrivateKey {
+		return certs.ParseCASecret(secret)
+	}
+
+	certificate, ok := secret.Data[certs.CACertKey]
+	if !ok {
+		return nil, fmt.Errorf("missing %s secret data", certs.CACertKey)
+	}
+	return &certs.KeyPair{Certificate: certificate}, nil
+}
+
+// printDetails prints the details of the certificates in a human-readable way
+func printDetails(details []Details) {
+	table := tabby.New()
+	table.AddHeader("Usage", "Secret", "Subject", "Issuer", "DNS Names",
+		"Expiration Date", "Days Left Until Expiration")
+
+	hasExpiringCertificate := false
+	hasInvalidCertificate := false
+	for _, certificate := range details {
+		if certificate.Error != "" {
+			hasInvalidCertificate = true
+			table.AddLine(certificate.Usage, certificate.SecretName, "Error: "+certificate.Error, "", "", "", "")
+			continue
+		}
+
+		validityLeft := time.Until(*certificate.NotAfter)
+		validityLeftInDays := fmt.Sprintf("%.2f", validityLeft.Hours()/24)
+		switch {
+		case validityLeft < 0:
+			validityLeftInDays = "Expired"
+			hasInvalidCertificate = true
+		case certificate.Expiring:
+			validityLeftInDays += " - Expires Soon"
+			hasExpiringCertificate = true
+		}
+
+		table.AddLine(
+			certificate.Usage,
+			certificate.SecretName,
+			certificate.Subject,
+			certificate.Issuer,
+			strings.Join(certificate.DNSNames, ","),
+			certificate.NotAfter.String(),
+			validityLeftInDays)
+	}
+
+	color := aurora.Green
+	if hasInvalidCertificate {
+		color = aurora.Red
+	} else if hasExpiringCertificate {
+		color = aurora.Yellow
+	}
+
+	fmt.Println(color("Certificates"))
+	table.Print()
+}
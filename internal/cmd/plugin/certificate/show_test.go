@@ -0,0 +1,89 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/certs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("certificate details", func() {
+	var ca *certs.KeyPair
+
+	BeforeEach(func() {
+		var err error
+		ca, err = certs.CreateRootCA("cluster-example", "default")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("describes a server certificate", func() {
+		server, err := ca.CreateAndSignPair("cluster-example-rw", certs.CertTypeServer,
+			[]string{"cluster-example-rw", "cluster-example-rw.default"})
+		Expect(err).ToNot(HaveOccurred())
+		secret := server.GenerateCertificateSecret("default", "cluster-example-server")
+
+		details := describeCertificate(
+			certificateSource{usage: "Server", secretName: secret.Name},
+			secret,
+			7*24*time.Hour)
+		Expect(details.Error).To(BeEmpty())
+		Expect(details.Subject).To(ContainSubstring("CN=cluster-example-rw"))
+		Expect(details.Issuer).To(ContainSubstring("CN=cluster-example"))
+		Expect(details.DNSNames).To(ContainElements("cluster-example-rw", "cluster-example-rw.default"))
+		Expect(details.NotAfter).ToNot(BeNil())
+		Expect(details.Expiring).To(BeFalse())
+	})
+
+	It("flags a certificate expiring within the threshold", func() {
+		secret := ca.GenerateCASecret("default", "cluster-example-ca")
+
+		details := describeCertificate(
+			certificateSource{usage: "Server CA", secretName: secret.Name, isCA: true},
+			secret,
+			10*365*24*time.Hour)
+		Expect(details.Error).To(BeEmpty())
+		Expect(details.Expiring).To(BeTrue())
+	})
+
+	It("describes a CA stored without its private key", func() {
+		secret := &corev1.Secret{
+			Data: map[string][]byte{certs.CACertKey: ca.Certificate},
+		}
+
+		details := describeCertificate(
+			certificateSource{usage: "Client CA", secretName: "user-ca", isCA: true},
+			secret,
+			7*24*time.Hour)
+		Expect(details.Error).To(BeEmpty())
+		Expect(details.Subject).To(ContainSubstring("CN=cluster-example"))
+	})
+
+	It("reports a secret without a certificate", func() {
+		details := describeCertificate(
+			certificateSource{usage: "Server", secretName: "empty"},
+			&corev1.Secret{},
+			7*24*time.Hour)
+		Expect(details.Error).ToNot(BeEmpty())
+		Expect(details.NotAfter).To(BeNil())
+	})
+})
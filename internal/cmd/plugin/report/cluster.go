@@ -26,15 +26,16 @@ import (
 
 func clusterCmd() *cobra.Command {
 	var (
-		file, output string
-		includeLogs  bool
+		file, output     string
+		includeLogs      bool
+		includeInstances bool
 	)
 
 	const filePlaceholder = "report_cluster_<name>_<timestamp>.zip"
 
 	cmd := &cobra.Command{
 		Use:   "cluster <clusterName>",
-		Short: "Report cluster resources, pods, events, logs (opt-in)",
+		Short: "Report cluster resources, pods, events, logs and instances status (opt-in)",
 		Long:  "Collects combined information on the cluster in a Zip file",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -44,7 +45,7 @@ func clusterCmd() *cobra.Command {
 				file = reportName("cluster", now, clusterName) + ".zip"
 			}
 			return cluster(cmd.Context(), clusterName, plugin.Namespace,
-				plugin.OutputFormat(output), file, includeLogs, now)
+				plugin.OutputFormat(output), file, includeLogs, includeInstances, now)
 		},
 	}
 
@@ -53,6 +54,8 @@ func clusterCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&output, "output", "o", "yaml",
 		"Output format (yaml or json)")
 	cmd.Flags().BoolVarP(&includeLogs, "logs", "l", false, "include logs")
+	cmd.Flags().BoolVar(&includeInstances, "instances", false,
+		"include the status, the PostgreSQL settings and the replication slots of every instance")
 
 	return cmd
 }
@@ -77,8 +77,10 @@ func (cr clusterReport) writeToZip(zipper *zip.Writer, format plugin.OutputForma
 //   - events in the cluster namespace
 //   - logs from the cluster pods (optional - activated with `includeLogs`)
 //   - logs from the cluster jobs (optional - activated with `includeLogs`)
+//   - status, PostgreSQL settings and replication slots of every instance
+//     (optional - activated with `includeInstances`)
 func cluster(ctx context.Context, clusterName, namespace string, format plugin.OutputFormat,
-	file string, includeLogs bool, timestamp time.Time,
+	file string, includeLogs, includeInstances bool, timestamp time.Time,
 ) error {
 	var events corev1.EventList
 	err := plugin.Client.List(ctx, &events, client.InNamespace(namespace))
@@ -135,6 +137,14 @@ func cluster(ctx context.Context, clusterName, namespace string, format plugin.O
 		sections = append(sections, logsZipper, jobLogsZipper)
 	}
 
+	if includeInstances {
+		instancesZipper := func(zipper *zip.Writer, dirname string) error {
+			return streamInstancesStatusToZip(ctx, clusterName, format, dirname, zipper)
+		}
+
+		sections = append(sections, instancesZipper)
+	}
+
 	err = writeZippedReport(sections, file, reportName("cluster", timestamp, clusterName))
 	if err != nil {
 		return fmt.Errorf("could not write report: %w", err)
@@ -0,0 +1,155 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/cloudnative-pg/cloudnative-pg/internal/cmd/plugin"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/plugin/resources"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/specs"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+)
+
+// instanceCommandTimeout is the timeout applied to each command executed
+// in the instance pods
+const instanceCommandTimeout = 10 * time.Second
+
+// instanceReportSection is a piece of information extracted from an
+// instance by the `instance status` command of the instance manager
+type instanceReportSection struct {
+	name   string
+	flags  []string
+	parser func(stdout []byte) (interface{}, error)
+}
+
+var instanceReportSections = []instanceReportSection{
+	{
+		name: "status",
+		parser: func(stdout []byte) (interface{}, error) {
+			var status postgres.PostgresqlStatus
+			err := json.Unmarshal(stdout, &status)
+			return status, err
+		},
+	},
+	{
+		name:  "settings",
+		flags: []string{"--settings"},
+		parser: func(stdout []byte) (interface{}, error) {
+			var settings []postgres.PgSetting
+			if err := json.Unmarshal(stdout, &settings); err != nil {
+				return nil, err
+			}
+			return redactPgSettings(settings), nil
+		},
+	},
+	{
+		name:  "replication-slots",
+		flags: []string{"--replication-slots"},
+		parser: func(stdout []byte) (interface{}, error) {
+			var slots []postgres.PgReplicationSlot
+			err := json.Unmarshal(stdout, &slots)
+			return slots, err
+		},
+	},
+}
+
+// streamInstancesStatusToZip queries every pod of the cluster for its status,
+// its PostgreSQL settings and its replication slots, and writes them in a
+// folder per instance. Instances that cannot be reached don't stop the report:
+// the error is written in the ZIP in place of the missing information
+func streamInstancesStatusToZip(ctx context.Context, clusterName string, format plugin.OutputFormat,
+	dirname string, zipper *zip.Writer,
+) error {
+	pods, _, err := resources.GetInstancePods(ctx, clusterName)
+	if err != nil {
+		return fmt.Errorf("could not get cluster instances: %w", err)
+	}
+
+	instancesDir := filepath.Join(dirname, "instances")
+	if _, err = zipper.Create(instancesDir + "/"); err != nil {
+		return fmt.Errorf("could not add '%s' to zip: %w", instancesDir, err)
+	}
+
+	clientInterface, err := kubernetes.NewForConfig(plugin.Config)
+	if err != nil {
+		return fmt.Errorf("could not create the Kubernetes client: %w", err)
+	}
+
+	for _, pod := range pods {
+		if !utils.IsPodActive(pod) {
+			continue
+		}
+
+		podDir := filepath.Join(instancesDir, pod.Name)
+		if _, err := zipper.Create(podDir + "/"); err != nil {
+			return fmt.Errorf("could not add '%s' to zip: %w", podDir, err)
+		}
+
+		for _, section := range instanceReportSections {
+			content, err := execInstanceReportSection(ctx, clientInterface, pod, section)
+			if err != nil {
+				content = map[string]string{"error": err.Error()}
+			}
+
+			if err := addContentToZip(content, section.name, podDir, format, zipper); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// execInstanceReportSection runs the `instance status` command in the
+// PostgreSQL container of the pod, and parses its output
+func execInstanceReportSection(
+	ctx context.Context,
+	clientInterface kubernetes.Interface,
+	pod corev1.Pod,
+	section instanceReportSection,
+) (interface{}, error) {
+	timeout := instanceCommandTimeout
+	command := append([]string{"/controller/manager", "instance", "status"}, section.flags...)
+	stdout, _, err := utils.ExecCommand(
+		ctx,
+		clientInterface,
+		plugin.Config,
+		pod,
+		specs.PostgresContainerName,
+		&timeout,
+		command...)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the instance %s: %w", section.name, err)
+	}
+
+	content, err := section.parser([]byte(stdout))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the instance %s: %w", section.name, err)
+	}
+
+	return content, nil
+}
@@ -36,7 +36,9 @@ func getWebhooks(ctx context.Context, stopRedact bool) (
 		mWebhookConfig              v1.MutatingWebhookConfigurationList
 		vWebhookConfig              v1.ValidatingWebhookConfigurationList
 		mutatingWebhookNames        = []string{"mbackup.kb.io", "mcluster.kb.io", "mscheduledbackup.kb.io"}
-		validatingWebhookNames      = []string{"vbackup.kb.io", "vcluster.kb.io", "vpooler.kb.io", "vscheduledbackup.kb.io"}
+		validatingWebhookNames      = []string{
+			"vbackup.kb.io", "vcluster.kb.io", "vcredentials.kb.io", "vpooler.kb.io", "vscheduledbackup.kb.io",
+		}
 	)
 
 	if err := plugin.Client.List(ctx, &mutatingWebhookConfigList); err != nil {
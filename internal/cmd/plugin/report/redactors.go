@@ -17,8 +17,12 @@ limitations under the License.
 package report
 
 import (
+	"strings"
+
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 )
 
 // redactSecret creates a version of a Secret with only the Data map's KEYS
@@ -66,3 +70,24 @@ func redactWebhookClientConfig(config admissionv1.WebhookClientConfig) admission
 	}
 	return config
 }
+
+// isSensitivePgSetting checks whether the value of a PostgreSQL configuration
+// parameter may contain credentials
+func isSensitivePgSetting(name string) bool {
+	return name == "primary_conninfo" ||
+		strings.Contains(name, "password") ||
+		strings.Contains(name, "passphrase")
+}
+
+// redactPgSettings creates a copy of the PostgreSQL settings with the values
+// of the parameters which may contain credentials removed
+func redactPgSettings(settings []postgres.PgSetting) []postgres.PgSetting {
+	redacted := make([]postgres.PgSetting, len(settings))
+	for i, setting := range settings {
+		if isSensitivePgSetting(setting.Name) && setting.Setting != "" {
+			setting.Setting = "-"
+		}
+		redacted[i] = setting
+	}
+	return redacted
+}
@@ -20,6 +20,8 @@ import (
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -63,3 +65,25 @@ var _ = Describe("Redact WebhookClientConfig", func() {
 		Expect(redactedWebhookClientConfig.CABundle).Should(BeEmpty())
 	})
 })
+
+var _ = Describe("Redact PostgreSQL settings", func() {
+	It("should obfuscate only the parameters which may contain credentials", func() {
+		settings := []postgres.PgSetting{
+			{Name: "primary_conninfo", Setting: "host=cluster-example-rw password=secret"},
+			{Name: "ssl_passphrase_command", Setting: "echo secret"},
+			{Name: "shared_buffers", Setting: "16384", Unit: "8kB"},
+		}
+		redactedSettings := redactPgSettings(settings)
+
+		Expect(redactedSettings).To(HaveLen(3))
+		Expect(redactedSettings[0].Setting).To(Equal("-"))
+		Expect(redactedSettings[1].Setting).To(Equal("-"))
+		Expect(redactedSettings[2]).To(Equal(settings[2]))
+		Expect(settings[0].Setting).To(Equal("host=cluster-example-rw password=secret"))
+	})
+
+	It("should not fill in empty parameters", func() {
+		settings := []postgres.PgSetting{{Name: "primary_conninfo"}}
+		Expect(redactPgSettings(settings)[0].Setting).To(BeEmpty())
+	})
+})
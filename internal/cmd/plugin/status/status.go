@@ -23,6 +23,7 @@ import (
 	"os"
 	"path"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/cheynewallace/tabby"
@@ -80,6 +81,7 @@ func Status(ctx context.Context, clusterName string, verbose bool, format plugin
 	status.printCertificatesStatus()
 	status.printBackupStatus()
 	status.printReplicaStatus()
+	status.printReplicationSlotsStatus()
 	status.printInstancesStatus()
 
 	if nonFatalError != nil {
@@ -341,6 +343,58 @@ func (fullStatus *PostgresqlStatus) printReplicaStatus() {
 	fmt.Println()
 }
 
+func (fullStatus *PostgresqlStatus) printReplicationSlotsStatus() {
+	status := tabby.New()
+	status.AddHeader(
+		"Instance",
+		"Slot Name",
+		"Type",
+		"Active",
+		"Restart LSN",
+		"Retained WAL",
+	)
+
+	slotsFound := false
+	sort.Sort(fullStatus.InstanceStatus)
+	for _, instance := range fullStatus.InstanceStatus.Items {
+		for _, slot := range instance.ReplicationSlots {
+			slotsFound = true
+			status.AddLine(
+				instance.Pod.Name,
+				slot.SlotName,
+				slot.SlotType,
+				slot.Active,
+				slot.RestartLsn,
+				formatSize(slot.RetainedWALSize),
+			)
+		}
+	}
+
+	if !slotsFound {
+		return
+	}
+
+	fmt.Println(aurora.Green("Replication slots"))
+	status.Print()
+	fmt.Println()
+}
+
+// formatSize formats a size in bytes with the same units used
+// by the pg_size_pretty function of PostgreSQL
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d bytes", size)
+	}
+
+	divisor, exponent := int64(unit), 0
+	for n := size / unit; n >= unit && exponent < 4; n /= unit {
+		divisor *= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(divisor), "kMGTP"[exponent])
+}
+
 func (fullStatus *PostgresqlStatus) printInstancesStatus() {
 	//  Column "Replication role"
 	//  If instance is primary, print "Primary"
@@ -386,6 +440,10 @@ func (fullStatus *PostgresqlStatus) printInstancesStatus() {
 		statusMsg := "OK"
 		if instance.PendingRestart {
 			statusMsg += " (pending restart)"
+			if len(instance.PendingRestartSettings) > 0 {
+				statusMsg = fmt.Sprintf("OK (pending restart: %s)",
+					strings.Join(instance.PendingRestartSettings, ", "))
+			}
 		}
 
 		replicaRole := getReplicaRole(instance, fullStatus)
@@ -0,0 +1,115 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"io"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// captureStdout returns what the passed function writes to the standard output
+func captureStdout(f func()) string {
+	reader, writer, err := os.Pipe()
+	Expect(err).ToNot(HaveOccurred())
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	f()
+	Expect(writer.Close()).To(Succeed())
+
+	output, err := io.ReadAll(reader)
+	Expect(err).ToNot(HaveOccurred())
+	return string(output)
+}
+
+var _ = Describe("formatSize", func() {
+	It("uses bytes below one kilobyte", func() {
+		Expect(formatSize(0)).To(Equal("0 bytes"))
+		Expect(formatSize(1023)).To(Equal("1023 bytes"))
+	})
+
+	It("uses the largest unit fitting the size", func() {
+		Expect(formatSize(1024)).To(Equal("1.0 kB"))
+		Expect(formatSize(1536)).To(Equal("1.5 kB"))
+		Expect(formatSize(16 * 1024 * 1024)).To(Equal("16.0 MB"))
+		Expect(formatSize(3 * 1024 * 1024 * 1024)).To(Equal("3.0 GB"))
+		Expect(formatSize(2 * 1024 * 1024 * 1024 * 1024)).To(Equal("2.0 TB"))
+	})
+
+	It("doesn't go beyond petabytes", func() {
+		Expect(formatSize(2048 * 1024 * 1024 * 1024 * 1024 * 1024)).To(Equal("2048.0 PB"))
+	})
+})
+
+var _ = Describe("printReplicationSlotsStatus", func() {
+	instance := func(name string, slots ...postgres.PgReplicationSlot) postgres.PostgresqlStatus {
+		return postgres.PostgresqlStatus{
+			Pod:              corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}},
+			ReplicationSlots: slots,
+		}
+	}
+
+	It("prints nothing without replication slots", func() {
+		fullStatus := &PostgresqlStatus{
+			InstanceStatus: &postgres.PostgresqlStatusList{
+				Items: []postgres.PostgresqlStatus{instance("cluster-example-1")},
+			},
+		}
+		Expect(captureStdout(fullStatus.printReplicationSlotsStatus)).To(BeEmpty())
+	})
+
+	It("prints the replication slots of every instance", func() {
+		fullStatus := &PostgresqlStatus{
+			InstanceStatus: &postgres.PostgresqlStatusList{
+				Items: []postgres.PostgresqlStatus{
+					instance("cluster-example-1", postgres.PgReplicationSlot{
+						SlotName:        "_cnpg_cluster_example_2",
+						SlotType:        "physical",
+						Active:          true,
+						RestartLsn:      "0/3000060",
+						RetainedWALSize: 16 * 1024 * 1024,
+					}),
+					instance("cluster-example-2", postgres.PgReplicationSlot{
+						SlotName:        "logical_slot",
+						SlotType:        "logical",
+						RestartLsn:      "0/2000028",
+						RetainedWALSize: 512,
+					}),
+				},
+			},
+		}
+
+		output := captureStdout(fullStatus.printReplicationSlotsStatus)
+		Expect(output).To(ContainSubstring("Replication slots"))
+		Expect(output).To(MatchRegexp(
+			`cluster-example-1\s+_cnpg_cluster_example_2\s+physical\s+true\s+0/3000060\s+16.0 MB`))
+		Expect(output).To(MatchRegexp(
+			`cluster-example-2\s+logical_slot\s+logical\s+false\s+0/2000028\s+512 bytes`))
+	})
+})
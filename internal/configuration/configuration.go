@@ -39,6 +39,14 @@ type Data struct {
 	// need to written. This is different between plain Kubernetes and OpenShift
 	WebhookCertDir string `json:"webhookCertDir" env:"WEBHOOK_CERT_DIR"`
 
+	// WebhookCertManager is true when the certificate of the webhook server
+	// is issued by cert-manager, instead of being generated by the operator
+	WebhookCertManager bool `json:"webhookCertManager" env:"WEBHOOK_CERT_MANAGER"`
+
+	// EnableWebhookTLSSessionTickets enables the TLS session tickets in the
+	// webhook server, which are disabled by default
+	EnableWebhookTLSSessionTickets bool `json:"enableWebhookTLSSessionTickets" env:"ENABLE_WEBHOOK_TLS_SESSION_TICKETS"`
+
 	// WatchNamespace is the namespace where the operator should watch and
 	// is configurable via environment variables in the OpenShift console.
 	// Multiple namespaces can be specified separated by comma
@@ -84,6 +92,15 @@ type Data struct {
 	// MonitoringQueriesSecret is the name of the secret in the operator namespace which contain
 	// the monitoring queries. The queries will be read from the data key: "queries".
 	MonitoringQueriesSecret string `json:"monitoringQueriesSecret" env:"MONITORING_QUERIES_SECRET"`
+
+	// MaxConcurrentRollouts is the maximum number of clusters that can roll
+	// out their instances at the same time, zero meaning unlimited. Rollouts
+	// exceeding it are queued until a running one is completed
+	MaxConcurrentRollouts int `json:"maxConcurrentRollouts" env:"MAX_CONCURRENT_ROLLOUTS"`
+
+	// MinimumPostgresMajorVersion is the oldest PostgreSQL major version
+	// new clusters are allowed to run, zero meaning no limit
+	MinimumPostgresMajorVersion int `json:"minimumPostgresMajorVersion" env:"MINIMUM_POSTGRES_MAJOR_VERSION"`
 }
 
 // Current is the configuration used by the operator
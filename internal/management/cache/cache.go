@@ -35,6 +35,12 @@ const (
 
 var cache sync.Map
 
+// WALArchiveDestinationKey is the key to be used to access the cached envs
+// for wal-archive on the additional destination with the given name
+func WALArchiveDestinationKey(name string) string {
+	return WALArchiveKey + "-" + name
+}
+
 // Store write an object into the local cache
 func Store(c string, v interface{}) {
 	cache.Store(c, v)
@@ -109,5 +109,30 @@ func (r *InstanceReconciler) shouldUpdateWALArchiveSettingsCache(
 	}
 
 	cache.Store(cache.WALArchiveKey, envArchive)
+
+	// Populate the cache with the credentials of the additional WAL archives
+	for i := range cluster.Spec.Backup.AdditionalWALArchives {
+		destination := &cluster.Spec.Backup.AdditionalWALArchives[i]
+		envDestination, err := barmanCredentials.EnvSetBackupCloudCredentials(
+			ctx,
+			r.GetClient(),
+			cluster.Namespace,
+			&destination.BarmanObjectStore,
+			os.Environ())
+		if apierrors.IsForbidden(err) {
+			log.Info("additional WAL archive credentials don't yet have access permissions. "+
+				"Will retry reconciliation loop", "destination", destination.Name)
+			return true
+		}
+
+		if err != nil {
+			log.Error(err, "while getting additional WAL archive credentials",
+				"destination", destination.Name)
+			continue
+		}
+
+		cache.Store(cache.WALArchiveDestinationKey(destination.Name), envDestination)
+	}
+
 	return false
 }
@@ -0,0 +1,53 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+)
+
+// warmUpConnectionPool opens the connections requested by the user on the
+// connection pool of this instance, which has just been promoted, so that
+// the subsystems of the instance manager don't pay the connection setup
+// latency. A failure is only reported, as it doesn't affect the promotion
+func (r *InstanceReconciler) warmUpConnectionPool(ctx context.Context, cluster *apiv1.Cluster) {
+	warmUp := cluster.Spec.PostgresConfiguration.ConnectionWarmUp
+	if warmUp == nil || warmUp.Connections <= 0 {
+		return
+	}
+
+	contextLogger := log.FromContext(ctx)
+	contextLogger.Info("Warming up the connection pool",
+		"connections", warmUp.Connections,
+		"database", warmUp.GetDatabase())
+
+	err := r.instance.ConnectionPool().WarmUp(ctx, warmUp.GetDatabase(), int(warmUp.Connections))
+	if err == nil {
+		return
+	}
+
+	contextLogger.Warning("Cannot warm up the connection pool", "err", err)
+	if r.recorder != nil {
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ConnectionWarmUpFailed",
+			"Cannot warm up the connection pool on %s: %v", r.instance.PodName, err)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronrunner
+
+import (
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// scheduledRun is the next planned execution of a scheduled operation
+type scheduledRun struct {
+	// schedule is the cron schedule used to compute the next execution,
+	// used to detect when the user changes it
+	schedule string
+
+	// next is the time of the next execution
+	next time.Time
+}
+
+// A Plan contains the next planned executions of a set of
+// scheduled operations, identified by their name
+type Plan struct {
+	nextRuns map[string]scheduledRun
+}
+
+// NewPlan creates a new empty plan
+func NewPlan() *Plan {
+	return &Plan{nextRuns: make(map[string]scheduledRun)}
+}
+
+// Due returns whether the operation with the passed name and schedule is
+// due at the passed time, planning its next execution if needed. An
+// operation which is new, or whose schedule has been changed, is planned
+// but not reported as due. An operation with an invalid schedule is
+// removed from the plan
+func (p *Plan) Due(name, schedule string, now time.Time) (bool, error) {
+	parsedSchedule, err := cron.Parse(schedule)
+	if err != nil {
+		delete(p.nextRuns, name)
+		return false, err
+	}
+
+	run, found := p.nextRuns[name]
+	isPlanned := found && run.schedule == schedule
+	if isPlanned && now.Before(run.next) {
+		return false, nil
+	}
+
+	p.nextRuns[name] = scheduledRun{schedule: schedule, next: parsedSchedule.Next(now)}
+	return isPlanned, nil
+}
+
+// Retain removes from the plan every operation not in the passed list
+func (p *Plan) Retain(names ...string) {
+	declared := make(map[string]bool, len(names))
+	for _, name := range names {
+		declared[name] = true
+	}
+
+	for name := range p.nextRuns {
+		if !declared[name] {
+			delete(p.nextRuns, name)
+		}
+	}
+}
+
+// Reset removes every operation from the plan
+func (p *Plan) Reset() {
+	p.nextRuns = make(map[string]scheduledRun)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronrunner
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("scheduled operations planning", func() {
+	now := time.Date(2022, 10, 1, 10, 0, 0, 0, time.UTC)
+	nextNight := time.Date(2022, 10, 2, 3, 0, 0, 0, time.UTC)
+	const nightly = "0 0 3 * * *"
+
+	It("plans a new operation without running it", func() {
+		plan := NewPlan()
+		due, err := plan.Due("nightly", nightly, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(due).To(BeFalse())
+		Expect(plan.nextRuns["nightly"].next).To(Equal(nextNight))
+	})
+
+	It("runs the operation when due and plans the next execution", func() {
+		plan := NewPlan()
+		plan.nextRuns["nightly"] = scheduledRun{schedule: nightly, next: now.Add(-time.Minute)}
+		due, err := plan.Due("nightly", nightly, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(due).To(BeTrue())
+		Expect(plan.nextRuns["nightly"].next).To(Equal(nextNight))
+	})
+
+	It("waits for the planned execution", func() {
+		plan := NewPlan()
+		planned := scheduledRun{schedule: nightly, next: now.Add(time.Minute)}
+		plan.nextRuns["nightly"] = planned
+		due, err := plan.Due("nightly", nightly, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(due).To(BeFalse())
+		Expect(plan.nextRuns["nightly"]).To(Equal(planned))
+	})
+
+	It("plans again when the schedule changes", func() {
+		plan := NewPlan()
+		plan.nextRuns["nightly"] = scheduledRun{schedule: nightly, next: now.Add(-time.Minute)}
+		due, err := plan.Due("nightly", "0 0 4 * * *", now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(due).To(BeFalse())
+		Expect(plan.nextRuns["nightly"].next).To(Equal(time.Date(2022, 10, 2, 4, 0, 0, 0, time.UTC)))
+	})
+
+	It("rejects invalid schedules and forgets the operation", func() {
+		plan := NewPlan()
+		plan.nextRuns["nightly"] = scheduledRun{schedule: nightly, next: now.Add(-time.Minute)}
+		_, err := plan.Due("nightly", "every night", now)
+		Expect(err).To(HaveOccurred())
+		Expect(plan.nextRuns).To(BeEmpty())
+	})
+
+	It("forgets the operations which are not retained", func() {
+		plan := NewPlan()
+		plan.nextRuns["nightly"] = scheduledRun{schedule: nightly, next: now}
+		plan.nextRuns["weekly"] = scheduledRun{schedule: "0 0 2 * * 0", next: now}
+		plan.Retain("nightly")
+		Expect(plan.nextRuns).To(HaveLen(1))
+		Expect(plan.nextRuns).To(HaveKey("nightly"))
+
+		plan.Reset()
+		Expect(plan.nextRuns).To(BeEmpty())
+	})
+})
@@ -0,0 +1,80 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronrunner
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+)
+
+// checkInterval is how often the runner checks for the
+// operations to be executed
+const checkInterval = 10 * time.Second
+
+// A Job is the work periodically done by a runner
+type Job interface {
+	// Run executes the operations which are due. It is only
+	// called in the stable primary
+	Run(ctx context.Context, cluster *apiv1.Cluster) error
+
+	// Reset forgets the planned executions. It is called when the
+	// instance is not the stable primary: a replica may become the
+	// primary, and it needs to start from scratch in that case
+	Reset()
+}
+
+// Run periodically retrieves the cluster and runs the passed job if this
+// instance is its stable primary, until the context is cancelled
+func Run(ctx context.Context, name string, instance *postgres.Instance, cli client.Client, job Job) error {
+	contextLog := log.FromContext(ctx).WithName(name)
+
+	for {
+		select {
+		case <-ctx.Done():
+			contextLog.Info("Terminated scheduled operations loop")
+			return nil
+		case <-time.After(checkInterval):
+		}
+
+		var cluster apiv1.Cluster
+		err := cli.Get(
+			ctx,
+			client.ObjectKey{Namespace: instance.Namespace, Name: instance.ClusterName},
+			&cluster)
+		if err != nil {
+			contextLog.Warning("getting the cluster, skipping the scheduled operations check", "err", err)
+			continue
+		}
+
+		isStablePrimary := cluster.Status.CurrentPrimary == instance.PodName &&
+			cluster.Status.TargetPrimary == instance.PodName
+		if !isStablePrimary {
+			job.Reset()
+			continue
+		}
+
+		if err := job.Run(ctx, &cluster); err != nil {
+			contextLog.Warning("running the scheduled operations", "err", err)
+		}
+	}
+}
@@ -25,12 +25,14 @@ import (
 	"path"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/lib/pq"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -111,6 +113,9 @@ func (r *InstanceReconciler) Reconcile(
 	// Reconcile PostgreSQL instance parameters
 	r.reconcileInstance(cluster)
 
+	// Apply the log level of the cluster to this instance manager
+	r.reconcileLogLevel(ctx, cluster)
+
 	// Takes care of the `.check-empty-wal-archive` file inside the PGDATA
 	// which, if present, before running the WAL archiver verifies that
 	// the backup object store is empty. This file is created immediately
@@ -135,6 +140,9 @@ func (r *InstanceReconciler) Reconcile(
 		return reconcile.Result{}, err
 	}
 	reloadNeeded = reloadNeeded || reloadConfigNeeded
+	r.reportSynchronousStandbysChange(cluster)
+	r.reportSlowQueries(cluster)
+	r.reportWALArchiveFailures(cluster)
 
 	// here we execute initialization tasks that need to be executed only on the first reconciliation loop
 	if !r.firstReconcileDone.Load() {
@@ -170,10 +178,22 @@ func (r *InstanceReconciler) Reconcile(
 
 	r.configureSlotReplicator(cluster)
 
-	if result, err := reconciler.ReconcileReplicationSlots(
+	// The replication slots are not essential to this instance, so a failure
+	// managing them must not prevent it from being promoted
+	if err := r.reconcileReplicationSlotsUser(ctx, cluster); err != nil {
+		contextLogger.Error(err, "Cannot create the user managing the replication slots")
+	}
+
+	slotsPool, err := r.instance.ReplicationSlotsConnectionPool(ctx, cluster.Spec.ReplicationSlots)
+	if err == nil && cluster.IsReplicationSlotsHAEnabled() {
+		err = infrastructure.CheckPrivileges(ctx, slotsPool, cluster.Spec.ReplicationSlots.GetDatabase())
+	}
+	if err != nil {
+		contextLogger.Error(err, "Cannot manage the replication slots, skipping their reconciliation")
+	} else if result, err := reconciler.ReconcileReplicationSlots(
 		ctx,
 		r.instance.PodName,
-		infrastructure.NewPostgresManager(r.instance.ConnectionPool()),
+		infrastructure.NewPostgresManager(slotsPool, cluster.Spec.ReplicationSlots.GetDatabase()),
 		cluster,
 	); err != nil || !result.IsZero() {
 		return result, err
@@ -195,6 +215,8 @@ func (r *InstanceReconciler) Reconcile(
 		return reconcile.Result{RequeueAfter: time.Second}, nil
 	}
 
+	r.reportStartupRole(ctx, cluster)
+
 	restartedInplace, err := r.restartPrimaryInplaceIfRequested(ctx, cluster)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -221,6 +243,9 @@ func (r *InstanceReconciler) Reconcile(
 		return reconcile.Result{}, fmt.Errorf("cannot reconcile database configurations: %w", err)
 	}
 
+	r.checkWalCompressionAvailability(ctx, cluster)
+	r.reconcileReadinessProbe(ctx, cluster)
+
 	// Extremely important.
 	// It could happen that current primary is reconciled before all the topology is extracted by the operator.
 	// We should detect that and schedule the instance manager for another run otherwise we will end up having
@@ -238,6 +263,10 @@ func (r *InstanceReconciler) Reconcile(
 }
 
 func (r *InstanceReconciler) configureSlotReplicator(cluster *apiv1.Cluster) {
+	// The primary of a replica cluster is the designated one, which is in
+	// recovery, and the replicator needs to know it
+	r.instance.SetReplicaCluster(cluster.IsReplica())
+
 	// If PostgreSQL is older than 11 never start the SlotReplicator
 	psqlVersion, err := cluster.GetPostgresqlVersion()
 	if err != nil || psqlVersion < 110000 {
@@ -280,6 +309,12 @@ func (r *InstanceReconciler) refreshConfigurationFiles(
 		return false, err
 	}
 
+	reloadIdent, err := r.instance.RefreshPGIdent(cluster)
+	if err != nil {
+		return false, err
+	}
+	reloadNeeded = reloadNeeded || reloadIdent
+
 	// Reconcile PostgreSQL configuration
 	// This doesn't need the PG connection, but it needs to reload it in case of changes
 	reloadConfig, err := r.instance.RefreshConfigurationFilesFromCluster(cluster)
@@ -333,6 +368,10 @@ func (r *InstanceReconciler) initialize(ctx context.Context, cluster *apiv1.Clus
 		return err
 	}
 
+	if err := r.verifyCleanShutdownBeforeHibernation(ctx, cluster); err != nil {
+		return err
+	}
+
 	r.instance.SetFencing(cluster.IsInstanceFenced(r.instance.PodName))
 
 	return nil
@@ -431,6 +470,7 @@ func (r *InstanceReconciler) reconcileOldPrimary(
 	}
 
 	contextLogger.Info("This is an old primary node. Shutting it down to get it demoted to a replica")
+	r.recordRoleTransition(ctx, cluster, "Demoted", rolePrimary, roleReplica)
 
 	// Here we need to invoke a fast shutdown on the instance, and wait the instance
 	// manager to be stopped.
@@ -486,12 +526,21 @@ func (r *InstanceReconciler) reconcileDatabases(ctx context.Context, cluster *ap
 		}
 	}
 
+	// The auth_query of PgBouncer is ready only when its user and
+	// function have been checked in every database
+	integrations := cluster.Status.PoolerIntegrations
+	pgbouncerIntegrationReady := integrations != nil && len(integrations.PgBouncerIntegration.Secrets) > 0
+
 	databases, errors := r.getAllAccessibleDatabases(ctx, db)
+	if errors != nil {
+		pgbouncerIntegrationReady = false
+	}
 	for _, databaseName := range databases {
 		db, err := r.instance.ConnectionPool().Connection(databaseName)
 		if err != nil {
 			errors = append(errors,
 				fmt.Errorf("could not connect to database %s: %w", databaseName, err))
+			pgbouncerIntegrationReady = false
 			continue
 		}
 		if extensionStatusChanged {
@@ -503,8 +552,10 @@ func (r *InstanceReconciler) reconcileDatabases(ctx context.Context, cluster *ap
 		if err = r.reconcilePoolers(ctx, db, databaseName, cluster.Status.PoolerIntegrations); err != nil {
 			errors = append(errors,
 				fmt.Errorf("could not reconcile extensions for database %s: %w", databaseName, err))
+			pgbouncerIntegrationReady = false
 		}
 	}
+	r.instance.SetPgBouncerIntegrationReady(pgbouncerIntegrationReady)
 	if errors != nil {
 		return fmt.Errorf("got errors while reconciling databases: %v", errors)
 	}
@@ -517,6 +568,98 @@ func (r *InstanceReconciler) reconcileDatabases(ctx context.Context, cluster *ap
 	return nil
 }
 
+// checkWalCompressionAvailability verifies that the WAL compression method
+// requested by the user is available in the PostgreSQL binaries, raising
+// a warning otherwise. PostgreSQL would just refuse the new value and keep
+// using the previous one
+func (r *InstanceReconciler) checkWalCompressionAvailability(ctx context.Context, cluster *apiv1.Cluster) {
+	contextLogger := log.FromContext(ctx)
+
+	method := cluster.GetWALCompression()
+	if method == "" {
+		method = strings.ToLower(cluster.Spec.PostgresConfiguration.Parameters["wal_compression"])
+	}
+	if method == r.lastCheckedWalCompression {
+		return
+	}
+
+	switch method {
+	case "pglz", "lz4", "zstd":
+	default:
+		// Boolean values are supported by every build
+		r.lastCheckedWalCompression = method
+		return
+	}
+
+	db, err := r.instance.GetSuperUserDB()
+	if err != nil {
+		contextLogger.Warning("Cannot check the availability of the WAL compression method", "err", err)
+		return
+	}
+
+	var availableMethods []string
+	row := db.QueryRowContext(ctx, "SELECT COALESCE(enumvals, '{}') FROM pg_settings WHERE name = 'wal_compression'")
+	if err := row.Scan(pq.Array(&availableMethods)); err != nil {
+		contextLogger.Warning("Cannot check the availability of the WAL compression method", "err", err)
+		return
+	}
+	r.lastCheckedWalCompression = method
+
+	for _, availableMethod := range availableMethods {
+		if availableMethod == method {
+			return
+		}
+	}
+
+	contextLogger.Warning("The requested WAL compression method is not available in the container image",
+		"walCompression", method, "availableMethods", availableMethods)
+	if r.recorder != nil {
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "WalCompressionUnavailable",
+			"Instance %s: WAL compression method %s is not supported by the container image (available: %v)",
+			r.instance.PodName, method, availableMethods)
+	}
+}
+
+// reconcileReadinessProbe checks the readiness probe configuration of the
+// cluster against this instance until it succeeds. The configuration is
+// used by the readiness probe only when the check succeeds, otherwise the
+// default one is kept, as a database or a query that can't be used would
+// make the instance never ready. A failed check is retried at the next
+// reconciliation, as the database may just not exist yet, but reported
+// only once for every configuration
+func (r *InstanceReconciler) reconcileReadinessProbe(ctx context.Context, cluster *apiv1.Cluster) {
+	contextLogger := log.FromContext(ctx)
+
+	desired := apiv1.ProbesConfiguration{
+		Database: cluster.Spec.Probes.GetDatabase(),
+		Query:    cluster.Spec.Probes.GetQuery(),
+	}
+	if r.lastCheckedProbes != nil && *r.lastCheckedProbes == desired {
+		return
+	}
+
+	config := desired.DeepCopy()
+	if err := r.instance.CheckReadiness(ctx, config); err != nil {
+		if r.lastInvalidProbes == nil || *r.lastInvalidProbes != desired {
+			contextLogger.Warning("The readiness probe configuration can't be used, keeping the default one",
+				"database", config.Database, "query", config.Query, "err", err)
+			if r.recorder != nil {
+				r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ReadinessProbeConfigurationInvalid",
+					"Instance %s: cannot run the readiness probe on database %s, keeping the default one: %v",
+					r.instance.PodName, config.Database, err)
+			}
+			r.lastInvalidProbes = &desired
+		}
+		r.lastCheckedProbes = nil
+		r.instance.SetProbesConfiguration(nil)
+		return
+	}
+
+	r.lastCheckedProbes = &desired
+	r.lastInvalidProbes = nil
+	r.instance.SetProbesConfiguration(config)
+}
+
 // getAllAccessibleDatabases returns the list of all the accessible databases using the superuser
 func (r *InstanceReconciler) getAllAccessibleDatabases(
 	ctx context.Context,
@@ -577,6 +720,9 @@ func (r *InstanceReconciler) reconcileExtensions(
 		// a DDL when it is not really needed.
 
 		if !extension.SkipCreateExtension && extensionIsUsed && !extensionIsInstalled {
+			if err = checkExtensionIsAvailable(tx, extension.Name); err != nil {
+				break
+			}
 			_, err = tx.Exec(fmt.Sprintf("CREATE EXTENSION %s", extension.Name))
 		} else if !extensionIsUsed && extensionIsInstalled {
 			_, err = tx.Exec(fmt.Sprintf("DROP EXTENSION %s", extension.Name))
@@ -593,6 +739,29 @@ func (r *InstanceReconciler) reconcileExtensions(
 	return tx.Commit()
 }
 
+// checkExtensionIsAvailable verifies that the control file of the passed
+// extension is shipped with the PostgreSQL binaries, so that we can report
+// a meaningful error when the operand image doesn't contain it
+func checkExtensionIsAvailable(tx *sql.Tx, extensionName string) error {
+	row := tx.QueryRow("SELECT COUNT(*) > 0 FROM pg_available_extensions WHERE name = $1", extensionName)
+	if err := row.Err(); err != nil {
+		return err
+	}
+
+	var extensionIsAvailable bool
+	if err := row.Scan(&extensionIsAvailable); err != nil {
+		return err
+	}
+
+	if !extensionIsAvailable {
+		return fmt.Errorf("extension %s is required by the PostgreSQL configuration "+
+			"but is not available in the container image, please use an image that includes it "+
+			"or remove the corresponding parameters", extensionName)
+	}
+
+	return nil
+}
+
 // ReconcileExtensions reconciles the expected extensions for this
 // PostgreSQL instance
 func (r *InstanceReconciler) reconcilePoolers(
@@ -875,6 +1044,31 @@ func (r *InstanceReconciler) reconcileInstance(cluster *apiv1.Cluster) {
 	r.instance.PgCtlTimeoutForPromotion = cluster.GetPgCtlTimeoutForPromotion()
 	r.instance.MaxSwitchoverDelay = cluster.GetMaxSwitchoverDelay()
 	r.instance.MaxStopDelay = cluster.GetMaxStopDelay()
+	r.instance.ShutdownMode = postgresManagement.ShutdownMode(cluster.Spec.ShutdownMode)
+	r.instance.ShutdownTimeout = cluster.Spec.ShutdownTimeout
+}
+
+// reconcileLogLevel applies the log level requested in the cluster
+// specification to the running instance manager, without restarting it
+func (r *InstanceReconciler) reconcileLogLevel(ctx context.Context, cluster *apiv1.Cluster) {
+	desiredLevel := cluster.Spec.LogLevel
+	if desiredLevel == "" {
+		desiredLevel = log.DefaultLevelString
+	}
+
+	currentLevel := log.GetLogLevel()
+	if desiredLevel == currentLevel {
+		return
+	}
+
+	contextLogger := log.FromContext(ctx)
+	if !log.SetLogLevel(desiredLevel) {
+		contextLogger.Warning("Invalid log level, keeping the current one",
+			"logLevel", desiredLevel, "currentLogLevel", currentLevel)
+		return
+	}
+	contextLogger.Info("Changed the log level of the instance manager",
+		"logLevel", desiredLevel, "previousLogLevel", currentLevel)
 }
 
 func (r *InstanceReconciler) reconcileCheckWalArchiveFile(cluster *apiv1.Cluster) error {
@@ -922,6 +1116,10 @@ func (r *InstanceReconciler) waitForConfigurationReload(ctx context.Context, clu
 		)
 	}
 
+	// PostgreSQL already applied every parameter that can be changed with
+	// a reload, the remaining ones are reported with the pending_restart
+	// flag and are the only reason to restart the instance
+	r.recordConfigurationReload(cluster, status.PendingRestart, status.PendingRestartSettings)
 	if !status.PendingRestart {
 		// Everything fine
 		return nil
@@ -959,6 +1157,7 @@ func (r *InstanceReconciler) waitForConfigurationReload(ctx context.Context, clu
 	oldCluster := cluster.DeepCopy()
 	cluster.Status.Phase = phase
 	cluster.Status.PhaseReason = phaseReason
+	meta.SetStatusCondition(&cluster.Status.Conditions, apiv1.GetWaitingForUserCondition(phase, phaseReason))
 	return r.client.Status().Patch(ctx, cluster, client.MergeFrom(oldCluster))
 }
 
@@ -1073,10 +1272,20 @@ func (r *InstanceReconciler) reconcilePrimary(ctx context.Context, cluster *apiv
 
 	// If I'm not the primary, let's promote myself
 	if !isPrimary {
+		// If I was the designated primary of a replica cluster, we need
+		// to be sure that the source cluster is not a primary anymore
+		if cluster.Spec.ReplicaCluster != nil && !cluster.IsReplica() &&
+			cluster.Status.CurrentPrimary == r.instance.PodName {
+			if err := r.ensureReplicaClusterSourceIsNotPrimary(ctx, cluster); err != nil {
+				return false, err
+			}
+		}
+
 		cluster.LogTimestampsWithMessage(ctx, "Setting myself as primary")
 		if err := r.promoteAndWait(ctx, cluster); err != nil {
 			return false, err
 		}
+		r.recordRoleTransition(ctx, cluster, "Promoted", roleReplica, rolePrimary)
 		restarted = true
 	}
 
@@ -1093,6 +1302,8 @@ func (r *InstanceReconciler) reconcilePrimary(ctx context.Context, cluster *apiv
 			return restarted, err
 		}
 
+		r.warmUpConnectionPool(ctx, cluster)
+
 		cluster.LogTimestampsWithMessage(ctx, "Finished setting myself as primary")
 		return restarted, nil
 	}
@@ -1113,6 +1324,10 @@ func (r *InstanceReconciler) promoteAndWait(ctx context.Context, cluster *apiv1.
 		}
 	}
 
+	if err := r.runPrePromotionHook(ctx, cluster); err != nil {
+		return err
+	}
+
 	contextLogger.Info("I'm the target primary, applying WALs and promoting my instance")
 	// I must promote my instance here
 	err := r.instance.PromoteAndWait()
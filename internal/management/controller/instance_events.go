@@ -0,0 +1,269 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/barman/archiver"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/logpipe"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+)
+
+const (
+	// rolePrimary is the role of an instance accepting writes
+	rolePrimary = "primary"
+
+	// roleReplica is the role of an instance in continuous recovery
+	roleReplica = "replica"
+
+	// maxSlowQueryStatementLength is the maximum number of characters of
+	// the statement reported in a slow queries event
+	maxSlowQueryStatementLength = 256
+)
+
+// recordRoleTransition emits an event on the cluster to keep track of a
+// role change of this instance, together with the LSN at which it happened
+func (r *InstanceReconciler) recordRoleTransition(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	reason string,
+	oldRole string,
+	newRole string,
+) {
+	if r.recorder == nil {
+		return
+	}
+
+	r.recorder.Event(
+		cluster,
+		corev1.EventTypeNormal,
+		reason,
+		roleTransitionMessage(r.instance.PodName, oldRole, newRole, r.getCurrentLSN(ctx)))
+}
+
+// reportStartupRole emits an event the first time the instance is found
+// to be running as a replica since the instance manager has been started
+func (r *InstanceReconciler) reportStartupRole(ctx context.Context, cluster *apiv1.Cluster) {
+	if r.startupRoleReported {
+		return
+	}
+
+	isPrimary, err := r.instance.IsPrimary()
+	if err != nil {
+		return
+	}
+
+	if !isPrimary {
+		r.recordRoleTransition(ctx, cluster, "StartedAsReplica", "", roleReplica)
+	}
+	r.startupRoleReported = true
+}
+
+// recordConfigurationReload emits an event on the cluster reporting whether
+// the configuration of this instance has been fully applied by a reload or
+// some of the changed parameters are still waiting for a restart
+func (r *InstanceReconciler) recordConfigurationReload(
+	cluster *apiv1.Cluster,
+	pendingRestart bool,
+	pendingRestartSettings []string,
+) {
+	if r.recorder == nil {
+		return
+	}
+
+	reason := "ConfigurationReloaded"
+	if pendingRestart {
+		reason = "ConfigurationPendingRestart"
+	}
+
+	r.recorder.Event(
+		cluster,
+		corev1.EventTypeNormal,
+		reason,
+		configurationReloadMessage(r.instance.PodName, pendingRestart, pendingRestartSettings))
+}
+
+// reportSynchronousStandbysChange emits an event on the cluster when the
+// primary instance changes the set of its synchronous standbys, which is
+// recomputed from the ready replicas at every reconciliation loop
+func (r *InstanceReconciler) reportSynchronousStandbysChange(cluster *apiv1.Cluster) {
+	if cluster.Status.CurrentPrimary != r.instance.PodName {
+		r.lastSynchronousStandbyNames = nil
+		return
+	}
+
+	syncReplicas, electable := cluster.GetSyncReplicasData()
+	sort.Strings(electable)
+	synchronousStandbyNames := postgres.GetSynchronousStandbyNames(syncReplicas, electable)
+
+	previous := r.lastSynchronousStandbyNames
+	r.lastSynchronousStandbyNames = &synchronousStandbyNames
+	if previous == nil || *previous == synchronousStandbyNames || r.recorder == nil {
+		return
+	}
+
+	r.recorder.Event(
+		cluster,
+		corev1.EventTypeNormal,
+		"SynchronousStandbysChanged",
+		synchronousStandbysChangeMessage(r.instance.PodName, synchronousStandbyNames))
+}
+
+// reportSlowQueries emits an event on the cluster reporting the slow queries
+// logged by this instance since the previous event, when requested. Events
+// are sampled, so that at most one is emitted per sampling interval
+func (r *InstanceReconciler) reportSlowQueries(cluster *apiv1.Cluster) {
+	monitoring := cluster.Spec.Monitoring
+	if !monitoring.AreSlowQueryEventsEnabled() || r.recorder == nil {
+		// Discard the slow queries logged while the events are disabled,
+		// to avoid reporting them when the events get enabled
+		logpipe.TakeSlowQueriesSample()
+		return
+	}
+
+	if time.Since(r.lastSlowQueriesEvent) < monitoring.GetSlowQueryEventsSamplingInterval() {
+		return
+	}
+
+	sample := logpipe.TakeSlowQueriesSample()
+	if sample.Count == 0 {
+		return
+	}
+
+	r.lastSlowQueriesEvent = time.Now()
+	r.recorder.Event(
+		cluster,
+		corev1.EventTypeWarning,
+		"SlowQueries",
+		slowQueriesMessage(r.instance.PodName, sample))
+}
+
+// reportWALArchiveFailures emits a warning event when the number of
+// consecutive WAL archiving failures reaches the configured threshold.
+// A single event is emitted until WAL archiving recovers
+func (r *InstanceReconciler) reportWALArchiveFailures(cluster *apiv1.Cluster) {
+	maxFailures := cluster.GetWALArchiveMaxConsecutiveFailures()
+	if maxFailures == 0 || r.recorder == nil {
+		r.walArchiveFailuresReported = false
+		return
+	}
+
+	failures, err := archiver.GetWALArchiveConsecutiveFailures()
+	if err != nil {
+		log.Warning("Cannot read the number of consecutive WAL archiving failures", "err", err)
+		return
+	}
+
+	if failures < maxFailures {
+		r.walArchiveFailuresReported = false
+		return
+	}
+
+	if r.walArchiveFailuresReported {
+		return
+	}
+
+	r.walArchiveFailuresReported = true
+	r.recorder.Eventf(
+		cluster,
+		corev1.EventTypeWarning,
+		"WALArchivingFailing",
+		"WAL archiving on instance %s failed %d consecutive times, "+
+			"WAL files are accumulating on the volume",
+		r.instance.PodName, failures)
+}
+
+// getCurrentLSN gets the current WAL location of this instance, which is
+// the last replayed LSN for a replica. An empty string is returned when
+// the location can't be detected
+func (r *InstanceReconciler) getCurrentLSN(ctx context.Context) string {
+	db, err := r.instance.GetSuperUserDB()
+	if err != nil {
+		return ""
+	}
+
+	var lsn string
+	row := db.QueryRowContext(ctx,
+		"SELECT COALESCE(CASE WHEN pg_is_in_recovery() "+
+			"THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END::text, '')")
+	if err := row.Scan(&lsn); err != nil {
+		log.FromContext(ctx).Debug("Cannot detect the current LSN", "err", err)
+		return ""
+	}
+
+	return lsn
+}
+
+// roleTransitionMessage builds the message of a role transition event
+func roleTransitionMessage(podName, oldRole, newRole, lsn string) string {
+	message := fmt.Sprintf("Instance %s started as %s", podName, newRole)
+	if oldRole != "" {
+		message = fmt.Sprintf("Instance %s changed role from %s to %s", podName, oldRole, newRole)
+	}
+
+	if lsn != "" {
+		message = fmt.Sprintf("%s at LSN %s", message, lsn)
+	}
+
+	return message
+}
+
+// configurationReloadMessage builds the message of a configuration reload event
+func configurationReloadMessage(podName string, pendingRestart bool, pendingRestartSettings []string) string {
+	if !pendingRestart {
+		return fmt.Sprintf("Instance %s applied the new configuration without a restart", podName)
+	}
+
+	if len(pendingRestartSettings) == 0 {
+		return fmt.Sprintf("Instance %s reloaded the configuration, a restart is needed to apply it", podName)
+	}
+
+	return fmt.Sprintf("Instance %s reloaded the configuration, a restart is needed to apply: %s",
+		podName, strings.Join(pendingRestartSettings, ", "))
+}
+
+// synchronousStandbysChangeMessage builds the message of the event
+// raised when the synchronous standbys of the primary change
+func synchronousStandbysChangeMessage(podName, synchronousStandbyNames string) string {
+	if synchronousStandbyNames == "" {
+		return fmt.Sprintf("Instance %s doesn't wait for any synchronous standby", podName)
+	}
+
+	return fmt.Sprintf("Instance %s set synchronous_standby_names to %s", podName, synchronousStandbyNames)
+}
+
+// slowQueriesMessage builds the message of the event reporting
+// the slow queries logged by an instance
+func slowQueriesMessage(podName string, sample logpipe.SlowQueriesSample) string {
+	slowest := sample.Slowest
+	statement := slowest.Statement
+	if runes := []rune(statement); len(runes) > maxSlowQueryStatementLength {
+		statement = string(runes[:maxSlowQueryStatementLength]) + "..."
+	}
+
+	return fmt.Sprintf("Instance %s logged %d slow queries, the slowest took %s on database %q as user %q: %s",
+		podName, sample.Count, slowest.Duration, slowest.DatabaseName, slowest.Username, statement)
+}
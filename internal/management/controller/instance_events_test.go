@@ -0,0 +1,103 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/logpipe"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("role transition events", func() {
+	It("describes a promotion", func() {
+		Expect(roleTransitionMessage("cluster-example-2", roleReplica, rolePrimary, "0/3000060")).
+			To(Equal("Instance cluster-example-2 changed role from replica to primary at LSN 0/3000060"))
+	})
+
+	It("describes the role at startup", func() {
+		Expect(roleTransitionMessage("cluster-example-3", "", roleReplica, "0/3000060")).
+			To(Equal("Instance cluster-example-3 started as replica at LSN 0/3000060"))
+	})
+
+	It("omits the LSN when it is not known", func() {
+		Expect(roleTransitionMessage("cluster-example-1", rolePrimary, roleReplica, "")).
+			To(Equal("Instance cluster-example-1 changed role from primary to replica"))
+	})
+})
+
+var _ = Describe("configuration reload events", func() {
+	It("describes a configuration applied by a reload", func() {
+		Expect(configurationReloadMessage("cluster-example-1", false, nil)).
+			To(Equal("Instance cluster-example-1 applied the new configuration without a restart"))
+	})
+
+	It("lists the parameters waiting for a restart", func() {
+		Expect(configurationReloadMessage("cluster-example-1", true, []string{"max_connections", "shared_buffers"})).
+			To(Equal("Instance cluster-example-1 reloaded the configuration, " +
+				"a restart is needed to apply: max_connections, shared_buffers"))
+	})
+
+	It("describes a pending restart without known parameters", func() {
+		Expect(configurationReloadMessage("cluster-example-1", true, nil)).
+			To(Equal("Instance cluster-example-1 reloaded the configuration, a restart is needed to apply it"))
+	})
+})
+
+var _ = Describe("synchronous standbys events", func() {
+	It("describes the new synchronous standbys", func() {
+		Expect(synchronousStandbysChangeMessage("cluster-example-1", `ANY 1 ("cluster-example-2")`)).
+			To(Equal(`Instance cluster-example-1 set synchronous_standby_names to ANY 1 ("cluster-example-2")`))
+	})
+
+	It("describes the primary not waiting for synchronous standbys", func() {
+		Expect(synchronousStandbysChangeMessage("cluster-example-1", "")).
+			To(Equal("Instance cluster-example-1 doesn't wait for any synchronous standby"))
+	})
+})
+
+var _ = Describe("slow queries events", func() {
+	It("describes the slowest query of the sample", func() {
+		sample := logpipe.SlowQueriesSample{
+			Count: 3,
+			Slowest: &logpipe.SlowQuery{
+				Duration:     1500 * time.Millisecond,
+				DatabaseName: "app",
+				Username:     "app",
+				Statement:    "SELECT pg_sleep(1.5)",
+			},
+		}
+		Expect(slowQueriesMessage("cluster-example-1", sample)).
+			To(Equal(`Instance cluster-example-1 logged 3 slow queries, the slowest took 1.5s ` +
+				`on database "app" as user "app": SELECT pg_sleep(1.5)`))
+	})
+
+	It("truncates long statements", func() {
+		sample := logpipe.SlowQueriesSample{
+			Count: 1,
+			Slowest: &logpipe.SlowQuery{
+				Duration:  time.Second,
+				Statement: strings.Repeat("x", 1000),
+			},
+		}
+		Expect(slowQueriesMessage("cluster-example-1", sample)).
+			To(HaveSuffix(": " + strings.Repeat("x", maxSlowQueryStatementLength) + "..."))
+	})
+})
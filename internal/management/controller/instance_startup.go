@@ -18,6 +18,8 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/util/retry"
@@ -25,11 +27,18 @@ import (
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/controllers"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/certs"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	postgresManagement "github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
 	postgresSpec "github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 	pkgUtils "github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
+// ErrUncleanShutdownBeforeHibernation is raised when the former primary of
+// a cluster resumed from hibernation wasn't shut down cleanly
+var ErrUncleanShutdownBeforeHibernation = errors.New(
+	"the former primary was not shut down cleanly before the hibernation, refusing to start")
+
 // refreshServerCertificateFiles gets the latest server certificates files from the
 // secrets. Returns true if configuration has been changed
 func (r *InstanceReconciler) refreshServerCertificateFiles(ctx context.Context, cluster *apiv1.Cluster) (bool, error) {
@@ -136,6 +145,12 @@ func (r *InstanceReconciler) refreshBarmanEndpointCA(ctx context.Context, cluste
 		if err != nil {
 			return false, err
 		}
+		if data, ok := secret.Data[secretKeySelector.Key]; ok {
+			if err := certs.ValidateCABundle(data); err != nil {
+				return changed, fmt.Errorf("invalid barman endpoint CA in key %s of secret %s: %w",
+					secretKeySelector.Key, secret.Name, err)
+			}
+		}
 		c, err := r.refreshFileFromSecret(ctx, &secret, secretKeySelector.Key, target)
 		changed = changed || c
 		if err != nil {
@@ -253,3 +268,39 @@ func (r *InstanceReconciler) verifyPgDataCoherenceForPrimary(
 		return r.instance.Demote(cluster)
 	}
 }
+
+// verifyCleanShutdownBeforeHibernation refuses to start the former primary
+// of a cluster being resumed from hibernation when PostgreSQL wasn't shut
+// down cleanly, as the WAL records which were not sent to the replicas
+// could be lost, making the replicas diverge from the primary
+func (r *InstanceReconciler) verifyCleanShutdownBeforeHibernation(
+	ctx context.Context, cluster *apiv1.Cluster,
+) error {
+	if cluster.Status.HibernationResumePrimary != r.instance.PodName {
+		return nil
+	}
+
+	contextLogger := log.FromContext(ctx)
+
+	state, err := postgresManagement.GetDatabaseClusterStateThroughPgControldata(r.instance.PgData)
+	if err != nil {
+		return fmt.Errorf("while checking the state of the former primary: %w", err)
+	}
+
+	if postgresManagement.IsCleanShutdownState(state) {
+		contextLogger.Info("The former primary was shut down cleanly, resuming from hibernation",
+			"state", state)
+		return nil
+	}
+
+	contextLogger.Warning("The former primary was not shut down cleanly, refusing to resume from hibernation",
+		"state", state)
+	r.instance.LogPgControldata("unclean shutdown before hibernation")
+	if r.recorder != nil {
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "HibernationUncleanShutdown",
+			"The former primary %s was not shut down cleanly (state: %s), refusing to resume from hibernation",
+			r.instance.PodName, state)
+	}
+
+	return fmt.Errorf("%w: database cluster state is %q", ErrUncleanShutdownBeforeHibernation, state)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("clean shutdown check when resuming from hibernation", func() {
+	var (
+		cluster    *apiv1.Cluster
+		recorder   *record.FakeRecorder
+		reconciler *InstanceReconciler
+		binDir     string
+	)
+
+	// usePgControldataState installs a fake pg_controldata reporting
+	// the passed database cluster state
+	usePgControldataState := func(state string) {
+		script := fmt.Sprintf("#!/bin/sh\necho 'Database cluster state:               %s'\n", state)
+		Expect(os.WriteFile(path.Join(binDir, "pg_controldata"), []byte(script), 0o700)).To(Succeed()) // #nosec
+	}
+
+	BeforeEach(func() {
+		binDir = GinkgoT().TempDir()
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		cluster = &apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Status: apiv1.ClusterStatus{
+				CurrentPrimary:           "cluster-1",
+				TargetPrimary:            "cluster-1",
+				HibernationResumePrimary: "cluster-1",
+			},
+		}
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &InstanceReconciler{
+			instance: &postgres.Instance{Namespace: "default", PodName: "cluster-1", PgData: binDir},
+			recorder: recorder,
+		}
+	})
+
+	It("starts the former primary when it was shut down cleanly", func() {
+		usePgControldataState("shut down")
+		Expect(reconciler.verifyCleanShutdownBeforeHibernation(context.Background(), cluster)).To(Succeed())
+		Expect(recorder.Events).ToNot(Receive())
+	})
+
+	It("refuses to start the former primary when it was not shut down cleanly", func() {
+		usePgControldataState("in production")
+		err := reconciler.verifyCleanShutdownBeforeHibernation(context.Background(), cluster)
+		Expect(err).To(MatchError(ErrUncleanShutdownBeforeHibernation))
+		Expect(recorder.Events).To(Receive(ContainSubstring("HibernationUncleanShutdown")))
+	})
+
+	It("doesn't check the instances which are not being resumed", func() {
+		usePgControldataState("in production")
+		cluster.Status.HibernationResumePrimary = ""
+		Expect(reconciler.verifyCleanShutdownBeforeHibernation(context.Background(), cluster)).To(Succeed())
+
+		cluster.Status.HibernationResumePrimary = "cluster-2"
+		Expect(reconciler.verifyCleanShutdownBeforeHibernation(context.Background(), cluster)).To(Succeed())
+		Expect(recorder.Events).ToNot(Receive())
+	})
+})
@@ -0,0 +1,209 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/cronrunner"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+)
+
+// A Scheduler is a runner that periodically executes, in the primary,
+// the VACUUM and ANALYZE operations declared in the cluster spec,
+// reporting the outcome of their last execution in the cluster status
+type Scheduler struct {
+	instance *postgres.Instance
+	client   client.Client
+
+	// plan contains, for every scheduled maintenance, its next
+	// planned execution
+	plan *cronrunner.Plan
+}
+
+// NewScheduler creates a new scheduled maintenance runner
+func NewScheduler(instance *postgres.Instance, client client.Client) *Scheduler {
+	return &Scheduler{
+		instance: instance,
+		client:   client,
+		plan:     cronrunner.NewPlan(),
+	}
+}
+
+// Start starts running the scheduled maintenance runner
+func (s *Scheduler) Start(ctx context.Context) error {
+	return cronrunner.Run(ctx, "MaintenanceScheduler", s.instance, s.client, s)
+}
+
+// Reset forgets the planned executions of the scheduled maintenance
+func (s *Scheduler) Reset() {
+	s.plan.Reset()
+}
+
+// Run executes the scheduled maintenance operations which are due
+func (s *Scheduler) Run(ctx context.Context, cluster *apiv1.Cluster) error {
+	contextLog := log.FromContext(ctx).WithName("MaintenanceScheduler")
+
+	dueMaintenance := planMaintenance(ctx, cluster.Spec.ScheduledMaintenance, s.plan, time.Now())
+	if len(dueMaintenance) == 0 {
+		return nil
+	}
+
+	results := make([]apiv1.ScheduledMaintenanceStatus, 0, len(dueMaintenance))
+	for _, maintenance := range dueMaintenance {
+		contextLog.Info("Running scheduled maintenance",
+			"name", maintenance.Name,
+			"operation", maintenance.Operation,
+			"database", maintenance.Database)
+
+		startTime := time.Now()
+		err := s.execute(ctx, maintenance)
+		duration := time.Since(startTime)
+
+		result := apiv1.ScheduledMaintenanceStatus{
+			Name:            maintenance.Name,
+			LastRunTime:     startTime.UTC().Format(time.RFC3339),
+			LastRunDuration: duration.Round(time.Millisecond).String(),
+		}
+		if err != nil {
+			contextLog.Warning("scheduled maintenance failed", "name", maintenance.Name, "err", err)
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	origCluster := cluster.DeepCopy()
+	cluster.Status.ScheduledMaintenance = mergeMaintenanceStatus(
+		cluster.Spec.ScheduledMaintenance,
+		cluster.Status.ScheduledMaintenance,
+		results)
+	return s.client.Status().Patch(ctx, cluster, client.MergeFrom(origCluster))
+}
+
+// execute runs the statements of a scheduled maintenance in its database
+func (s *Scheduler) execute(ctx context.Context, maintenance apiv1.ScheduledMaintenance) error {
+	statements, err := buildMaintenanceStatements(maintenance)
+	if err != nil {
+		return err
+	}
+
+	db, err := s.instance.ConnectionPool().Connection(maintenance.Database)
+	if err != nil {
+		return fmt.Errorf("connecting to database %q: %w", maintenance.Database, err)
+	}
+
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("executing %q: %w", statement, err)
+		}
+	}
+
+	return nil
+}
+
+// planMaintenance returns the scheduled maintenance operations that are due
+// at the passed time, updating their next planned execution. Operations which
+// are new, or whose schedule has been changed, are planned but not returned
+func planMaintenance(
+	ctx context.Context,
+	scheduledMaintenance []apiv1.ScheduledMaintenance,
+	plan *cronrunner.Plan,
+	now time.Time,
+) []apiv1.ScheduledMaintenance {
+	contextLog := log.FromContext(ctx).WithName("planMaintenance")
+
+	var result []apiv1.ScheduledMaintenance
+	declared := make([]string, 0, len(scheduledMaintenance))
+	for _, maintenance := range scheduledMaintenance {
+		declared = append(declared, maintenance.Name)
+
+		due, err := plan.Due(maintenance.Name, maintenance.Schedule, now)
+		if err != nil {
+			contextLog.Warning("invalid schedule, skipping scheduled maintenance",
+				"name", maintenance.Name, "schedule", maintenance.Schedule, "err", err)
+			continue
+		}
+		if due {
+			result = append(result, maintenance)
+		}
+	}
+	plan.Retain(declared...)
+
+	return result
+}
+
+// buildMaintenanceStatements returns the SQL statements implementing
+// a scheduled maintenance, one for each of the requested tables
+func buildMaintenanceStatements(maintenance apiv1.ScheduledMaintenance) ([]string, error) {
+	var command string
+	switch maintenance.Operation {
+	case apiv1.MaintenanceOperationVacuum:
+		command = "VACUUM"
+	case apiv1.MaintenanceOperationAnalyze:
+		command = "ANALYZE"
+	case apiv1.MaintenanceOperationVacuumAnalyze:
+		command = "VACUUM (ANALYZE)"
+	default:
+		return nil, fmt.Errorf("unknown maintenance operation %q", maintenance.Operation)
+	}
+
+	if len(maintenance.Tables) == 0 {
+		return []string{command}, nil
+	}
+
+	statements := make([]string, 0, len(maintenance.Tables))
+	for _, table := range maintenance.Tables {
+		identifier := pgx.Identifier(strings.SplitN(table, ".", 2))
+		statements = append(statements, fmt.Sprintf("%s %s", command, identifier.Sanitize()))
+	}
+
+	return statements, nil
+}
+
+// mergeMaintenanceStatus updates the status of the scheduled maintenance
+// with the results of the latest executions, dropping the entries of the
+// operations which are not declared anymore
+func mergeMaintenanceStatus(
+	scheduledMaintenance []apiv1.ScheduledMaintenance,
+	currentStatus []apiv1.ScheduledMaintenanceStatus,
+	results []apiv1.ScheduledMaintenanceStatus,
+) []apiv1.ScheduledMaintenanceStatus {
+	statusByName := make(map[string]apiv1.ScheduledMaintenanceStatus, len(currentStatus)+len(results))
+	for _, status := range currentStatus {
+		statusByName[status.Name] = status
+	}
+	for _, status := range results {
+		statusByName[status.Name] = status
+	}
+
+	var merged []apiv1.ScheduledMaintenanceStatus
+	for _, maintenance := range scheduledMaintenance {
+		if status, found := statusByName[maintenance.Name]; found {
+			merged = append(merged, status)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,120 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/cronrunner"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("maintenance statements", func() {
+	It("processes the whole database when no table is specified", func() {
+		statements, err := buildMaintenanceStatements(apiv1.ScheduledMaintenance{
+			Operation: apiv1.MaintenanceOperationVacuumAnalyze,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(statements).To(Equal([]string{"VACUUM (ANALYZE)"}))
+	})
+
+	It("quotes the requested tables", func() {
+		statements, err := buildMaintenanceStatements(apiv1.ScheduledMaintenance{
+			Operation: apiv1.MaintenanceOperationAnalyze,
+			Tables:    []string{"orders", "sales.Items"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(statements).To(Equal([]string{
+			`ANALYZE "orders"`,
+			`ANALYZE "sales"."Items"`,
+		}))
+	})
+
+	It("rejects unknown operations", func() {
+		_, err := buildMaintenanceStatements(apiv1.ScheduledMaintenance{Operation: "reindex"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("maintenance planning", func() {
+	ctx := context.Background()
+	now := time.Date(2022, 10, 1, 10, 0, 0, 0, time.UTC)
+	nightly := apiv1.ScheduledMaintenance{
+		Name:      "nightly",
+		Schedule:  "0 0 2 * * *",
+		Operation: apiv1.MaintenanceOperationVacuum,
+		Database:  "app",
+	}
+
+	nextNight := time.Date(2022, 10, 2, 2, 0, 0, 0, time.UTC)
+
+	It("plans new operations without running them", func() {
+		plan := cronrunner.NewPlan()
+		Expect(planMaintenance(ctx, []apiv1.ScheduledMaintenance{nightly}, plan, now)).To(BeEmpty())
+		Expect(planMaintenance(ctx, []apiv1.ScheduledMaintenance{nightly}, plan, nextNight.Add(-time.Second))).
+			To(BeEmpty())
+	})
+
+	It("runs the operations when they are due and plans the next execution", func() {
+		plan := cronrunner.NewPlan()
+		Expect(planMaintenance(ctx, []apiv1.ScheduledMaintenance{nightly}, plan, now)).To(BeEmpty())
+		Expect(planMaintenance(ctx, []apiv1.ScheduledMaintenance{nightly}, plan, nextNight)).
+			To(Equal([]apiv1.ScheduledMaintenance{nightly}))
+		Expect(planMaintenance(ctx, []apiv1.ScheduledMaintenance{nightly}, plan, nextNight.Add(time.Hour))).
+			To(BeEmpty())
+	})
+
+	It("plans again the operations whose schedule has changed", func() {
+		plan := cronrunner.NewPlan()
+		changed := nightly
+		changed.Schedule = "0 0 3 * * *"
+		Expect(planMaintenance(ctx, []apiv1.ScheduledMaintenance{changed}, plan, now)).To(BeEmpty())
+		Expect(planMaintenance(ctx, []apiv1.ScheduledMaintenance{nightly}, plan, nextNight.Add(2*time.Hour))).
+			To(BeEmpty())
+	})
+
+	It("forgets the operations which are not declared anymore", func() {
+		plan := cronrunner.NewPlan()
+		Expect(planMaintenance(ctx, []apiv1.ScheduledMaintenance{nightly}, plan, now)).To(BeEmpty())
+		Expect(planMaintenance(ctx, nil, plan, now)).To(BeEmpty())
+		Expect(planMaintenance(ctx, []apiv1.ScheduledMaintenance{nightly}, plan, nextNight)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("maintenance status", func() {
+	It("updates the executed operations and drops the undeclared ones", func() {
+		merged := mergeMaintenanceStatus(
+			[]apiv1.ScheduledMaintenance{{Name: "nightly"}, {Name: "hourly"}},
+			[]apiv1.ScheduledMaintenanceStatus{
+				{Name: "nightly", LastRunTime: "2022-10-01T02:00:00Z"},
+				{Name: "hourly", LastRunTime: "2022-10-01T09:00:00Z"},
+				{Name: "weekly", LastRunTime: "2022-09-25T02:00:00Z"},
+			},
+			[]apiv1.ScheduledMaintenanceStatus{
+				{Name: "hourly", LastRunTime: "2022-10-01T10:00:00Z", LastRunDuration: "1.5s"},
+			},
+		)
+		Expect(merged).To(Equal([]apiv1.ScheduledMaintenanceStatus{
+			{Name: "nightly", LastRunTime: "2022-10-01T02:00:00Z"},
+			{Name: "hourly", LastRunTime: "2022-10-01T10:00:00Z", LastRunDuration: "1.5s"},
+		}))
+	})
+})
@@ -21,10 +21,12 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/atomic"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
@@ -39,6 +41,7 @@ import (
 type InstanceReconciler struct {
 	client   ctrl.Client
 	instance *postgres.Instance
+	recorder record.EventRecorder
 
 	secretVersions  map[string]string
 	extensionStatus map[string]bool
@@ -46,6 +49,35 @@ type InstanceReconciler struct {
 	systemInitialization  *concurrency.Executed
 	firstReconcileDone    atomic.Bool
 	metricsServerExporter *metricserver.Exporter
+
+	// startupRoleReported is true when the role of the instance
+	// has already been reported after the instance manager started
+	startupRoleReported bool
+
+	// lastCheckedWalCompression is the last value of wal_compression
+	// whose availability has been checked against the binaries
+	lastCheckedWalCompression string
+
+	// lastCheckedProbes is the last readiness probe configuration
+	// that has been successfully checked against the instance
+	lastCheckedProbes *apiv1.ProbesConfiguration
+
+	// lastInvalidProbes is the last readiness probe configuration
+	// whose check failed, and which has already been reported
+	lastInvalidProbes *apiv1.ProbesConfiguration
+
+	// lastSynchronousStandbyNames is the last value of synchronous_standby_names
+	// computed while this instance was the primary
+	lastSynchronousStandbyNames *string
+
+	// lastSlowQueriesEvent is the time when the last event reporting
+	// the slow queries logged by this instance has been emitted
+	lastSlowQueriesEvent time.Time
+
+	// walArchiveFailuresReported is true when the event reporting that WAL
+	// archiving exceeded the maximum number of consecutive failures has been
+	// emitted, and WAL archiving hasn't recovered since then
+	walArchiveFailuresReported bool
 }
 
 // NewInstanceReconciler creates a new instance reconciler
@@ -53,10 +85,12 @@ func NewInstanceReconciler(
 	instance *postgres.Instance,
 	client ctrl.Client,
 	server *metricserver.MetricsServer,
+	recorder record.EventRecorder,
 ) *InstanceReconciler {
 	return &InstanceReconciler{
 		instance:              instance,
 		client:                client,
+		recorder:              recorder,
 		secretVersions:        make(map[string]string),
 		extensionStatus:       make(map[string]bool),
 		systemInitialization:  concurrency.NewExecuted(),
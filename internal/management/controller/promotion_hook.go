@@ -0,0 +1,139 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/conditions"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+)
+
+// runPrePromotionHook runs the SQL statements requested by the user on this
+// instance, just before promoting it. An error is returned only when the
+// statements failed and the user requested to abort the promotion in that case
+func (r *InstanceReconciler) runPrePromotionHook(ctx context.Context, cluster *apiv1.Cluster) error {
+	hook := cluster.Spec.PostgresConfiguration.PrePromotionHook
+	if hook == nil || len(hook.SQL) == 0 {
+		return nil
+	}
+
+	contextLogger := log.FromContext(ctx)
+	contextLogger.Info("Running the pre-promotion hook", "statements", len(hook.SQL))
+
+	db, err := r.instance.GetSuperUserDB()
+	if err == nil {
+		err = executePrePromotionHook(ctx, db, hook)
+	}
+
+	return r.reportPrePromotionHook(ctx, cluster, hook, err)
+}
+
+// reportPrePromotionHook reports the outcome of the pre-promotion hook
+// through an event and the PrePromotionHookSucceeded condition of the
+// cluster, returning an error if the promotion must be aborted
+func (r *InstanceReconciler) reportPrePromotionHook(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	hook *apiv1.PrePromotionHookConfiguration,
+	hookErr error,
+) error {
+	contextLogger := log.FromContext(ctx)
+
+	if hookErr != nil {
+		contextLogger.Error(hookErr, "Pre-promotion hook failed", "abortOnFailure", hook.AbortOnFailure)
+		message := fmt.Sprintf("Pre-promotion hook failed on %s: %v", r.instance.PodName, hookErr)
+		r.recordPrePromotionHookEvent(cluster, corev1.EventTypeWarning, "PrePromotionHookFailed", message)
+		r.updatePrePromotionHookCondition(ctx, cluster, metav1.ConditionFalse,
+			apiv1.ConditionReasonPrePromotionHookFailed, message)
+		if hook.AbortOnFailure {
+			return fmt.Errorf("pre-promotion hook failed, aborting the promotion: %w", hookErr)
+		}
+		return nil
+	}
+
+	message := fmt.Sprintf("Pre-promotion hook completed on %s", r.instance.PodName)
+	r.recordPrePromotionHookEvent(cluster, corev1.EventTypeNormal, "PrePromotionHookCompleted", message)
+	r.updatePrePromotionHookCondition(ctx, cluster, metav1.ConditionTrue,
+		apiv1.ConditionReasonPrePromotionHookCompleted, message)
+	return nil
+}
+
+// executePrePromotionHook runs the statements of the pre-promotion hook,
+// stopping at the first failure or when the timeout expires
+func executePrePromotionHook(
+	ctx context.Context,
+	db *sql.DB,
+	hook *apiv1.PrePromotionHookConfiguration,
+) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, hook.GetTimeout())
+	defer cancel()
+
+	for idx, statement := range hook.SQL {
+		if _, err := db.ExecContext(timeoutCtx, statement); err != nil {
+			return fmt.Errorf("while running statement %d: %w", idx+1, err)
+		}
+	}
+
+	return nil
+}
+
+// recordPrePromotionHookEvent emits an event on the cluster reporting
+// the execution of the pre-promotion hook
+func (r *InstanceReconciler) recordPrePromotionHookEvent(
+	cluster *apiv1.Cluster,
+	eventType string,
+	reason string,
+	message string,
+) {
+	if r.recorder == nil {
+		return
+	}
+
+	r.recorder.Event(cluster, eventType, reason, message)
+}
+
+// updatePrePromotionHookCondition sets the PrePromotionHookSucceeded
+// condition of the cluster. A failure is only logged, as it must not
+// interfere with the promotion
+func (r *InstanceReconciler) updatePrePromotionHookCondition(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+	status metav1.ConditionStatus,
+	reason apiv1.ConditionReason,
+	message string,
+) {
+	if r.client == nil {
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:    string(apiv1.ConditionPrePromotionHook),
+		Status:  status,
+		Reason:  string(reason),
+		Message: message,
+	}
+	if err := conditions.Update(ctx, r.client, cluster, &condition); err != nil {
+		log.FromContext(ctx).Error(err, "Cannot update the pre-promotion hook condition")
+	}
+}
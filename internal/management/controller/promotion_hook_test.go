@@ -0,0 +1,154 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("pre-promotion hook execution", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("runs the statements in the given order", func() {
+		hook := &apiv1.PrePromotionHookConfiguration{
+			SQL: []string{"SELECT 1", "SELECT 2", "SELECT 3"},
+		}
+		mock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("SELECT 2").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("SELECT 3").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		Expect(executePrePromotionHook(context.Background(), db, hook)).To(Succeed())
+	})
+
+	It("stops at the first failure, reporting the failed statement", func() {
+		hook := &apiv1.PrePromotionHookConfiguration{
+			SQL: []string{"SELECT 1", "SELECT broken", "SELECT 3"},
+		}
+		mock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("SELECT broken").WillReturnError(errors.New("syntax error"))
+
+		err := executePrePromotionHook(context.Background(), db, hook)
+		Expect(err).To(MatchError(ContainSubstring("while running statement 2: syntax error")))
+	})
+
+	It("interrupts the statements when the timeout expires", func() {
+		hook := &apiv1.PrePromotionHookConfiguration{
+			SQL:     []string{"SELECT pg_sleep(10)", "SELECT 2"},
+			Timeout: 1,
+		}
+		mock.ExpectExec("SELECT pg_sleep(10)").
+			WillDelayFor(10 * time.Second).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		start := time.Now()
+		err := executePrePromotionHook(context.Background(), db, hook)
+		Expect(err).To(MatchError(ContainSubstring("while running statement 1")))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+})
+
+var _ = Describe("pre-promotion hook outcome", func() {
+	var (
+		cluster    *apiv1.Cluster
+		fakeClient ctrl.Client
+		recorder   *record.FakeRecorder
+		reconciler *InstanceReconciler
+	)
+
+	BeforeEach(func() {
+		cluster = &apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		}
+		scheme := runtime.NewScheme()
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &InstanceReconciler{
+			client:   fakeClient,
+			instance: &postgres.Instance{Namespace: "default", PodName: "cluster-2"},
+			recorder: recorder,
+		}
+	})
+
+	getCondition := func() *metav1.Condition {
+		var updated apiv1.Cluster
+		Expect(fakeClient.Get(context.Background(), ctrl.ObjectKeyFromObject(cluster), &updated)).To(Succeed())
+		return meta.FindStatusCondition(updated.Status.Conditions, string(apiv1.ConditionPrePromotionHook))
+	}
+
+	It("reports the completed hook", func() {
+		hook := &apiv1.PrePromotionHookConfiguration{SQL: []string{"SELECT 1"}}
+		Expect(reconciler.reportPrePromotionHook(context.Background(), cluster, hook, nil)).To(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring("PrePromotionHookCompleted")))
+
+		condition := getCondition()
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(string(apiv1.ConditionReasonPrePromotionHookCompleted)))
+	})
+
+	It("continues the promotion when the hook fails", func() {
+		hook := &apiv1.PrePromotionHookConfiguration{SQL: []string{"SELECT 1"}}
+		err := reconciler.reportPrePromotionHook(context.Background(), cluster, hook, errors.New("failed"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.Events).To(Receive(ContainSubstring("PrePromotionHookFailed")))
+
+		condition := getCondition()
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(apiv1.ConditionReasonPrePromotionHookFailed)))
+		Expect(condition.Message).To(ContainSubstring("cluster-2"))
+	})
+
+	It("aborts the promotion when the hook fails and it was requested", func() {
+		hook := &apiv1.PrePromotionHookConfiguration{SQL: []string{"SELECT 1"}, AbortOnFailure: true}
+		err := reconciler.reportPrePromotionHook(context.Background(), cluster, hook, errors.New("failed"))
+		Expect(err).To(MatchError(ContainSubstring("aborting the promotion")))
+		Expect(recorder.Events).To(Receive(ContainSubstring("PrePromotionHookFailed")))
+		Expect(getCondition().Status).To(Equal(metav1.ConditionFalse))
+	})
+})
@@ -18,13 +18,30 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
+
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/external"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+	pkgUtils "github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
+// ErrReplicaClusterSourceIsPrimary is raised when the designated primary of
+// a replica cluster is requested to be promoted while the source cluster is
+// still running as a primary
+var ErrReplicaClusterSourceIsPrimary = errors.New(
+	"the source of the replica cluster is still running as a primary, refusing to promote")
+
+// ErrReplicaClusterSourceUnreachable is raised when the designated primary of
+// a replica cluster is requested to be promoted and we can't verify whether
+// the source cluster is still running as a primary
+var ErrReplicaClusterSourceUnreachable = errors.New(
+	"the source of the replica cluster is not reachable, refusing to promote")
+
 // refreshReplicaConfiguration writes the PostgreSQL correct
 // replication configuration for connecting to the right primary server,
 // depending on the cluster replica mode
@@ -86,3 +103,75 @@ func (r *InstanceReconciler) writeReplicaConfigurationForDesignatedPrimary(
 	slotName := cluster.GetSlotNameFromInstanceName(r.instance.PodName)
 	return postgres.UpdateReplicaConfiguration(r.instance.PgData, connectionString, slotName)
 }
+
+// ensureReplicaClusterSourceIsNotPrimary checks that the source of a replica
+// cluster whose replica mode has been disabled is not running as a primary
+// anymore. Promoting the designated primary while the source cluster is still
+// accepting writes would lead to a split-brain, so we refuse to do it unless
+// the check has been explicitly disabled by the user.
+func (r *InstanceReconciler) ensureReplicaClusterSourceIsNotPrimary(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+) error {
+	contextLogger := log.FromContext(ctx)
+
+	if !pkgUtils.IsReplicaClusterSourceCheckEnabled(&cluster.ObjectMeta) {
+		contextLogger.Info("Skipping the replica cluster source check as requested by the user")
+		return nil
+	}
+
+	server, ok := cluster.ExternalCluster(cluster.Spec.ReplicaCluster.Source)
+	if !ok || len(server.ConnectionParameters) == 0 {
+		// We have no way to connect to the source cluster, as
+		// it is reachable only through the WAL archive
+		return nil
+	}
+
+	connectionString, pgpassfile, err := external.ConfigureConnectionToServer(
+		ctx, r.client, r.instance.Namespace, &server)
+	if err != nil {
+		return err
+	}
+
+	if pgpassfile != "" {
+		connectionString = fmt.Sprintf("%v passfile=%v", connectionString, pgpassfile)
+	}
+	if _, ok := server.ConnectionParameters["dbname"]; !ok {
+		connectionString = fmt.Sprintf("%v dbname=postgres", connectionString)
+	}
+	connectionString = fmt.Sprintf("%v connect_timeout=5", connectionString)
+
+	db, err := pkgUtils.NewSimpleDBConnection(connectionString)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	var isInRecovery bool
+	row := db.QueryRowContext(ctx, "SELECT pg_is_in_recovery()")
+	if err := row.Scan(&isInRecovery); err != nil {
+		// An unreachable source cluster may well be still running
+		// as a primary behind a network partition, so we don't promote
+		// unless the user explicitly skipped this check
+		if r.recorder != nil {
+			r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ReplicaClusterSourceUnreachable",
+				"Refusing to promote the designated primary as the source cluster %q is "+
+					"not reachable: %v", server.Name, err)
+		}
+		return fmt.Errorf("%w: %v", ErrReplicaClusterSourceUnreachable, err)
+	}
+
+	if isInRecovery {
+		return nil
+	}
+
+	if r.recorder != nil {
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ReplicaClusterSourceIsPrimary",
+			"Refusing to promote the designated primary while the source cluster %q is "+
+				"still running as a primary", server.Name)
+	}
+
+	return ErrReplicaClusterSourceIsPrimary
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("replica cluster source check", func() {
+	var (
+		cluster    *apiv1.Cluster
+		recorder   *record.FakeRecorder
+		reconciler *InstanceReconciler
+	)
+
+	BeforeEach(func() {
+		cluster = &apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cluster-replica",
+				Namespace: "default",
+			},
+			Spec: apiv1.ClusterSpec{
+				ReplicaCluster: &apiv1.ReplicaClusterConfiguration{
+					Source:  "cluster-source",
+					Enabled: false,
+				},
+				ExternalClusters: []apiv1.ExternalCluster{
+					{
+						Name: "cluster-source",
+						ConnectionParameters: map[string]string{
+							"host": "/nonexistent",
+							"user": "streaming_replica",
+						},
+					},
+				},
+			},
+		}
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &InstanceReconciler{
+			instance: &postgres.Instance{Namespace: "default"},
+			recorder: recorder,
+		}
+	})
+
+	It("refuses to promote when the source cluster is not reachable", func() {
+		err := reconciler.ensureReplicaClusterSourceIsNotPrimary(context.Background(), cluster)
+		Expect(err).To(MatchError(ErrReplicaClusterSourceUnreachable))
+		Expect(recorder.Events).To(Receive(ContainSubstring("ReplicaClusterSourceUnreachable")))
+	})
+
+	It("promotes an unreachable source cluster when the check is skipped", func() {
+		cluster.Annotations = map[string]string{
+			"cnpg.io/skipReplicaClusterSourceCheck": "enabled",
+		}
+		Expect(reconciler.ensureReplicaClusterSourceIsNotPrimary(context.Background(), cluster)).To(Succeed())
+		Expect(recorder.Events).ToNot(Receive())
+	})
+
+	It("skips the check when the source cluster is reachable only through the archive", func() {
+		cluster.Spec.ExternalClusters[0].ConnectionParameters = nil
+		Expect(reconciler.ensureReplicaClusterSourceIsNotPrimary(context.Background(), cluster)).To(Succeed())
+	})
+})
@@ -0,0 +1,78 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+)
+
+// reconcileReplicationSlotsUser makes sure the user managing the local
+// replication slots exists in the primary when it's requested, with only
+// the rights needed to do it. The user is created by the primary, and is
+// propagated to the replicas by the streaming replication
+func (r *InstanceReconciler) reconcileReplicationSlotsUser(ctx context.Context, cluster *apiv1.Cluster) error {
+	if !cluster.Spec.ReplicationSlots.IsDedicatedRoleEnabled() {
+		return nil
+	}
+
+	isPrimary, err := r.instance.IsPrimary()
+	if err != nil || !isPrimary {
+		return err
+	}
+
+	db, err := r.instance.GetSuperUserDB()
+	if err != nil {
+		return fmt.Errorf("while getting a connection to the instance: %w", err)
+	}
+
+	return configureReplicationSlotsUser(ctx, db)
+}
+
+// configureReplicationSlotsUser creates the user managing the local
+// replication slots, or grants it the needed rights if it already exists
+func configureReplicationSlotsUser(ctx context.Context, db *sql.DB) error {
+	identifier := pgx.Identifier{apiv1.ReplicationSlotsUser}.Sanitize()
+
+	var hasLoginRight, hasReplicationRight bool
+	row := db.QueryRowContext(ctx, "SELECT rolcanlogin, rolreplication FROM pg_catalog.pg_roles WHERE rolname = $1",
+		apiv1.ReplicationSlotsUser)
+	err := row.Scan(&hasLoginRight, &hasReplicationRight)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err = db.ExecContext(ctx, fmt.Sprintf("CREATE USER %v REPLICATION", identifier)); err != nil {
+			return fmt.Errorf("CREATE USER %v error: %w", apiv1.ReplicationSlotsUser, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("while checking the replication slots user: %w", err)
+	}
+
+	if !hasLoginRight || !hasReplicationRight {
+		if _, err = db.ExecContext(ctx, fmt.Sprintf("ALTER USER %v LOGIN REPLICATION", identifier)); err != nil {
+			return fmt.Errorf("ALTER USER %v error: %w", apiv1.ReplicationSlotsUser, err)
+		}
+	}
+
+	return nil
+}
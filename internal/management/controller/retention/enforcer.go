@@ -0,0 +1,180 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retention
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/cronrunner"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/barman"
+	barmanCredentials "github.com/cloudnative-pg/cloudnative-pg/pkg/management/barman/credentials"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/catalog"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+)
+
+// retentionPolicyOperation is the name of the retention policy
+// enforcement in the plan
+const retentionPolicyOperation = "retentionPolicy"
+
+// An Enforcer is a runner that periodically applies, from the primary,
+// the backup retention policy of the cluster to the object store,
+// reporting the deleted backups in the cluster status and events
+type Enforcer struct {
+	instance *postgres.Instance
+	client   client.Client
+	recorder record.EventRecorder
+
+	// plan contains the next planned enforcement
+	plan *cronrunner.Plan
+}
+
+// NewEnforcer creates a new retention policy enforcer
+func NewEnforcer(instance *postgres.Instance, client client.Client, recorder record.EventRecorder) *Enforcer {
+	return &Enforcer{
+		instance: instance,
+		client:   client,
+		recorder: recorder,
+		plan:     cronrunner.NewPlan(),
+	}
+}
+
+// Start starts running the retention policy enforcer
+func (e *Enforcer) Start(ctx context.Context) error {
+	return cronrunner.Run(ctx, "RetentionPolicyEnforcer", e.instance, e.client, e)
+}
+
+// Reset forgets the planned enforcement of the retention policy
+func (e *Enforcer) Reset() {
+	e.plan.Reset()
+}
+
+// Run enforces the retention policy if its execution is due
+func (e *Enforcer) Run(ctx context.Context, cluster *apiv1.Cluster) error {
+	contextLog := log.FromContext(ctx).WithName("RetentionPolicyEnforcer")
+
+	backupConfig := cluster.Spec.Backup
+	if backupConfig == nil || backupConfig.BarmanObjectStore == nil ||
+		backupConfig.RetentionPolicy == "" || backupConfig.RetentionPolicySchedule == "" {
+		e.plan.Reset()
+		return nil
+	}
+
+	due, err := e.plan.Due(retentionPolicyOperation, backupConfig.RetentionPolicySchedule, time.Now())
+	if err != nil {
+		return fmt.Errorf("invalid retention policy schedule %q: %w", backupConfig.RetentionPolicySchedule, err)
+	}
+	if !due {
+		return nil
+	}
+
+	contextLog.Info("Enforcing the backup retention policy",
+		"retentionPolicy", backupConfig.RetentionPolicy)
+	startTime := time.Now()
+	deletedBackups, backupList, err := e.enforce(ctx, cluster)
+
+	result := &apiv1.RetentionPolicyStatus{
+		LastRunTime:    startTime.UTC().Format(time.RFC3339),
+		DeletedBackups: deletedBackups,
+	}
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+		e.recorder.Eventf(cluster, "Warning", "RetentionPolicyFailed",
+			"Retention policy enforcement failed: %v", err)
+	case len(deletedBackups) > 0:
+		e.recorder.Eventf(cluster, "Normal", "RetentionPolicyApplied",
+			"Retention policy %s applied, deleted backups: %s",
+			backupConfig.RetentionPolicy, strings.Join(deletedBackups, ", "))
+	}
+
+	origCluster := cluster.DeepCopy()
+	cluster.Status.RetentionPolicy = result
+	if backupList != nil {
+		if ts := backupList.FirstRecoverabilityPoint(); ts != nil {
+			cluster.Status.FirstRecoverabilityPoint = ts.Format(time.RFC3339)
+		}
+	}
+	return e.client.Status().Patch(ctx, cluster, client.MergeFrom(origCluster))
+}
+
+// enforce applies the retention policy to the object store, returning the
+// IDs of the deleted backups and the resulting backup catalog
+func (e *Enforcer) enforce(
+	ctx context.Context,
+	cluster *apiv1.Cluster,
+) ([]string, *catalog.Catalog, error) {
+	objectStore := cluster.Spec.Backup.BarmanObjectStore
+	serverName := objectStore.ServerName
+	if serverName == "" {
+		serverName = cluster.Name
+	}
+
+	env, err := barmanCredentials.EnvSetBackupCloudCredentials(
+		ctx,
+		e.client,
+		cluster.Namespace,
+		objectStore,
+		os.Environ())
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot recover backup credentials: %w", err)
+	}
+
+	backupListBefore, err := barman.GetBackupList(objectStore, serverName, env)
+	if err != nil {
+		return nil, nil, fmt.Errorf("while reading the backup catalog: %w", err)
+	}
+
+	if err := barman.DeleteBackupsByPolicy(cluster.Spec.Backup, serverName, env); err != nil {
+		return nil, nil, fmt.Errorf("while deleting the backups by policy: %w", err)
+	}
+
+	backupListAfter, err := barman.GetBackupList(objectStore, serverName, env)
+	if err != nil {
+		return nil, nil, fmt.Errorf("while reading the backup catalog: %w", err)
+	}
+	deletedBackups := getDeletedBackups(backupListBefore, backupListAfter)
+
+	err = barman.DeleteBackupsNotInCatalog(ctx, e.client, cluster, backupListAfter)
+	return deletedBackups, backupListAfter, err
+}
+
+// getDeletedBackups returns the IDs of the backups which are in the
+// first catalog but not in the second one
+func getDeletedBackups(before, after *catalog.Catalog) []string {
+	remaining := make(map[string]bool, len(after.List))
+	for _, backup := range after.List {
+		remaining[backup.ID] = true
+	}
+
+	var deleted []string
+	for _, backup := range before.List {
+		if !remaining[backup.ID] {
+			deleted = append(deleted, backup.ID)
+		}
+	}
+
+	return deleted
+}
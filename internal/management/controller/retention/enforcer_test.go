@@ -0,0 +1,33 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retention
+
+import (
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/catalog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("deleted backups detection", func() {
+	It("returns the backups not present anymore in the catalog", func() {
+		before := &catalog.Catalog{List: []catalog.BarmanBackup{{ID: "b1"}, {ID: "b2"}, {ID: "b3"}}}
+		after := &catalog.Catalog{List: []catalog.BarmanBackup{{ID: "b3"}}}
+		Expect(getDeletedBackups(before, after)).To(Equal([]string{"b1", "b2"}))
+		Expect(getDeletedBackups(after, after)).To(BeEmpty())
+	})
+})
@@ -33,4 +33,7 @@ type Manager interface {
 	Create(ctx context.Context, slot ReplicationSlot) error
 	// Delete the replication slot
 	Delete(ctx context.Context, slot ReplicationSlot) error
+	// Disable the replication slot, moving it to the current position
+	// of the instance so that it doesn't retain WAL files
+	Disable(ctx context.Context, slot ReplicationSlot) error
 }
@@ -19,9 +19,12 @@ package infrastructure
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	v1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 )
 
 // pooler is an internal interface to pass a connection pooler to NewPostgresManager
@@ -32,18 +35,30 @@ type pooler interface {
 
 // PostgresManager is a Manager for a database instance
 type PostgresManager struct {
-	pool pooler
+	pool     pooler
+	database string
 }
 
-// NewPostgresManager returns an implementation of Manager for postgres
-func NewPostgresManager(pool pooler) Manager {
+// NewPostgresManager returns an implementation of Manager for postgres,
+// connecting to the passed database to manage the replication slots
+func NewPostgresManager(pool pooler, database string) Manager {
 	return PostgresManager{
-		pool: pool,
+		pool:     pool,
+		database: database,
 	}
 }
 
 func (sm PostgresManager) String() string {
-	return sm.pool.GetDsn("postgres")
+	return sm.pool.GetDsn(sm.database)
+}
+
+// connect returns a connection to the database used to manage the replication slots
+func (sm PostgresManager) connect() (*sql.DB, error) {
+	db, err := sm.pool.Connection(sm.database)
+	if err != nil {
+		return nil, fmt.Errorf("while connecting to database %q to manage replication slots: %w", sm.database, err)
+	}
+	return db, nil
 }
 
 // List the available replication slots
@@ -51,7 +66,7 @@ func (sm PostgresManager) List(
 	ctx context.Context,
 	config *v1.ReplicationSlotsConfiguration,
 ) (ReplicationSlotList, error) {
-	db, err := sm.pool.Connection("postgres")
+	db, err := sm.connect()
 	if err != nil {
 		return ReplicationSlotList{}, err
 	}
@@ -92,28 +107,60 @@ func (sm PostgresManager) List(
 	return status, nil
 }
 
-// Update the replication slot
+// Update the replication slot, advancing it to the passed restart_lsn.
+// The slot is never moved backward: when its position is already at or
+// ahead of the passed one, nothing is done
 func (sm PostgresManager) Update(ctx context.Context, slot ReplicationSlot) error {
 	contextLog := log.FromContext(ctx).WithName("updateSlot")
 	contextLog.Trace("Invoked", "slot", slot)
 	if slot.RestartLSN == "" {
 		return nil
 	}
-	db, err := sm.pool.Connection("postgres")
+	db, err := sm.connect()
 	if err != nil {
 		return err
 	}
 
+	var localRestartLSN string
+	row := db.QueryRowContext(
+		ctx,
+		"SELECT coalesce(restart_lsn::TEXT, '') FROM pg_replication_slots WHERE slot_name = $1",
+		slot.SlotName,
+	)
+	if err := row.Scan(&localRestartLSN); err != nil {
+		return fmt.Errorf("while getting the position of replication slot %q: %w", slot.SlotName, err)
+	}
+
+	if localRestartLSN == "" {
+		contextLog.Info("Replication slot doesn't reserve WAL and can't be advanced, "+
+			"it will be reported as unsynchronized",
+			"slot", slot.SlotName, "restartLSN", slot.RestartLSN)
+		return nil
+	}
+
+	if !isSlotAdvanceNeeded(postgres.LSN(localRestartLSN), postgres.LSN(slot.RestartLSN)) {
+		contextLog.Trace("Replication slot already up to date, skipping",
+			"slot", slot.SlotName, "localRestartLSN", localRestartLSN)
+		return nil
+	}
+
 	_, err = db.ExecContext(ctx, "SELECT pg_replication_slot_advance($1, $2)", slot.SlotName, slot.RestartLSN)
 	return err
 }
 
+// isSlotAdvanceNeeded checks whether a replication slot at the local
+// position is behind the target one. A slot which doesn't reserve WAL,
+// having no position, can't be advanced
+func isSlotAdvanceNeeded(local, target postgres.LSN) bool {
+	return local.Less(target)
+}
+
 // Create the replication slot
 func (sm PostgresManager) Create(ctx context.Context, slot ReplicationSlot) error {
 	contextLog := log.FromContext(ctx).WithName("createSlot")
 	contextLog.Trace("Invoked", "slot", slot)
 
-	db, err := sm.pool.Connection("postgres")
+	db, err := sm.connect()
 	if err != nil {
 		return err
 	}
@@ -131,7 +178,7 @@ func (sm PostgresManager) Delete(ctx context.Context, slot ReplicationSlot) erro
 		return nil
 	}
 
-	db, err := sm.pool.Connection("postgres")
+	db, err := sm.connect()
 	if err != nil {
 		return err
 	}
@@ -139,3 +186,82 @@ func (sm PostgresManager) Delete(ctx context.Context, slot ReplicationSlot) erro
 	_, err = db.ExecContext(ctx, "SELECT pg_drop_replication_slot($1)", slot.SlotName)
 	return err
 }
+
+// Disable the replication slot, advancing it to the last WAL location
+// replayed by the standby. Active slots are left untouched, and the slot
+// is never moved backward.
+// The slot keeps its name, as PostgreSQL can neither rename a replication
+// slot nor attach any marker to it. Recreating it with a different prefix
+// would take it out of the HA slots, which are the only ones advanced here,
+// and it would retain WAL files forever. Disabled slots are reported in
+// the convergence status instead
+func (sm PostgresManager) Disable(ctx context.Context, slot ReplicationSlot) error {
+	contextLog := log.FromContext(ctx).WithName("disableSlot")
+	contextLog.Trace("Invoked", "slot", slot)
+	if slot.Active {
+		return nil
+	}
+
+	db, err := sm.connect()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		`SELECT pg_replication_slot_advance(slot_name, pg_last_wal_replay_lsn())
+            FROM pg_replication_slots
+            WHERE slot_name = $1 AND NOT active AND restart_lsn < pg_last_wal_replay_lsn()`,
+		slot.SlotName,
+	)
+	return err
+}
+
+// slotFunctions are the functions executed to manage the replication slots
+var slotFunctions = []string{
+	"pg_catalog.pg_create_physical_replication_slot(name, boolean, boolean)",
+	"pg_catalog.pg_drop_replication_slot(name)",
+	"pg_catalog.pg_replication_slot_advance(name, pg_lsn)",
+}
+
+// CheckPrivileges verifies that the user connecting to the passed database
+// has the REPLICATION attribute and is allowed to execute the functions
+// used to manage the replication slots
+func CheckPrivileges(ctx context.Context, pool pooler, database string) error {
+	db, err := pool.Connection(database)
+	if err != nil {
+		return fmt.Errorf("while connecting to database %q to manage replication slots: %w", database, err)
+	}
+
+	var hasReplicationAttribute bool
+	row := db.QueryRowContext(ctx,
+		"SELECT rolsuper OR rolreplication FROM pg_catalog.pg_roles WHERE rolname = current_user")
+	if err := row.Scan(&hasReplicationAttribute); err != nil {
+		return fmt.Errorf("while checking the privileges to manage replication slots in database %q: %w",
+			database, err)
+	}
+	if !hasReplicationAttribute {
+		return fmt.Errorf("the replication slots can't be managed in database %q, "+
+			"the current user doesn't have the REPLICATION attribute", database)
+	}
+
+	var missing []string
+	for _, function := range slotFunctions {
+		var allowed bool
+		row := db.QueryRowContext(ctx, "SELECT has_function_privilege($1, 'EXECUTE')", function)
+		if err := row.Scan(&allowed); err != nil {
+			return fmt.Errorf("while checking the privileges to manage replication slots in database %q: %w",
+				database, err)
+		}
+		if !allowed {
+			missing = append(missing, function)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("the replication slots can't be managed in database %q, "+
+			"missing the EXECUTE privilege on: %s", database, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
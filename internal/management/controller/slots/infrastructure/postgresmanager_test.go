@@ -0,0 +1,65 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("replication slot advance", func() {
+	ginkgo.It("is needed when the local slot is behind the target position", func() {
+		gomega.Expect(isSlotAdvanceNeeded("0/3000060", "0/4000000")).To(gomega.BeTrue())
+		gomega.Expect(isSlotAdvanceNeeded("0/FFFFFFFF", "1/0")).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("is skipped when the local slot is already at the target position", func() {
+		gomega.Expect(isSlotAdvanceNeeded("0/4000000", "0/4000000")).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("never moves the local slot backward", func() {
+		gomega.Expect(isSlotAdvanceNeeded("1/0", "0/FFFFFFFF")).To(gomega.BeFalse())
+		gomega.Expect(isSlotAdvanceNeeded("0/4000000", "0/3000060")).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("is skipped when the local slot has no position", func() {
+		gomega.Expect(isSlotAdvanceNeeded("", "0/4000000")).To(gomega.BeFalse())
+	})
+})
+
+// unreachablePooler is a pooler which can't connect to any database
+type unreachablePooler struct{}
+
+func (unreachablePooler) Connection(string) (*sql.DB, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (unreachablePooler) GetDsn(dbname string) string {
+	return "dbname=" + dbname
+}
+
+var _ = ginkgo.Describe("replication slot privileges", func() {
+	ginkgo.It("reports the databases which can't be reached", func() {
+		err := CheckPrivileges(context.Background(), unreachablePooler{}, "app")
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(
+			`while connecting to database "app" to manage replication slots`)))
+	})
+})
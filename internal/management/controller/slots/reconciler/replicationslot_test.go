@@ -47,6 +47,10 @@ func (fk fakeReplicationSlotManager) Delete(ctx context.Context, slot infrastruc
 	return nil
 }
 
+func (fk fakeReplicationSlotManager) Disable(ctx context.Context, slot infrastructure.ReplicationSlot) error {
+	return nil
+}
+
 func (fk fakeReplicationSlotManager) Update(ctx context.Context, slot infrastructure.ReplicationSlot) error {
 	return nil
 }
@@ -0,0 +1,115 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The operations on the local replication slots which are
+// counted by the slot replicator when they fail
+const (
+	slotOperationCreate  = "create"
+	slotOperationUpdate  = "update"
+	slotOperationDelete  = "delete"
+	slotOperationDisable = "disable"
+)
+
+// The categories of the failures of the operations on the replication slots
+const (
+	errorReasonConnection       = "connection"
+	errorReasonLimitExceeded    = "limit_exceeded"
+	errorReasonAlreadyExists    = "already_exists"
+	errorReasonInvalidState     = "invalid_state"
+	errorReasonPermissionDenied = "permission_denied"
+	errorReasonTimeout          = "timeout"
+	errorReasonOther            = "other"
+)
+
+// slotOperationError is raised when an operation on a local replication
+// slot fails
+type slotOperationError struct {
+	operation string
+	slotName  string
+	err       error
+}
+
+func (e *slotOperationError) Error() string {
+	return fmt.Sprintf("cannot %s replication slot %q: %v", e.operation, e.slotName, e.err)
+}
+
+func (e *slotOperationError) Unwrap() error {
+	return e.err
+}
+
+// slotOperationErrors aggregates the failures of the operations on the
+// local replication slots applied concurrently in the same cycle
+type slotOperationErrors []*slotOperationError
+
+func (e slotOperationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i := range e {
+		messages[i] = e[i].Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// sqlStateError is implemented by the errors raised by PostgreSQL
+type sqlStateError interface {
+	SQLState() string
+}
+
+// getErrorReason returns the category of the failure of an
+// operation on a replication slot
+func getErrorReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return errorReasonTimeout
+	}
+
+	var pgErr sqlStateError
+	if !errors.As(err, &pgErr) {
+		return errorReasonOther
+	}
+
+	code := pgErr.SQLState()
+	switch {
+	// connection_exception, invalid_authorization_specification
+	case strings.HasPrefix(code, "08"), strings.HasPrefix(code, "28"):
+		return errorReasonConnection
+	// insufficient_resources, i.e. all replication slots are in use
+	case strings.HasPrefix(code, "53"):
+		return errorReasonLimitExceeded
+	// duplicate_object
+	case code == "42710":
+		return errorReasonAlreadyExists
+	// object_not_in_prerequisite_state, i.e. the slot is active
+	// or wal_level is too low
+	case strings.HasPrefix(code, "55"):
+		return errorReasonInvalidState
+	// insufficient_privilege
+	case code == "42501":
+		return errorReasonPermissionDenied
+	// query_canceled, i.e. statement_timeout
+	case code == "57014":
+		return errorReasonTimeout
+	default:
+		return errorReasonOther
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeSQLStateError struct {
+	code string
+}
+
+func (e fakeSQLStateError) Error() string {
+	return fmt.Sprintf("fake error with SQLSTATE %s", e.code)
+}
+
+func (e fakeSQLStateError) SQLState() string {
+	return e.code
+}
+
+var _ = Describe("Slot synchronization error reasons", func() {
+	DescribeTable("categorizes the errors",
+		func(err error, reason string) {
+			Expect(getErrorReason(err)).To(Equal(reason))
+		},
+		Entry("connection failure", fakeSQLStateError{code: "08006"}, errorReasonConnection),
+		Entry("authentication failure", fakeSQLStateError{code: "28P01"}, errorReasonConnection),
+		Entry("too many replication slots", fakeSQLStateError{code: "53400"}, errorReasonLimitExceeded),
+		Entry("duplicate slot", fakeSQLStateError{code: "42710"}, errorReasonAlreadyExists),
+		Entry("active slot", fakeSQLStateError{code: "55006"}, errorReasonInvalidState),
+		Entry("missing privileges", fakeSQLStateError{code: "42501"}, errorReasonPermissionDenied),
+		Entry("statement timeout", fakeSQLStateError{code: "57014"}, errorReasonTimeout),
+		Entry("context deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), errorReasonTimeout),
+		Entry("wrapped PostgreSQL error", fmt.Errorf("while connecting: %w", fakeSQLStateError{code: "08001"}),
+			errorReasonConnection),
+		Entry("undefined function", fakeSQLStateError{code: "42883"}, errorReasonOther),
+		Entry("generic error", errors.New("generic"), errorReasonOther),
+	)
+})
@@ -0,0 +1,106 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+)
+
+// primaryConnectionCheckTimeout is the maximum time we wait for the
+// primary to answer while validating its connection
+const primaryConnectionCheckTimeout = 5 * time.Second
+
+// errPrimaryInRecovery is raised when the server we are connected to
+// is not the primary anymore
+var errPrimaryInRecovery = errors.New("the server is in recovery")
+
+// primaryConnectionPool is the part of the connection pool used to
+// validate the connection to the primary
+type primaryConnectionPool interface {
+	Connection(dbname string) (*sql.DB, error)
+	ResetConnection(dbname string)
+}
+
+// validatePrimaryConnection checks that the cached connection to the
+// primary is still usable and that it points to a server that is not in
+// recovery. The pooled handle may still be connected to a demoted primary
+// after a switchover, so when the check fails the pool entry is recreated
+// and the check is tried once again. In a replica cluster the primary is
+// the designated one, which is always in recovery: replicaCluster skips
+// the recovery check
+func validatePrimaryConnection(
+	ctx context.Context,
+	pool primaryConnectionPool,
+	dbname string,
+	replicaCluster bool,
+) error {
+	err := checkPrimaryConnection(ctx, pool, dbname, replicaCluster)
+	if err == nil {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Resetting the connection to the primary",
+		"database", dbname, "reason", err.Error())
+	pool.ResetConnection(dbname)
+
+	if err := checkPrimaryConnection(ctx, pool, dbname, replicaCluster); err != nil {
+		return fmt.Errorf("while validating the connection to the primary: %w", err)
+	}
+
+	return nil
+}
+
+// checkPrimaryConnection pings the primary and, unless it is the designated
+// primary of a replica cluster, verifies it is not in recovery
+func checkPrimaryConnection(
+	ctx context.Context,
+	pool primaryConnectionPool,
+	dbname string,
+	replicaCluster bool,
+) error {
+	db, err := pool.Connection(dbname)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, primaryConnectionCheckTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	if replicaCluster {
+		return nil
+	}
+
+	var inRecovery bool
+	if err := db.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return err
+	}
+	if inRecovery {
+		return errPrimaryInRecovery
+	}
+
+	return nil
+}
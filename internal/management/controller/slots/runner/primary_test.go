@@ -0,0 +1,105 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakePrimaryPool is a connection pool whose connections are never able
+// to reach the database
+type fakePrimaryPool struct {
+	connections map[string]*sql.DB
+	resets      int
+}
+
+func (pool *fakePrimaryPool) Connection(dbname string) (*sql.DB, error) {
+	if db, ok := pool.connections[dbname]; ok {
+		return db, nil
+	}
+
+	db, err := utils.NewSimpleDBConnection("host=/nonexistent connect_timeout=1 dbname=" + dbname)
+	if err != nil {
+		return nil, err
+	}
+	pool.connections[dbname] = db
+	return db, nil
+}
+
+func (pool *fakePrimaryPool) ResetConnection(dbname string) {
+	if db, ok := pool.connections[dbname]; ok {
+		_ = db.Close()
+		delete(pool.connections, dbname)
+	}
+	pool.resets++
+}
+
+var _ = Describe("primary connection validation", func() {
+	It("resets the connection and retries once when the ping fails", func() {
+		pool := &fakePrimaryPool{connections: make(map[string]*sql.DB)}
+		staleConnection, err := pool.Connection("postgres")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = validatePrimaryConnection(context.Background(), pool, "postgres", false)
+		Expect(err).To(MatchError(ContainSubstring("while validating the connection to the primary")))
+		Expect(pool.resets).To(Equal(1))
+
+		// the stale handle has been replaced
+		Expect(pool.connections).To(HaveKey("postgres"))
+		Expect(pool.connections["postgres"]).ToNot(BeIdenticalTo(staleConnection))
+	})
+
+	Context("when the primary is in recovery", func() {
+		var (
+			db   *sql.DB
+			mock sqlmock.Sqlmock
+			pool *fakePrimaryPool
+		)
+
+		BeforeEach(func() {
+			var err error
+			db, mock, err = sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			Expect(err).ToNot(HaveOccurred())
+			pool = &fakePrimaryPool{connections: map[string]*sql.DB{"postgres": db}}
+		})
+
+		It("accepts the designated primary of a replica cluster", func() {
+			err := validatePrimaryConnection(context.Background(), pool, "postgres", true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pool.resets).To(BeZero())
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("rejects a demoted primary", func() {
+			mock.ExpectQuery("SELECT pg_is_in_recovery()").
+				WillReturnRows(sqlmock.NewRows([]string{"pg_is_in_recovery"}).AddRow(true))
+
+			err := validatePrimaryConnection(context.Background(), pool, "postgres", false)
+			Expect(err).To(MatchError(ContainSubstring("while validating the connection to the primary")))
+			Expect(pool.resets).To(Equal(1))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+})
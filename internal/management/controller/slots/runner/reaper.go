@@ -0,0 +1,184 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/slots/infrastructure"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+)
+
+// An OrphanReaper is a runner that periodically drops, in the primary, the
+// HA replication slots which don't belong to any instance of the cluster.
+// It runs independently of the slot Replicator and of the instance
+// reconciliation loop, to catch the slots they may have missed
+type OrphanReaper struct {
+	instance *postgres.Instance
+	client   client.Client
+
+	// orphanSince contains, for every orphan slot, the time when it
+	// has been first seen without a matching instance
+	orphanSince map[string]time.Time
+}
+
+// NewOrphanReaper creates a new orphan replication slots reaper
+func NewOrphanReaper(instance *postgres.Instance, client client.Client) *OrphanReaper {
+	return &OrphanReaper{
+		instance:    instance,
+		client:      client,
+		orphanSince: make(map[string]time.Time),
+	}
+}
+
+// Start starts running the orphan replication slots reaper
+func (r *OrphanReaper) Start(ctx context.Context) error {
+	contextLog := log.FromContext(ctx).WithName("OrphanReaper")
+	interval := r.getFirstInterval(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			contextLog.Info("Terminated orphan replication slots reaper loop")
+			return nil
+		case <-time.After(interval):
+		}
+
+		cluster, err := r.getCluster(ctx)
+		if err != nil {
+			contextLog.Warning("getting the cluster, skipping the orphan replication slots check", "err", err)
+			continue
+		}
+
+		interval = cluster.Spec.ReplicationSlots.GetOrphanReaperInterval()
+		if err := r.reap(ctx, cluster); err != nil {
+			contextLog.Warning("dropping orphan replication slots", "err", err)
+		}
+	}
+}
+
+// getFirstInterval gets the time to wait before the first check, that
+// must already honor the interval configured in the cluster
+func (r *OrphanReaper) getFirstInterval(ctx context.Context) time.Duration {
+	cluster, err := r.getCluster(ctx)
+	if err != nil {
+		log.FromContext(ctx).WithName("OrphanReaper").Warning(
+			"getting the cluster, using the default orphan replication slots reaper interval",
+			"err", err)
+		return apiv1.DefaultReplicationSlotsOrphanReaperInterval * time.Second
+	}
+
+	return cluster.Spec.ReplicationSlots.GetOrphanReaperInterval()
+}
+
+// getCluster reads the cluster the instance belongs to
+func (r *OrphanReaper) getCluster(ctx context.Context) (*apiv1.Cluster, error) {
+	var cluster apiv1.Cluster
+	err := r.client.Get(
+		ctx,
+		client.ObjectKey{Namespace: r.instance.Namespace, Name: r.instance.ClusterName},
+		&cluster)
+	if err != nil {
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+func (r *OrphanReaper) reap(ctx context.Context, cluster *apiv1.Cluster) error {
+	config := cluster.Spec.ReplicationSlots
+	isStablePrimary := cluster.Status.CurrentPrimary == r.instance.PodName &&
+		cluster.Status.TargetPrimary == r.instance.PodName
+	if config == nil || config.HighAvailability == nil || !config.HighAvailability.Enabled || !isStablePrimary {
+		// The reaper only runs in the primary, and a replica may become
+		// the primary, so we need to start from scratch in that case
+		r.orphanSince = make(map[string]time.Time)
+		return nil
+	}
+
+	slotsPool, err := r.instance.ReplicationSlotsConnectionPool(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	manager := infrastructure.NewPostgresManager(slotsPool, config.GetDatabase())
+	return reapOrphanSlots(ctx, manager, cluster, r.orphanSince, time.Now())
+}
+
+// reapOrphanSlots drops the HA replication slots not belonging to any instance of
+// the cluster, after they have been orphan for the configured grace period.
+// Active slots are never dropped
+func reapOrphanSlots(
+	ctx context.Context,
+	manager infrastructure.Manager,
+	cluster *apiv1.Cluster,
+	orphanSince map[string]time.Time,
+	now time.Time,
+) error {
+	contextLog := log.FromContext(ctx).WithName("reapOrphanSlots")
+
+	slots, err := manager.List(ctx, cluster.Spec.ReplicationSlots)
+	if err != nil {
+		return fmt.Errorf("listing replication slots: %w", err)
+	}
+
+	expectedSlots := make(map[string]bool)
+	for _, instanceName := range cluster.Status.InstanceNames {
+		if instanceName == cluster.Status.CurrentPrimary {
+			continue
+		}
+		expectedSlots[cluster.GetSlotNameFromInstanceName(instanceName)] = true
+	}
+
+	gracePeriod := cluster.Spec.ReplicationSlots.GetOrphanGracePeriod()
+	orphanSlots := make(map[string]bool)
+	for _, slot := range slots.Items {
+		if expectedSlots[slot.SlotName] {
+			continue
+		}
+
+		since, found := orphanSince[slot.SlotName]
+		if !found {
+			orphanSince[slot.SlotName] = now
+			orphanSlots[slot.SlotName] = true
+			continue
+		}
+
+		if slot.Active || now.Sub(since) < gracePeriod {
+			orphanSlots[slot.SlotName] = true
+			continue
+		}
+
+		contextLog.Info("Dropping orphan replication slot", "slot", slot.SlotName, "orphanSince", since)
+		if err := manager.Delete(ctx, slot); err != nil {
+			return fmt.Errorf("dropping orphan replication slot %q: %w", slot.SlotName, err)
+		}
+	}
+
+	for slotName := range orphanSince {
+		if !orphanSlots[slotName] {
+			delete(orphanSince, slotName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Orphan slots reaper", func() {
+	ctx := context.TODO()
+	start := time.Date(2022, 11, 7, 10, 0, 0, 0, time.UTC)
+
+	cluster := &apiv1.Cluster{
+		Spec: apiv1.ClusterSpec{
+			ReplicationSlots: &apiv1.ReplicationSlotsConfiguration{
+				HighAvailability: &apiv1.ReplicationSlotsHAConfiguration{
+					Enabled:    true,
+					SlotPrefix: "_cnpg_",
+				},
+				OrphanGracePeriod: 60,
+			},
+		},
+		Status: apiv1.ClusterStatus{
+			CurrentPrimary: "cluster-1",
+			TargetPrimary:  "cluster-1",
+			InstanceNames:  []string{"cluster-1", "cluster-2"},
+		},
+	}
+
+	newPrimary := func() *fakeSlotManager {
+		return &fakeSlotManager{
+			slots: map[string]fakeSlot{
+				"_cnpg_cluster_2": {name: "_cnpg_cluster_2", restartLSN: "0/301C4D8"},
+				"_cnpg_cluster_3": {name: "_cnpg_cluster_3", restartLSN: "0/301C4D8"},
+			},
+		}
+	}
+
+	It("drops an orphan slot only after the grace period", func() {
+		primary := newPrimary()
+		orphanSince := make(map[string]time.Time)
+
+		Expect(reapOrphanSlots(ctx, primary, cluster, orphanSince, start)).To(Succeed())
+		Expect(primary.slotsDeleted).To(BeZero())
+		Expect(orphanSince).To(Equal(map[string]time.Time{"_cnpg_cluster_3": start}))
+
+		Expect(reapOrphanSlots(ctx, primary, cluster, orphanSince, start.Add(30*time.Second))).To(Succeed())
+		Expect(primary.slotsDeleted).To(BeZero())
+
+		Expect(reapOrphanSlots(ctx, primary, cluster, orphanSince, start.Add(60*time.Second))).To(Succeed())
+		Expect(primary.slotsDeleted).To(Equal(1))
+		Expect(primary.slots).To(HaveKey("_cnpg_cluster_2"))
+		Expect(orphanSince).To(BeEmpty())
+	})
+
+	It("forgets slots whose instance comes back", func() {
+		primary := newPrimary()
+		orphanSince := make(map[string]time.Time)
+
+		Expect(reapOrphanSlots(ctx, primary, cluster, orphanSince, start)).To(Succeed())
+		Expect(orphanSince).To(HaveLen(1))
+
+		clusterWithInstance := cluster.DeepCopy()
+		clusterWithInstance.Status.InstanceNames = append(clusterWithInstance.Status.InstanceNames, "cluster-3")
+		Expect(reapOrphanSlots(ctx, primary, clusterWithInstance, orphanSince, start.Add(time.Hour))).To(Succeed())
+		Expect(primary.slotsDeleted).To(BeZero())
+		Expect(orphanSince).To(BeEmpty())
+	})
+
+	It("waits for the interval configured in the cluster before the first check", func() {
+		scheme := runtime.NewScheme()
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+		instance := &postgres.Instance{Namespace: "default", ClusterName: "cluster"}
+
+		By("using the default interval when the cluster can't be read", func() {
+			reaper := NewOrphanReaper(instance, fake.NewClientBuilder().WithScheme(scheme).Build())
+			Expect(reaper.getFirstInterval(ctx)).
+				To(Equal(apiv1.DefaultReplicationSlotsOrphanReaperInterval * time.Second))
+		})
+
+		By("using the interval of the cluster", func() {
+			configuredCluster := cluster.DeepCopy()
+			configuredCluster.ObjectMeta = metav1.ObjectMeta{Namespace: "default", Name: "cluster"}
+			configuredCluster.Spec.ReplicationSlots.OrphanReaperInterval = 10
+			reaper := NewOrphanReaper(instance,
+				fake.NewClientBuilder().WithScheme(scheme).WithObjects(configuredCluster).Build())
+			Expect(reaper.getFirstInterval(ctx)).To(Equal(10 * time.Second))
+		})
+	})
+})
@@ -18,24 +18,48 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/slots/infrastructure"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/webserver/metricserver"
+	postgresSpec "github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 )
 
 // A Replicator is a runner that keeps replication slots in sync between the primary and this replica
 type Replicator struct {
 	instance *postgres.Instance
+	exporter *metricserver.Exporter
+
+	// inactiveSince contains, for every slot on the primary without
+	// a consumer, the time when it has been first seen inactive
+	inactiveSince map[string]time.Time
+
+	// unsyncedSince contains, for every slot on the primary whose local
+	// copy is not synchronized, the time when it has been first seen so
+	unsyncedSince map[string]time.Time
+
+	// synchronizationTargets contains the position every slot had on the
+	// primary during the last synchronization, which is the one the local
+	// copy should have reached by the next one
+	synchronizationTargets map[string]postgresSpec.LSN
 }
 
 // NewReplicator creates a new slot Replicator
-func NewReplicator(instance *postgres.Instance) *Replicator {
+func NewReplicator(instance *postgres.Instance, exporter *metricserver.Exporter) *Replicator {
 	runner := &Replicator{
-		instance: instance,
+		instance:      instance,
+		exporter:      exporter,
+		inactiveSince: make(map[string]time.Time),
+
+		unsyncedSince:          make(map[string]time.Time),
+		synchronizationTargets: make(map[string]postgresSpec.LSN),
 	}
 	return runner
 }
@@ -65,6 +89,9 @@ func (sr *Replicator) Start(ctx context.Context) error {
 			// the process will resume through the wakeUp channel if necessary
 			if config == nil || config.HighAvailability == nil || !config.HighAvailability.Enabled {
 				ticker.Stop()
+				sr.updateInactivityMetrics(infrastructure.ReplicationSlotList{}, time.Now())
+				sr.resetUnsyncedSlots()
+				sr.instance.SetSlotsConvergence(nil)
 				// we set updateInterval to 0 to make sure the Ticker will be reset
 				// if the feature is enabled again
 				updateInterval = 0
@@ -78,7 +105,25 @@ func (sr *Replicator) Start(ctx context.Context) error {
 				updateInterval = newUpdateInterval
 			}
 
-			err := sr.reconcile(ctx, config)
+			// The replicator is stopped by the instance reconciler when this
+			// instance becomes the primary, but during a role transition it may
+			// still be running: synchronizing the slots of the primary would
+			// alter the ones in use by the standbys, so we wait for the role
+			// to change
+			isPrimary, err := sr.instance.IsPrimary()
+			if err != nil {
+				contextLog.Warning("checking the role of the instance", "err", err)
+				continue
+			}
+			if isPrimary {
+				contextLog.Info("Skipping the replication slots synchronization on the primary, " +
+					"waiting for the role to change")
+				sr.resetUnsyncedSlots()
+				sr.instance.SetSlotsConvergence(nil)
+				continue
+			}
+
+			err = sr.reconcile(ctx, config)
 			if err != nil {
 				contextLog.Warning("synchronizing replication slots", "err", err)
 				continue
@@ -99,25 +144,260 @@ func (sr *Replicator) reconcile(ctx context.Context, config *apiv1.ReplicationSl
 	}()
 
 	primaryPool := sr.instance.PrimaryConnectionPool()
-	localPool := sr.instance.ConnectionPool()
-	err = synchronizeReplicationSlots(
+	localPool, err := sr.instance.ReplicationSlotsConnectionPool(ctx, config)
+	if err == nil {
+		err = infrastructure.CheckPrivileges(ctx, localPool, config.GetDatabase())
+	}
+	if err == nil {
+		// every worker synchronizing the local slots needs its own
+		// connection, otherwise they would just wait for each other
+		err = localPool.EnsureMaxOpenConns(config.GetDatabase(), config.GetSynchronizationConcurrency())
+	}
+	if err == nil {
+		err = validatePrimaryConnection(ctx, primaryPool, config.GetDatabase(), sr.instance.IsReplicaCluster())
+	}
+	if err != nil {
+		sr.instance.SetSlotsConvergence(&postgresSpec.SlotsConvergence{
+			Error:               err.Error(),
+			LastSynchronization: time.Now(),
+		})
+		return err
+	}
+
+	slotsInPrimary, slotsInLocal, convergence, err := synchronizeReplicationSlots(
 		ctx,
-		infrastructure.NewPostgresManager(primaryPool),
-		infrastructure.NewPostgresManager(localPool),
+		infrastructure.NewPostgresManager(primaryPool, config.GetDatabase()),
+		infrastructure.NewPostgresManager(localPool, config.GetDatabase()),
 		sr.instance.PodName,
 		config,
 	)
-	return err
+	now := time.Now()
+	if err != nil {
+		sr.instance.SetSlotsConvergence(&postgresSpec.SlotsConvergence{
+			Error:               err.Error(),
+			LastSynchronization: now,
+		})
+		sr.countSynchronizationError(err)
+		return err
+	}
+
+	convergence.LastSynchronization = now
+	sr.instance.SetSlotsConvergence(convergence)
+	sr.updateInactivityMetrics(slotsInPrimary, now)
+	sr.updateUnsyncedMetrics(
+		slotsInPrimary,
+		slotsInLocal,
+		config.HighAvailability.GetSlotNameFromInstanceName(sr.instance.PodName),
+		now,
+	)
+	return nil
+}
+
+// countSynchronizationError increments the counter of the failed
+// operations on the local replication slots, if the error is one of them
+func (sr *Replicator) countSynchronizationError(err error) {
+	if sr.exporter == nil {
+		return
+	}
+
+	var operationErrors slotOperationErrors
+	var operationError *slotOperationError
+	switch {
+	case errors.As(err, &operationErrors):
+	case errors.As(err, &operationError):
+		operationErrors = slotOperationErrors{operationError}
+	default:
+		return
+	}
+
+	for _, operationError := range operationErrors {
+		sr.exporter.Metrics.ReplicationSlotErrors.
+			WithLabelValues(operationError.operation, getErrorReason(operationError.err)).
+			Inc()
+	}
+}
+
+// updateInactivityMetrics exports for how long every replication slot
+// on the primary has been without a consumer
+func (sr *Replicator) updateInactivityMetrics(slotsInPrimary infrastructure.ReplicationSlotList, now time.Time) {
+	inactivity := computeSlotsInactivity(sr.inactiveSince, slotsInPrimary, now)
+	if sr.exporter == nil {
+		return
+	}
+
+	gauge := sr.exporter.Metrics.ReplicationSlotInactive
+	gauge.Reset()
+	for slotName, duration := range inactivity {
+		gauge.WithLabelValues(slotName).Set(duration.Seconds())
+	}
+}
+
+// computeSlotsInactivity updates the time when every slot has been first seen
+// inactive, forgetting the slots which are active or don't exist anymore, and
+// returns for how long every inactive slot has been without a consumer
+func computeSlotsInactivity(
+	inactiveSince map[string]time.Time,
+	slots infrastructure.ReplicationSlotList,
+	now time.Time,
+) map[string]time.Duration {
+	inactivity := make(map[string]time.Duration)
+	for _, slot := range slots.Items {
+		if slot.Active {
+			continue
+		}
+
+		since, ok := inactiveSince[slot.SlotName]
+		if !ok {
+			since = now
+			inactiveSince[slot.SlotName] = now
+		}
+		inactivity[slot.SlotName] = now.Sub(since)
+	}
+
+	for slotName := range inactiveSince {
+		if _, ok := inactivity[slotName]; !ok {
+			delete(inactiveSince, slotName)
+		}
+	}
+
+	return inactivity
+}
+
+// updateUnsyncedMetrics exports for how long the oldest replication slot
+// on the primary has been without a synchronized copy in this instance
+func (sr *Replicator) updateUnsyncedMetrics(
+	slotsInPrimary infrastructure.ReplicationSlotList,
+	slotsInLocal infrastructure.ReplicationSlotList,
+	mySlotName string,
+	now time.Time,
+) {
+	age := computeOldestUnsyncedSlotAge(
+		sr.unsyncedSince,
+		sr.synchronizationTargets,
+		slotsInPrimary,
+		slotsInLocal,
+		mySlotName,
+		now,
+	)
+	if sr.exporter == nil {
+		return
+	}
+
+	sr.exporter.Metrics.ReplicationSlotUnsynced.Set(age.Seconds())
+}
+
+// resetUnsyncedSlots forgets the unsynchronized slots, as happens
+// when this instance stops synchronizing them
+func (sr *Replicator) resetUnsyncedSlots() {
+	sr.unsyncedSince = make(map[string]time.Time)
+	sr.synchronizationTargets = make(map[string]postgresSpec.LSN)
+	if sr.exporter == nil {
+		return
+	}
+
+	sr.exporter.Metrics.ReplicationSlotUnsynced.Set(0)
+}
+
+// computeOldestUnsyncedSlotAge updates the time when every slot on the primary
+// has been first seen without a synchronized local copy, and returns the age of
+// the oldest one. A local copy is synchronized when it exists and has reached
+// the position its slot had on the primary during the previous synchronization,
+// which is why a standby which is not able to keep up, even without errors, is
+// detected as well. A local copy which doesn't reserve WAL is never synchronized
+func computeOldestUnsyncedSlotAge(
+	unsyncedSince map[string]time.Time,
+	synchronizationTargets map[string]postgresSpec.LSN,
+	slotsInPrimary infrastructure.ReplicationSlotList,
+	slotsInLocal infrastructure.ReplicationSlotList,
+	mySlotName string,
+	now time.Time,
+) time.Duration {
+	var oldest time.Duration
+	expectedSlots := make(map[string]bool)
+	for _, slot := range slotsInPrimary.Items {
+		if slot.SlotName == mySlotName || slot.RestartLSN == "" {
+			continue
+		}
+		expectedSlots[slot.SlotName] = true
+
+		localSlot := slotsInLocal.Get(slot.SlotName)
+		target, hasTarget := synchronizationTargets[slot.SlotName]
+		synchronizationTargets[slot.SlotName] = postgresSpec.LSN(slot.RestartLSN)
+
+		// a local copy without a position, not reserving WAL, can't be advanced
+		// and is never synchronized
+		isSynchronized := localSlot != nil && localSlot.RestartLSN != "" &&
+			(!hasTarget || !postgresSpec.LSN(localSlot.RestartLSN).Less(target))
+		if isSynchronized {
+			delete(unsyncedSince, slot.SlotName)
+			continue
+		}
+
+		since, ok := unsyncedSince[slot.SlotName]
+		if !ok {
+			since = now
+			unsyncedSince[slot.SlotName] = now
+		}
+		if age := now.Sub(since); age > oldest {
+			oldest = age
+		}
+	}
+
+	for slotName := range synchronizationTargets {
+		if !expectedSlots[slotName] {
+			delete(synchronizationTargets, slotName)
+			delete(unsyncedSince, slotName)
+		}
+	}
+
+	return oldest
+}
+
+// computeSlotsConvergence compares the slots in the local instance with those in the primary,
+// reporting the ones that need to be created or dropped to align them
+func computeSlotsConvergence(
+	slotsInPrimary infrastructure.ReplicationSlotList,
+	slotsInLocal infrastructure.ReplicationSlotList,
+	mySlotName string,
+	extraSlotsPolicy apiv1.ExtraSlotsPolicy,
+) *postgresSpec.SlotsConvergence {
+	convergence := &postgresSpec.SlotsConvergence{}
+	for _, slot := range slotsInPrimary.Items {
+		if slot.SlotName == mySlotName || slot.RestartLSN == "" {
+			continue
+		}
+		if !slotsInLocal.Has(slot.SlotName) {
+			convergence.MissingSlots = append(convergence.MissingSlots, slot.SlotName)
+		}
+	}
+	for _, slot := range slotsInLocal.Items {
+		switch {
+		case isExtraSlotToBeDisabled(slot, slotsInPrimary, mySlotName, extraSlotsPolicy):
+			convergence.DisabledSlots = append(convergence.DisabledSlots, slot.SlotName)
+		case !slotsInPrimary.Has(slot.SlotName) || slot.SlotName == mySlotName:
+			convergence.ExtraSlots = append(convergence.ExtraSlots, slot.SlotName)
+		}
+	}
+
+	convergence.Converged = len(convergence.MissingSlots) == 0 && len(convergence.ExtraSlots) == 0
+	return convergence
 }
 
-// synchronizeReplicationSlots aligns the slots in the local instance with those in the primary
+// synchronizeReplicationSlots aligns the slots in the local instance with those in the primary,
+// returning the list of the slots in the primary, the list of the local slots before
+// the synchronization, and how much they were diverged
 func synchronizeReplicationSlots(
 	ctx context.Context,
 	primarySlotManager infrastructure.Manager,
 	localSlotManager infrastructure.Manager,
 	podName string,
 	config *apiv1.ReplicationSlotsConfiguration,
-) error {
+) (
+	slotsInPrimary infrastructure.ReplicationSlotList,
+	slotsInLocal infrastructure.ReplicationSlotList,
+	convergence *postgresSpec.SlotsConvergence,
+	err error,
+) {
 	contextLog := log.FromContext(ctx).WithName("synchronizeReplicationSlots")
 	contextLog.Trace("Invoked",
 		"primary", primarySlotManager,
@@ -125,46 +405,155 @@ func synchronizeReplicationSlots(
 		"podName", podName,
 		"config", config)
 
-	slotsInPrimary, err := primarySlotManager.List(ctx, config)
+	slotsInPrimary, err = primarySlotManager.List(ctx, config)
 	if err != nil {
-		return fmt.Errorf("getting replication slot status from primary: %v", err)
+		return infrastructure.ReplicationSlotList{}, infrastructure.ReplicationSlotList{}, nil,
+			fmt.Errorf("getting replication slot status from primary: %v", err)
 	}
 	contextLog.Trace("primary slot status", "slotsInPrimary", slotsInPrimary)
 
-	slotsInLocal, err := localSlotManager.List(ctx, config)
+	slotsInLocal, err = localSlotManager.List(ctx, config)
 	if err != nil {
-		return fmt.Errorf("getting replication slot status from local: %v", err)
+		return infrastructure.ReplicationSlotList{}, infrastructure.ReplicationSlotList{}, nil,
+			fmt.Errorf("getting replication slot status from local: %v", err)
 	}
 	contextLog.Trace("local slot status", "slotsInLocal", slotsInLocal)
 
 	mySlotName := config.HighAvailability.GetSlotNameFromInstanceName(podName)
+	extraSlotsPolicy := config.GetExtraSlotsPolicy()
+	convergence = computeSlotsConvergence(slotsInPrimary, slotsInLocal, mySlotName, extraSlotsPolicy)
 
+	if config.IsPaused() {
+		contextLog.Info("Replication slots synchronization is paused, not applying the differences with the primary",
+			"missingSlots", convergence.MissingSlots,
+			"extraSlots", convergence.ExtraSlots)
+		convergence.Paused = true
+		return slotsInPrimary, slotsInLocal, convergence, nil
+	}
+
+	var operations []slotOperation
 	for _, slot := range slotsInPrimary.Items {
+		slot := slot
 		if slot.SlotName == mySlotName {
 			continue
 		}
 		if slot.RestartLSN == "" {
 			continue
 		}
-		if !slotsInLocal.Has(slot.SlotName) {
-			err := localSlotManager.Create(ctx, slot)
-			if err != nil {
-				return err
+		createNeeded := !slotsInLocal.Has(slot.SlotName)
+		operations = append(operations, func() *slotOperationError {
+			if createNeeded {
+				if err := localSlotManager.Create(ctx, slot); err != nil {
+					return &slotOperationError{operation: slotOperationCreate, slotName: slot.SlotName, err: err}
+				}
 			}
-		}
-		err := localSlotManager.Update(ctx, slot)
-		if err != nil {
-			return err
-		}
+			if err := localSlotManager.Update(ctx, slot); err != nil {
+				return &slotOperationError{operation: slotOperationUpdate, slotName: slot.SlotName, err: err}
+			}
+			return nil
+		})
 	}
 	for _, slot := range slotsInLocal.Items {
-		if !slotsInPrimary.Has(slot.SlotName) || slot.SlotName == mySlotName {
-			err := localSlotManager.Delete(ctx, slot)
-			if err != nil {
-				return err
+		slot := slot
+		switch {
+		case isExtraSlotToBeDisabled(slot, slotsInPrimary, mySlotName, extraSlotsPolicy):
+			operations = append(operations, func() *slotOperationError {
+				if err := localSlotManager.Disable(ctx, slot); err != nil {
+					return &slotOperationError{operation: slotOperationDisable, slotName: slot.SlotName, err: err}
+				}
+				return nil
+			})
+		case !slotsInPrimary.Has(slot.SlotName) || slot.SlotName == mySlotName:
+			operations = append(operations, func() *slotOperationError {
+				if err := localSlotManager.Delete(ctx, slot); err != nil {
+					return &slotOperationError{operation: slotOperationDelete, slotName: slot.SlotName, err: err}
+				}
+				return nil
+			})
+		}
+	}
+
+	if err := applySlotOperations(operations, config.GetSynchronizationConcurrency()); err != nil {
+		return infrastructure.ReplicationSlotList{}, infrastructure.ReplicationSlotList{}, nil, err
+	}
+
+	return slotsInPrimary, slotsInLocal, convergence, nil
+}
+
+// isExtraSlotToBeDisabled checks whether a local replication slot which
+// doesn't exist anymore in the primary should be kept disabled instead of
+// being dropped. The slot named after the local instance is always dropped
+func isExtraSlotToBeDisabled(
+	slot infrastructure.ReplicationSlot,
+	slotsInPrimary infrastructure.ReplicationSlotList,
+	mySlotName string,
+	extraSlotsPolicy apiv1.ExtraSlotsPolicy,
+) bool {
+	return extraSlotsPolicy == apiv1.ExtraSlotsPolicyDisable &&
+		slot.SlotName != mySlotName &&
+		!slotsInPrimary.Has(slot.SlotName)
+}
+
+// slotOperation is the set of operations needed to synchronize
+// a local replication slot with the primary
+type slotOperation func() *slotOperationError
+
+// applySlotOperations applies the passed operations using a pool of
+// concurrency workers. After the first failure no further operation is
+// started, and the failures of the ones already running are aggregated
+// in the returned error
+func applySlotOperations(operations []slotOperation, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		failures slotOperationErrors
+	)
+	hasFailed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(failures) > 0
+	}
+
+	queue := make(chan slotOperation)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for operation := range queue {
+				if hasFailed() {
+					continue
+				}
+				if err := operation(); err != nil {
+					mutex.Lock()
+					failures = append(failures, err)
+					mutex.Unlock()
+				}
 			}
+		}()
+	}
+
+	for _, operation := range operations {
+		if hasFailed() {
+			break
 		}
+		queue <- operation
 	}
+	close(queue)
+	wg.Wait()
 
-	return nil
+	switch len(failures) {
+	case 0:
+		return nil
+	case 1:
+		return failures[0]
+	default:
+		sort.Slice(failures, func(i, j int) bool {
+			return failures[i].slotName < failures[j].slotName
+		})
+		return failures
+	}
 }
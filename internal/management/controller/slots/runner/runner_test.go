@@ -18,10 +18,15 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/slots/infrastructure"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -33,16 +38,23 @@ type fakeSlot struct {
 }
 
 type fakeSlotManager struct {
+	mutex        sync.Mutex
 	slots        map[string]fakeSlot
 	slotsUpdated int
 	slotsCreated int
 	slotsDeleted int
+
+	// slotsDisabled contains the names of the disabled slots
+	slotsDisabled []string
 }
 
 func (sm *fakeSlotManager) List(
 	ctx context.Context,
 	config *apiv1.ReplicationSlotsConfiguration,
 ) (infrastructure.ReplicationSlotList, error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
 	var slotList infrastructure.ReplicationSlotList
 	for _, slot := range sm.slots {
 		slotList.Items = append(slotList.Items, infrastructure.ReplicationSlot{
@@ -56,6 +68,9 @@ func (sm *fakeSlotManager) List(
 }
 
 func (sm *fakeSlotManager) Update(ctx context.Context, slot infrastructure.ReplicationSlot) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
 	localSlot, found := sm.slots[slot.SlotName]
 	if !found {
 		return fmt.Errorf("while updating slot: Slot %s not found", slot.SlotName)
@@ -68,6 +83,9 @@ func (sm *fakeSlotManager) Update(ctx context.Context, slot infrastructure.Repli
 }
 
 func (sm *fakeSlotManager) Create(ctx context.Context, slot infrastructure.ReplicationSlot) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
 	if _, found := sm.slots[slot.SlotName]; found {
 		return fmt.Errorf("while creating slot: Slot %s already exists", slot.SlotName)
 	}
@@ -77,6 +95,9 @@ func (sm *fakeSlotManager) Create(ctx context.Context, slot infrastructure.Repli
 }
 
 func (sm *fakeSlotManager) Delete(ctx context.Context, slot infrastructure.ReplicationSlot) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
 	if _, found := sm.slots[slot.SlotName]; !found {
 		return fmt.Errorf("while deleting slot: Slot %s not found", slot.SlotName)
 	}
@@ -85,6 +106,17 @@ func (sm *fakeSlotManager) Delete(ctx context.Context, slot infrastructure.Repli
 	return nil
 }
 
+func (sm *fakeSlotManager) Disable(ctx context.Context, slot infrastructure.ReplicationSlot) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if _, found := sm.slots[slot.SlotName]; !found {
+		return fmt.Errorf("while disabling slot: Slot %s not found", slot.SlotName)
+	}
+	sm.slotsDisabled = append(sm.slotsDisabled, slot.SlotName)
+	return nil
+}
+
 var _ = Describe("Slot synchronization", func() {
 	ctx := context.TODO()
 	localPodName := "cluster-2"
@@ -114,8 +146,11 @@ var _ = Describe("Slot synchronization", func() {
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(localSlotsBefore.Items).Should(HaveLen(0))
 
-		err = synchronizeReplicationSlots(context.TODO(), primary, local, localPodName, &config)
+		_, _, convergence, err := synchronizeReplicationSlots(context.TODO(), primary, local, localPodName, &config)
 		Expect(err).ShouldNot(HaveOccurred())
+		Expect(convergence.Converged).To(BeFalse())
+		Expect(convergence.MissingSlots).To(ConsistOf(slot3, slot4))
+		Expect(convergence.ExtraSlots).To(BeEmpty())
 
 		localSlotsAfter, err := local.List(ctx, &config)
 		Expect(err).ShouldNot(HaveOccurred())
@@ -130,8 +165,9 @@ var _ = Describe("Slot synchronization", func() {
 		err := primary.Update(ctx, infrastructure.ReplicationSlot{SlotName: slot3, RestartLSN: newLSN})
 		Expect(err).ShouldNot(HaveOccurred())
 
-		err = synchronizeReplicationSlots(context.TODO(), primary, local, localPodName, &config)
+		_, _, convergence, err := synchronizeReplicationSlots(context.TODO(), primary, local, localPodName, &config)
 		Expect(err).ShouldNot(HaveOccurred())
+		Expect(convergence.Converged).To(BeTrue())
 
 		localSlotsAfter, err := local.List(ctx, &config)
 		Expect(err).ShouldNot(HaveOccurred())
@@ -145,8 +181,11 @@ var _ = Describe("Slot synchronization", func() {
 		err := primary.Delete(ctx, infrastructure.ReplicationSlot{SlotName: slot4})
 		Expect(err).ShouldNot(HaveOccurred())
 
-		err = synchronizeReplicationSlots(context.TODO(), primary, local, localPodName, &config)
+		_, _, convergence, err := synchronizeReplicationSlots(context.TODO(), primary, local, localPodName, &config)
 		Expect(err).ShouldNot(HaveOccurred())
+		Expect(convergence.Converged).To(BeFalse())
+		Expect(convergence.MissingSlots).To(BeEmpty())
+		Expect(convergence.ExtraSlots).To(ConsistOf(slot4))
 
 		localSlotsAfter, err := local.List(ctx, &config)
 		Expect(err).ShouldNot(HaveOccurred())
@@ -155,3 +194,344 @@ var _ = Describe("Slot synchronization", func() {
 		Expect(local.slotsDeleted).To(Equal(1))
 	})
 })
+
+var _ = Describe("Slot inactivity", func() {
+	start := time.Date(2022, 11, 7, 10, 0, 0, 0, time.UTC)
+
+	slots := func(active bool) infrastructure.ReplicationSlotList {
+		return infrastructure.ReplicationSlotList{
+			Items: []infrastructure.ReplicationSlot{
+				{SlotName: "_cnpg_cluster_2", Active: true},
+				{SlotName: "_cnpg_cluster_3", Active: active},
+			},
+		}
+	}
+
+	It("tracks for how long a slot has been inactive", func() {
+		inactiveSince := make(map[string]time.Time)
+
+		inactivity := computeSlotsInactivity(inactiveSince, slots(false), start)
+		Expect(inactivity).To(Equal(map[string]time.Duration{"_cnpg_cluster_3": 0}))
+
+		inactivity = computeSlotsInactivity(inactiveSince, slots(false), start.Add(90*time.Second))
+		Expect(inactivity).To(Equal(map[string]time.Duration{"_cnpg_cluster_3": 90 * time.Second}))
+	})
+
+	It("forgets slots which become active again", func() {
+		inactiveSince := make(map[string]time.Time)
+
+		computeSlotsInactivity(inactiveSince, slots(false), start)
+		inactivity := computeSlotsInactivity(inactiveSince, slots(true), start.Add(30*time.Second))
+		Expect(inactivity).To(BeEmpty())
+		Expect(inactiveSince).To(BeEmpty())
+
+		inactivity = computeSlotsInactivity(inactiveSince, slots(false), start.Add(60*time.Second))
+		Expect(inactivity).To(Equal(map[string]time.Duration{"_cnpg_cluster_3": 0}))
+	})
+
+	It("forgets slots which have been dropped", func() {
+		inactiveSince := make(map[string]time.Time)
+
+		computeSlotsInactivity(inactiveSince, slots(false), start)
+		inactivity := computeSlotsInactivity(inactiveSince, infrastructure.ReplicationSlotList{}, start)
+		Expect(inactivity).To(BeEmpty())
+		Expect(inactiveSince).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Unsynced slots", func() {
+	const mySlotName = "_cnpg_cluster_2"
+	start := time.Date(2022, 11, 7, 10, 0, 0, 0, time.UTC)
+
+	slotList := func(positions map[string]string) infrastructure.ReplicationSlotList {
+		var list infrastructure.ReplicationSlotList
+		for name, position := range positions {
+			list.Items = append(list.Items, infrastructure.ReplicationSlot{SlotName: name, RestartLSN: position})
+		}
+		return list
+	}
+
+	var (
+		unsyncedSince          map[string]time.Time
+		synchronizationTargets map[string]postgres.LSN
+	)
+
+	BeforeEach(func() {
+		unsyncedSince = make(map[string]time.Time)
+		synchronizationTargets = make(map[string]postgres.LSN)
+	})
+
+	compute := func(primary, local map[string]string, now time.Time) time.Duration {
+		return computeOldestUnsyncedSlotAge(
+			unsyncedSince, synchronizationTargets, slotList(primary), slotList(local), mySlotName, now)
+	}
+
+	It("is zero while the local copies keep up with the primary", func() {
+		Expect(compute(
+			map[string]string{mySlotName: "0/3000000", "_cnpg_cluster_3": "0/3000000"},
+			map[string]string{"_cnpg_cluster_3": "0/2000000"},
+			start,
+		)).To(BeZero())
+
+		Expect(compute(
+			map[string]string{"_cnpg_cluster_3": "0/4000000"},
+			map[string]string{"_cnpg_cluster_3": "0/3000000"},
+			start.Add(30*time.Second),
+		)).To(BeZero())
+		Expect(unsyncedSince).To(BeEmpty())
+	})
+
+	It("reports the age of the oldest slot missing or behind the last synchronization", func() {
+		Expect(compute(
+			map[string]string{"_cnpg_cluster_3": "0/3000000"},
+			map[string]string{"_cnpg_cluster_3": "0/3000000"},
+			start,
+		)).To(BeZero())
+
+		// the local copy didn't reach the position of the previous
+		// synchronization, and another slot is missing
+		Expect(compute(
+			map[string]string{"_cnpg_cluster_3": "0/5000000", "_cnpg_cluster_4": "0/5000000"},
+			map[string]string{"_cnpg_cluster_3": "0/2800000"},
+			start.Add(30*time.Second),
+		)).To(BeZero())
+		Expect(compute(
+			map[string]string{"_cnpg_cluster_3": "0/6000000", "_cnpg_cluster_4": "0/6000000"},
+			map[string]string{"_cnpg_cluster_3": "0/4000000", "_cnpg_cluster_4": "0/5000000"},
+			start.Add(60*time.Second),
+		)).To(Equal(30 * time.Second))
+		Expect(unsyncedSince).To(HaveKey("_cnpg_cluster_3"))
+		Expect(unsyncedSince).ToNot(HaveKey("_cnpg_cluster_4"))
+	})
+
+	It("counts the local copies without a position as unsynchronized", func() {
+		Expect(compute(
+			map[string]string{"_cnpg_cluster_3": "0/3000000"},
+			map[string]string{"_cnpg_cluster_3": ""},
+			start,
+		)).To(BeZero())
+		Expect(compute(
+			map[string]string{"_cnpg_cluster_3": "0/3000000"},
+			map[string]string{"_cnpg_cluster_3": ""},
+			start.Add(30*time.Second),
+		)).To(Equal(30 * time.Second))
+	})
+
+	It("forgets the slots which have been dropped from the primary", func() {
+		compute(map[string]string{"_cnpg_cluster_3": "0/3000000"}, nil, start)
+		Expect(unsyncedSince).To(HaveKey("_cnpg_cluster_3"))
+
+		Expect(compute(nil, nil, start.Add(30*time.Second))).To(BeZero())
+		Expect(unsyncedSince).To(BeEmpty())
+		Expect(synchronizationTargets).To(BeEmpty())
+	})
+})
+
+type failingCreateSlotManager struct {
+	*fakeSlotManager
+	err error
+}
+
+func (sm failingCreateSlotManager) Create(context.Context, infrastructure.ReplicationSlot) error {
+	return sm.err
+}
+
+var _ = Describe("Slot synchronization errors", func() {
+	config := apiv1.ReplicationSlotsConfiguration{
+		HighAvailability: &apiv1.ReplicationSlotsHAConfiguration{
+			Enabled:    true,
+			SlotPrefix: "_cnpg_",
+		},
+	}
+
+	It("reports the operation and the slot which failed", func() {
+		primary := &fakeSlotManager{
+			slots: map[string]fakeSlot{
+				"_cnpg_cluster_3": {name: "_cnpg_cluster_3", restartLSN: "0/302C4D8"},
+			},
+		}
+		local := failingCreateSlotManager{
+			fakeSlotManager: &fakeSlotManager{slots: map[string]fakeSlot{}},
+			err:             fakeSQLStateError{code: "53400"},
+		}
+
+		_, _, _, err := synchronizeReplicationSlots(context.TODO(), primary, local, "cluster-2", &config)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`cannot create replication slot "_cnpg_cluster_3"`))
+
+		var operationError *slotOperationError
+		Expect(errors.As(err, &operationError)).To(BeTrue())
+		Expect(operationError.operation).To(Equal(slotOperationCreate))
+		Expect(getErrorReason(operationError.err)).To(Equal(errorReasonLimitExceeded))
+	})
+})
+
+var _ = Describe("Slot synchronization concurrency", func() {
+	failure := func(slotName string) *slotOperationError {
+		return &slotOperationError{
+			operation: slotOperationUpdate,
+			slotName:  slotName,
+			err:       errors.New("failure"),
+		}
+	}
+
+	It("applies every operation without exceeding the concurrency", func() {
+		var running, maxRunning, completed int32
+		operations := make([]slotOperation, 20)
+		for i := range operations {
+			operations[i] = func() *slotOperationError {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					observed := atomic.LoadInt32(&maxRunning)
+					if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				atomic.AddInt32(&completed, 1)
+				return nil
+			}
+		}
+
+		Expect(applySlotOperations(operations, 4)).To(Succeed())
+		Expect(completed).To(BeEquivalentTo(20))
+		Expect(maxRunning).To(BeNumerically("<=", 4))
+	})
+
+	It("stops at the first failure when the operations are applied one at a time", func() {
+		var completed int32
+		operations := []slotOperation{
+			func() *slotOperationError { return failure("_cnpg_cluster_3") },
+			func() *slotOperationError {
+				atomic.AddInt32(&completed, 1)
+				return nil
+			},
+		}
+
+		err := applySlotOperations(operations, 1)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`"_cnpg_cluster_3"`))
+		Expect(completed).To(BeZero())
+	})
+
+	It("aggregates the failures of the operations running at the same time", func() {
+		var started sync.WaitGroup
+		started.Add(2)
+		failing := func(slotName string) slotOperation {
+			return func() *slotOperationError {
+				started.Done()
+				started.Wait()
+				return failure(slotName)
+			}
+		}
+
+		err := applySlotOperations([]slotOperation{failing("_cnpg_cluster_4"), failing("_cnpg_cluster_3")}, 2)
+		var operationErrors slotOperationErrors
+		Expect(errors.As(err, &operationErrors)).To(BeTrue())
+		Expect(operationErrors).To(HaveLen(2))
+		Expect(operationErrors[0].slotName).To(Equal("_cnpg_cluster_3"))
+		Expect(err.Error()).To(And(
+			ContainSubstring(`"_cnpg_cluster_3"`),
+			ContainSubstring(`"_cnpg_cluster_4"`),
+		))
+	})
+
+	It("synchronizes the replication slots concurrently", func() {
+		config := apiv1.ReplicationSlotsConfiguration{
+			HighAvailability: &apiv1.ReplicationSlotsHAConfiguration{
+				Enabled:    true,
+				SlotPrefix: "_cnpg_",
+			},
+			SynchronizationConcurrency: 4,
+		}
+		primary := &fakeSlotManager{slots: map[string]fakeSlot{}}
+		local := &fakeSlotManager{slots: map[string]fakeSlot{
+			"_cnpg_cluster_99": {name: "_cnpg_cluster_99", restartLSN: "0/301C4D8"},
+		}}
+		for i := 3; i < 50; i++ {
+			name := fmt.Sprintf("_cnpg_cluster_%d", i)
+			primary.slots[name] = fakeSlot{name: name, restartLSN: "0/302C4D8"}
+		}
+
+		_, _, _, err := synchronizeReplicationSlots(context.TODO(), primary, local, "cluster-2", &config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(local.slotsCreated).To(Equal(47))
+		Expect(local.slotsDeleted).To(Equal(1))
+		Expect(local.slots).To(HaveLen(47))
+	})
+})
+
+var _ = Describe("Extra slots policy", func() {
+	config := apiv1.ReplicationSlotsConfiguration{
+		HighAvailability: &apiv1.ReplicationSlotsHAConfiguration{
+			Enabled:    true,
+			SlotPrefix: "_cnpg_",
+		},
+		ExtraSlotsPolicy: apiv1.ExtraSlotsPolicyDisable,
+	}
+
+	It("disables the extra slots instead of dropping them", func() {
+		primary := &fakeSlotManager{slots: map[string]fakeSlot{
+			"_cnpg_cluster_2": {name: "_cnpg_cluster_2", restartLSN: "0/301C4D8"},
+			"_cnpg_cluster_3": {name: "_cnpg_cluster_3", restartLSN: "0/302C4D8"},
+		}}
+		local := &fakeSlotManager{slots: map[string]fakeSlot{
+			"_cnpg_cluster_2": {name: "_cnpg_cluster_2", restartLSN: "0/301C4D8"},
+			"_cnpg_cluster_3": {name: "_cnpg_cluster_3", restartLSN: "0/302C4D8"},
+			"_cnpg_cluster_4": {name: "_cnpg_cluster_4", restartLSN: "0/300C4D8"},
+		}}
+
+		_, _, convergence, err := synchronizeReplicationSlots(context.TODO(), primary, local, "cluster-2", &config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(local.slotsDisabled).To(ConsistOf("_cnpg_cluster_4"))
+		Expect(local.slotsDeleted).To(Equal(1))
+		Expect(local.slots).To(HaveKey("_cnpg_cluster_4"))
+		Expect(local.slots).ToNot(HaveKey("_cnpg_cluster_2"))
+
+		Expect(convergence.DisabledSlots).To(ConsistOf("_cnpg_cluster_4"))
+		Expect(convergence.ExtraSlots).To(ConsistOf("_cnpg_cluster_2"))
+		Expect(convergence.Converged).To(BeFalse())
+
+		_, _, convergence, err = synchronizeReplicationSlots(context.TODO(), primary, local, "cluster-2", &config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(convergence.DisabledSlots).To(ConsistOf("_cnpg_cluster_4"))
+		Expect(convergence.Converged).To(BeTrue())
+	})
+})
+
+var _ = Describe("Paused slot synchronization", func() {
+	config := apiv1.ReplicationSlotsConfiguration{
+		HighAvailability: &apiv1.ReplicationSlotsHAConfiguration{
+			Enabled:    true,
+			SlotPrefix: "_cnpg_",
+		},
+		Paused: true,
+	}
+
+	It("reports the differences with the primary without applying them", func() {
+		primary := &fakeSlotManager{slots: map[string]fakeSlot{
+			"_cnpg_cluster_2": {name: "_cnpg_cluster_2", restartLSN: "0/301C4D8"},
+			"_cnpg_cluster_3": {name: "_cnpg_cluster_3", restartLSN: "0/302C4D8"},
+			"_cnpg_cluster_4": {name: "_cnpg_cluster_4", restartLSN: "0/303C4D8"},
+		}}
+		local := &fakeSlotManager{slots: map[string]fakeSlot{
+			"_cnpg_cluster_3": {name: "_cnpg_cluster_3", restartLSN: "0/300C4D8"},
+			"_cnpg_cluster_5": {name: "_cnpg_cluster_5", restartLSN: "0/300C4D8"},
+		}}
+
+		slotsInPrimary, _, convergence, err := synchronizeReplicationSlots(
+			context.TODO(), primary, local, "cluster-2", &config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(slotsInPrimary.Items).To(HaveLen(3))
+		Expect(convergence.Paused).To(BeTrue())
+		Expect(convergence.Converged).To(BeFalse())
+		Expect(convergence.MissingSlots).To(ConsistOf("_cnpg_cluster_4"))
+		Expect(convergence.ExtraSlots).To(ConsistOf("_cnpg_cluster_5"))
+
+		Expect(local.slotsCreated).To(BeZero())
+		Expect(local.slotsUpdated).To(BeZero())
+		Expect(local.slotsDeleted).To(BeZero())
+		Expect(local.slots["_cnpg_cluster_3"].restartLSN).To(Equal("0/300C4D8"))
+	})
+})
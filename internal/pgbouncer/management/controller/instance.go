@@ -17,6 +17,7 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -32,7 +33,7 @@ import (
 // implementations should be thread safe
 type PgBouncerInstanceInterface interface {
 	Paused() bool
-	Pause() error
+	Pause(ctx context.Context) error
 	Resume() error
 	Reload() error
 }
@@ -71,8 +72,9 @@ func (p *pgBouncerInstance) Paused() bool {
 	return p.paused
 }
 
-// Pause the instance, thread safe
-func (p *pgBouncerInstance) Pause() error {
+// Pause the instance, thread safe. As PgBouncer waits for the running
+// transactions to complete, the passed context can be used to bound it
+func (p *pgBouncerInstance) Pause(ctx context.Context) error {
 	// First step: connect to the pgbouncer administrative database
 	db, err := p.pool.Connection("pgbouncer")
 	if err != nil {
@@ -85,12 +87,15 @@ func (p *pgBouncerInstance) Pause() error {
 	// pgbouncer to be really up and the user could have created
 	// a pooler which is paused from the start.
 	err = retry.OnError(retry.DefaultBackoff, func(err error) bool {
+		if ctx.Err() != nil {
+			return false
+		}
 		if errors.Is(err, os.ErrNotExist) {
 			return true
 		}
 		return true
 	}, func() error {
-		_, err = db.Exec("PAUSE")
+		_, err = db.ExecContext(ctx, "PAUSE")
 		return err
 	})
 	if err != nil {
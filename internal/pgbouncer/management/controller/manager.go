@@ -20,7 +20,10 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
 	"time"
 
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
@@ -36,6 +39,7 @@ import (
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/pgbouncer/config"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
 // PgBouncerReconciler can reconcile the status of the PostgreSQL cluster with
@@ -46,6 +50,15 @@ type PgBouncerReconciler struct {
 	poolerWatch          watch.Interface
 	instance             PgBouncerInstanceInterface
 	poolerNamespacedName types.NamespacedName
+	podName              string
+
+	// pauseMutex serializes the synchronization of the pause, which happens
+	// both when the Pooler changes and when a switchover pause expires
+	pauseMutex sync.Mutex
+
+	// switchoverPauseTimer resumes PgBouncer when the
+	// pause requested for a switchover expires
+	switchoverPauseTimer *time.Timer
 }
 
 // NewPgBouncerReconciler creates a new pgbouncer reconciler
@@ -55,10 +68,16 @@ func NewPgBouncerReconciler(poolerNamespacedName types.NamespacedName) (*PgBounc
 		return nil, err
 	}
 
+	podName, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
 	return &PgBouncerReconciler{
 		client:               client,
 		instance:             NewPgBouncerInstance(),
 		poolerNamespacedName: poolerNamespacedName,
+		podName:              podName,
 	}, nil
 }
 
@@ -135,16 +154,26 @@ func (r *PgBouncerReconciler) Reconcile(ctx context.Context, event *watch.Event)
 		return fmt.Errorf("while reconciling configuration: %w", err)
 	}
 
-	return r.synchronizePause(pooler)
+	return r.synchronizePause(ctx, pooler)
 }
 
 // synchronizePause ensure that the pause flag inside the Pooler
-// specification matches the PgBouncer status
-func (r *PgBouncerReconciler) synchronizePause(pooler *apiv1.Pooler) error {
+// specification, or the pause requested by the operator for a switchover,
+// matches the PgBouncer status
+func (r *PgBouncerReconciler) synchronizePause(ctx context.Context, pooler *apiv1.Pooler) error {
+	r.pauseMutex.Lock()
+	defer r.pauseMutex.Unlock()
+
+	_, deadline, switchoverPause := pooler.GetSwitchoverPauseRequest()
+	switchoverPause = switchoverPause && time.Now().Before(deadline)
+	if switchoverPause {
+		r.scheduleSwitchoverPauseExpiration(ctx, deadline)
+	}
+
 	isPaused := r.instance.Paused()
-	shouldBePaused := pooler.Spec.PgBouncer.IsPaused()
+	shouldBePaused := pooler.Spec.PgBouncer.IsPaused() || switchoverPause
 	if shouldBePaused && !isPaused {
-		if err := r.instance.Pause(); err != nil {
+		if err := r.pause(ctx, pooler, deadline); err != nil {
 			return fmt.Errorf("while pausing instance: %w", err)
 		}
 	}
@@ -153,9 +182,80 @@ func (r *PgBouncerReconciler) synchronizePause(pooler *apiv1.Pooler) error {
 			return fmt.Errorf("while resuming instance: %w", err)
 		}
 	}
+
+	return r.synchronizeSwitchoverPauseAcknowledgement(ctx, pooler, switchoverPause)
+}
+
+// pause pauses PgBouncer. When the pause has been requested only for a
+// switchover, PgBouncer is resumed if the running transactions don't
+// complete before the pause expires
+func (r *PgBouncerReconciler) pause(ctx context.Context, pooler *apiv1.Pooler, deadline time.Time) error {
+	if pooler.Spec.PgBouncer.IsPaused() {
+		return r.instance.Pause(ctx)
+	}
+
+	pauseCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	err := r.instance.Pause(pauseCtx)
+	if err == nil || !errors.Is(pauseCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	log.Warning("The switchover pause expired before the running transactions completed, resuming PgBouncer")
+	if err := r.instance.Resume(); err != nil {
+		log.Warning("Error while resuming PgBouncer after the switchover pause expiration", "err", err)
+	}
 	return nil
 }
 
+// scheduleSwitchoverPauseExpiration makes sure that PgBouncer is resumed
+// when the switchover pause expires, even if the Pooler doesn't change
+func (r *PgBouncerReconciler) scheduleSwitchoverPauseExpiration(ctx context.Context, deadline time.Time) {
+	if r.switchoverPauseTimer != nil {
+		r.switchoverPauseTimer.Stop()
+	}
+
+	r.switchoverPauseTimer = time.AfterFunc(time.Until(deadline), func() {
+		var pooler apiv1.Pooler
+		if err := r.client.Get(ctx, r.poolerNamespacedName, &pooler); err != nil {
+			log.Error(err, "while getting the pooler after the switchover pause expiration")
+			return
+		}
+		if err := r.synchronizePause(ctx, &pooler); err != nil {
+			log.Error(err, "while resuming PgBouncer after the switchover pause expiration")
+		}
+	})
+}
+
+// synchronizeSwitchoverPauseAcknowledgement reports in the Pooler status
+// whether this PgBouncer instance is paused for the switchover pause
+// requested by the operator, which waits for it before switching over
+func (r *PgBouncerReconciler) synchronizeSwitchoverPauseAcknowledgement(
+	ctx context.Context,
+	pooler *apiv1.Pooler,
+	switchoverPause bool,
+) error {
+	acknowledgement, acknowledged := pooler.Status.SwitchoverPausedInstances[r.podName]
+	request := pooler.Annotations[utils.SwitchoverPauseAnnotationName]
+	shouldBeAcknowledged := switchoverPause && r.instance.Paused()
+	if shouldBeAcknowledged == acknowledged && (!acknowledged || acknowledgement == request) {
+		return nil
+	}
+
+	original := pooler.DeepCopy()
+	if shouldBeAcknowledged {
+		if pooler.Status.SwitchoverPausedInstances == nil {
+			pooler.Status.SwitchoverPausedInstances = make(map[string]string)
+		}
+		pooler.Status.SwitchoverPausedInstances[r.podName] = request
+	} else {
+		delete(pooler.Status.SwitchoverPausedInstances, r.podName)
+	}
+
+	return r.client.Status().Patch(ctx, pooler, ctrl.MergeFrom(original))
+}
+
 // synchronizeConfig ensure that the configuration derived from
 // the pooler specification matches the one loaded in PgBouncer
 func (r *PgBouncerReconciler) synchronizeConfig(ctx context.Context, pooler *apiv1.Pooler) error {
@@ -0,0 +1,110 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+)
+
+// secretKeyReference is a reference to a secret key found in the cluster spec
+type secretKeyReference struct {
+	// path is the path of the reference in the cluster spec
+	path *field.Path
+
+	// selector is the referenced secret key
+	selector apiv1.SecretKeySelector
+}
+
+// getCredentialsReferences returns the secret keys referenced by the object
+// store configurations of the passed cluster: the one used for backups, the
+// additional WAL archives and the ones of the external clusters
+func getCredentialsReferences(cluster *apiv1.Cluster) []secretKeyReference {
+	var result []secretKeyReference
+
+	if backup := cluster.Spec.Backup; backup != nil {
+		path := field.NewPath("spec", "backup")
+		result = append(result, getObjectStoreReferences(
+			path.Child("barmanObjectStore"), backup.BarmanObjectStore)...)
+
+		for idx := range backup.AdditionalWALArchives {
+			destination := &backup.AdditionalWALArchives[idx]
+			result = append(result, getObjectStoreReferences(
+				path.Child("additionalWalArchives").Index(idx).Child("barmanObjectStore"),
+				&destination.BarmanObjectStore)...)
+		}
+	}
+
+	for idx, externalCluster := range cluster.Spec.ExternalClusters {
+		result = append(result, getObjectStoreReferences(
+			field.NewPath("spec", "externalClusters").Index(idx).Child("barmanObjectStore"),
+			externalCluster.BarmanObjectStore)...)
+	}
+
+	return result
+}
+
+// credentialsReferencesChanged checks if the secret keys referenced by the
+// object store configurations of the passed clusters are different
+func credentialsReferencesChanged(oldCluster, cluster *apiv1.Cluster) bool {
+	return !reflect.DeepEqual(getCredentialsReferences(oldCluster), getCredentialsReferences(cluster))
+}
+
+// getObjectStoreReferences returns the secret keys referenced
+// by an object store configuration
+func getObjectStoreReferences(
+	path *field.Path,
+	configuration *apiv1.BarmanObjectStoreConfiguration,
+) []secretKeyReference {
+	if configuration == nil {
+		return nil
+	}
+
+	var result []secretKeyReference
+	appendReference := func(path *field.Path, selector *apiv1.SecretKeySelector) {
+		if selector != nil {
+			result = append(result, secretKeyReference{path: path, selector: *selector})
+		}
+	}
+
+	if aws := configuration.AWS; aws != nil {
+		awsPath := path.Child("s3Credentials")
+		appendReference(awsPath.Child("accessKeyId"), aws.AccessKeyIDReference)
+		appendReference(awsPath.Child("secretAccessKey"), aws.SecretAccessKeyReference)
+		appendReference(awsPath.Child("region"), aws.RegionReference)
+		appendReference(awsPath.Child("sessionToken"), aws.SessionToken)
+	}
+
+	if azure := configuration.Azure; azure != nil {
+		azurePath := path.Child("azureCredentials")
+		appendReference(azurePath.Child("connectionString"), azure.ConnectionString)
+		appendReference(azurePath.Child("storageAccount"), azure.StorageAccount)
+		appendReference(azurePath.Child("storageKey"), azure.StorageKey)
+		appendReference(azurePath.Child("storageSasToken"), azure.StorageSasToken)
+	}
+
+	if google := configuration.Google; google != nil {
+		appendReference(path.Child("googleCredentials", "applicationCredentials"), google.ApplicationCredentials)
+	}
+
+	appendReference(path.Child("endpointCA"), configuration.EndpointCA)
+
+	return result
+}
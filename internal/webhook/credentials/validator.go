@@ -0,0 +1,202 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
+)
+
+// WebhookPath is the path where the webhook is served
+const WebhookPath = "/validate-credentials-postgresql-cnpg-io-v1"
+
+// credentialsLog is for logging in this package.
+var credentialsLog = log.WithName("credentials-webhook")
+
+// +kubebuilder:webhook:webhookVersions={v1},admissionReviewVersions={v1},verbs=create;update,path=/validate-credentials-postgresql-cnpg-io-v1,mutating=false,failurePolicy=fail,groups=postgresql.cnpg.io,resources=clusters;backups,versions=v1,name=vcredentials.kb.io,sideEffects=None
+
+// Validator is the admission handler rejecting the clusters, and the
+// backups of the clusters, whose object store configurations reference
+// secrets or keys that don't exist
+type Validator struct {
+	client  client.Reader
+	decoder *admission.Decoder
+}
+
+// NewValidator creates a new credentials validator, reading the
+// secrets with the passed client
+func NewValidator(client client.Reader) *Validator {
+	return &Validator{client: client}
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *Validator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	switch req.Kind.Kind {
+	case apiv1.ClusterKind:
+		return v.handleCluster(ctx, req)
+	case apiv1.BackupKind:
+		return v.handleBackup(ctx, req)
+	default:
+		return admission.Allowed("")
+	}
+}
+
+// handleCluster rejects the clusters referencing missing credentials.
+// Updates are only checked when they change the references to the secrets,
+// and the clusters being deleted are never checked, as the operator needs
+// to update them regardless of the secrets, i.e. to remove their finalizers
+func (v *Validator) handleCluster(ctx context.Context, req admission.Request) admission.Response {
+	var cluster apiv1.Cluster
+	if err := v.decoder.Decode(req, &cluster); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if cluster.DeletionTimestamp != nil || !utils.IsCredentialsCheckEnabled(&cluster.ObjectMeta) {
+		return admission.Allowed("")
+	}
+
+	if req.Operation == admissionv1.Update {
+		var oldCluster apiv1.Cluster
+		if err := v.decoder.DecodeRaw(req.OldObject, &oldCluster); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if !credentialsReferencesChanged(&oldCluster, &cluster) {
+			return admission.Allowed("")
+		}
+	}
+
+	result, err := v.validate(ctx, &cluster)
+	if err != nil {
+		return allowedWithWarning(req, err)
+	}
+
+	if len(result) == 0 {
+		return admission.Allowed("")
+	}
+
+	return admission.Denied(fmt.Sprintf("invalid object store credentials: %v", result.ToAggregate()))
+}
+
+// handleBackup rejects the new backups of the clusters referencing missing
+// credentials, as they would fail immediately
+func (v *Validator) handleBackup(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	var backup apiv1.Backup
+	if err := v.decoder.Decode(req, &backup); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var cluster apiv1.Cluster
+	err := v.client.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: backup.Spec.Cluster.Name}, &cluster)
+	if apierrs.IsNotFound(err) {
+		// The backup controller reports the missing cluster
+		return admission.Allowed("")
+	}
+	if err != nil {
+		return allowedWithWarning(req, err)
+	}
+
+	if !utils.IsCredentialsCheckEnabled(&cluster.ObjectMeta) {
+		return admission.Allowed("")
+	}
+
+	result, err := v.validate(ctx, &cluster)
+	if err != nil {
+		return allowedWithWarning(req, err)
+	}
+	if len(result) == 0 {
+		return admission.Allowed("")
+	}
+
+	return admission.Denied(fmt.Sprintf("the object store credentials of cluster %s are not valid: %v",
+		cluster.Name, result.ToAggregate()))
+}
+
+// allowedWithWarning admits a request which couldn't be validated,
+// warning the user about it
+func allowedWithWarning(req admission.Request, err error) admission.Response {
+	credentialsLog.Warning("Cannot check the object store credentials",
+		"kind", req.Kind.Kind, "name", req.Name, "namespace", req.Namespace, "err", err)
+	return admission.Allowed("").WithWarnings(
+		fmt.Sprintf("cannot check the object store credentials: %v", err))
+}
+
+// validate checks that the secrets, and their keys, referenced by the
+// object store configurations of the passed cluster exist
+func (v *Validator) validate(ctx context.Context, cluster *apiv1.Cluster) (field.ErrorList, error) {
+	var result field.ErrorList
+
+	secrets := make(map[string]*corev1.Secret)
+	for _, reference := range getCredentialsReferences(cluster) {
+		secret, found := secrets[reference.selector.Name]
+		if !found {
+			secret = &corev1.Secret{}
+			err := v.client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: reference.selector.Name}, secret)
+			switch {
+			case apierrs.IsNotFound(err):
+				secret = nil
+			case err != nil:
+				return nil, err
+			}
+			secrets[reference.selector.Name] = secret
+		}
+
+		switch {
+		case secret == nil:
+			result = append(result, field.NotFound(
+				reference.path.Child("name"),
+				reference.selector.Name))
+		case !secretHasKey(secret, reference.selector.Key):
+			result = append(result, field.Invalid(
+				reference.path.Child("key"),
+				reference.selector.Key,
+				fmt.Sprintf("the key doesn't exist in secret %s", reference.selector.Name)))
+		}
+	}
+
+	return result, nil
+}
+
+// secretHasKey checks if the passed key is contained in the secret
+func secretHasKey(secret *corev1.Secret, key string) bool {
+	if _, ok := secret.Data[key]; ok {
+		return true
+	}
+	_, ok := secret.StringData[key]
+	return ok
+}
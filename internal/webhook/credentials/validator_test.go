@@ -0,0 +1,265 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("credentials references", func() {
+	It("collects the references of every object store", func() {
+		cluster := &apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				Backup: &apiv1.BackupConfiguration{
+					BarmanObjectStore: &apiv1.BarmanObjectStoreConfiguration{
+						BarmanCredentials: apiv1.BarmanCredentials{
+							AWS: &apiv1.S3Credentials{
+								AccessKeyIDReference: &apiv1.SecretKeySelector{
+									LocalObjectReference: apiv1.LocalObjectReference{Name: "aws"},
+									Key:                  "ID",
+								},
+								SecretAccessKeyReference: &apiv1.SecretKeySelector{
+									LocalObjectReference: apiv1.LocalObjectReference{Name: "aws"},
+									Key:                  "KEY",
+								},
+							},
+						},
+					},
+					AdditionalWALArchives: []apiv1.WALArchiveDestination{
+						{
+							Name: "dr",
+							BarmanObjectStore: apiv1.BarmanObjectStoreConfiguration{
+								BarmanCredentials: apiv1.BarmanCredentials{
+									Google: &apiv1.GoogleCredentials{
+										ApplicationCredentials: &apiv1.SecretKeySelector{
+											LocalObjectReference: apiv1.LocalObjectReference{Name: "gcs"},
+											Key:                  "credentials.json",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				ExternalClusters: []apiv1.ExternalCluster{
+					{Name: "origin"},
+					{
+						Name: "archive",
+						BarmanObjectStore: &apiv1.BarmanObjectStoreConfiguration{
+							BarmanCredentials: apiv1.BarmanCredentials{
+								Azure: &apiv1.AzureCredentials{
+									ConnectionString: &apiv1.SecretKeySelector{
+										LocalObjectReference: apiv1.LocalObjectReference{Name: "azure"},
+										Key:                  "CONNECTION",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		var paths []string
+		for _, reference := range getCredentialsReferences(cluster) {
+			paths = append(paths, reference.path.String())
+		}
+		Expect(paths).To(Equal([]string{
+			"spec.backup.barmanObjectStore.s3Credentials.accessKeyId",
+			"spec.backup.barmanObjectStore.s3Credentials.secretAccessKey",
+			"spec.backup.additionalWalArchives[0].barmanObjectStore.googleCredentials.applicationCredentials",
+			"spec.externalClusters[1].barmanObjectStore.azureCredentials.connectionString",
+		}))
+	})
+
+	It("doesn't find references in clusters without object stores", func() {
+		Expect(getCredentialsReferences(&apiv1.Cluster{})).To(BeEmpty())
+	})
+})
+
+var _ = Describe("credentials validator", func() {
+	const namespace = "default"
+
+	newCluster := func(secretName, key string) *apiv1.Cluster {
+		return &apiv1.Cluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: apiv1.ClusterKind},
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-example", Namespace: namespace},
+			Spec: apiv1.ClusterSpec{
+				Backup: &apiv1.BackupConfiguration{
+					BarmanObjectStore: &apiv1.BarmanObjectStoreConfiguration{
+						BarmanCredentials: apiv1.BarmanCredentials{
+							Google: &apiv1.GoogleCredentials{
+								ApplicationCredentials: &apiv1.SecretKeySelector{
+									LocalObjectReference: apiv1.LocalObjectReference{Name: secretName},
+									Key:                  key,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "gcs", Namespace: namespace},
+		Data:       map[string][]byte{"credentials.json": []byte("{}")},
+	}
+
+	newValidator := func(objects ...client.Object) *Validator {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+
+		validator := NewValidator(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build())
+		decoder, err := admission.NewDecoder(scheme)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(validator.InjectDecoder(decoder)).To(Succeed())
+		return validator
+	}
+
+	newRequest := func(kind string, object runtime.Object) admission.Request {
+		raw, err := json.Marshal(object)
+		Expect(err).ToNot(HaveOccurred())
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: apiv1.GroupVersion.Group, Version: "v1", Kind: kind},
+			Namespace: namespace,
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+
+	It("accepts clusters referencing existing secret keys", func() {
+		validator := newValidator(secret)
+		result, err := validator.validate(context.Background(), newCluster("gcs", "credentials.json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(BeEmpty())
+	})
+
+	It("rejects clusters referencing missing secrets or keys", func() {
+		validator := newValidator(secret)
+
+		result, err := validator.validate(context.Background(), newCluster("missing", "credentials.json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Field).To(Equal("spec.backup.barmanObjectStore.googleCredentials.applicationCredentials.name"))
+
+		result, err = validator.validate(context.Background(), newCluster("gcs", "missing.json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Field).To(Equal("spec.backup.barmanObjectStore.googleCredentials.applicationCredentials.key"))
+	})
+
+	newUpdateRequest := func(kind string, oldObject, object runtime.Object) admission.Request {
+		request := newRequest(kind, object)
+		raw, err := json.Marshal(oldObject)
+		Expect(err).ToNot(HaveOccurred())
+		request.Operation = admissionv1.Update
+		request.OldObject = runtime.RawExtension{Raw: raw}
+		return request
+	}
+
+	It("rejects the creation of an invalid cluster", func() {
+		validator := newValidator()
+		response := validator.Handle(context.Background(),
+			newRequest(apiv1.ClusterKind, newCluster("gcs", "credentials.json")))
+		Expect(response.Allowed).To(BeFalse())
+		Expect(string(response.Result.Reason)).To(ContainSubstring("gcs"))
+
+		response = newValidator(secret).Handle(context.Background(),
+			newRequest(apiv1.ClusterKind, newCluster("gcs", "credentials.json")))
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("checks the updates changing the credentials only", func() {
+		validator := newValidator()
+		cluster := newCluster("gcs", "credentials.json")
+
+		updatedCluster := cluster.DeepCopy()
+		updatedCluster.Annotations = map[string]string{"test": "value"}
+		response := validator.Handle(context.Background(),
+			newUpdateRequest(apiv1.ClusterKind, cluster, updatedCluster))
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Warnings).To(BeEmpty())
+
+		updatedCluster = newCluster("gcs", "other.json")
+		response = validator.Handle(context.Background(),
+			newUpdateRequest(apiv1.ClusterKind, cluster, updatedCluster))
+		Expect(response.Allowed).To(BeFalse())
+	})
+
+	It("doesn't check the clusters being deleted", func() {
+		cluster := newCluster("gcs", "credentials.json")
+		updatedCluster := newCluster("missing", "credentials.json")
+		updatedCluster.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		updatedCluster.Finalizers = []string{"cnpg.io/test"}
+
+		response := newValidator().Handle(context.Background(),
+			newUpdateRequest(apiv1.ClusterKind, cluster, updatedCluster))
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Warnings).To(BeEmpty())
+	})
+
+	It("skips the check when requested", func() {
+		validator := newValidator()
+		cluster := newCluster("gcs", "credentials.json")
+		cluster.Annotations = map[string]string{"cnpg.io/skipCredentialsCheck": "enabled"}
+		Expect(validator.Handle(context.Background(), newRequest(apiv1.ClusterKind, cluster)).Allowed).To(BeTrue())
+	})
+
+	It("checks the credentials of the cluster of a backup", func() {
+		backup := &apiv1.Backup{
+			TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: apiv1.BackupKind},
+			ObjectMeta: metav1.ObjectMeta{Name: "backup-example", Namespace: namespace},
+			Spec: apiv1.BackupSpec{
+				Cluster: apiv1.LocalObjectReference{Name: "cluster-example"},
+			},
+		}
+
+		response := newValidator().Handle(context.Background(), newRequest(apiv1.BackupKind, backup))
+		Expect(response.Allowed).To(BeTrue())
+
+		response = newValidator(newCluster("gcs", "credentials.json")).
+			Handle(context.Background(), newRequest(apiv1.BackupKind, backup))
+		Expect(response.Allowed).To(BeFalse())
+		Expect(string(response.Result.Reason)).To(ContainSubstring("cluster-example"))
+
+		response = newValidator(newCluster("gcs", "credentials.json"), secret).
+			Handle(context.Background(), newRequest(apiv1.BackupKind, backup))
+		Expect(response.Allowed).To(BeTrue())
+
+		// The existing backups are not checked again
+		response = newValidator(newCluster("gcs", "credentials.json")).
+			Handle(context.Background(), newUpdateRequest(apiv1.BackupKind, backup, backup))
+		Expect(response.Allowed).To(BeTrue())
+	})
+})
@@ -0,0 +1,144 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	v1 "k8s.io/api/core/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
+)
+
+// setupCertManagerWebhooksCertificate reads the webhook certificate issued
+// by cert-manager and makes it available to the webhook server
+func (pki PublicKeyInfrastructure) setupCertManagerWebhooksCertificate(
+	ctx context.Context,
+	client kubernetes.Interface,
+	apiClient apiextensionsclientset.Interface,
+) error {
+	secret, err := client.CoreV1().Secrets(pki.OperatorNamespace).Get(ctx, pki.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	return pki.refreshCertManagerWebhooksCertificate(ctx, client, apiClient, secret)
+}
+
+// refreshCertManagerWebhooksCertificate dumps the webhook certificate
+// contained in the passed secret, issued by cert-manager, into the
+// certificates directory, and injects the CA which issued it into the
+// webhooks
+func (pki PublicKeyInfrastructure) refreshCertManagerWebhooksCertificate(
+	ctx context.Context,
+	client kubernetes.Interface,
+	apiClient apiextensionsclientset.Interface,
+	secret *v1.Secret,
+) error {
+	if _, err := ParseServerSecret(secret); err != nil {
+		return fmt.Errorf("while parsing the webhook certificate issued by cert-manager: %w", err)
+	}
+
+	// The CA is injected into the webhooks instead of the webhook certificate,
+	// as it doesn't change when cert-manager renews the latter
+	caBundle := secret.Data[CACertKey]
+	if err := ValidateCABundle(caBundle); err != nil {
+		return fmt.Errorf("while parsing the %s secret data issued by cert-manager: %w", CACertKey, err)
+	}
+
+	if err := pki.dumpWebhookCertificate(secret); err != nil {
+		return err
+	}
+
+	return pki.injectCABundle(ctx, client, apiClient, caBundle)
+}
+
+// dumpWebhookCertificate writes the webhook certificate and its private key
+// into the certificates directory. Every file is atomically replaced, and
+// the webhook server reloads the certificate as soon as they change
+func (pki PublicKeyInfrastructure) dumpWebhookCertificate(secret *v1.Secret) error {
+	if err := fileutils.EnsureDirectoryExist(pki.CertDir); err != nil {
+		return err
+	}
+
+	// The private key is written first: the webhook server refuses to load
+	// a certificate not matching it, and keeps serving the previous one
+	// until the certificate is replaced too
+	for _, name := range []string{TLSPrivateKeyKey, TLSCertKey} {
+		changed, err := fileutils.WriteFileAtomic(path.Join(pki.CertDir, name), secret.Data[name], 0o600)
+		if err != nil {
+			return fmt.Errorf("while writing %s: %w", name, err)
+		}
+		if changed {
+			pkiLog.Info("Updated the webhook certificate issued by cert-manager", "file", name)
+		}
+	}
+
+	return nil
+}
+
+// WatchCertManagerCertificate watches the secret containing the webhook
+// certificate issued by cert-manager, making every renewal available to the
+// webhook server, and blocks until the passed context is done.
+// Since the webhook server of every operator instance needs the certificate,
+// it must run regardless of the leadership
+func (pki PublicKeyInfrastructure) WatchCertManagerCertificate(
+	ctx context.Context,
+	client kubernetes.Interface,
+	apiClient apiextensionsclientset.Interface,
+) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		0,
+		informers.WithNamespace(pki.OperatorNamespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", pki.SecretName).String()
+		}),
+	)
+
+	refresh := func(obj interface{}) {
+		secret, ok := obj.(*v1.Secret)
+		if !ok || secret.Name != pki.SecretName {
+			return
+		}
+
+		if err := pki.refreshCertManagerWebhooksCertificate(ctx, client, apiClient, secret); err != nil {
+			pkiLog.Error(err, "Cannot refresh the webhook certificate issued by cert-manager",
+				"secret", secret.Name)
+		}
+	}
+
+	factory.Core().V1().Secrets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: refresh,
+		UpdateFunc: func(_, newObj interface{}) {
+			refresh(newObj)
+		},
+	})
+
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+
+	return nil
+}
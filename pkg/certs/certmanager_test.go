@@ -0,0 +1,146 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"os"
+	"path"
+
+	v1 "k8s.io/api/core/v1"
+	fakeApiExtension "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook certificate issued by cert-manager", func() {
+	var (
+		pki        PublicKeyInfrastructure
+		ca         *KeyPair
+		tempDir    string
+		ctx        context.Context
+		cancel     context.CancelFunc
+		issueCerts func() *v1.Secret
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "cert_manager_*")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(func() {
+			Expect(os.RemoveAll(tempDir)).To(Succeed())
+		})
+
+		ctx, cancel = context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+
+		pki = pkiEnvironmentTemplate
+		pki.CertDir = tempDir
+		pki.CertManager = true
+
+		ca, err = CreateRootCA("root", operatorNamespaceName)
+		Expect(err).ToNot(HaveOccurred())
+
+		// issueCerts builds a secret like the ones issued by cert-manager
+		issueCerts = func() *v1.Secret {
+			pair, err := ca.CreateAndSignPair("webhook-service.operator-namespace.svc", CertTypeServer, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			secret := pair.GenerateCertificateSecret(pki.OperatorNamespace, pki.SecretName)
+			secret.Data[CACertKey] = ca.Certificate
+			return secret
+		}
+	})
+
+	readCertDir := func(name string) []byte {
+		content, err := os.ReadFile(path.Join(tempDir, name)) // #nosec
+		Expect(err).ToNot(HaveOccurred())
+		return content
+	}
+
+	It("writes the certificate and injects its CA without a CA of the operator", func() {
+		secret := issueCerts()
+		mutatingWebhook := mutatingWebhookTemplate
+		validatingWebhook := validatingWebhookTemplate
+		firstCrd := firstCrdTemplate
+		clientSet := fake.NewSimpleClientset(secret, &mutatingWebhook, &validatingWebhook)
+		apiClientSet := fakeApiExtension.NewSimpleClientset(&firstCrd)
+		pki.CustomResourceDefinitionsName = []string{firstCrd.Name}
+
+		Expect(pki.ensureCertificatesAreUpToDate(ctx, clientSet, apiClientSet)).To(Succeed())
+
+		Expect(readCertDir(TLSCertKey)).To(Equal(secret.Data[TLSCertKey]))
+		Expect(readCertDir(TLSPrivateKeyKey)).To(Equal(secret.Data[TLSPrivateKeyKey]))
+
+		updatedMutatingWebhook, err := clientSet.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(
+			ctx, pki.MutatingWebhookConfigurationName, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updatedMutatingWebhook.Webhooks[0].ClientConfig.CABundle).To(Equal(ca.Certificate))
+
+		updatedValidatingWebhook, err := clientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(
+			ctx, pki.ValidatingWebhookConfigurationName, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updatedValidatingWebhook.Webhooks[0].ClientConfig.CABundle).To(Equal(ca.Certificate))
+
+		updatedCrd, err := apiClientSet.ApiextensionsV1().CustomResourceDefinitions().Get(
+			ctx, firstCrd.Name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updatedCrd.Spec.Conversion.Webhook.ClientConfig.CABundle).To(Equal(ca.Certificate))
+
+		_, err = clientSet.CoreV1().Secrets(pki.OperatorNamespace).Get(ctx, pki.CaSecretName, metav1.GetOptions{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("refuses a secret without the CA", func() {
+		secret := issueCerts()
+		delete(secret.Data, CACertKey)
+		clientSet := fake.NewSimpleClientset(secret)
+
+		err := pki.ensureCertificatesAreUpToDate(ctx, clientSet, fakeApiExtension.NewSimpleClientset())
+		Expect(err).To(MatchError(ContainSubstring(CACertKey)))
+
+		_, err = os.Stat(path.Join(tempDir, TLSCertKey))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("follows the renewals of the certificate", func() {
+		secret := issueCerts()
+		clientSet := fake.NewSimpleClientset(secret)
+		apiClientSet := fakeApiExtension.NewSimpleClientset()
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(pki.WatchCertManagerCertificate(ctx, clientSet, apiClientSet)).To(Succeed())
+		}()
+
+		Eventually(func() ([]byte, error) {
+			return os.ReadFile(path.Join(tempDir, TLSCertKey)) // #nosec
+		}).Should(Equal(secret.Data[TLSCertKey]))
+
+		renewedSecret := issueCerts()
+		_, err := clientSet.CoreV1().Secrets(pki.OperatorNamespace).Update(ctx, renewedSecret, metav1.UpdateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(func() ([]byte, error) {
+			return os.ReadFile(path.Join(tempDir, TLSCertKey)) // #nosec
+		}).Should(Equal(renewedSecret.Data[TLSCertKey]))
+		Expect(readCertDir(TLSPrivateKeyKey)).To(Equal(renewedSecret.Data[TLSPrivateKeyKey]))
+	})
+})
@@ -306,6 +306,12 @@ func (pair *KeyPair) RenewCertificate(caPrivateKey *ecdsa.PrivateKey, parentCert
 
 // IsExpiring check if the certificate will expire in the configured duration
 func (pair *KeyPair) IsExpiring() (bool, *time.Time, error) {
+	return pair.IsExpiringWithin(expiringCheckThreshold)
+}
+
+// IsExpiringWithin check if the certificate will expire in the passed duration,
+// or is not valid yet
+func (pair *KeyPair) IsExpiringWithin(threshold time.Duration) (bool, *time.Time, error) {
 	cert, err := pair.ParseCertificate()
 	if err != nil {
 		return true, nil, err
@@ -314,7 +320,7 @@ func (pair *KeyPair) IsExpiring() (bool, *time.Time, error) {
 	if time.Now().Before(cert.NotBefore) {
 		return true, &cert.NotAfter, nil
 	}
-	if time.Now().Add(expiringCheckThreshold).After(cert.NotAfter) {
+	if time.Now().Add(threshold).After(cert.NotAfter) {
 		return true, &cert.NotAfter, nil
 	}
 
@@ -347,28 +353,115 @@ func CreateRootCA(commonName string, organizationalUnit string) (*KeyPair, error
 	return createCAWithValidity(notBefore, notAfter, nil, nil, commonName, organizationalUnit)
 }
 
-// ParseCASecret parse a CA secret to a key pair
-func ParseCASecret(secret *v1.Secret) (*KeyPair, error) {
-	privateKey, ok := secret.Data[CAPrivateKeyKey]
-	if !ok {
-		return nil, fmt.Errorf("missing %s secret data", CAPrivateKeyKey)
-	}
+// CASecretLayout describes the keys of a secret containing a CA
+// certificate and its private key
+type CASecretLayout struct {
+	// The key containing the certificate
+	CertificateKey string
 
-	publicKey, ok := secret.Data[CACertKey]
-	if !ok {
-		return nil, fmt.Errorf("missing %s secret data", CACertKey)
+	// The key containing the private key
+	PrivateKeyKey string
+}
+
+var (
+	// DefaultCASecretLayout is the layout of the CA secrets generated by the operator
+	DefaultCASecretLayout = CASecretLayout{CertificateKey: CACertKey, PrivateKeyKey: CAPrivateKeyKey}
+
+	// TLSCASecretLayout is the layout of the CA secrets issued by cert-manager,
+	// which stores the CA certificate in "tls.crt" and the certificate of its
+	// issuer in "ca.crt"
+	TLSCASecretLayout = CASecretLayout{CertificateKey: TLSCertKey, PrivateKeyKey: TLSPrivateKeyKey}
+)
+
+// ParseCASecret parse a CA secret to a key pair. The secret is expected to
+// follow the DefaultCASecretLayout, or one of the passed fallback layouts.
+// Exactly one layout must match the secret, to avoid choosing the wrong key pair
+func ParseCASecret(secret *v1.Secret, fallbackLayouts ...CASecretLayout) (*KeyPair, error) {
+	layout, err := FindCASecretLayout(secret, fallbackLayouts...)
+	if err != nil {
+		return nil, err
 	}
 
+	privateKey := secret.Data[layout.PrivateKeyKey]
+	publicKey := secret.Data[layout.CertificateKey]
+
 	// Verify the key matches the certificate
-	_, err := tls.X509KeyPair(publicKey, privateKey)
+	_, err = tls.X509KeyPair(publicKey, privateKey)
 	if err != nil {
 		return nil, err
 	}
 
-	return &KeyPair{
+	pair := &KeyPair{
 		Private:     privateKey,
 		Certificate: publicKey,
-	}, nil
+	}
+
+	// The fallback layouts are shared with the secrets containing the leaf
+	// certificates, so we need to verify we have been given a CA
+	if layout != DefaultCASecretLayout {
+		certificate, err := pair.ParseCertificate()
+		if err != nil {
+			return nil, err
+		}
+		if !certificate.IsCA {
+			return nil, fmt.Errorf("the certificate in %s secret data is not a CA", layout.CertificateKey)
+		}
+
+		// The operator can only sign certificates with an ECDSA key, while
+		// cert-manager issues RSA keys unless told otherwise
+		if _, err := pair.ParseECPrivateKey(); err != nil {
+			return nil, fmt.Errorf(
+				"unsupported %s private key in %s secret data, expecting an ECDSA key in SEC 1 format: %w",
+				certificate.PublicKeyAlgorithm, layout.PrivateKeyKey, err)
+		}
+	}
+
+	return pair, nil
+}
+
+// FindCASecretLayout returns the layout of the passed CA secret, choosing
+// between the DefaultCASecretLayout and the passed fallback ones. An error
+// is raised unless exactly one of them is matching the secret
+func FindCASecretLayout(secret *v1.Secret, fallbackLayouts ...CASecretLayout) (CASecretLayout, error) {
+	layouts := append([]CASecretLayout{DefaultCASecretLayout}, fallbackLayouts...)
+
+	var matchingLayouts []CASecretLayout
+	for _, layout := range layouts {
+		_, hasCertificate := secret.Data[layout.CertificateKey]
+		_, hasPrivateKey := secret.Data[layout.PrivateKeyKey]
+		if hasCertificate && hasPrivateKey {
+			matchingLayouts = append(matchingLayouts, layout)
+		}
+	}
+
+	switch len(matchingLayouts) {
+	case 1:
+		return matchingLayouts[0], nil
+
+	case 0:
+		// Report the missing data of the default layout, as happens
+		// when no fallback layout is allowed
+		if len(fallbackLayouts) == 0 {
+			if _, ok := secret.Data[CAPrivateKeyKey]; !ok {
+				return CASecretLayout{}, fmt.Errorf("missing %s secret data", CAPrivateKeyKey)
+			}
+			return CASecretLayout{}, fmt.Errorf("missing %s secret data", CACertKey)
+		}
+		return CASecretLayout{}, fmt.Errorf("missing CA secret data, expecting one of %s", describeLayouts(layouts))
+
+	default:
+		return CASecretLayout{}, fmt.Errorf("ambiguous CA secret data, containing more than one of %s",
+			describeLayouts(matchingLayouts))
+	}
+}
+
+// describeLayouts describes a list of CA secret layouts for the error messages
+func describeLayouts(layouts []CASecretLayout) string {
+	descriptions := make([]string, len(layouts))
+	for idx, layout := range layouts {
+		descriptions[idx] = fmt.Sprintf("%s/%s", layout.CertificateKey, layout.PrivateKeyKey)
+	}
+	return strings.Join(descriptions, ", ")
 }
 
 // ParseServerSecret parse a secret for a server to a key pair
@@ -395,6 +488,36 @@ func ParseServerSecret(secret *v1.Secret) (*KeyPair, error) {
 	}, nil
 }
 
+// ValidateCABundle checks that the passed data is a PEM encoded bundle
+// containing only valid certificates, and at least one of them.
+// As in the system CA bundles, any text outside the PEM blocks is ignored
+func ValidateCABundle(data []byte) error {
+	count := 0
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		count++
+		if block.Type != certificatePEMBlockType {
+			return fmt.Errorf("unexpected PEM block of type %q at position %d", block.Type, count)
+		}
+
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("invalid certificate at position %d: %w", count, err)
+		}
+	}
+
+	if count == 0 {
+		return fmt.Errorf("the CA bundle doesn't contain any certificate")
+	}
+
+	return nil
+}
+
 // createCAWithValidity create a CA with a certain validity, with a parent certificate and signed by a certain
 // private key. If the latest two parameters are nil, the CA will be a root one (self-signed)
 func createCAWithValidity(
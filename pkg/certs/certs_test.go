@@ -18,10 +18,16 @@ package certs
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
+	"math/big"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -106,6 +112,20 @@ var _ = Describe("Keypair generation", func() {
 		Expect(isExpiring, err).To(BeFalse())
 	})
 
+	It("marks a certificate expiring within the passed threshold as expiring", func() {
+		notBefore := time.Now().Add(-time.Hour)
+		notAfter := time.Now().Add(20 * 24 * time.Hour)
+		ca, err := createCAWithValidity(notBefore, notAfter, nil, nil, "root", "namespace")
+		Expect(err).To(BeNil())
+
+		isExpiring, expiration, err := ca.IsExpiringWithin(30 * 24 * time.Hour)
+		Expect(isExpiring, err).To(BeTrue())
+		Expect(expiration.Unix()).To(Equal(notAfter.Unix()))
+
+		isExpiring, _, err = ca.IsExpiringWithin(10 * 24 * time.Hour)
+		Expect(isExpiring, err).To(BeFalse())
+	})
+
 	When("we have a CA generated", func() {
 		It("should successfully generate a leaf certificate", func() {
 			rootCA, err := CreateRootCA("test", "namespace")
@@ -278,3 +298,120 @@ var _ = Describe("Keypair generation", func() {
 		})
 	})
 })
+
+var _ = Describe("CA bundle validation", func() {
+	It("accepts a bundle of certificates", func() {
+		rootCA, err := CreateRootCA("test", "namespace")
+		Expect(err).ToNot(HaveOccurred())
+		otherCA, err := CreateRootCA("other", "namespace")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ValidateCABundle(rootCA.Certificate)).To(Succeed())
+
+		bundle := append([]byte("# Comment\n"), rootCA.Certificate...)
+		bundle = append(bundle, otherCA.Certificate...)
+		Expect(ValidateCABundle(bundle)).To(Succeed())
+	})
+
+	It("rejects data that doesn't contain certificates", func() {
+		Expect(ValidateCABundle(nil)).ToNot(Succeed())
+		Expect(ValidateCABundle([]byte("not a certificate"))).ToNot(Succeed())
+	})
+
+	It("rejects private keys and corrupted certificates", func() {
+		rootCA, err := CreateRootCA("test", "namespace")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ValidateCABundle(rootCA.Private)).ToNot(Succeed())
+
+		corrupted := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("corrupted")})
+		Expect(ValidateCABundle(corrupted)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("CA secret layouts", func() {
+	var ca *KeyPair
+
+	// certManagerSecret builds a secret like the ones issued by cert-manager
+	certManagerSecret := func(pair *KeyPair) *v1.Secret {
+		return &v1.Secret{Data: map[string][]byte{
+			TLSCertKey:       pair.Certificate,
+			TLSPrivateKeyKey: pair.Private,
+			CACertKey:        pair.Certificate,
+		}}
+	}
+
+	BeforeEach(func() {
+		var err error
+		ca, err = CreateRootCA("root", "namespace")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("parses the secrets generated by the operator", func() {
+		secret := ca.GenerateCASecret("namespace", "ca")
+		pair, err := ParseCASecret(secret, TLSCASecretLayout)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pair.Private).To(Equal(ca.Private))
+
+		layout, err := FindCASecretLayout(secret, TLSCASecretLayout)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layout).To(Equal(DefaultCASecretLayout))
+	})
+
+	It("parses the secrets issued by cert-manager only when allowed", func() {
+		secret := certManagerSecret(ca)
+		_, err := ParseCASecret(secret)
+		Expect(err).To(MatchError("missing ca.key secret data"))
+
+		pair, err := ParseCASecret(secret, TLSCASecretLayout)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pair.Certificate).To(Equal(ca.Certificate))
+		Expect(pair.Private).To(Equal(ca.Private))
+	})
+
+	It("refuses the secrets matching more than one layout", func() {
+		secret := certManagerSecret(ca)
+		secret.Data[CAPrivateKeyKey] = ca.Private
+		_, err := ParseCASecret(secret, TLSCASecretLayout)
+		Expect(err).To(MatchError(ContainSubstring("ambiguous CA secret data")))
+	})
+
+	It("refuses the secrets without a key pair", func() {
+		secret := &v1.Secret{Data: map[string][]byte{CACertKey: ca.Certificate}}
+		_, err := ParseCASecret(secret, TLSCASecretLayout)
+		Expect(err).To(MatchError(
+			"missing CA secret data, expecting one of ca.crt/ca.key, tls.crt/tls.key"))
+	})
+
+	It("refuses the leaf certificates", func() {
+		leaf, err := ca.CreateAndSignPair("host", CertTypeServer, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ParseCASecret(certManagerSecret(leaf), TLSCASecretLayout)
+		Expect(err).To(MatchError(ContainSubstring("is not a CA")))
+	})
+
+	It("refuses the CAs having an RSA key, naming the algorithm", func() {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "rsa-root"},
+			NotBefore:             time.Now().Add(-time.Minute),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		certificate, err := x509.CreateCertificate(rand.Reader, template, template, &rsaKey.PublicKey, rsaKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		rsaCA := &KeyPair{
+			Private: pem.EncodeToMemory(&pem.Block{
+				Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+			}),
+			Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}),
+		}
+		_, err = ParseCASecret(certManagerSecret(rsaCA), TLSCASecretLayout)
+		Expect(err).To(MatchError(ContainSubstring("unsupported RSA private key in tls.key secret data")))
+	})
+})
@@ -86,12 +86,27 @@ type PublicKeyInfrastructure struct {
 	// The labelSelector to be used to get the operators deployment,
 	// e.g. "app.kubernetes.io/name=cloudnative-pg"
 	OperatorDeploymentLabelSelector string
+
+	// CertManager is true when the webhook certificate is issued by
+	// cert-manager. In this case the operator doesn't need its own CA,
+	// and never generates nor renews the webhook certificate
+	CertManager bool
 }
 
+// operatorCAFallbackLayouts are the layouts, other than the default one,
+// accepted for the secret containing the CA of the operator, allowing
+// it to be issued by cert-manager
+var operatorCAFallbackLayouts = []CASecretLayout{TLSCASecretLayout}
+
 // RenewLeafCertificate renew a secret containing a server
-// certificate given the secret containing the CA that will sign it.
+// certificate given the secret containing the CA that will sign it,
+// which may follow one of the passed fallback layouts.
 // Returns true if the certificate has been renewed
-func RenewLeafCertificate(caSecret *v1.Secret, secret *v1.Secret) (bool, error) {
+func RenewLeafCertificate(
+	caSecret *v1.Secret,
+	secret *v1.Secret,
+	caSecretFallbackLayouts ...CASecretLayout,
+) (bool, error) {
 	// Verify the temporal validity of this CA
 	pair, err := ParseServerSecret(secret)
 	if err != nil {
@@ -107,7 +122,7 @@ func RenewLeafCertificate(caSecret *v1.Secret, secret *v1.Secret) (bool, error)
 	}
 
 	// Parse the CA secret to get the private key
-	caPair, err := ParseCASecret(caSecret)
+	caPair, err := ParseCASecret(caSecret, caSecretFallbackLayouts...)
 	if err != nil {
 		return false, err
 	}
@@ -132,27 +147,18 @@ func RenewLeafCertificate(caSecret *v1.Secret, secret *v1.Secret) (bool, error)
 	return true, nil
 }
 
-// Setup ensures that we have the required PKI infrastructure to make the operator and the clusters working
+// Setup ensures that we have the required PKI infrastructure to make the operator and the clusters working.
+// The periodic maintenance of the certificates is not scheduled here, see SchedulePeriodicMaintenance
 func (pki *PublicKeyInfrastructure) Setup(
 	ctx context.Context,
 	clientSet *kubernetes.Clientset,
 	apiClientSet *apiextensionsclientset.Clientset,
 ) error {
-	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
 		return apierrors.IsNotFound(err) || apierrors.IsAlreadyExists(err) || isSecretsMountNotRefreshedError(err)
 	}, func() error {
 		return pki.ensureCertificatesAreUpToDate(ctx, clientSet, apiClientSet)
 	})
-	if err != nil {
-		return err
-	}
-
-	err = pki.schedulePeriodicMaintenance(ctx, clientSet, apiClientSet)
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
 
 // ensureRootCACertificate ensure that in the cluster there is a root CA Certificate
@@ -197,11 +203,19 @@ func (pki *PublicKeyInfrastructure) ensureRootCACertificate(
 // secret if the secret has been renewed
 func renewCACertificate(ctx context.Context, client kubernetes.Interface, secret *v1.Secret) (*v1.Secret, error) {
 	// Verify the temporal validity of this CA
-	pair, err := ParseCASecret(secret)
+	pair, err := ParseCASecret(secret, operatorCAFallbackLayouts...)
 	if err != nil {
 		return nil, err
 	}
 
+	// A CA which has not been generated by the operator is renewed
+	// by its issuer, i.e. cert-manager
+	if layout, _ := FindCASecretLayout(secret, operatorCAFallbackLayouts...); layout != DefaultCASecretLayout {
+		pkiLog.Debug("The operator CA is not managed by the operator, skipping its renewal",
+			"secret", secret.Name)
+		return secret, nil
+	}
+
 	expiring, _, err := pair.IsExpiring()
 	if err != nil {
 		return nil, err
@@ -236,6 +250,10 @@ func (pki PublicKeyInfrastructure) ensureCertificatesAreUpToDate(
 	client kubernetes.Interface,
 	apiClient apiextensionsclientset.Interface,
 ) error {
+	if pki.CertManager {
+		return pki.setupCertManagerWebhooksCertificate(ctx, client, apiClient)
+	}
+
 	caSecret, err := pki.ensureRootCACertificate(
 		ctx,
 		client,
@@ -274,32 +292,46 @@ func (pki PublicKeyInfrastructure) setupWebhooksCertificate(
 		return nil, err
 	}
 
-	if err := pki.injectPublicKeyIntoMutatingWebhook(
-		ctx,
-		client,
-		webhookSecret); err != nil {
+	// The webhook certificate is signed by the operator CA, but the leaf
+	// itself is injected as the trust anchor, so the API server trusts it
+	// directly. It's injected at every maintenance, following its renewals
+	if err := pki.injectCABundle(ctx, client, apiClient, webhookSecret.Data[TLSCertKey]); err != nil {
 		return nil, err
 	}
 
-	if err := pki.injectPublicKeyIntoValidatingWebhook(
-		ctx,
-		client,
-		webhookSecret); err != nil {
-		return nil, err
+	return webhookSecret, nil
+}
+
+// injectCABundle injects the passed CA bundle into the webhook configurations
+// and into the conversion webhooks of the CRDs
+func (pki PublicKeyInfrastructure) injectCABundle(
+	ctx context.Context,
+	client kubernetes.Interface,
+	apiClient apiextensionsclientset.Interface,
+	caBundle []byte,
+) error {
+	if err := pki.injectPublicKeyIntoMutatingWebhook(ctx, client, caBundle); err != nil {
+		return err
+	}
+
+	if err := pki.injectPublicKeyIntoValidatingWebhook(ctx, client, caBundle); err != nil {
+		return err
 	}
 
 	for _, name := range pki.CustomResourceDefinitionsName {
-		if err := pki.injectPublicKeyIntoCRD(ctx, apiClient, name, webhookSecret); err != nil {
-			return nil, err
+		if err := pki.injectPublicKeyIntoCRD(ctx, apiClient, name, caBundle); err != nil {
+			return err
 		}
 	}
 
-	return webhookSecret, nil
+	return nil
 }
 
-// schedulePeriodicMaintenance schedule a background periodic certificate maintenance,
-// to automatically renew TLS certificates
-func (pki PublicKeyInfrastructure) schedulePeriodicMaintenance(
+// SchedulePeriodicMaintenance schedule a background periodic certificate maintenance,
+// to automatically renew TLS certificates, and blocks until the passed context is done.
+// Since the maintenance updates the secrets and the webhook configurations, it must
+// be run only by the operator instance holding the leadership
+func (pki PublicKeyInfrastructure) SchedulePeriodicMaintenance(
 	ctx context.Context,
 	client kubernetes.Interface,
 	apiClient apiextensionsclientset.Interface,
@@ -313,13 +345,14 @@ func (pki PublicKeyInfrastructure) schedulePeriodicMaintenance(
 	}
 
 	c := cron.New()
-	err := c.AddFunc("@every 1h", maintenance)
-	c.Start()
-
-	if err != nil {
+	if err := c.AddFunc("@every 1h", maintenance); err != nil {
 		return fmt.Errorf("error while scheduling CA maintenance: %w", err)
 	}
 
+	c.Start()
+	<-ctx.Done()
+	c.Stop()
+
 	return nil
 }
 
@@ -339,7 +372,7 @@ func (pki PublicKeyInfrastructure) ensureCertificate(
 	}
 
 	// Let's generate the pki certificate
-	caPair, err := ParseCASecret(caSecret)
+	caPair, err := ParseCASecret(caSecret, operatorCAFallbackLayouts...)
 	if err != nil {
 		return nil, err
 	}
@@ -372,7 +405,7 @@ func (pki PublicKeyInfrastructure) ensureCertificate(
 func renewServerCertificate(
 	ctx context.Context, client kubernetes.Interface, caSecret v1.Secret, secret *v1.Secret,
 ) (*v1.Secret, error) {
-	hasBeenRenewed, err := RenewLeafCertificate(&caSecret, secret)
+	hasBeenRenewed, err := RenewLeafCertificate(&caSecret, secret, operatorCAFallbackLayouts...)
 	if err != nil {
 		return nil, err
 	}
@@ -404,10 +437,10 @@ func ensureMountedSecretsAreInSync(secret *v1.Secret, certDir string) error {
 	return nil
 }
 
-// injectPublicKeyIntoMutatingWebhook inject the TLS public key into the admitted
+// injectPublicKeyIntoMutatingWebhook inject the passed CA bundle into the admitted
 // ones for a certain mutating webhook configuration
 func (pki PublicKeyInfrastructure) injectPublicKeyIntoMutatingWebhook(
-	ctx context.Context, client kubernetes.Interface, tlsSecret *v1.Secret,
+	ctx context.Context, client kubernetes.Interface, caBundle []byte,
 ) error {
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		config, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(
@@ -423,7 +456,7 @@ func (pki PublicKeyInfrastructure) injectPublicKeyIntoMutatingWebhook(
 		oldConfig := config.DeepCopy()
 
 		for idx := range config.Webhooks {
-			config.Webhooks[idx].ClientConfig.CABundle = tlsSecret.Data["tls.crt"]
+			config.Webhooks[idx].ClientConfig.CABundle = caBundle
 		}
 
 		if reflect.DeepEqual(oldConfig.Webhooks, config.Webhooks) {
@@ -437,10 +470,10 @@ func (pki PublicKeyInfrastructure) injectPublicKeyIntoMutatingWebhook(
 	})
 }
 
-// injectPublicKeyIntoValidatingWebhook inject the TLS public key into the admitted
+// injectPublicKeyIntoValidatingWebhook inject the passed CA bundle into the admitted
 // ones for a certain validating webhook configuration
 func (pki PublicKeyInfrastructure) injectPublicKeyIntoValidatingWebhook(
-	ctx context.Context, client kubernetes.Interface, tlsSecret *v1.Secret,
+	ctx context.Context, client kubernetes.Interface, caBundle []byte,
 ) error {
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		config, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(
@@ -456,7 +489,7 @@ func (pki PublicKeyInfrastructure) injectPublicKeyIntoValidatingWebhook(
 		oldConfig := config.DeepCopy()
 
 		for idx := range config.Webhooks {
-			config.Webhooks[idx].ClientConfig.CABundle = tlsSecret.Data["tls.crt"]
+			config.Webhooks[idx].ClientConfig.CABundle = caBundle
 		}
 
 		if reflect.DeepEqual(oldConfig.Webhooks, config.Webhooks) {
@@ -470,13 +503,13 @@ func (pki PublicKeyInfrastructure) injectPublicKeyIntoValidatingWebhook(
 	})
 }
 
-// injectPublicKeyIntoCRD inject the TLS public key into the admitted
+// injectPublicKeyIntoCRD inject the passed CA bundle into the admitted
 // ones from a certain conversion webhook inside a CRD
 func (pki PublicKeyInfrastructure) injectPublicKeyIntoCRD(
 	ctx context.Context,
 	apiClient apiextensionsclientset.Interface,
 	name string,
-	tlsSecret *v1.Secret,
+	caBundle []byte,
 ) error {
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		crd, err := apiClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
@@ -487,11 +520,11 @@ func (pki PublicKeyInfrastructure) injectPublicKeyIntoCRD(
 		if crd.Spec.Conversion == nil ||
 			crd.Spec.Conversion.Webhook == nil ||
 			crd.Spec.Conversion.Webhook.ClientConfig == nil ||
-			reflect.DeepEqual(crd.Spec.Conversion.Webhook.ClientConfig.CABundle, tlsSecret.Data["tls.crt"]) {
+			reflect.DeepEqual(crd.Spec.Conversion.Webhook.ClientConfig.CABundle, caBundle) {
 			return nil
 		}
 
-		crd.Spec.Conversion.Webhook.ClientConfig.CABundle = tlsSecret.Data["tls.crt"]
+		crd.Spec.Conversion.Webhook.ClientConfig.CABundle = caBundle
 
 		_, err = apiClient.ApiextensionsV1().CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{})
 		return err
@@ -178,6 +178,26 @@ var _ = Describe("Root CA secret generation", func() {
 		Expect(cert.NotBefore).To(BeTemporally("<", time.Now()))
 		Expect(cert.NotAfter).To(BeTemporally(">", time.Now()))
 	})
+
+	It("must leave the renewal of a CA issued by cert-manager to its issuer", func() {
+		notAfter := time.Now().Add(-10 * time.Hour)
+		notBefore := notAfter.Add(-90 * 24 * time.Hour)
+		ca, err := createCAWithValidity(notBefore, notAfter,
+			nil, nil, "root", operatorNamespaceName)
+		Expect(err).To(BeNil())
+
+		secret := ca.GenerateCASecret(operatorNamespaceName, "ca-secret-name")
+		secret.Data = map[string][]byte{
+			TLSCertKey:       ca.Certificate,
+			TLSPrivateKeyKey: ca.Private,
+			CACertKey:        ca.Certificate,
+		}
+		clientSet := fake.NewSimpleClientset(secret)
+
+		resultingSecret, err := pki.ensureRootCACertificate(context.TODO(), clientSet)
+		Expect(err).To(BeNil())
+		Expect(resultingSecret.Data).To(Equal(secret.Data))
+	})
 })
 
 var _ = Describe("Webhook certificate validation", func() {
@@ -277,7 +297,7 @@ var _ = Describe("TLS certificates injection", func() {
 		mutatingWebhook := mutatingWebhookTemplate
 		clientSet := fake.NewSimpleClientset(caSecret, webhookSecret, &mutatingWebhook)
 
-		err := pki.injectPublicKeyIntoMutatingWebhook(context.TODO(), clientSet, webhookSecret)
+		err := pki.injectPublicKeyIntoMutatingWebhook(context.TODO(), clientSet, webhookSecret.Data["tls.crt"])
 		Expect(err).To(BeNil())
 
 		updatedWebhook, err := clientSet.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(
@@ -292,7 +312,7 @@ var _ = Describe("TLS certificates injection", func() {
 		validatingWebhook := validatingWebhookTemplate
 		clientSet := fake.NewSimpleClientset(caSecret, webhookSecret, &validatingWebhook)
 
-		err := pki.injectPublicKeyIntoValidatingWebhook(context.TODO(), clientSet, webhookSecret)
+		err := pki.injectPublicKeyIntoValidatingWebhook(context.TODO(), clientSet, webhookSecret.Data["tls.crt"])
 		Expect(err).To(BeNil())
 
 		updatedWebhook, err := clientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(
@@ -358,3 +378,21 @@ var _ = Describe("Webhook environment creation", func() {
 		Expect(updatedSecondCrd.Spec.Conversion.Webhook.ClientConfig.CABundle).To(Equal(webhookSecret.Data["tls.crt"]))
 	})
 })
+
+var _ = Describe("Periodic certificates maintenance", func() {
+	It("stops when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		pki := pkiEnvironmentTemplate
+		clientSet := fake.NewSimpleClientset()
+		apiClientSet := fakeApiExtension.NewSimpleClientset()
+
+		done := make(chan error)
+		go func() {
+			done <- pki.SchedulePeriodicMaintenance(ctx, clientSet, apiClientSet)
+		}()
+
+		Consistently(done).ShouldNot(Receive())
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
@@ -0,0 +1,40 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto/tls"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook TLS options", func() {
+	It("disables the session tickets and the renegotiation by default", func() {
+		config := &tls.Config{Renegotiation: tls.RenegotiateFreelyAsClient} //nolint:gosec
+		WebhookTLSOptions{}.ApplyTo(config)
+		Expect(config.SessionTicketsDisabled).To(BeTrue())
+		Expect(config.Renegotiation).To(Equal(tls.RenegotiateNever))
+	})
+
+	It("allows enabling the session tickets", func() {
+		config := &tls.Config{} //nolint:gosec
+		WebhookTLSOptions{EnableSessionTickets: true}.ApplyTo(config)
+		Expect(config.SessionTicketsDisabled).To(BeFalse())
+		Expect(config.Renegotiation).To(Equal(tls.RenegotiateNever))
+	})
+})
@@ -38,6 +38,10 @@ type FakeData struct {
 
 	// EnablePodDebugging enable debugging mode in new generated pods
 	EnablePodDebugging bool `json:"enablePodDebugging" env:"POD_DEBUG"`
+
+	// MaxConcurrentRollouts is the maximum number of clusters rolling out
+	// their instances at the same time
+	MaxConcurrentRollouts int `json:"maxConcurrentRollouts" env:"MAX_CONCURRENT_ROLLOUTS"`
 }
 
 var defaultInheritedAnnotations = []string{
@@ -50,7 +54,10 @@ const oneNamespace = "one-namespace"
 
 // readConfigMap reads the configuration from the environment and the passed in data map
 func (config *FakeData) readConfigMap(data map[string]string, env EnvironmentSource) {
-	ReadConfigMap(config, &FakeData{InheritedAnnotations: defaultInheritedAnnotations}, data, env)
+	ReadConfigMap(config, &FakeData{
+		InheritedAnnotations:  defaultInheritedAnnotations,
+		MaxConcurrentRollouts: 3,
+	}, data, env)
 }
 
 var _ = Describe("Data test suite", func() {
@@ -90,6 +97,20 @@ var _ = Describe("Data test suite", func() {
 		Expect(config.InheritedAnnotations).To(Equal(defaultInheritedAnnotations))
 		Expect(config.InheritedLabels).To(BeNil())
 	})
+
+	It("loads integer values, skipping the invalid ones", func() {
+		config := &FakeData{}
+		config.readConfigMap(map[string]string{"MAX_CONCURRENT_ROLLOUTS": "5"}, NewFakeEnvironment(nil))
+		Expect(config.MaxConcurrentRollouts).To(Equal(5))
+
+		config = &FakeData{}
+		config.readConfigMap(nil, NewFakeEnvironment(nil))
+		Expect(config.MaxConcurrentRollouts).To(Equal(3))
+
+		config = &FakeData{}
+		config.readConfigMap(map[string]string{"MAX_CONCURRENT_ROLLOUTS": "many"}, NewFakeEnvironment(nil))
+		Expect(config.MaxConcurrentRollouts).To(BeZero())
+	})
 })
 
 // FakeEnvironment is an EnvironmentSource that fetches data from an internal map
@@ -56,6 +56,26 @@ type WALArchiver struct {
 	env []string
 
 	pgDataDirectory string
+
+	// The additional object stores where the WAL files are archived
+	destinations []Destination
+}
+
+// Destination is an additional object store where the WAL files
+// are archived after being archived into the main one
+type Destination struct {
+	// The name of the destination
+	Name string
+
+	// The options that should be used to invoke barman-cloud-wal-archive
+	Options []string
+
+	// The environment that should be used to invoke barman-cloud-wal-archive
+	Env []string
+
+	// If true, a failure archiving into this destination is reported
+	// but it is not considered a failure of the archival of the WAL file
+	BestEffort bool
 }
 
 // WALArchiverResult contains the result of the archival of one WAL
@@ -66,6 +86,10 @@ type WALArchiverResult struct {
 	// If not nil, this is the error that has been detected
 	Err error
 
+	// The result of the archival into each additional destination,
+	// indexed by destination name. A nil error means success
+	DestinationErrors map[string]error
+
 	// The time when we started barman-cloud-wal-archive
 	StartTime time.Time
 
@@ -98,6 +122,11 @@ func New(
 	return archiver, nil
 }
 
+// AddDestination adds an additional object store where the WAL files are archived
+func (archiver *WALArchiver) AddDestination(destination Destination) {
+	archiver.destinations = append(archiver.destinations, destination)
+}
+
 // DeleteFromSpool checks if a WAL file is in the spool and, if it is, remove it
 func (archiver *WALArchiver) DeleteFromSpool(walName string) (hasBeenDeleted bool, err error) {
 	var isContained bool
@@ -129,6 +158,9 @@ func (archiver *WALArchiver) ArchiveList(
 			walStatus.WalName = walNames[walIndex]
 			walStatus.StartTime = time.Now()
 			walStatus.Err = archiver.Archive(walNames[walIndex], options)
+			if walStatus.Err == nil {
+				walStatus.DestinationErrors, walStatus.Err = archiver.archiveToDestinations(ctx, walNames[walIndex])
+			}
 			walStatus.EndTime = time.Now()
 			if walStatus.Err == nil && walIndex != 0 {
 				walStatus.Err = archiver.spool.Touch(walNames[walIndex])
@@ -160,9 +192,65 @@ func (archiver *WALArchiver) ArchiveList(
 	return result
 }
 
+// archiveToDestinations archives a WAL file into every additional destination.
+// The returned error is the one of the first failing destination that is
+// not best-effort
+func (archiver *WALArchiver) archiveToDestinations(
+	ctx context.Context,
+	walName string,
+) (map[string]error, error) {
+	if len(archiver.destinations) == 0 {
+		return nil, nil
+	}
+
+	contextLog := log.FromContext(ctx)
+	destinationErrors := make(map[string]error, len(archiver.destinations))
+
+	var requiredErr error
+	for _, destination := range archiver.destinations {
+		err := archiver.runBarmanCloudWalArchive(walName, destination.Options, destination.Env)
+		destinationErrors[destination.Name] = err
+		if err == nil {
+			continue
+		}
+
+		if destination.BestEffort {
+			contextLog.Warning(
+				"Failed archiving WAL into a best-effort destination",
+				"walName", walName,
+				"destination", destination.Name,
+				"error", err)
+			continue
+		}
+
+		if requiredErr == nil {
+			requiredErr = fmt.Errorf("while archiving into destination %s: %w", destination.Name, err)
+		}
+	}
+
+	return destinationErrors, requiredErr
+}
+
 // Archive archives a certain WAL file using barman-cloud-wal-archive.
 // See archiveWALFileList for the meaning of the parameters
 func (archiver *WALArchiver) Archive(walName string, baseOptions []string) error {
+	if err := archiver.runBarmanCloudWalArchive(walName, baseOptions, archiver.env); err != nil {
+		return err
+	}
+
+	// Removes the `.check-empty-wal-archive` file inside PGDATA after the
+	// first successful archival of a WAL file.
+	filePath := path.Join(archiver.pgDataDirectory, CheckEmptyWalArchiveFile)
+	if err := fileutils.RemoveFile(filePath); err != nil {
+		return fmt.Errorf("error while deleting the check WAL file flag: %w", err)
+	}
+
+	return nil
+}
+
+// runBarmanCloudWalArchive invokes barman-cloud-wal-archive for a
+// certain WAL file, using the passed options and environment
+func (archiver *WALArchiver) runBarmanCloudWalArchive(walName string, baseOptions []string, env []string) error {
 	optionsLength := len(baseOptions)
 	if optionsLength >= math.MaxInt-1 {
 		return fmt.Errorf("can't archive wal file %v, options too long", walName)
@@ -179,7 +267,7 @@ func (archiver *WALArchiver) Archive(walName string, baseOptions []string) error
 	)
 
 	barmanCloudWalArchiveCmd := exec.Command(barmanCapabilities.BarmanCloudWalArchive, options...) // #nosec G204
-	barmanCloudWalArchiveCmd.Env = archiver.env
+	barmanCloudWalArchiveCmd.Env = env
 
 	err := execlog.RunStreaming(barmanCloudWalArchiveCmd, barmanCapabilities.BarmanCloudWalArchive)
 	if err != nil {
@@ -193,13 +281,6 @@ func (archiver *WALArchiver) Archive(walName string, baseOptions []string) error
 		return fmt.Errorf("unexpected failure invoking %s: %w", barmanCapabilities.BarmanCloudWalArchive, err)
 	}
 
-	// Removes the `.check-empty-wal-archive` file inside PGDATA after the
-	// first successful archival of a WAL file.
-	filePath := path.Join(archiver.pgDataDirectory, CheckEmptyWalArchiveFile)
-	if err := fileutils.RemoveFile(filePath); err != nil {
-		return fmt.Errorf("error while deleting the check WAL file flag: %w", err)
-	}
-
 	return nil
 }
 
@@ -0,0 +1,126 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archiver
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+)
+
+// walArchiveDestinationsFile is the file where the wal-archive command keeps
+// the result of the archiving into the additional WAL archive destinations.
+// The instance manager reports its content in the instance status, and the
+// operator stores it in the cluster status
+const walArchiveDestinationsFile = postgres.ScratchDataDirectory + "/wal-archive-destinations.json"
+
+// GetWALArchiveDestinationsStatus gets the result of the archiving
+// into the additional WAL archive destinations
+func GetWALArchiveDestinationsStatus() (map[string]postgres.WALArchiveDestinationStatus, error) {
+	return readWALArchiveDestinationsStatus(walArchiveDestinationsFile)
+}
+
+// RecordWALArchiveDestinationsResult updates the result of the archiving
+// into the additional WAL archive destinations after an attempt
+func RecordWALArchiveDestinationsResult(
+	destinations []apiv1.WALArchiveDestination,
+	walStatus []WALArchiverResult,
+) error {
+	return recordWALArchiveDestinationsResult(walArchiveDestinationsFile, destinations, walStatus)
+}
+
+func readWALArchiveDestinationsStatus(fileName string) (map[string]postgres.WALArchiveDestinationStatus, error) {
+	content, err := fileutils.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) == 0 {
+		// The status has never been written
+		return nil, nil
+	}
+
+	var result map[string]postgres.WALArchiveDestinationStatus
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("while parsing %s: %w", fileName, err)
+	}
+
+	return result, nil
+}
+
+func recordWALArchiveDestinationsResult(
+	fileName string,
+	destinations []apiv1.WALArchiveDestination,
+	walStatus []WALArchiverResult,
+) error {
+	// The status is rewritten from scratch when it can't be parsed
+	currentStatus, _ := readWALArchiveDestinationsStatus(fileName)
+
+	content, err := json.Marshal(setWALArchiveDestinationsStatus(destinations, currentStatus, walStatus))
+	if err != nil {
+		return err
+	}
+
+	_, err = fileutils.WriteFileAtomic(fileName, content, 0o600)
+	return err
+}
+
+// setWALArchiveDestinationsStatus computes the status of the additional WAL
+// archives given the current one and the result of the archival, dropping
+// the destinations that are not defined anymore
+func setWALArchiveDestinationsStatus(
+	destinations []apiv1.WALArchiveDestination,
+	currentStatus map[string]postgres.WALArchiveDestinationStatus,
+	walStatus []WALArchiverResult,
+) map[string]postgres.WALArchiveDestinationStatus {
+	if len(destinations) == 0 {
+		return nil
+	}
+
+	result := make(map[string]postgres.WALArchiveDestinationStatus, len(destinations))
+	for _, destination := range destinations {
+		result[destination.Name] = currentStatus[destination.Name]
+	}
+
+	for _, status := range walStatus {
+		walName := filepath.Base(status.WalName)
+		timestamp := status.EndTime.Format(metav1.RFC3339Micro)
+		for name, err := range status.DestinationErrors {
+			destinationStatus, ok := result[name]
+			if !ok {
+				continue
+			}
+
+			if err != nil {
+				destinationStatus.LastFailedWAL = walName
+				destinationStatus.LastFailedTime = timestamp
+				destinationStatus.LastFailedError = err.Error()
+			} else if walName > destinationStatus.LastArchivedWAL {
+				destinationStatus.LastArchivedWAL = walName
+				destinationStatus.LastArchivedTime = timestamp
+			}
+			result[name] = destinationStatus
+		}
+	}
+
+	return result
+}
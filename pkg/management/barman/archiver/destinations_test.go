@@ -0,0 +1,143 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archiver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("additional WAL archives status", func() {
+	destinations := []apiv1.WALArchiveDestination{
+		{Name: "dr"},
+		{Name: "offsite", BestEffort: true},
+	}
+	endTime := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	It("is empty when there are no additional destinations", func() {
+		status := setWALArchiveDestinationsStatus(
+			nil,
+			map[string]postgres.WALArchiveDestinationStatus{"dr": {LastArchivedWAL: "000000010000000000000001"}},
+			nil,
+		)
+		Expect(status).To(BeNil())
+	})
+
+	It("records successes and failures for each destination", func() {
+		status := setWALArchiveDestinationsStatus(destinations, nil, []WALArchiverResult{
+			{
+				WalName: "pg_wal/000000010000000000000002",
+				EndTime: endTime,
+				DestinationErrors: map[string]error{
+					"dr":      nil,
+					"offsite": errors.New("connection refused"),
+				},
+			},
+		})
+		Expect(status).To(HaveLen(2))
+		Expect(status["dr"].LastArchivedWAL).To(Equal("000000010000000000000002"))
+		Expect(status["dr"].LastFailedWAL).To(BeEmpty())
+		Expect(status["offsite"].LastArchivedWAL).To(BeEmpty())
+		Expect(status["offsite"].LastFailedWAL).To(Equal("000000010000000000000002"))
+		Expect(status["offsite"].LastFailedError).To(Equal("connection refused"))
+	})
+
+	It("keeps the most recent archived WAL file", func() {
+		status := setWALArchiveDestinationsStatus(destinations, nil, []WALArchiverResult{
+			{
+				WalName:           "pg_wal/000000010000000000000004",
+				EndTime:           endTime,
+				DestinationErrors: map[string]error{"dr": nil},
+			},
+			{
+				WalName:           "pg_wal/000000010000000000000003",
+				EndTime:           endTime,
+				DestinationErrors: map[string]error{"dr": nil},
+			},
+		})
+		Expect(status["dr"].LastArchivedWAL).To(Equal("000000010000000000000004"))
+	})
+
+	It("drops the destinations that are not defined anymore", func() {
+		status := setWALArchiveDestinationsStatus(
+			destinations[:1],
+			map[string]postgres.WALArchiveDestinationStatus{
+				"dr":      {LastArchivedWAL: "000000010000000000000001"},
+				"removed": {LastArchivedWAL: "000000010000000000000001"},
+			},
+			nil,
+		)
+		Expect(status).To(HaveLen(1))
+		Expect(status).To(HaveKey("dr"))
+	})
+
+	When("stored in a file", func() {
+		var fileName string
+
+		BeforeEach(func() {
+			tempDir, err := os.MkdirTemp("", "wal-archive-destinations")
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(func() {
+				Expect(os.RemoveAll(tempDir)).To(Succeed())
+			})
+			fileName = filepath.Join(tempDir, "wal-archive-destinations.json")
+		})
+
+		It("is empty when it has never been written", func() {
+			Expect(readWALArchiveDestinationsStatus(fileName)).To(BeNil())
+		})
+
+		It("accumulates the results of the archiving attempts", func() {
+			Expect(recordWALArchiveDestinationsResult(fileName, destinations, []WALArchiverResult{
+				{
+					WalName:           "pg_wal/000000010000000000000002",
+					EndTime:           endTime,
+					DestinationErrors: map[string]error{"dr": nil, "offsite": errors.New("connection refused")},
+				},
+			})).To(Succeed())
+			Expect(recordWALArchiveDestinationsResult(fileName, destinations, []WALArchiverResult{
+				{
+					WalName:           "pg_wal/000000010000000000000003",
+					EndTime:           endTime,
+					DestinationErrors: map[string]error{"dr": nil},
+				},
+			})).To(Succeed())
+
+			status, err := readWALArchiveDestinationsStatus(fileName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status["dr"].LastArchivedWAL).To(Equal("000000010000000000000003"))
+			Expect(status["offsite"].LastFailedWAL).To(Equal("000000010000000000000002"))
+		})
+
+		It("is rewritten when it is corrupted", func() {
+			Expect(os.WriteFile(fileName, []byte("garbage"), 0o600)).To(Succeed())
+			_, err := readWALArchiveDestinationsStatus(fileName)
+			Expect(err).To(HaveOccurred())
+
+			Expect(recordWALArchiveDestinationsResult(fileName, destinations, nil)).To(Succeed())
+			Expect(readWALArchiveDestinationsStatus(fileName)).To(HaveLen(2))
+		})
+	})
+})
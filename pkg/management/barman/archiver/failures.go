@@ -0,0 +1,78 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archiver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+)
+
+// walArchiveFailuresFile is the file where the wal-archive command keeps
+// the number of consecutive WAL archiving failures. The file is shared
+// between the wal-archive processes spawned by PostgreSQL and the
+// instance manager, which exports its content as a metric
+const walArchiveFailuresFile = postgres.ScratchDataDirectory + "/wal-archive-failures"
+
+// GetWALArchiveConsecutiveFailures gets the number of consecutive
+// failures of the WAL archiving
+func GetWALArchiveConsecutiveFailures() (int, error) {
+	return readWALArchiveFailures(walArchiveFailuresFile)
+}
+
+// RecordWALArchiveResult updates the number of consecutive failures of
+// the WAL archiving after an attempt, resetting it when the attempt
+// succeeded, and returns the updated value
+func RecordWALArchiveResult(archiveErr error) (int, error) {
+	return recordWALArchiveResult(walArchiveFailuresFile, archiveErr)
+}
+
+func readWALArchiveFailures(fileName string) (int, error) {
+	content, err := fileutils.ReadFile(fileName)
+	if err != nil {
+		return 0, err
+	}
+	if len(content) == 0 {
+		// The counter has never been written
+		return 0, nil
+	}
+
+	failures, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("while parsing %s: %w", fileName, err)
+	}
+
+	return failures, nil
+}
+
+func recordWALArchiveResult(fileName string, archiveErr error) (int, error) {
+	failures := 0
+	if archiveErr != nil {
+		// The counter is rewritten from scratch when it can't be parsed
+		failures, _ = readWALArchiveFailures(fileName)
+		failures++
+	}
+
+	if _, err := fileutils.WriteFileAtomic(fileName, []byte(strconv.Itoa(failures)), 0o600); err != nil {
+		return failures, err
+	}
+
+	return failures, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archiver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WAL archive consecutive failures", func() {
+	var fileName string
+
+	BeforeEach(func() {
+		tempDir, err := os.MkdirTemp("", "wal-archive-failures")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(func() {
+			Expect(os.RemoveAll(tempDir)).To(Succeed())
+		})
+		fileName = filepath.Join(tempDir, "wal-archive-failures")
+	})
+
+	It("reports no failures when the counter has never been written", func() {
+		Expect(readWALArchiveFailures(fileName)).To(Equal(0))
+	})
+
+	It("counts the consecutive failures and resets them after a success", func() {
+		archiveErr := errors.New("bucket not found")
+		Expect(recordWALArchiveResult(fileName, archiveErr)).To(Equal(1))
+		Expect(recordWALArchiveResult(fileName, archiveErr)).To(Equal(2))
+		Expect(readWALArchiveFailures(fileName)).To(Equal(2))
+
+		Expect(recordWALArchiveResult(fileName, nil)).To(Equal(0))
+		Expect(readWALArchiveFailures(fileName)).To(Equal(0))
+	})
+
+	It("keeps the counter readable only by its owner", func() {
+		Expect(recordWALArchiveResult(fileName, errors.New("failure"))).To(Equal(1))
+		info, err := os.Stat(fileName)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+	})
+
+	It("restarts counting when the counter is corrupted", func() {
+		Expect(os.WriteFile(fileName, []byte("garbage"), 0o600)).To(Succeed())
+		_, err := readWALArchiveFailures(fileName)
+		Expect(err).To(HaveOccurred())
+		Expect(recordWALArchiveResult(fileName, errors.New("failure"))).To(Equal(1))
+	})
+})
@@ -71,18 +71,8 @@ func ParseBarmanCloudBackupList(output string) (*catalog.Catalog, error) {
 	}
 
 	for idx := range result.List {
-		if result.List[idx].BeginTimeString != "" {
-			result.List[idx].BeginTime, err = time.Parse(barmanTimeLayout, result.List[idx].BeginTimeString)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		if result.List[idx].EndTimeString != "" {
-			result.List[idx].EndTime, err = time.Parse(barmanTimeLayout, result.List[idx].EndTimeString)
-			if err != nil {
-				return nil, err
-			}
+		if err = parseBackupTimes(&result.List[idx]); err != nil {
+			return nil, err
 		}
 	}
 
@@ -92,25 +82,39 @@ func ParseBarmanCloudBackupList(output string) (*catalog.Catalog, error) {
 	return result, nil
 }
 
+// parseBackupTimes fills the begin and end time of a backup
+// from their string representation
+func parseBackupTimes(backup *catalog.BarmanBackup) error {
+	var err error
+
+	if backup.BeginTimeString != "" {
+		backup.BeginTime, err = time.Parse(barmanTimeLayout, backup.BeginTimeString)
+		if err != nil {
+			return err
+		}
+	}
+
+	if backup.EndTimeString != "" {
+		backup.EndTime, err = time.Parse(barmanTimeLayout, backup.EndTimeString)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // GetBackupList returns the catalog reading it from the object store
 func GetBackupList(
 	barmanConfiguration *v1.BarmanObjectStoreConfiguration,
 	serverName string,
 	env []string,
 ) (*catalog.Catalog, error) {
-	options := []string{"--format", "json"}
-
-	if barmanConfiguration.EndpointURL != "" {
-		options = append(options, "--endpoint-url", barmanConfiguration.EndpointURL)
-	}
-
-	options, err := AppendCloudProviderOptionsFromConfiguration(options, barmanConfiguration)
+	options, err := appendObjectStoreOptions([]string{"--format", "json"}, barmanConfiguration, serverName)
 	if err != nil {
 		return nil, err
 	}
 
-	options = append(options, barmanConfiguration.DestinationPath, serverName)
-
 	var stdoutBuffer bytes.Buffer
 	var stderrBuffer bytes.Buffer
 	cmd := exec.Command(barmanCapabilities.BarmanCloudBackupList, options...) // #nosec G204
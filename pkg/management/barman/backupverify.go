@@ -0,0 +1,244 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package barman
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+
+	v1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	barmanCapabilities "github.com/cloudnative-pg/cloudnative-pg/pkg/management/barman/capabilities"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/catalog"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+)
+
+// VerifyBackup checks that the backup with the passed ID can be used
+// to recover a cluster. The backup metadata is read again from the object
+// store, and every WAL file between the beginning and the end of the backup
+// is downloaded into a temporary directory created inside scratchDir
+func VerifyBackup(
+	barmanConfiguration *v1.BarmanObjectStoreConfiguration,
+	serverName string,
+	backupID string,
+	scratchDir string,
+	env []string,
+) error {
+	backup, err := GetBackupByID(barmanConfiguration, serverName, backupID, env)
+	if err != nil {
+		return err
+	}
+
+	if err = backup.Verify(); err != nil {
+		return err
+	}
+
+	var segmentSize *int64
+	if backup.SegmentSize != 0 {
+		segmentSize = &backup.SegmentSize
+	}
+
+	walNames, err := walRange(backup.BeginWal, backup.EndWal, segmentSize)
+	if err != nil {
+		return fmt.Errorf("backup %s: %w", backupID, err)
+	}
+
+	verifyDir, err := os.MkdirTemp(scratchDir, "backup-verify")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(verifyDir); err != nil {
+			barmanLog.Error(err, "Can't remove the backup verification directory", "directory", verifyDir)
+		}
+	}()
+
+	for _, walName := range walNames {
+		if err := checkWALPresence(barmanConfiguration, serverName, walName, verifyDir, env); err != nil {
+			return fmt.Errorf("backup %s: %w", backupID, err)
+		}
+	}
+
+	return nil
+}
+
+// GetBackupByID reads the metadata of the backup with the passed ID from
+// the object store. barman-cloud-backup-show is used when available,
+// falling back to the whole catalog otherwise
+func GetBackupByID(
+	barmanConfiguration *v1.BarmanObjectStoreConfiguration,
+	serverName string,
+	backupID string,
+	env []string,
+) (*catalog.BarmanBackup, error) {
+	capabilities, err := barmanCapabilities.CurrentCapabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	if !capabilities.HasBackupShow {
+		backupList, err := GetBackupList(barmanConfiguration, serverName, env)
+		if err != nil {
+			return nil, err
+		}
+		for idx := range backupList.List {
+			if backupList.List[idx].ID == backupID {
+				return &backupList.List[idx], nil
+			}
+		}
+		return nil, fmt.Errorf("backup %s not found in the catalog", backupID)
+	}
+
+	options := []string{"--format", "json"}
+	options, err = appendObjectStoreOptions(options, barmanConfiguration, serverName)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, backupID)
+
+	var stdoutBuffer bytes.Buffer
+	var stderrBuffer bytes.Buffer
+	cmd := exec.Command(barmanCapabilities.BarmanCloudBackupShow, options...) // #nosec G204
+	cmd.Env = env
+	cmd.Stdout = &stdoutBuffer
+	cmd.Stderr = &stderrBuffer
+	if err = cmd.Run(); err != nil {
+		barmanLog.Error(err,
+			"Can't read the backup using barman-cloud-backup-show",
+			"options", options,
+			"stdout", stdoutBuffer.String(),
+			"stderr", stderrBuffer.String())
+		return nil, fmt.Errorf("backup %s not found in the object store: %w", backupID, err)
+	}
+
+	return ParseBarmanCloudBackupShow(stdoutBuffer.String())
+}
+
+// ParseBarmanCloudBackupShow parses the output of barman-cloud-backup-show
+func ParseBarmanCloudBackupShow(output string) (*catalog.BarmanBackup, error) {
+	result := struct {
+		Cloud *catalog.BarmanBackup `json:"cloud"`
+	}{}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+
+	if result.Cloud == nil {
+		return nil, fmt.Errorf("missing backup information in barman-cloud-backup-show output")
+	}
+
+	if err := parseBackupTimes(result.Cloud); err != nil {
+		return nil, err
+	}
+
+	return result.Cloud, nil
+}
+
+// checkWALPresence downloads a WAL file from the object store into the
+// passed directory, removing it afterwards
+func checkWALPresence(
+	barmanConfiguration *v1.BarmanObjectStoreConfiguration,
+	serverName string,
+	walName string,
+	directory string,
+	env []string,
+) error {
+	options, err := appendObjectStoreOptions(nil, barmanConfiguration, serverName)
+	if err != nil {
+		return err
+	}
+	destinationPath := path.Join(directory, walName)
+	options = append(options, walName, destinationPath)
+
+	var stderrBuffer bytes.Buffer
+	cmd := exec.Command(barmanCapabilities.BarmanCloudWalRestore, options...) // #nosec G204
+	cmd.Env = env
+	cmd.Stderr = &stderrBuffer
+	if err = cmd.Run(); err != nil {
+		barmanLog.Error(err,
+			"Can't download WAL file using barman-cloud-wal-restore",
+			"walName", walName,
+			"options", options,
+			"stderr", stderrBuffer.String())
+		return fmt.Errorf("WAL file %s is not available in the object store: %w", walName, err)
+	}
+
+	return os.Remove(destinationPath)
+}
+
+// appendObjectStoreOptions adds the options needed to reach the
+// object store and the server name to a barman-cloud command line
+func appendObjectStoreOptions(
+	options []string,
+	barmanConfiguration *v1.BarmanObjectStoreConfiguration,
+	serverName string,
+) ([]string, error) {
+	if barmanConfiguration.EndpointURL != "" {
+		options = append(options, "--endpoint-url", barmanConfiguration.EndpointURL)
+	}
+
+	options, err := AppendCloudProviderOptionsFromConfiguration(options, barmanConfiguration)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(options, barmanConfiguration.DestinationPath, serverName), nil
+}
+
+// walRange returns the names of all the WAL files between beginWal
+// and endWal, both included
+func walRange(beginWal, endWal string, segmentSize *int64) ([]string, error) {
+	begin, err := postgres.SegmentFromName(beginWal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid begin WAL %s: %w", beginWal, err)
+	}
+
+	end, err := postgres.SegmentFromName(endWal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end WAL %s: %w", endWal, err)
+	}
+
+	switch {
+	case begin.Tli != end.Tli:
+		return nil, fmt.Errorf("WAL range %s-%s spans more than one timeline", beginWal, endWal)
+	case isSegmentAfter(begin, end):
+		return nil, fmt.Errorf("WAL range %s-%s is not valid: it begins after its end", beginWal, endWal)
+	}
+
+	var result []string
+	current := begin
+	for !isSegmentAfter(current, end) {
+		result = append(result, current.Name())
+		current = current.NextSegments(2, nil, segmentSize)[1]
+	}
+
+	// With a segment size not matching the WAL names, the end may be skipped
+	if result[len(result)-1] != end.Name() {
+		return nil, fmt.Errorf("WAL range %s-%s is not valid for the WAL segment size", beginWal, endWal)
+	}
+
+	return result, nil
+}
+
+// isSegmentAfter checks whether the segment a comes after the segment b
+// in the same timeline
+func isSegmentAfter(a, b postgres.Segment) bool {
+	return a.Log > b.Log || (a.Log == b.Log && a.Seg > b.Seg)
+}
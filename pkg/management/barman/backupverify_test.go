@@ -0,0 +1,105 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package barman
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const barmanCloudShowOutput = `{
+  "cloud": {
+    "backup_label": null,
+    "begin_offset": 40,
+    "begin_time": "Tue Oct 20 11:52:31 2020",
+    "begin_wal": "000000010000000000000006",
+    "begin_xlog": "0/6000028",
+    "end_offset": 312,
+    "end_time": "Tue Oct 20 11:52:34 2020",
+    "end_wal": "000000010000000000000008",
+    "end_xlog": "0/8000138",
+    "error": null,
+    "server_name": "cloud",
+    "status": "DONE",
+    "systemid": "6885668674852188181",
+    "timeline": 1,
+    "version": 120004,
+    "xlog_segment_size": 16777216,
+    "backup_id": "20201020T115231"
+  }
+}`
+
+var _ = Describe("barman-cloud-backup-show parsing", func() {
+	It("must parse a correct output", func() {
+		result, err := ParseBarmanCloudBackupShow(barmanCloudShowOutput)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.ID).To(Equal("20201020T115231"))
+		Expect(result.BeginWal).To(Equal("000000010000000000000006"))
+		Expect(result.EndWal).To(Equal("000000010000000000000008"))
+		Expect(result.SegmentSize).To(BeEquivalentTo(16777216))
+		Expect(result.BeginTime.IsZero()).To(BeFalse())
+		Expect(result.EndTime.IsZero()).To(BeFalse())
+		Expect(result.Verify()).To(Succeed())
+	})
+
+	It("must complain when the backup information is missing", func() {
+		_, err := ParseBarmanCloudBackupShow("{}")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WAL range of a backup", func() {
+	It("contains a single WAL file when the backup begins and ends in the same file", func() {
+		Expect(walRange("000000010000000000000006", "000000010000000000000006", nil)).
+			To(Equal([]string{"000000010000000000000006"}))
+	})
+
+	It("contains every WAL file between the beginning and the end", func() {
+		Expect(walRange("0000000100000000000000FE", "000000010000000100000001", nil)).
+			To(Equal([]string{
+				"0000000100000000000000FE",
+				"0000000100000000000000FF",
+				"000000010000000100000000",
+				"000000010000000100000001",
+			}))
+	})
+
+	It("takes the WAL segment size into account", func() {
+		segmentSize := int64(1024 * 1024 * 1024)
+		Expect(walRange("000000010000000000000003", "000000010000000100000000", &segmentSize)).
+			To(Equal([]string{
+				"000000010000000000000003",
+				"000000010000000100000000",
+			}))
+	})
+
+	It("rejects ranges spanning more than one timeline", func() {
+		_, err := walRange("000000010000000000000006", "000000020000000000000007", nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects ranges ending before they begin", func() {
+		_, err := walRange("000000010000000000000007", "000000010000000000000006", nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects ranges whose end is skipped because of the WAL segment size", func() {
+		segmentSize := int64(1024 * 1024 * 1024)
+		_, err := walRange("000000010000000000000003", "000000010000000000000005", &segmentSize)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -29,6 +29,9 @@ const (
 	// BarmanCloudBackupList is the command name for 'barman-cloud-backup-delete'
 	BarmanCloudBackupList = "barman-cloud-backup-list"
 
+	// BarmanCloudBackupShow is the command name for 'barman-cloud-backup-show'
+	BarmanCloudBackupShow = "barman-cloud-backup-show"
+
 	// BarmanCloudWalArchive is the command name for 'barman-cloud-wal-archive'
 	BarmanCloudWalArchive = "barman-cloud-wal-archive"
 
@@ -47,6 +47,16 @@ func Detect() (*Capabilities, error) {
 	newCapabilities.Version = version
 
 	switch {
+	case version.GE(semver.Version{Major: 3, Minor: 12}):
+		// Zstd compression support for base backups, added in Barman >= 3.12
+		newCapabilities.HasZstd = true
+		fallthrough
+	case version.GE(semver.Version{Major: 3, Minor: 0}):
+		// Base backup bandwidth throttling, added in Barman >= 3.0
+		newCapabilities.HasMaxBandwidth = true
+		// Barman-cloud-backup-show, added in Barman >= 3.0
+		newCapabilities.HasBackupShow = true
+		fallthrough
 	case version.GE(semver.Version{Major: 2, Minor: 18}):
 		// Tags, added in Barman >= 2.18
 		newCapabilities.HasTags = true
@@ -30,7 +30,10 @@ type Capabilities struct {
 	HasTags                    bool
 	HasCheckWalArchive         bool
 	HasSnappy                  bool
+	HasZstd                    bool
 	HasErrorCodesForWALRestore bool
 	HasAzureManagedIdentity    bool
+	HasMaxBandwidth            bool
+	HasBackupShow              bool
 	Version                    *semver.Version
 }
@@ -20,12 +20,17 @@ package credentials
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 )
 
@@ -44,6 +49,12 @@ func EnvSetBackupCloudCredentials(
 		env = append(env, fmt.Sprintf("REQUESTS_CA_BUNDLE=%s", postgres.BarmanBackupEndpointCACertificateLocation))
 	}
 
+	env, err := envSetCustomVariables(ctx, c, namespace, configuration, env)
+	if err != nil {
+		return nil, err
+	}
+	env = envSetProxy(configuration, env)
+
 	return envSetCloudCredentials(ctx, c, namespace, configuration, env)
 }
 
@@ -61,9 +72,180 @@ func EnvSetRestoreCloudCredentials(
 	} else if configuration.EndpointCA != nil && configuration.BarmanCredentials.Azure != nil {
 		env = append(env, fmt.Sprintf("REQUESTS_CA_BUNDLE=%s", postgres.BarmanRestoreEndpointCACertificateLocation))
 	}
+
+	env, err := envSetCustomVariables(ctx, c, namespace, configuration, env)
+	if err != nil {
+		return nil, err
+	}
+	env = envSetProxy(configuration, env)
+
 	return envSetCloudCredentials(ctx, c, namespace, configuration, env)
 }
 
+// proxyEnvVars are the environment variables containing the proxy URL. Both
+// the upper and the lower case forms are set, as the libraries used by the
+// barman-cloud commands read either of them
+var proxyEnvVars = []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"}
+
+// envSetProxy adds the environment variables configuring the proxy of the
+// object store, if any. They're set after the custom variables, so that
+// they take precedence over them
+func envSetProxy(configuration *apiv1.BarmanObjectStoreConfiguration, env []string) []string {
+	if configuration.Proxy == nil {
+		return env
+	}
+
+	for _, name := range proxyEnvVars {
+		env = append(env, fmt.Sprintf("%s=%s", name, configuration.Proxy.URL))
+	}
+
+	if len(configuration.Proxy.NoProxy) > 0 {
+		noProxy := strings.Join(configuration.Proxy.NoProxy, ",")
+		env = append(env, fmt.Sprintf("NO_PROXY=%s", noProxy), fmt.Sprintf("no_proxy=%s", noProxy))
+	}
+
+	return env
+}
+
+// envSetCustomVariables adds the environment variables defined by the user
+// in the object store configuration, skipping the ones which can't be
+// customized. They're set before the credentials, so that the variables
+// managed by the operator always take precedence
+func envSetCustomVariables(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	configuration *apiv1.BarmanObjectStoreConfiguration,
+	env []string,
+) ([]string, error) {
+	for _, source := range configuration.EnvFrom {
+		values, err := getEnvFromSourceValues(ctx, c, namespace, source)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			variableName := source.Prefix + name
+			if len(validation.IsEnvVarName(variableName)) > 0 || !apiv1.IsBarmanCustomizableEnvVar(variableName) {
+				log.Info("Skipping environment variable for barman-cloud", "name", variableName)
+				continue
+			}
+			env = append(env, fmt.Sprintf("%s=%s", variableName, values[name]))
+		}
+	}
+
+	for _, variable := range configuration.Env {
+		if !apiv1.IsBarmanCustomizableEnvVar(variable.Name) {
+			log.Info("Skipping environment variable for barman-cloud", "name", variable.Name)
+			continue
+		}
+
+		value, found, err := getEnvVarValue(ctx, c, namespace, variable)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			env = append(env, fmt.Sprintf("%s=%s", variable.Name, value))
+		}
+	}
+
+	return env, nil
+}
+
+// getEnvVarValue gets the value of an environment variable, reading it from
+// the referenced secret or config map if needed. Optional references
+// to missing objects or keys are reported as not found
+func getEnvVarValue(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	variable corev1.EnvVar,
+) (string, bool, error) {
+	if variable.ValueFrom == nil {
+		return variable.Value, true, nil
+	}
+
+	var values map[string]string
+	var key string
+	var optional *bool
+	var err error
+	switch {
+	case variable.ValueFrom.SecretKeyRef != nil:
+		selector := variable.ValueFrom.SecretKeyRef
+		key, optional = selector.Key, selector.Optional
+		values, err = getEnvFromSourceValues(ctx, c, namespace, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: selector.LocalObjectReference, Optional: optional},
+		})
+	case variable.ValueFrom.ConfigMapKeyRef != nil:
+		selector := variable.ValueFrom.ConfigMapKeyRef
+		key, optional = selector.Key, selector.Optional
+		values, err = getEnvFromSourceValues(ctx, c, namespace, corev1.EnvFromSource{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: selector.LocalObjectReference, Optional: optional},
+		})
+	default:
+		return "", false, fmt.Errorf("unsupported source for environment variable %s", variable.Name)
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := values[key]
+	if !ok && (optional == nil || !*optional) {
+		return "", false, fmt.Errorf("missing key %s for environment variable %s", key, variable.Name)
+	}
+
+	return value, ok, nil
+}
+
+// getEnvFromSourceValues reads the content of the secret or config map
+// referenced by the passed source. A missing optional object has no values
+func getEnvFromSourceValues(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	source corev1.EnvFromSource,
+) (map[string]string, error) {
+	var object client.Object
+	var name string
+	var optional *bool
+	switch {
+	case source.SecretRef != nil:
+		object, name, optional = &corev1.Secret{}, source.SecretRef.Name, source.SecretRef.Optional
+	case source.ConfigMapRef != nil:
+		object, name, optional = &corev1.ConfigMap{}, source.ConfigMapRef.Name, source.ConfigMapRef.Optional
+	default:
+		return nil, fmt.Errorf("missing secretRef or configMapRef in envFrom")
+	}
+
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, object)
+	if apierrors.IsNotFound(err) && optional != nil && *optional {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("while getting %s for barman-cloud environment: %w", name, err)
+	}
+
+	values := make(map[string]string)
+	switch typedObject := object.(type) {
+	case *corev1.Secret:
+		for key, value := range typedObject.Data {
+			values[key] = string(value)
+		}
+	case *corev1.ConfigMap:
+		for key, value := range typedObject.Data {
+			values[key] = value
+		}
+	}
+
+	return values, nil
+}
+
 // envSetCloudCredentials sets the AWS environment variables given the configuration
 // inside the cluster
 func envSetCloudCredentials(
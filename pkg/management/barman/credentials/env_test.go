@@ -0,0 +1,146 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("custom environment variables for barman-cloud", func() {
+	const namespace = "default"
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy-secret", Namespace: namespace},
+		Data: map[string][]byte{
+			"HTTPS_PROXY":       []byte("http://proxy:3128"),
+			"AWS_ACCESS_KEY_ID": []byte("overridden"),
+			"LD_PRELOAD":        []byte("/tmp/library.so"),
+			"PYTHONPATH":        []byte("/tmp"),
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "provider-options", Namespace: namespace},
+		Data:       map[string]string{"region": "eu-west-1"},
+	}
+
+	It("adds the variables from values, secrets and config maps", func() {
+		c := fake.NewClientBuilder().WithObjects(secret, configMap).Build()
+		configuration := &apiv1.BarmanObjectStoreConfiguration{
+			EnvFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "proxy-secret"},
+				}},
+			},
+			Env: []corev1.EnvVar{
+				{Name: "NO_PROXY", Value: "localhost"},
+				{Name: "AWS_REGION", ValueFrom: &corev1.EnvVarSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "provider-options"},
+						Key:                  "region",
+					},
+				}},
+			},
+		}
+
+		env, err := envSetCustomVariables(context.Background(), c, namespace, configuration, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(env).To(Equal([]string{
+			"HTTPS_PROXY=http://proxy:3128",
+			"NO_PROXY=localhost",
+			"AWS_REGION=eu-west-1",
+		}))
+	})
+
+	It("skips optional references to missing objects", func() {
+		c := fake.NewClientBuilder().Build()
+		configuration := &apiv1.BarmanObjectStoreConfiguration{
+			EnvFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+					Optional:             pointer.Bool(true),
+				}},
+			},
+			Env: []corev1.EnvVar{
+				{Name: "HTTPS_PROXY", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+						Key:                  "proxy",
+						Optional:             pointer.Bool(true),
+					},
+				}},
+			},
+		}
+
+		env, err := envSetCustomVariables(context.Background(), c, namespace, configuration, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(env).To(BeEmpty())
+	})
+
+	It("fails when a required reference is missing", func() {
+		c := fake.NewClientBuilder().WithObjects(configMap).Build()
+		configuration := &apiv1.BarmanObjectStoreConfiguration{
+			Env: []corev1.EnvVar{
+				{Name: "AWS_REGION", ValueFrom: &corev1.EnvVarSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "provider-options"},
+						Key:                  "missing",
+					},
+				}},
+			},
+		}
+
+		_, err := envSetCustomVariables(context.Background(), c, namespace, configuration, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("proxy environment variables for barman-cloud", func() {
+	It("does nothing without a proxy", func() {
+		env := envSetProxy(&apiv1.BarmanObjectStoreConfiguration{}, []string{"A=B"})
+		Expect(env).To(Equal([]string{"A=B"}))
+	})
+
+	It("sets the proxy after the custom variables", func() {
+		configuration := &apiv1.BarmanObjectStoreConfiguration{
+			Proxy: &apiv1.ProxyConfiguration{
+				URL:     "http://proxy.example.com:3128",
+				NoProxy: []string{"10.0.0.0/8", ".svc"},
+			},
+		}
+
+		env := envSetProxy(configuration, []string{"HTTPS_PROXY=http://other:8080"})
+		Expect(env).To(Equal([]string{
+			"HTTPS_PROXY=http://other:8080",
+			"HTTP_PROXY=http://proxy.example.com:3128",
+			"HTTPS_PROXY=http://proxy.example.com:3128",
+			"http_proxy=http://proxy.example.com:3128",
+			"https_proxy=http://proxy.example.com:3128",
+			"NO_PROXY=10.0.0.0/8,.svc",
+			"no_proxy=10.0.0.0/8,.svc",
+		}))
+	})
+})
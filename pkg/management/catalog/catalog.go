@@ -226,6 +226,24 @@ type BarmanBackup struct {
 
 	// The TimeLine
 	TimeLine int `json:"timeline"`
+
+	// The size of the WAL segments of the cluster
+	SegmentSize int64 `json:"xlog_segment_size"`
+}
+
+// Verify checks that the backup has been stored without errors
+// and contains everything needed to be used in a recovery
+func (b *BarmanBackup) Verify() error {
+	switch {
+	case b.Error != "":
+		return fmt.Errorf("backup %s has been stored with errors: %s", b.ID, b.Error)
+	case !b.isBackupDone():
+		return fmt.Errorf("backup %s is not complete", b.ID)
+	case b.BeginWal == "" || b.EndWal == "":
+		return fmt.Errorf("backup %s has no WAL range, it can't be used for a recovery", b.ID)
+	}
+
+	return nil
 }
 
 func (b *BarmanBackup) isBackupDone() bool {
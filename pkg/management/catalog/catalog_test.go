@@ -97,3 +97,34 @@ var _ = Describe("Backup catalog", func() {
 		Expect(BackupInfo.ID).To(Equal("202101011200"))
 	})
 })
+
+var _ = Describe("Backup verification", func() {
+	It("accepts a complete backup", func() {
+		backup := BarmanBackup{
+			ID:        "202101011200",
+			BeginTime: time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2021, 1, 1, 12, 30, 0, 0, time.UTC),
+			BeginWal:  "000000010000000000000002",
+			EndWal:    "000000010000000000000002",
+		}
+		Expect(backup.Verify()).To(Succeed())
+	})
+
+	It("refuses a backup without a WAL range", func() {
+		backup := BarmanBackup{
+			ID:        "202101021200",
+			BeginTime: time.Date(2021, 1, 2, 12, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2021, 1, 2, 12, 30, 0, 0, time.UTC),
+		}
+		Expect(backup.Verify()).To(MatchError(ContainSubstring("no WAL range")))
+	})
+
+	It("refuses a backup stored with errors", func() {
+		backup := BarmanBackup{
+			ID:        "202101031200",
+			BeginTime: time.Date(2021, 1, 3, 12, 0, 0, 0, time.UTC),
+			Error:     "failure uploading data",
+		}
+		Expect(backup.Verify()).To(MatchError(ContainSubstring("failure uploading data")))
+	})
+})
@@ -22,6 +22,7 @@ import (
 	"os"
 
 	"github.com/spf13/pflag"
+	uberzap "go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"k8s.io/klog/v2"
 	controllerruntime "sigs.k8s.io/controller-runtime"
@@ -37,6 +38,10 @@ type Flags struct {
 var (
 	logLevel       string
 	logDestination string
+
+	// currentLevel is the level of the logger created by ConfigureLogging,
+	// which can be changed at runtime with SetLogLevel
+	currentLevel = uberzap.NewAtomicLevelAt(DefaultLevel)
 )
 
 // AddFlags binds manager configuration flags to a given flagset
@@ -54,20 +59,44 @@ func (l *Flags) AddFlags(flags *pflag.FlagSet) {
 // passed from the user
 func (l *Flags) ConfigureLogging() {
 	logger := zap.New(zap.UseFlagOptions(&l.zapOptions), customLevel, customDestination)
-	switch logLevel {
+	if !isValidLogLevel(logLevel) {
+		logger.Info("Invalid log level, defaulting", "level", logLevel, "default", DefaultLevel)
+	}
+
+	controllerruntime.SetLogger(logger)
+	klog.SetLogger(logger)
+	SetLogger(logger)
+}
+
+// SetLogLevel changes the level of the logger created by ConfigureLogging
+// without restarting the process. It returns false, leaving the level
+// unchanged, when the passed level is not valid
+func SetLogLevel(l string) bool {
+	if !isValidLogLevel(l) {
+		return false
+	}
+
+	currentLevel.SetLevel(getLogLevel(l))
+	return true
+}
+
+// GetLogLevel returns the current level of the logger
+// created by ConfigureLogging
+func GetLogLevel() string {
+	return getLogLevelString(currentLevel.Level())
+}
+
+func isValidLogLevel(l string) bool {
+	switch l {
 	case ErrorLevelString,
 		WarningLevelString,
 		InfoLevelString,
 		DebugLevelString,
 		TraceLevelString:
-		break
+		return true
 	default:
-		logger.Info("Invalid log level, defaulting", "level", logLevel, "default", DefaultLevel)
+		return false
 	}
-
-	controllerruntime.SetLogger(logger)
-	klog.SetLogger(logger)
-	SetLogger(logger)
 }
 
 func getLogLevel(l string) zapcore.Level {
@@ -105,7 +134,8 @@ func getLogLevelString(l zapcore.Level) string {
 }
 
 func customLevel(in *zap.Options) {
-	in.Level = getLogLevel(logLevel)
+	currentLevel.SetLevel(getLogLevel(logLevel))
+	in.Level = currentLevel
 	in.EncoderConfigOptions = append(in.EncoderConfigOptions, func(c *zapcore.EncoderConfig) {
 		c.EncodeLevel = func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
 			enc.AppendString(getLogLevelString(l))
@@ -0,0 +1,49 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("log level", func() {
+	var previousLevel string
+
+	BeforeEach(func() {
+		previousLevel = GetLogLevel()
+		DeferCleanup(func() {
+			Expect(SetLogLevel(previousLevel)).To(BeTrue())
+		})
+	})
+
+	It("can be changed at runtime", func() {
+		Expect(SetLogLevel(TraceLevelString)).To(BeTrue())
+		Expect(GetLogLevel()).To(Equal(TraceLevelString))
+		Expect(currentLevel.Enabled(TraceLevel)).To(BeTrue())
+
+		Expect(SetLogLevel(WarningLevelString)).To(BeTrue())
+		Expect(GetLogLevel()).To(Equal(WarningLevelString))
+		Expect(currentLevel.Enabled(InfoLevel)).To(BeFalse())
+	})
+
+	It("is not changed when the new level is not valid", func() {
+		Expect(SetLogLevel(DebugLevelString)).To(BeTrue())
+		Expect(SetLogLevel("verbose")).To(BeFalse())
+		Expect(GetLogLevel()).To(Equal(DebugLevelString))
+	})
+})
@@ -113,7 +113,6 @@ var (
 		"admin_users":          PgBouncerAdminUser,
 		"auth_type":            "hba",
 		"auth_hba_file":        ConfigsDir + "/pg_hba.conf",
-		"server_tls_sslmode":   "verify-ca",
 		"server_tls_ca_file":   serverTLSCAPath,
 		"client_tls_sslmode":   "prefer",
 		"client_tls_cert_file": clientTLSCertPath,
@@ -165,7 +164,14 @@ func BuildConfigurationFiles(pooler *apiv1.Pooler, secrets *Secrets) (Configurat
 		return nil, fmt.Errorf("unsupported secret type for auth query: %s", secrets.AuthQuery.Type)
 	}
 
+	// PgBouncer would start anyway with an invalid CA, failing every
+	// connection to PostgreSQL, so we refuse to generate the configuration
+	if err := certs.ValidateCABundle(secrets.ServerCA.Data[certs.CACertKey]); err != nil {
+		return nil, fmt.Errorf("while validating the server CA secret %s: %w", secrets.ServerCA.Name, err)
+	}
+
 	parameters := buildPgBouncerParameters(pooler.Spec.PgBouncer.Parameters)
+	parameters["server_tls_sslmode"] = string(pooler.Spec.PgBouncer.GetServerTLSMode())
 
 	if isCertAuth {
 		parameters["server_tls_cert_file"] = authUserCrtPath
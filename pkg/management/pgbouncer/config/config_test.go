@@ -0,0 +1,94 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/certs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PgBouncer configuration files", func() {
+	var secrets *Secrets
+
+	BeforeEach(func() {
+		ca, err := certs.CreateRootCA("ca", "cnpg")
+		Expect(err).ToNot(HaveOccurred())
+		caSecret := ca.GenerateCASecret("default", "cluster-ca")
+
+		secrets = &Secrets{
+			AuthQuery: &corev1.Secret{
+				Type: corev1.SecretTypeBasicAuth,
+				Data: map[string][]byte{
+					corev1.BasicAuthUsernameKey: []byte("cnpg_pooler_pgbouncer"),
+					corev1.BasicAuthPasswordKey: []byte("password"),
+				},
+			},
+			Client:   &corev1.Secret{},
+			ClientCA: caSecret,
+			ServerCA: caSecret,
+		}
+	})
+
+	pooler := func(mode apiv1.PgBouncerServerTLSMode) *apiv1.Pooler {
+		return &apiv1.Pooler{
+			ObjectMeta: metav1.ObjectMeta{Name: "pooler", Namespace: "default"},
+			Spec: apiv1.PoolerSpec{
+				Cluster: apiv1.LocalObjectReference{Name: "cluster"},
+				Type:    apiv1.PoolerTypeRW,
+				PgBouncer: &apiv1.PgBouncerSpec{
+					PoolMode:      apiv1.PgBouncerPoolModeSession,
+					ServerTLSMode: mode,
+				},
+			},
+		}
+	}
+
+	pgbouncerIni := func(files ConfigurationFiles) string {
+		return string(files[filepath.Join(ConfigsDir, PgBouncerIniFileName)])
+	}
+
+	It("verifies the CA of PostgreSQL by default", func() {
+		files, err := BuildConfigurationFiles(pooler(""), secrets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pgbouncerIni(files)).To(ContainSubstring("server_tls_sslmode = verify-ca\n"))
+		Expect(pgbouncerIni(files)).To(ContainSubstring("server_tls_ca_file = " + serverTLSCAPath + "\n"))
+		Expect(files[serverTLSCAPath]).To(Equal(secrets.ServerCA.Data[certs.CACertKey]))
+	})
+
+	It("verifies the name of the PostgreSQL service when requested", func() {
+		files, err := BuildConfigurationFiles(pooler(apiv1.PgBouncerServerTLSModeVerifyFull), secrets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pgbouncerIni(files)).To(ContainSubstring("server_tls_sslmode = verify-full\n"))
+	})
+
+	It("refuses an invalid server CA", func() {
+		secrets.ServerCA = &corev1.Secret{
+			Data: map[string][]byte{certs.CACertKey: []byte(strings.Repeat("-", 10))},
+		}
+		_, err := BuildConfigurationFiles(pooler(""), secrets)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -100,6 +100,10 @@ func getDataConfiguration(
 		return nil, fmt.Errorf("snappy compression is not supported in Barman %v", capabilities.Version)
 	}
 
+	if configuration.Data.Compression == apiv1.CompressionTypeZstd && !capabilities.HasZstd {
+		return nil, fmt.Errorf("zstd compression is not supported in Barman %v", capabilities.Version)
+	}
+
 	if len(configuration.Data.Compression) != 0 {
 		options = append(
 			options,
@@ -126,6 +130,20 @@ func getDataConfiguration(
 			strconv.Itoa(int(*configuration.Data.Jobs)))
 	}
 
+	maxBandwidth, err := configuration.Data.GetMaxBandwidth()
+	if err != nil {
+		return nil, err
+	}
+	if maxBandwidth > 0 {
+		if !capabilities.HasMaxBandwidth {
+			return nil, fmt.Errorf("limiting the backup bandwidth is not supported in Barman %v", capabilities.Version)
+		}
+		options = append(
+			options,
+			"--max-bandwidth",
+			strconv.FormatInt(maxBandwidth, 10))
+	}
+
 	return options, nil
 }
 
@@ -401,6 +419,9 @@ func (b *BackupCommand) run(ctx context.Context) {
 
 	// Update backup status to match with the latest completed backup
 	b.updateCompletedBackupStatus(backupList)
+	if b.Cluster.Spec.Backup.Verify {
+		b.verifyBackup()
+	}
 	if err = UpdateBackupStatusAndRetry(ctx, b.Client, b.Backup); err != nil {
 		b.Log.Error(err, "Can't set backup status as completed")
 	}
@@ -469,6 +490,8 @@ func (b *BackupCommand) setupBackupStatus() {
 	backupStatus.DestinationPath = barmanConfiguration.DestinationPath
 	if barmanConfiguration.Data != nil {
 		backupStatus.Encryption = string(barmanConfiguration.Data.Encryption)
+		backupStatus.Compression = string(barmanConfiguration.Data.Compression)
+		backupStatus.MaxBandwidth = barmanConfiguration.Data.MaxBandwidth
 	}
 	// Set the barman server name as specified by the user.
 	// If not explicitly configured use the cluster name
@@ -479,6 +502,30 @@ func (b *BackupCommand) setupBackupStatus() {
 	backupStatus.Phase = apiv1.BackupPhaseRunning
 }
 
+// verifyBackup checks the completed backup against the object store,
+// reading its metadata again and downloading the WAL files needed to
+// make it consistent, and records the result in the backup status
+func (b *BackupCommand) verifyBackup() {
+	backupStatus := b.Backup.GetStatus()
+
+	err := barman.VerifyBackup(
+		b.Cluster.Spec.Backup.BarmanObjectStore,
+		backupStatus.ServerName,
+		backupStatus.BackupID,
+		postgres.ScratchDataDirectory,
+		b.Env,
+	)
+	backupStatus.SetVerification(err, time.Now())
+	if err != nil {
+		b.Log.Error(err, "Backup verification failed", "backupID", backupStatus.BackupID)
+		b.Recorder.Event(b.Backup, "Warning", "VerificationFailed", err.Error())
+		return
+	}
+
+	b.Log.Info("Backup verified", "backupID", backupStatus.BackupID)
+	b.Recorder.Event(b.Backup, "Normal", "Verified", "Backup verified")
+}
+
 // updateCompletedBackupStatus updates the backup calling barman-cloud-backup-list
 // to retrieve all the relevant data
 func (b *BackupCommand) updateCompletedBackupStatus(backupList *catalog.Catalog) {
@@ -0,0 +1,88 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgres
+
+import (
+	"github.com/blang/semver"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	barmanCapabilities "github.com/cloudnative-pg/cloudnative-pg/pkg/management/barman/capabilities"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("barman-cloud-backup data options", func() {
+	newConfiguration := func(compression apiv1.CompressionType) *apiv1.BarmanObjectStoreConfiguration {
+		return &apiv1.BarmanObjectStoreConfiguration{
+			Data: &apiv1.DataBackupConfiguration{
+				Compression: compression,
+			},
+		}
+	}
+
+	It("passes the compression algorithm to barman-cloud-backup", func() {
+		capabilities := &barmanCapabilities.Capabilities{
+			Version: &semver.Version{Major: 3, Minor: 12},
+			HasZstd: true,
+		}
+		options, err := getDataConfiguration(nil, newConfiguration(apiv1.CompressionTypeZstd), capabilities)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(options).To(ConsistOf("--zstd"))
+
+		options, err = getDataConfiguration(nil, newConfiguration(apiv1.CompressionTypeGzip), capabilities)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(options).To(ConsistOf("--gzip"))
+	})
+
+	It("refuses zstd compression when barman does not support it", func() {
+		capabilities := &barmanCapabilities.Capabilities{
+			Version:   &semver.Version{Major: 3, Minor: 0},
+			HasSnappy: true,
+		}
+		_, err := getDataConfiguration(nil, newConfiguration(apiv1.CompressionTypeZstd), capabilities)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("adds no compression option when compression is not set", func() {
+		options, err := getDataConfiguration(nil, newConfiguration(""), &barmanCapabilities.Capabilities{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(options).To(BeEmpty())
+	})
+
+	It("passes the maximum bandwidth in bytes to barman-cloud-backup", func() {
+		capabilities := &barmanCapabilities.Capabilities{
+			Version:         &semver.Version{Major: 3, Minor: 0},
+			HasMaxBandwidth: true,
+		}
+		configuration := newConfiguration("")
+		configuration.Data.MaxBandwidth = "50Mi"
+		options, err := getDataConfiguration(nil, configuration, capabilities)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(options).To(Equal([]string{"--max-bandwidth", "52428800"}))
+	})
+
+	It("refuses to limit the bandwidth when barman does not support it", func() {
+		capabilities := &barmanCapabilities.Capabilities{
+			Version: &semver.Version{Major: 2, Minor: 19},
+		}
+		configuration := newConfiguration("")
+		configuration.Data.MaxBandwidth = "50Mi"
+		_, err := getDataConfiguration(nil, configuration, capabilities)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -30,6 +30,7 @@ import (
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/constants"
 	postgresutils "github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/utils"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
 // InstallPgDataFileContent installs a file in PgData, returning true/false if
@@ -100,6 +101,7 @@ func (instance *Instance) GeneratePostgresqlHBA(cluster *apiv1.Cluster, ldapBind
 
 	return postgres.CreateHBARules(
 		cluster.Spec.PostgresConfiguration.PgHBA,
+		cluster.GetClientCertificateUsers(),
 		defaultAuthenticationMethod,
 		buildLDAPConfigString(cluster, ldapBindPassword))
 }
@@ -127,6 +129,19 @@ func (instance *Instance) RefreshPGHBA(cluster *apiv1.Cluster, ldapBindPassword
 	return postgresHBAChanged, err
 }
 
+// RefreshPGIdent writes down the pg_ident.conf file, mapping the operating
+// system user to the user managing the replication slots only when it's enabled
+func (instance *Instance) RefreshPGIdent(cluster *apiv1.Cluster) (postgresIdentChanged bool, err error) {
+	postgresIdentChanged, err = WritePostgresUserMaps(
+		instance.PgData,
+		cluster.Spec.ReplicationSlots.IsDedicatedRoleEnabled())
+	if err != nil {
+		return postgresIdentChanged, fmt.Errorf("installing postgresql user maps: %w", err)
+	}
+
+	return postgresIdentChanged, nil
+}
+
 // buildLDAPConfigString will create the string needed for ldap in pg_hba
 func buildLDAPConfigString(cluster *apiv1.Cluster, ldapBindPassword string) string {
 	var ldapConfigString string
@@ -303,6 +318,19 @@ func createPostgresqlConfiguration(cluster *apiv1.Cluster) (string, string, erro
 		IncludingSharedPreloadLibraries:  true,
 		AdditionalSharedPreloadLibraries: cluster.Spec.PostgresConfiguration.AdditionalLibraries,
 		IsReplicaCluster:                 cluster.IsReplica(),
+		LogDestination:                   string(cluster.GetLogFormat()),
+		TCPKeepalives:                    cluster.GetTCPKeepalivesParameters(),
+		WALKeepSize:                      cluster.GetWALKeepSizeParameters(fromVersion),
+		MemoryParameters:                 cluster.GetMemoryParameters(),
+		IdleInTransactionSessionTimeout:  cluster.GetIdleInTransactionSessionTimeout(),
+		ParametersSkippingValidation:     utils.GetParametersSkippingValidation(&cluster.ObjectMeta),
+		Autovacuum:                       cluster.GetAutovacuumParameters(),
+		WALCompression:                   cluster.GetWALCompression(),
+	}
+
+	if len(info.ParametersSkippingValidation) > 0 {
+		log.Warning("The validation of some PostgreSQL parameters has been disabled by the user",
+			"parameters", info.ParametersSkippingValidation)
 	}
 
 	// Compute the actual number of sync replicas
@@ -21,13 +21,33 @@ import (
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
+// primaryKeepalivesIdle is the number of seconds of inactivity after which
+// a TCP keepalive message is sent on the connections to the primary
+const primaryKeepalivesIdle = 30
+
 // buildPrimaryConnInfo builds the connection string to connect to primaryHostname
 func buildPrimaryConnInfo(primaryHostname, applicationName string) string {
 	// We should have been using configfile.CreateConnectionString
 	// but doing that we would cause an unnecessary restart of
 	// existing PostgreSQL 12 clusters.
+	return buildPrimaryConnInfoWithSSLMode(primaryHostname, applicationName, "verify-ca")
+}
+
+// buildVerifiedPrimaryConnInfo builds the connection string to connect to
+// primaryHostname, verifying that the server certificate has been issued
+// for it. The hostname must be included in the alternative DNS names of
+// the server certificate, as happens for the cluster services
+func buildVerifiedPrimaryConnInfo(primaryHostname, applicationName string) string {
+	// Detect the dead connections, i.e. after a network partition,
+	// sooner than the driver would do by default
+	return buildPrimaryConnInfoWithSSLMode(primaryHostname, applicationName, "verify-full") +
+		fmt.Sprintf(" %s=%d", utils.KeepalivesIdleParameter, primaryKeepalivesIdle)
+}
+
+func buildPrimaryConnInfoWithSSLMode(primaryHostname, applicationName, sslMode string) string {
 	primaryConnInfo := fmt.Sprintf("host=%v ", primaryHostname) +
 		fmt.Sprintf("user=%v ", apiv1.StreamingReplicationUser) +
 		fmt.Sprintf("port=%v ", GetServerPort()) +
@@ -35,6 +55,6 @@ func buildPrimaryConnInfo(primaryHostname, applicationName string) string {
 		fmt.Sprintf("sslcert=%v ", postgres.StreamingReplicaCertificateLocation) +
 		fmt.Sprintf("sslrootcert=%v ", postgres.ServerCACertificateLocation) +
 		fmt.Sprintf("application_name=%v ", applicationName) +
-		"sslmode=verify-ca"
+		fmt.Sprintf("sslmode=%v", sslMode)
 	return primaryConnInfo
 }
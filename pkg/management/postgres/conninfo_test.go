@@ -0,0 +1,41 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgres
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("primary connection string", func() {
+	It("verifies the CA for the streaming replication connection", func() {
+		connInfo := buildPrimaryConnInfo("cluster-example-rw", "cluster-example-2")
+		Expect(connInfo).To(ContainSubstring("host=cluster-example-rw "))
+		Expect(connInfo).To(ContainSubstring("application_name=cluster-example-2 "))
+		Expect(connInfo).To(ContainSubstring("sslrootcert=/controller/certificates/server-ca.crt "))
+		Expect(connInfo).To(HaveSuffix("sslmode=verify-ca"))
+		Expect(connInfo).ToNot(ContainSubstring("keepalives_idle"))
+	})
+
+	It("verifies the identity of the primary when requested", func() {
+		connInfo := buildVerifiedPrimaryConnInfo("cluster-example-rw", "cluster-example-2")
+		Expect(connInfo).To(ContainSubstring("host=cluster-example-rw "))
+		Expect(connInfo).To(ContainSubstring("sslrootcert=/controller/certificates/server-ca.crt "))
+		Expect(connInfo).To(ContainSubstring("sslmode=verify-full "))
+		Expect(connInfo).To(HaveSuffix("keepalives_idle=30"))
+	})
+})
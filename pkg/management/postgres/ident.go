@@ -21,14 +21,16 @@ import (
 	"os/user"
 	"path/filepath"
 
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/constants"
 )
 
 // WritePostgresUserMaps creates a pg_ident.conf file containing only one map called "local" that
-// maps the current user to "postgres" user.
-func WritePostgresUserMaps(pgData string) error {
+// maps the current user to "postgres" user and, when requested, to the user managing the
+// replication slots. Returns true if the file has been changed
+func WritePostgresUserMaps(pgData string, mapReplicationSlotsUser bool) (bool, error) {
 	var username string
 
 	currentUser, err := user.Current()
@@ -39,11 +41,10 @@ func WritePostgresUserMaps(pgData string) error {
 		username = currentUser.Username
 	}
 
-	_, err = fileutils.WriteStringToFile(filepath.Join(pgData, constants.PostgresqlIdentFile),
-		fmt.Sprintf("local %s postgres\n", username))
-	if err != nil {
-		return err
+	content := fmt.Sprintf("local %s postgres\n", username)
+	if mapReplicationSlotsUser {
+		content += fmt.Sprintf("local %s %s\n", username, apiv1.ReplicationSlotsUser)
 	}
 
-	return nil
+	return fileutils.WriteStringToFile(filepath.Join(pgData, constants.PostgresqlIdentFile), content)
 }
@@ -281,11 +281,12 @@ func (info InitInfo) executePostInitApplicationSQLRefs(sqlUser *sql.DB) error {
 	for _, file := range files {
 		sql, ioErr := fileutils.ReadFile(path.Join(info.PostInitApplicationSQLRefsFolder, file))
 		if ioErr != nil {
-			return fmt.Errorf("could not read file: %s, err; %w", file, err)
+			return fmt.Errorf("could not read file: %s, err; %w", file, ioErr)
 		}
 
+		log.Info("executing post init application SQL script", "file", file)
 		if err = info.executeQueries(sqlUser, []string{string(sql)}); err != nil {
-			return fmt.Errorf("could not execute queries: %w", err)
+			return fmt.Errorf("could not execute queries from file %s: %w", file, err)
 		}
 	}
 
@@ -399,12 +400,38 @@ func executeLogicalImport(
 	case apiv1.MicroserviceSnapshotType:
 		return logicalimport.Microservice(ctx, cluster, destinationPool, originPool)
 	case apiv1.MonolithSnapshotType:
-		return logicalimport.Monolith(ctx, cluster, destinationPool, originPool)
+		importedRoles, err := logicalimport.Monolith(ctx, cluster, destinationPool, originPool)
+		if err != nil {
+			return err
+		}
+		// The import is already complete, so we don't want to fail
+		// the bootstrap only because the status can't be updated
+		if err := updateImportedRolesStatus(ctx, client, cluster, importedRoles); err != nil {
+			log.FromContext(ctx).Error(err, "while reporting the imported roles")
+		}
+		return nil
 	default:
 		return fmt.Errorf("unrecognized clone type %s", cloneType)
 	}
 }
 
+// updateImportedRolesStatus stores in the cluster status the names
+// of the roles imported from the source cluster
+func updateImportedRolesStatus(
+	ctx context.Context,
+	client ctrl.Client,
+	cluster *apiv1.Cluster,
+	importedRoles []string,
+) error {
+	if len(importedRoles) == 0 {
+		return nil
+	}
+
+	existingCluster := cluster.DeepCopy()
+	cluster.Status.ImportedRoles = importedRoles
+	return client.Status().Patch(ctx, cluster, ctrl.MergeFrom(existingCluster))
+}
+
 func getConnectionPoolerForExternalCluster(
 	ctx context.Context,
 	cluster *apiv1.Cluster,
@@ -26,6 +26,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/blang/semver"
@@ -34,6 +35,7 @@ import (
 	"k8s.io/client-go/util/retry"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/management/controller/slots/infrastructure"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/execlog"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
@@ -133,6 +135,23 @@ type Instance struct {
 	// Pool of DB connections pointing to primary instance
 	primaryPool *pool.ConnectionPool
 
+	// poolMutex protects pool and primaryPool, which are lazily created
+	// and requested concurrently by the metrics collector, the replicator
+	// and the reconciler
+	poolMutex sync.Mutex
+
+	// Pool of DB connections used to manage the local replication
+	// slots with the dedicated low-privileged user
+	slotsPool *pool.ConnectionPool
+
+	// slotsPoolMutex protects slotsPool, which is requested concurrently
+	// by the replicator, the orphan slots reaper and the reconciler
+	slotsPoolMutex sync.Mutex
+
+	// slotsUserChecked is true when the privileges of the user
+	// managing the replication slots have been verified
+	slotsUserChecked atomic.Bool
+
 	// The namespace of the k8s object representing this cluster
 	Namespace string
 
@@ -167,6 +186,14 @@ type Instance struct {
 	// MaxStopDelay is the current MaxStopDelay of the cluster
 	MaxStopDelay int32
 
+	// ShutdownMode is the shutdown mode requested by the user for planned
+	// operations, empty when the default one should be used
+	ShutdownMode ShutdownMode
+
+	// ShutdownTimeout is the shutdown timeout requested by the user for
+	// planned operations, zero when the default one should be used
+	ShutdownTimeout int32
+
 	// canCheckReadiness specifies whether the instance can start being checked for readiness
 	// Is set to true before the instance is run and to false once it exits,
 	// it's used by the readiness probe to know whether it should be short-circuited
@@ -179,8 +206,30 @@ type Instance struct {
 	// fenced entails mightBeUnavailable ( entails as in logical consequence)
 	fenced atomic.Bool
 
+	// replicaCluster specifies whether the instance belongs to a replica
+	// cluster, whose primary is the designated one, always in recovery
+	replicaCluster atomic.Bool
+
 	// slotsReplicatorChan is used to send replication slot configuration to the slot replicator
 	slotsReplicatorChan chan *apiv1.ReplicationSlotsConfiguration
+
+	// slotsConvergence is the outcome of the last synchronization
+	// made by the slot replicator
+	slotsConvergence atomic.Pointer[postgres.SlotsConvergence]
+
+	// pgbouncerIntegrationReady is true when the user and the function
+	// used by the auth_query of PgBouncer exist in every database
+	pgbouncerIntegrationReady atomic.Bool
+
+	// probesConfiguration is the configuration of the readiness probe,
+	// nil when the default one should be used
+	probesConfiguration atomic.Pointer[apiv1.ProbesConfiguration]
+}
+
+// SetProbesConfiguration sets the configuration used by the readiness probe,
+// nil meaning the default one
+func (instance *Instance) SetProbesConfiguration(config *apiv1.ProbesConfiguration) {
+	instance.probesConfiguration.Store(config)
 }
 
 // IsFenced checks whether the instance is marked as fenced
@@ -188,6 +237,16 @@ func (instance *Instance) IsFenced() bool {
 	return instance.fenced.Load()
 }
 
+// IsReplicaCluster checks whether the instance belongs to a replica cluster
+func (instance *Instance) IsReplicaCluster() bool {
+	return instance.replicaCluster.Load()
+}
+
+// SetReplicaCluster marks whether the instance belongs to a replica cluster
+func (instance *Instance) SetReplicaCluster(enabled bool) {
+	instance.replicaCluster.Store(enabled)
+}
+
 // CanCheckReadiness checks whether the instance should be checked for readiness
 func (instance *Instance) CanCheckReadiness() bool {
 	return instance.canCheckReadiness.Load()
@@ -228,6 +287,30 @@ func (instance *Instance) SlotReplicatorChan() <-chan *apiv1.ReplicationSlotsCon
 	return instance.slotsReplicatorChan
 }
 
+// SetSlotsConvergence stores the outcome of the last synchronization made
+// by the slot replicator. A nil value means that no synchronization is in place
+func (instance *Instance) SetSlotsConvergence(convergence *postgres.SlotsConvergence) {
+	instance.slotsConvergence.Store(convergence)
+}
+
+// GetSlotsConvergence returns the outcome of the last synchronization made
+// by the slot replicator, or nil if no synchronization is in place
+func (instance *Instance) GetSlotsConvergence() *postgres.SlotsConvergence {
+	return instance.slotsConvergence.Load()
+}
+
+// SetPgBouncerIntegrationReady stores whether the user and the function used
+// by the auth_query of PgBouncer exist in every database
+func (instance *Instance) SetPgBouncerIntegrationReady(ready bool) {
+	instance.pgbouncerIntegrationReady.Store(ready)
+}
+
+// IsPgBouncerIntegrationReady returns true when the user and the function
+// used by the auth_query of PgBouncer exist in every database
+func (instance *Instance) IsPgBouncerIntegrationReady() bool {
+	return instance.pgbouncerIntegrationReady.Load()
+}
+
 // InstanceCommand are commands for the goroutine managing postgres
 type InstanceCommand string
 
@@ -332,12 +415,20 @@ func (instance *Instance) Startup() error {
 
 // ShutdownConnections tears down database connections
 func (instance *Instance) ShutdownConnections() {
+	instance.poolMutex.Lock()
 	if instance.pool != nil {
 		instance.pool.ShutdownConnections()
 	}
 	if instance.primaryPool != nil {
 		instance.primaryPool.ShutdownConnections()
 	}
+	instance.poolMutex.Unlock()
+
+	instance.slotsPoolMutex.Lock()
+	defer instance.slotsPoolMutex.Unlock()
+	if instance.slotsPool != nil {
+		instance.slotsPool.ShutdownConnections()
+	}
 }
 
 // Shutdown shuts down a PostgreSQL instance which was previously started
@@ -525,6 +616,10 @@ func (instance *Instance) GetPgVersion() (semver.Version, error) {
 // ConnectionPool gets or initializes the connection pool for this instance
 func (instance *Instance) ConnectionPool() *pool.ConnectionPool {
 	const applicationName = "cnpg-instance-manager"
+
+	instance.poolMutex.Lock()
+	defer instance.poolMutex.Unlock()
+
 	if instance.pool == nil {
 		socketDir := GetSocketDir()
 		dsn := fmt.Sprintf(
@@ -535,16 +630,62 @@ func (instance *Instance) ConnectionPool() *pool.ConnectionPool {
 			applicationName,
 		)
 
-		instance.pool = pool.NewConnectionPool(dsn)
+		instance.pool = pool.NewConnectionPool(dsn).WithCircuitBreaker()
 	}
 
 	return instance.pool
 }
 
+// ReplicationSlotsConnectionPool gets the connection pool used to manage the
+// local replication slots. Unless the dedicated user is enabled, this is the
+// superuser connection pool. The privileges of the dedicated user are
+// verified the first time it is used, so that a misconfiguration is
+// reported with a clear error
+func (instance *Instance) ReplicationSlotsConnectionPool(
+	ctx context.Context,
+	config *apiv1.ReplicationSlotsConfiguration,
+) (*pool.ConnectionPool, error) {
+	if !config.IsDedicatedRoleEnabled() {
+		return instance.ConnectionPool(), nil
+	}
+
+	instance.slotsPoolMutex.Lock()
+	defer instance.slotsPoolMutex.Unlock()
+
+	if instance.slotsPool == nil {
+		const applicationName = "cnpg-instance-manager"
+		dsn := fmt.Sprintf(
+			"host=%s port=%v user=%v sslmode=disable application_name=%v",
+			GetSocketDir(),
+			GetServerPort(),
+			apiv1.ReplicationSlotsUser,
+			applicationName,
+		)
+		instance.slotsPool = pool.NewConnectionPool(dsn).WithCircuitBreaker()
+	}
+
+	if !instance.slotsUserChecked.Load() {
+		if err := infrastructure.CheckPrivileges(ctx, instance.slotsPool, config.GetDatabase()); err != nil {
+			return nil, fmt.Errorf("while checking the %q role: %w", apiv1.ReplicationSlotsUser, err)
+		}
+		instance.slotsUserChecked.Store(true)
+	}
+
+	return instance.slotsPool, nil
+}
+
 // PrimaryConnectionPool gets or initializes the primary connection pool for this instance
 func (instance *Instance) PrimaryConnectionPool() *pool.ConnectionPool {
+	instance.poolMutex.Lock()
+	defer instance.poolMutex.Unlock()
+
 	if instance.primaryPool == nil {
-		instance.primaryPool = pool.NewConnectionPool(instance.GetPrimaryConnInfo())
+		// Unlike the streaming replication connection, which is part of the
+		// PostgreSQL configuration, this one can be changed freely, so we
+		// also verify the identity of the primary
+		instance.primaryPool = pool.NewConnectionPool(
+			buildVerifiedPrimaryConnInfo(instance.ClusterName+"-rw", instance.PodName),
+		).WithCircuitBreaker()
 	}
 
 	return instance.primaryPool
@@ -597,7 +738,9 @@ func (instance *Instance) WaitForPrimaryAvailable() error {
 		_ = db.Close()
 	}()
 
-	return waitForConnectionAvailable(db)
+	return waitForConnectionAvailable(func() (*sql.DB, error) {
+		return db, nil
+	})
 }
 
 // CompleteCrashRecovery temporary starts up the server and wait for it
@@ -617,23 +760,23 @@ func (instance *Instance) CompleteCrashRecovery() error {
 // WaitForSuperuserConnectionAvailable waits until we can connect to this
 // instance using the superuser account
 func (instance *Instance) WaitForSuperuserConnectionAvailable() error {
-	db, err := instance.GetSuperUserDB()
-	if err != nil {
-		return err
-	}
-
-	return waitForConnectionAvailable(db)
+	return waitForConnectionAvailable(instance.GetSuperUserDB)
 }
 
-// waitForConnectionAvailable waits until we can connect to the passed
-// sql.DB connection
-func waitForConnectionAvailable(db *sql.DB) error {
+// waitForConnectionAvailable waits until we can connect to the
+// sql.DB connection returned by the passed function. The connection
+// is requested at every attempt, as pools may short-circuit the
+// requests while the database is not reachable
+func waitForConnectionAvailable(getDB func() (*sql.DB, error)) error {
 	errorIsRetryable := func(err error) bool {
 		return err != nil
 	}
 
 	return retry.OnError(RetryUntilServerAvailable, errorIsRetryable, func() error {
-		err := db.Ping()
+		db, err := getDB()
+		if err == nil {
+			err = db.Ping()
+		}
 		if err != nil {
 			log.Info("DB not available, will retry", "err", err)
 		}
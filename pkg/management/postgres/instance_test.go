@@ -17,12 +17,15 @@ limitations under the License.
 package postgres
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/pool"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -224,3 +227,86 @@ var _ = Describe("check atomic bool", func() {
 		Expect(unAvailable).To(BeTrue())
 	})
 })
+
+var _ = Describe("connection pools", func() {
+	It("creates a single pool when requested concurrently", func() {
+		instance := Instance{}
+		const requests = 10
+
+		pools := make(chan *pool.ConnectionPool, requests)
+		primaryPools := make(chan *pool.ConnectionPool, requests)
+		var wg sync.WaitGroup
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pools <- instance.ConnectionPool()
+				primaryPools <- instance.PrimaryConnectionPool()
+			}()
+		}
+		wg.Wait()
+		close(pools)
+		close(primaryPools)
+
+		for connectionPool := range pools {
+			Expect(connectionPool).To(BeIdenticalTo(instance.ConnectionPool()))
+		}
+		for connectionPool := range primaryPools {
+			Expect(connectionPool).To(BeIdenticalTo(instance.PrimaryConnectionPool()))
+		}
+	})
+})
+
+var _ = Describe("replication slots connection pool", func() {
+	It("uses the superuser connection pool unless the dedicated role is enabled", func() {
+		instance := Instance{}
+		slotsPool, err := instance.ReplicationSlotsConnectionPool(context.Background(), nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(slotsPool).To(BeIdenticalTo(instance.ConnectionPool()))
+
+		slotsPool, err = instance.ReplicationSlotsConnectionPool(
+			context.Background(),
+			&apiv1.ReplicationSlotsConfiguration{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(slotsPool).To(BeIdenticalTo(instance.ConnectionPool()))
+	})
+
+	It("reports when the dedicated role can't be used", func() {
+		instance := Instance{}
+		_, err := instance.ReplicationSlotsConnectionPool(
+			context.Background(),
+			&apiv1.ReplicationSlotsConfiguration{UseDedicatedRole: true},
+		)
+		Expect(err).To(MatchError(ContainSubstring(
+			fmt.Sprintf("while checking the %q role", apiv1.ReplicationSlotsUser))))
+		Expect(instance.slotsUserChecked.Load()).To(BeFalse())
+	})
+
+	It("maps the current user to the dedicated role only when enabled", func() {
+		instance := Instance{PgData: GinkgoT().TempDir()}
+		identFile := filepath.Join(instance.PgData, "pg_ident.conf")
+		cluster := &apiv1.Cluster{}
+
+		changed, err := instance.RefreshPGIdent(cluster)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		content, err := os.ReadFile(identFile) // #nosec
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(MatchRegexp(`(?m)^local \S+ postgres$`))
+		Expect(string(content)).ToNot(ContainSubstring(apiv1.ReplicationSlotsUser))
+
+		cluster.Spec.ReplicationSlots = &apiv1.ReplicationSlotsConfiguration{UseDedicatedRole: true}
+		changed, err = instance.RefreshPGIdent(cluster)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		content, err = os.ReadFile(identFile) // #nosec
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(MatchRegexp(`(?m)^local \S+ postgres$`))
+		Expect(string(content)).To(MatchRegexp(`(?m)^local \S+ ` + apiv1.ReplicationSlotsUser + `$`))
+
+		changed, err = instance.RefreshPGIdent(cluster)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+	})
+})
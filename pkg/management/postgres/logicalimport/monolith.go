@@ -24,49 +24,57 @@ import (
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/pool"
 )
 
-// Monolith executes the monolith clone type
+// Monolith executes the monolith clone type, returning the names
+// of the imported roles
 func Monolith(
 	ctx context.Context,
 	cluster *apiv1.Cluster,
 	destination *pool.ConnectionPool,
 	origin *pool.ConnectionPool,
-) error {
+) ([]string, error) {
 	contextLogger := log.FromContext(ctx)
 	contextLogger.Info("starting monolith clone process")
 
-	if err := cloneRoles(ctx, cluster, destination, origin); err != nil {
-		return err
+	importedRoles, err := cloneRoles(ctx, cluster, destination, origin)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := cloneRoleInheritance(ctx, destination, origin); err != nil {
-		return err
+		return nil, err
 	}
 
 	ds := databaseSnapshotter{cluster: cluster}
 	databases, err := ds.getDatabaseList(ctx, origin)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := createDumpsDirectory(); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := ds.exportDatabases(ctx, origin, databases); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := ds.importDatabases(ctx, destination, databases); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := cleanDumpDirectory(); err != nil {
-		return err
+		return nil, err
+	}
+
+	// The settings of the roles are imported after the databases, as
+	// they can be specific to one of them
+	if err := cloneRoleSettings(ctx, destination, origin, importedRoles); err != nil {
+		return nil, err
 	}
 
 	if err := ds.analyze(ctx, destination, databases); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return importedRoles, nil
 }
@@ -56,43 +56,64 @@ type Role struct {
 	IsCurrentUser  bool    `json:"is_current_user,omitempty"`
 }
 
+// cloneRoles imports the roles from the origin, returning the names
+// of the ones that have been successfully created
 func cloneRoles(
 	ctx context.Context,
 	cluster *apiv1.Cluster,
 	destination *pool.ConnectionPool,
 	origin *pool.ConnectionPool,
-) error {
+) ([]string, error) {
 	rs := roleManager{origin: origin, destination: destination, cluster: cluster}
 	roles, err := rs.getRoles(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := rs.importRoles(ctx, roles); err != nil {
-		return err
+	return rs.importRoles(ctx, roles)
+}
+
+func (rs *roleManager) importRoles(ctx context.Context, roles []Role) ([]string, error) {
+	db, err := rs.destination.Connection(postgresDatabase)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return rs.createRoles(ctx, db, roles)
 }
 
-func (rs *roleManager) importRoles(ctx context.Context, roles []Role) error {
+// createRoles creates the passed roles in the destination database, returning
+// the names of the ones existing there after the import. The roles already
+// existing are not changed, but they are reported too
+func (rs *roleManager) createRoles(ctx context.Context, db *sql.DB, roles []Role) ([]string, error) {
 	contextLogger := log.FromContext(ctx)
 
-	db, err := rs.destination.Connection(postgresDatabase)
-	if err != nil {
-		return err
-	}
+	importedRoles := make([]string, 0, len(roles))
 	for _, role := range roles {
+		var exists bool
+		row := db.QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1)",
+			role.Rolname)
+		if err := row.Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists {
+			contextLogger.Info("role already exists, skipping its creation", "role", role.Rolname)
+			importedRoles = append(importedRoles, role.Rolname)
+			continue
+		}
+
 		query := rs.createSQLStatement(role)
-		contextLogger.Info("executing import role query", "query", query)
+		contextLogger.Info("executing import role query", "role", role.Rolname)
 		_, err := db.Exec(query)
 		if err != nil {
 			contextLogger.Error(err, "error while importing the role")
 
 			continue
 		}
+		importedRoles = append(importedRoles, role.Rolname)
 	}
-	return nil
+	return importedRoles, nil
 }
 
 func (rs *roleManager) createSQLStatement(role Role) string {
@@ -128,7 +149,8 @@ func (rs *roleManager) createSQLStatement(role Role) string {
 		query += fmt.Sprintf("VALID UNTIL %s ", pq.QuoteLiteral(*role.Rolvaliduntil))
 	}
 
-	if role.Rolpassword != nil {
+	if role.Rolpassword != nil &&
+		rs.cluster.Spec.Bootstrap.InitDB.Import.RolePasswords != apiv1.ImportRolePasswordsReset {
 		query += fmt.Sprintf("PASSWORD %s ", pq.QuoteLiteral(*role.Rolpassword))
 	}
 	query += fmt.Sprintf("CONNECTION LIMIT %d", role.Rolconnlimit)
@@ -0,0 +1,71 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logicalimport
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("roles import", func() {
+	const existsQuery = "SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1)"
+
+	rs := roleManager{
+		cluster: &apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				Bootstrap: &apiv1.BootstrapConfiguration{
+					InitDB: &apiv1.BootstrapInitDB{
+						Import: &apiv1.Import{Type: apiv1.MonolithSnapshotType},
+					},
+				},
+			},
+		},
+	}
+
+	It("reports the roles that already exist without creating them", func() {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			_ = db.Close()
+		}()
+
+		roles := []Role{{Rolname: "existing"}, {Rolname: "created"}, {Rolname: "failed"}}
+
+		mock.ExpectQuery(existsQuery).WithArgs("existing").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(existsQuery).WithArgs("created").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(rs.createSQLStatement(roles[1])).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(existsQuery).WithArgs("failed").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(rs.createSQLStatement(roles[2])).
+			WillReturnError(fmt.Errorf("permission denied"))
+
+		importedRoles, err := rs.createRoles(context.TODO(), db, roles)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(importedRoles).To(Equal([]string{"existing", "created"}))
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+})
@@ -0,0 +1,209 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logicalimport
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/pool"
+)
+
+// listQuotedSettings are the settings whose value is a list of
+// elements, each of them to be quoted separately
+var listQuotedSettings = []string{
+	"local_preload_libraries",
+	"search_path",
+	"session_preload_libraries",
+	"shared_preload_libraries",
+	"temp_tablespaces",
+	"unix_socket_directories",
+}
+
+type roleSettingManager struct {
+	origin      *pool.ConnectionPool
+	destination *pool.ConnectionPool
+}
+
+// RoleSetting contains a configuration parameter set for a role,
+// optionally restricted to a database, based on pg_db_role_setting
+type RoleSetting struct {
+	Role     string  `json:"role,omitempty"`
+	Database *string `json:"database,omitempty"`
+	Setting  string  `json:"setting,omitempty"`
+}
+
+// cloneRoleSettings imports the configuration parameters set for
+// the passed roles, i.e. with ALTER ROLE ... SET
+func cloneRoleSettings(
+	ctx context.Context,
+	destination *pool.ConnectionPool,
+	origin *pool.ConnectionPool,
+	roles []string,
+) error {
+	rs := roleSettingManager{
+		origin:      origin,
+		destination: destination,
+	}
+
+	settings, err := rs.getRoleSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	return rs.importRoleSettings(ctx, settings, roles)
+}
+
+func (rs *roleSettingManager) importRoleSettings(
+	ctx context.Context,
+	settings []RoleSetting,
+	roles []string,
+) error {
+	contextLogger := log.FromContext(ctx)
+	contextLogger.Info("importing role settings")
+
+	db, err := rs.destination.Connection(postgresDatabase)
+	if err != nil {
+		return err
+	}
+
+	for _, setting := range settings {
+		if !slices.Contains(roles, setting.Role) {
+			continue
+		}
+
+		query, err := setting.alterRoleStatement()
+		if err != nil {
+			contextLogger.Error(err, "while importing role setting")
+			continue
+		}
+
+		contextLogger.Info("executing role setting query", "query", query)
+		if _, err := db.Exec(query); err != nil {
+			contextLogger.Error(err, "while importing role setting")
+		}
+	}
+
+	return nil
+}
+
+func (rs *roleSettingManager) getRoleSettings(ctx context.Context) ([]RoleSetting, error) {
+	contextLogger := log.FromContext(ctx)
+	originDB, err := rs.origin.Connection(postgresDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve the settings of the roles excluding roles that are owned by the postgres catalog
+	// see FirstNormalObjectId in https://github.com/postgres/postgres/blob/662dbe2/src/include/access/transam.h#L197
+	query := "SELECT r.rolname, d.datname, unnest(s.setconfig) " +
+		"FROM pg_db_role_setting s " +
+		"JOIN pg_authid r ON r.oid = s.setrole " +
+		"LEFT JOIN pg_database d ON d.oid = s.setdatabase " +
+		"WHERE r.oid >= 16384 " +
+		"ORDER BY 1, 2"
+
+	rows, err := originDB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		closeErr := rows.Close()
+		if closeErr != nil {
+			contextLogger.Error(closeErr, "while closing rows: %w")
+		}
+	}()
+
+	var settings []RoleSetting
+	for rows.Next() {
+		var setting RoleSetting
+		if err := rows.Scan(
+			&setting.Role,
+			&setting.Database,
+			&setting.Setting,
+		); err != nil {
+			return nil, err
+		}
+
+		settings = append(settings, setting)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// alterRoleStatement builds the statement applying the setting to the role
+func (setting RoleSetting) alterRoleStatement() (string, error) {
+	name, value, found := strings.Cut(setting.Setting, "=")
+	if !found || name == "" {
+		return "", fmt.Errorf("invalid setting %q for role %s", setting.Setting, setting.Role)
+	}
+
+	query := fmt.Sprintf("ALTER ROLE %s ", pgx.Identifier{setting.Role}.Sanitize())
+	if setting.Database != nil {
+		query += fmt.Sprintf("IN DATABASE %s ", pgx.Identifier{*setting.Database}.Sanitize())
+	}
+
+	quotedValue := pq.QuoteLiteral(value)
+	if slices.Contains(listQuotedSettings, strings.ToLower(name)) {
+		elements := splitSettingList(value)
+		quotedElements := make([]string, len(elements))
+		for idx, element := range elements {
+			quotedElements[idx] = pq.QuoteLiteral(element)
+		}
+		quotedValue = strings.Join(quotedElements, ", ")
+	}
+
+	return query + fmt.Sprintf("SET %s TO %s", pgx.Identifier{name}.Sanitize(), quotedValue), nil
+}
+
+// splitSettingList splits the value of a list setting, as stored by
+// PostgreSQL, into its elements, removing the double quotes around them
+// and the spaces between them
+func splitSettingList(value string) []string {
+	var elements []string
+	var current strings.Builder
+	inQuotes := false
+
+	for idx := 0; idx < len(value); idx++ {
+		char := value[idx]
+		switch {
+		case char == '"' && inQuotes && idx+1 < len(value) && value[idx+1] == '"':
+			current.WriteByte('"')
+			idx++
+		case char == '"':
+			inQuotes = !inQuotes
+		case char == ',' && !inQuotes:
+			elements = append(elements, current.String())
+			current.Reset()
+		case char == ' ' && !inQuotes:
+			continue
+		default:
+			current.WriteByte(char)
+		}
+	}
+
+	return append(elements, current.String())
+}
@@ -0,0 +1,52 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logicalimport
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("role settings import", func() {
+	It("sets a parameter for a role", func() {
+		setting := RoleSetting{Role: "app", Setting: "statement_timeout=5min"}
+		Expect(setting.alterRoleStatement()).
+			To(Equal(`ALTER ROLE "app" SET "statement_timeout" TO '5min'`))
+	})
+
+	It("sets a parameter for a role in a database", func() {
+		database := "sales"
+		setting := RoleSetting{Role: "app", Database: &database, Setting: "work_mem=64MB"}
+		Expect(setting.alterRoleStatement()).
+			To(Equal(`ALTER ROLE "app" IN DATABASE "sales" SET "work_mem" TO '64MB'`))
+	})
+
+	It("quotes each element of a list parameter", func() {
+		setting := RoleSetting{Role: "app", Setting: `search_path="$user", public, "My Schema"`}
+		Expect(setting.alterRoleStatement()).
+			To(Equal(`ALTER ROLE "app" SET "search_path" TO '$user', 'public', 'My Schema'`))
+	})
+
+	It("rejects an invalid setting", func() {
+		_, err := RoleSetting{Role: "app", Setting: "invalid"}.alterRoleStatement()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("splits list parameters handling the escaped double quotes", func() {
+		Expect(splitSettingList(`a, "b ""c""", d`)).To(Equal([]string{"a", `b "c"`, "d"}))
+	})
+})
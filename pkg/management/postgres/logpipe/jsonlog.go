@@ -0,0 +1,105 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logpipe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonLogSeverityKey is a key that is always present in the records
+// produced by the jsonlog log destination
+const jsonLogSeverityKey = "error_severity"
+
+// FromJSONLog stores inside the record structure the relative fields
+// of a jsonlog record, using the same names of the CSV log format.
+//
+// See https://www.postgresql.org/docs/current/runtime-config-logging.html
+// section "20.8.5. Using JSON-Format Log Output".
+func (r *LoggingRecord) FromJSONLog(content map[string]interface{}) NamedRecord {
+	get := func(key string) string {
+		value, ok := content[key]
+		if !ok || value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	}
+
+	connectionFrom := get("remote_host")
+	if port := get("remote_port"); connectionFrom != "" && port != "" {
+		connectionFrom = fmt.Sprintf("%s:%s", connectionFrom, port)
+	}
+
+	location := get("func_name")
+	if fileName := get("file_name"); fileName != "" {
+		fileLocation := fmt.Sprintf("%s:%s", fileName, get("file_line_num"))
+		if location == "" {
+			location = fileLocation
+		} else {
+			location = fmt.Sprintf("%s, %s", location, fileLocation)
+		}
+	}
+
+	*r = LoggingRecord{
+		LogTime:              get("timestamp"),
+		Username:             get("user"),
+		DatabaseName:         get("dbname"),
+		ProcessID:            get("pid"),
+		ConnectionFrom:       connectionFrom,
+		SessionID:            get("session_id"),
+		SessionLineNum:       get("line_num"),
+		CommandTag:           get("ps"),
+		SessionStartTime:     get("session_start"),
+		VirtualTransactionID: get("vxid"),
+		TransactionID:        get("txid"),
+		ErrorSeverity:        get(jsonLogSeverityKey),
+		SQLStateCode:         get("state_code"),
+		Message:              get("message"),
+		Detail:               get("detail"),
+		Hint:                 get("hint"),
+		InternalQuery:        get("internal_query"),
+		InternalQueryPos:     get("internal_position"),
+		Context:              get("context"),
+		Query:                get("statement"),
+		QueryPos:             get("cursor_position"),
+		Location:             location,
+		ApplicationName:      get("application_name"),
+		BackendType:          get("backend_type"),
+		LeaderPid:            get("leader_pid"),
+		QueryID:              get("query_id"),
+	}
+	return r
+}
+
+// parseJSONLogLine decodes a line written by the jsonlog log destination,
+// returning nil if the line has not been produced by PostgreSQL
+func parseJSONLogLine(line []byte, record *PgAuditLoggingDecorator) NamedRecord {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	var content map[string]interface{}
+	if err := decoder.Decode(&content); err != nil {
+		return nil
+	}
+
+	if _, ok := content[jsonLogSeverityKey]; !ok {
+		return nil
+	}
+
+	return record.FromJSONLog(content)
+}
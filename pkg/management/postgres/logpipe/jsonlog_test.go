@@ -0,0 +1,77 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logpipe
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PostgreSQL jsonlog record", func() {
+	It("fills the fields using the CSV names", func() {
+		line := []byte(`{"timestamp":"2022-11-07 10:15:02.100 UTC","user":"postgres","dbname":"app",` +
+			`"pid":108,"remote_host":"10.0.0.1","remote_port":54000,"session_id":"6368da56.6c",` +
+			`"line_num":3,"ps":"idle","session_start":"2022-11-07 10:15:02 UTC","vxid":"3/4",` +
+			`"txid":0,"error_severity":"LOG","state_code":"00000","message":"statement: SELECT 1",` +
+			`"statement":"SELECT 1","func_name":"exec_simple_query","file_name":"postgres.c",` +
+			`"file_line_num":1092,"application_name":"psql","backend_type":"client backend",` +
+			`"query_id":-1285429181736357165}`)
+
+		record := parseJSONLogLine(line, NewPgAuditLoggingDecorator())
+		Expect(record).ToNot(BeNil())
+		Expect(record.GetName()).To(Equal(LoggingCollectorRecordName))
+		Expect(record).To(Equal(&LoggingRecord{
+			LogTime:              "2022-11-07 10:15:02.100 UTC",
+			Username:             "postgres",
+			DatabaseName:         "app",
+			ProcessID:            "108",
+			ConnectionFrom:       "10.0.0.1:54000",
+			SessionID:            "6368da56.6c",
+			SessionLineNum:       "3",
+			CommandTag:           "idle",
+			SessionStartTime:     "2022-11-07 10:15:02 UTC",
+			VirtualTransactionID: "3/4",
+			TransactionID:        "0",
+			ErrorSeverity:        "LOG",
+			SQLStateCode:         "00000",
+			Message:              "statement: SELECT 1",
+			Query:                "SELECT 1",
+			Location:             "exec_simple_query, postgres.c:1092",
+			ApplicationName:      "psql",
+			BackendType:          "client backend",
+			QueryID:              "-1285429181736357165",
+		}))
+	})
+
+	It("decodes pgAudit messages", func() {
+		line := []byte(`{"timestamp":"2022-11-07 10:15:02.100 UTC","error_severity":"LOG",` +
+			`"message":"AUDIT: SESSION,1,1,READ,SELECT,,,SELECT 1,<not logged>"}`)
+
+		record := parseJSONLogLine(line, NewPgAuditLoggingDecorator())
+		Expect(record.GetName()).To(Equal(PgAuditRecordName))
+		decorator := record.(*PgAuditLoggingDecorator)
+		Expect(decorator.Audit.Class).To(Equal("READ"))
+		Expect(decorator.Audit.Statement).To(Equal("SELECT 1"))
+		Expect(decorator.LoggingRecord.Message).To(BeEmpty())
+	})
+
+	It("ignores lines that have not been written by PostgreSQL", func() {
+		Expect(parseJSONLogLine([]byte(`{"level":"info","msg":"Archived WAL file"}`),
+			NewPgAuditLoggingDecorator())).To(BeNil())
+		Expect(parseJSONLogLine([]byte(`not a JSON line`), NewPgAuditLoggingDecorator())).To(BeNil())
+	})
+})
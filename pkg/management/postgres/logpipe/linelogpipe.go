@@ -58,11 +58,20 @@ func (p *LineLogPipe) GetExitedCondition() *concurrency.Executed {
 	return p.exited
 }
 
-// NewJSONLineLogPipe returns a logPipe for json format
+// NewJSONLineLogPipe returns a logPipe for json format. Records written by
+// the PostgreSQL jsonlog log destination are converted to the same format
+// used for the CSV ones, while every other line is passed through unchanged
 func NewJSONLineLogPipe(fileName string) *LineLogPipe {
+	record := NewPgAuditLoggingDecorator()
+	writer := &LogRecordWriter{}
+
 	return &LineLogPipe{
 		fileName: fileName,
 		handler: func(line []byte) {
+			if parsedRecord := parseJSONLogLine(line, record); parsedRecord != nil {
+				writer.Write(parsedRecord)
+				return
+			}
 			fmt.Println(string(line))
 		},
 		initialized: concurrency.NewExecuted(),
@@ -54,7 +54,19 @@ func getTagAndContent(record *LoggingRecord) (string, string) {
 // FromCSV implements the CSVRecordParser interface, parsing a LoggingRecord and then
 func (r *PgAuditLoggingDecorator) FromCSV(content []string) NamedRecord {
 	r.LoggingRecord.FromCSV(content)
+	return r.decodeAudit()
+}
+
+// FromJSONLog parses a LoggingRecord from a PostgreSQL jsonlog record and then
+// decodes the pgAudit message it may contain
+func (r *PgAuditLoggingDecorator) FromJSONLog(content map[string]interface{}) NamedRecord {
+	r.LoggingRecord.FromJSONLog(content)
+	return r.decodeAudit()
+}
 
+// decodeAudit checks whether the current LoggingRecord has been generated
+// by pgAudit, decoding its content if that's the case
+func (r *PgAuditLoggingDecorator) decodeAudit() NamedRecord {
 	tag, record := getTagAndContent(r.LoggingRecord)
 	if tag != "AUDIT" || record == "" {
 		return r.LoggingRecord
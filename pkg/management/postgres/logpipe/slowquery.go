@@ -0,0 +1,134 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logpipe
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// slowQueryRegex matches the messages logged by PostgreSQL for the statements
+// exceeding log_min_duration_statement, in the simple and extended query protocol.
+// In the extended query protocol, the parse and bind steps of a statement are
+// logged separately from its execution, and only the latter is matched, so
+// that every statement is counted once
+var slowQueryRegex = regexp.MustCompile(
+	`(?s)^duration: ([0-9]+(?:\.[0-9]+)?) ms\s+(?:statement|execute[^:]*): (.*)$`)
+
+// SlowQuery is a statement logged by PostgreSQL for exceeding
+// log_min_duration_statement
+type SlowQuery struct {
+	Duration     time.Duration
+	DatabaseName string
+	Username     string
+	Statement    string
+}
+
+// SlowQueriesSample contains the slow queries logged since the previous sample
+type SlowQueriesSample struct {
+	// Count is the number of slow queries logged since the previous sample
+	Count int64
+
+	// Slowest is the slowest of them, nil when Count is zero
+	Slowest *SlowQuery
+}
+
+// slowQueryTracker counts the slow queries logged by PostgreSQL, keeping
+// the slowest one since the last time a sample has been taken
+type slowQueryTracker struct {
+	mu     sync.Mutex
+	total  int64
+	sample SlowQueriesSample
+}
+
+// slowQueries tracks the slow queries flowing through the log pipes
+// of this instance manager
+var slowQueries = &slowQueryTracker{}
+
+// parseSlowQuery extracts the slow query contained in a log record,
+// returning nil if the record doesn't describe one
+func parseSlowQuery(record NamedRecord) *SlowQuery {
+	var loggingRecord *LoggingRecord
+	switch r := record.(type) {
+	case *LoggingRecord:
+		loggingRecord = r
+	case *PgAuditLoggingDecorator:
+		loggingRecord = r.LoggingRecord
+	default:
+		return nil
+	}
+
+	if loggingRecord == nil || loggingRecord.ErrorSeverity != "LOG" {
+		return nil
+	}
+
+	matches := slowQueryRegex.FindStringSubmatch(loggingRecord.Message)
+	if matches == nil {
+		return nil
+	}
+
+	milliseconds, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return nil
+	}
+
+	return &SlowQuery{
+		Duration:     time.Duration(milliseconds * float64(time.Millisecond)),
+		DatabaseName: loggingRecord.DatabaseName,
+		Username:     loggingRecord.Username,
+		Statement:    matches[2],
+	}
+}
+
+// observe accounts the passed log record if it describes a slow query
+func (t *slowQueryTracker) observe(record NamedRecord) {
+	query := parseSlowQuery(record)
+	if query == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.total++
+	t.sample.Count++
+	if t.sample.Slowest == nil || query.Duration > t.sample.Slowest.Duration {
+		t.sample.Slowest = query
+	}
+}
+
+// GetSlowQueriesTotal returns the number of slow queries logged by
+// PostgreSQL since the instance manager started
+func GetSlowQueriesTotal() int64 {
+	slowQueries.mu.Lock()
+	defer slowQueries.mu.Unlock()
+
+	return slowQueries.total
+}
+
+// TakeSlowQueriesSample returns the slow queries logged by PostgreSQL
+// since the previous call, starting a new sample
+func TakeSlowQueriesSample() SlowQueriesSample {
+	slowQueries.mu.Lock()
+	defer slowQueries.mu.Unlock()
+
+	sample := slowQueries.sample
+	slowQueries.sample = SlowQueriesSample{}
+	return sample
+}
@@ -0,0 +1,93 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logpipe
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("slow queries", func() {
+	slowQueryRecord := func(message string, database string) *LoggingRecord {
+		return &LoggingRecord{
+			ErrorSeverity: "LOG",
+			DatabaseName:  database,
+			Username:      "app",
+			Message:       message,
+		}
+	}
+
+	It("parses the statements of the simple query protocol", func() {
+		query := parseSlowQuery(slowQueryRecord("duration: 1523.125 ms  statement: SELECT pg_sleep(1.5)", "app"))
+		Expect(query).To(Equal(&SlowQuery{
+			Duration:     1523125 * time.Microsecond,
+			DatabaseName: "app",
+			Username:     "app",
+			Statement:    "SELECT pg_sleep(1.5)",
+		}))
+	})
+
+	It("parses the statements of the extended query protocol", func() {
+		query := parseSlowQuery(slowQueryRecord("duration: 250.5 ms  execute <unnamed>: SELECT *\nFROM orders", "app"))
+		Expect(query).ToNot(BeNil())
+		Expect(query.Duration).To(Equal(250500 * time.Microsecond))
+		Expect(query.Statement).To(Equal("SELECT *\nFROM orders"))
+	})
+
+	It("counts only the execution of the statements of the extended query protocol", func() {
+		Expect(parseSlowQuery(slowQueryRecord("duration: 120.5 ms  parse <unnamed>: SELECT 1", "app"))).To(BeNil())
+		Expect(parseSlowQuery(slowQueryRecord("duration: 110.2 ms  bind <unnamed>: SELECT 1", "app"))).To(BeNil())
+		Expect(parseSlowQuery(slowQueryRecord("duration: 130.7 ms  execute <unnamed>: SELECT 1", "app"))).
+			ToNot(BeNil())
+	})
+
+	It("parses the slow queries wrapped by the pgAudit decorator", func() {
+		record := NewPgAuditLoggingDecorator()
+		record.LoggingRecord = slowQueryRecord("duration: 10 ms  statement: VACUUM", "app")
+		Expect(parseSlowQuery(record)).ToNot(BeNil())
+	})
+
+	It("ignores the other log records", func() {
+		Expect(parseSlowQuery(slowQueryRecord("checkpoint starting: time", ""))).To(BeNil())
+		Expect(parseSlowQuery(slowQueryRecord("duration: 10.000 ms", "app"))).To(BeNil())
+		Expect(parseSlowQuery(&LoggingRecord{
+			ErrorSeverity: "ERROR",
+			Message:       "duration: 10 ms  statement: SELECT 1",
+		})).To(BeNil())
+	})
+
+	It("counts the slow queries and samples the slowest one", func() {
+		total := GetSlowQueriesTotal()
+		TakeSlowQueriesSample()
+
+		writer := &LogRecordWriter{}
+		writer.Write(slowQueryRecord("duration: 1000 ms  statement: SELECT 1", "app"))
+		writer.Write(slowQueryRecord("duration: 3000 ms  statement: SELECT 3", "app"))
+		writer.Write(slowQueryRecord("duration: 2000 ms  statement: SELECT 2", "app"))
+		writer.Write(slowQueryRecord("checkpoint complete", ""))
+
+		Expect(GetSlowQueriesTotal()).To(Equal(total + 3))
+		sample := TakeSlowQueriesSample()
+		Expect(sample.Count).To(BeEquivalentTo(3))
+		Expect(sample.Slowest.Statement).To(Equal("SELECT 3"))
+
+		Expect(TakeSlowQueriesSample()).To(Equal(SlowQueriesSample{}))
+		Expect(GetSlowQueriesTotal()).To(Equal(total + 3))
+	})
+})
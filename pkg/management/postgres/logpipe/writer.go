@@ -33,7 +33,9 @@ type RecordWriter interface {
 // instance manager logger
 type LogRecordWriter struct{}
 
-// Write writes the PostgreSQL log record to the instance manager logger
+// Write writes the PostgreSQL log record to the instance manager logger,
+// accounting it when it describes a slow query
 func (writer *LogRecordWriter) Write(record NamedRecord) {
+	slowQueries.observe(record)
 	log.WithName(record.GetName()).Info(logRecordKey, logRecordKey, record)
 }
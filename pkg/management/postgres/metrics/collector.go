@@ -45,6 +45,10 @@ type QueriesCollector struct {
 	mappings       map[string]MetricMapSet
 	variableLabels map[string]VariableSet
 
+	// invalidQueries contains the validation errors of the user
+	// queries which have been discarded while parsing
+	invalidQueries map[string]error
+
 	errorUserQueries      *prometheus.CounterVec
 	errorUserQueriesGauge prometheus.Gauge
 }
@@ -61,6 +65,12 @@ func (q QueriesCollector) Collect(ch chan<- prometheus.Metric) error {
 	// Reset before collecting
 	q.errorUserQueries.Reset()
 
+	// Invalid queries are never executed, but we keep reporting them
+	// as errors to let the user know that something needs fixing
+	for name, err := range q.invalidQueries {
+		q.reportUserQueryErrorMetric(name + ": " + err.Error())
+	}
+
 	err := q.collectUserQueries(ch)
 	if err != nil {
 		return err
@@ -256,6 +266,7 @@ func NewQueriesCollector(
 		instance:       instance,
 		mappings:       make(map[string]MetricMapSet),
 		variableLabels: make(map[string]VariableSet),
+		invalidQueries: make(map[string]error),
 		userQueries:    make(UserQueries),
 		defaultDBName:  defaultDBName,
 		errorUserQueries: prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -272,7 +283,8 @@ func NewQueriesCollector(
 }
 
 // ParseQueries parses a YAML file containing custom queries and add it
-// to the set of gathered one
+// to the set of gathered one. Queries not passing the validation are
+// discarded and reported in the errors metric at every collection
 func (q *QueriesCollector) ParseQueries(customQueries []byte) error {
 	var err error
 
@@ -281,12 +293,22 @@ func (q *QueriesCollector) ParseQueries(customQueries []byte) error {
 		return err
 	}
 	for name, query := range parsedQueries {
+		if err := ValidateQueryName(name); err != nil {
+			q.discardInvalidQuery(name, err)
+			continue
+		}
+		if err := query.Validate(); err != nil {
+			q.discardInvalidQuery(name, err)
+			continue
+		}
+
 		if _, found := q.userQueries[name]; found {
 			log.Warning("Query with the same name already found. Overwriting the existing one.",
 				"queryName",
 				name)
 		}
 
+		delete(q.invalidQueries, name)
 		q.userQueries[name] = query
 		q.mappings[name], q.variableLabels[name] = query.ToMetricMap(
 			fmt.Sprintf("%v_%v", q.collectorName, name))
@@ -295,6 +317,14 @@ func (q *QueriesCollector) ParseQueries(customQueries []byte) error {
 	return nil
 }
 
+// discardInvalidQuery records a query which didn't pass the validation
+func (q *QueriesCollector) discardInvalidQuery(name string, err error) {
+	log.Warning("Discarding invalid user query",
+		"queryName", name,
+		"error", err.Error())
+	q.invalidQueries[name] = err
+}
+
 // InjectUserQueries injects the passed queries
 func (q *QueriesCollector) InjectUserQueries(defaultQueries UserQueries) {
 	if q == nil {
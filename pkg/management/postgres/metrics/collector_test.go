@@ -62,6 +62,48 @@ var _ = Describe("Set default queries", func() {
 	})
 })
 
+var _ = Describe("Parse user queries", func() {
+	It("discards the invalid queries and keeps the valid ones", func() {
+		q := NewQueriesCollector("test", nil, "db")
+		err := q.ParseQueries([]byte(`
+valid:
+  query: "SELECT 1 AS rows"
+  metrics:
+  - rows:
+      usage: "GAUGE"
+invalid:
+  query: "SELECT 1 AS rows"
+  metrics:
+  - rows:
+      usage: "GAUGES"
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(q.userQueries).To(HaveKey("valid"))
+		Expect(q.userQueries).ToNot(HaveKey("invalid"))
+		Expect(q.invalidQueries).To(HaveKey("invalid"))
+		Expect(q.invalidQueries).ToNot(HaveKey("valid"))
+	})
+
+	It("forgets an invalid query when it is redefined correctly", func() {
+		q := NewQueriesCollector("test", nil, "db")
+		Expect(q.ParseQueries([]byte(`
+query:
+  query: ""
+`))).To(Succeed())
+		Expect(q.invalidQueries).To(HaveKey("query"))
+
+		Expect(q.ParseQueries([]byte(`
+query:
+  query: "SELECT 1 AS rows"
+  metrics:
+  - rows:
+      usage: "GAUGE"
+`))).To(Succeed())
+		Expect(q.invalidQueries).To(BeEmpty())
+		Expect(q.userQueries).To(HaveKey("query"))
+	})
+})
+
 var _ = Describe("QueryCollector tests", func() {
 	Context("collect metric tests", func() {
 		It("should ensure that a metric without conversion is discarded", func() {
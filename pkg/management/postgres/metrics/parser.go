@@ -18,8 +18,11 @@ limitations under the License.
 package metrics
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 
+	"github.com/blang/semver"
 	"gopkg.in/yaml.v3"
 )
 
@@ -78,6 +81,89 @@ const (
 	HISTOGRAM ColumnUsage = "HISTOGRAM"
 )
 
+// metricNameRegex matches the names which can be used for queries and columns,
+// as they will be part of the generated Prometheus metric and label names
+var metricNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// isKnownUsage checks if the passed usage is one of the supported ones
+func isKnownUsage(usage ColumnUsage) bool {
+	switch usage {
+	case DISCARD, LABEL, COUNTER, GAUGE, MAPPEDMETRIC, DURATION, HISTOGRAM:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks if the user query is correctly defined, returning
+// an error describing the first problem found
+func (userQuery UserQuery) Validate() error {
+	if userQuery.Query == "" {
+		return errors.New("missing query")
+	}
+
+	if len(userQuery.Metrics) == 0 {
+		return errors.New("no metrics defined")
+	}
+
+	if userQuery.RunOnServer != "" {
+		if _, err := semver.ParseRange(userQuery.RunOnServer); err != nil {
+			return fmt.Errorf("invalid runonserver version range %q: %w", userQuery.RunOnServer, err)
+		}
+	}
+
+	columnNames := make(map[string]bool)
+	for _, mapping := range userQuery.Metrics {
+		if len(mapping) == 0 {
+			return errors.New("empty metric definition")
+		}
+
+		for columnName, columnMapping := range mapping {
+			if columnNames[columnName] {
+				return fmt.Errorf("duplicate column %q", columnName)
+			}
+			columnNames[columnName] = true
+
+			if err := columnMapping.validate(columnName); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks if the column mapping is correctly defined
+func (columnMapping ColumnMapping) validate(columnName string) error {
+	if !metricNameRegex.MatchString(columnName) {
+		return fmt.Errorf("invalid column name %q", columnName)
+	}
+
+	if !isKnownUsage(columnMapping.Usage) {
+		return fmt.Errorf("column %q: unknown usage %q", columnName, columnMapping.Usage)
+	}
+
+	if columnMapping.Usage == MAPPEDMETRIC && len(columnMapping.Mapping) == 0 {
+		return fmt.Errorf("column %q: metric_mapping is required with usage %v", columnName, MAPPEDMETRIC)
+	}
+
+	if columnMapping.Usage != MAPPEDMETRIC && len(columnMapping.Mapping) != 0 {
+		return fmt.Errorf("column %q: metric_mapping is allowed only with usage %v", columnName, MAPPEDMETRIC)
+	}
+
+	return nil
+}
+
+// ValidateQueryName checks if the passed name can be used for
+// a user query, as it will be part of the generated metric names
+func ValidateQueryName(name string) error {
+	if !metricNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid query name %q", name)
+	}
+
+	return nil
+}
+
 // ParseQueries parse a YAML file containing custom queries
 func ParseQueries(content []byte) (UserQueries, error) {
 	var result UserQueries
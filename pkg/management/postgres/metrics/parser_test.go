@@ -86,6 +86,88 @@ test:
 		Expect(err).ToNot(BeNil())
 		Expect(result).To(BeNil())
 	})
+
+	It("accepts the postgres_exporter example queries as valid", func() {
+		result, err := ParseQueries([]byte(pgExporterQueries))
+		Expect(err).ToNot(HaveOccurred())
+		for name, query := range result {
+			Expect(ValidateQueryName(name)).To(Succeed())
+			Expect(query.Validate()).To(Succeed())
+		}
+	})
+
+	It("rejects invalid query names", func() {
+		Expect(ValidateQueryName("some_query")).To(Succeed())
+		Expect(ValidateQueryName("some-query")).ToNot(Succeed())
+		Expect(ValidateQueryName("1query")).ToNot(Succeed())
+		Expect(ValidateQueryName("")).ToNot(Succeed())
+	})
+
+	DescribeTable("rejects invalid query definitions",
+		func(content string, expectedError string) {
+			result, err := ParseQueries([]byte(content))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(HaveKey("test"))
+			Expect(result["test"].Validate()).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("missing query", `
+test:
+  metrics:
+  - rows:
+      usage: "GAUGE"
+`, "missing query"),
+		Entry("missing metrics", `
+test:
+  query: "SELECT 1"
+`, "no metrics defined"),
+		Entry("invalid runonserver", `
+test:
+  query: "SELECT 1 AS rows"
+  runonserver: "not a range"
+  metrics:
+  - rows:
+      usage: "GAUGE"
+`, "invalid runonserver"),
+		Entry("invalid column name", `
+test:
+  query: "SELECT 1 AS \"my-rows\""
+  metrics:
+  - my-rows:
+      usage: "GAUGE"
+`, "invalid column name"),
+		Entry("duplicate column", `
+test:
+  query: "SELECT 1 AS rows"
+  metrics:
+  - rows:
+      usage: "GAUGE"
+  - rows:
+      usage: "COUNTER"
+`, "duplicate column"),
+		Entry("unknown usage", `
+test:
+  query: "SELECT 1 AS rows"
+  metrics:
+  - rows:
+      usage: "GAUGES"
+`, "unknown usage"),
+		Entry("mapped metric without mapping", `
+test:
+  query: "SELECT 'on' AS status"
+  metrics:
+  - status:
+      usage: "MAPPEDMETRIC"
+`, "metric_mapping is required"),
+		Entry("mapping without mapped metric", `
+test:
+  query: "SELECT 'on' AS status"
+  metrics:
+  - status:
+      usage: "GAUGE"
+      metric_mapping:
+        "on": 1
+`, "metric_mapping is allowed only"),
+	)
 })
 
 const pgExporterQueries = `
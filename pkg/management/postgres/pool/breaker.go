@@ -0,0 +1,185 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the circuit breaker of a connection pool
+type BreakerState int
+
+const (
+	// BreakerClosed means the database is reachable and connections are
+	// handed out normally
+	BreakerClosed BreakerState = iota
+
+	// BreakerHalfOpen means the database was unreachable and a single
+	// request is probing it for recovery, while the others fail immediately
+	BreakerHalfOpen
+
+	// BreakerOpen means the database is unreachable and the requests
+	// for a connection fail immediately
+	BreakerOpen
+)
+
+const (
+	// defaultFailureThreshold is the number of consecutive failed health
+	// probes after which the circuit breaker opens
+	defaultFailureThreshold = 3
+
+	// defaultOpenTimeout is the time the circuit breaker stays open before
+	// allowing a new health probe
+	defaultOpenTimeout = 5 * time.Second
+
+	// defaultHealthCheckInterval is the maximum age of the last successful
+	// health probe after which the connection is probed again
+	defaultHealthCheckInterval = 10 * time.Second
+
+	// defaultProbeTimeout is the timeout of a single health probe
+	defaultProbeTimeout = 3 * time.Second
+)
+
+// ErrCircuitOpen is raised when a connection is requested while the
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// String implements the Stringer interface
+func (state BreakerState) String() string {
+	switch state {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuitBreaker keeps track of the health probes of a connection pool
+// and short-circuits the requests after repeated failures
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	failureThreshold    int
+	openTimeout         time.Duration
+	healthCheckInterval time.Duration
+
+	state           BreakerState
+	failures        int
+	openedAt        time.Time
+	lastHealthCheck time.Time
+	lastError       error
+
+	// probeStartedAt is when the recovery probe of the half-open
+	// breaker has been allowed, zero when no probe is running
+	probeStartedAt time.Time
+
+	// now is used to get the current time, and can be replaced in tests
+	now func() time.Time
+}
+
+// newCircuitBreaker creates a closed circuit breaker with the default settings
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		failureThreshold:    defaultFailureThreshold,
+		openTimeout:         defaultOpenTimeout,
+		healthCheckInterval: defaultHealthCheckInterval,
+		state:               BreakerClosed,
+		now:                 time.Now,
+	}
+}
+
+// allow returns an error if the requests should be short-circuited,
+// moving an expired open breaker to the half-open state. Only one request
+// at a time is allowed to probe a half-open breaker, unless the probe
+// doesn't complete within its timeout
+func (cb *circuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+	switch cb.state {
+	case BreakerClosed:
+		return nil
+
+	case BreakerOpen:
+		if now.Sub(cb.openedAt) < cb.openTimeout {
+			return fmt.Errorf("%w: %v", ErrCircuitOpen, cb.lastError)
+		}
+		cb.state = BreakerHalfOpen
+
+	case BreakerHalfOpen:
+		if !cb.probeStartedAt.IsZero() && now.Sub(cb.probeStartedAt) < defaultProbeTimeout {
+			return fmt.Errorf("%w: waiting for the recovery probe: %v", ErrCircuitOpen, cb.lastError)
+		}
+	}
+
+	cb.probeStartedAt = now
+	return nil
+}
+
+// needsProbe returns true if the health of the connection should be probed
+// before being handed out
+func (cb *circuitBreaker) needsProbe() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.state != BreakerClosed ||
+		cb.failures > 0 ||
+		cb.now().Sub(cb.lastHealthCheck) >= cb.healthCheckInterval
+}
+
+// recordSuccess closes the breaker after a successful probe
+func (cb *circuitBreaker) recordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.state = BreakerClosed
+	cb.failures = 0
+	cb.lastError = nil
+	cb.lastHealthCheck = cb.now()
+	cb.probeStartedAt = time.Time{}
+}
+
+// recordFailure registers a failed probe, opening the breaker when
+// the threshold is reached or when the recovery probe failed
+func (cb *circuitBreaker) recordFailure(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.lastError = err
+	cb.probeStartedAt = time.Time{}
+	if cb.state == BreakerHalfOpen || cb.failures >= cb.failureThreshold {
+		cb.state = BreakerOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// getState returns the current state of the breaker
+func (cb *circuitBreaker) getState() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.state
+}
@@ -0,0 +1,138 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Circuit breaker", func() {
+	var (
+		breaker     *circuitBreaker
+		currentTime time.Time
+		errProbe    = errors.New("connection refused")
+	)
+
+	BeforeEach(func() {
+		currentTime = time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+		breaker = newCircuitBreaker()
+		breaker.now = func() time.Time { return currentTime }
+	})
+
+	It("is initially closed and requires a probe", func() {
+		Expect(breaker.getState()).To(Equal(BreakerClosed))
+		Expect(breaker.allow()).To(Succeed())
+		Expect(breaker.needsProbe()).To(BeTrue())
+	})
+
+	It("doesn't probe again until the health check interval is elapsed", func() {
+		breaker.recordSuccess()
+		Expect(breaker.needsProbe()).To(BeFalse())
+
+		currentTime = currentTime.Add(defaultHealthCheckInterval)
+		Expect(breaker.needsProbe()).To(BeTrue())
+	})
+
+	It("opens after repeated failures and fails fast", func() {
+		for i := 0; i < defaultFailureThreshold-1; i++ {
+			breaker.recordFailure(errProbe)
+			Expect(breaker.getState()).To(Equal(BreakerClosed))
+			Expect(breaker.needsProbe()).To(BeTrue())
+		}
+
+		breaker.recordFailure(errProbe)
+		Expect(breaker.getState()).To(Equal(BreakerOpen))
+
+		err := breaker.allow()
+		Expect(err).To(MatchError(ErrCircuitOpen))
+		Expect(err.Error()).To(ContainSubstring("connection refused"))
+	})
+
+	It("allows a recovery probe after the open timeout", func() {
+		for i := 0; i < defaultFailureThreshold; i++ {
+			breaker.recordFailure(errProbe)
+		}
+
+		currentTime = currentTime.Add(defaultOpenTimeout)
+		Expect(breaker.allow()).To(Succeed())
+		Expect(breaker.getState()).To(Equal(BreakerHalfOpen))
+		Expect(breaker.needsProbe()).To(BeTrue())
+
+		breaker.recordSuccess()
+		Expect(breaker.getState()).To(Equal(BreakerClosed))
+	})
+
+	It("allows a single recovery probe at a time", func() {
+		for i := 0; i < defaultFailureThreshold; i++ {
+			breaker.recordFailure(errProbe)
+		}
+
+		currentTime = currentTime.Add(defaultOpenTimeout)
+		Expect(breaker.allow()).To(Succeed())
+		Expect(breaker.allow()).To(MatchError(ErrCircuitOpen))
+
+		By("allowing another probe when the running one times out", func() {
+			currentTime = currentTime.Add(defaultProbeTimeout)
+			Expect(breaker.allow()).To(Succeed())
+			Expect(breaker.allow()).To(MatchError(ErrCircuitOpen))
+		})
+
+		By("letting every request through once the probe succeeded", func() {
+			breaker.recordSuccess()
+			Expect(breaker.allow()).To(Succeed())
+			Expect(breaker.allow()).To(Succeed())
+		})
+	})
+
+	It("opens again if the recovery probe fails", func() {
+		for i := 0; i < defaultFailureThreshold; i++ {
+			breaker.recordFailure(errProbe)
+		}
+
+		currentTime = currentTime.Add(defaultOpenTimeout)
+		Expect(breaker.allow()).To(Succeed())
+
+		breaker.recordFailure(errProbe)
+		Expect(breaker.getState()).To(Equal(BreakerOpen))
+		Expect(breaker.allow()).To(MatchError(ErrCircuitOpen))
+	})
+})
+
+var _ = Describe("Connection pool with circuit breaker", func() {
+	It("reports a closed breaker when it is disabled", func() {
+		pool := NewConnectionPool("host=127.0.0.1")
+		Expect(pool.CircuitBreakerState()).To(Equal(BreakerClosed))
+	})
+
+	It("short-circuits the requests after repeated failures", func() {
+		// Nothing is listening on this port, so the probe fails
+		pool := NewConnectionPool("host=127.0.0.1 port=1 connect_timeout=1").WithCircuitBreaker()
+		for i := 0; i < defaultFailureThreshold; i++ {
+			_, err := pool.Connection("test")
+			Expect(err).To(HaveOccurred())
+			Expect(err).ToNot(MatchError(ErrCircuitOpen))
+		}
+
+		Expect(pool.CircuitBreakerState()).To(Equal(BreakerOpen))
+		_, err := pool.Connection("test")
+		Expect(err).To(MatchError(ErrCircuitOpen))
+	})
+})
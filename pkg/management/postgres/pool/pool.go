@@ -19,8 +19,10 @@ limitations under the License.
 package pool
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 
@@ -28,14 +30,24 @@ import (
 	_ "github.com/jackc/pgx/v4/stdlib"
 )
 
+// defaultMaxOpenConns is the maximum number of open connections
+// to every database, unless the pool is warmed up
+const defaultMaxOpenConns = 2
+
 // ConnectionPool is a repository of DB connections, pointing to the same instance
 // given a base DSN without the "dbname" parameter
 type ConnectionPool struct {
 	// This is the base connection string (without the "dbname" parameter)
 	baseConnectionString string
 
-	// A map of connection for every used database
-	connectionMap map[string]*sql.DB
+	// A map of connection for every used database, protected by
+	// connectionMapMutex as the pool may be used concurrently
+	connectionMap      map[string]*sql.DB
+	connectionMapMutex sync.Mutex
+
+	// The circuit breaker protecting the callers from an unreachable
+	// database, nil if disabled
+	breaker *circuitBreaker
 }
 
 // NewConnectionPool creates a new connectionMap of connections given
@@ -47,10 +59,55 @@ func NewConnectionPool(baseConnectionString string) *ConnectionPool {
 	}
 }
 
+// WithCircuitBreaker enables the health probe of the connections handed out
+// by this pool. After repeated failures, the requests for a connection
+// fail immediately for a while, before probing the database again
+func (pool *ConnectionPool) WithCircuitBreaker() *ConnectionPool {
+	pool.breaker = newCircuitBreaker()
+	return pool
+}
+
+// CircuitBreakerState returns the state of the circuit breaker of this
+// pool, which is always closed when the circuit breaker is disabled
+func (pool *ConnectionPool) CircuitBreakerState() BreakerState {
+	if pool.breaker == nil {
+		return BreakerClosed
+	}
+
+	return pool.breaker.getState()
+}
+
 // Connection gets the connection for the given database
 func (pool *ConnectionPool) Connection(dbname string) (*sql.DB, error) {
-	if result, ok := pool.connectionMap[dbname]; ok {
-		return result, nil
+	if pool.breaker != nil {
+		if err := pool.breaker.allow(); err != nil {
+			return nil, err
+		}
+	}
+
+	connection, err := pool.getOrCreateConnection(dbname)
+	if err != nil {
+		return nil, err
+	}
+
+	if pool.breaker != nil && pool.breaker.needsProbe() {
+		if err := pool.probe(connection); err != nil {
+			return nil, err
+		}
+	}
+
+	return connection, nil
+}
+
+// getOrCreateConnection gets the connection for the given database,
+// creating it if needed
+func (pool *ConnectionPool) getOrCreateConnection(dbname string) (*sql.DB, error) {
+	pool.connectionMapMutex.Lock()
+	defer pool.connectionMapMutex.Unlock()
+
+	connection, ok := pool.connectionMap[dbname]
+	if ok {
+		return connection, nil
 	}
 
 	connection, err := pool.newConnection(dbname)
@@ -62,8 +119,91 @@ func (pool *ConnectionPool) Connection(dbname string) (*sql.DB, error) {
 	return connection, nil
 }
 
+// ResetConnection closes the connection for the given database, if any,
+// so that the next request opens a new one. This is useful when the
+// connection doesn't point anymore to the expected server
+func (pool *ConnectionPool) ResetConnection(dbname string) {
+	pool.connectionMapMutex.Lock()
+	defer pool.connectionMapMutex.Unlock()
+
+	if connection, ok := pool.connectionMap[dbname]; ok {
+		_ = connection.Close()
+		delete(pool.connectionMap, dbname)
+	}
+}
+
+// probe checks if the database is reachable, updating the circuit breaker
+func (pool *ConnectionPool) probe(connection *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultProbeTimeout)
+	defer cancel()
+
+	if err := connection.PingContext(ctx); err != nil {
+		pool.breaker.recordFailure(err)
+		return fmt.Errorf("while checking the connection health: %w", err)
+	}
+
+	pool.breaker.recordSuccess()
+	return nil
+}
+
+// WarmUp opens the passed number of connections to the given database,
+// keeping them idle in the pool, so that the callers don't pay the
+// connection setup latency
+func (pool *ConnectionPool) WarmUp(ctx context.Context, dbname string, connections int) error {
+	db, err := pool.Connection(dbname)
+	if err != nil {
+		return err
+	}
+
+	ensureMaxOpenConns(db, connections)
+	db.SetMaxIdleConns(connections)
+
+	// The connections are given back to the pool, as idle ones, only
+	// after all of them have been opened
+	openedConnections := make([]*sql.Conn, 0, connections)
+	defer func() {
+		for _, conn := range openedConnections {
+			_ = conn.Close()
+		}
+	}()
+
+	for i := 0; i < connections; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("while opening connection %d of %d: %w", i+1, connections, err)
+		}
+		openedConnections = append(openedConnections, conn)
+	}
+
+	return nil
+}
+
+// EnsureMaxOpenConns raises the maximum number of open connections to
+// the given database to the passed one, so that as many callers can use
+// the database at the same time. The limit is never lowered
+func (pool *ConnectionPool) EnsureMaxOpenConns(dbname string, connections int) error {
+	db, err := pool.Connection(dbname)
+	if err != nil {
+		return err
+	}
+
+	ensureMaxOpenConns(db, connections)
+	return nil
+}
+
+// ensureMaxOpenConns raises the maximum number of open connections of
+// the passed database handle, if lower than the requested one
+func ensureMaxOpenConns(db *sql.DB, connections int) {
+	if connections > db.Stats().MaxOpenConnections {
+		db.SetMaxOpenConns(connections)
+	}
+}
+
 // ShutdownConnections closes every database connection
 func (pool *ConnectionPool) ShutdownConnections() {
+	pool.connectionMapMutex.Lock()
+	defer pool.connectionMapMutex.Unlock()
+
 	for _, db := range pool.connectionMap {
 		_ = db.Close()
 	}
@@ -80,7 +220,7 @@ func (pool *ConnectionPool) newConnection(dbname string) (*sql.DB, error) {
 		return nil, fmt.Errorf("cannot create connection connectionMap: %w", err)
 	}
 
-	db.SetMaxOpenConns(2)
+	db.SetMaxOpenConns(defaultMaxOpenConns)
 	db.SetMaxIdleConns(0)
 
 	return db, nil
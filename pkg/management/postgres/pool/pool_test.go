@@ -17,6 +17,8 @@ limitations under the License.
 package pool
 
 import (
+	"context"
+
 	_ "github.com/lib/pq"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -44,6 +46,47 @@ var _ = Describe("Connection pool test", func() {
 		Expect(len(pool.connectionMap)).To(Equal(1))
 	})
 
+	It("reports the failure to warm up the connections", func() {
+		pool := NewConnectionPool("host=/nonexistent connect_timeout=1")
+		err := pool.WarmUp(context.Background(), "test", 3)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("connection 1 of 3"))
+
+		db, err := pool.Connection("test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(db.Stats().MaxOpenConnections).To(Equal(3))
+	})
+
+	It("raises the maximum number of open connections without lowering it", func() {
+		pool := NewConnectionPool("host=127.0.0.1")
+		Expect(pool.EnsureMaxOpenConns("test", 8)).To(Succeed())
+
+		db, err := pool.Connection("test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(db.Stats().MaxOpenConnections).To(Equal(8))
+
+		Expect(pool.EnsureMaxOpenConns("test", 1)).To(Succeed())
+		Expect(db.Stats().MaxOpenConnections).To(Equal(8))
+	})
+
+	It("replaces a connection after it has been reset", func() {
+		pool := NewConnectionPool("host=127.0.0.1")
+		first, err := pool.Connection("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		pool.ResetConnection("test")
+		Expect(pool.connectionMap).To(BeEmpty())
+
+		second, err := pool.Connection("test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second).ToNot(BeIdenticalTo(first))
+		Expect(first.Ping()).To(MatchError(ContainSubstring("closed")))
+
+		// Resetting a database never used is harmless
+		pool.ResetConnection("other")
+		Expect(pool.connectionMap).To(HaveLen(1))
+	})
+
 	It("shut down connections on request", func() {
 		pool := NewConnectionPool("host=127.0.0.1")
 		Expect(pool.Connection("test")).ToNot(BeNil())
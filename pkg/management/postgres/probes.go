@@ -17,24 +17,33 @@ limitations under the License.
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/strings/slices"
 
 	v1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/executablehash"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/barman/archiver"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/specs"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/versions"
 )
 
+// readinessProbeQueryTimeout is the maximum time the query of the
+// readiness probe is allowed to run
+const readinessProbeQueryTimeout = 5 * time.Second
+
 // IsServerHealthy check if the instance is healthy
 func (instance *Instance) IsServerHealthy() error {
 	err := instance.PgIsReady()
@@ -54,12 +63,48 @@ func (instance *Instance) IsServerReady() error {
 	if !instance.CanCheckReadiness() {
 		return fmt.Errorf("instance is not ready yet")
 	}
-	superUserDB, err := instance.GetSuperUserDB()
+
+	return instance.CheckReadiness(context.Background(), instance.probesConfiguration.Load())
+}
+
+// CheckReadiness connects to the database of the passed readiness probe
+// configuration and runs its query, if any
+func (instance *Instance) CheckReadiness(ctx context.Context, config *v1.ProbesConfiguration) error {
+	db, err := instance.ConnectionPool().Connection(config.GetDatabase())
+	if err != nil {
+		return err
+	}
+
+	query := config.GetQuery()
+	if query == "" {
+		return db.PingContext(ctx)
+	}
+
+	return runReadinessQuery(ctx, db, query)
+}
+
+// runReadinessQuery runs the query of the readiness probe inside a read-only
+// transaction, which is always rolled back, so that the probe can't change
+// the data. The statement timeout stops the query on the server side too
+func runReadinessQuery(ctx context.Context, db *sql.DB, query string) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessProbeQueryTimeout)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return err
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	return superUserDB.Ping()
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"SET LOCAL statement_timeout TO %d", readinessProbeQueryTimeout.Milliseconds())); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query)
+	return err
 }
 
 // GetStatus Extract the status of this PostgreSQL database
@@ -106,13 +151,34 @@ func (instance *Instance) GetStatus() (result *postgres.PostgresqlStatus, err er
 			-- True if at least one column requires a restart
 			EXISTS(SELECT 1 FROM pg_settings WHERE pending_restart),
 			-- The size of database in human readable format
-			(SELECT pg_size_pretty(SUM(pg_database_size(oid))) FROM pg_database)`)
-	err = row.Scan(&result.SystemID, &result.IsPrimary, &result.PendingRestart, &result.TotalInstanceSize)
+			(SELECT pg_size_pretty(SUM(pg_database_size(oid))) FROM pg_database),
+			-- The size of the WAL segments, chosen at initdb time
+			pg_size_bytes(current_setting('wal_segment_size')),
+			-- True if data checksums are enabled, chosen at initdb time
+			current_setting('data_checksums') = 'on',
+			-- The encoding and the locale, chosen at initdb time
+			pg_encoding_to_char(d.encoding), d.datcollate, d.datctype
+		FROM pg_database d WHERE d.datname = current_database()`)
+	err = row.Scan(&result.SystemID, &result.IsPrimary, &result.PendingRestart, &result.TotalInstanceSize,
+		&result.WALSegmentSize, &result.DataChecksums,
+		&result.Encoding, &result.LocaleCollate, &result.LocaleCType)
 	if err != nil {
 		return result, err
 	}
 
 	if result.PendingRestart {
+		result.PendingRestartSettings, err = getPendingRestartSettings(superUserDB)
+		if err != nil {
+			return result, err
+		}
+
+		if slices.Contains(result.PendingRestartSettings, postgres.SharedPreloadLibraries) {
+			result.MissingSharedPreloadLibraries, err = getMissingSharedPreloadLibraries(superUserDB)
+			if err != nil {
+				return result, err
+			}
+		}
+
 		err = updateResultForDecrease(instance, superUserDB, result)
 		if err != nil {
 			return result, err
@@ -124,6 +190,21 @@ func (instance *Instance) GetStatus() (result *postgres.PostgresqlStatus, err er
 		return result, err
 	}
 
+	if !result.IsPrimary {
+		result.SlotsConvergence = instance.GetSlotsConvergence()
+	} else {
+		result.PgBouncerIntegrationReady = instance.IsPgBouncerIntegrationReady()
+	}
+
+	// the replication slots are only informative, and a failure
+	// reading them doesn't make the instance status unavailable
+	replicationSlots, slotsErr := instance.GetReplicationSlots()
+	if slotsErr != nil {
+		log.Warning("Error while reading the replication slots, reporting the status without them",
+			"err", slotsErr)
+	}
+	result.ReplicationSlots = replicationSlots
+
 	result.InstanceArch = runtime.GOARCH
 
 	result.ExecutableHash, err = executablehash.Get()
@@ -136,6 +217,190 @@ func (instance *Instance) GetStatus() (result *postgres.PostgresqlStatus, err er
 	return result, nil
 }
 
+// getPendingRestartSettings gets the names of the parameters whose
+// new value requires a restart of the instance to be applied
+func getPendingRestartSettings(superUserDB *sql.DB) ([]string, error) {
+	rows, err := superUserDB.Query("SELECT name FROM pg_settings WHERE pending_restart ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var settings []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		settings = append(settings, name)
+	}
+
+	return settings, rows.Err()
+}
+
+// getMissingSharedPreloadLibraries gets the shared preload libraries, waiting
+// for a restart to be loaded, which can't be found in this instance
+func getMissingSharedPreloadLibraries(superUserDB *sql.DB) ([]string, error) {
+	row := superUserDB.QueryRow(
+		`SELECT
+			-- The last value of the parameter in the configuration files
+			(SELECT setting FROM pg_file_settings
+				WHERE name = 'shared_preload_libraries' AND error IS NULL
+				ORDER BY seqno DESC LIMIT 1),
+			(SELECT setting FROM pg_config WHERE name = 'PKGLIBDIR'),
+			current_setting('dynamic_library_path')`)
+
+	var libraries sql.NullString
+	var pkgLibDir, dynamicLibraryPath string
+	if err := row.Scan(&libraries, &pkgLibDir, &dynamicLibraryPath); err != nil {
+		return nil, err
+	}
+
+	if !libraries.Valid {
+		return nil, nil
+	}
+
+	return findMissingLibraries(
+		parseLibraryList(libraries.String),
+		pkgLibDir,
+		strings.Split(dynamicLibraryPath, ":"))
+}
+
+// parseLibraryList parses the value of the shared_preload_libraries parameter
+func parseLibraryList(value string) []string {
+	var result []string
+	for _, library := range strings.Split(value, ",") {
+		library = strings.Trim(strings.TrimSpace(library), `"`)
+		if library != "" {
+			result = append(result, library)
+		}
+	}
+
+	return result
+}
+
+// findMissingLibraries returns the libraries which can't be found, following
+// the same rules PostgreSQL uses to load them: names containing a directory
+// separator are used as they are, while the other ones are looked up in the
+// dynamic library path. In both cases, the name with the `.so` suffix is
+// tried too
+func findMissingLibraries(libraries []string, pkgLibDir string, searchPath []string) ([]string, error) {
+	expandLibDir := func(path string) string {
+		return strings.Replace(path, "$libdir", pkgLibDir, 1)
+	}
+
+	var missing []string
+	for _, library := range libraries {
+		var candidates []string
+		if strings.Contains(library, "/") {
+			candidates = []string{expandLibDir(library)}
+		} else {
+			for _, directory := range searchPath {
+				candidates = append(candidates, filepath.Join(expandLibDir(directory), library))
+			}
+		}
+
+		found := false
+		for _, candidate := range candidates {
+			for _, fileName := range []string{candidate, candidate + ".so"} {
+				exists, err := fileutils.FileExists(fileName)
+				if err != nil {
+					return nil, err
+				}
+				found = found || exists
+			}
+		}
+
+		if !found {
+			missing = append(missing, library)
+		}
+	}
+
+	return missing, nil
+}
+
+// GetSettings gets the configuration parameters as loaded by
+// the running instance, together with their source
+func (instance *Instance) GetSettings() ([]postgres.PgSetting, error) {
+	superUserDB, err := instance.GetSuperUserDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := superUserDB.Query(
+		"SELECT name, COALESCE(setting, ''), COALESCE(unit, ''), source, pending_restart " +
+			"FROM pg_settings ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var settings []postgres.PgSetting
+	for rows.Next() {
+		var setting postgres.PgSetting
+		if err := rows.Scan(
+			&setting.Name,
+			&setting.Setting,
+			&setting.Unit,
+			&setting.Source,
+			&setting.PendingRestart,
+		); err != nil {
+			return nil, err
+		}
+		settings = append(settings, setting)
+	}
+
+	return settings, rows.Err()
+}
+
+// GetReplicationSlots gets the replication slots defined in the
+// running PostgreSQL instance
+func (instance *Instance) GetReplicationSlots() ([]postgres.PgReplicationSlot, error) {
+	superUserDB, err := instance.GetSuperUserDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := superUserDB.Query(
+		"SELECT slot_name, COALESCE(plugin, ''), slot_type, COALESCE(database, ''), " +
+			"temporary, active, COALESCE(restart_lsn::TEXT, ''), " +
+			"COALESCE(confirmed_flush_lsn::TEXT, ''), " +
+			"COALESCE(pg_wal_lsn_diff(CASE WHEN pg_is_in_recovery() " +
+			"THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END, restart_lsn), 0)::BIGINT " +
+			"FROM pg_replication_slots ORDER BY slot_name")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var slots []postgres.PgReplicationSlot
+	for rows.Next() {
+		var slot postgres.PgReplicationSlot
+		if err := rows.Scan(
+			&slot.SlotName,
+			&slot.Plugin,
+			&slot.SlotType,
+			&slot.Database,
+			&slot.Temporary,
+			&slot.Active,
+			&slot.RestartLsn,
+			&slot.ConfirmedFlushLsn,
+			&slot.RetainedWALSize,
+		); err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+
+	return slots, rows.Err()
+}
+
 // updateResultForDecrease updates the given postgres.PostgresqlStatus
 // in case of pending restart, by checking whether the restart is due to hot standby
 // sensible parameters being decreased
@@ -362,6 +627,13 @@ func (instance *Instance) fillWalStatus(result *postgres.PostgresqlStatus) error
 		return err
 	}
 
+	// the status of the additional WAL archives is only informative,
+	// and a failure reading it doesn't make the instance status unavailable
+	result.WALArchiveDestinations, err = archiver.GetWALArchiveDestinationsStatus()
+	if err != nil {
+		log.Warning("Error while reading the status of the additional WAL archives", "err", err)
+	}
+
 	return nil
 }
 
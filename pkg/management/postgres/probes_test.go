@@ -0,0 +1,116 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("the detection of missing shared preload libraries", func() {
+	var pkgLibDir string
+	var extraLibDir string
+
+	_ = BeforeEach(func() {
+		var err error
+		pkgLibDir, err = os.MkdirTemp("", "missing-libraries-pkglibdir-")
+		Expect(err).NotTo(HaveOccurred())
+		extraLibDir, err = os.MkdirTemp("", "missing-libraries-extra-")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, fileName := range []string{
+			filepath.Join(pkgLibDir, "pg_stat_statements.so"),
+			filepath.Join(extraLibDir, "timescaledb.so"),
+		} {
+			Expect(os.WriteFile(fileName, nil, 0o600)).To(Succeed())
+		}
+	})
+
+	_ = AfterEach(func() {
+		Expect(os.RemoveAll(pkgLibDir)).To(Succeed())
+		Expect(os.RemoveAll(extraLibDir)).To(Succeed())
+	})
+
+	It("parses the value of the shared_preload_libraries parameter", func() {
+		Expect(parseLibraryList(`pg_stat_statements, "auto_explain",,`)).To(
+			Equal([]string{"pg_stat_statements", "auto_explain"}))
+		Expect(parseLibraryList("")).To(BeEmpty())
+	})
+
+	It("finds the libraries in the dynamic library path", func() {
+		missing, err := findMissingLibraries(
+			[]string{"pg_stat_statements", "pg_stat_statements.so", "timescaledb", "pgaudit"},
+			pkgLibDir,
+			[]string{"$libdir", extraLibDir})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(Equal([]string{"pgaudit"}))
+	})
+
+	It("uses the names containing a directory as they are", func() {
+		missing, err := findMissingLibraries(
+			[]string{"$libdir/pg_stat_statements", filepath.Join(extraLibDir, "timescaledb"), "$libdir/timescaledb"},
+			pkgLibDir,
+			[]string{"$libdir"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(Equal([]string{"$libdir/timescaledb"}))
+	})
+})
+
+var _ = Describe("the readiness probe query", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("runs the query in a transaction with a statement timeout, rolling it back", func() {
+		mock.ExpectBegin()
+		mock.ExpectExec("SET LOCAL statement_timeout TO 5000").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		Expect(runReadinessQuery(context.Background(), db, "SELECT 1")).To(Succeed())
+	})
+
+	It("reports the failure of the query, rolling back the transaction", func() {
+		mock.ExpectBegin()
+		mock.ExpectExec("SET LOCAL statement_timeout TO 5000").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO probe VALUES (1)").
+			WillReturnError(errors.New("cannot execute INSERT in a read-only transaction"))
+		mock.ExpectRollback()
+
+		Expect(runReadinessQuery(context.Background(), db, "INSERT INTO probe VALUES (1)")).
+			To(MatchError(ContainSubstring("read-only transaction")))
+	})
+})
@@ -29,6 +29,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
@@ -36,6 +39,8 @@ import (
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/internal/cmd/manager/walarchive"
+	"github.com/cloudnative-pg/cloudnative-pg/internal/management/utils"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/conditions"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/configfile"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management"
@@ -52,6 +57,16 @@ import (
 	postgresSpec "github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 )
 
+const (
+	// pgControldataStateShutDown is the database cluster state reported by
+	// pg_controldata for a primary which has been shut down cleanly
+	pgControldataStateShutDown = "shut down"
+
+	// pgControldataStateShutDownInRecovery is the database cluster state reported
+	// by pg_controldata for a standby which has been shut down cleanly
+	pgControldataStateShutDownInRecovery = "shut down in recovery"
+)
+
 var (
 	// ErrInstanceInRecovery is raised while PostgreSQL is still in recovery mode
 	ErrInstanceInRecovery = fmt.Errorf("instance in recovery")
@@ -66,6 +81,7 @@ var (
 	}
 
 	enforcedParametersRegex          = regexp.MustCompile(`(?P<PARAM>[a-z_]+) setting:\s+(?P<VALUE>[a-z0-9]+)`)
+	databaseClusterStateRegex        = regexp.MustCompile(`^Database cluster state:\s+(?P<STATE>.+)$`)
 	pgControldataSettingsToParamsMap = map[string]string{
 		"max_connections":      "max_connections",
 		"max_wal_senders":      "max_wal_senders",
@@ -141,7 +157,152 @@ func (info InitInfo) Restore(ctx context.Context) error {
 		return err
 	}
 
-	return info.ConfigureInstanceAfterRestore(cluster, env)
+	if err := info.ConfigureInstanceAfterRestore(cluster, env); err != nil {
+		return err
+	}
+
+	if !cluster.ShouldRecoveryRegenerateCredentials() {
+		return nil
+	}
+
+	return info.regenerateCredentials(ctx, typedClient, cluster, env)
+}
+
+// roleCredentials is the password that must be set for a role at the
+// end of the recovery. A nil password disables the password authentication
+type roleCredentials struct {
+	username string
+	password *string
+}
+
+// alterRoleStatement returns the SQL statement setting the password of the role
+func (credentials roleCredentials) alterRoleStatement() string {
+	password := "NULL"
+	if credentials.password != nil {
+		password = pq.QuoteLiteral(*credentials.password)
+	}
+
+	return fmt.Sprintf("ALTER ROLE %v WITH PASSWORD %v",
+		pgx.Identifier{credentials.username}.Sanitize(),
+		password)
+}
+
+// loadRegeneratedCredentials reads, from the secrets of the new cluster,
+// the credentials that must replace the ones inherited from the source cluster
+func (info InitInfo) loadRegeneratedCredentials(
+	ctx context.Context,
+	typedClient client.Client,
+	cluster *apiv1.Cluster,
+) ([]roleCredentials, error) {
+	readCredentials := func(username, secretName string) (roleCredentials, error) {
+		var secret corev1.Secret
+		if err := typedClient.Get(
+			ctx,
+			client.ObjectKey{Namespace: info.Namespace, Name: secretName},
+			&secret); err != nil {
+			return roleCredentials{}, fmt.Errorf("while getting secret %s: %w", secretName, err)
+		}
+
+		usernameFromSecret, password, err := utils.GetUserPasswordFromSecret(&secret)
+		if err != nil {
+			return roleCredentials{}, fmt.Errorf("while reading secret %s: %w", secretName, err)
+		}
+		if usernameFromSecret != username {
+			return roleCredentials{}, fmt.Errorf("wrong username '%v' in secret %s, expected '%v'",
+				usernameFromSecret, secretName, username)
+		}
+
+		return roleCredentials{username: username, password: &password}, nil
+	}
+
+	// The superuser password is always reset, as the one of the
+	// source cluster must not be usable in the new one
+	superuserCredentials := roleCredentials{username: "postgres"}
+	if cluster.GetEnableSuperuserAccess() {
+		var err error
+		superuserCredentials, err = readCredentials("postgres", cluster.GetSuperuserSecretName())
+		if err != nil {
+			return nil, err
+		}
+	}
+	result := []roleCredentials{superuserCredentials}
+
+	if cluster.ShouldRecoveryCreateApplicationDatabase() {
+		appCredentials, err := readCredentials(cluster.GetApplicationDatabaseOwner(), cluster.GetApplicationSecretName())
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, appCredentials)
+	}
+
+	return result, nil
+}
+
+// regenerateCredentials replaces the passwords inherited from the source
+// cluster with the ones of the new cluster. This is done after the recovered
+// instance has been promoted, while it is still accepting only local
+// connections, and is reported in the cluster status
+func (info InitInfo) regenerateCredentials(
+	ctx context.Context,
+	typedClient client.Client,
+	cluster *apiv1.Cluster,
+	env []string,
+) error {
+	credentials, err := info.loadRegeneratedCredentials(ctx, typedClient, cluster)
+	if err != nil {
+		return fmt.Errorf("while loading the credentials to be regenerated: %w", err)
+	}
+
+	instance := info.GetInstance()
+	instance.Env = env
+
+	var rotatedRoles []string
+	if err := instance.WithActiveInstance(func() error {
+		db, err := instance.GetSuperUserDB()
+		if err != nil {
+			return err
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		for _, role := range credentials {
+			// When recovering to a point in time, a role could not exist yet
+			var exists bool
+			row := tx.QueryRow("SELECT COUNT(*) > 0 FROM pg_catalog.pg_roles WHERE rolname = $1", role.username)
+			if err := row.Scan(&exists); err != nil {
+				return err
+			}
+			if !exists {
+				log.Info("Role not found in the recovered instance, skipping credentials regeneration",
+					"role", role.username)
+				continue
+			}
+
+			if _, err := tx.Exec(role.alterRoleStatement()); err != nil {
+				return fmt.Errorf("while running ALTER ROLE %v WITH PASSWORD: %w", role.username, err)
+			}
+			rotatedRoles = append(rotatedRoles, role.username)
+		}
+
+		return tx.Commit()
+	}); err != nil {
+		return fmt.Errorf("while regenerating credentials: %w", err)
+	}
+
+	log.Info("Credentials regenerated after the recovery", "roles", rotatedRoles)
+
+	return conditions.Update(ctx, typedClient, cluster, &metav1.Condition{
+		Type:    string(apiv1.ConditionCredentialsRotated),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(apiv1.ConditionReasonCredentialsRegenerated),
+		Message: fmt.Sprintf("Credentials regenerated after the recovery for roles: %s", strings.Join(rotatedRoles, ", ")),
+	})
 }
 
 // restoreCustomWalDir moves the current pg_wal data to the specified custom wal dir and applies the symlink
@@ -448,6 +609,57 @@ func (info InitInfo) writeRestoreWalConfig(backup *apiv1.Backup, cluster *apiv1.
 // GetEnforcedParametersThroughPgControldata will parse the output of pg_controldata in order to get
 // the values of all the hot standby sensible parameters
 func GetEnforcedParametersThroughPgControldata(pgData string) (map[string]string, error) {
+	output, err := getPgControldataOutput(pgData)
+	if err != nil {
+		return nil, err
+	}
+
+	enforcedParams := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		matches := enforcedParametersRegex.FindStringSubmatch(line)
+		if len(matches) < 3 {
+			continue
+		}
+		if param, ok := pgControldataSettingsToParamsMap[matches[1]]; ok {
+			enforcedParams[param] = matches[2]
+		}
+	}
+	return enforcedParams, nil
+}
+
+// GetDatabaseClusterStateThroughPgControldata will parse the output of pg_controldata
+// in order to get the state of the database cluster, i.e. "shut down"
+func GetDatabaseClusterStateThroughPgControldata(pgData string) (string, error) {
+	output, err := getPgControldataOutput(pgData)
+	if err != nil {
+		return "", err
+	}
+
+	return parseDatabaseClusterState(output)
+}
+
+// IsCleanShutdownState checks if the passed database cluster state,
+// as reported by pg_controldata, belongs to a cleanly shut down instance
+func IsCleanShutdownState(state string) bool {
+	return state == pgControldataStateShutDown || state == pgControldataStateShutDownInRecovery
+}
+
+// parseDatabaseClusterState extracts the database cluster state from
+// the output of pg_controldata
+func parseDatabaseClusterState(output string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		matches := databaseClusterStateRegex.FindStringSubmatch(line)
+		if len(matches) == 2 {
+			return strings.TrimSpace(matches[1]), nil
+		}
+	}
+
+	return "", fmt.Errorf("database cluster state not found in the pg_controldata output")
+}
+
+// getPgControldataOutput runs pg_controldata on the passed data directory
+// and returns its output
+func getPgControldataOutput(pgData string) (string, error) {
 	var stdoutBuffer bytes.Buffer
 	var stderrBuffer bytes.Buffer
 	pgControlDataCmd := exec.Command(pgControlDataName,
@@ -461,22 +673,12 @@ func GetEnforcedParametersThroughPgControldata(pgData string) (map[string]string
 		log.Error(err, "while reading pg_controldata",
 			"stderr", stderrBuffer.String(),
 			"stdout", stdoutBuffer.String())
-		return nil, err
+		return "", err
 	}
 
 	log.Debug("pg_controldata stdout", "stdout", stdoutBuffer.String())
 
-	enforcedParams := map[string]string{}
-	for _, line := range strings.Split(stdoutBuffer.String(), "\n") {
-		matches := enforcedParametersRegex.FindStringSubmatch(line)
-		if len(matches) < 3 {
-			continue
-		}
-		if param, ok := pgControldataSettingsToParamsMap[matches[1]]; ok {
-			enforcedParams[param] = matches[2]
-		}
-	}
-	return enforcedParams, nil
+	return stdoutBuffer.String(), nil
 }
 
 // WriteInitialPostgresqlConf resets the postgresql.conf that there is in the instance using
@@ -566,7 +768,8 @@ func (info InitInfo) WriteRestoreHbaConf() error {
 	}
 
 	// Create the local map referred in the HBA configuration
-	return WritePostgresUserMaps(info.PgData)
+	_, err = WritePostgresUserMaps(info.PgData, false)
+	return err
 }
 
 // ConfigureInstanceAfterRestore changes the superuser password
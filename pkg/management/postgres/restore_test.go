@@ -22,8 +22,12 @@ import (
 	"path"
 
 	"github.com/thoas/go-funk"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/strings/slices"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/fileutils"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -148,3 +152,104 @@ var _ = Describe("testing restore InitInfo methods", func() {
 		Expect(chg).To(BeFalse())
 	})
 })
+
+var _ = Describe("credentials regeneration after a recovery", func() {
+	buildSecret := func(name, username, password string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data: map[string][]byte{
+				"username": []byte(username),
+				"password": []byte(password),
+			},
+		}
+	}
+
+	cluster := &apiv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-example", Namespace: "default"},
+		Spec: apiv1.ClusterSpec{
+			Bootstrap: &apiv1.BootstrapConfiguration{
+				Recovery: &apiv1.BootstrapRecovery{
+					Database:              "app",
+					Owner:                 "app",
+					RegenerateCredentials: true,
+				},
+			},
+		},
+	}
+	info := InitInfo{Namespace: "default"}
+
+	It("generates the ALTER ROLE statements", func() {
+		password := "it's a secret"
+		Expect(roleCredentials{username: "app", password: &password}.alterRoleStatement()).
+			To(Equal(`ALTER ROLE "app" WITH PASSWORD 'it''s a secret'`))
+		Expect(roleCredentials{username: "postgres"}.alterRoleStatement()).
+			To(Equal(`ALTER ROLE "postgres" WITH PASSWORD NULL`))
+	})
+
+	It("loads the superuser and the application user credentials from the secrets", func() {
+		typedClient := fake.NewClientBuilder().WithObjects(
+			buildSecret(cluster.GetSuperuserSecretName(), "postgres", "superuser-password"),
+			buildSecret(cluster.GetApplicationSecretName(), "app", "app-password"),
+		).Build()
+
+		credentials, err := info.loadRegeneratedCredentials(context.TODO(), typedClient, cluster)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credentials).To(HaveLen(2))
+		Expect(credentials[0].username).To(Equal("postgres"))
+		Expect(*credentials[0].password).To(Equal("superuser-password"))
+		Expect(credentials[1].username).To(Equal("app"))
+		Expect(*credentials[1].password).To(Equal("app-password"))
+	})
+
+	It("disables the superuser password when the superuser access is disabled", func() {
+		disabled := false
+		noSuperuser := cluster.DeepCopy()
+		noSuperuser.Spec.EnableSuperuserAccess = &disabled
+		typedClient := fake.NewClientBuilder().WithObjects(
+			buildSecret(cluster.GetApplicationSecretName(), "app", "app-password"),
+		).Build()
+
+		credentials, err := info.loadRegeneratedCredentials(context.TODO(), typedClient, noSuperuser)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credentials).To(HaveLen(2))
+		Expect(credentials[0].username).To(Equal("postgres"))
+		Expect(credentials[0].password).To(BeNil())
+	})
+
+	It("refuses a secret belonging to a different user", func() {
+		typedClient := fake.NewClientBuilder().WithObjects(
+			buildSecret(cluster.GetSuperuserSecretName(), "postgres", "superuser-password"),
+			buildSecret(cluster.GetApplicationSecretName(), "another", "app-password"),
+		).Build()
+
+		_, err := info.loadRegeneratedCredentials(context.TODO(), typedClient, cluster)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("pg_controldata database cluster state", func() {
+	const controlData = `pg_control version number:            1300
+Catalog version number:               202209061
+Database system identifier:           7193264311467569171
+Database cluster state:               shut down in recovery
+pg_control last modified:             Mon 16 Jan 2023 10:00:00 AM UTC
+`
+
+	It("extracts the database cluster state", func() {
+		state, err := parseDatabaseClusterState(controlData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal("shut down in recovery"))
+	})
+
+	It("fails when the database cluster state is missing", func() {
+		_, err := parseDatabaseClusterState("pg_control version number:            1300\n")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("detects the states of the cleanly shut down instances", func() {
+		Expect(IsCleanShutdownState("shut down")).To(BeTrue())
+		Expect(IsCleanShutdownState("shut down in recovery")).To(BeTrue())
+		Expect(IsCleanShutdownState("in production")).To(BeFalse())
+		Expect(IsCleanShutdownState("in crash recovery")).To(BeFalse())
+	})
+})
@@ -61,6 +61,7 @@ func NewLocalWebServer(instance *postgres.Instance) (*Webserver, error) {
 	serveMux := http.NewServeMux()
 	serveMux.HandleFunc(url.PathCache, endpoints.serveCache)
 	serveMux.HandleFunc(url.PathPgBackup, endpoints.requestBackup)
+	serveMux.HandleFunc(url.PathPgSettings, endpoints.pgSettings)
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf("localhost:%d", url.LocalPort),
@@ -81,8 +82,8 @@ func (ws *localWebserverEndpoints) serveCache(w http.ResponseWriter, r *http.Req
 	log.Debug("Cached object request received")
 
 	var js []byte
-	switch requestedObject {
-	case cache.ClusterKey:
+	switch {
+	case requestedObject == cache.ClusterKey:
 		response, err := cache.LoadCluster()
 		if errors.Is(err, cache.ErrCacheMiss) {
 			w.WriteHeader(http.StatusNotFound)
@@ -99,7 +100,7 @@ func (ws *localWebserverEndpoints) serveCache(w http.ResponseWriter, r *http.Req
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-	case cache.WALRestoreKey, cache.WALArchiveKey:
+	case requestedObject == cache.WALRestoreKey, strings.HasPrefix(requestedObject, cache.WALArchiveKey):
 		response, err := cache.LoadEnv(requestedObject)
 		if errors.Is(err, cache.ErrCacheMiss) {
 			w.WriteHeader(http.StatusNotFound)
@@ -126,6 +127,31 @@ func (ws *localWebserverEndpoints) serveCache(w http.ResponseWriter, r *http.Req
 	_, _ = w.Write(js)
 }
 
+// This endpoint returns the configuration parameters as loaded by PostgreSQL.
+// It's served only locally, as the settings may contain sensitive information
+func (ws *localWebserverEndpoints) pgSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := ws.instance.GetSettings()
+	if err != nil {
+		log.Info(
+			"Cannot extract the PostgreSQL settings",
+			"err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	js, err := json.Marshal(settings)
+	if err != nil {
+		log.Info(
+			"Internal error marshalling PostgreSQL settings",
+			"err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(js)
+}
+
 // This function schedule a backup
 func (ws *localWebserverEndpoints) requestBackup(w http.ResponseWriter, r *http.Request) {
 	var cluster apiv1.Cluster
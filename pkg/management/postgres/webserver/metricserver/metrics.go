@@ -17,6 +17,7 @@ limitations under the License.
 package metricserver
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/webserver"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/url"
+	postgresSpec "github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 )
 
 // MetricsServer exposes the metrics of the postgres instance
@@ -39,8 +41,9 @@ type MetricsServer struct {
 }
 
 // New configure the web statusServer for a certain PostgreSQL instance, and
-// must be invoked before starting the real web statusServer
-func New(serverInstance *postgres.Instance) (*MetricsServer, error) {
+// must be invoked before starting the real web statusServer. When enableTLS
+// is true the metrics are served over TLS using the server certificate
+func New(serverInstance *postgres.Instance, enableTLS bool) (*MetricsServer, error) {
 	registry := prometheus.NewRegistry()
 	exporter := NewExporter(serverInstance)
 	if err := registry.Register(exporter); err != nil {
@@ -59,6 +62,13 @@ func New(serverInstance *postgres.Instance) (*MetricsServer, error) {
 		ReadHeaderTimeout: webserver.DefaultReadHeaderTimeout,
 	}
 
+	if enableTLS {
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: getServerCertificate,
+		}
+	}
+
 	metricServer := &MetricsServer{
 		Webserver: webserver.NewWebServer(serverInstance, server),
 		exporter:  exporter,
@@ -67,6 +77,20 @@ func New(serverInstance *postgres.Instance) (*MetricsServer, error) {
 	return metricServer, nil
 }
 
+// getServerCertificate loads the server certificate for each new TLS
+// connection, so that renewed certificates are used without restarting
+// the webserver
+func getServerCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certificate, err := tls.LoadX509KeyPair(
+		postgresSpec.ServerCertificateLocation,
+		postgresSpec.ServerKeyLocation)
+	if err != nil {
+		return nil, fmt.Errorf("while loading the server certificate: %w", err)
+	}
+
+	return &certificate, nil
+}
+
 // GetExporter get the exporter used for metrics. If the web statusServer still
 // has not started, the exporter is nil
 func (ms *MetricsServer) GetExporter() *Exporter {
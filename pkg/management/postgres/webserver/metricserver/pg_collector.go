@@ -23,13 +23,17 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/cloudnative-pg/cloudnative-pg/internal/management/cache"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/barman/archiver"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/logpipe"
 	m "github.com/cloudnative-pg/cloudnative-pg/pkg/management/postgres/metrics"
 	postgresconf "github.com/cloudnative-pg/cloudnative-pg/pkg/postgres"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/specs"
@@ -63,10 +67,18 @@ type metrics struct {
 	SyncReplicas             *prometheus.GaugeVec
 	ReplicaCluster           prometheus.Gauge
 	PgWALArchiveStatus       *prometheus.GaugeVec
+	PgWALArchiveFailures     prometheus.Gauge
 	PgWALDirectory           *prometheus.GaugeVec
 	PgVersion                *prometheus.GaugeVec
 	FirstRecoverabilityPoint prometheus.Gauge
 	FencingOn                prometheus.Gauge
+	PoolCircuitBreakerState  *prometheus.GaugeVec
+	ReplicationSlotInactive  *prometheus.GaugeVec
+	ReplicationSlotErrors    *prometheus.CounterVec
+	ReplicationSlotUnsynced  prometheus.Gauge
+	LogicalSlotFlushGap      *prometheus.GaugeVec
+	Backends                 *prometheus.GaugeVec
+	SlowQueries              prometheus.CounterFunc
 	PgStatWalMetrics         PgStatWalMetrics
 }
 
@@ -149,6 +161,12 @@ func newMetrics() *metrics {
 			Help: fmt.Sprintf("Number of WAL segments in the '%s' directory (ready, done)",
 				specs.PgWalArchiveStatusPath),
 		}, []string{"value"}),
+		PgWALArchiveFailures: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: subsystem,
+			Name:      "pg_wal_archive_consecutive_failures",
+			Help:      "Number of consecutive failures of the WAL archiving, reset after a successful attempt",
+		}),
 		PgVersion: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: PrometheusNamespace,
 			Subsystem: subsystem,
@@ -175,6 +193,52 @@ func newMetrics() *metrics {
 			Name:      "fencing_on",
 			Help:      "1 if the instance is fenced, 0 otherwise",
 		}),
+		PoolCircuitBreakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: subsystem,
+			Name:      "connection_pool_circuit_breaker_state",
+			Help:      "State of the circuit breaker of the connection pool (0 closed, 1 half-open, 2 open)",
+		}, []string{"pool"}),
+		ReplicationSlotInactive: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Name:      "replication_slot_inactive_seconds",
+			Help: "Number of seconds since the replication slot on the primary has been " +
+				"first seen without a consumer by the slot replicator of this instance",
+		}, []string{"slot_name"}),
+		ReplicationSlotErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: PrometheusNamespace,
+			Name:      "replication_slot_sync_errors_total",
+			Help: "Number of failures of the slot replicator of this instance while creating, " +
+				"updating, dropping or disabling the local replication slots, by operation and reason",
+		}, []string{"operation", "reason"}),
+		ReplicationSlotUnsynced: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Name:      "replication_slot_oldest_unsynced_seconds",
+			Help: "Number of seconds since the oldest replication slot on the primary has been " +
+				"first seen without a synchronized copy in this instance, 0 if every slot is synchronized",
+		}),
+		LogicalSlotFlushGap: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: subsystem,
+			Name:      "logical_replication_slot_flush_gap_bytes",
+			Help: "Amount of WAL, in bytes, between the restart_lsn and the confirmed_flush_lsn " +
+				"of each logical replication slot, which retains WAL and catalog_xmin",
+		}, []string{"slot_name", "database", "plugin"}),
+		Backends: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: subsystem,
+			Name:      "backends_total",
+			Help:      "Number of client backends per database and state, from pg_stat_activity",
+		}, []string{"datname", "state"}),
+		SlowQueries: prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: subsystem,
+			Name:      "slow_queries_total",
+			Help: "Number of statements logged by PostgreSQL for exceeding log_min_duration_statement " +
+				"since the instance manager started",
+		}, func() float64 {
+			return float64(logpipe.GetSlowQueriesTotal())
+		}),
 		PgStatWalMetrics: PgStatWalMetrics{
 			WalRecords: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 				Namespace: PrometheusNamespace,
@@ -246,10 +310,18 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	e.Metrics.SyncReplicas.Describe(ch)
 	ch <- e.Metrics.ReplicaCluster.Desc()
 	e.Metrics.PgWALArchiveStatus.Describe(ch)
+	ch <- e.Metrics.PgWALArchiveFailures.Desc()
 	e.Metrics.PgWALDirectory.Describe(ch)
 	e.Metrics.PgVersion.Describe(ch)
 	e.Metrics.FirstRecoverabilityPoint.Describe(ch)
 	e.Metrics.FencingOn.Describe(ch)
+	e.Metrics.PoolCircuitBreakerState.Describe(ch)
+	e.Metrics.ReplicationSlotInactive.Describe(ch)
+	e.Metrics.ReplicationSlotErrors.Describe(ch)
+	ch <- e.Metrics.ReplicationSlotUnsynced.Desc()
+	e.Metrics.LogicalSlotFlushGap.Describe(ch)
+	e.Metrics.Backends.Describe(ch)
+	ch <- e.Metrics.SlowQueries.Desc()
 
 	if e.queries != nil {
 		e.queries.Describe(ch)
@@ -281,10 +353,20 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	e.Metrics.SyncReplicas.Collect(ch)
 	ch <- e.Metrics.ReplicaCluster
 	e.Metrics.PgWALArchiveStatus.Collect(ch)
+	ch <- e.Metrics.PgWALArchiveFailures
 	e.Metrics.PgWALDirectory.Collect(ch)
 	e.Metrics.PgVersion.Collect(ch)
 	e.Metrics.FirstRecoverabilityPoint.Collect(ch)
 
+	e.collectPoolCircuitBreakerState()
+	e.Metrics.PoolCircuitBreakerState.Collect(ch)
+	e.Metrics.ReplicationSlotInactive.Collect(ch)
+	e.Metrics.ReplicationSlotErrors.Collect(ch)
+	ch <- e.Metrics.ReplicationSlotUnsynced
+	e.Metrics.LogicalSlotFlushGap.Collect(ch)
+	e.Metrics.Backends.Collect(ch)
+	ch <- e.Metrics.SlowQueries
+
 	if version, _ := e.instance.GetPgVersion(); version.Major >= 14 {
 		e.Metrics.PgStatWalMetrics.WalSync.Collect(ch)
 		e.Metrics.PgStatWalMetrics.WalWriteTime.Collect(ch)
@@ -297,6 +379,15 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// collectPoolCircuitBreakerState sets the state of the circuit breaker
+// of the local and primary connection pools
+func (e *Exporter) collectPoolCircuitBreakerState() {
+	e.Metrics.PoolCircuitBreakerState.WithLabelValues("local").Set(
+		float64(e.instance.ConnectionPool().CircuitBreakerState()))
+	e.Metrics.PoolCircuitBreakerState.WithLabelValues("primary").Set(
+		float64(e.instance.PrimaryConnectionPool().CircuitBreakerState()))
+}
+
 func (e *Exporter) collectPgMetrics(ch chan<- prometheus.Metric) {
 	e.Metrics.CollectionsTotal.Inc()
 	collectionStart := time.Now()
@@ -366,6 +457,12 @@ func (e *Exporter) collectPgMetrics(ch chan<- prometheus.Metric) {
 		e.Metrics.PgWALArchiveStatus.Reset()
 	}
 
+	if err := collectPGWalArchiveFailures(e); err != nil {
+		log.Error(err, "while collecting WAL archive failures")
+		e.Metrics.Error.Set(1)
+		e.Metrics.PgCollectionErrors.WithLabelValues("Collect.PgWALArchiveFailures").Inc()
+	}
+
 	if err := collectPGWalMetric(e, db); err != nil {
 		log.Error(err, "while collecting WAL metrics", "path", specs.PgWalPath)
 		e.Metrics.Error.Set(1)
@@ -380,6 +477,20 @@ func (e *Exporter) collectPgMetrics(ch chan<- prometheus.Metric) {
 		e.Metrics.PgVersion.Reset()
 	}
 
+	if err := collectBackends(e, db); err != nil {
+		log.Error(err, "while collecting backends metrics")
+		e.Metrics.Error.Set(1)
+		e.Metrics.PgCollectionErrors.WithLabelValues("Collect.Backends").Inc()
+		e.Metrics.Backends.Reset()
+	}
+
+	if err := collectLogicalSlotFlushGap(e, db); err != nil {
+		log.Error(err, "while collecting logical replication slots metrics")
+		e.Metrics.Error.Set(1)
+		e.Metrics.PgCollectionErrors.WithLabelValues("Collect.LogicalSlotFlushGap").Inc()
+		e.Metrics.LogicalSlotFlushGap.Reset()
+	}
+
 	if version, _ := e.instance.GetPgVersion(); version.Major >= 14 {
 		if err := collectPGWALStat(e); err != nil {
 			log.Error(err, "while collecting pg_wal_stat")
@@ -474,6 +585,99 @@ func collectPGWalArchiveMetric(exporter *Exporter) error {
 	return nil
 }
 
+// collectPGWalArchiveFailures exports the number of consecutive WAL
+// archiving failures, as recorded by the wal-archive command
+func collectPGWalArchiveFailures(exporter *Exporter) error {
+	failures, err := archiver.GetWALArchiveConsecutiveFailures()
+	if err != nil {
+		return err
+	}
+
+	exporter.Metrics.PgWALArchiveFailures.Set(float64(failures))
+	return nil
+}
+
+// collectBackends counts the client backends connected to every database,
+// grouped by state. Every known state is reported, even when no backend is
+// in it, so that the series don't disappear
+func collectBackends(exporter *Exporter, db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT d.datname, s.state, COUNT(a.pid)
+		FROM pg_catalog.pg_database d
+		CROSS JOIN ( VALUES ('active')
+			, ('idle')
+			, ('idle in transaction')
+			, ('idle in transaction (aborted)')
+			, ('fastpath function call')
+			, ('disabled')
+			) AS s(state)
+		LEFT JOIN pg_catalog.pg_stat_activity a
+			ON a.datname = d.datname
+			AND a.state = s.state
+			AND a.backend_type = 'client backend'
+		WHERE d.datallowconn
+		GROUP BY d.datname, s.state`)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	exporter.Metrics.Backends.Reset()
+	for rows.Next() {
+		var datname, state string
+		var count int
+		if err := rows.Scan(&datname, &state, &count); err != nil {
+			return err
+		}
+		exporter.Metrics.Backends.WithLabelValues(datname, backendStateLabel(state)).Set(float64(count))
+	}
+
+	return rows.Err()
+}
+
+// collectLogicalSlotFlushGap exports, for every logical replication slot,
+// the amount of WAL between its restart_lsn and its confirmed_flush_lsn.
+// A growing gap means that the slot keeps retaining WAL and, through its
+// catalog_xmin, prevents VACUUM from removing dead rows from the catalog
+func collectLogicalSlotFlushGap(exporter *Exporter, db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT slot_name, database, plugin,
+			pg_catalog.pg_wal_lsn_diff(confirmed_flush_lsn, restart_lsn)
+		FROM pg_catalog.pg_replication_slots
+		WHERE slot_type = 'logical'
+			AND confirmed_flush_lsn IS NOT NULL
+			AND restart_lsn IS NOT NULL`)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	exporter.Metrics.LogicalSlotFlushGap.Reset()
+	for rows.Next() {
+		var slotName, database, plugin string
+		var gap float64
+		if err := rows.Scan(&slotName, &database, &plugin, &gap); err != nil {
+			return err
+		}
+		exporter.Metrics.LogicalSlotFlushGap.WithLabelValues(slotName, database, plugin).Set(gap)
+	}
+
+	return rows.Err()
+}
+
+// backendStateLabel converts a backend state, as reported by
+// pg_stat_activity, in a label value, i.e. "idle in transaction (aborted)"
+// becomes "idle_in_transaction_aborted"
+func backendStateLabel(state string) string {
+	return strings.Join(strings.FieldsFunc(state, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "_")
+}
+
 func collectPGWALStat(e *Exporter) error {
 	walStat, err := e.instance.TryGetPgStatWAL()
 	if walStat == nil || err != nil {
@@ -0,0 +1,138 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricserver
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backends metrics", func() {
+	var (
+		db       *sql.DB
+		mock     sqlmock.Sqlmock
+		exporter *Exporter
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		exporter = &Exporter{Metrics: newMetrics()}
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("converts the backend states in label values", func() {
+		Expect(backendStateLabel("active")).To(Equal("active"))
+		Expect(backendStateLabel("idle in transaction")).To(Equal("idle_in_transaction"))
+		Expect(backendStateLabel("idle in transaction (aborted)")).To(Equal("idle_in_transaction_aborted"))
+		Expect(backendStateLabel("fastpath function call")).To(Equal("fastpath_function_call"))
+	})
+
+	It("exports the number of client backends per database and state", func() {
+		mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN pg_catalog.pg_stat_activity")).
+			WillReturnRows(sqlmock.NewRows([]string{"datname", "state", "count"}).
+				AddRow("app", "active", 3).
+				AddRow("app", "idle in transaction (aborted)", 1).
+				AddRow("postgres", "idle", 0))
+
+		Expect(collectBackends(exporter, db)).To(Succeed())
+		Expect(testutil.CollectAndCount(exporter.Metrics.Backends)).To(Equal(3))
+		Expect(testutil.ToFloat64(exporter.Metrics.Backends.WithLabelValues("app", "active"))).To(Equal(3.0))
+		Expect(testutil.ToFloat64(
+			exporter.Metrics.Backends.WithLabelValues("app", "idle_in_transaction_aborted"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(exporter.Metrics.Backends.WithLabelValues("postgres", "idle"))).To(BeZero())
+	})
+
+	It("forgets the databases which don't exist anymore", func() {
+		exporter.Metrics.Backends.WithLabelValues("dropped", "active").Set(2)
+		mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN pg_catalog.pg_stat_activity")).
+			WillReturnRows(sqlmock.NewRows([]string{"datname", "state", "count"}).
+				AddRow("app", "active", 1))
+
+		Expect(collectBackends(exporter, db)).To(Succeed())
+		Expect(testutil.CollectAndCount(exporter.Metrics.Backends)).To(Equal(1))
+	})
+
+	It("reports the failure of the query", func() {
+		mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN pg_catalog.pg_stat_activity")).
+			WillReturnError(errors.New("connection refused"))
+
+		Expect(collectBackends(exporter, db)).To(MatchError("connection refused"))
+	})
+})
+
+var _ = Describe("logical replication slots flush gap metrics", func() {
+	var (
+		db       *sql.DB
+		mock     sqlmock.Sqlmock
+		exporter *Exporter
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		exporter = &Exporter{Metrics: newMetrics()}
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("exports the flush gap of every logical replication slot", func() {
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE slot_type = 'logical'")).
+			WillReturnRows(sqlmock.NewRows([]string{"slot_name", "database", "plugin", "gap"}).
+				AddRow("subscription", "app", "pgoutput", 16777216).
+				AddRow("cdc", "app", "wal2json", 0))
+
+		Expect(collectLogicalSlotFlushGap(exporter, db)).To(Succeed())
+		Expect(testutil.CollectAndCount(exporter.Metrics.LogicalSlotFlushGap)).To(Equal(2))
+		Expect(testutil.ToFloat64(
+			exporter.Metrics.LogicalSlotFlushGap.WithLabelValues("subscription", "app", "pgoutput"))).
+			To(Equal(16777216.0))
+		Expect(testutil.ToFloat64(
+			exporter.Metrics.LogicalSlotFlushGap.WithLabelValues("cdc", "app", "wal2json"))).
+			To(BeZero())
+	})
+
+	It("forgets the slots which have been dropped", func() {
+		exporter.Metrics.LogicalSlotFlushGap.WithLabelValues("dropped", "app", "pgoutput").Set(1024)
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE slot_type = 'logical'")).
+			WillReturnRows(sqlmock.NewRows([]string{"slot_name", "database", "plugin", "gap"}))
+
+		Expect(collectLogicalSlotFlushGap(exporter, db)).To(Succeed())
+		Expect(testutil.CollectAndCount(exporter.Metrics.LogicalSlotFlushGap)).To(BeZero())
+	})
+
+	It("reports the failure of the query", func() {
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE slot_type = 'logical'")).
+			WillReturnError(errors.New("connection refused"))
+
+		Expect(collectLogicalSlotFlushGap(exporter, db)).To(MatchError("connection refused"))
+	})
+})
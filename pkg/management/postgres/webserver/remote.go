@@ -56,6 +56,8 @@ func NewRemoteWebServer(
 	serveMux.HandleFunc(url.PathHealth, endpoints.isServerHealthy)
 	serveMux.HandleFunc(url.PathReady, endpoints.isServerReady)
 	serveMux.HandleFunc(url.PathPgStatus, endpoints.pgStatus)
+	serveMux.HandleFunc(url.PathPgReplicationSlots, endpoints.pgReplicationSlots)
+	serveMux.HandleFunc(url.PathPgReplicationSlotsConvergence, endpoints.pgReplicationSlotsConvergence)
 	serveMux.HandleFunc(url.PathUpdate,
 		endpoints.updateInstanceManager(cancelFunc, exitedConditions))
 
@@ -128,6 +130,54 @@ func (ws *remoteWebserverEndpoints) pgStatus(w http.ResponseWriter, r *http.Requ
 	_, _ = w.Write(js)
 }
 
+func (ws *remoteWebserverEndpoints) pgReplicationSlots(w http.ResponseWriter, r *http.Request) {
+	slots, err := ws.instance.GetReplicationSlots()
+	if err != nil {
+		log.Info(
+			"Cannot extract the replication slots",
+			"err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	js, err := json.Marshal(slots)
+	if err != nil {
+		log.Info(
+			"Internal error marshalling replication slots",
+			"err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(js)
+}
+
+// This endpoint reports whether the HA replication slots of a replica are
+// aligned with the ones in the primary, failing when they are diverged
+func (ws *remoteWebserverEndpoints) pgReplicationSlotsConvergence(w http.ResponseWriter, r *http.Request) {
+	convergence := ws.instance.GetSlotsConvergence()
+	if convergence == nil {
+		http.Error(w, "replication slots are not being synchronized", http.StatusNotFound)
+		return
+	}
+
+	js, err := json.Marshal(convergence)
+	if err != nil {
+		log.Info(
+			"Internal error marshalling replication slots convergence",
+			"err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !convergence.Converged {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_, _ = w.Write(js)
+}
+
 // updateInstanceManager replace the instance with one in the
 // new binary
 func (ws *remoteWebserverEndpoints) updateInstanceManager(
@@ -51,9 +51,15 @@ func NewWebServer(instance *postgres.Instance, server *http.Server) *Webserver {
 func (ws *Webserver) Start(ctx context.Context) error {
 	errChan := make(chan error, 1)
 	go func() {
-		log.Info("Starting webserver", "address", ws.server.Addr)
+		log.Info("Starting webserver", "address", ws.server.Addr, "tls", ws.server.TLSConfig != nil)
 
-		err := ws.server.ListenAndServe()
+		var err error
+		if ws.server.TLSConfig != nil {
+			// The certificates are provided by the TLS configuration
+			err = ws.server.ListenAndServeTLS("", "")
+		} else {
+			err = ws.server.ListenAndServe()
+		}
 		if err != nil {
 			errChan <- err
 		}
@@ -40,6 +40,18 @@ const (
 	// PathPgStatus is the URL path for PostgreSQL Status
 	PathPgStatus string = "/pg/status"
 
+	// PathPgSettings is the URL path for the PostgreSQL configuration
+	// parameters, as loaded by the running instance
+	PathPgSettings string = "/pg/settings"
+
+	// PathPgReplicationSlots is the URL path for the replication slots
+	// defined in the running instance
+	PathPgReplicationSlots string = "/pg/replication-slots"
+
+	// PathPgReplicationSlotsConvergence is the URL path reporting whether
+	// the HA replication slots of a replica are aligned with the primary
+	PathPgReplicationSlotsConvergence string = "/pg/replication-slots/convergence"
+
 	// PathPgBackup is the URL path for PostgreSQL Backup
 	PathPgBackup string = "/pg/backup"
 
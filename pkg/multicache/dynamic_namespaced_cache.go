@@ -0,0 +1,624 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package multicache implements a cache that is able to work on multiple namespaces but also able to
+// read data from a namespace which is beside the specified ones. This is different from the
+// MultiNamespacedCache implementation that is inside the controller-runtime library, as the
+// set of watched namespaces can be changed while the cache is running.
+package multicache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/management/log"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/stringset"
+)
+
+// namespaceSyncTimeout is the time we wait for the cache of an added
+// namespace to be synced
+var namespaceSyncTimeout = 30 * time.Second
+
+// NamespacesUpdater is implemented by the caches whose set of watched
+// namespaces can be changed while they are running
+type NamespacesUpdater interface {
+	// SetNamespaces changes the set of watched namespaces, starting the
+	// informers for the new ones and stopping the informers of the
+	// namespaces not watched anymore
+	SetNamespaces(ctx context.Context, namespaces []string) error
+}
+
+// namespaceCache is the cache of a single watched namespace
+type namespaceCache struct {
+	cache  cache.Cache
+	cancel context.CancelFunc
+
+	// synced is true when the cache has been synced after being added
+	// to a running cache
+	synced bool
+}
+
+// fieldIndex is a field index requested to the cache, that needs to be
+// applied to the caches of the namespaces added later
+type fieldIndex struct {
+	obj          client.Object
+	field        string
+	extractValue client.IndexerFunc
+}
+
+// dynamicNamespacedCache is a cache working on a set of namespaces that can
+// be changed while the cache is running, without restarting the controllers
+// that use it. Requests belonging to namespaces different from the watched
+// ones are served by a cache of the operator namespace.
+type dynamicNamespacedCache struct {
+	config *rest.Config
+	opts   cache.Options
+
+	clusterCache  cache.Cache
+	externalCache cache.Cache
+
+	mu              sync.RWMutex
+	startContext    context.Context
+	namespaceCaches map[string]*namespaceCache
+	informers       map[schema.GroupVersionKind]*dynamicInformer
+	indexes         []fieldIndex
+}
+
+// Just to ensure we respect the interfaces
+var (
+	_ cache.Cache       = &dynamicNamespacedCache{}
+	_ NamespacesUpdater = &dynamicNamespacedCache{}
+)
+
+// DynamicNamespacedCacheBuilder returns a cache creation function. The
+// created cache watches the passed namespaces, reads the objects of the other
+// namespaces from the operator one, and implements NamespacesUpdater to
+// change the watched namespaces at runtime.
+func DynamicNamespacedCacheBuilder(namespaces []string, operatorNamespace string) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		if opts.Scheme == nil || opts.Mapper == nil {
+			return nil, fmt.Errorf("the dynamic namespaced cache requires a scheme and a REST mapper")
+		}
+
+		// create a cache for cluster scoped resources
+		clusterOpts := opts
+		clusterOpts.Namespace = ""
+		clusterCache, err := cache.New(config, clusterOpts)
+		if err != nil {
+			return nil, fmt.Errorf("error creating cluster scoped cache: %w", err)
+		}
+
+		// create a cache for external resources
+		externalOpts := opts
+		externalOpts.Namespace = operatorNamespace
+		externalCache, err := cache.New(config, externalOpts)
+		if err != nil {
+			return nil, fmt.Errorf("error creating global cache %v", err)
+		}
+
+		c := &dynamicNamespacedCache{
+			config:          config,
+			opts:            opts,
+			clusterCache:    clusterCache,
+			externalCache:   externalCache,
+			namespaceCaches: make(map[string]*namespaceCache),
+			informers:       make(map[schema.GroupVersionKind]*dynamicInformer),
+		}
+
+		for _, namespace := range namespaces {
+			if err := c.addNamespaceCache(context.Background(), namespace); err != nil {
+				return nil, err
+			}
+		}
+
+		return c, nil
+	}
+}
+
+// SetNamespaces implements the NamespacesUpdater interface
+func (c *dynamicNamespacedCache) SetNamespaces(ctx context.Context, namespaces []string) error {
+	contextLog := log.FromContext(ctx)
+
+	c.mu.Lock()
+	added, removed := diffNamespaces(c.getNamespaces(), namespaces)
+
+	for _, namespace := range removed {
+		c.removeNamespaceCache(namespace)
+		contextLog.Info("Stopped watching namespace", "namespace", namespace)
+	}
+
+	for _, namespace := range added {
+		if err := c.addNamespaceCache(ctx, namespace); err != nil {
+			c.mu.Unlock()
+			return err
+		}
+		contextLog.Info("Started watching namespace", "namespace", namespace)
+	}
+	started := c.startContext != nil
+
+	// The namespaces whose cache couldn't be synced by a previous call
+	// are checked again, so that the failure is reported until they are
+	// synced even if the set of watched namespaces doesn't change
+	pending := make(map[string]*namespaceCache)
+	for namespace, entry := range c.namespaceCaches {
+		if !entry.synced {
+			pending[namespace] = entry
+		}
+	}
+	c.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+
+	// The cache of a namespace cannot be synced when the operator is missing
+	// the permissions to watch it: don't wait forever, the informers keep
+	// retrying and the cache will be synced once the permissions are granted
+	syncCtx, cancel := context.WithTimeout(ctx, namespaceSyncTimeout)
+	defer cancel()
+	var notSynced []string
+	for namespace, entry := range pending {
+		if !entry.cache.WaitForCacheSync(syncCtx) {
+			notSynced = append(notSynced, namespace)
+			continue
+		}
+
+		c.mu.Lock()
+		entry.synced = true
+		c.mu.Unlock()
+	}
+
+	if len(notSynced) > 0 {
+		sort.Strings(notSynced)
+		return fmt.Errorf("cannot sync the cache of the namespaces %v", notSynced)
+	}
+
+	return nil
+}
+
+// getNamespaces gets the list of the watched namespaces. The caller
+// must hold the lock
+func (c *dynamicNamespacedCache) getNamespaces() []string {
+	namespaces := make([]string, 0, len(c.namespaceCaches))
+	for namespace := range c.namespaceCaches {
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
+}
+
+// addNamespaceCache creates the cache of a namespace, with the indexes and
+// the informers already requested to this cache, and starts it if this
+// cache is running. The caller must hold the lock
+func (c *dynamicNamespacedCache) addNamespaceCache(ctx context.Context, namespace string) error {
+	opts := c.opts
+	opts.Namespace = namespace
+	namespacedCache, err := cache.New(c.config, opts)
+	if err != nil {
+		return fmt.Errorf("error creating the cache for namespace %s: %w", namespace, err)
+	}
+
+	// The indexes must be added before the informers are started
+	for _, index := range c.indexes {
+		if err := namespacedCache.IndexField(ctx, index.obj, index.field, index.extractValue); err != nil {
+			return err
+		}
+	}
+
+	for gvk, informer := range c.informers {
+		namespacedInformer, err := namespacedCache.GetInformerForKind(ctx, gvk)
+		if err != nil {
+			return err
+		}
+		if err := informer.addNamespace(namespace, namespacedInformer); err != nil {
+			return err
+		}
+	}
+
+	entry := &namespaceCache{cache: namespacedCache}
+	c.namespaceCaches[namespace] = entry
+	if c.startContext != nil {
+		c.startNamespaceCache(namespace, entry)
+	}
+
+	return nil
+}
+
+// removeNamespaceCache stops the cache of a namespace and forgets it. The
+// caller must hold the lock
+func (c *dynamicNamespacedCache) removeNamespaceCache(namespace string) {
+	entry, ok := c.namespaceCaches[namespace]
+	if !ok {
+		return
+	}
+
+	if entry.cancel != nil {
+		entry.cancel()
+	}
+	for _, informer := range c.informers {
+		informer.removeNamespace(namespace)
+	}
+	delete(c.namespaceCaches, namespace)
+}
+
+// startNamespaceCache starts the cache of a namespace. The caller must
+// hold the lock
+func (c *dynamicNamespacedCache) startNamespaceCache(namespace string, entry *namespaceCache) {
+	ctx, cancel := context.WithCancel(c.startContext)
+	entry.cancel = cancel
+	go func() {
+		if err := entry.cache.Start(ctx); err != nil {
+			log.Error(err, "namespaced cache failed to start", "namespace", namespace)
+		}
+	}()
+}
+
+// isNamespaced checks whether the passed object, or list of objects,
+// is namespace scoped
+func (c *dynamicNamespacedCache) isNamespaced(obj runtime.Object) (bool, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.opts.Scheme)
+	if err != nil {
+		return false, err
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	return c.isNamespacedKind(gvk)
+}
+
+// isNamespacedKind checks whether the passed kind is namespace scoped
+func (c *dynamicNamespacedCache) isNamespacedKind(gvk schema.GroupVersionKind) (bool, error) {
+	mapping, err := c.opts.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, err
+	}
+	return mapping.Scope.Name() != apimeta.RESTScopeNameRoot, nil
+}
+
+// Methods for dynamicNamespacedCache to conform to the cache.Informers interface.
+
+func (c *dynamicNamespacedCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.opts.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetInformerForKind(ctx, gvk)
+}
+
+func (c *dynamicNamespacedCache) GetInformerForKind(
+	ctx context.Context, gvk schema.GroupVersionKind,
+) (cache.Informer, error) {
+	isNamespaced, err := c.isNamespacedKind(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if !isNamespaced {
+		return c.clusterCache.GetInformerForKind(ctx, gvk)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if informer, ok := c.informers[gvk]; ok {
+		return informer, nil
+	}
+
+	informer := newDynamicInformer()
+	for namespace, entry := range c.namespaceCaches {
+		namespacedInformer, err := entry.cache.GetInformerForKind(ctx, gvk)
+		if err != nil {
+			return nil, err
+		}
+		if err := informer.addNamespace(namespace, namespacedInformer); err != nil {
+			return nil, err
+		}
+	}
+	c.informers[gvk] = informer
+
+	return informer, nil
+}
+
+func (c *dynamicNamespacedCache) Start(ctx context.Context) error {
+	go func() {
+		if err := c.clusterCache.Start(ctx); err != nil {
+			log.Error(err, "cluster scoped cache failed to start")
+		}
+	}()
+
+	go func() {
+		if err := c.externalCache.Start(ctx); err != nil {
+			log.Error(err, "external cache failed to start")
+		}
+	}()
+
+	c.mu.Lock()
+	c.startContext = ctx
+	for namespace, entry := range c.namespaceCaches {
+		c.startNamespaceCache(namespace, entry)
+	}
+	c.mu.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+func (c *dynamicNamespacedCache) WaitForCacheSync(ctx context.Context) bool {
+	synced := true
+
+	c.mu.RLock()
+	namespacedCaches := make([]cache.Cache, 0, len(c.namespaceCaches))
+	for _, entry := range c.namespaceCaches {
+		namespacedCaches = append(namespacedCaches, entry.cache)
+	}
+	c.mu.RUnlock()
+
+	for _, namespacedCache := range namespacedCaches {
+		if !namespacedCache.WaitForCacheSync(ctx) {
+			synced = false
+		}
+	}
+
+	if !c.clusterCache.WaitForCacheSync(ctx) {
+		synced = false
+	}
+
+	if !c.externalCache.WaitForCacheSync(ctx) {
+		synced = false
+	}
+
+	return synced
+}
+
+func (c *dynamicNamespacedCache) IndexField(
+	ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc,
+) error {
+	isNamespaced, err := c.isNamespaced(obj)
+	if err != nil {
+		return err
+	}
+	if !isNamespaced {
+		return c.clusterCache.IndexField(ctx, obj, field, extractValue)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, entry := range c.namespaceCaches {
+		if err := entry.cache.IndexField(ctx, obj, field, extractValue); err != nil {
+			return err
+		}
+	}
+	c.indexes = append(c.indexes, fieldIndex{obj: obj, field: field, extractValue: extractValue})
+
+	return nil
+}
+
+// Methods for dynamicNamespacedCache to conform to the client.Reader interface.
+
+func (c *dynamicNamespacedCache) Get(
+	ctx context.Context,
+	key client.ObjectKey,
+	obj client.Object,
+	opts ...client.GetOption,
+) error {
+	isNamespaced, err := c.isNamespaced(obj)
+	if err != nil {
+		return err
+	}
+	if !isNamespaced {
+		return c.clusterCache.Get(ctx, key, obj, opts...)
+	}
+
+	// If the object we are looking for is in one of the watched namespaces just use
+	// the cache of that namespace, otherwise we can use the global one
+	c.mu.RLock()
+	entry, ok := c.namespaceCaches[key.Namespace]
+	c.mu.RUnlock()
+	if ok {
+		return entry.cache.Get(ctx, key, obj, opts...)
+	}
+
+	return c.externalCache.Get(ctx, key, obj, opts...)
+}
+
+func (c *dynamicNamespacedCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	isNamespaced, err := c.isNamespaced(list)
+	if err != nil {
+		return err
+	}
+	if !isNamespaced {
+		return c.clusterCache.List(ctx, list, opts...)
+	}
+
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	c.mu.RLock()
+	if listOpts.Namespace != corev1.NamespaceAll {
+		entry, ok := c.namespaceCaches[listOpts.Namespace]
+		c.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("unable to list: %v because of unknown namespace for the cache", listOpts.Namespace)
+		}
+		return entry.cache.List(ctx, list, opts...)
+	}
+	namespacedCaches := make([]cache.Cache, 0, len(c.namespaceCaches))
+	for _, entry := range c.namespaceCaches {
+		namespacedCaches = append(namespacedCaches, entry.cache)
+	}
+	c.mu.RUnlock()
+
+	allItems, err := apimeta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	var resourceVersion string
+	for _, namespacedCache := range namespacedCaches {
+		listObj := list.DeepCopyObject().(client.ObjectList)
+		if err := namespacedCache.List(ctx, listObj, &listOpts); err != nil {
+			return err
+		}
+
+		items, err := apimeta.ExtractList(listObj)
+		if err != nil {
+			return err
+		}
+		allItems = append(allItems, items...)
+		// The last list call should have the most correct resource version.
+		resourceVersion = listObj.GetResourceVersion()
+	}
+
+	list.SetResourceVersion(resourceVersion)
+	return apimeta.SetList(list, allItems)
+}
+
+// diffNamespaces computes the namespaces that need to be added to and
+// removed from the current ones to get the desired ones
+func diffNamespaces(current, desired []string) (added, removed []string) {
+	currentSet := stringset.From(current)
+	desiredSet := stringset.From(desired)
+
+	for _, namespace := range desiredSet.ToList() {
+		if !currentSet.Has(namespace) {
+			added = append(added, namespace)
+		}
+	}
+
+	for _, namespace := range currentSet.ToList() {
+		if !desiredSet.Has(namespace) {
+			removed = append(removed, namespace)
+		}
+	}
+
+	return added, removed
+}
+
+// eventHandler is an event handler registered on a dynamicInformer
+type eventHandler struct {
+	handler      toolscache.ResourceEventHandler
+	resyncPeriod *time.Duration
+}
+
+// dynamicInformer is an informer spanning the watched namespaces, keeping
+// track of the registered event handlers and indexers to apply them
+// to the informers of the namespaces added later
+type dynamicInformer struct {
+	mu        sync.RWMutex
+	informers map[string]cache.Informer
+	handlers  []eventHandler
+	indexers  []toolscache.Indexers
+}
+
+// Just to ensure we respect the interface
+var _ cache.Informer = &dynamicInformer{}
+
+func newDynamicInformer() *dynamicInformer {
+	return &dynamicInformer{
+		informers: make(map[string]cache.Informer),
+	}
+}
+
+// addNamespace adds the informer of a new namespace, applying the
+// registered indexers and event handlers
+func (i *dynamicInformer) addNamespace(namespace string, informer cache.Informer) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, indexers := range i.indexers {
+		if err := informer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+
+	for _, handler := range i.handlers {
+		if handler.resyncPeriod != nil {
+			informer.AddEventHandlerWithResyncPeriod(handler.handler, *handler.resyncPeriod)
+		} else {
+			informer.AddEventHandler(handler.handler)
+		}
+	}
+
+	i.informers[namespace] = informer
+	return nil
+}
+
+// removeNamespace forgets the informer of a namespace
+func (i *dynamicInformer) removeNamespace(namespace string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.informers, namespace)
+}
+
+func (i *dynamicInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.handlers = append(i.handlers, eventHandler{handler: handler})
+	for _, informer := range i.informers {
+		informer.AddEventHandler(handler)
+	}
+}
+
+func (i *dynamicInformer) AddEventHandlerWithResyncPeriod(
+	handler toolscache.ResourceEventHandler, resyncPeriod time.Duration,
+) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.handlers = append(i.handlers, eventHandler{handler: handler, resyncPeriod: &resyncPeriod})
+	for _, informer := range i.informers {
+		informer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	}
+}
+
+func (i *dynamicInformer) AddIndexers(indexers toolscache.Indexers) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, informer := range i.informers {
+		if err := informer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	i.indexers = append(i.indexers, indexers)
+
+	return nil
+}
+
+func (i *dynamicInformer) HasSynced() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	for _, informer := range i.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
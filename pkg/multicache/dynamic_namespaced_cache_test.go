@@ -0,0 +1,240 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicache
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("watched namespaces changes", func() {
+	It("detects the added and the removed namespaces", func() {
+		added, removed := diffNamespaces(
+			[]string{"tenant-a", "tenant-b"},
+			[]string{"tenant-b", "tenant-c", "tenant-d"})
+		Expect(added).To(ConsistOf("tenant-c", "tenant-d"))
+		Expect(removed).To(ConsistOf("tenant-a"))
+	})
+
+	It("detects no changes when the namespaces are the same", func() {
+		added, removed := diffNamespaces(
+			[]string{"tenant-a", "tenant-b"},
+			[]string{"tenant-b", "tenant-a", "tenant-a"})
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(BeEmpty())
+	})
+})
+
+var _ = Describe("dynamic informer", func() {
+	It("applies the registered event handlers to the informers of new namespaces", func() {
+		informer := newDynamicInformer()
+		informer.AddEventHandler(nil)
+
+		namespacedInformer := &fakeInformer{}
+		Expect(informer.addNamespace("tenant-a", namespacedInformer)).To(Succeed())
+		Expect(namespacedInformer.handlers).To(Equal(1))
+
+		informer.AddEventHandler(nil)
+		Expect(namespacedInformer.handlers).To(Equal(2))
+	})
+
+	It("is synced when every namespace informer is synced", func() {
+		informer := newDynamicInformer()
+		Expect(informer.HasSynced()).To(BeTrue())
+
+		Expect(informer.addNamespace("tenant-a", &fakeInformer{synced: true})).To(Succeed())
+		Expect(informer.addNamespace("tenant-b", &fakeInformer{})).To(Succeed())
+		Expect(informer.HasSynced()).To(BeFalse())
+
+		informer.removeNamespace("tenant-b")
+		Expect(informer.HasSynced()).To(BeTrue())
+	})
+})
+
+var _ = Describe("dynamic namespaced cache", func() {
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+
+	// newDynamicNamespacedCache creates a cache that is never started, so
+	// that it doesn't need to contact the API server
+	newDynamicNamespacedCache := func(namespaces ...string) *dynamicNamespacedCache {
+		mapper := apimeta.NewDefaultRESTMapper(nil)
+		mapper.Add(podGVK, apimeta.RESTScopeNamespace)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), apimeta.RESTScopeRoot)
+
+		newCache := DynamicNamespacedCacheBuilder(namespaces, "operator-namespace")
+		c, err := newCache(&rest.Config{Host: "https://localhost"}, cache.Options{
+			Scheme: scheme.Scheme,
+			Mapper: mapper,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return c.(*dynamicNamespacedCache)
+	}
+
+	getDynamicInformer := func(c *dynamicNamespacedCache) *dynamicInformer {
+		informer, err := c.GetInformerForKind(context.TODO(), podGVK)
+		Expect(err).ToNot(HaveOccurred())
+		return informer.(*dynamicInformer)
+	}
+
+	It("requires a scheme and a REST mapper", func() {
+		newCache := DynamicNamespacedCacheBuilder([]string{"tenant-a"}, "operator-namespace")
+		_, err := newCache(&rest.Config{}, cache.Options{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("creates a cache for each watched namespace", func() {
+		c := newDynamicNamespacedCache("tenant-a", "tenant-b")
+		Expect(c.getNamespaces()).To(ConsistOf("tenant-a", "tenant-b"))
+	})
+
+	It("changes the watched namespaces", func() {
+		c := newDynamicNamespacedCache("tenant-a", "tenant-b")
+
+		Expect(c.SetNamespaces(context.TODO(), []string{"tenant-b", "tenant-c"})).To(Succeed())
+		Expect(c.getNamespaces()).To(ConsistOf("tenant-b", "tenant-c"))
+
+		Expect(c.SetNamespaces(context.TODO(), []string{"tenant-b", "tenant-c"})).To(Succeed())
+		Expect(c.getNamespaces()).To(ConsistOf("tenant-b", "tenant-c"))
+	})
+
+	It("adds and removes the informers of the changed namespaces", func() {
+		c := newDynamicNamespacedCache("tenant-a", "tenant-b")
+		informer := getDynamicInformer(c)
+		Expect(informer.informers).To(HaveLen(2))
+		Expect(informer.informers).To(HaveKey("tenant-a"))
+		Expect(informer.informers).To(HaveKey("tenant-b"))
+
+		Expect(c.SetNamespaces(context.TODO(), []string{"tenant-b", "tenant-c"})).To(Succeed())
+		Expect(informer.informers).To(HaveLen(2))
+		Expect(informer.informers).To(HaveKey("tenant-b"))
+		Expect(informer.informers).To(HaveKey("tenant-c"))
+
+		By("returning the same informer after the namespaces changed", func() {
+			Expect(getDynamicInformer(c)).To(BeIdenticalTo(informer))
+		})
+	})
+
+	It("applies the requested indexes to the caches of the added namespaces", func() {
+		c := newDynamicNamespacedCache("tenant-a")
+		Expect(c.IndexField(context.TODO(), &corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		})).To(Succeed())
+
+		Expect(c.SetNamespaces(context.TODO(), []string{"tenant-a", "tenant-b"})).To(Succeed())
+		Expect(c.indexes).To(HaveLen(1))
+
+		// The index is applied when the informer of the new namespace is created,
+		// so the informer of the new namespace already knows it
+		namespacedInformer, err := c.namespaceCaches["tenant-b"].cache.GetInformerForKind(context.TODO(), podGVK)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespacedInformer.(toolscache.SharedIndexInformer).GetIndexer().GetIndexers()).
+			To(HaveKey("field:spec.nodeName"))
+	})
+
+	It("stops the caches of the removed namespaces when running", func() {
+		c := newDynamicNamespacedCache("tenant-a")
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		// Simulate a running cache without starting the informers, which
+		// would need the API server
+		var stopped context.Context
+		c.mu.Lock()
+		c.startContext = ctx
+		stopped, c.namespaceCaches["tenant-a"].cancel = context.WithCancel(ctx)
+		c.mu.Unlock()
+
+		Expect(c.SetNamespaces(context.TODO(), []string{})).To(Succeed())
+		Expect(stopped.Err()).To(MatchError(context.Canceled))
+		Expect(c.getNamespaces()).To(BeEmpty())
+	})
+
+	It("reports the namespaces not synced until their cache is synced", func() {
+		defaultSyncTimeout := namespaceSyncTimeout
+		namespaceSyncTimeout = 100 * time.Millisecond
+		defer func() {
+			namespaceSyncTimeout = defaultSyncTimeout
+		}()
+
+		c := newDynamicNamespacedCache("tenant-a")
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		// Simulate a running cache whose informers can't be synced, as
+		// they are never started
+		c.mu.Lock()
+		c.startContext = ctx
+		c.mu.Unlock()
+
+		By("retrying the sync even if the namespaces didn't change", func() {
+			Expect(c.SetNamespaces(context.TODO(), []string{"tenant-a"})).
+				To(MatchError(ContainSubstring("[tenant-a]")))
+			Expect(c.SetNamespaces(context.TODO(), []string{"tenant-a"})).
+				To(MatchError(ContainSubstring("[tenant-a]")))
+		})
+
+		By("not reporting the namespaces once synced", func() {
+			c.mu.Lock()
+			c.namespaceCaches["tenant-a"].cache = &syncedCache{}
+			c.mu.Unlock()
+
+			Expect(c.SetNamespaces(context.TODO(), []string{"tenant-a"})).To(Succeed())
+			Expect(c.namespaceCaches["tenant-a"].synced).To(BeTrue())
+		})
+	})
+})
+
+// syncedCache is a cache that is always synced
+type syncedCache struct {
+	cache.Cache
+}
+
+func (s *syncedCache) WaitForCacheSync(context.Context) bool {
+	return true
+}
+
+type fakeInformer struct {
+	handlers int
+	synced   bool
+}
+
+func (f *fakeInformer) AddEventHandler(toolscache.ResourceEventHandler) {
+	f.handlers++
+}
+
+func (f *fakeInformer) AddEventHandlerWithResyncPeriod(toolscache.ResourceEventHandler, time.Duration) {
+	f.handlers++
+}
+
+func (f *fakeInformer) AddIndexers(toolscache.Indexers) error {
+	return nil
+}
+
+func (f *fakeInformer) HasSynced() bool {
+	return f.synced
+}
@@ -23,6 +23,8 @@ import (
 	"sort"
 	"strings"
 	"text/template"
+
+	"k8s.io/utils/strings/slices"
 )
 
 const (
@@ -36,6 +38,14 @@ local all all peer map=local
 hostssl postgres streaming_replica all cert
 hostssl replication streaming_replica all cert
 hostssl all cnpg_pooler_pgbouncer all cert
+{{ if .ClientCertificateUsers }}
+
+# Require client certificate authentication for the roles having
+# a client certificate issued by the operator
+{{ range $user := .ClientCertificateUsers -}}
+hostssl all {{ $user }} all cert
+{{ end -}}
+{{ end }}
 
 {{ range $rule := .UserRules }}
 {{ $rule -}}
@@ -224,6 +234,38 @@ type ConfigurationInfo struct {
 
 	// Is this a replica cluster?
 	IsReplicaCluster bool
+
+	// The log destination to be used by the logging collector,
+	// overriding the default one when not empty
+	LogDestination string
+
+	// The TCP keepalive parameters, overriding the ones
+	// set by the user
+	TCPKeepalives map[string]string
+
+	// The WAL retention parameters, overriding the ones
+	// set by the user and the default ones
+	WALKeepSize map[string]string
+
+	// The memory parameters computed from the memory available to
+	// the instances, overriding the ones set by the user
+	MemoryParameters map[string]string
+
+	// The idle_in_transaction_session_timeout parameter, overriding
+	// the one set by the user when not empty
+	IdleInTransactionSessionTimeout string
+
+	// The autovacuum parameters, overriding the ones set by the user
+	Autovacuum map[string]string
+
+	// The wal_compression parameter, overriding the one set
+	// by the user when not empty
+	WALCompression string
+
+	// The user settings that are applied even if they are fixed
+	// parameters, as the user requested not to validate them.
+	// The mandatory settings still take precedence over them
+	ParametersSkippingValidation []string
 }
 
 // ManagedExtension defines all the information about a managed extension
@@ -405,17 +447,19 @@ var (
 
 // CreateHBARules will create the content of pg_hba.conf file given
 // the rules set by the cluster spec
-func CreateHBARules(hba []string,
+func CreateHBARules(hba []string, clientCertificateUsers []string,
 	defaultAuthenticationMethod, ldapConfigString string,
 ) (string, error) {
 	var hbaContent bytes.Buffer
 
 	templateData := struct {
 		UserRules                   []string
+		ClientCertificateUsers      []string
 		LDAPConfiguration           string
 		DefaultAuthenticationMethod string
 	}{
 		UserRules:                   hba,
+		ClientCertificateUsers:      clientCertificateUsers,
 		LDAPConfiguration:           ldapConfigString,
 		DefaultAuthenticationMethod: defaultAuthenticationMethod,
 	}
@@ -495,7 +539,7 @@ func CreatePostgresqlConfiguration(info ConfigurationInfo) *PgConfiguration {
 	// ignoring those which are fixed if ignoreFixedSettingsFromUser is true
 	for key, value := range info.UserSettings {
 		_, isFixed := FixedConfigurationParameters[key]
-		if isFixed && ignoreFixedSettingsFromUser {
+		if isFixed && ignoreFixedSettingsFromUser && !slices.Contains(info.ParametersSkippingValidation, key) {
 			continue
 		}
 		configuration.OverwriteConfig(key, value)
@@ -508,6 +552,41 @@ func CreatePostgresqlConfiguration(info ConfigurationInfo) *PgConfiguration {
 		}
 	}
 
+	// Apply the requested log format
+	if info.LogDestination != "" {
+		configuration.OverwriteConfig("log_destination", info.LogDestination)
+	}
+
+	// Apply the TCP keepalive settings
+	for key, value := range info.TCPKeepalives {
+		configuration.OverwriteConfig(key, value)
+	}
+
+	// Apply the requested WAL retention
+	for key, value := range info.WALKeepSize {
+		configuration.OverwriteConfig(key, value)
+	}
+
+	// Apply the memory parameters
+	for key, value := range info.MemoryParameters {
+		configuration.OverwriteConfig(key, value)
+	}
+
+	// Apply the requested timeout for the sessions idle in transaction
+	if info.IdleInTransactionSessionTimeout != "" {
+		configuration.OverwriteConfig("idle_in_transaction_session_timeout", info.IdleInTransactionSessionTimeout)
+	}
+
+	// Apply the autovacuum settings
+	for key, value := range info.Autovacuum {
+		configuration.OverwriteConfig(key, value)
+	}
+
+	// Apply the requested WAL compression method
+	if info.WALCompression != "" {
+		configuration.OverwriteConfig("wal_compression", info.WALCompression)
+	}
+
 	// Apply the correct archive_mode
 	if info.IsReplicaCluster {
 		configuration.OverwriteConfig("archive_mode", "always")
@@ -583,17 +662,26 @@ func setUserSharedPreloadLibraries(info ConfigurationInfo, configuration *PgConf
 	}
 }
 
+// GetSynchronousStandbyNames computes the value of synchronous_standby_names
+// requiring syncReplicas synchronous standbys among the electable ones.
+// An empty string is returned when synchronous replication is not required
+func GetSynchronousStandbyNames(syncReplicas int, electable []string) string {
+	if electable == nil || syncReplicas <= 0 {
+		return ""
+	}
+
+	escapedReplicas := make([]string, len(electable))
+	for idx, name := range electable {
+		escapedReplicas[idx] = escapePostgresConfLiteral(name)
+	}
+	return fmt.Sprintf("ANY %v (%v)", syncReplicas, strings.Join(escapedReplicas, ","))
+}
+
 // setReplicasListConfigurations sets the standby node list
 func setReplicasListConfigurations(info ConfigurationInfo, configuration *PgConfiguration) {
-	if info.SyncReplicasElectable != nil && info.SyncReplicas > 0 {
-		escapedReplicas := make([]string, len(info.SyncReplicasElectable))
-		for idx, name := range info.SyncReplicasElectable {
-			escapedReplicas[idx] = escapePostgresConfLiteral(name)
-		}
-		configuration.OverwriteConfig(SynchronousStandbyNames, fmt.Sprintf(
-			"ANY %v (%v)",
-			info.SyncReplicas,
-			strings.Join(escapedReplicas, ",")))
+	if synchronousStandbyNames := GetSynchronousStandbyNames(
+		info.SyncReplicas, info.SyncReplicasElectable); synchronousStandbyNames != "" {
+		configuration.OverwriteConfig(SynchronousStandbyNames, synchronousStandbyNames)
 	}
 
 	if info.ClusterName != "" {
@@ -145,6 +145,142 @@ var _ = Describe("PostgreSQL configuration creation", func() {
 		})
 	})
 
+	When("a log destination is requested", func() {
+		It("will override the default one", func() {
+			info := ConfigurationInfo{
+				Settings:           CnpgConfigurationSettings,
+				MajorVersion:       150000,
+				UserSettings:       settings,
+				IncludingMandatory: true,
+				LogDestination:     "jsonlog",
+			}
+			config := CreatePostgresqlConfiguration(info)
+			Expect(config.GetConfig("log_destination")).To(Equal("jsonlog"))
+		})
+
+		It("will keep csvlog when not specified", func() {
+			info := ConfigurationInfo{
+				Settings:           CnpgConfigurationSettings,
+				MajorVersion:       150000,
+				UserSettings:       settings,
+				IncludingMandatory: true,
+			}
+			config := CreatePostgresqlConfiguration(info)
+			Expect(config.GetConfig("log_destination")).To(Equal("csvlog"))
+		})
+	})
+
+	When("TCP keepalives are requested", func() {
+		It("will override the user settings", func() {
+			info := ConfigurationInfo{
+				Settings:     CnpgConfigurationSettings,
+				MajorVersion: 150000,
+				UserSettings: map[string]string{
+					"tcp_keepalives_idle": "600",
+				},
+				IncludingMandatory: true,
+				TCPKeepalives: map[string]string{
+					"tcp_keepalives_idle":  "60",
+					"tcp_keepalives_count": "5",
+				},
+			}
+			config := CreatePostgresqlConfiguration(info)
+			Expect(config.GetConfig("tcp_keepalives_idle")).To(Equal("60"))
+			Expect(config.GetConfig("tcp_keepalives_count")).To(Equal("5"))
+		})
+	})
+
+	When("a WAL retention is requested", func() {
+		It("will override the default and the user settings", func() {
+			info := ConfigurationInfo{
+				Settings:     CnpgConfigurationSettings,
+				MajorVersion: 150000,
+				UserSettings: map[string]string{
+					"wal_keep_size": "1GB",
+				},
+				IncludingMandatory: true,
+				WALKeepSize: map[string]string{
+					"wal_keep_size": "2048MB",
+				},
+			}
+			config := CreatePostgresqlConfiguration(info)
+			Expect(config.GetConfig("wal_keep_size")).To(Equal("2048MB"))
+		})
+	})
+
+	When("memory parameters are expressed as a percentage", func() {
+		It("will override the default and the user settings", func() {
+			info := ConfigurationInfo{
+				Settings:     CnpgConfigurationSettings,
+				MajorVersion: 150000,
+				UserSettings: map[string]string{
+					"shared_buffers":       "128MB",
+					"effective_cache_size": "4GB",
+				},
+				IncludingMandatory: true,
+				MemoryParameters: map[string]string{
+					"shared_buffers":       "1024MB",
+					"effective_cache_size": "3072MB",
+				},
+			}
+			config := CreatePostgresqlConfiguration(info)
+			Expect(config.GetConfig("shared_buffers")).To(Equal("1024MB"))
+			Expect(config.GetConfig("effective_cache_size")).To(Equal("3072MB"))
+		})
+	})
+
+	When("a timeout for the sessions idle in transaction is requested", func() {
+		It("will override the user settings", func() {
+			info := ConfigurationInfo{
+				Settings:     CnpgConfigurationSettings,
+				MajorVersion: 150000,
+				UserSettings: map[string]string{
+					"idle_in_transaction_session_timeout": "1h",
+				},
+				IncludingMandatory:              true,
+				IdleInTransactionSessionTimeout: "300s",
+			}
+			config := CreatePostgresqlConfiguration(info)
+			Expect(config.GetConfig("idle_in_transaction_session_timeout")).To(Equal("300s"))
+		})
+	})
+
+	When("a WAL compression method is requested", func() {
+		It("will override the user settings", func() {
+			info := ConfigurationInfo{
+				Settings:     CnpgConfigurationSettings,
+				MajorVersion: 150000,
+				UserSettings: map[string]string{
+					"wal_compression": "on",
+				},
+				IncludingMandatory: true,
+				WALCompression:     "zstd",
+			}
+			config := CreatePostgresqlConfiguration(info)
+			Expect(config.GetConfig("wal_compression")).To(Equal("zstd"))
+		})
+	})
+
+	When("autovacuum settings are requested", func() {
+		It("will override the user settings", func() {
+			info := ConfigurationInfo{
+				Settings:     CnpgConfigurationSettings,
+				MajorVersion: 150000,
+				UserSettings: map[string]string{
+					"autovacuum_max_workers":         "10",
+					"autovacuum_vacuum_scale_factor": "0.2",
+				},
+				IncludingMandatory: true,
+				Autovacuum: map[string]string{
+					"autovacuum_max_workers": "5",
+				},
+			}
+			config := CreatePostgresqlConfiguration(info)
+			Expect(config.GetConfig("autovacuum_max_workers")).To(Equal("5"))
+			Expect(config.GetConfig("autovacuum_vacuum_scale_factor")).To(Equal("0.2"))
+		})
+	})
+
 	It("adds shared_preload_library correctly", func() {
 		info := ConfigurationInfo{
 			Settings:                         CnpgConfigurationSettings,
@@ -179,6 +315,11 @@ var _ = Describe("PostgreSQL configuration creation", func() {
 			Expect(config.GetConfig("synchronous_standby_names")).
 				To(Equal("ANY 2 (\"one\",\"two\",\"three\")"))
 		})
+
+		It("doesn't require synchronous standbys when none can be elected", func() {
+			Expect(GetSynchronousStandbyNames(1, nil)).To(BeEmpty())
+			Expect(GetSynchronousStandbyNames(0, []string{"one"})).To(BeEmpty())
+		})
 	})
 
 	It("checks if PreserveFixedSettingsFromUser works properly", func() {
@@ -223,6 +364,22 @@ var _ = Describe("PostgreSQL configuration creation", func() {
 			Expect(config.GetConfig("recovery_target_name")).To(Equal(""))
 		})
 	})
+
+	It("applies the fixed parameters whose validation has been skipped", func() {
+		info := ConfigurationInfo{
+			Settings:     CnpgConfigurationSettings,
+			MajorVersion: 100000,
+			UserSettings: map[string]string{
+				"ssl":                  "off",
+				"recovery_target_name": "test",
+			},
+			IncludingMandatory:           true,
+			ParametersSkippingValidation: []string{"ssl", "recovery_target_name"},
+		}
+		config := CreatePostgresqlConfiguration(info)
+		Expect(config.GetConfig("recovery_target_name")).To(Equal("test"))
+		Expect(config.GetConfig("ssl")).To(Equal("on"))
+	})
 })
 
 var _ = Describe("pg_hba.conf generation", func() {
@@ -233,19 +390,31 @@ var _ = Describe("pg_hba.conf generation", func() {
 	}
 
 	It("insert the spec configuration between an header and a footer when the version can not be parsed", func() {
-		Expect(CreateHBARules(specRules, "md5", "")).To(
+		Expect(CreateHBARules(specRules, nil, "md5", "")).To(
 			ContainSubstring("\ntwo\n"))
 	})
 
 	It("really use the passed default authentication method", func() {
-		Expect(CreateHBARules(specRules, "this-one", "")).To(
+		Expect(CreateHBARules(specRules, nil, "this-one", "")).To(
 			ContainSubstring("\nhost all all all this-one\n"))
 	})
 
 	It("really uses the ldapConfigString", func() {
-		Expect(CreateHBARules(specRules, "defaultAuthenticationMethod", "ldapConfigString")).To(
+		Expect(CreateHBARules(specRules, nil, "defaultAuthenticationMethod", "ldapConfigString")).To(
 			ContainSubstring("\nldapConfigString\n"))
 	})
+
+	It("requires certificate authentication for the client certificate users", func() {
+		hba, err := CreateHBARules(specRules, []string{"app", "reporting"}, "md5", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hba).To(ContainSubstring("\nhostssl all app all cert\nhostssl all reporting all cert\n"))
+		Expect(strings.Index(hba, "hostssl all app all cert")).To(BeNumerically("<", strings.Index(hba, "\none\n")))
+	})
+
+	It("doesn't add certificate authentication rules when no client certificate user is defined", func() {
+		Expect(CreateHBARules(specRules, nil, "md5", "")).ToNot(
+			ContainSubstring("client certificate issued by the operator"))
+	})
 })
 
 var _ = Describe("pgaudit", func() {
@@ -19,6 +19,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -64,6 +65,27 @@ type PostgresqlStatus struct {
 	// Is the number of '.ready' wal files contained in the wal archive folder
 	ReadyWALFiles int `json:"readyWalFiles,omitempty"`
 
+	// The status of the WAL archiving into the additional object stores,
+	// indexed by destination name, as recorded by the wal-archive command
+	WALArchiveDestinations map[string]WALArchiveDestinationStatus `json:"walArchiveDestinations,omitempty"`
+
+	// The size in bytes of the WAL segments
+	// SELECT pg_size_bytes(current_setting('wal_segment_size'))
+	WALSegmentSize int64 `json:"walSegmentSize,omitempty"`
+
+	// Whether the data checksums are enabled, nil when not reported
+	// by the instance manager
+	// SELECT current_setting('data_checksums') = 'on'
+	DataChecksums *bool `json:"dataChecksums,omitempty"`
+
+	// The encoding, collation and character classification of the database
+	// the instance manager connects to, which are the ones chosen at initdb time
+	// SELECT pg_encoding_to_char(encoding), datcollate, datctype
+	// FROM pg_database WHERE datname = current_database()
+	Encoding      string `json:"encoding,omitempty"`
+	LocaleCollate string `json:"localeCollate,omitempty"`
+	LocaleCType   string `json:"localeCType,omitempty"`
+
 	// The current timeline ID
 	// SELECT timeline_id FROM pg_control_checkpoint()
 	TimeLineID int `json:"timeLineID,omitempty"`
@@ -81,6 +103,98 @@ type PostgresqlStatus struct {
 
 	// contains the PgStatReplication rows content.
 	ReplicationInfo PgStatReplicationList `json:"replicationInfo,omitempty"`
+
+	// The names of the parameters whose new value will
+	// be applied only after a restart of the instance
+	PendingRestartSettings []string `json:"pendingRestartSettings,omitempty"`
+
+	// The shared preload libraries, waiting for a restart to be
+	// loaded, which can't be found in the instance
+	MissingSharedPreloadLibraries []string `json:"missingSharedPreloadLibraries,omitempty"`
+
+	// The convergence of the HA replication slots of a replica
+	// with the ones in the primary, as seen by the slot replicator
+	SlotsConvergence *SlotsConvergence `json:"slotsConvergence,omitempty"`
+
+	// True when the primary has the user and the function used by
+	// the auth_query of PgBouncer in every database
+	PgBouncerIntegrationReady bool `json:"pgBouncerIntegrationReady,omitempty"`
+
+	// The replication slots existing in the instance
+	ReplicationSlots []PgReplicationSlot `json:"replicationSlots,omitempty"`
+}
+
+// SlotsConvergence reports whether the HA replication slots of a replica
+// were aligned with the ones in the primary during the last
+// synchronization made by the slot replicator
+type SlotsConvergence struct {
+	// True when the last synchronization succeeded and found every
+	// slot in the replica already aligned with the primary
+	Converged bool `json:"converged"`
+
+	// The slots existing in the primary but not in the replica
+	MissingSlots []string `json:"missingSlots,omitempty"`
+
+	// The slots existing in the replica but not in the primary
+	ExtraSlots []string `json:"extraSlots,omitempty"`
+
+	// The slots existing in the replica but not in the primary which
+	// are kept disabled, as requested by the extra slots policy
+	DisabledSlots []string `json:"disabledSlots,omitempty"`
+
+	// True when the synchronization is paused, and the differences
+	// with the primary have not been applied
+	Paused bool `json:"paused,omitempty"`
+
+	// The error raised by the last synchronization, if any
+	Error string `json:"error,omitempty"`
+
+	// When the last synchronization happened
+	LastSynchronization time.Time `json:"lastSynchronization"`
+}
+
+// WALArchiveDestinationStatus is the result of the WAL archiving into
+// an additional object store
+type WALArchiveDestinationStatus struct {
+	LastArchivedWAL  string `json:"lastArchivedWAL,omitempty"`
+	LastArchivedTime string `json:"lastArchivedTime,omitempty"`
+	LastFailedWAL    string `json:"lastFailedWAL,omitempty"`
+	LastFailedTime   string `json:"lastFailedTime,omitempty"`
+	LastFailedError  string `json:"lastFailedError,omitempty"`
+}
+
+// AreSlotsConverged checks if the HA replication slots of a replica are
+// known to be aligned with the ones in the primary. Instances not
+// reporting the convergence status are considered converged
+func (status PostgresqlStatus) AreSlotsConverged() bool {
+	return status.SlotsConvergence == nil || status.SlotsConvergence.Converged
+}
+
+// PgSetting contains the value of a configuration parameter, as
+// loaded by the running PostgreSQL instance
+type PgSetting struct {
+	Name           string `json:"name"`
+	Setting        string `json:"setting"`
+	Unit           string `json:"unit,omitempty"`
+	Source         string `json:"source"`
+	PendingRestart bool   `json:"pendingRestart"`
+}
+
+// PgReplicationSlot contains the state of a replication slot, as
+// reported by the running PostgreSQL instance
+type PgReplicationSlot struct {
+	SlotName          string `json:"slotName"`
+	Plugin            string `json:"plugin,omitempty"`
+	SlotType          string `json:"slotType"`
+	Database          string `json:"database,omitempty"`
+	Temporary         bool   `json:"temporary"`
+	Active            bool   `json:"active"`
+	RestartLsn        LSN    `json:"restartLsn,omitempty"`
+	ConfirmedFlushLsn LSN    `json:"confirmedFlushLsn,omitempty"`
+
+	// The size in bytes of the WAL files retained by the slot, from its
+	// restart_lsn to the current position of the instance
+	RetainedWALSize int64 `json:"retainedWALSize"`
 }
 
 // PgStatReplication contains the replications of replicas as reported by the primary instance
@@ -228,6 +342,15 @@ func (list *PostgresqlStatusList) Less(i, j int) bool {
 		return !list.Items[i].ReplayLsn.Less(list.Items[j].ReplayLsn)
 	}
 
+	// Replicas whose replication slots are diverged from
+	// the primary ones are less suitable to be promoted
+	switch {
+	case list.Items[i].AreSlotsConverged() && !list.Items[j].AreSlotsConverged():
+		return true
+	case !list.Items[i].AreSlotsConverged() && list.Items[j].AreSlotsConverged():
+		return false
+	}
+
 	return list.Items[i].Pod.Name < list.Items[j].Pod.Name
 }
 
@@ -258,6 +381,18 @@ func (list PostgresqlStatusList) IsPodReporting(podname string) bool {
 	return false
 }
 
+// IsPodInRecovery checks if the given pod is correctly reporting its status
+// and is running as a standby server
+func (list PostgresqlStatusList) IsPodInRecovery(podname string) bool {
+	for _, item := range list.Items {
+		if item.Pod.Name == podname {
+			return item.Error == nil && !item.IsPrimary
+		}
+	}
+
+	return false
+}
+
 // IsComplete checks the PostgreSQL status list for Pods which
 // contain errors. Returns true if everything is green and
 // false otherwise
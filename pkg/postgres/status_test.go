@@ -93,6 +93,13 @@ var _ = Describe("PostgreSQL status", func() {
 		Expect(greenList.IsComplete()).To(BeTrue())
 	})
 
+	It("checks for pods running as standby servers", func() {
+		Expect(list.IsPodInRecovery("server-30")).To(BeTrue())
+		Expect(list.IsPodInRecovery("server-10")).To(BeFalse())
+		Expect(list.IsPodInRecovery("server-04")).To(BeFalse())
+		Expect(list.IsPodInRecovery("server-99")).To(BeFalse())
+	})
+
 	It("checks for pods on which we are upgrading the instance manager", func() {
 		podList := PostgresqlStatusList{
 			Items: []PostgresqlStatus{
@@ -200,3 +207,33 @@ var _ = Describe("PostgreSQL status real", func() {
 		})
 	})
 })
+
+var _ = Describe("replication slots convergence", func() {
+	It("prefers the replicas whose slots are converged when they are equally updated", func() {
+		list := PostgresqlStatusList{
+			Items: []PostgresqlStatus{
+				{
+					Pod:              corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "server-1"}},
+					IsReady:          true,
+					ReceivedLsn:      "0/3000000",
+					ReplayLsn:        "0/3000000",
+					SlotsConvergence: &SlotsConvergence{Converged: false, MissingSlots: []string{"_cnpg_server_3"}},
+				},
+				{
+					Pod:              corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "server-2"}},
+					IsReady:          true,
+					ReceivedLsn:      "0/3000000",
+					ReplayLsn:        "0/3000000",
+					SlotsConvergence: &SlotsConvergence{Converged: true},
+				},
+			},
+		}
+		sort.Sort(&list)
+		Expect(list.Items[0].Pod.Name).To(Equal("server-2"))
+	})
+
+	It("considers converged the instances not reporting the slots status", func() {
+		Expect(PostgresqlStatus{}.AreSlotsConverged()).To(BeTrue())
+		Expect(PostgresqlStatus{SlotsConvergence: &SlotsConvergence{}}.AreSlotsConverged()).To(BeFalse())
+	})
+})
@@ -149,6 +149,19 @@ func (segment Segment) Name() string {
 	return fmt.Sprintf("%08X%08X%08X", segment.Tli, segment.Log, segment.Seg)
 }
 
+// EndLSN gets the LSN where the segment ends, which is the first LSN
+// of the following segment. If segmentSize == nil,
+// wal_segment_size=DefaultWALSegmentSize is assumed.
+func (segment Segment) EndLSN(segmentSize *int64) LSN {
+	size := DefaultWALSegmentSize
+	if segmentSize != nil {
+		size = *segmentSize
+	}
+
+	position := uint64(uint32(segment.Log))<<32 + (uint64(uint32(segment.Seg))+1)*uint64(size)
+	return LSN(fmt.Sprintf("%X/%X", position>>32, uint32(position)))
+}
+
 // WalSegmentsPerFile is the number of WAL Segments in a WAL File
 func WalSegmentsPerFile(walSegmentSize int64) int32 {
 	// Given that segment section is represented by 8 hex characters,
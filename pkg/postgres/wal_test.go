@@ -188,6 +188,14 @@ var _ = Describe("Segment name parsing and generation", func() {
 				test.start.Name(), test.size, test.version, test.walSize)
 		}
 	})
+
+	It("can compute the LSN where a segment ends", func() {
+		walSize64MB := int64(1 << 26)
+
+		Expect(MustSegmentFromName("000000010000000000000001").EndLSN(nil)).To(Equal(LSN("0/2000000")))
+		Expect(MustSegmentFromName("0000000200000001000000FF").EndLSN(nil)).To(Equal(LSN("2/0")))
+		Expect(MustSegmentFromName("000000010000000100000002").EndLSN(&walSize64MB)).To(Equal(LSN("1/C000000")))
+	})
 })
 
 var _ = Describe("WAL files checking", func() {
@@ -114,6 +114,9 @@ func buildInitDBFlags(cluster apiv1.Cluster) (initCommand []string) {
 	if encoding := config.Encoding; encoding != "" {
 		options = append(options, fmt.Sprintf("--encoding=%s", encoding))
 	}
+	if locale := config.Locale; locale != "" {
+		options = append(options, fmt.Sprintf("--locale=%s", locale))
+	}
 	if localeCollate := config.LocaleCollate; localeCollate != "" {
 		options = append(options, fmt.Sprintf("--lc-collate=%s", localeCollate))
 	}
@@ -250,13 +253,14 @@ func createPrimaryJob(cluster apiv1.Cluster, nodeSerial int, role string, initCo
 							SecurityContext: CreateContainerSecurityContext(),
 						},
 					},
-					Volumes:            createPostgresVolumes(cluster, instanceName),
-					SecurityContext:    CreatePodSecurityContext(cluster.GetPostgresUID(), cluster.GetPostgresGID()),
-					Affinity:           CreateAffinitySection(cluster.Name, cluster.Spec.Affinity),
-					Tolerations:        cluster.Spec.Affinity.Tolerations,
-					ServiceAccountName: cluster.Name,
-					RestartPolicy:      corev1.RestartPolicyNever,
-					NodeSelector:       cluster.Spec.Affinity.NodeSelector,
+					Volumes:                   createPostgresVolumes(cluster, instanceName),
+					SecurityContext:           CreatePodSecurityContext(cluster.GetPostgresUID(), cluster.GetPostgresGID()),
+					Affinity:                  CreateAffinitySection(cluster.Name, cluster.Spec.Affinity),
+					TopologySpreadConstraints: CreateTopologySpreadConstraints(cluster),
+					Tolerations:               cluster.Spec.Affinity.Tolerations,
+					ServiceAccountName:        cluster.Name,
+					RestartPolicy:             corev1.RestartPolicyNever,
+					NodeSelector:              cluster.GetInstanceNodeSelector(nodeSerial),
 				},
 			},
 		},
@@ -18,9 +18,11 @@ package specs
 
 import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
+	"github.com/cloudnative-pg/cloudnative-pg/pkg/certs"
 	"github.com/cloudnative-pg/cloudnative-pg/pkg/utils"
 )
 
@@ -32,15 +34,34 @@ func CreatePodMonitor(cluster *apiv1.Cluster) *monitoringv1.PodMonitor {
 	}
 	utils.LabelClusterName(&meta, cluster.Name)
 
+	endpoint := monitoringv1.PodMetricsEndpoint{
+		Port: "metrics",
+	}
+
+	if cluster.IsMetricsTLSEnabled() {
+		// The metrics are served using the server certificate, so we
+		// trust the server CA and check one of the names it is issued for
+		endpoint.Scheme = "https"
+		endpoint.TLSConfig = &monitoringv1.PodMetricsEndpointTLSConfig{
+			SafeTLSConfig: monitoringv1.SafeTLSConfig{
+				CA: monitoringv1.SecretOrConfigMap{
+					Secret: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: cluster.GetServerCASecretName(),
+						},
+						Key: certs.CACertKey,
+					},
+				},
+				ServerName: cluster.GetServiceReadWriteName(),
+			},
+		}
+	}
+
 	spec := monitoringv1.PodMonitorSpec{
 		Selector: metav1.LabelSelector{
 			MatchLabels: meta.Labels,
 		},
-		PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{
-			{
-				Port: "metrics",
-			},
-		},
+		PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{endpoint},
 	}
 
 	return &monitoringv1.PodMonitor{
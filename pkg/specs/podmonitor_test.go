@@ -44,4 +44,25 @@ var _ = Describe("PodMonitor test", func() {
 		Expect(monitor.Spec.Selector.MatchLabels[utils.ClusterLabelName]).To(Equal(clusterName))
 		Expect(monitor.Spec.PodMetricsEndpoints).To(ContainElement(monitoringv1.PodMetricsEndpoint{Port: "metrics"}))
 	})
+	It("should scrape the metrics over TLS when enabled", func() {
+		cluster := v1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test-namespace",
+				Name:      "test",
+			},
+			Spec: v1.ClusterSpec{
+				Monitoring: &v1.MonitoringConfiguration{
+					EnablePodMonitor: true,
+					TLSConfig:        &v1.ClusterMonitoringTLSConfiguration{Enabled: true},
+				},
+			},
+		}
+		monitor := CreatePodMonitor(&cluster)
+		Expect(monitor.Spec.PodMetricsEndpoints).To(HaveLen(1))
+		endpoint := monitor.Spec.PodMetricsEndpoints[0]
+		Expect(endpoint.Scheme).To(Equal("https"))
+		Expect(endpoint.TLSConfig.CA.Secret.Name).To(Equal(cluster.GetServerCASecretName()))
+		Expect(endpoint.TLSConfig.CA.Secret.Key).To(Equal("ca.crt"))
+		Expect(endpoint.TLSConfig.ServerName).To(Equal("test-rw"))
+	})
 })
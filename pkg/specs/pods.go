@@ -42,7 +42,7 @@ const (
 
 	// ClusterRestartAnnotationName is the name of the annotation containing the
 	// latest required restart time
-	ClusterRestartAnnotationName = "kubectl.kubernetes.io/restartedAt"
+	ClusterRestartAnnotationName = MetadataNamespace + "/restartedAt"
 
 	// ClusterReloadAnnotationName is the name of the annotation containing the
 	// latest required restart time
@@ -57,6 +57,10 @@ const (
 	// ClusterRoleLabelReplica is written in labels to represent replica servers
 	ClusterRoleLabelReplica = "replica"
 
+	// ClusterCaughtUpLabelName label is applied to the replicas to mark the
+	// ones whose lag is within the limit set for the read-only service
+	ClusterCaughtUpLabelName = MetadataNamespace + "/caughtUp"
+
 	// WatchedLabelName label is for Secrets or ConfigMaps that needs to be reloaded
 	WatchedLabelName = MetadataNamespace + "/reload"
 
@@ -87,6 +91,10 @@ const (
 
 	// ReadinessProbePeriod is the period set for the postgres instance readiness probe
 	ReadinessProbePeriod = 10
+
+	// MetricsTLSEnvVar is the environment variable telling the instance
+	// manager to serve the metrics over TLS
+	MetricsTLSEnvVar = "METRICS_TLS"
 )
 
 func createEnvVarPostgresContainer(cluster apiv1.Cluster, podName string) []corev1.EnvVar {
@@ -117,6 +125,13 @@ func createEnvVarPostgresContainer(cluster apiv1.Cluster, podName string) []core
 		},
 	}
 
+	if cluster.IsMetricsTLSEnabled() {
+		envVar = append(envVar, corev1.EnvVar{
+			Name:  MetricsTLSEnvVar,
+			Value: "true",
+		})
+	}
+
 	return envVar
 }
 
@@ -223,6 +238,29 @@ func CreateAffinitySection(clusterName string, config apiv1.AffinityConfiguratio
 	return affinity
 }
 
+// CreateTopologySpreadConstraints creates the topology spread constraints
+// for Pods, given the configuration from the user. Constraints without a
+// label selector are applied to the instances of the cluster
+func CreateTopologySpreadConstraints(cluster apiv1.Cluster) []corev1.TopologySpreadConstraint {
+	if len(cluster.Spec.TopologySpreadConstraints) == 0 {
+		return nil
+	}
+
+	result := make([]corev1.TopologySpreadConstraint, len(cluster.Spec.TopologySpreadConstraints))
+	for idx := range cluster.Spec.TopologySpreadConstraints {
+		cluster.Spec.TopologySpreadConstraints[idx].DeepCopyInto(&result[idx])
+		if result[idx].LabelSelector == nil {
+			result[idx].LabelSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					ClusterLabelName: cluster.Name,
+				},
+			}
+		}
+	}
+
+	return result
+}
+
 // CreateGeneratedAntiAffinity generates the affinity terms the operator is in charge for if enabled,
 // return nil if disabled or an error occurred, as invalid values should be validated before this method is called
 func CreateGeneratedAntiAffinity(clusterName string, config apiv1.AffinityConfiguration) *corev1.Affinity {
@@ -295,7 +333,7 @@ func CreatePodSecurityContext(user, group int64) *corev1.PodSecurityContext {
 // PodWithExistingStorage create a new instance with an existing storage
 func PodWithExistingStorage(cluster apiv1.Cluster, nodeSerial int) *corev1.Pod {
 	podName := GetInstanceName(cluster.Name, nodeSerial)
-	gracePeriod := int64(cluster.GetMaxStopDelay())
+	gracePeriod := cluster.GetTerminationGracePeriodSeconds()
 
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -321,9 +359,10 @@ func PodWithExistingStorage(cluster apiv1.Cluster, nodeSerial int) *corev1.Pod {
 			Volumes:                       createPostgresVolumes(cluster, podName),
 			SecurityContext:               CreatePodSecurityContext(cluster.GetPostgresUID(), cluster.GetPostgresGID()),
 			Affinity:                      CreateAffinitySection(cluster.Name, cluster.Spec.Affinity),
+			TopologySpreadConstraints:     CreateTopologySpreadConstraints(cluster),
 			Tolerations:                   cluster.Spec.Affinity.Tolerations,
 			ServiceAccountName:            cluster.Name,
-			NodeSelector:                  cluster.Spec.Affinity.NodeSelector,
+			NodeSelector:                  cluster.GetInstanceNodeSelector(nodeSerial),
 			TerminationGracePeriodSeconds: &gracePeriod,
 		},
 	}
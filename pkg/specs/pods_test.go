@@ -247,3 +247,80 @@ var _ = Describe("Create affinity section", func() {
 		})
 	})
 })
+
+var _ = Describe("Topology spread constraints and node selectors", func() {
+	cluster := v1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-test"},
+		Spec: v1.ClusterSpec{
+			Affinity: v1.AffinityConfiguration{
+				NodeSelector: map[string]string{"workload": "postgres"},
+				InstanceNodeSelectors: []v1.InstanceNodeSelector{
+					{
+						InstanceSerial: 2,
+						NodeSelector:   map[string]string{"topology.kubernetes.io/zone": "zone-b"},
+					},
+				},
+			},
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+				{
+					MaxSkew:           1,
+					TopologyKey:       "topology.kubernetes.io/zone",
+					WhenUnsatisfiable: corev1.DoNotSchedule,
+				},
+				{
+					MaxSkew:           1,
+					TopologyKey:       "kubernetes.io/hostname",
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+					LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				},
+			},
+		},
+	}
+
+	It("doesn't generate constraints when none are requested", func() {
+		Expect(CreateTopologySpreadConstraints(v1.Cluster{})).To(BeNil())
+	})
+
+	It("applies the constraints without a label selector to the cluster instances", func() {
+		constraints := CreateTopologySpreadConstraints(cluster)
+		Expect(constraints).To(HaveLen(2))
+		Expect(constraints[0].LabelSelector.MatchLabels).To(Equal(map[string]string{
+			ClusterLabelName: "cluster-test",
+		}))
+		Expect(constraints[1].LabelSelector.MatchLabels).To(Equal(map[string]string{"app": "test"}))
+		Expect(cluster.Spec.TopologySpreadConstraints[0].LabelSelector).To(BeNil())
+	})
+
+	It("sets the constraints and the node selector of each instance", func() {
+		firstPod := PodWithExistingStorage(cluster, 1)
+		Expect(firstPod.Spec.TopologySpreadConstraints).To(HaveLen(2))
+		Expect(firstPod.Spec.NodeSelector).To(Equal(map[string]string{"workload": "postgres"}))
+
+		secondPod := PodWithExistingStorage(cluster, 2)
+		Expect(secondPod.Spec.NodeSelector).To(Equal(map[string]string{
+			"workload":                    "postgres",
+			"topology.kubernetes.io/zone": "zone-b",
+		}))
+	})
+})
+
+var _ = Describe("termination grace period", func() {
+	It("leaves a margin after the shutdown timeout by default", func() {
+		cluster := v1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-test", Namespace: "default"},
+			Spec:       v1.ClusterSpec{ShutdownTimeout: 120},
+		}
+		pod := PodWithExistingStorage(cluster, 1)
+		Expect(*pod.Spec.TerminationGracePeriodSeconds).To(BeEquivalentTo(120 + v1.DefaultTerminationGracePeriodMargin))
+	})
+
+	It("uses the requested termination grace period", func() {
+		gracePeriod := int64(600)
+		cluster := v1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-test", Namespace: "default"},
+			Spec:       v1.ClusterSpec{TerminationGracePeriodSeconds: &gracePeriod},
+		}
+		pod := PodWithExistingStorage(cluster, 1)
+		Expect(*pod.Spec.TerminationGracePeriodSeconds).To(BeEquivalentTo(600))
+	})
+})
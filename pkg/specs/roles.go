@@ -54,6 +54,12 @@ func CreateRole(cluster apiv1.Cluster, backupOrigin *apiv1.Backup) rbacv1.Role {
 	involvedSecretNames = append(involvedSecretNames, backupSecrets(cluster, backupOrigin)...)
 	involvedSecretNames = append(involvedSecretNames, externalClusterSecrets(cluster)...)
 
+	for _, configuration := range barmanObjectStores(cluster) {
+		secrets, configMaps := barmanEnvReferences(configuration)
+		involvedSecretNames = append(involvedSecretNames, secrets...)
+		involvedConfigMapNames = append(involvedConfigMapNames, configMaps...)
+	}
+
 	rules := []rbacv1.PolicyRule{
 		{
 			APIGroups: []string{
@@ -163,6 +169,55 @@ func CreateRole(cluster apiv1.Cluster, backupOrigin *apiv1.Backup) rbacv1.Role {
 	}
 }
 
+// barmanObjectStores returns every object store configured in the cluster
+func barmanObjectStores(cluster apiv1.Cluster) []*apiv1.BarmanObjectStoreConfiguration {
+	var result []*apiv1.BarmanObjectStoreConfiguration
+
+	if cluster.Spec.Backup != nil {
+		if cluster.Spec.Backup.BarmanObjectStore != nil {
+			result = append(result, cluster.Spec.Backup.BarmanObjectStore)
+		}
+		for i := range cluster.Spec.Backup.AdditionalWALArchives {
+			result = append(result, &cluster.Spec.Backup.AdditionalWALArchives[i].BarmanObjectStore)
+		}
+	}
+
+	for _, server := range cluster.Spec.ExternalClusters {
+		if server.BarmanObjectStore != nil {
+			result = append(result, server.BarmanObjectStore)
+		}
+	}
+
+	return result
+}
+
+// barmanEnvReferences returns the names of the secrets and of the config
+// maps used by the custom environment variables of an object store
+func barmanEnvReferences(configuration *apiv1.BarmanObjectStoreConfiguration) (secrets, configMaps []string) {
+	for _, source := range configuration.EnvFrom {
+		if source.SecretRef != nil {
+			secrets = append(secrets, source.SecretRef.Name)
+		}
+		if source.ConfigMapRef != nil {
+			configMaps = append(configMaps, source.ConfigMapRef.Name)
+		}
+	}
+
+	for _, variable := range configuration.Env {
+		if variable.ValueFrom == nil {
+			continue
+		}
+		if variable.ValueFrom.SecretKeyRef != nil {
+			secrets = append(secrets, variable.ValueFrom.SecretKeyRef.Name)
+		}
+		if variable.ValueFrom.ConfigMapKeyRef != nil {
+			configMaps = append(configMaps, variable.ValueFrom.ConfigMapKeyRef.Name)
+		}
+	}
+
+	return secrets, configMaps
+}
+
 func externalClusterSecrets(cluster apiv1.Cluster) []string {
 	var result []string
 
@@ -218,6 +273,16 @@ func backupSecrets(cluster apiv1.Cluster, backupOrigin *apiv1.Backup) []string {
 			googleCredentialsSecrets(cluster.Spec.Backup.BarmanObjectStore.BarmanCredentials.Google)...)
 	}
 
+	// Secrets needed to access the additional WAL archives
+	if cluster.Spec.Backup != nil {
+		for _, destination := range cluster.Spec.Backup.AdditionalWALArchives {
+			credentials := destination.BarmanObjectStore.BarmanCredentials
+			result = append(result, s3CredentialsSecrets(credentials.AWS)...)
+			result = append(result, azureCredentialsSecrets(credentials.Azure)...)
+			result = append(result, googleCredentialsSecrets(credentials.Google)...)
+		}
+	}
+
 	// Secrets needed by Barman, if set
 	if cluster.Spec.Backup.IsBarmanEndpointCASet() {
 		result = append(
@@ -219,4 +219,70 @@ var _ = Describe("Secrets", func() {
 		secrets = backupSecrets(cluster, nil)
 		Expect(secrets).To(ConsistOf("test-secret", "test-access", "test-endpoint-ca-name"))
 	})
+
+	It("include the credentials of the additional WAL archives", func() {
+		cluster.Spec = apiv1.ClusterSpec{
+			Backup: &apiv1.BackupConfiguration{
+				BarmanObjectStore: &apiv1.BarmanObjectStoreConfiguration{},
+				AdditionalWALArchives: []apiv1.WALArchiveDestination{
+					{
+						Name: "dr",
+						BarmanObjectStore: apiv1.BarmanObjectStoreConfiguration{
+							BarmanCredentials: apiv1.BarmanCredentials{
+								Azure: &apiv1.AzureCredentials{
+									ConnectionString: &apiv1.SecretKeySelector{
+										LocalObjectReference: apiv1.LocalObjectReference{Name: "dr-connection"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		secrets := backupSecrets(cluster, nil)
+		Expect(secrets).To(ConsistOf("dr-connection"))
+	})
+})
+
+var _ = Describe("Barman environment references", func() {
+	It("include the secrets and the config maps of every object store", func() {
+		cluster := apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "thisTest",
+				Namespace: "default",
+			},
+			Spec: apiv1.ClusterSpec{
+				Backup: &apiv1.BackupConfiguration{
+					BarmanObjectStore: &apiv1.BarmanObjectStoreConfiguration{
+						EnvFrom: []corev1.EnvFromSource{
+							{SecretRef: &corev1.SecretEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "proxy-secret"},
+							}},
+						},
+					},
+				},
+				ExternalClusters: []apiv1.ExternalCluster{
+					{
+						Name: "origin",
+						BarmanObjectStore: &apiv1.BarmanObjectStoreConfiguration{
+							Env: []corev1.EnvVar{
+								{Name: "NO_PROXY", Value: "localhost"},
+								{Name: "AWS_REGION", ValueFrom: &corev1.EnvVarSource{
+									ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "provider-options"},
+										Key:                  "region",
+									},
+								}},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		role := CreateRole(cluster, nil)
+		Expect(role.Rules[0].ResourceNames).To(ContainElement("provider-options"))
+		Expect(role.Rules[1].ResourceNames).To(ContainElement("proxy-secret"))
+	})
 })
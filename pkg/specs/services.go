@@ -52,7 +52,7 @@ func CreateClusterAnyService(cluster apiv1.Cluster) *corev1.Service {
 
 // CreateClusterReadService create a service insisting on all the ready pods
 func CreateClusterReadService(cluster apiv1.Cluster) *corev1.Service {
-	return &corev1.Service{
+	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cluster.GetServiceReadName(),
 			Namespace: cluster.Namespace,
@@ -72,11 +72,14 @@ func CreateClusterReadService(cluster apiv1.Cluster) *corev1.Service {
 			},
 		},
 	}
+
+	applyServiceConfiguration(service, cluster.GetReadServiceConfiguration())
+	return service
 }
 
 // CreateClusterReadOnlyService create a service insisting on all the ready pods
 func CreateClusterReadOnlyService(cluster apiv1.Cluster) *corev1.Service {
-	return &corev1.Service{
+	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cluster.GetServiceReadOnlyName(),
 			Namespace: cluster.Namespace,
@@ -97,11 +100,19 @@ func CreateClusterReadOnlyService(cluster apiv1.Cluster) *corev1.Service {
 			},
 		},
 	}
+
+	// Exclude the lagging replicas when a maximum lag is set
+	if _, ok := cluster.GetReadOnlyServiceMaxLag(); ok {
+		service.Spec.Selector[ClusterCaughtUpLabelName] = "true"
+	}
+
+	applyServiceConfiguration(service, cluster.GetReadOnlyServiceConfiguration())
+	return service
 }
 
 // CreateClusterReadWriteService create a service insisting on the primary pod
 func CreateClusterReadWriteService(cluster apiv1.Cluster) *corev1.Service {
-	return &corev1.Service{
+	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cluster.GetServiceReadWriteName(),
 			Namespace: cluster.Namespace,
@@ -122,4 +133,34 @@ func CreateClusterReadWriteService(cluster apiv1.Cluster) *corev1.Service {
 			},
 		},
 	}
+
+	applyServiceConfiguration(service, cluster.GetReadWriteServiceConfiguration())
+	return service
+}
+
+// applyServiceConfiguration applies the type, annotations and labels
+// requested by the user to a service generated by the operator
+func applyServiceConfiguration(service *corev1.Service, configuration *apiv1.ServiceConfiguration) {
+	if configuration == nil {
+		return
+	}
+
+	service.Spec.Type = configuration.GetType()
+	if configuration.IsHeadless() {
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+
+	if len(configuration.Annotations) > 0 {
+		service.Annotations = make(map[string]string, len(configuration.Annotations))
+		for key, value := range configuration.Annotations {
+			service.Annotations[key] = value
+		}
+	}
+
+	if len(configuration.Labels) > 0 {
+		service.Labels = make(map[string]string, len(configuration.Labels))
+		for key, value := range configuration.Labels {
+			service.Labels[key] = value
+		}
+	}
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package specs
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/cloudnative-pg/cloudnative-pg/api/v1"
@@ -52,6 +53,15 @@ var _ = Describe("Services specification", func() {
 		Expect(service.Spec.PublishNotReadyAddresses).To(BeFalse())
 		Expect(service.Spec.Selector["postgresql"]).To(Equal("clustername"))
 		Expect(service.Spec.Selector[ClusterRoleLabelName]).To(Equal(ClusterRoleLabelReplica))
+		Expect(service.Spec.Selector).ToNot(HaveKey(ClusterCaughtUpLabelName))
+	})
+
+	It("create a -ro service excluding the lagging replicas", func() {
+		cluster := postgresql.DeepCopy()
+		cluster.Spec.ReadOnlyService = &apiv1.ReadOnlyServiceConfiguration{MaxLag: "16Mi"}
+		service := CreateClusterReadOnlyService(*cluster)
+		Expect(service.Spec.Selector[ClusterRoleLabelName]).To(Equal(ClusterRoleLabelReplica))
+		Expect(service.Spec.Selector[ClusterCaughtUpLabelName]).To(Equal("true"))
 	})
 
 	It("create a configured -rw service", func() {
@@ -61,4 +71,34 @@ var _ = Describe("Services specification", func() {
 		Expect(service.Spec.Selector["postgresql"]).To(Equal("clustername"))
 		Expect(service.Spec.Selector[ClusterRoleLabelName]).To(Equal(ClusterRoleLabelPrimary))
 	})
+
+	It("create services with the type, annotations and labels requested by the user", func() {
+		cluster := postgresql.DeepCopy()
+		cluster.Spec.ReadWriteService = &apiv1.ServiceConfiguration{
+			Type:        corev1.ServiceTypeLoadBalancer,
+			Annotations: map[string]string{"lb": "internal"},
+			Labels:      map[string]string{"exposed": "true"},
+		}
+		cluster.Spec.ReadOnlyService = &apiv1.ReadOnlyServiceConfiguration{
+			ServiceConfiguration: apiv1.ServiceConfiguration{Type: corev1.ServiceTypeNodePort},
+		}
+		cluster.Spec.ReadService = &apiv1.ServiceConfiguration{Headless: true}
+
+		readWriteService := CreateClusterReadWriteService(*cluster)
+		Expect(readWriteService.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(readWriteService.Annotations).To(HaveKeyWithValue("lb", "internal"))
+		Expect(readWriteService.Labels).To(HaveKeyWithValue("exposed", "true"))
+
+		readOnlyService := CreateClusterReadOnlyService(*cluster)
+		Expect(readOnlyService.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+		Expect(readOnlyService.Annotations).To(BeEmpty())
+
+		readService := CreateClusterReadService(*cluster)
+		Expect(readService.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		Expect(readService.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+
+		anyService := CreateClusterAnyService(*cluster)
+		Expect(anyService.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		Expect(anyService.Spec.ClusterIP).To(BeEmpty())
+	})
 })
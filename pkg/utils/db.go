@@ -18,12 +18,21 @@ package utils
 
 import (
 	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 	// this is needed to correctly open the sql connection with the pgx driver. Do not remove this import.
 	"github.com/jackc/pgx/v4/stdlib"
 )
 
+// KeepalivesIdleParameter is the connection parameter setting the number
+// of seconds of inactivity after which a TCP keepalive message is sent,
+// as in libpq
+const KeepalivesIdleParameter = "keepalives_idle"
+
 // NewSimpleDBConnection creates a postgres connection with the simple protocol
 func NewSimpleDBConnection(connectionString string) (*sql.DB, error) {
 	conf, err := pgx.ParseConfig(connectionString)
@@ -45,5 +54,21 @@ func NewSimpleDBConnection(connectionString string) (*sql.DB, error) {
 	// when it's needed
 	conf.RuntimeParams["datestyle"] = "ISO"
 
+	// pgx doesn't know the libpq keepalives parameters, and would send
+	// them to the server as runtime parameters, so we apply them to the
+	// dialer of this connection instead
+	if keepalivesIdle, ok := conf.RuntimeParams[KeepalivesIdleParameter]; ok {
+		delete(conf.RuntimeParams, KeepalivesIdleParameter)
+		seconds, err := strconv.Atoi(keepalivesIdle)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid %s value: %q", KeepalivesIdleParameter, keepalivesIdle)
+		}
+		dialer := &net.Dialer{
+			KeepAlive: time.Duration(seconds) * time.Second,
+			Timeout:   conf.ConnectTimeout,
+		}
+		conf.DialFunc = dialer.DialContext
+	}
+
 	return sql.Open("pgx", stdlib.RegisterConnConfig(conf))
 }
@@ -0,0 +1,35 @@
+/*
+Copyright The CloudNativePG Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("simple database connection", func() {
+	It("accepts the keepalives_idle connection parameter", func() {
+		db, err := NewSimpleDBConnection("host=localhost user=postgres keepalives_idle=30")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(db.Close()).To(Succeed())
+	})
+
+	It("refuses an invalid keepalives_idle connection parameter", func() {
+		_, err := NewSimpleDBConnection("host=localhost user=postgres keepalives_idle=often")
+		Expect(err).To(MatchError(ContainSubstring("invalid keepalives_idle value")))
+	})
+})
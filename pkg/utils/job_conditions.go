@@ -18,6 +18,7 @@ package utils
 
 import (
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // IsJobComplete check if a certain job is complete
@@ -29,6 +30,27 @@ func IsJobComplete(job batchv1.Job) bool {
 	return job.Status.Succeeded == requestedCompletions
 }
 
+// IsJobFailed check if a certain job has failed, and won't be retried
+func IsJobFailed(job batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterFailedJobs returns jobs that have failed
+func FilterFailedJobs(jobList []batchv1.Job) []batchv1.Job {
+	var result []batchv1.Job
+	for _, job := range jobList {
+		if IsJobFailed(job) {
+			result = append(result, job)
+		}
+	}
+	return result
+}
+
 // FilterCompleteJobs returns jobs that are complete
 func FilterCompleteJobs(jobList []batchv1.Job) []batchv1.Job {
 	var result []batchv1.Job
@@ -18,6 +18,7 @@ package utils
 
 import (
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -47,4 +48,20 @@ var _ = Describe("Job conditions", func() {
 		Expect(CountCompleteJobs([]batchv1.Job{completeJob})).To(Equal(1))
 		Expect(CountCompleteJobs([]batchv1.Job{})).To(Equal(0))
 	})
+
+	It("detects if a certain job has failed", func() {
+		failedJob := batchv1.Job{
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue},
+				},
+			},
+		}
+
+		Expect(IsJobFailed(nonCompleteJob)).To(BeFalse())
+		Expect(IsJobFailed(completeJob)).To(BeFalse())
+		Expect(IsJobFailed(failedJob)).To(BeTrue())
+		Expect(FilterFailedJobs([]batchv1.Job{nonCompleteJob, failedJob, completeJob})).To(
+			Equal([]batchv1.Job{failedJob}))
+	})
 })
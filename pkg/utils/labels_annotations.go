@@ -41,6 +41,10 @@ const (
 	// InstanceNameLabelName is the name of the label containing the instance name
 	InstanceNameLabelName = "cnpg.io/instanceName"
 
+	// ClientCertificateUserLabelName is the name of the label containing the
+	// PostgreSQL role a generated client certificate has been issued for
+	ClientCertificateUserLabelName = "cnpg.io/clientCertificateUser"
+
 	// OperatorVersionAnnotationName is the name of the annotation containing
 	// the version of the operator that generated a certain object
 	OperatorVersionAnnotationName = "cnpg.io/operatorVersion"
@@ -62,8 +66,59 @@ const (
 	// HibernatePgControlDataAnnotationName contains the pg_controldata output of the hibernated cluster
 	HibernatePgControlDataAnnotationName = "cnpg.io/hibernatePgControlData"
 
+	// HibernationAnnotationName is the name of the annotation controlling
+	// the declarative hibernation of the cluster
+	HibernationAnnotationName = "cnpg.io/hibernation"
+
+	// HibernationPrimaryAnnotationName contains, on every PVC of an hibernated
+	// cluster, the name of the instance that was the primary before the hibernation
+	HibernationPrimaryAnnotationName = "cnpg.io/hibernationPrimary"
+
+	// SwitchoverToAnnotationName is the name of the annotation used to request
+	// a switchover to the instance whose name is the value of the annotation
+	SwitchoverToAnnotationName = "cnpg.io/switchoverTo"
+
+	// ApprovePrimaryUpdateAnnotationName is the name of the annotation used
+	// to approve the update of the primary instance of a cluster using the
+	// supervised primary update strategy
+	ApprovePrimaryUpdateAnnotationName = "cnpg.io/approvePrimaryUpdate"
+
+	// RebuildInstanceAnnotationName is the name of the annotation used,
+	// on the Pod of a replica, to request the operator to destroy the
+	// instance data and to rebuild it from the current primary
+	RebuildInstanceAnnotationName = "cnpg.io/rebuild"
+
+	// SwitchoverPauseAnnotationName is the name of the annotation set by the
+	// operator on a Pooler, containing the time when the pause of PgBouncer
+	// has been requested before a planned switchover of the cluster
+	SwitchoverPauseAnnotationName = "cnpg.io/switchoverPauseRequested"
+
+	// RetainPVCsFinalizerName is the name of the finalizer used to detach
+	// the PVCs to be retained from a cluster being deleted
+	RetainPVCsFinalizerName = "cnpg.io/retainPVCs"
+
 	// skipEmptyWalArchiveCheck turns off the checks that ensure that the WAL archive is empty before writing data
 	skipEmptyWalArchiveCheck = "cnpg.io/skipEmptyWalArchiveCheck"
+
+	// skipWalStorageCheck turns off the checks that prevent removing
+	// the WAL storage from a cluster that has it
+	skipWalStorageCheck = "cnpg.io/skipWalStorageCheck"
+
+	// skipParametersValidation contains a comma separated list of PostgreSQL
+	// parameters that are not validated by the operator
+	skipParametersValidation = "cnpg.io/skipParametersValidation"
+
+	// skipReplicaClusterSourceCheck turns off the check that prevents the
+	// promotion of a replica cluster while its source is still a primary
+	skipReplicaClusterSourceCheck = "cnpg.io/skipReplicaClusterSourceCheck"
+
+	// skipCredentialsCheck turns off the checks that ensure that the secrets
+	// referenced by the object store configurations exist
+	skipCredentialsCheck = "cnpg.io/skipCredentialsCheck"
+
+	// skipMajorVersionCheck turns off the checks that prevent changing the PostgreSQL
+	// major version of the image used by a cluster
+	skipMajorVersionCheck = "cnpg.io/skipMajorVersionCheck"
 )
 
 type annotationStatus string
@@ -73,6 +128,19 @@ const (
 	annotationStatusEnabled  annotationStatus = "enabled"
 )
 
+// HibernationAnnotationValue describes the status of the hibernation
+type HibernationAnnotationValue string
+
+const (
+	// HibernationAnnotationValueOff is the value of the hibernation annotation
+	// for a running cluster
+	HibernationAnnotationValueOff HibernationAnnotationValue = "off"
+
+	// HibernationAnnotationValueOn is the value of the hibernation annotation
+	// requesting the cluster to be hibernated
+	HibernationAnnotationValueOn HibernationAnnotationValue = "on"
+)
+
 // PodRole describes the Role of a given pod
 type PodRole string
 
@@ -118,9 +186,43 @@ func SetOperatorVersion(object *metav1.ObjectMeta, version string) {
 	object.Annotations[OperatorVersionAnnotationName] = version
 }
 
+// operatorMetadataPrefix is the prefix of the labels and annotations
+// managed by the operator
+const operatorMetadataPrefix = "cnpg.io/"
+
+// operatorManagedLabels are the labels, outside the operator namespace,
+// whose value is set by the operator on the generated objects
+var operatorManagedLabels = []string{
+	"role",
+	"postgresql",
+}
+
+// IsOperatorManagedLabel checks if a label is managed by the operator,
+// and then must not be overridden by the inherited ones
+func IsOperatorManagedLabel(name string) bool {
+	if strings.HasPrefix(name, operatorMetadataPrefix) {
+		return true
+	}
+
+	for _, label := range operatorManagedLabels {
+		if name == label {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOperatorManagedAnnotation checks if an annotation is managed by the operator,
+// and then must not be overridden by the inherited ones
+func IsOperatorManagedAnnotation(name string) bool {
+	return strings.HasPrefix(name, operatorMetadataPrefix)
+}
+
 // InheritAnnotations puts into the object metadata the passed annotations if
 // the annotations are supposed to be inherited. The passed configuration is
-// used to determine whenever a certain annotation is inherited or not
+// used to determine whenever a certain annotation is inherited or not.
+// Annotations managed by the operator are never inherited
 func InheritAnnotations(
 	object *metav1.ObjectMeta,
 	annotations map[string]string,
@@ -132,11 +234,13 @@ func InheritAnnotations(
 	}
 
 	for key, value := range fixedAnnotations {
-		object.Annotations[key] = value
+		if !IsOperatorManagedAnnotation(key) {
+			object.Annotations[key] = value
+		}
 	}
 
 	for key, value := range annotations {
-		if config.IsAnnotationInherited(key) {
+		if config.IsAnnotationInherited(key) && !IsOperatorManagedAnnotation(key) {
 			object.Annotations[key] = value
 		}
 	}
@@ -144,7 +248,8 @@ func InheritAnnotations(
 
 // InheritLabels puts into the object metadata the passed labels if
 // the labels are supposed to be inherited. The passed configuration is
-// used to determine whenever a certain label is inherited or not
+// used to determine whenever a certain label is inherited or not.
+// Labels managed by the operator are never inherited
 func InheritLabels(
 	object *metav1.ObjectMeta,
 	labels map[string]string,
@@ -156,11 +261,13 @@ func InheritLabels(
 	}
 
 	for key, value := range fixedLabels {
-		object.Labels[key] = value
+		if !IsOperatorManagedLabel(key) {
+			object.Labels[key] = value
+		}
 	}
 
 	for key, value := range labels {
-		if config.IsLabelInherited(key) {
+		if config.IsLabelInherited(key) && !IsOperatorManagedLabel(key) {
 			object.Labels[key] = value
 		}
 	}
@@ -210,3 +317,51 @@ func IsReconciliationDisabled(object *metav1.ObjectMeta) bool {
 func IsEmptyWalArchiveCheckEnabled(object *metav1.ObjectMeta) bool {
 	return object.Annotations[skipEmptyWalArchiveCheck] != string(annotationStatusEnabled)
 }
+
+// IsWalStorageCheckEnabled returns a boolean indicating if we should prevent removing
+// the WAL storage from a cluster that has it
+func IsWalStorageCheckEnabled(object *metav1.ObjectMeta) bool {
+	return object.Annotations[skipWalStorageCheck] != string(annotationStatusEnabled)
+}
+
+// IsReplicaClusterSourceCheckEnabled returns a boolean indicating if we should
+// check that the source of a replica cluster is not a primary before promoting it
+func IsReplicaClusterSourceCheckEnabled(object *metav1.ObjectMeta) bool {
+	return object.Annotations[skipReplicaClusterSourceCheck] != string(annotationStatusEnabled)
+}
+
+// GetParametersSkippingValidation returns the names of the PostgreSQL
+// parameters that the user requested not to be validated by the operator
+func GetParametersSkippingValidation(object *metav1.ObjectMeta) []string {
+	var parameters []string
+	for _, parameter := range strings.Split(object.Annotations[skipParametersValidation], ",") {
+		if parameter = strings.TrimSpace(parameter); parameter != "" {
+			parameters = append(parameters, parameter)
+		}
+	}
+
+	return parameters
+}
+
+// IsCredentialsCheckEnabled returns a boolean indicating if we should check
+// that the secrets referenced by the object store configurations exist
+func IsCredentialsCheckEnabled(object *metav1.ObjectMeta) bool {
+	return object.Annotations[skipCredentialsCheck] != string(annotationStatusEnabled)
+}
+
+// IsMajorVersionCheckEnabled returns a boolean indicating if we should prevent changing
+// the PostgreSQL major version of the image used by the cluster
+func IsMajorVersionCheckEnabled(object *metav1.ObjectMeta) bool {
+	return object.Annotations[skipMajorVersionCheck] != string(annotationStatusEnabled)
+}
+
+// IsInstanceRebuildRequested checks if the rebuild of the instance
+// running in the Pod having the passed metadata has been requested
+func IsInstanceRebuildRequested(object *metav1.ObjectMeta) bool {
+	return object.Annotations[RebuildInstanceAnnotationName] == string(annotationStatusEnabled)
+}
+
+// IsHibernationEnabled checks if the hibernation of the cluster has been requested
+func IsHibernationEnabled(object *metav1.ObjectMeta) bool {
+	return object.Annotations[HibernationAnnotationName] == string(HibernationAnnotationValueOn)
+}
@@ -88,6 +88,57 @@ var _ = Describe("Label management", func() {
 	})
 })
 
+var _ = Describe("Operator managed metadata protection", func() {
+	config := &configuration.Data{}
+	config.ReadConfigMap(map[string]string{
+		"INHERITED_LABELS":      "*",
+		"INHERITED_ANNOTATIONS": "*",
+	})
+
+	It("detects the labels and annotations managed by the operator", func() {
+		Expect(IsOperatorManagedLabel(ClusterLabelName)).To(BeTrue())
+		Expect(IsOperatorManagedLabel("role")).To(BeTrue())
+		Expect(IsOperatorManagedLabel("team")).To(BeFalse())
+		Expect(IsOperatorManagedAnnotation(OperatorVersionAnnotationName)).To(BeTrue())
+		Expect(IsOperatorManagedAnnotation("cost-center")).To(BeFalse())
+	})
+
+	It("doesn't override the labels managed by the operator", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"role": "primary", ClusterLabelName: "cluster-example"},
+			},
+		}
+		InheritLabels(&pod.ObjectMeta,
+			map[string]string{"role": "replica", "team": "dba"},
+			map[string]string{ClusterLabelName: "another", "cost-center": "42"},
+			config)
+		Expect(pod.Labels).To(Equal(map[string]string{
+			"role":           "primary",
+			ClusterLabelName: "cluster-example",
+			"team":           "dba",
+			"cost-center":    "42",
+		}))
+	})
+
+	It("doesn't override the annotations managed by the operator", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{OperatorVersionAnnotationName: "1.18.0"},
+			},
+		}
+		InheritAnnotations(&pod.ObjectMeta,
+			map[string]string{OperatorVersionAnnotationName: "0.0.1", "team": "dba"},
+			map[string]string{"cost-center": "42"},
+			config)
+		Expect(pod.Annotations).To(Equal(map[string]string{
+			OperatorVersionAnnotationName: "1.18.0",
+			"team":                        "dba",
+			"cost-center":                 "42",
+		}))
+	})
+})
+
 var _ = Describe("Label cluster name management", func() {
 	pod := corev1.Pod{}
 	podTwo := corev1.Pod{
@@ -121,3 +172,18 @@ var _ = Describe("Annotate pods management", func() {
 		Expect(pod.ObjectMeta.Annotations[AppArmorAnnotationPrefix+"/apparmor_profile"]).To(Equal("unconfined"))
 	})
 })
+
+var _ = Describe("Parameters skipping validation", func() {
+	It("returns nothing when the annotation is missing", func() {
+		Expect(GetParametersSkippingValidation(&metav1.ObjectMeta{})).To(BeEmpty())
+	})
+
+	It("splits the comma separated list of parameters", func() {
+		object := &metav1.ObjectMeta{
+			Annotations: map[string]string{
+				skipParametersValidation: " wal_compression,, recovery_target_name ",
+			},
+		}
+		Expect(GetParametersSkippingValidation(object)).To(Equal([]string{"wal_compression", "recovery_target_name"}))
+	})
+})
@@ -48,8 +48,8 @@ func CollectDifferencesFromMaps(p1 map[string]string, p2 map[string]string) map[
 	return nil
 }
 
-// isMapSubset returns true if mapSubset is a subset of mapSet otherwise false
-func isMapSubset(mapSet map[string]string, mapSubset map[string]string) bool {
+// IsMapSubset returns true if mapSubset is a subset of mapSet otherwise false
+func IsMapSubset(mapSet map[string]string, mapSubset map[string]string) bool {
 	if len(mapSet) < len(mapSubset) {
 		return false
 	}
@@ -101,16 +101,18 @@ func IsLabelSubset(mapSet, clusterLabels, fixedInheritedLabels map[string]string
 	mapToEvaluate := map[string]string{}
 
 	for key, value := range fixedInheritedLabels {
-		mapToEvaluate[key] = value
+		if !IsOperatorManagedLabel(key) {
+			mapToEvaluate[key] = value
+		}
 	}
 
 	for key, value := range clusterLabels {
-		if configuration.IsLabelInherited(key) {
+		if configuration.IsLabelInherited(key) && !IsOperatorManagedLabel(key) {
 			mapToEvaluate[key] = value
 		}
 	}
 
-	return isMapSubset(mapSet, mapToEvaluate)
+	return IsMapSubset(mapSet, mapToEvaluate)
 }
 
 // IsAnnotationSubset checks if a collection of annotations is a subset of another
@@ -124,16 +126,18 @@ func IsAnnotationSubset(mapSet, clusterAnnotations, fixedInheritedAnnotations ma
 	mapToEvaluate := map[string]string{}
 
 	for key, value := range fixedInheritedAnnotations {
-		mapToEvaluate[key] = value
+		if !IsOperatorManagedAnnotation(key) {
+			mapToEvaluate[key] = value
+		}
 	}
 
 	for key, value := range clusterAnnotations {
-		if configuration.IsAnnotationInherited(key) {
+		if configuration.IsAnnotationInherited(key) && !IsOperatorManagedAnnotation(key) {
 			mapToEvaluate[key] = value
 		}
 	}
 
-	return isMapSubset(mapSet, mapToEvaluate)
+	return IsMapSubset(mapSet, mapToEvaluate)
 }
 
 // IsResourceSubset checks if some resource requirements are a subset of another